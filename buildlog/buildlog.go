@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -19,6 +20,29 @@ const (
 	rowBuildLogPath = "sd-artifacts/builds.log"
 )
 
+// Format is the format of the build log output.
+type Format string
+
+const (
+	// FormatText outputs each line as "<step name>: <message>".
+	FormatText Format = "text"
+	// FormatJSON outputs each line as a JSON object for log pipelines.
+	FormatJSON Format = "json"
+)
+
+// Option is the option of the build log output.
+type Option struct {
+	Format Format
+	// JobName is added to each line in JSON format to distinguish the jobs run at the same time.
+	JobName string
+	// StepsDir is the directory where the log of each step is written. It is not written if empty.
+	StepsDir string
+	// Timestamps adds the time to each line and the elapsed time after each step in text format.
+	Timestamps bool
+	// Secrets are replaced with Mask in each line before it is written into the writer and the step logs.
+	Secrets []string
+}
+
 // Logger outputs logs
 type Logger interface {
 	Run()
@@ -31,7 +55,13 @@ type log struct {
 	writer         io.Writer
 	cancel         context.CancelFunc
 	done           chan<- struct{}
+	option         Option
+	replacer       *strings.Replacer
+	steps          *stepFiles
 	currentLineNum int
+	currentStep    string
+	stepStart      int64
+	lastTime       int64
 }
 
 type logLine struct {
@@ -41,15 +71,24 @@ type logLine struct {
 	StepName string `json:"s"`
 }
 
+type jsonLine struct {
+	Time     string `json:"time"`
+	Job      string `json:"job,omitempty"`
+	StepName string `json:"step"`
+	Message  string `json:"message"`
+}
+
 type parseError struct{}
 
 func (e *parseError) Error() string { return "Parse Error" }
 
 // New creates new Logger interface.
-func New(filepath string, writer io.Writer, done chan<- struct{}) (Logger, error) {
+func New(filepath string, writer io.Writer, done chan<- struct{}, option Option) (Logger, error) {
 	log := log{
-		writer: writer,
-		done:   done,
+		writer:   writer,
+		done:     done,
+		option:   option,
+		replacer: newReplacer(option.Secrets),
 	}
 
 	var err error
@@ -58,6 +97,13 @@ func New(filepath string, writer io.Writer, done chan<- struct{}) (Logger, error
 		return &log, fmt.Errorf("failed to open raw build log file: %w", err)
 	}
 
+	if option.StepsDir != "" {
+		log.steps, err = newStepFiles(option.StepsDir)
+		if err != nil {
+			return &log, err
+		}
+	}
+
 	log.ctx, log.cancel = context.WithCancel(context.Background())
 
 	return &log, nil
@@ -89,11 +135,14 @@ func (l *log) Run() {
 		if err != nil {
 			logrus.Errorf("failed to run logger: %v\n", err)
 			logrus.Info("But build is still running")
+			l.closeSteps()
 			close(l.done)
 			break
 		}
 
 		if buildDone && readDone {
+			l.outputElapsed()
+			l.closeSteps()
 			close(l.done)
 			break
 		}
@@ -125,22 +174,84 @@ func (l *log) output(reader *bufio.Reader) (bool, error) {
 		return false, fmt.Errorf("failed to read logfile: %w", err)
 	}
 
-	parsedLog, err := parse(line)
+	ll, err := parse(line)
 	if err != nil {
 		logrus.Warnf("\x1b[33mParsed error. If you want to check see %s:%d \x1b[0m", rowBuildLogPath, l.currentLineNum)
 		return false, &parseError{}
 	}
+	if l.replacer != nil {
+		ll.Message = l.replacer.Replace(ll.Message)
+	}
+
+	formatted, err := l.format(ll)
+	if err != nil {
+		return false, err
+	}
+
+	l.lastTime = ll.Time
+	if ll.StepName != l.currentStep {
+		l.outputElapsed()
+		l.currentStep, l.stepStart = ll.StepName, ll.Time
+	}
+
+	fmt.Fprintln(l.writer, formatted)
 
-	fmt.Fprintln(l.writer, parsedLog)
+	if l.steps != nil {
+		if err := l.steps.write(ll.StepName, ll.Message); err != nil {
+			logrus.Warn(err)
+		}
+	}
 	return false, nil
 }
 
-func parse(rawLog []byte) (string, error) {
+// outputElapsed outputs the elapsed time of the current step, which lasts until the next step starts.
+func (l *log) outputElapsed() {
+	if !l.option.Timestamps || l.option.Format == FormatJSON || l.currentStep == "" {
+		return
+	}
+
+	elapsed := time.Duration(l.lastTime-l.stepStart) * time.Millisecond
+	fmt.Fprintf(l.writer, "%s: elapsed %s\n", l.currentStep, elapsed.Round(time.Second))
+}
+
+func (l *log) closeSteps() {
+	if l.steps != nil {
+		l.steps.close()
+	}
+}
+
+// logTime returns the time of the line, which is written in milliseconds by the launcher.
+func logTime(ms int64) time.Time {
+	return time.Unix(0, ms*int64(time.Millisecond)).UTC()
+}
+
+func parse(rawLog []byte) (*logLine, error) {
 	ll := &logLine{}
 	err := json.Unmarshal(rawLog, ll)
 	if err != nil {
-		return "", fmt.Errorf("failed to parse raw log: %w", err)
+		return nil, fmt.Errorf("failed to parse raw log: %w", err)
+	}
+
+	return ll, nil
+}
+
+func (l *log) format(ll *logLine) (string, error) {
+	if l.option.Format != FormatJSON {
+		if l.option.Timestamps {
+			return fmt.Sprintf("%s %s: %s", logTime(ll.Time).Format(time.RFC3339), ll.StepName, ll.Message), nil
+		}
+		return fmt.Sprintf("%s: %s", ll.StepName, ll.Message), nil
+	}
+
+	out, err := json.Marshal(jsonLine{
+		Time:     logTime(ll.Time).Format(time.RFC3339Nano),
+		Job:      l.option.JobName,
+		StepName: ll.StepName,
+		Message:  ll.Message,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to format log: %w", err)
 	}
 
-	return fmt.Sprintf("%s: %s", ll.StepName, ll.Message), nil
+	return string(out), nil
 }
@@ -25,23 +25,28 @@ var (
 	}
 )
 
+// write is run in another goroutine, so it reports the errors with Error instead of Fatal.
 func write(tb testing.TB, filepath string, inputs []string) {
 	tb.Helper()
 
 	for _, input := range inputs {
 		file, err := os.OpenFile(filepath, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
 		if err != nil {
-			tb.Fatal(err)
+			tb.Error(err)
+			return
 		}
 
 		_, err = file.Write([]byte(input))
 		if err != nil {
-			tb.Fatal(err)
+			file.Close()
+			tb.Error(err)
+			return
 		}
 
 		err = file.Close()
 		if err != nil {
-			tb.Fatal(err)
+			tb.Error(err)
+			return
 		}
 	}
 }
@@ -128,6 +133,147 @@ func TestRun(t *testing.T) {
 		}
 	})
 
+	t.Run("success with JSON format", func(t *testing.T) {
+		tmpFile, err := ioutil.TempFile("", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer tmpFile.Close()
+
+		go write(t, tmpFile.Name(), testInputs)
+
+		parent, cancel := context.WithCancel(context.Background())
+		writer := bytes.NewBuffer(nil)
+		done := make(chan struct{})
+		l := log{
+			file:   tmpFile,
+			writer: writer,
+			ctx:    parent,
+			cancel: cancel,
+			done:   done,
+			option: Option{Format: FormatJSON, JobName: "test"},
+		}
+
+		go l.Run()
+
+		time.Sleep(intervalTime * time.Millisecond)
+		l.Stop()
+		timeout := time.After(5 * time.Second)
+
+		select {
+		case <-done:
+			expected := `{"time":"2020-02-14T06:33:42.394Z","job":"test","step":"main","message":"test 1"}` + "\n" +
+				`{"time":"2020-02-14T06:33:42.395Z","job":"test","step":"main","message":"test 2"}` + "\n"
+			assert.Equal(t, expected, writer.String())
+		case <-timeout:
+			assert.Fail(t, "timeout stop buildlog")
+		}
+	})
+
+	t.Run("success with masking secrets in output and step logs", func(t *testing.T) {
+		tmpFile, err := ioutil.TempFile("", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer tmpFile.Close()
+
+		inputs := []string{
+			`{"t": 1581662022394, "m": "token is jwt-token", "n": 0, "s": "main"}` + "\n",
+			`{"t": 1581662022395, "m": "key is \"quoted\"", "n": 1, "s": "main"}` + "\n",
+		}
+		go write(t, tmpFile.Name(), inputs)
+
+		dir, err := ioutil.TempDir("", "sd-artifacts")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		steps, err := newStepFiles(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		parent, cancel := context.WithCancel(context.Background())
+		writer := bytes.NewBuffer(nil)
+		done := make(chan struct{})
+		l := log{
+			file:     tmpFile,
+			writer:   writer,
+			ctx:      parent,
+			cancel:   cancel,
+			done:     done,
+			option:   Option{Format: FormatJSON, JobName: "test"},
+			replacer: newReplacer([]string{"jwt-token", `"quoted"`}),
+			steps:    steps,
+		}
+
+		go l.Run()
+
+		time.Sleep(intervalTime * time.Millisecond)
+		l.Stop()
+		timeout := time.After(5 * time.Second)
+
+		select {
+		case <-done:
+			expected := `{"time":"2020-02-14T06:33:42.394Z","job":"test","step":"main","message":"token is ***"}` + "\n" +
+				`{"time":"2020-02-14T06:33:42.395Z","job":"test","step":"main","message":"key is ***"}` + "\n"
+			assert.Equal(t, expected, writer.String())
+
+			content, err := ioutil.ReadFile(StepLogPath(dir, 1, "main"))
+			assert.Nil(t, err)
+			assert.Equal(t, "token is ***\nkey is ***\n", string(content))
+		case <-timeout:
+			assert.Fail(t, "timeout stop buildlog")
+		}
+	})
+
+	t.Run("success with timestamps", func(t *testing.T) {
+		tmpFile, err := ioutil.TempFile("", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer tmpFile.Close()
+
+		inputs := []string{
+			`{"t": 1581662022394, "m": "npm install", "n": 0, "s": "install"}` + "\n",
+			`{"t": 1581662034394, "m": "added 1 package", "n": 1, "s": "install"}` + "\n",
+			`{"t": 1581662040394, "m": "npm test", "n": 2, "s": "test"}` + "\n",
+			`{"t": 1581662043394, "m": "passed", "n": 3, "s": "test"}` + "\n",
+		}
+		go write(t, tmpFile.Name(), inputs)
+
+		parent, cancel := context.WithCancel(context.Background())
+		writer := bytes.NewBuffer(nil)
+		done := make(chan struct{})
+		l := log{
+			file:   tmpFile,
+			writer: writer,
+			ctx:    parent,
+			cancel: cancel,
+			done:   done,
+			option: Option{Timestamps: true},
+		}
+
+		go l.Run()
+
+		time.Sleep(intervalTime * time.Millisecond)
+		l.Stop()
+		timeout := time.After(5 * time.Second)
+
+		select {
+		case <-done:
+			expected := "2020-02-14T06:33:42Z install: npm install\n" +
+				"2020-02-14T06:33:54Z install: added 1 package\n" +
+				"install: elapsed 18s\n" +
+				"2020-02-14T06:34:00Z test: npm test\n" +
+				"2020-02-14T06:34:03Z test: passed\n" +
+				"test: elapsed 3s\n"
+			assert.Equal(t, expected, writer.String())
+		case <-timeout:
+			assert.Fail(t, "timeout stop buildlog")
+		}
+	})
+
 	t.Run("continue builds with parsing error", func(t *testing.T) {
 		defer func() {
 			logrus.SetOutput(os.Stderr)
@@ -208,7 +354,7 @@ func TestNew(t *testing.T) {
 		writer := bytes.NewBuffer(nil)
 
 		loggerDone := make(chan struct{})
-		logger, err := New(tmpFile.Name(), writer, loggerDone)
+		logger, err := New(tmpFile.Name(), writer, loggerDone, Option{Format: FormatJSON, JobName: "main"})
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -225,21 +371,23 @@ func TestNew(t *testing.T) {
 
 		assert.Equal(t, tmpFile.Name(), file.Name())
 		assert.Equal(t, writer, log.writer)
+		assert.Equal(t, Option{Format: FormatJSON, JobName: "main"}, log.option)
 	})
 
 	t.Run("failure", func(t *testing.T) {
 		writer := bytes.NewBuffer(nil)
 
 		loggerDone := make(chan struct{})
-		logger, err := New("/", writer, loggerDone)
+		logger, err := New("/", writer, loggerDone, Option{})
 		if err == nil {
 			t.Fatal("failure err is nil")
 		}
 
 		expected := &log{
-			writer: writer,
-			file:   (*os.File)(nil),
-			done:   loggerDone,
+			writer:   writer,
+			file:     (*os.File)(nil),
+			done:     loggerDone,
+			replacer: newReplacer(nil),
 		}
 
 		msg := err.Error()
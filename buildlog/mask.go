@@ -0,0 +1,85 @@
+package buildlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Mask is the string which replaces secret values in build logs.
+const Mask = "***"
+
+type maskWriter struct {
+	writer   io.Writer
+	replacer *strings.Replacer
+}
+
+func newReplacer(secrets []string) *strings.Replacer {
+	values := make([]string, 0, len(secrets))
+	for _, s := range secrets {
+		if s == "" {
+			continue
+		}
+		values = append(values, s)
+
+		// The raw build log has the values escaped in JSON
+		escaped, err := json.Marshal(s)
+		if err == nil && string(escaped[1:len(escaped)-1]) != s {
+			values = append(values, string(escaped[1:len(escaped)-1]))
+		}
+	}
+	// Longer values are replaced first so that a secret containing another one is masked entirely.
+	sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+
+	oldnew := make([]string, 0, len(values)*2)
+	for _, v := range values {
+		oldnew = append(oldnew, v, Mask)
+	}
+	return strings.NewReplacer(oldnew...)
+}
+
+// NewMaskWriter returns a writer which replaces secrets with Mask.
+// Each write must contain whole lines, as the logger does, so that no secret is split across writes.
+func NewMaskWriter(writer io.Writer, secrets []string) io.Writer {
+	return &maskWriter{
+		writer:   writer,
+		replacer: newReplacer(secrets),
+	}
+}
+
+func (w *maskWriter) Write(p []byte) (int, error) {
+	_, err := io.WriteString(w.writer, w.replacer.Replace(string(p)))
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// MaskFile replaces secrets in the file with Mask.
+func MaskFile(filepath string, secrets []string) error {
+	info, err := os.Stat(filepath)
+	if err != nil {
+		return fmt.Errorf("failed to mask build log file: %v", err)
+	}
+
+	buf, err := ioutil.ReadFile(filepath)
+	if err != nil {
+		return fmt.Errorf("failed to mask build log file: %v", err)
+	}
+
+	masked := newReplacer(secrets).Replace(string(buf))
+	if masked == string(buf) {
+		return nil
+	}
+
+	err = ioutil.WriteFile(filepath, []byte(masked), info.Mode())
+	if err != nil {
+		return fmt.Errorf("failed to mask build log file: %v", err)
+	}
+
+	return nil
+}
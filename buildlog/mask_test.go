@@ -0,0 +1,101 @@
+package buildlog
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaskWriter(t *testing.T) {
+	testCases := []struct {
+		name    string
+		secrets []string
+		input   string
+		expect  string
+	}{
+		{
+			name:    "success",
+			secrets: []string{"git-key", "jwt-token"},
+			input:   "step: git-key and jwt-token\n",
+			expect:  "step: *** and ***\n",
+		},
+		{
+			name:    "success with overlapping secrets",
+			secrets: []string{"key", "long-key"},
+			input:   "step: long-key key\n",
+			expect:  "step: *** ***\n",
+		},
+		{
+			name:    "success with empty secret",
+			secrets: []string{""},
+			input:   "step: message\n",
+			expect:  "step: message\n",
+		},
+		{
+			name:    "success without secrets",
+			secrets: nil,
+			input:   "step: message\n",
+			expect:  "step: message\n",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := bytes.NewBuffer(nil)
+			w := NewMaskWriter(buf, tt.secrets)
+
+			n, err := fmt.Fprint(w, tt.input)
+			assert.Nil(t, err)
+			assert.Equal(t, len(tt.input), n)
+			assert.Equal(t, tt.expect, buf.String())
+		})
+	}
+}
+
+func TestMaskFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mask")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	t.Run("success", func(t *testing.T) {
+		path := filepath.Join(dir, "builds.log")
+		err := ioutil.WriteFile(path, []byte(`{"t":1,"m":"token is jwt-token","n":0,"s":"test"}`+"\n"), 0666)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = MaskFile(path, []string{"jwt-token"})
+		assert.Nil(t, err)
+
+		buf, err := ioutil.ReadFile(path)
+		assert.Nil(t, err)
+		assert.Equal(t, `{"t":1,"m":"token is ***","n":0,"s":"test"}`+"\n", string(buf))
+	})
+
+	t.Run("success with escaped secret", func(t *testing.T) {
+		path := filepath.Join(dir, "builds.log")
+		err := ioutil.WriteFile(path, []byte(`{"t":1,"m":"key is \"quoted\"","n":0,"s":"test"}`+"\n"), 0666)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = MaskFile(path, []string{`"quoted"`})
+		assert.Nil(t, err)
+
+		buf, err := ioutil.ReadFile(path)
+		assert.Nil(t, err)
+		assert.Equal(t, `{"t":1,"m":"key is ***","n":0,"s":"test"}`+"\n", string(buf))
+	})
+
+	t.Run("failure by nonexistent file", func(t *testing.T) {
+		err := MaskFile(filepath.Join(dir, "none"), []string{"jwt-token"})
+		assert.NotNil(t, err)
+	})
+}
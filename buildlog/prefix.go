@@ -0,0 +1,44 @@
+package buildlog
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+type prefixWriter struct {
+	writer io.Writer
+	prefix []byte
+	mutex  *sync.Mutex
+}
+
+// NewPrefixWriter returns a writer which adds prefix to each line.
+// Writers sharing the same mutex never interleave in the middle of a line.
+func NewPrefixWriter(writer io.Writer, prefix string, mutex *sync.Mutex) io.Writer {
+	return &prefixWriter{
+		writer: writer,
+		prefix: []byte(prefix),
+		mutex:  mutex,
+	}
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	lines := bytes.SplitAfter(p, []byte("\n"))
+	buf := bytes.NewBuffer(make([]byte, 0, len(p)+len(lines)*len(w.prefix)))
+	for _, line := range lines {
+		if len(line) == 0 {
+			continue
+		}
+		buf.Write(w.prefix)
+		buf.Write(line)
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	_, err := w.writer.Write(buf.Bytes())
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,51 @@
+package buildlog
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrefixWriter(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		buf := bytes.NewBuffer(nil)
+		w := NewPrefixWriter(buf, "[main] ", &sync.Mutex{})
+
+		fmt.Fprintln(w, "install: npm install")
+		fmt.Fprint(w, "test: line1\ntest: line2\n")
+
+		assert.Equal(t, "[main] install: npm install\n[main] test: line1\n[main] test: line2\n", buf.String())
+	})
+
+	t.Run("success with shared writer", func(t *testing.T) {
+		buf := bytes.NewBuffer(nil)
+		mutex := &sync.Mutex{}
+		writers := []io.Writer{
+			NewPrefixWriter(buf, "[a] ", mutex),
+			NewPrefixWriter(buf, "[b] ", mutex),
+		}
+
+		wg := &sync.WaitGroup{}
+		for _, w := range writers {
+			wg.Add(1)
+			go func(w io.Writer) {
+				defer wg.Done()
+				for i := 0; i < 100; i++ {
+					fmt.Fprintln(w, "step: message")
+				}
+			}(w)
+		}
+		wg.Wait()
+
+		lines := bytes.Split(bytes.TrimSuffix(buf.Bytes(), []byte("\n")), []byte("\n"))
+		assert.Equal(t, 200, len(lines))
+		for _, line := range lines {
+			s := string(line)
+			assert.True(t, s == "[a] step: message" || s == "[b] step: message", s)
+		}
+	})
+}
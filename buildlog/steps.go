@@ -0,0 +1,58 @@
+package buildlog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// StepsDir is the directory in the artifacts directory where the log of each step is written.
+const StepsDir = "steps"
+
+// stepFiles writes the messages of each step into its own file named <n>-<step name>.log,
+// where n is the order in which the step started.
+// The messages are masked by the log with the same replacer as the output before they are written.
+type stepFiles struct {
+	dir   string
+	files map[string]*os.File
+}
+
+// newStepFiles creates the directory of the step logs, removing the step logs of the previous build.
+func newStepFiles(dir string) (*stepFiles, error) {
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, fmt.Errorf("failed to remove step logs: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return nil, fmt.Errorf("failed to create step logs directory: %w", err)
+	}
+
+	return &stepFiles{dir: dir, files: make(map[string]*os.File)}, nil
+}
+
+// StepLogPath returns the path of the log file of the step which started in the order n.
+func StepLogPath(dir string, n int, stepName string) string {
+	return filepath.Join(dir, fmt.Sprintf("%02d-%s.log", n, stepName))
+}
+
+func (s *stepFiles) write(stepName, message string) error {
+	f, ok := s.files[stepName]
+	if !ok {
+		var err error
+		f, err = os.OpenFile(StepLogPath(s.dir, len(s.files)+1, stepName), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
+		if err != nil {
+			return fmt.Errorf("failed to open step log file: %w", err)
+		}
+		s.files[stepName] = f
+	}
+
+	if _, err := fmt.Fprintln(f, message); err != nil {
+		return fmt.Errorf("failed to write step log file: %w", err)
+	}
+	return nil
+}
+
+func (s *stepFiles) close() {
+	for _, f := range s.files {
+		f.Close()
+	}
+}
@@ -0,0 +1,62 @@
+package buildlog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStepFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sd-artifacts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	stepsDir := filepath.Join(dir, StepsDir)
+	if err := os.MkdirAll(stepsDir, 0777); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(stepsDir, "01-old.log"), []byte("old"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("success", func(t *testing.T) {
+		s, err := newStepFiles(stepsDir)
+		assert.Nil(t, err)
+
+		for _, l := range [][]string{{"install", "npm install"}, {"install", "added 1 package"}, {"test", "npm test"}} {
+			assert.Nil(t, s.write(l[0], l[1]))
+		}
+		s.close()
+
+		files, err := ioutil.ReadDir(stepsDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		names := make([]string, 0, len(files))
+		for _, f := range files {
+			names = append(names, f.Name())
+		}
+		assert.Equal(t, []string{"01-install.log", "02-test.log"}, names)
+
+		content, err := ioutil.ReadFile(StepLogPath(stepsDir, 1, "install"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, "npm install\nadded 1 package\n", string(content))
+	})
+
+	t.Run("failure by invalid directory", func(t *testing.T) {
+		file := filepath.Join(dir, "file")
+		if err := ioutil.WriteFile(file, nil, 0666); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := newStepFiles(filepath.Join(file, StepsDir))
+		assert.NotNil(t, err)
+	})
+}
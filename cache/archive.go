@@ -0,0 +1,178 @@
+package cache
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// archive writes the files in dir to writer as tar.gz with the paths relative to dir.
+func archive(dir string, writer io.Writer) error {
+	gw := gzip.NewWriter(writer)
+	tw := tar.NewWriter(gw)
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || rel == "." {
+			return err
+		}
+
+		link := ""
+		if info.Mode()&os.ModeSymlink != 0 {
+			link, err = os.Readlink(path)
+			if err != nil {
+				return err
+			}
+		}
+
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+
+		err = tw.WriteHeader(header)
+		if err != nil {
+			return err
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		_, err = io.Copy(tw, file)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to archive %s: %v", dir, err)
+	}
+
+	err = tw.Close()
+	if err != nil {
+		return err
+	}
+	return gw.Close()
+}
+
+// extract extracts tar.gz read from reader into dir.
+// The entries which are written or linked outside of dir, including through the symlinks in the archive, are refused.
+func extract(reader io.Reader, dir string) error {
+	gr, err := gzip.NewReader(reader)
+	if err != nil {
+		return fmt.Errorf("failed to extract into %s: %v", dir, err)
+	}
+	defer gr.Close()
+
+	err = os.MkdirAll(dir, 0777)
+	if err != nil {
+		return fmt.Errorf("failed to extract into %s: %v", dir, err)
+	}
+	root, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return fmt.Errorf("failed to extract into %s: %v", dir, err)
+	}
+
+	tr := tar.NewReader(gr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to extract into %s: %v", dir, err)
+		}
+
+		path := filepath.Join(dir, filepath.FromSlash(header.Name))
+		if !within(filepath.Clean(dir), path) {
+			return fmt.Errorf("failed to extract into %s: %s is outside of the directory", dir, header.Name)
+		}
+
+		if header.Typeflag == tar.TypeSymlink {
+			target := filepath.FromSlash(header.Linkname)
+			if filepath.IsAbs(target) || !within(filepath.Clean(dir), filepath.Join(filepath.Dir(path), target)) {
+				return fmt.Errorf("failed to extract into %s: link %s to %s is outside of the directory", dir, header.Name, header.Linkname)
+			}
+		}
+
+		parent, err := resolveExisting(filepath.Dir(path))
+		if err != nil {
+			return fmt.Errorf("failed to extract into %s: %v", dir, err)
+		}
+		if !within(root, parent) {
+			return fmt.Errorf("failed to extract into %s: %s is written outside of the directory through a link", dir, header.Name)
+		}
+
+		err = extractEntry(tr, header, path)
+		if err != nil {
+			return fmt.Errorf("failed to extract into %s: %v", dir, err)
+		}
+	}
+}
+
+// within reports whether path is dir or in dir.
+func within(dir, path string) bool {
+	return path == dir || strings.HasPrefix(path, dir+string(os.PathSeparator))
+}
+
+// resolveExisting returns the path of the nearest existing ancestor of path, including itself, with its symlinks resolved.
+func resolveExisting(path string) (string, error) {
+	for {
+		resolved, err := filepath.EvalSymlinks(path)
+		if err == nil {
+			return resolved, nil
+		}
+		if !os.IsNotExist(err) || filepath.Dir(path) == path {
+			return "", err
+		}
+		path = filepath.Dir(path)
+	}
+}
+
+func extractEntry(tr *tar.Reader, header *tar.Header, path string) error {
+	mode := os.FileMode(header.Mode).Perm()
+
+	switch header.Typeflag {
+	case tar.TypeDir:
+		return os.MkdirAll(path, mode|0700)
+	case tar.TypeSymlink:
+		err := os.MkdirAll(filepath.Dir(path), 0777)
+		if err != nil {
+			return err
+		}
+		os.Remove(path)
+		return os.Symlink(header.Linkname, path)
+	case tar.TypeReg:
+		err := os.MkdirAll(filepath.Dir(path), 0777)
+		if err != nil {
+			return err
+		}
+		// The file is not written through the symlink extracted at the same path
+		if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSymlink != 0 {
+			os.Remove(path)
+		}
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		_, err = io.Copy(file, tr)
+		return err
+	}
+
+	return nil
+}
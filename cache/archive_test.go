@@ -0,0 +1,123 @@
+package cache
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArchiveAndExtract(t *testing.T) {
+	dir, err := ioutil.TempDir("", "archive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	src := filepath.Join(dir, "src")
+	assert.Nil(t, os.MkdirAll(filepath.Join(src, "bin"), 0777))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(src, "bin", "tool"), []byte("#!/bin/sh"), 0755))
+	assert.Nil(t, os.Symlink("bin/tool", filepath.Join(src, "tool")))
+
+	buf := bytes.NewBuffer(nil)
+	assert.Nil(t, archive(src, buf))
+
+	dst := filepath.Join(dir, "dst")
+	assert.Nil(t, extract(buf, dst))
+
+	info, err := os.Stat(filepath.Join(dst, "bin", "tool"))
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+
+	link, err := os.Readlink(filepath.Join(dst, "tool"))
+	assert.Nil(t, err)
+	assert.Equal(t, "bin/tool", link)
+}
+
+func TestExtractOutsideOfDirectory(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	gw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gw)
+	assert.Nil(t, tw.WriteHeader(&tar.Header{Name: "../evil", Mode: 0644, Size: 0, Typeflag: tar.TypeReg}))
+	assert.Nil(t, tw.Close())
+	assert.Nil(t, gw.Close())
+
+	dir, err := ioutil.TempDir("", "archive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	err = extract(buf, filepath.Join(dir, "dst"))
+	assert.Contains(t, err.Error(), "../evil is outside of the directory")
+}
+
+func TestExtractLinkOutsideOfDirectory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "archive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	outside := filepath.Join(dir, "outside")
+	assert.Nil(t, os.MkdirAll(outside, 0777))
+
+	testCases := []struct {
+		name    string
+		headers []*tar.Header
+		message string
+	}{
+		{
+			name: "absolute link",
+			headers: []*tar.Header{
+				{Name: "link", Linkname: outside, Typeflag: tar.TypeSymlink},
+				{Name: "link/evil", Mode: 0644, Typeflag: tar.TypeReg},
+			},
+			message: "link link to " + outside + " is outside of the directory",
+		},
+		{
+			name: "relative link",
+			headers: []*tar.Header{
+				{Name: "sub/link", Linkname: "../../outside", Typeflag: tar.TypeSymlink},
+				{Name: "sub/link/evil", Mode: 0644, Typeflag: tar.TypeReg},
+			},
+			message: "link sub/link to ../../outside is outside of the directory",
+		},
+		{
+			name: "chained links",
+			headers: []*tar.Header{
+				{Name: "a/b", Linkname: "..", Typeflag: tar.TypeSymlink},
+				{Name: "link", Linkname: "a/b/../outside", Typeflag: tar.TypeSymlink},
+				{Name: "link/evil", Mode: 0644, Typeflag: tar.TypeReg},
+			},
+			message: "link/evil is written outside of the directory through a link",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := bytes.NewBuffer(nil)
+			gw := gzip.NewWriter(buf)
+			tw := tar.NewWriter(gw)
+			for _, h := range tt.headers {
+				assert.Nil(t, tw.WriteHeader(h))
+			}
+			assert.Nil(t, tw.Close())
+			assert.Nil(t, gw.Close())
+
+			dst := filepath.Join(dir, "dst")
+			defer os.RemoveAll(dst)
+
+			err := extract(buf, dst)
+			assert.NotNil(t, err)
+			assert.Contains(t, err.Error(), tt.message)
+			_, err = os.Stat(filepath.Join(outside, "evil"))
+			assert.True(t, os.IsNotExist(err))
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package cache
+
+import (
+	"fmt"
+
+	"github.com/screwdriver-cd/sd-local/config"
+)
+
+const (
+	// LocalBackend keeps caches only in the local directory
+	LocalBackend = "local"
+	// S3Backend shares caches through S3 or S3 compatible storage
+	S3Backend = "s3"
+)
+
+// Backend restores and stores the cache directories remotely
+type Backend interface {
+	// Restore downloads the cache stored as key into dir. It does nothing if the cache is not stored yet.
+	Restore(key, dir string) error
+	// Store uploads the files in dir as key.
+	Store(key, dir string) error
+}
+
+type localBackend struct{}
+
+var _ Backend = (*localBackend)(nil)
+
+func (b *localBackend) Restore(key, dir string) error { return nil }
+
+func (b *localBackend) Store(key, dir string) error { return nil }
+
+// NewBackend creates the Backend set in the config
+func NewBackend(entry config.Entry) (Backend, error) {
+	switch entry.CacheBackend {
+	case "", LocalBackend:
+		return &localBackend{}, nil
+	case S3Backend:
+		if entry.CacheBucket == "" {
+			return nil, fmt.Errorf("`cache-bucket` must be set to use %s cache backend", S3Backend)
+		}
+		return newS3(entry.CacheBucket, entry.CacheEndpoint, entry.CacheRegion)
+	default:
+		return nil, fmt.Errorf("invalid cache backend %s", entry.CacheBackend)
+	}
+}
@@ -0,0 +1,38 @@
+package cache
+
+import (
+	"os"
+	"testing"
+
+	"github.com/screwdriver-cd/sd-local/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewBackend(t *testing.T) {
+	os.Setenv("AWS_ACCESS_KEY_ID", "access-key")
+	os.Setenv("AWS_SECRET_ACCESS_KEY", "secret-key")
+	defer os.Unsetenv("AWS_ACCESS_KEY_ID")
+	defer os.Unsetenv("AWS_SECRET_ACCESS_KEY")
+
+	t.Run("success with default", func(t *testing.T) {
+		b, err := NewBackend(config.Entry{})
+		assert.Nil(t, err)
+		assert.IsType(t, &localBackend{}, b)
+	})
+
+	t.Run("success with s3", func(t *testing.T) {
+		b, err := NewBackend(config.Entry{CacheBackend: "s3", CacheBucket: "bucket", CacheEndpoint: "http://minio:9000"})
+		assert.Nil(t, err)
+		assert.Equal(t, "http://minio:9000", b.(*s3).endpoint)
+	})
+
+	t.Run("failure with s3 without bucket", func(t *testing.T) {
+		_, err := NewBackend(config.Entry{CacheBackend: "s3"})
+		assert.Equal(t, "`cache-bucket` must be set to use s3 cache backend", err.Error())
+	})
+
+	t.Run("failure with unknown backend", func(t *testing.T) {
+		_, err := NewBackend(config.Entry{CacheBackend: "gcs"})
+		assert.Equal(t, "invalid cache backend gcs", err.Error())
+	})
+}
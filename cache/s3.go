@@ -0,0 +1,215 @@
+package cache
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	defaultRegion    = "us-east-1"
+	signingAlgorithm = "AWS4-HMAC-SHA256"
+	emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+var now = time.Now
+
+type credentials struct {
+	accessKeyID     string
+	secretAccessKey string
+	sessionToken    string
+}
+
+type s3 struct {
+	HTTPClient  *http.Client
+	endpoint    string
+	bucket      string
+	region      string
+	credentials credentials
+}
+
+var _ Backend = (*s3)(nil)
+
+// newS3 creates a Backend with S3. The endpoint is for S3 compatible storage such as MinIO.
+// The credentials are read from the environment variables as AWS CLI does.
+func newS3(bucket, endpoint, region string) (Backend, error) {
+	if region == "" {
+		region = defaultRegion
+	}
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", region)
+	}
+
+	c := credentials{
+		accessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		secretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		sessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+	if c.accessKeyID == "" || c.secretAccessKey == "" {
+		return nil, errors.New("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set to use s3 cache backend")
+	}
+
+	return &s3{
+		HTTPClient:  http.DefaultClient,
+		endpoint:    endpoint,
+		bucket:      bucket,
+		region:      region,
+		credentials: c,
+	}, nil
+}
+
+// objectURL returns the path style URL of the object
+func (s *s3) objectURL(key string) (*url.URL, error) {
+	u, err := url.Parse(s.endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request url: %v", err)
+	}
+	u.Path = path.Join(u.Path, s.bucket, key+".tar.gz")
+
+	return u, nil
+}
+
+func (s *s3) Restore(key, dir string) error {
+	u, err := s.objectURL(key)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	s.sign(req, emptyPayloadHash)
+
+	res, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to restore cache %s: StatusCode %d", key, res.StatusCode)
+	}
+
+	return extract(res.Body, dir)
+}
+
+func (s *s3) Store(key, dir string) error {
+	u, err := s.objectURL(key)
+	if err != nil {
+		return err
+	}
+
+	file, err := ioutil.TempFile("", "sd-local-cache")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	hash := sha256.New()
+	err = archive(dir, io.MultiWriter(file, hash))
+	if err != nil {
+		return err
+	}
+
+	size, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	_, err = file.Seek(0, io.SeekStart)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPut, u.String(), file)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "application/gzip")
+	s.sign(req, hex.EncodeToString(hash.Sum(nil)))
+
+	res, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to store cache %s: StatusCode %d", key, res.StatusCode)
+	}
+
+	return nil
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// sign signs the request with AWS Signature Version 4. All headers of the request are signed.
+func (s *s3) sign(req *http.Request, payloadHash string) {
+	t := now().UTC()
+	amzDate := t.Format("20060102T150405Z")
+	date := t.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if s.credentials.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.credentials.sessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		headers[strings.ToLower(k)] = strings.TrimSpace(strings.Join(v, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	canonicalHeaders := ""
+	for _, k := range names {
+		canonicalHeaders += k + ":" + headers[k] + "\n"
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := fmt.Sprintf("%s/%s/s3/aws4_request", date, s.region)
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{signingAlgorithm, amzDate, scope, hex.EncodeToString(requestHash[:])}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.credentials.secretAccessKey), date)
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		signingAlgorithm, s.credentials.accessKeyID, scope, signedHeaders, signature))
+}
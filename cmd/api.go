@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/screwdriver-cd/sd-local/config"
+	"github.com/screwdriver-cd/sd-local/screwdriver"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// defaultAPIRetries is the number of times the requests to Screwdriver.cd API are retried unless it is set in the config.
+	defaultAPIRetries = 3
+	// defaultAPITimeout is the time limit of each request to Screwdriver.cd API unless it is set in the config.
+	defaultAPITimeout = 60 * time.Second
+)
+
+// apiOption returns the settings of the client of Screwdriver.cd API in the config, which caches JWT in sdlocalDir.
+// The values are validated when they are set, so the invalid ones are ignored.
+func apiOption(entry *config.Entry, sdlocalDir string) (screwdriver.Option, error) {
+	option := screwdriver.Option{
+		Retries:     defaultAPIRetries,
+		Timeout:     defaultAPITimeout,
+		TokenType:   entry.TokenType,
+		JWTCacheDir: filepath.Join(sdlocalDir, jwtDirName),
+	}
+	if n, err := strconv.Atoi(entry.APIRetries); err == nil {
+		option.Retries = n
+	}
+	if d, err := time.ParseDuration(entry.APITimeout); err == nil {
+		option.Timeout = d
+	}
+	if d, err := time.ParseDuration(entry.APIKeepAlive); err == nil {
+		option.KeepAlive = d
+		option.DisableKeepAlives = d == 0
+	}
+	if u, err := url.Parse(entry.APIProxy); err == nil && entry.APIProxy != "" {
+		option.Proxy = u
+	}
+
+	if entry.APICACert != "" || entry.APIClientCert != "" || entry.APIClientKey != "" || entry.InsecureSkipTLSVerify {
+		tlsConfig, err := screwdriver.NewTLSConfig(entry.APICACert, entry.APIClientCert, entry.APIClientKey, entry.InsecureSkipTLSVerify)
+		if err != nil {
+			return screwdriver.Option{}, err
+		}
+		option.TLSConfig = tlsConfig
+	}
+	if entry.InsecureSkipTLSVerify {
+		logrus.Warn("The certificate of Screwdriver.cd API is NOT verified because insecure-skip-tls-verify is set in the config. " +
+			"The token and the secrets can be stolen by man-in-the-middle attacks, so set api-ca-cert instead if possible.")
+	}
+
+	return option, nil
+}
+
+// fallbackAPI validates screwdriver.yaml locally when Screwdriver.cd API turns out to be unreachable.
+// JWT reused from the cache does not tell whether the API is reachable, so it is found on the validation.
+type fallbackAPI struct {
+	screwdriver.API
+	local screwdriver.API
+}
+
+func warnUnreachable(err error) {
+	logrus.Warnf("Screwdriver.cd API is unreachable, so screwdriver.yaml is validated locally: %v", err)
+}
+
+// Job returns the job validated by the API, or locally when the API is unreachable
+func (f *fallbackAPI) Job(jobName, filePath string) (screwdriver.Job, error) {
+	job, err := f.API.Job(jobName, filePath)
+	if screwdriver.IsUnreachable(err) {
+		warnUnreachable(err)
+		f.API = f.local
+		return f.local.Job(jobName, filePath)
+	}
+	return job, err
+}
+
+// Jobs returns the jobs validated by the API, or locally when the API is unreachable
+func (f *fallbackAPI) Jobs(filePath string) (map[string]screwdriver.Job, error) {
+	jobs, err := f.API.Jobs(filePath)
+	if screwdriver.IsUnreachable(err) {
+		warnUnreachable(err)
+		f.API = f.local
+		return f.local.Jobs(filePath)
+	}
+	return jobs, err
+}
+
+// Validate returns the result of the validation by the API, or of the local one when the API is unreachable
+func (f *fallbackAPI) Validate(filePath string) (screwdriver.Validation, error) {
+	v, err := f.API.Validate(filePath)
+	if screwdriver.IsUnreachable(err) {
+		warnUnreachable(err)
+		f.API = f.local
+		return f.local.Validate(filePath)
+	}
+	return v, err
+}
+
+// validatorAPI returns the API which validates screwdriver.yaml with the current config along with the resolved entry of the config.
+// screwdriver.yaml is validated locally in offline mode, or when Screwdriver.cd API is unreachable.
+func validatorAPI(offline bool) (screwdriver.API, config.Entry, error) {
+	home, err := homedir.Dir()
+	if err != nil {
+		return nil, config.Entry{}, err
+	}
+	sdlocalDir := filepath.Join(home, ".sdlocal")
+
+	c, err := configNew(filepath.Join(sdlocalDir, "config"))
+	if err != nil {
+		return nil, config.Entry{}, err
+	}
+	current, err := c.Entry(c.Current)
+	if err != nil {
+		return nil, config.Entry{}, err
+	}
+	entry := current.Resolved()
+
+	api := localAPINew(filepath.Join(sdlocalDir, templatesDirName))
+	if offline {
+		return api, entry, nil
+	}
+
+	option, err := apiOption(&entry, sdlocalDir)
+	if err != nil {
+		return nil, config.Entry{}, err
+	}
+	remote := apiNew(entry.APIURL, entry.Token, option)
+
+	err = remote.InitJWT()
+	if screwdriver.IsUnreachable(err) {
+		warnUnreachable(err)
+		return api, entry, nil
+	} else if err != nil {
+		return nil, config.Entry{}, err
+	}
+
+	return &fallbackAPI{API: remote, local: api}, entry, nil
+}
+
+// screwdriverYAMLPath returns the path of screwdriver.yaml in args, which defaults to the one in the current directory.
+func screwdriverYAMLPath(args []string) (string, error) {
+	if len(args) != 0 {
+		return args[0], nil
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cwd, "screwdriver.yaml"), nil
+}
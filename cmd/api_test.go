@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/screwdriver-cd/sd-local/config"
+	"github.com/screwdriver-cd/sd-local/screwdriver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIOption(t *testing.T) {
+	testCases := []struct {
+		name   string
+		entry  config.Entry
+		expect screwdriver.Option
+	}{
+		{name: "default", entry: config.Entry{}, expect: screwdriver.Option{JWTCacheDir: "/sdlocal/jwt", Retries: 3, Timeout: time.Minute}},
+		{name: "with retries", entry: config.Entry{APIRetries: "5"}, expect: screwdriver.Option{JWTCacheDir: "/sdlocal/jwt", Retries: 5, Timeout: time.Minute}},
+		{name: "without retries", entry: config.Entry{APIRetries: "0"}, expect: screwdriver.Option{JWTCacheDir: "/sdlocal/jwt", Retries: 0, Timeout: time.Minute}},
+		{
+			name:   "with timeout and keep-alive",
+			entry:  config.Entry{APITimeout: "30s", APIKeepAlive: "2m"},
+			expect: screwdriver.Option{JWTCacheDir: "/sdlocal/jwt", Retries: 3, Timeout: 30 * time.Second, KeepAlive: 2 * time.Minute},
+		},
+		{
+			name:   "without keep-alive",
+			entry:  config.Entry{APIKeepAlive: "0"},
+			expect: screwdriver.Option{JWTCacheDir: "/sdlocal/jwt", Retries: 3, Timeout: time.Minute, DisableKeepAlives: true},
+		},
+		{
+			name:   "with pipeline token",
+			entry:  config.Entry{TokenType: "pipeline"},
+			expect: screwdriver.Option{JWTCacheDir: "/sdlocal/jwt", Retries: 3, Timeout: time.Minute, TokenType: "pipeline"},
+		},
+		{
+			name:   "with proxy",
+			entry:  config.Entry{APIProxy: "http://proxy.example.com:8080"},
+			expect: screwdriver.Option{JWTCacheDir: "/sdlocal/jwt", Retries: 3, Timeout: time.Minute, Proxy: &url.URL{Scheme: "http", Host: "proxy.example.com:8080"}},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			option, err := apiOption(&tt.entry, "/sdlocal")
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expect, option)
+		})
+	}
+}
+
+func TestAPIOptionTLS(t *testing.T) {
+	t.Run("success with insecure-skip-tls-verify", func(t *testing.T) {
+		option, err := apiOption(&config.Entry{InsecureSkipTLSVerify: true}, "/sdlocal")
+		assert.Nil(t, err)
+		assert.True(t, option.TLSConfig.InsecureSkipVerify)
+	})
+
+	t.Run("failure with CA certificate", func(t *testing.T) {
+		_, err := apiOption(&config.Entry{APICACert: "/not/exist/ca.crt"}, "/sdlocal")
+		assert.Equal(t, "failed to read CA certificate: open /not/exist/ca.crt: no such file or directory", err.Error())
+	})
+
+	t.Run("failure with client certificate without key", func(t *testing.T) {
+		_, err := apiOption(&config.Entry{APIClientCert: "client.crt"}, "/sdlocal")
+		assert.Equal(t, fmt.Errorf("both of client certificate and its key must be set"), err)
+	})
+}
+
+type mockUnreachableValidatorAPI struct {
+	mockAPI
+}
+
+func (mock mockUnreachableValidatorAPI) Job(jobName, filePath string) (screwdriver.Job, error) {
+	return screwdriver.Job{}, fmt.Errorf("failed to send request: %w", &url.Error{Op: "Post", URL: "http://localhost", Err: fmt.Errorf("connection refused")})
+}
+
+func (mock mockUnreachableValidatorAPI) Jobs(filePath string) (map[string]screwdriver.Job, error) {
+	_, err := mock.Job("", filePath)
+	return nil, err
+}
+
+type mockLocalValidationAPI struct {
+	mockAPI
+}
+
+func (mock mockLocalValidationAPI) Job(jobName, filePath string) (screwdriver.Job, error) {
+	return screwdriver.Job{Image: "local"}, nil
+}
+
+func (mock mockLocalValidationAPI) Jobs(filePath string) (map[string]screwdriver.Job, error) {
+	return map[string]screwdriver.Job{"main": {Image: "local"}}, nil
+}
+
+func TestFallbackAPI(t *testing.T) {
+	t.Run("success with reachable API", func(t *testing.T) {
+		api := &fallbackAPI{API: mockAPI{}, local: mockLocalValidationAPI{}}
+		job, err := api.Job("main", "screwdriver.yaml")
+		assert.Nil(t, err)
+		assert.NotEqual(t, "local", job.Image)
+	})
+
+	t.Run("success with unreachable API", func(t *testing.T) {
+		api := &fallbackAPI{API: mockUnreachableValidatorAPI{}, local: mockLocalValidationAPI{}}
+		job, err := api.Job("main", "screwdriver.yaml")
+		assert.Nil(t, err)
+		assert.Equal(t, "local", job.Image)
+		assert.Equal(t, mockLocalValidationAPI{}, api.API)
+	})
+
+	t.Run("success with unreachable API for jobs", func(t *testing.T) {
+		api := &fallbackAPI{API: mockUnreachableValidatorAPI{}, local: mockLocalValidationAPI{}}
+		jobs, err := api.Jobs("screwdriver.yaml")
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]screwdriver.Job{"main": {Image: "local"}}, jobs)
+	})
+}
+
+func (mock mockUnreachableValidatorAPI) Validate(filePath string) (screwdriver.Validation, error) {
+	_, err := mock.Job("", filePath)
+	return screwdriver.Validation{}, err
+}
+
+func (mock mockLocalValidationAPI) Validate(filePath string) (screwdriver.Validation, error) {
+	return screwdriver.Validation{Errors: []string{"job 'main' has no steps"}}, nil
+}
+
+func TestFallbackAPIValidate(t *testing.T) {
+	api := &fallbackAPI{API: mockUnreachableValidatorAPI{}, local: mockLocalValidationAPI{}}
+	v, err := api.Validate("screwdriver.yaml")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"job 'main' has no steps"}, v.Errors)
+	assert.Equal(t, mockLocalValidationAPI{}, api.API)
+}
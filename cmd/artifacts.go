@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/screwdriver-cd/sd-local/launch"
+	"github.com/sirupsen/logrus"
+)
+
+var readArtifacts = launch.ReadArtifacts
+
+// printArtifactSummary prints the files written into the artifacts directory since the build started, with their sizes.
+func printArtifactSummary(out io.Writer, artifactsPath string, since time.Time) {
+	artifacts, err := readArtifacts(artifactsPath, since)
+	if err != nil {
+		logrus.Warn(err)
+		return
+	}
+	if len(artifacts) == 0 {
+		return
+	}
+
+	fmt.Fprintf(out, "\nArtifacts in %s\n", artifactsPath)
+	tw := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "ARTIFACT\tSIZE")
+	var total int64
+	for _, a := range artifacts {
+		fmt.Fprintf(tw, "%s\t%s\n", a.Path, launch.FormatSize(a.Size))
+		total += a.Size
+	}
+	fmt.Fprintf(tw, "TOTAL\t%s\n", launch.FormatSize(total))
+	tw.Flush()
+}
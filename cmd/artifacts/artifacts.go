@@ -0,0 +1,57 @@
+package artifacts
+
+import (
+	"path/filepath"
+
+	"github.com/screwdriver-cd/sd-local/launch"
+	"github.com/spf13/cobra"
+)
+
+var (
+	artifactsDir = launch.ArtifactsDir
+	jobName      = ""
+)
+
+// artifactsPath returns the artifacts directory of the build.
+// The artifacts of a job run with multiple jobs are stored under the sub directory named after the job.
+func artifactsPath() string {
+	if jobName != "" {
+		return filepath.Join(artifactsDir, jobName)
+	}
+	return artifactsDir
+}
+
+// NewArtifactsCmd return artifacts command.
+func NewArtifactsCmd() *cobra.Command {
+	artifactsCmd := &cobra.Command{
+		Use:   "artifacts",
+		Short: "Browse artifacts of local builds.",
+		Long: `Browse artifacts of local builds.
+The artifacts directory is overwritten by the next build, so the artifacts of the last build are browsed.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := cmd.Help()
+			if err != nil {
+				return err
+			}
+			return nil
+		},
+	}
+
+	artifactsCmd.PersistentFlags().StringVar(
+		&artifactsDir,
+		"artifacts-dir",
+		launch.ArtifactsDir,
+		"Path to the host side directory which is mounted into $SD_ARTIFACTS_DIR.")
+
+	artifactsCmd.PersistentFlags().StringVar(
+		&jobName,
+		"job",
+		"",
+		"Name of the job run with multiple jobs. Its artifacts are stored separately.")
+
+	artifactsCmd.AddCommand(
+		newArtifactsServeCmd(),
+	)
+
+	return artifactsCmd
+}
@@ -0,0 +1,197 @@
+package artifacts
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/screwdriver-cd/sd-local/launch"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var listenAndServe = http.ListenAndServe
+
+var pageTemplate = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}} - sd-local artifacts</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+td, th { padding: 0.2em 1em; text-align: left; }
+pre { background: #222; color: #eee; padding: 1em; overflow-x: auto; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{if .Parent}}<p><a href="{{.Parent}}">../</a></p>{{end}}
+{{if .Entries}}<table>
+<tr><th>NAME</th><th>SIZE</th><th>MODIFIED</th></tr>
+{{range .Entries}}<tr><td><a href="{{.Link}}">{{.Name}}</a></td><td>{{.Size}}</td><td>{{.Modified}}</td></tr>
+{{end}}</table>{{end}}
+{{if .Steps}}<p><a href="?raw">raw</a></p>
+{{range .Steps}}<h3>{{.Name}}</h3>
+<pre>{{range .Lines}}{{.}}
+{{end}}</pre>
+{{end}}{{end}}
+</body>
+</html>
+`))
+
+type entry struct {
+	Name     string
+	Link     string
+	Size     string
+	Modified string
+}
+
+type step struct {
+	Name  string
+	Lines []string
+}
+
+type page struct {
+	Title   string
+	Parent  string
+	Entries []entry
+	Steps   []step
+}
+
+// readEntries lists the files in the directory. The directories come first.
+func readEntries(dir, urlPath string) ([]entry, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(infos, func(i, j int) bool {
+		return infos[i].IsDir() && !infos[j].IsDir()
+	})
+
+	entries := make([]entry, 0, len(infos))
+	for _, info := range infos {
+		e := entry{
+			Name:     info.Name(),
+			Link:     path.Join(urlPath, info.Name()),
+			Size:     launch.FormatSize(info.Size()),
+			Modified: info.ModTime().Format("2006-01-02 15:04:05"),
+		}
+		if info.IsDir() {
+			e.Name += "/"
+			e.Size = "-"
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// readLogSteps reads the build log, which is written by the launcher as JSON lines, and groups its lines by step.
+func readLogSteps(filePath string) ([]step, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	steps := make([]step, 0)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var line struct {
+			Message  string `json:"m"`
+			StepName string `json:"s"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			continue
+		}
+		if len(steps) == 0 || steps[len(steps)-1].Name != line.StepName {
+			steps = append(steps, step{Name: line.StepName})
+		}
+		steps[len(steps)-1].Lines = append(steps[len(steps)-1].Lines, line.Message)
+	}
+
+	return steps, scanner.Err()
+}
+
+// newHandler returns the handler which renders the directories and the build log in root, and serves the other files as they are.
+func newHandler(root string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		urlPath := path.Clean("/" + r.URL.Path)
+		filePath := filepath.Join(root, filepath.FromSlash(urlPath))
+
+		info, err := os.Stat(filePath)
+		if os.IsNotExist(err) {
+			http.NotFound(w, r)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		_, raw := r.URL.Query()["raw"]
+		if !info.IsDir() && (info.Name() != launch.LogFile || raw) {
+			http.ServeFile(w, r, filePath)
+			return
+		}
+
+		p := page{Title: urlPath}
+		if urlPath != "/" {
+			p.Parent = path.Dir(urlPath)
+		}
+		if info.IsDir() {
+			p.Entries, err = readEntries(filePath, urlPath)
+		} else {
+			p.Steps, err = readLogSteps(filePath)
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := pageTemplate.Execute(w, p); err != nil {
+			logrus.Warn(err)
+		}
+	})
+}
+
+func newArtifactsServeCmd() *cobra.Command {
+	var addr string
+
+	artifactsServeCmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve artifacts over HTTP.",
+		Long: `Start an HTTP server which renders the artifacts directory of the last build.
+The build log is shown with its steps, and the other files are served as they are.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			root := artifactsPath()
+			if _, err := os.Stat(root); err != nil {
+				return fmt.Errorf("failed to read artifacts directory: %v", err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Serving %s on http://%s\n", root, addr)
+			return listenAndServe(addr, newHandler(root))
+		},
+	}
+
+	artifactsServeCmd.Flags().StringVar(
+		&addr,
+		"addr",
+		"localhost:8080",
+		"Address to listen on.")
+
+	return artifactsServeCmd
+}
@@ -0,0 +1,149 @@
+package artifacts
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewHandler(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sd-artifacts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	log := `{"t":1,"m":"npm install","n":1,"s":"install"}
+{"t":2,"m":"added 1 package","n":2,"s":"install"}
+{"t":3,"m":"npm test","n":1,"s":"test"}
+`
+	if err := os.MkdirAll(filepath.Join(dir, "reports"), 0777); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "builds.log"), []byte(log), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "reports", "result.txt"), []byte("ok"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name       string
+		url        string
+		wantStatus int
+		wantBody   []string
+	}{
+		{
+			name:       "success with directory",
+			url:        "/",
+			wantStatus: http.StatusOK,
+			wantBody:   []string{`<a href="/reports">reports/</a>`, `<a href="/builds.log">builds.log</a>`},
+		},
+		{
+			name:       "success with sub directory",
+			url:        "/reports",
+			wantStatus: http.StatusOK,
+			wantBody:   []string{`<a href="/">../</a>`, `<a href="/reports/result.txt">result.txt</a>`, "2 B"},
+		},
+		{
+			name:       "success with build log",
+			url:        "/builds.log",
+			wantStatus: http.StatusOK,
+			wantBody:   []string{"<h3>install</h3>\n<pre>npm install\nadded 1 package\n</pre>", "<h3>test</h3>\n<pre>npm test\n</pre>"},
+		},
+		{
+			name:       "success with raw build log",
+			url:        "/builds.log?raw",
+			wantStatus: http.StatusOK,
+			wantBody:   []string{log},
+		},
+		{
+			name:       "success with file",
+			url:        "/reports/result.txt",
+			wantStatus: http.StatusOK,
+			wantBody:   []string{"ok"},
+		},
+		{
+			name:       "failure by not existing file",
+			url:        "/none",
+			wantStatus: http.StatusNotFound,
+			wantBody:   []string{"404 page not found"},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			newHandler(dir).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.url, nil))
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			for _, want := range tt.wantBody {
+				assert.Contains(t, rec.Body.String(), want)
+			}
+		})
+	}
+}
+
+func TestArtifactsServeCmd(t *testing.T) {
+	defer func() {
+		listenAndServe = http.ListenAndServe
+	}()
+
+	testCases := []struct {
+		name     string
+		args     []string
+		wantAddr string
+		checkErr bool
+	}{
+		{
+			name:     "success",
+			args:     []string{"serve", "--artifacts-dir", "."},
+			wantAddr: "localhost:8080",
+			checkErr: false,
+		},
+		{
+			name:     "success with addr",
+			args:     []string{"serve", "--artifacts-dir", ".", "--addr", ":9000"},
+			wantAddr: ":9000",
+			checkErr: false,
+		},
+		{
+			name:     "failure by not existing artifacts dir",
+			args:     []string{"serve", "--artifacts-dir", "./none"},
+			checkErr: true,
+		},
+		{
+			name:     "failure by too many args",
+			args:     []string{"serve", "foo"},
+			checkErr: true,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			var addr string
+			listenAndServe = func(a string, handler http.Handler) error {
+				addr = a
+				return nil
+			}
+
+			cmd := NewArtifactsCmd()
+			cmd.SetArgs(tt.args)
+			buf := bytes.NewBuffer(nil)
+			cmd.SetOut(buf)
+			err := cmd.Execute()
+			if tt.checkErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.wantAddr, addr)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/screwdriver-cd/sd-local/launch"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrintArtifactSummary(t *testing.T) {
+	defer func() {
+		readArtifacts = launch.ReadArtifacts
+	}()
+
+	since := time.Date(2020, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	t.Run("success", func(t *testing.T) {
+		readArtifacts = func(artifactsPath string, s time.Time) ([]launch.Artifact, error) {
+			assert.Equal(t, "/sd-artifacts", artifactsPath)
+			assert.Equal(t, since, s)
+			return []launch.Artifact{
+				{Path: "builds.log", Size: 2048},
+				{Path: "reports/junit.xml", Size: 512},
+			}, nil
+		}
+
+		buf := bytes.NewBuffer(nil)
+		printArtifactSummary(buf, "/sd-artifacts", since)
+		assert.Equal(t, "\nArtifacts in /sd-artifacts\nARTIFACT           SIZE\nbuilds.log         2.0 KB\nreports/junit.xml  512 B\nTOTAL              2.5 KB\n", buf.String())
+	})
+
+	t.Run("success without artifacts", func(t *testing.T) {
+		readArtifacts = func(artifactsPath string, s time.Time) ([]launch.Artifact, error) { return nil, nil }
+
+		buf := bytes.NewBuffer(nil)
+		printArtifactSummary(buf, "/sd-artifacts", since)
+		assert.Equal(t, "", buf.String())
+	})
+
+	t.Run("failure by reading artifacts", func(t *testing.T) {
+		readArtifacts = func(artifactsPath string, s time.Time) ([]launch.Artifact, error) {
+			return nil, errors.New("failed to read artifacts")
+		}
+
+		buf := bytes.NewBuffer(nil)
+		printArtifactSummary(buf, "/sd-artifacts", since)
+		assert.Equal(t, "", buf.String())
+	})
+}
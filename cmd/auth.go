@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/screwdriver-cd/sd-local/config"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// accessTokensPath is the page of Screwdriver.cd UI where the user creates the access tokens
+const accessTokensPath = "/user-settings/access-tokens"
+
+var openBrowser = defaultOpenBrowser
+
+func defaultOpenBrowser(url string) error {
+	name := "xdg-open"
+	if goos == "darwin" {
+		name = "open"
+	}
+	return execCommand(name, url).Start()
+}
+
+// login opens the page of the access tokens in the browser, and sets the token pasted by the user into entry after checking it.
+// Screwdriver.cd API has no flow to issue a token to CLI, so the token created in the browser is pasted.
+func login(out io.Writer, entry *config.Entry, sdlocalDir, uiURL string) error {
+	if entry.APIURL == "" {
+		return errors.New("api-url must be set to log in, run `sd-local config set api-url <url>`")
+	}
+
+	url := strings.TrimSuffix(uiURL, "/") + accessTokensPath
+	fmt.Fprintf(out, "Log in to Screwdriver.cd at %s, create an access token, and paste it here.\n", url)
+	if err := openBrowser(url); err != nil {
+		logrus.Warnf("failed to open the browser, open the URL manually: %v", err)
+	}
+
+	fmt.Fprint(out, "Token: ")
+	input, err := bufio.NewReader(stdin).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return err
+	}
+	token := strings.TrimSpace(input)
+	if token == "" {
+		return errors.New("token is empty")
+	}
+
+	// The access tokens created in the browser are user tokens
+	checked := *entry
+	checked.Token = token
+	checked.TokenType = ""
+	option, err := apiOption(&checked, sdlocalDir)
+	if err != nil {
+		return err
+	}
+	if err := apiNew(checked.APIURL, checked.Token, option).InitJWT(); err != nil {
+		return fmt.Errorf("failed to log in: %v", err)
+	}
+
+	*entry = checked
+	fmt.Fprintf(out, "Logged in to %s\n", entry.APIURL)
+
+	return nil
+}
+
+func newAuthLoginCmd() *cobra.Command {
+	var uiURL string
+
+	authLoginCmd := &cobra.Command{
+		Use:   "login",
+		Short: "Log in to Screwdriver.cd.",
+		Long: `Log in to Screwdriver.cd in the browser, and store the access token in the current config.
+The page to create an access token is opened in the browser, and the token pasted is checked with Screwdriver.cd API.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if uiURL == "" {
+				return errors.New("`ui-url` must be specified")
+			}
+			cmd.SilenceUsage = true
+
+			home, err := homedir.Dir()
+			if err != nil {
+				return err
+			}
+
+			sdlocalDir := filepath.Join(home, ".sdlocal")
+			config, err := configNew(filepath.Join(sdlocalDir, "config"))
+			if err != nil {
+				return err
+			}
+
+			entry, err := config.Entry(config.Current)
+			if err != nil {
+				return err
+			}
+
+			if err := login(cmd.OutOrStdout(), entry, sdlocalDir, uiURL); err != nil {
+				return err
+			}
+
+			return config.Save()
+		},
+	}
+
+	authLoginCmd.Flags().StringVar(
+		&uiURL,
+		"ui-url",
+		"",
+		"URL of Screwdriver.cd UI to log in (e.g. https://cd.screwdriver.cd).")
+
+	return authLoginCmd
+}
+
+func newAuthCmd() *cobra.Command {
+	authCmd := &cobra.Command{
+		Use:   "auth",
+		Short: "Authenticate with Screwdriver.cd.",
+		Long:  `Authenticate with Screwdriver.cd, and store the token in the current config.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := cmd.Help()
+			if err != nil {
+				return err
+			}
+			return nil
+		},
+	}
+
+	authCmd.AddCommand(
+		newAuthLoginCmd(),
+	)
+
+	return authCmd
+}
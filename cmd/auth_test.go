@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/screwdriver-cd/sd-local/config"
+	"github.com/screwdriver-cd/sd-local/screwdriver"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockLoginAPI struct {
+	mockAPI
+	err error
+}
+
+func (mock mockLoginAPI) InitJWT() error { return mock.err }
+
+func TestLogin(t *testing.T) {
+	origAPINew := apiNew
+	t.Cleanup(func() { apiNew = origAPINew })
+	defer func() {
+		stdin = os.Stdin
+		openBrowser = defaultOpenBrowser
+	}()
+
+	testCases := []struct {
+		name       string
+		entry      config.Entry
+		input      string
+		browserErr error
+		apiErr     error
+		expect     config.Entry
+		out        string
+		err        error
+	}{
+		{
+			name:   "success",
+			entry:  config.Entry{APIURL: "https://api.screwdriver.cd", Token: "old", TokenType: "pipeline"},
+			input:  "new-token\n",
+			expect: config.Entry{APIURL: "https://api.screwdriver.cd", Token: "new-token"},
+			out: "Log in to Screwdriver.cd at https://cd.screwdriver.cd/user-settings/access-tokens, create an access token, and paste it here.\n" +
+				"Token: Logged in to https://api.screwdriver.cd\n",
+		},
+		{
+			name:       "success without browser",
+			entry:      config.Entry{APIURL: "https://api.screwdriver.cd"},
+			input:      "new-token",
+			browserErr: errors.New("executable file not found"),
+			expect:     config.Entry{APIURL: "https://api.screwdriver.cd", Token: "new-token"},
+			out: "Log in to Screwdriver.cd at https://cd.screwdriver.cd/user-settings/access-tokens, create an access token, and paste it here.\n" +
+				"Token: Logged in to https://api.screwdriver.cd\n",
+		},
+		{
+			name:   "failure without api-url",
+			entry:  config.Entry{},
+			expect: config.Entry{},
+			err:    errors.New("api-url must be set to log in, run `sd-local config set api-url <url>`"),
+		},
+		{
+			name:   "failure with empty token",
+			entry:  config.Entry{APIURL: "https://api.screwdriver.cd", Token: "old"},
+			input:  "\n",
+			expect: config.Entry{APIURL: "https://api.screwdriver.cd", Token: "old"},
+			out:    "Log in to Screwdriver.cd at https://cd.screwdriver.cd/user-settings/access-tokens, create an access token, and paste it here.\nToken: ",
+			err:    errors.New("token is empty"),
+		},
+		{
+			name:   "failure with invalid token",
+			entry:  config.Entry{APIURL: "https://api.screwdriver.cd", Token: "old"},
+			input:  "invalid\n",
+			apiErr: errors.New("failed to get JWT: StatusCode 401"),
+			expect: config.Entry{APIURL: "https://api.screwdriver.cd", Token: "old"},
+			out:    "Log in to Screwdriver.cd at https://cd.screwdriver.cd/user-settings/access-tokens, create an access token, and paste it here.\nToken: ",
+			err:    fmt.Errorf("failed to log in: failed to get JWT: StatusCode 401"),
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			var opened, token string
+			openBrowser = func(url string) error {
+				opened = url
+				return tt.browserErr
+			}
+			apiNew = func(url, tok string, option screwdriver.Option) screwdriver.API {
+				token = tok
+				return mockLoginAPI{err: tt.apiErr}
+			}
+			stdin = strings.NewReader(tt.input)
+
+			out := bytes.NewBuffer(nil)
+			entry := tt.entry
+			err := login(out, &entry, "/sdlocal", "https://cd.screwdriver.cd/")
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.expect, entry)
+			assert.Equal(t, tt.out, out.String())
+			if tt.input != "" && tt.input != "\n" {
+				assert.Equal(t, "https://cd.screwdriver.cd/user-settings/access-tokens", opened)
+				assert.Equal(t, strings.TrimSpace(tt.input), token)
+			}
+		})
+	}
+}
@@ -1,20 +1,27 @@
 package cmd
 
 import (
+	"bufio"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/mitchellh/go-homedir"
 	"github.com/screwdriver-cd/sd-local/buildlog"
+	"github.com/screwdriver-cd/sd-local/cache"
 	"github.com/screwdriver-cd/sd-local/config"
 	"github.com/screwdriver-cd/sd-local/launch"
 	"github.com/screwdriver-cd/sd-local/scm"
 	"github.com/screwdriver-cd/sd-local/screwdriver"
+	"github.com/screwdriver-cd/sd-local/secrets"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
@@ -22,16 +29,34 @@ import (
 var (
 	configNew       = config.New
 	apiNew          = screwdriver.New
+	localAPINew     = screwdriver.NewLocal
 	buildLogNew     = buildlog.New
+	maskFile        = buildlog.MaskFile
+	vaultNew        = secrets.NewVaultProvider
+	readCache       = screwdriver.ReadCache
+	readParameters  = screwdriver.ReadParameters
 	launchNew       = launch.New
+	launchPlan      = launch.NewPlan
 	artifactsDir    = launch.ArtifactsDir
 	memory          = ""
+	logFormat       = string(buildlog.FormatText)
 	scmNew          = scm.New
 	osMkdirAll      = os.MkdirAll
+	readMeta        = launch.ReadMeta
+	readProject     = config.ReadProject
+	checkSSHAgent   = launch.CheckSSHAgent
+	dockerSocket    = launch.DockerSocketVolume
+	readStepResults = launch.ReadStepResults
 	useSudo         = false
 	usePrivileged   = false
 	interactiveMode = false
-	loggerDone      chan struct{}
+	pauseOnFailure  = false
+	reuse           = false
+	dryRun          = false
+	envFormat       = ""
+	useLocalAPI     = false
+	noColor         = false
+	stdin           = io.Reader(os.Stdin)
 )
 
 func mergeEnvFromFile(optionEnv *map[string]string, envFilePath string) error {
@@ -53,35 +78,521 @@ func mergeEnvFromFile(optionEnv *map[string]string, envFilePath string) error {
 	return nil
 }
 
+var errNoJobName = fmt.Errorf("requires a job name, or job in %s", config.ProjectFileName)
+
+// defaultJobName returns the job name in the project file of the current directory.
+func defaultJobName() (string, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+
+	project, err := readProject(cwd)
+	if err != nil {
+		return "", err
+	}
+
+	if project.Job == "" {
+		return "", errNoJobName
+	}
+
+	return project.Job, nil
+}
+
+// buildVolumes returns the volumes of the current config, the project file and --volume in this order.
+// The relative host paths of --volume are resolved from the current directory.
+func buildVolumes(cwd string, entryVolumes, projectVolumes, optionVolumes []string) []string {
+	volumes := make([]string, 0, len(entryVolumes)+len(projectVolumes)+len(optionVolumes))
+	volumes = append(volumes, entryVolumes...)
+	volumes = append(volumes, projectVolumes...)
+	for _, v := range optionVolumes {
+		volumes = append(volumes, config.AbsVolume(cwd, v))
+	}
+	return volumes
+}
+
+// buildServices returns the services in the project file and --service, whose names must be unique in the network of the build.
+func buildServices(projectServices []config.Service, optionServices []string) ([]config.Service, error) {
+	var services []config.Service
+	services = append(services, projectServices...)
+	for _, v := range optionServices {
+		s, err := config.ParseService(v)
+		if err != nil {
+			return nil, err
+		}
+		services = append(services, s)
+	}
+
+	names := make(map[string]bool, len(services))
+	for _, s := range services {
+		if names[s.Name] {
+			return nil, fmt.Errorf("service %s is defined more than once, so name the other one with --service <name>=<image>", s.Name)
+		}
+		names[s.Name] = true
+	}
+
+	return services, nil
+}
+
+func validPullPolicy(policy string) bool {
+	for _, p := range launch.PullPolicies {
+		if p == policy {
+			return true
+		}
+	}
+	return false
+}
+
+// absDockerfileImages returns the images of the project file whose Dockerfiles are resolved from the directory.
+func absDockerfileImages(dir string, images map[string]string) (map[string]string, error) {
+	absImages := make(map[string]string, len(images))
+	for name, image := range images {
+		if launch.IsDockerfileImage(image) {
+			abs, err := launch.AbsDockerfileImage(dir, image)
+			if err != nil {
+				return nil, fmt.Errorf("invalid image of %s in %s: %v", name, config.ProjectFileName, err)
+			}
+			image = abs
+		}
+		absImages[name] = image
+	}
+	return absImages, nil
+}
+
+// checkPrivileges checks that the privileges given to the build are allowed with allow-privileged of the config.
+// The capabilities dropped by default can be added back, and no-new-privileges can be set without it, because they give no privileges.
+func checkPrivileges(entry config.Entry, privileged bool, capAdd, securityOpts []string) error {
+	if entry.AllowPrivileged {
+		return nil
+	}
+
+	notAllowed := func(option string) error {
+		return fmt.Errorf("`%s` gives the build privileges on the host, so it must be allowed with `sd-local config set allow-privileged true`", option)
+	}
+
+	if privileged {
+		return notAllowed("privileged")
+	}
+	for _, c := range capAdd {
+		if !launch.IsDefaultDropCapability(c) {
+			return notAllowed("cap-add " + c)
+		}
+	}
+	for _, o := range securityOpts {
+		if o != "no-new-privileges" && o != "no-new-privileges:true" {
+			return notAllowed("security-opt " + o)
+		}
+	}
+	return nil
+}
+
+// overrideImage replaces the image of the job with the Dockerfile of --build-image, or the image in the project file.
+func overrideImage(name string, job *screwdriver.Job, images map[string]string, dockerfile string) {
+	if dockerfile != "" {
+		logrus.Infof("Using the image built from %s for %s", dockerfile, name)
+		job.Image = dockerfile
+		return
+	}
+
+	if image, ok := images[name]; ok {
+		logrus.Infof("Using the image %s for %s in %s", image, name, config.ProjectFileName)
+		job.Image = image
+	}
+}
+
+// confirmPipelineSecrets asks whether the secrets of the pipeline may be set in the build container.
+func confirmPipelineSecrets(pipelineID int, pipelineSecrets map[string]string, out io.Writer) (bool, error) {
+	names := make([]string, 0, len(pipelineSecrets))
+	for name := range pipelineSecrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintf(out, "Secrets %v of pipeline %d are set in the build container if the job lists them. Continue? [y/N]: ", names, pipelineID)
+	input, err := bufio.NewReader(stdin).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+
+	switch strings.TrimSpace(input) {
+	case "y", "Y", "yes", "Yes":
+		return true, nil
+	case "n", "N", "no", "No", "":
+		return false, nil
+	}
+	return false, errors.New("Invalid input")
+}
+
+// maskedValues returns the secrets and tokens which must not appear in build logs.
+func maskedValues(option launch.Option) []string {
+	values := []string{option.JWT, option.Entry.Token}
+	for _, v := range option.Secrets {
+		values = append(values, v)
+	}
+	return values
+}
+
+// runJob runs a build of the job and outputs its log to writer until the build finishes.
+// The metadata of the build is stored in the meta directory under the artifacts path,
+// and its result is written into the result file including the skipped steps.
+// The log of each step is also written into the steps directory under the artifacts path.
+// Secrets and tokens are masked in the output and the log files of the steps by the logger,
+// and in the raw build log file after the build, which fails if it cannot be masked.
+func runJob(option launch.Option, skipped []string, writer io.Writer) error {
+	err := osMkdirAll(option.ArtifactsPath, 0777)
+	if err != nil {
+		return err
+	}
+
+	if option.MetaPath == "" {
+		option.MetaPath = filepath.Join(option.ArtifactsPath, launch.MetaDir)
+	}
+	err = osMkdirAll(option.MetaPath, 0777)
+	if err != nil {
+		return err
+	}
+
+	for _, dir := range option.Cache.HostDirs(option.JobName) {
+		err = osMkdirAll(dir, 0777)
+		if err != nil {
+			return err
+		}
+	}
+
+	// The directory is created before the container runtime creates it owned by root
+	if option.CommandsPath != "" {
+		err = osMkdirAll(option.CommandsPath, 0777)
+		if err != nil {
+			return err
+		}
+	}
+
+	if useLocalAPI {
+		stop, err := startLocalAPI(&option)
+		if err != nil {
+			return err
+		}
+		defer stop()
+	}
+
+	// The results of the steps in the previous build must not be read
+	os.Remove(filepath.Join(option.ArtifactsPath, launch.StepsFile))
+
+	masked := maskedValues(option)
+	logFilePath := filepath.Join(option.ArtifactsPath, launch.LogFile)
+	loggerDone := make(chan struct{})
+	stepsDir := filepath.Join(option.ArtifactsPath, buildlog.StepsDir)
+	logger, err := buildLogNew(logFilePath, writer, loggerDone, buildlog.Option{Format: buildlog.Format(logFormat), JobName: option.JobName, StepsDir: stepsDir, Timestamps: option.Entry.Timestamps, Secrets: masked})
+	if err != nil {
+		return err
+	}
+	go logger.Run()
+
+	launch := launchNew(option)
+	l, ok := launch.(Cleaner)
+	if ok {
+		addCleaner(l)
+	}
+
+	logrus.Info("Prepare to start build...")
+	start := timeNow()
+	err = launch.Run()
+
+	logger.Stop()
+	<-loggerDone
+
+	// The logger masks the lines it writes, but the raw build log is written by the launcher
+	maskErr := maskFile(logFilePath, masked)
+
+	writeResult(option.ArtifactsPath, option.JobName, start, err, skipped, launch)
+
+	if err != nil {
+		if maskErr != nil {
+			logrus.Error(maskErr)
+		}
+		return err
+	}
+	return maskErr
+}
+
 func newBuildCmd() *cobra.Command {
 	var srcURL string
+	var shallow bool
 	var optionEnv map[string]string
 	var envFilePath string
+	var secretsFilePath string
 	var optionMeta string
 	var metaFilePath string
 	var socketPath string
+	var sshAgent bool
+	var runtime string
+	var platform string
+	var pullPolicy string
+	var launcherVersion string
+	var launcherImage string
+	var step string
+	var fromStep string
+	var snapshotAfter string
+	var skipStepPatterns []string
+	var timeout time.Duration
+	var optionStepTimeouts map[string]string
+	var optionParams []string
+	var pipelineID int
+	var pipelineRef string
+	var templateFile string
+	var sourceOverride launch.SourceOption
+	var prNumber int
+	var prBaseBranch string
+	var offline bool
+	var strict bool
+	var runAll bool
+	var continueOnError bool
+	var parallel int
+	var junitPattern string
+	var output string
+	var timestamps bool
+	var serveAddr string
+	var volumes []string
+	var mountDockerSocket bool
+	var cpus string
+	var dockerfile string
+	var services []string
+	var composeFile string
+	var network string
+	var extraHosts []string
+	var publish []string
+	var gpus string
+	var user string
+	var tmpfs []string
+	var shmSize string
+	var capAdd []string
+	var capDrop []string
+	var securityOpts []string
+	var jobArg string
+	var watch bool
 
 	buildCmd := &cobra.Command{
 		Use:   "build [job name]",
 		Short: "Run screwdriver build.",
-		Long:  `Run screwdriver build of the specified job name.`,
+		Long: `Run screwdriver build of the specified job name.
+Multiple jobs can be specified with comma separated names (e.g. lint,test,build).
+With --all, all jobs in the workflow are run in the order of their requires.
+Jobs which don't require each other are run in parallel.
+The metadata set by a job is passed into the jobs which require it.
+The job name can be omitted when job is set in .sd-local.yaml of the current directory.
+Otherwise the job is picked from the list of the jobs in the terminal, which can be searched with a part of the job name.
+With --watch, the build is run again whenever the files in the source directory change until it is interrupted.`,
 		Args: func(cmd *cobra.Command, args []string) error {
-			err := cobra.ExactArgs(1)(cmd, args)
+			var err error
+			if runAll {
+				err = cobra.NoArgs(cmd, args)
+			} else if len(args) == 0 {
+				jobArg, err = defaultJobName()
+				// The job is picked from screwdriver.yaml after it is validated when sd-local is run in the terminal
+				if err == errNoJobName && stdinIsTerminal() {
+					jobArg, err = "", nil
+				}
+			} else {
+				err = cobra.ExactArgs(1)(cmd, args)
+				if err == nil {
+					jobArg = args[0]
+				}
+			}
 
 			if err != nil {
 				return err
 			}
 
+			multiJobs := runAll || strings.Contains(jobArg, ",")
+
+			if continueOnError && !multiJobs {
+				return errors.New("`continue-on-error` can be used only with multiple jobs")
+			}
+
+			if multiJobs && interactiveMode {
+				return errors.New("interactive mode can be used only with a single job")
+			}
+
+			if multiJobs && (step != "" || fromStep != "") {
+				return errors.New("`step` and `from-step` can be used only with a single job")
+			}
+
+			if step != "" && fromStep != "" {
+				return errors.New("can't pass the both options `step` and `from-step`, please specify only one of them")
+			}
+
+			if err := validateStepPatterns(skipStepPatterns); err != nil {
+				return err
+			}
+
+			for _, v := range volumes {
+				if err := config.ValidateVolume(v); err != nil {
+					return err
+				}
+			}
+
+			for _, v := range services {
+				if _, err := config.ParseService(v); err != nil {
+					return err
+				}
+			}
+
+			for _, v := range extraHosts {
+				if err := config.ValidateExtraHost(v); err != nil {
+					return err
+				}
+			}
+
+			for _, v := range publish {
+				if err := config.ValidatePublish(v); err != nil {
+					return err
+				}
+			}
+
+			for _, v := range tmpfs {
+				if err := config.ValidateTmpfs(v); err != nil {
+					return err
+				}
+			}
+
+			if shmSize != "" {
+				if _, err := config.ParseSize(shmSize); err != nil {
+					return err
+				}
+			}
+
+			if user != "" {
+				if err := config.ValidateUser(user); err != nil {
+					return err
+				}
+			}
+
+			if gpus != "" {
+				if err := config.ValidateGPUs(gpus); err != nil {
+					return err
+				}
+			}
+
+			if len(publish) != 0 && network == "host" {
+				return errors.New("`publish` can not be used with host network, because the ports of the build are already on the host")
+			}
+
+			if !validPullPolicy(pullPolicy) {
+				return fmt.Errorf("`pull` must be one of %v", launch.PullPolicies)
+			}
+
+			if platform != "" {
+				if err := config.ValidatePlatform(platform); err != nil {
+					return err
+				}
+			}
+
+			if cpus != "" {
+				if err := config.ValidateCPUs(cpus); err != nil {
+					return err
+				}
+			}
+
+			if pauseOnFailure && interactiveMode {
+				return errors.New("`pause-on-failure` can not be used in interactive mode")
+			}
+
+			if reuse && interactiveMode {
+				return errors.New("`reuse` can not be used in interactive mode")
+			}
+
+			if snapshotAfter != "" && interactiveMode {
+				return errors.New("`snapshot-after` can not be used in interactive mode")
+			}
+
+			if snapshotAfter != "" && reuse {
+				return errors.New("`snapshot-after` can not be used with `reuse`, because the build container is kept after the build")
+			}
+
+			if reuse && pauseOnFailure {
+				return errors.New("`pause-on-failure` can not be used with `reuse`, because the build container is kept after the build")
+			}
+
+			if dryRun && interactiveMode {
+				return errors.New("`dry-run` can not be used in interactive mode")
+			}
+
+			if prNumber < 0 {
+				return errors.New("`pr` must be a positive number")
+			}
+
+			if pipelineID != 0 && offline {
+				return errors.New("`use-pipeline-secrets` can not be used in offline mode")
+			}
+
+			if pipelineRef != "" {
+				if offline {
+					return errors.New("`pipeline` can not be used in offline mode")
+				}
+				if _, _, err := parsePipelineRef(pipelineRef); err != nil {
+					return err
+				}
+			}
+
+			if logFormat != string(buildlog.FormatText) && logFormat != string(buildlog.FormatJSON) {
+				return errors.New("`log-format` must be either text or json")
+			}
+
+			if output != outputText && output != outputJSON {
+				return errors.New("`output` must be either text or json")
+			}
+
+			if serveAddr != "" && interactiveMode {
+				return errors.New("`serve` can not be used in interactive mode")
+			}
+
+			if output == outputJSON && interactiveMode {
+				return errors.New("`output` json can not be used in interactive mode")
+			}
+
 			if optionMeta != "" && metaFilePath != "" {
 				return errors.New("can't pass the both options `meta` and `meta-file`, please specify only one of them")
 			}
 
+			if watch {
+				if jobArg == "" && !runAll {
+					return errNoJobName
+				}
+				if interactiveMode || dryRun {
+					return errors.New("`watch` can not be used in interactive mode or with `dry-run`")
+				}
+				if srcURL != "" {
+					return errors.New("`watch` can not be used with `src-url`, because the source is not on the host")
+				}
+			}
+
 			return nil
 		},
-		RunE: func(cmd *cobra.Command, args []string) error {
-			var err error
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
 			cmd.SilenceUsage = true
 
+			// The builds are run in the child processes, which run in the warm build container after the first one
+			if watch {
+				cwd, err := os.Getwd()
+				if err != nil {
+					return err
+				}
+				artifactsPath, err := filepath.Abs(artifactsDir)
+				if err != nil {
+					return err
+				}
+				return watchBuild(cwd, cwd, []string{artifactsPath}, watchArgs(os.Args[1:], snapshotAfter == "" && !pauseOnFailure))
+			}
+
+			// The SSH agent is forwarded if it is available, unless --ssh-agent requires or disables it
+			if cmd.Flags().Changed("ssh-agent") {
+				if !sshAgent {
+					socketPath = ""
+				} else if err := checkSSHAgent(socketPath); err != nil {
+					return err
+				}
+			}
+
 			if envFilePath != "" {
 				err = mergeEnvFromFile(&optionEnv, envFilePath)
 				if err != nil {
@@ -89,6 +600,78 @@ func newBuildCmd() *cobra.Command {
 				}
 			}
 
+			cwd, err := os.Getwd()
+			if err != nil {
+				return err
+			}
+
+			// The flags take precedence over the project file
+			project, err := readProject(cwd)
+			if err != nil {
+				return err
+			}
+			for k, v := range project.Env {
+				if _, ok := optionEnv[k]; !ok {
+					optionEnv[k] = v
+				}
+			}
+			buildMemory := memory
+			if buildMemory == "" {
+				buildMemory = project.Memory
+			}
+			buildCPUs := cpus
+			if buildCPUs == "" {
+				buildCPUs = project.CPUs
+			}
+			if shmSize == "" {
+				shmSize = project.ShmSize
+			}
+			// The snapshot of the project file is not used with the options which keep the build container
+			buildSnapshotAfter := snapshotAfter
+			if buildSnapshotAfter == "" && !reuse && !interactiveMode {
+				buildSnapshotAfter = project.SnapshotAfter
+			}
+
+			// The Dockerfiles are resolved from the current directory, because the source may be cloned into another directory
+			projectImages, err := absDockerfileImages(cwd, project.Images)
+			if err != nil {
+				return err
+			}
+			if dockerfile != "" {
+				dockerfile, err = launch.AbsDockerfileImage(cwd, dockerfile)
+				if err != nil {
+					return err
+				}
+			}
+
+			sidecars, err := buildServices(project.Services, services)
+			if err != nil {
+				return err
+			}
+
+			if composeFile == "" {
+				composeFile = project.Compose
+			}
+			if composeFile != "" {
+				composeFile, err = filepath.Abs(composeFile)
+				if err != nil {
+					return err
+				}
+				if _, err := os.Stat(composeFile); err != nil {
+					return fmt.Errorf("failed to find compose file: %v", err)
+				}
+			}
+
+			if network != "" {
+				if composeFile != "" {
+					return errors.New("`network` can not be used with the compose file, because the build joins the network of the compose project")
+				}
+				// The services are reachable with their names only on the user-defined networks
+				if len(sidecars) != 0 && (network == "host" || network == "bridge" || network == "none") {
+					return fmt.Errorf("the services can not be run on %s network, so specify a user-defined network with `network`", network)
+				}
+			}
+
 			metaJSON := []byte("{}")
 			if optionMeta != "" {
 				metaJSON = []byte(optionMeta)
@@ -99,76 +682,229 @@ func newBuildCmd() *cobra.Command {
 					return err
 				}
 
-				metaJSON, err = ioutil.ReadFile(absMetaFilePath)
+				metaJSON, err = ioutil.ReadFile(absMetaFilePath)
+
+				if err != nil {
+					return fmt.Errorf("failed to read meta-file %s: %v", metaFilePath, err)
+				}
+			}
+
+			var meta launch.Meta
+
+			err = json.Unmarshal(metaJSON, &meta)
+
+			if err != nil {
+				return fmt.Errorf("failed to parse meta %s, meta must be formated with JSON: %v", string(metaJSON), err)
+			}
+
+			configBaseDir, err := homedir.Dir()
+			if err != nil {
+				return err
+			}
+
+			sdlocalDir := filepath.Join(configBaseDir, ".sdlocal")
+			srcPath := cwd
+
+			if srcURL != "" {
+				logrus.Infof("Pulling the source code from %s...", srcURL)
+
+				scm, err := scmNew(sdlocalDir, srcURL, useSudo, scm.CloneOption{Shallow: shallow, SHA: sourceOverride.SHA})
+				if err != nil {
+					return err
+				}
+				s, ok := scm.(Cleaner)
+				if ok {
+					addCleaner(s)
+				}
+
+				err = scm.Pull()
+				if err != nil {
+					return err
+				}
+				srcPath = scm.LocalPath()
+			}
+
+			config, err := configNew(filepath.Join(sdlocalDir, "config"))
+			if err != nil {
+				return err
+			}
+
+			current, err := config.Entry(config.Current)
+			if err != nil {
+				return err
+			}
+			resolved := current.Resolved()
+			entry := &resolved
+
+			srcUsage, err := readSourceUsage(srcPath)
+			if err != nil {
+				return err
+			}
+			warnSourceSize(srcUsage.Size, entry.SrcSizeWarning)
+
+			if runtime == "" {
+				runtime = entry.Runtime
+			}
+
+			if platform == "" {
+				platform = entry.Platform
+			}
+
+			if user == "" {
+				user = entry.User
+			}
+
+			if err := checkPrivileges(*entry, usePrivileged, capAdd, securityOpts); err != nil {
+				return err
+			}
+
+			if mountDockerSocket {
+				if !entry.AllowDockerSocket {
+					return errors.New("`mount-docker-socket` gives the build root access to the host, so it must be allowed with `sd-local config set allow-docker-socket true`")
+				}
+				socketVolume, err := dockerSocket(runtime)
+				if err != nil {
+					return err
+				}
+				logrus.Warn("The docker socket of the host is mounted into the build container. " +
+					"The build can control all containers and get root access to the host, so run only trusted jobs and images with it.")
+				volumes = append(volumes, socketVolume)
+			}
+
+			if secretsFilePath == "" {
+				secretsFilePath = entry.SecretsFile
+			}
 
-				if err != nil {
-					return fmt.Errorf("failed to read meta-file %s: %v", metaFilePath, err)
-				}
+			// --timestamps takes precedence over the config, so that it can be disabled with --timestamps=false
+			if cmd.Flags().Changed("timestamps") {
+				entry.Timestamps = timestamps
 			}
 
-			var meta launch.Meta
+			// The launcher can be pinned for the build, such as to the version of the cluster or a pre-release
+			if launcherVersion != "" {
+				entry.Launcher.Version = launcherVersion
+			}
+			if launcherImage != "" {
+				entry.Launcher.Image = launcherImage
+			}
 
-			err = json.Unmarshal(metaJSON, &meta)
+			if (entry.VaultAddr == "") != (entry.VaultPath == "") {
+				return errors.New("both of `vault-addr` and `vault-path` must be set to read secrets from Vault")
+			}
 
-			if err != nil {
-				return fmt.Errorf("failed to parse meta %s, meta must be formated with JSON: %v", string(metaJSON), err)
+			providers := make([]secrets.Provider, 0, 2)
+			if entry.VaultAddr != "" {
+				providers = append(providers, vaultNew(entry.VaultAddr, entry.VaultPath))
+			}
+			if secretsFilePath != "" {
+				providers = append(providers, secrets.NewFileProvider(secretsFilePath))
 			}
 
-			cwd, err := os.Getwd()
+			// The secrets in the secrets file take precedence over the secrets in Vault
+			buildSecrets, err := secrets.Resolve(providers...)
 			if err != nil {
 				return err
 			}
 
-			configBaseDir, err := homedir.Dir()
-			if err != nil {
-				return err
+			templateDir := filepath.Join(sdlocalDir, templatesDirName)
+			var api screwdriver.API
+			online := !offline
+			if offline {
+				api = localAPINew(templateDir)
+			} else {
+				option, err := apiOption(entry, sdlocalDir)
+				if err != nil {
+					return err
+				}
+				api = apiNew(entry.APIURL, entry.Token, option)
+
+				err = api.InitJWT()
+				if screwdriver.IsUnreachable(err) {
+					warnUnreachable(err)
+					api = localAPINew(templateDir)
+					online = false
+				} else if err != nil {
+					return err
+				} else {
+					api = &fallbackAPI{API: api, local: localAPINew(templateDir)}
+				}
 			}
 
-			sdlocalDir := filepath.Join(configBaseDir, ".sdlocal")
-			srcPath := cwd
+			if pipelineID != 0 {
+				pipelineSecrets, err := api.Secrets(pipelineID)
+				if err != nil {
+					return err
+				}
 
-			if srcURL != "" {
-				logrus.Infof("Pulling the source code from %s...", srcURL)
+				ok, err := confirmPipelineSecrets(pipelineID, pipelineSecrets, cmd.OutOrStdout())
+				if err != nil {
+					return err
+				}
+				if !ok {
+					logrus.Warn("Aborted the build")
+					return nil
+				}
+
+				// The secrets resolved locally take precedence over the secrets of the pipeline
+				for k, v := range buildSecrets {
+					pipelineSecrets[k] = v
+				}
+				buildSecrets = pipelineSecrets
+			}
 
-				scm, err := scmNew(sdlocalDir, srcURL, useSudo)
+			sdYAMLPath := filepath.Join(srcPath, "screwdriver.yaml")
+			if pipelineRef != "" {
+				yamlPipelineID, branch, _ := parsePipelineRef(pipelineRef)
+				sdYAMLPath, err = pipelineYAMLPath(api, sdlocalDir, yamlPipelineID, branch, useSudo)
 				if err != nil {
 					return err
 				}
-				s, ok := scm.(Cleaner)
-				if ok {
-					cleaners = append(cleaners, s)
+				if sourceOverride.PipelineID == 0 && pipelineID == 0 {
+					sourceOverride.PipelineID = yamlPipelineID
 				}
+			}
 
-				err = scm.Pull()
+			if templateFile != "" {
+				sdYAMLPath, err = inlineTemplateFile(sdYAMLPath, templateFile)
 				if err != nil {
 					return err
 				}
-				srcPath = scm.LocalPath()
+				defer os.Remove(sdYAMLPath)
 			}
 
-			config, err := configNew(filepath.Join(sdlocalDir, "config"))
+			if online {
+				cacheTemplates(api, sdYAMLPath, templateDir)
+			}
+
+			definedParameters, err := readParameters(sdYAMLPath)
 			if err != nil {
 				return err
 			}
-
-			entry, err := config.Entry(config.Current)
+			parameters, err := resolveParameters(definedParameters, optionParams)
 			if err != nil {
 				return err
 			}
 
-			api := apiNew(entry.APIURL, entry.Token)
-
-			err = api.InitJWT()
+			cacheSettings, err := readCache(sdYAMLPath)
 			if err != nil {
 				return err
 			}
+			cacheOption := newCacheOption(sdlocalDir, srcPath, cacheSettings)
+			// The event caches may also be stored with the local API without the settings
+			defer os.RemoveAll(cacheOption.EventDir)
 
-			jobName := args[0]
+			var remote *remoteCache
+			if entry.CacheBackend != "" && entry.CacheBackend != cache.LocalBackend {
+				backend, err := cacheBackendNew(*entry)
+				if err != nil {
+					return err
+				}
+				remote = &remoteCache{backend: backend, key: remoteCacheKey(srcPath), settings: cacheSettings}
+			}
 
-			sdYAMLPath := filepath.Join(srcPath, "screwdriver.yaml")
-			job, err := api.Job(jobName, sdYAMLPath)
-			if err != nil {
-				return err
+			if len(cacheSettings.Pipeline) != 0 {
+				remote.restore("pipeline", cacheOption.PipelineDir)
+				defer remote.store("pipeline", cacheOption.PipelineDir)
 			}
 
 			artifactsPath, err := filepath.Abs(artifactsDir)
@@ -176,49 +912,232 @@ func newBuildCmd() *cobra.Command {
 				return err
 			}
 
-			err = osMkdirAll(artifactsPath, 0777)
+			// The stored meta is used as the initial meta, and the meta options take precedence
+			storedMeta, err := readMeta(filepath.Join(artifactsPath, launch.MetaDir))
 			if err != nil {
 				return err
 			}
+			meta = storedMeta.Merge(meta)
 
-			loggerDone = make(chan struct{})
-			logger, err := buildLogNew(filepath.Join(artifactsPath, launch.LogFile), os.Stdout, loggerDone)
-			if err != nil {
-				return err
+			stepTimeouts := make(map[string]time.Duration, len(optionStepTimeouts))
+			for name, value := range optionStepTimeouts {
+				d, err := time.ParseDuration(value)
+				if err != nil {
+					return fmt.Errorf("failed to parse timeout of step %s: %v", name, err)
+				}
+				stepTimeouts[name] = d
+			}
+
+			if sourceOverride.PipelineID == 0 {
+				sourceOverride.PipelineID = pipelineID
+			}
+			// JWT of a pipeline token can be used only for its pipeline
+			if id, ok := screwdriver.JWTPipelineID(api.JWT()); ok && sourceOverride.PipelineID == 0 {
+				sourceOverride.PipelineID = id
 			}
-			go logger.Run()
 
 			option := launch.Option{
-				Job:             job,
 				Entry:           *entry,
-				JobName:         jobName,
 				JWT:             api.JWT(),
 				ArtifactsPath:   artifactsPath,
-				Memory:          memory,
+				Memory:          buildMemory,
+				CPUs:            buildCPUs,
 				SrcPath:         srcPath,
+				IgnoredDirs:     srcUsage.IgnoredDirs,
 				OptionEnv:       optionEnv,
+				Secrets:         buildSecrets,
 				Meta:            meta,
+				Parameters:      parameters,
+				Source:          newSourceOption(srcPath, sourceOverride),
+				Cache:           cacheOption,
+				CommandsPath:    filepath.Join(sdlocalDir, commandsDirName),
+				Timeout:         launch.TimeoutOption{Build: timeout, Steps: stepTimeouts},
 				UseSudo:         useSudo,
 				UsePrivileged:   usePrivileged,
+				CapAdd:          capAdd,
+				CapDrop:         capDrop,
+				SecurityOpt:     securityOpts,
 				InteractiveMode: interactiveMode,
+				PauseOnFailure:  pauseOnFailure,
+				Reuse:           reuse,
 				SocketPath:      socketPath,
 				FlagVerbose:     flagVerbose,
+				Runtime:         runtime,
+				Platform:        platform,
+				Pull:            pullPolicy,
+				Volumes:         buildVolumes(cwd, entry.Volumes, project.Volumes, volumes),
+				Services:        sidecars,
+				ComposeFile:     composeFile,
+				Network:         network,
+				ExtraHosts:      extraHosts,
+				Publish:         publish,
+				GPUs:            gpus,
+				User:            user,
+				Tmpfs:           tmpfs,
+				ShmSize:         shmSize,
+				Snapshot:        launch.SnapshotOption{After: buildSnapshotAfter, Files: []string{sdYAMLPath}},
+			}
+
+			// The build log and the summaries are not printed into stdout so that it can be parsed as JSON
+			logWriter, summaryOut := io.Writer(os.Stdout), cmd.OutOrStdout()
+			if output == outputJSON {
+				logWriter, summaryOut = os.Stderr, ioutil.Discard
+			}
+
+			if serveAddr != "" {
+				stream := newLogStream()
+				logWriter = io.MultiWriter(logWriter, stream)
+				defer serveLogStream(serveAddr, stream)()
+			}
+
+			var jobs map[string]screwdriver.Job
+			if jobArg == "" && !runAll {
+				jobs, err = validatedJobs(api, sdYAMLPath, strict, cmd.ErrOrStderr())
+				if err != nil {
+					return err
+				}
+				jobArg, err = pickBuildJob(jobs, srcPath, sdlocalDir, cmd.ErrOrStderr())
+				if err != nil {
+					return err
+				}
+			}
+
+			jobNames := []string{}
+			if !runAll {
+				jobNames, prNumber, err = parsePRJobNames(strings.Split(jobArg, ","), prNumber)
+				if err != nil {
+					return err
+				}
+			}
+			option.PullRequest = newPullRequest(prNumber, prBaseBranch)
+
+			// The builds printed with --dry-run are neither listed as running nor recorded in the history
+			runningJob := "all"
+			if !runAll {
+				runningJob = strings.Join(jobNames, ",")
+			}
+			if !dryRun {
+				registerBuild(runningJob, srcPath, runtime)
+				record := recordBuild(runningJob, cwd, artifactsPath, os.Args[1:])
+				defer func() { record.finish(err) }()
+			}
+
+			// The jobs are validated only once, when the job was picked from them
+			if jobs == nil {
+				jobs, err = validatedJobs(api, sdYAMLPath, strict, cmd.ErrOrStderr())
+				if err != nil {
+					return err
+				}
+			}
+
+			if len(jobNames) > 1 || runAll {
+				jobs, err := selectJobs(jobs, jobNames)
+				if err != nil {
+					return err
+				}
+				for name, job := range jobs {
+					overrideImage(name, &job, projectImages, dockerfile)
+					jobs[name] = job
+				}
+
+				if runAll && option.PullRequest.Number != 0 {
+					jobs = screwdriver.PRJobs(jobs)
+					if len(jobs) == 0 {
+						return errors.New("no jobs are triggered by pull requests in screwdriver.yaml")
+					}
+				}
+
+				skippedSteps := make(map[string][]string)
+				for name, job := range jobs {
+					var skipped []string
+					job.Steps, skipped = skipSteps(job.Steps, skipStepPatterns)
+					if len(skipped) != 0 {
+						skippedSteps[name] = skipped
+					}
+					jobs[name] = job
+				}
+
+				if dryRun {
+					order, err := screwdriver.WorkflowOrder(jobs)
+					if err != nil {
+						return err
+					}
+					for _, name := range order {
+						o := option
+						o.Job, o.JobName = jobs[name], name
+						o.ArtifactsPath = filepath.Join(option.ArtifactsPath, name)
+						if err := printPlan(cmd.OutOrStdout(), o); err != nil {
+							return err
+						}
+					}
+					return nil
+				}
+
+				buildStart := timeNow()
+				err = runWorkflow(option, jobs, skippedSteps, parallel, continueOnError, remote, logWriter, summaryOut)
+				printArtifactSummary(summaryOut, option.ArtifactsPath, buildStart)
+				printJUnitSummary(summaryOut, option.ArtifactsPath, junitPattern, buildStart)
+				order, _ := screwdriver.WorkflowOrder(jobs)
+				if output == outputJSON {
+					if printErr := printResult(cmd.OutOrStdout(), workflowArtifactsPaths(option.ArtifactsPath, order), order, buildStart, err); printErr != nil {
+						logrus.Warn(printErr)
+					}
+				}
+				builds := runResults(workflowArtifactsPaths(option.ArtifactsPath, order), order, buildStart)
+				notifyBuilds(*entry, builds)
+				emitMetrics(*entry, builds)
+				return err
 			}
 
-			launch := launchNew(option)
-			l, ok := launch.(Cleaner)
-			if ok {
-				cleaners = append(cleaners, l)
+			jobName := jobNames[0]
+
+			job, ok := jobs[jobName]
+			if !ok {
+				return fmt.Errorf("not found '%s' in parsed screwdriver.yaml", jobName)
 			}
+			overrideImage(jobName, &job, projectImages, dockerfile)
 
-			logrus.Info("Prepare to start build...")
-			err = launch.Run()
+			job.Steps, err = selectSteps(job.Steps, step, fromStep)
 			if err != nil {
 				return err
 			}
 
-			logger.Stop()
-			<-loggerDone
+			if snapshotAfter != "" {
+				if _, err := findStep(job.Steps, snapshotAfter); err != nil {
+					return err
+				}
+			}
+
+			var skipped []string
+			job.Steps, skipped = skipSteps(job.Steps, skipStepPatterns)
+
+			option.Job = job
+			option.JobName = jobName
+
+			if dryRun {
+				return printPlan(cmd.OutOrStdout(), option)
+			}
+
+			buildStart := timeNow()
+			err = remote.runJobWithCache(option, skipped, logWriter)
+			if !interactiveMode {
+				printStepSummary(summaryOut, jobNames, map[string][]launch.StepResult{jobName: stepResults(option.ArtifactsPath, skipped)})
+				printArtifactSummary(summaryOut, option.ArtifactsPath, buildStart)
+				printJUnitSummary(summaryOut, option.ArtifactsPath, junitPattern, buildStart)
+			}
+			if output == outputJSON {
+				if printErr := printResult(cmd.OutOrStdout(), map[string]string{jobName: option.ArtifactsPath}, jobNames, buildStart, err); printErr != nil {
+					logrus.Warn(printErr)
+				}
+			}
+			if !interactiveMode {
+				builds := runResults(map[string]string{jobName: option.ArtifactsPath}, jobNames, buildStart)
+				notifyBuilds(*entry, builds)
+				emitMetrics(*entry, builds)
+			}
+			if err != nil {
+				return err
+			}
 
 			return nil
 		},
@@ -235,7 +1154,13 @@ func newBuildCmd() *cobra.Command {
 		"memory",
 		"m",
 		"",
-		"Memory limit for build container, which take a positive integer, followed by a suffix of b, k, m, g.")
+		"Memory limit for build container, which take a positive integer, followed by a suffix of b, k, m, g. Defaults to memory of .sd-local.yaml, the screwdriver.cd/ram annotation of the job or memory of the current config.")
+
+	buildCmd.Flags().StringVar(
+		&cpus,
+		"cpus",
+		"",
+		"Number of CPUs of the build container (e.g. 0.5, 2). Defaults to cpus of .sd-local.yaml, the screwdriver.cd/cpu annotation of the job or cpus of the current config.")
 
 	buildCmd.Flags().StringVar(
 		&srcURL,
@@ -245,6 +1170,12 @@ func newBuildCmd() *cobra.Command {
 ex) git@github.com:<org>/<repo>.git[#<branch>]
     https://github.com/<org>/<repo>.git[#<branch>]`)
 
+	buildCmd.Flags().BoolVar(
+		&shallow,
+		"shallow",
+		false,
+		"Clone only the latest commit of the source. Only used with --src-url.")
+
 	buildCmd.Flags().StringToStringVarP(
 		&optionEnv,
 		"env",
@@ -259,11 +1190,59 @@ ex) git@github.com:<org>/<repo>.git[#<branch>]
 		"",
 		"Path to config file of environment variables. '.env' format file can be used.")
 
+	buildCmd.Flags().StringVar(
+		&secretsFilePath,
+		"secrets-file",
+		"",
+		"Path to the YAML file of secrets. The secrets listed in the secrets of the job are set as environment variables. Defaults to the secrets file of the current config.")
+
+	buildCmd.Flags().IntVar(
+		&pipelineID,
+		"use-pipeline-secrets",
+		0,
+		"ID of the pipeline whose secrets are fetched from Screwdriver.cd API. Only the secrets listed in the secrets of the job are set, after confirmation. A pipeline token is required.")
+
+	buildCmd.Flags().StringVar(
+		&sourceOverride.URL,
+		"git-url",
+		"",
+		"URL of the repository which is set as $GIT_URL. Defaults to the URL of origin remote of the source directory.")
+
+	buildCmd.Flags().StringVar(
+		&sourceOverride.Branch,
+		"git-branch",
+		"",
+		"Branch which is set as $GIT_BRANCH. Defaults to the current branch of the source directory.")
+
+	buildCmd.Flags().StringVar(
+		&sourceOverride.SHA,
+		"sha",
+		"",
+		"Commit SHA which is set as $SD_BUILD_SHA. Defaults to HEAD of the source directory. With --src-url, the commit is checked out.")
+
+	buildCmd.Flags().IntVar(
+		&sourceOverride.PipelineID,
+		"pipeline-id",
+		0,
+		"ID of the pipeline which is set as $SD_PIPELINE_ID. Defaults to the ID passed with --use-pipeline-secrets or --pipeline, or the pipeline of the pipeline token.")
+
+	buildCmd.Flags().StringVar(
+		&pipelineRef,
+		"pipeline",
+		"",
+		"Pipeline whose screwdriver.yaml is used instead of the local one, which is <id>[@<branch>]. The repository of the pipeline found with Screwdriver.cd API is cloned to read it. Defaults to the branch of the pipeline.")
+
+	buildCmd.Flags().StringVar(
+		&templateFile,
+		"template-file",
+		"",
+		"Template definition such as sd-template.yaml, which is used by the jobs using the template instead of the published one regardless of the version.")
+
 	buildCmd.Flags().StringVar(
 		&optionMeta,
 		"meta",
 		"",
-		"Metadata to pass into the build environment, which is represented with JSON format",
+		"Metadata to pass into the build environment, which is represented with JSON format. With multiple jobs, it is passed into the first jobs of the workflow.",
 	)
 
 	buildCmd.Flags().StringVar(
@@ -272,6 +1251,12 @@ ex) git@github.com:<org>/<repo>.git[#<branch>]
 		"",
 		"Path to the meta file. meta file is represented with JSON format.")
 
+	buildCmd.Flags().StringArrayVar(
+		&optionParams,
+		"param",
+		[]string{},
+		"Set the value of the build parameter defined in screwdriver.yaml, which is set as $SD_PARAM_<NAME> and the parameters in meta. (<name>=<value>) Can be specified multiple times.")
+
 	buildCmd.Flags().BoolVar(
 		&useSudo,
 		"sudo",
@@ -282,7 +1267,25 @@ ex) git@github.com:<org>/<repo>.git[#<branch>]
 		&usePrivileged,
 		"privileged",
 		false,
-		"Use privileged mode for container runtime.")
+		"Use privileged mode for container runtime. It gives the build root access to the host, so it must be allowed with allow-privileged of the config.")
+
+	buildCmd.Flags().StringArrayVar(
+		&capAdd,
+		"cap-add",
+		[]string{},
+		"Add the Linux capability to the build container, such as NET_ADMIN. The capabilities other than "+strings.Join(launch.DefaultDropCapabilities, ", ")+", which are dropped by default, must be allowed with allow-privileged of the config. Can be specified multiple times.")
+
+	buildCmd.Flags().StringArrayVar(
+		&capDrop,
+		"cap-drop",
+		[]string{},
+		"Drop the Linux capability from the build container in addition to "+strings.Join(launch.DefaultDropCapabilities, ", ")+", which are dropped by default. Can be specified multiple times.")
+
+	buildCmd.Flags().StringArrayVar(
+		&securityOpts,
+		"security-opt",
+		[]string{},
+		"Security option of the build container, such as seccomp=unconfined. The options other than no-new-privileges must be allowed with allow-privileged of the config. Can be specified multiple times.")
 
 	buildCmd.Flags().BoolVarP(
 		&interactiveMode,
@@ -291,6 +1294,36 @@ ex) git@github.com:<org>/<repo>.git[#<branch>]
 		false,
 		"Attach the build container in interactive mode.")
 
+	buildCmd.Flags().BoolVar(
+		&useLocalAPI,
+		"local-api",
+		false,
+		"Point $SD_API_URL and $SD_STORE_URL of the build at the stub of Screwdriver.cd API served by sd-local, which stores the meta, the caches and the artifacts on the host.")
+
+	buildCmd.Flags().BoolVar(
+		&watch,
+		"watch",
+		false,
+		"Run the build again whenever the files in the source directory change, except the directories in the ignore file. The builds after the first one run in the build container kept with --reuse.")
+
+	buildCmd.Flags().BoolVar(
+		&pauseOnFailure,
+		"pause-on-failure",
+		false,
+		"Keep the build container alive when the build fails, so that it can be inspected with the exec command of the container runtime.")
+
+	buildCmd.Flags().BoolVar(
+		&reuse,
+		"reuse",
+		false,
+		"Keep the build container after the build and run the next builds of the job in it, which is removed with \"sd-local clean\".")
+
+	buildCmd.Flags().BoolVar(
+		&dryRun,
+		"dry-run",
+		false,
+		"Print the image, the steps, the environment variables, the volumes and the command line of the build container without running the build. The secrets and the tokens are masked.")
+
 	buildCmd.Flags().StringVarP(
 		&socketPath,
 		"socket",
@@ -298,5 +1331,229 @@ ex) git@github.com:<org>/<repo>.git[#<branch>]
 		launch.DefaultSocketPath(),
 		"Path to the socket. It will used in build container.")
 
+	buildCmd.Flags().BoolVar(
+		&sshAgent,
+		"ssh-agent",
+		false,
+		"Forward the SSH agent of the host into the build container with the socket, so that the steps can clone private repositories and ssh to hosts. The build fails if the agent is not available. Without it, the agent is forwarded only if it is available, and --ssh-agent=false disables it.")
+
+	buildCmd.Flags().BoolVar(
+		&mountDockerSocket,
+		"mount-docker-socket",
+		false,
+		"Mount the docker socket of the host into the build container, so that the steps can build and run images. It gives the build root access to the host, so it must be allowed with allow-docker-socket of the config.")
+
+	buildCmd.Flags().StringVar(
+		&runtime,
+		"runtime",
+		"",
+		"Container runtime to run the build, docker, podman or nerdctl. Defaults to the runtime of the current config, or nerdctl when the docker socket is absent.")
+
+	buildCmd.Flags().StringVar(
+		&platform,
+		"platform",
+		"",
+		"Platform of the build image and the launcher image, linux/amd64 or linux/arm64, such as to emulate the architecture of the cluster on Apple Silicon. Defaults to the platform of the current config, or the platform of the host.")
+
+	buildCmd.Flags().StringVar(
+		&launcherVersion,
+		"launcher-version",
+		"",
+		"Version of the launcher image, such as the version run by the cluster or a pre-release. Defaults to launcher-version of the current config.")
+
+	buildCmd.Flags().StringVar(
+		&launcherImage,
+		"launcher-image",
+		"",
+		"Launcher image without the tag, such as a fork or a mirror of screwdrivercd/launcher. Defaults to launcher-image of the current config.")
+
+	buildCmd.Flags().StringVar(
+		&pullPolicy,
+		"pull",
+		launch.PullAlways,
+		"Policy to pull the build image and the launcher image, which is always, missing or never. With missing, the images are pulled only if they are not found locally, and with never, they are not pulled.")
+
+	buildCmd.Flags().StringVar(
+		&dockerfile,
+		"build-image",
+		"",
+		"Dockerfile to build the image of the job instead of pulling it, such as ./Dockerfile or ./Dockerfile:target with the build stage. The image is built with BuildKit, which caches the layers between the builds.")
+
+	buildCmd.Flags().StringArrayVar(
+		&services,
+		"service",
+		[]string{},
+		"Run the sidecar container of the image alongside the build, such as a database for the integration tests, which is reachable with its name as the host name. ([<name>=]<image>) The name defaults to the name of the image. Can be specified multiple times.")
+
+	buildCmd.Flags().StringVar(
+		&composeFile,
+		"compose-file",
+		"",
+		"Path to the docker-compose file whose services are started alongside the build and removed after it. The build joins the default network of the compose project, where the services are reachable with their names. Defaults to compose of .sd-local.yaml.")
+
+	buildCmd.Flags().StringVar(
+		&network,
+		"network",
+		"",
+		"Network of the build container, which is host, bridge or the name of a network, such as host to reach the services on the host with localhost. The services of --service are also run on the network. Defaults to the network of the container runtime.")
+
+	buildCmd.Flags().StringArrayVar(
+		&extraHosts,
+		"add-host",
+		[]string{},
+		"Add the host to /etc/hosts of the build container, such as to reach the services on the host with their names. (<name>:<ip>) host-gateway can be used as the IP address of the host. Can be specified multiple times.")
+
+	buildCmd.Flags().StringArrayVar(
+		&publish,
+		"publish",
+		[]string{},
+		"Publish the port of the build container to the host, such as to open the dev server started by the step in the browser. ([<ip>:][<host port>:]<container port>[/<protocol>]) Can be specified multiple times.")
+
+	buildCmd.Flags().StringArrayVar(
+		&tmpfs,
+		"tmpfs",
+		[]string{},
+		"Mount the tmpfs into the build container, which is <container path>[:<options>] (e.g. /tmp:size=1g). Can be specified multiple times.")
+
+	buildCmd.Flags().StringVar(
+		&shmSize,
+		"shm-size",
+		"",
+		"Size of /dev/shm of the build container, which take a positive integer, followed by a suffix of b, k, m, g, such as for the browsers which crash with the default 64m. Defaults to shm-size of .sd-local.yaml.")
+
+	buildCmd.Flags().StringVar(
+		&user,
+		"user",
+		"",
+		"User of the build container, which is <name|uid>[:<group|gid>] or host for the user of the host, so that the files written into the source directory, the artifacts and the volumes are owned by the user instead of root. Defaults to the user of the current config, or the user of the image.")
+
+	buildCmd.Flags().StringVar(
+		&gpus,
+		"gpus",
+		"",
+		"GPUs exposed to the build container, which are all, the number of GPUs or device=<index>[,<index>...], such as to test the machine learning pipelines with CUDA. NVIDIA Container Toolkit must be installed on the host.")
+
+	buildCmd.Flags().StringVar(
+		&step,
+		"step",
+		"",
+		"Name of the step to run. The other steps are not run.")
+
+	buildCmd.Flags().StringVar(
+		&fromStep,
+		"from-step",
+		"",
+		"Name of the step to resume the build from. The steps before it are not run.")
+
+	buildCmd.Flags().StringVar(
+		&snapshotAfter,
+		"snapshot-after",
+		"",
+		"Name of the last setup step, such as the step installing the dependencies. The build container is committed after it, and the next builds start from the snapshot without running the steps until it. The snapshot is taken again when the image, the steps, screwdriver.yaml or the lockfiles change. Defaults to snapshot-after of .sd-local.yaml.")
+
+	buildCmd.Flags().StringSliceVar(
+		&skipStepPatterns,
+		"skip-step",
+		[]string{},
+		"Name of the step not to run, which can be a glob pattern (e.g. notify-*). Can be specified multiple times.")
+
+	buildCmd.Flags().DurationVar(
+		&timeout,
+		"timeout",
+		0,
+		"Timeout of the build (e.g. 30m). Defaults to the screwdriver.cd/timeout annotation of the job.")
+
+	buildCmd.Flags().StringToStringVar(
+		&optionStepTimeouts,
+		"step-timeout",
+		map[string]string{},
+		"Set timeout of the step. (<step name>=<timeout>, e.g. install=10m)")
+
+	buildCmd.Flags().IntVar(
+		&prNumber,
+		"pr",
+		0,
+		"Number of the pull request to run the build as a PR build. With --all, only the jobs triggered by pull requests are run. Job names prefixed with PR-<number>: can also be used.")
+
+	buildCmd.Flags().StringVar(
+		&prBaseBranch,
+		"pr-branch",
+		"",
+		"Base branch of the pull request to run the build as a PR build. Defaults to master.")
+
+	buildCmd.Flags().BoolVar(
+		&runAll,
+		"all",
+		false,
+		"Run all jobs in the workflow in the order of their requires.")
+
+	buildCmd.Flags().BoolVar(
+		&continueOnError,
+		"continue-on-error",
+		false,
+		"Continue running the rest of jobs even if a job fails. The downstream jobs of the failed job are skipped. Only used with multiple jobs.")
+
+	buildCmd.Flags().IntVarP(
+		&parallel,
+		"parallel",
+		"p",
+		4,
+		"Maximum number of jobs run at the same time. Only used with multiple jobs.")
+
+	buildCmd.Flags().BoolVar(
+		&offline,
+		"offline",
+		false,
+		"Validate screwdriver.yaml locally without calling Screwdriver.cd API. Only the templates cached by the previous builds can be used in offline mode.")
+
+	buildCmd.Flags().BoolVar(
+		&strict,
+		"strict",
+		false,
+		"Fail the build when screwdriver.yaml has warnings, such as the deprecated syntax and the unknown annotations.")
+
+	buildCmd.Flags().StringVar(
+		&junitPattern,
+		"junit",
+		launch.DefaultJUnitPattern,
+		"Glob of the JUnit XML files in the artifacts directory, whose test results are shown after the build. It is matched with the file path if it contains /, otherwise with the file name. Set empty to disable.")
+
+	buildCmd.Flags().StringVar(
+		&logFormat,
+		"log-format",
+		string(buildlog.FormatText),
+		"Format of the build log output, which is either text or json. With json, each line is printed as a JSON object with the time, job, step and message. The stderr of the steps is not distinguished from the stdout.")
+
+	buildCmd.Flags().BoolVar(
+		&timestamps,
+		"timestamps",
+		false,
+		"Show the time of each line and the elapsed time of each step in the build log. Defaults to the timestamps of the current config.")
+
+	buildCmd.Flags().BoolVar(
+		&noColor,
+		"no-color",
+		false,
+		"Print the summary of the steps without colours.")
+
+	buildCmd.Flags().StringVar(
+		&serveAddr,
+		"serve",
+		"",
+		"Address to stream the build log over HTTP while the build is running (e.g. localhost:8080). The log is served at /logs with Server-Sent Events, and can be followed in a browser at /. The clients connecting in the middle of the build receive the last 10000 lines.")
+
+	buildCmd.Flags().StringArrayVar(
+		&volumes,
+		"volume",
+		[]string{},
+		"Mount the host path into the build container, which is <host path>:<container path>[:ro]. Can be specified multiple times. The volumes of the current config and .sd-local.yaml are also mounted.")
+
+	buildCmd.Flags().StringVarP(
+		&output,
+		"output",
+		"o",
+		outputText,
+		"Output format of the result, which is either text or json. With json, the results of the jobs are printed in JSON after the build, and the build log is printed into stderr.")
+
 	return buildCmd
 }
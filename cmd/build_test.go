@@ -2,12 +2,22 @@ package cmd
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/screwdriver-cd/sd-local/buildlog"
+	"github.com/screwdriver-cd/sd-local/config"
 	"github.com/screwdriver-cd/sd-local/launch"
+	"github.com/screwdriver-cd/sd-local/scm"
+	"github.com/screwdriver-cd/sd-local/screwdriver"
+	"github.com/screwdriver-cd/sd-local/secrets"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -16,23 +26,104 @@ Usage:
   build [job name] [flags]
 
 Flags:
-      --artifacts-dir string   Path to the host side directory which is mounted into $SD_ARTIFACTS_DIR. (default "sd-artifacts")
-  -e, --env stringToString     Set key and value relationship which is set as environment variables of Build Container. (<key>=<value>) (default [])
-      --env-file string        Path to config file of environment variables. '.env' format file can be used.
-  -h, --help                   help for build
-  -i, --interactive            Attach the build container in interactive mode.
-  -m, --memory string          Memory limit for build container, which take a positive integer, followed by a suffix of b, k, m, g.
-      --meta string            Metadata to pass into the build environment, which is represented with JSON format
-      --meta-file string       Path to the meta file. meta file is represented with JSON format.
-      --privileged             Use privileged mode for container runtime.
-  -S, --socket string          Path to the socket. It will used in build container.
-      --src-url string         Specify the source url to build.
-                               ex) git@github.com:<org>/<repo>.git[#<branch>]
-                                   https://github.com/<org>/<repo>.git[#<branch>]
-      --sudo                   Use sudo command for container runtime.
+      --add-host stringArray          Add the host to /etc/hosts of the build container, such as to reach the services on the host with their names. (<name>:<ip>) host-gateway can be used as the IP address of the host. Can be specified multiple times.
+      --all                           Run all jobs in the workflow in the order of their requires.
+      --artifacts-dir string          Path to the host side directory which is mounted into $SD_ARTIFACTS_DIR. (default "sd-artifacts")
+      --build-image string            Dockerfile to build the image of the job instead of pulling it, such as ./Dockerfile or ./Dockerfile:target with the build stage. The image is built with BuildKit, which caches the layers between the builds.
+      --cap-add stringArray           Add the Linux capability to the build container, such as NET_ADMIN. The capabilities other than AUDIT_WRITE, MKNOD, NET_RAW, which are dropped by default, must be allowed with allow-privileged of the config. Can be specified multiple times.
+      --cap-drop stringArray          Drop the Linux capability from the build container in addition to AUDIT_WRITE, MKNOD, NET_RAW, which are dropped by default. Can be specified multiple times.
+      --compose-file string           Path to the docker-compose file whose services are started alongside the build and removed after it. The build joins the default network of the compose project, where the services are reachable with their names. Defaults to compose of .sd-local.yaml.
+      --continue-on-error             Continue running the rest of jobs even if a job fails. The downstream jobs of the failed job are skipped. Only used with multiple jobs.
+      --cpus string                   Number of CPUs of the build container (e.g. 0.5, 2). Defaults to cpus of .sd-local.yaml, the screwdriver.cd/cpu annotation of the job or cpus of the current config.
+      --dry-run                       Print the image, the steps, the environment variables, the volumes and the command line of the build container without running the build. The secrets and the tokens are masked.
+  -e, --env stringToString            Set key and value relationship which is set as environment variables of Build Container. (<key>=<value>) (default [])
+      --env-file string               Path to config file of environment variables. '.env' format file can be used.
+      --from-step string              Name of the step to resume the build from. The steps before it are not run.
+      --git-branch string             Branch which is set as $GIT_BRANCH. Defaults to the current branch of the source directory.
+      --git-url string                URL of the repository which is set as $GIT_URL. Defaults to the URL of origin remote of the source directory.
+      --gpus string                   GPUs exposed to the build container, which are all, the number of GPUs or device=<index>[,<index>...], such as to test the machine learning pipelines with CUDA. NVIDIA Container Toolkit must be installed on the host.
+  -h, --help                          help for build
+  -i, --interactive                   Attach the build container in interactive mode.
+      --junit string                  Glob of the JUnit XML files in the artifacts directory, whose test results are shown after the build. It is matched with the file path if it contains /, otherwise with the file name. Set empty to disable. (default "*.xml")
+      --launcher-image string         Launcher image without the tag, such as a fork or a mirror of screwdrivercd/launcher. Defaults to launcher-image of the current config.
+      --launcher-version string       Version of the launcher image, such as the version run by the cluster or a pre-release. Defaults to launcher-version of the current config.
+      --local-api                     Point $SD_API_URL and $SD_STORE_URL of the build at the stub of Screwdriver.cd API served by sd-local, which stores the meta, the caches and the artifacts on the host.
+      --log-format string             Format of the build log output, which is either text or json. With json, each line is printed as a JSON object with the time, job, step and message. The stderr of the steps is not distinguished from the stdout. (default "text")
+  -m, --memory string                 Memory limit for build container, which take a positive integer, followed by a suffix of b, k, m, g. Defaults to memory of .sd-local.yaml, the screwdriver.cd/ram annotation of the job or memory of the current config.
+      --meta string                   Metadata to pass into the build environment, which is represented with JSON format. With multiple jobs, it is passed into the first jobs of the workflow.
+      --meta-file string              Path to the meta file. meta file is represented with JSON format.
+      --mount-docker-socket           Mount the docker socket of the host into the build container, so that the steps can build and run images. It gives the build root access to the host, so it must be allowed with allow-docker-socket of the config.
+      --network string                Network of the build container, which is host, bridge or the name of a network, such as host to reach the services on the host with localhost. The services of --service are also run on the network. Defaults to the network of the container runtime.
+      --no-color                      Print the summary of the steps without colours.
+      --offline                       Validate screwdriver.yaml locally without calling Screwdriver.cd API. Only the templates cached by the previous builds can be used in offline mode.
+  -o, --output string                 Output format of the result, which is either text or json. With json, the results of the jobs are printed in JSON after the build, and the build log is printed into stderr. (default "text")
+  -p, --parallel int                  Maximum number of jobs run at the same time. Only used with multiple jobs. (default 4)
+      --param stringArray             Set the value of the build parameter defined in screwdriver.yaml, which is set as $SD_PARAM_<NAME> and the parameters in meta. (<name>=<value>) Can be specified multiple times.
+      --pause-on-failure              Keep the build container alive when the build fails, so that it can be inspected with the exec command of the container runtime.
+      --pipeline string               Pipeline whose screwdriver.yaml is used instead of the local one, which is <id>[@<branch>]. The repository of the pipeline found with Screwdriver.cd API is cloned to read it. Defaults to the branch of the pipeline.
+      --pipeline-id int               ID of the pipeline which is set as $SD_PIPELINE_ID. Defaults to the ID passed with --use-pipeline-secrets or --pipeline, or the pipeline of the pipeline token.
+      --platform string               Platform of the build image and the launcher image, linux/amd64 or linux/arm64, such as to emulate the architecture of the cluster on Apple Silicon. Defaults to the platform of the current config, or the platform of the host.
+      --pr int                        Number of the pull request to run the build as a PR build. With --all, only the jobs triggered by pull requests are run. Job names prefixed with PR-<number>: can also be used.
+      --pr-branch string              Base branch of the pull request to run the build as a PR build. Defaults to master.
+      --privileged                    Use privileged mode for container runtime. It gives the build root access to the host, so it must be allowed with allow-privileged of the config.
+      --publish stringArray           Publish the port of the build container to the host, such as to open the dev server started by the step in the browser. ([<ip>:][<host port>:]<container port>[/<protocol>]) Can be specified multiple times.
+      --pull string                   Policy to pull the build image and the launcher image, which is always, missing or never. With missing, the images are pulled only if they are not found locally, and with never, they are not pulled. (default "always")
+      --reuse                         Keep the build container after the build and run the next builds of the job in it, which is removed with "sd-local clean".
+      --runtime string                Container runtime to run the build, docker, podman or nerdctl. Defaults to the runtime of the current config, or nerdctl when the docker socket is absent.
+      --secrets-file string           Path to the YAML file of secrets. The secrets listed in the secrets of the job are set as environment variables. Defaults to the secrets file of the current config.
+      --security-opt stringArray      Security option of the build container, such as seccomp=unconfined. The options other than no-new-privileges must be allowed with allow-privileged of the config. Can be specified multiple times.
+      --serve string                  Address to stream the build log over HTTP while the build is running (e.g. localhost:8080). The log is served at /logs with Server-Sent Events, and can be followed in a browser at /. The clients connecting in the middle of the build receive the last 10000 lines.
+      --service stringArray           Run the sidecar container of the image alongside the build, such as a database for the integration tests, which is reachable with its name as the host name. ([<name>=]<image>) The name defaults to the name of the image. Can be specified multiple times.
+      --sha string                    Commit SHA which is set as $SD_BUILD_SHA. Defaults to HEAD of the source directory. With --src-url, the commit is checked out.
+      --shallow                       Clone only the latest commit of the source. Only used with --src-url.
+      --shm-size string               Size of /dev/shm of the build container, which take a positive integer, followed by a suffix of b, k, m, g, such as for the browsers which crash with the default 64m. Defaults to shm-size of .sd-local.yaml.
+      --skip-step strings             Name of the step not to run, which can be a glob pattern (e.g. notify-*). Can be specified multiple times.
+      --snapshot-after string         Name of the last setup step, such as the step installing the dependencies. The build container is committed after it, and the next builds start from the snapshot without running the steps until it. The snapshot is taken again when the image, the steps, screwdriver.yaml or the lockfiles change. Defaults to snapshot-after of .sd-local.yaml.
+  -S, --socket string                 Path to the socket. It will used in build container.
+      --src-url string                Specify the source url to build.
+                                      ex) git@github.com:<org>/<repo>.git[#<branch>]
+                                          https://github.com/<org>/<repo>.git[#<branch>]
+      --ssh-agent                     Forward the SSH agent of the host into the build container with the socket, so that the steps can clone private repositories and ssh to hosts. The build fails if the agent is not available. Without it, the agent is forwarded only if it is available, and --ssh-agent=false disables it.
+      --step string                   Name of the step to run. The other steps are not run.
+      --step-timeout stringToString   Set timeout of the step. (<step name>=<timeout>, e.g. install=10m) (default [])
+      --strict                        Fail the build when screwdriver.yaml has warnings, such as the deprecated syntax and the unknown annotations.
+      --sudo                          Use sudo command for container runtime.
+      --template-file string          Template definition such as sd-template.yaml, which is used by the jobs using the template instead of the published one regardless of the version.
+      --timeout duration              Timeout of the build (e.g. 30m). Defaults to the screwdriver.cd/timeout annotation of the job.
+      --timestamps                    Show the time of each line and the elapsed time of each step in the build log. Defaults to the timestamps of the current config.
+      --tmpfs stringArray             Mount the tmpfs into the build container, which is <container path>[:<options>] (e.g. /tmp:size=1g). Can be specified multiple times.
+      --use-pipeline-secrets int      ID of the pipeline whose secrets are fetched from Screwdriver.cd API. Only the secrets listed in the secrets of the job are set, after confirmation. A pipeline token is required.
+      --user string                   User of the build container, which is <name|uid>[:<group|gid>] or host for the user of the host, so that the files written into the source directory, the artifacts and the volumes are owned by the user instead of root. Defaults to the user of the current config, or the user of the image.
+      --volume stringArray            Mount the host path into the build container, which is <host path>:<container path>[:ro]. Can be specified multiple times. The volumes of the current config and .sd-local.yaml are also mounted.
+      --watch                         Run the build again whenever the files in the source directory change, except the directories in the ignore file. The builds after the first one run in the build container kept with --reuse.
 
 `
 
+type mockSCM struct{}
+
+func (m mockSCM) Pull() error { return nil }
+
+func (m mockSCM) Kill(sig os.Signal) {}
+
+func (m mockSCM) Clean() {}
+
+func (m mockSCM) LocalPath() string { return "/src" }
+
+type mockStepsAPI struct{ mockAPI }
+
+func (mock mockStepsAPI) Job(jobName, filePath string) (screwdriver.Job, error) {
+	return screwdriver.Job{Steps: []screwdriver.Step{
+		{Name: "install", Command: "npm install"},
+		{Name: "test", Command: "npm test"},
+		{Name: "publish", Command: "npm publish"},
+	}}, nil
+}
+
+func (mock mockStepsAPI) Validate(filePath string) (screwdriver.Validation, error) {
+	job, _ := mock.Job("test", filePath)
+	return screwdriver.Validation{Jobs: map[string]screwdriver.Job{"test": job}}, nil
+}
+
 func TestBuildCmd(t *testing.T) {
 	t.Run("Success build cmd", func(t *testing.T) {
 		root := newBuildCmd()
@@ -88,6 +179,8 @@ func TestBuildCmd(t *testing.T) {
 			"foo":  "bar",
 		}
 
+		origLaunchNew := launchNew
+		t.Cleanup(func() { launchNew = origLaunchNew })
 		launchNew = func(option launch.Option) launch.Launcher {
 			assert.Equal(t, expected, option.OptionEnv)
 			return mockLaunch{}
@@ -112,6 +205,8 @@ func TestBuildCmd(t *testing.T) {
 			"foo":  "bar",
 		}
 
+		origLaunchNew := launchNew
+		t.Cleanup(func() { launchNew = origLaunchNew })
 		launchNew = func(option launch.Option) launch.Launcher {
 			assert.Equal(t, expected, option.OptionEnv)
 			return mockLaunch{}
@@ -137,6 +232,8 @@ func TestBuildCmd(t *testing.T) {
 			"baz":  "qux",
 		}
 
+		origLaunchNew := launchNew
+		t.Cleanup(func() { launchNew = origLaunchNew })
 		launchNew = func(option launch.Option) launch.Launcher {
 			assert.Equal(t, expected, option.OptionEnv)
 			return mockLaunch{}
@@ -149,6 +246,290 @@ func TestBuildCmd(t *testing.T) {
 		assert.Equal(t, "sd-artifacts", artifactsDir)
 	})
 
+	t.Run("Success build cmd with --ssh-agent=false", func(t *testing.T) {
+		defer func() {
+			setup()
+		}()
+
+		root := newBuildCmd()
+		root.SetArgs([]string{"test", "--socket", "/tmp/agent.sock", "--ssh-agent=false"})
+		buf := bytes.NewBuffer(nil)
+		root.SetOut(buf)
+
+		origLaunchNew := launchNew
+		t.Cleanup(func() { launchNew = origLaunchNew })
+		launchNew = func(option launch.Option) launch.Launcher {
+			assert.Equal(t, "", option.SocketPath)
+			return mockLaunch{}
+		}
+
+		err := root.Execute()
+		assert.Equal(t, "", buf.String())
+		assert.Nil(t, err)
+	})
+
+	t.Run("Success build cmd with --launcher-version and --launcher-image", func(t *testing.T) {
+		defer func() {
+			setup()
+		}()
+		configNew = func(confPath string) (config.Config, error) {
+			return config.Config{
+				Entries: map[string]*config.Entry{
+					"default": {Launcher: config.Launcher{Version: "stable", Image: "screwdrivercd/launcher"}},
+				},
+				Current: "default",
+			}, nil
+		}
+
+		root := newBuildCmd()
+		root.SetArgs([]string{"test", "--launcher-version", "v6.0.150", "--launcher-image", "registry.example.com/launcher"})
+		buf := bytes.NewBuffer(nil)
+		root.SetOut(buf)
+
+		origLaunchNew := launchNew
+		t.Cleanup(func() { launchNew = origLaunchNew })
+		launchNew = func(option launch.Option) launch.Launcher {
+			assert.Equal(t, config.Launcher{Version: "v6.0.150", Image: "registry.example.com/launcher"}, option.Entry.Launcher)
+			return mockLaunch{}
+		}
+
+		err := root.Execute()
+		assert.Equal(t, "", buf.String())
+		assert.Nil(t, err)
+	})
+
+	t.Run("Failed build cmd with --ssh-agent when the agent is not available", func(t *testing.T) {
+		defer func() {
+			checkSSHAgent = launch.CheckSSHAgent
+		}()
+		checkSSHAgent = func(socketPath string) error {
+			assert.Equal(t, "/tmp/agent.sock", socketPath)
+			return errors.New("SSH agent is not available: stat /tmp/agent.sock: no such file or directory")
+		}
+
+		root := newBuildCmd()
+		root.SetArgs([]string{"test", "--socket", "/tmp/agent.sock", "--ssh-agent"})
+		buf := bytes.NewBuffer(nil)
+		root.SetOut(buf)
+
+		err := root.Execute()
+		assert.Equal(t, "Error: SSH agent is not available: stat /tmp/agent.sock: no such file or directory\n", buf.String())
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Success build cmd with --mount-docker-socket", func(t *testing.T) {
+		defer func() {
+			setup()
+			dockerSocket = launch.DockerSocketVolume
+		}()
+		configNew = func(confPath string) (config.Config, error) {
+			return config.Config{
+				Entries: map[string]*config.Entry{
+					"default": {AllowDockerSocket: true},
+				},
+				Current: "default",
+			}, nil
+		}
+		dockerSocket = func(runtime string) (string, error) {
+			assert.Equal(t, "podman", runtime)
+			return "/run/podman/podman.sock:/var/run/docker.sock", nil
+		}
+
+		root := newBuildCmd()
+		root.SetArgs([]string{"test", "--runtime", "podman", "--mount-docker-socket"})
+		buf := bytes.NewBuffer(nil)
+		root.SetOut(buf)
+
+		origLaunchNew := launchNew
+		t.Cleanup(func() { launchNew = origLaunchNew })
+		launchNew = func(option launch.Option) launch.Launcher {
+			assert.Contains(t, option.Volumes, "/run/podman/podman.sock:/var/run/docker.sock")
+			return mockLaunch{}
+		}
+
+		err := root.Execute()
+		assert.Equal(t, "", buf.String())
+		assert.Nil(t, err)
+	})
+
+	t.Run("Failed build cmd with --mount-docker-socket when it is not allowed", func(t *testing.T) {
+		defer func() {
+			dockerSocket = launch.DockerSocketVolume
+		}()
+		dockerSocket = func(runtime string) (string, error) {
+			t.Fatal("the docker socket must not be mounted")
+			return "", nil
+		}
+
+		root := newBuildCmd()
+		root.SetArgs([]string{"test", "--mount-docker-socket"})
+		buf := bytes.NewBuffer(nil)
+		root.SetOut(buf)
+
+		err := root.Execute()
+		assert.Equal(t, "Error: `mount-docker-socket` gives the build root access to the host, so it must be allowed with `sd-local config set allow-docker-socket true`\n", buf.String())
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Success build cmd with --build-image", func(t *testing.T) {
+		defer func() {
+			setup()
+		}()
+
+		dir, err := ioutil.TempDir("", "build-image")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		dockerfile := filepath.Join(dir, "Dockerfile")
+		if err := ioutil.WriteFile(dockerfile, []byte("FROM node:18\n"), 0666); err != nil {
+			t.Fatal(err)
+		}
+
+		root := newBuildCmd()
+		root.SetArgs([]string{"test", "--build-image", dockerfile + ":ci"})
+		buf := bytes.NewBuffer(nil)
+		root.SetOut(buf)
+
+		origLaunchNew := launchNew
+		t.Cleanup(func() { launchNew = origLaunchNew })
+		launchNew = func(option launch.Option) launch.Launcher {
+			assert.Equal(t, dockerfile+":ci", option.Job.Image)
+			return mockLaunch{}
+		}
+
+		err = root.Execute()
+		assert.Equal(t, "", buf.String())
+		assert.Nil(t, err)
+	})
+
+	t.Run("Failed build cmd with --build-image without Dockerfile", func(t *testing.T) {
+		root := newBuildCmd()
+		root.SetArgs([]string{"test", "--build-image", "./notFound/Dockerfile"})
+		buf := bytes.NewBuffer(nil)
+		root.SetOut(buf)
+
+		err := root.Execute()
+		assert.Contains(t, buf.String(), "Error: failed to find Dockerfile: stat ")
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Success build cmd with --cpus", func(t *testing.T) {
+		defer func() {
+			setup()
+		}()
+
+		root := newBuildCmd()
+		root.SetArgs([]string{"test", "--cpus", "0.5"})
+		buf := bytes.NewBuffer(nil)
+		root.SetOut(buf)
+
+		origLaunchNew := launchNew
+		t.Cleanup(func() { launchNew = origLaunchNew })
+		launchNew = func(option launch.Option) launch.Launcher {
+			assert.Equal(t, "0.5", option.CPUs)
+			return mockLaunch{}
+		}
+
+		err := root.Execute()
+		assert.Equal(t, "", buf.String())
+		assert.Nil(t, err)
+	})
+
+	t.Run("Success build cmd with --platform", func(t *testing.T) {
+		defer func() {
+			setup()
+		}()
+		configNew = func(confPath string) (config.Config, error) {
+			return config.Config{
+				Entries: map[string]*config.Entry{
+					"default": {Platform: "linux/arm64"},
+				},
+				Current: "default",
+			}, nil
+		}
+
+		root := newBuildCmd()
+		root.SetArgs([]string{"test", "--platform", "linux/amd64"})
+		buf := bytes.NewBuffer(nil)
+		root.SetOut(buf)
+
+		origLaunchNew := launchNew
+		t.Cleanup(func() { launchNew = origLaunchNew })
+		launchNew = func(option launch.Option) launch.Launcher {
+			assert.Equal(t, "linux/amd64", option.Platform)
+			return mockLaunch{}
+		}
+
+		err := root.Execute()
+		assert.Equal(t, "", buf.String())
+		assert.Nil(t, err)
+	})
+
+	t.Run("Success build cmd with --pull", func(t *testing.T) {
+		defer func() {
+			setup()
+		}()
+
+		root := newBuildCmd()
+		root.SetArgs([]string{"test", "--pull", "never"})
+		buf := bytes.NewBuffer(nil)
+		root.SetOut(buf)
+
+		origLaunchNew := launchNew
+		t.Cleanup(func() { launchNew = origLaunchNew })
+		launchNew = func(option launch.Option) launch.Launcher {
+			assert.Equal(t, launch.PullNever, option.Pull)
+			return mockLaunch{}
+		}
+
+		err := root.Execute()
+		assert.Equal(t, "", buf.String())
+		assert.Nil(t, err)
+	})
+
+	t.Run("Success build cmd with project file", func(t *testing.T) {
+		defer func() {
+			setup()
+		}()
+		readProject = func(dir string) (config.Project, error) {
+			return config.Project{
+				Job:     "test",
+				Memory:  "2g",
+				CPUs:    "2",
+				Env:     map[string]string{"hoge": "fuga", "foo": "bar"},
+				Volumes: []string{"/repo/data:/data"},
+			}, nil
+		}
+
+		root := newBuildCmd()
+
+		root.SetArgs([]string{"--env", "hoge=overwritten"})
+		buf := bytes.NewBuffer(nil)
+		root.SetOut(buf)
+
+		expected := launch.EnvVar{
+			"hoge": "overwritten",
+			"foo":  "bar",
+		}
+
+		origLaunchNew := launchNew
+		t.Cleanup(func() { launchNew = origLaunchNew })
+		launchNew = func(option launch.Option) launch.Launcher {
+			assert.Equal(t, expected, option.OptionEnv)
+			assert.Equal(t, "2g", option.Memory)
+			assert.Equal(t, "2", option.CPUs)
+			assert.Equal(t, []string{"/repo/data:/data"}, option.Volumes)
+			assert.Equal(t, "test", option.JobName)
+			return mockLaunch{}
+		}
+
+		err := root.Execute()
+		want := ""
+		assert.Equal(t, want, buf.String())
+		assert.Nil(t, err)
+	})
+
 	t.Run("Success build cmd with --meta", func(t *testing.T) {
 		root := newBuildCmd()
 
@@ -160,6 +541,41 @@ func TestBuildCmd(t *testing.T) {
 			"hoge": "fuga",
 		}
 
+		origLaunchNew := launchNew
+		t.Cleanup(func() { launchNew = origLaunchNew })
+		launchNew = func(option launch.Option) launch.Launcher {
+			assert.Equal(t, expected, option.Meta)
+			return mockLaunch{}
+		}
+
+		err := root.Execute()
+		want := ""
+		assert.Equal(t, want, buf.String())
+		assert.Nil(t, err)
+	})
+
+	t.Run("Success build cmd with stored meta", func(t *testing.T) {
+		defer func() {
+			readMeta = launch.ReadMeta
+		}()
+
+		readMeta = func(dir string) (launch.Meta, error) {
+			return launch.Meta{"hoge": "stored", "foo": "stored"}, nil
+		}
+
+		root := newBuildCmd()
+
+		root.SetArgs([]string{"test", "--meta", "{\"hoge\":\"fuga\"}"})
+		buf := bytes.NewBuffer(nil)
+		root.SetOut(buf)
+
+		expected := launch.Meta{
+			"hoge": "fuga",
+			"foo":  "stored",
+		}
+
+		origLaunchNew := launchNew
+		t.Cleanup(func() { launchNew = origLaunchNew })
 		launchNew = func(option launch.Option) launch.Launcher {
 			assert.Equal(t, expected, option.Meta)
 			return mockLaunch{}
@@ -185,6 +601,8 @@ func TestBuildCmd(t *testing.T) {
 			},
 		}
 
+		origLaunchNew := launchNew
+		t.Cleanup(func() { launchNew = origLaunchNew })
 		launchNew = func(option launch.Option) launch.Launcher {
 			assert.Equal(t, expected, option.Meta)
 			return mockLaunch{}
@@ -196,43 +614,1080 @@ func TestBuildCmd(t *testing.T) {
 		assert.Nil(t, err)
 	})
 
-	t.Run("Failed build cmd with --meta and --meta-file", func(t *testing.T) {
+	t.Run("Success build cmd with --runtime", func(t *testing.T) {
 		root := newBuildCmd()
 
-		root.SetArgs([]string{"test", "--meta-file", "./testdata/test_meta.json", "--meta", "{\"hoge\":\"fuga\"}"})
+		root.SetArgs([]string{"test", "--runtime", "podman"})
 		buf := bytes.NewBuffer(nil)
 		root.SetOut(buf)
 
+		origLaunchNew := launchNew
+		t.Cleanup(func() { launchNew = origLaunchNew })
 		launchNew = func(option launch.Option) launch.Launcher {
+			assert.Equal(t, "podman", option.Runtime)
 			return mockLaunch{}
 		}
 
 		err := root.Execute()
-		want := "Error: can't pass the both options `meta` and `meta-file`, please specify only one of them" + buildUsage
-		assert.Equal(t, want, buf.String())
-		assert.NotNil(t, err)
+		assert.Equal(t, "", buf.String())
+		assert.Nil(t, err)
 	})
 
-	t.Run("Failed build cmd when too many args", func(t *testing.T) {
+	t.Run("Success build cmd with --pause-on-failure", func(t *testing.T) {
 		root := newBuildCmd()
-		root.SetArgs([]string{"test", "main"})
+
+		root.SetArgs([]string{"test", "--pause-on-failure"})
 		buf := bytes.NewBuffer(nil)
 		root.SetOut(buf)
+
+		origLaunchNew := launchNew
+		t.Cleanup(func() { launchNew = origLaunchNew })
+		launchNew = func(option launch.Option) launch.Launcher {
+			assert.True(t, option.PauseOnFailure)
+			return mockLaunch{}
+		}
+
 		err := root.Execute()
-		want := "Error: accepts 1 arg(s), received 2" + buildUsage
+		assert.Equal(t, "", buf.String())
+		assert.Nil(t, err)
+	})
+
+	t.Run("Failed build cmd with --pause-on-failure and --interactive", func(t *testing.T) {
+		root := newBuildCmd()
+		root.SetArgs([]string{"test", "--pause-on-failure", "--interactive"})
+		buf := bytes.NewBuffer(nil)
+		root.SetOut(buf)
+		err := root.Execute()
+		want := "Error: `pause-on-failure` can not be used in interactive mode" + buildUsage
 		assert.Equal(t, want, buf.String())
 		assert.NotNil(t, err)
 	})
 
-	t.Run("Failed build cmd when too little args", func(t *testing.T) {
+	t.Run("Success build cmd with --reuse", func(t *testing.T) {
 		root := newBuildCmd()
-		root.SetArgs([]string{})
 
+		root.SetArgs([]string{"test", "--reuse"})
+		buf := bytes.NewBuffer(nil)
+		root.SetOut(buf)
+
+		origLaunchNew := launchNew
+		t.Cleanup(func() { launchNew = origLaunchNew })
+		launchNew = func(option launch.Option) launch.Launcher {
+			assert.True(t, option.Reuse)
+			return mockLaunch{}
+		}
+
+		err := root.Execute()
+		assert.Equal(t, "", buf.String())
+		assert.Nil(t, err)
+	})
+
+	t.Run("Failed build cmd with --reuse and --pause-on-failure", func(t *testing.T) {
+		root := newBuildCmd()
+		root.SetArgs([]string{"test", "--reuse", "--pause-on-failure"})
 		buf := bytes.NewBuffer(nil)
 		root.SetOut(buf)
 		err := root.Execute()
-		want := "Error: accepts 1 arg(s), received 0" + buildUsage
+		want := "Error: `pause-on-failure` can not be used with `reuse`, because the build container is kept after the build" + buildUsage
 		assert.Equal(t, want, buf.String())
 		assert.NotNil(t, err)
 	})
+
+	t.Run("Success build cmd with --snapshot-after", func(t *testing.T) {
+		origAPINew := apiNew
+		t.Cleanup(func() { apiNew = origAPINew })
+		apiNew = func(url, token string, option screwdriver.Option) screwdriver.API { return mockStepsAPI{} }
+
+		root := newBuildCmd()
+
+		root.SetArgs([]string{"test", "--snapshot-after", "test"})
+		buf := bytes.NewBuffer(nil)
+		root.SetOut(buf)
+
+		origLaunchNew := launchNew
+		t.Cleanup(func() { launchNew = origLaunchNew })
+		launchNew = func(option launch.Option) launch.Launcher {
+			assert.Equal(t, "test", option.Snapshot.After)
+			assert.Equal(t, 1, len(option.Snapshot.Files))
+			return mockLaunch{}
+		}
+
+		err := root.Execute()
+		assert.Equal(t, "", buf.String())
+		assert.Nil(t, err)
+	})
+
+	t.Run("Failed build cmd with --snapshot-after and --reuse", func(t *testing.T) {
+		root := newBuildCmd()
+		root.SetArgs([]string{"test", "--snapshot-after", "test", "--reuse"})
+		buf := bytes.NewBuffer(nil)
+		root.SetOut(buf)
+		err := root.Execute()
+		want := "Error: `snapshot-after` can not be used with `reuse`, because the build container is kept after the build" + buildUsage
+		assert.Equal(t, want, buf.String())
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Success build cmd with --dry-run", func(t *testing.T) {
+		defer func() {
+			launchPlan = launch.NewPlan
+		}()
+		root := newBuildCmd()
+
+		root.SetArgs([]string{"test", "--dry-run"})
+		buf := bytes.NewBuffer(nil)
+		root.SetOut(buf)
+
+		origLaunchNew := launchNew
+		t.Cleanup(func() { launchNew = origLaunchNew })
+		launchNew = func(option launch.Option) launch.Launcher {
+			t.Error("the build must not be run with --dry-run")
+			return mockLaunch{}
+		}
+		launchPlan = func(option launch.Option) (launch.Plan, error) {
+			assert.Equal(t, "test", option.JobName)
+			return launch.Plan{Image: "node:12", Command: "docker container run node:12"}, nil
+		}
+
+		err := root.Execute()
+		assert.Nil(t, err)
+		assert.Contains(t, buf.String(), "Job: test\nImage: node:12\n")
+		assert.Contains(t, buf.String(), "Command:\n  docker container run node:12\n")
+	})
+
+	t.Run("Failed build cmd with --dry-run and --interactive", func(t *testing.T) {
+		root := newBuildCmd()
+		root.SetArgs([]string{"test", "--dry-run", "--interactive"})
+		buf := bytes.NewBuffer(nil)
+		root.SetOut(buf)
+		err := root.Execute()
+		want := "Error: `dry-run` can not be used in interactive mode" + buildUsage
+		assert.Equal(t, want, buf.String())
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Success build cmd with --step", func(t *testing.T) {
+		origAPINew := apiNew
+		t.Cleanup(func() { apiNew = origAPINew })
+		apiNew = func(url, token string, option screwdriver.Option) screwdriver.API { return mockStepsAPI{} }
+
+		root := newBuildCmd()
+		root.SetArgs([]string{"test", "--step", "test"})
+		root.SetOut(bytes.NewBuffer(nil))
+
+		origLaunchNew := launchNew
+		t.Cleanup(func() { launchNew = origLaunchNew })
+		launchNew = func(option launch.Option) launch.Launcher {
+			assert.Equal(t, []screwdriver.Step{{Name: "test", Command: "npm test"}}, option.Job.Steps)
+			return mockLaunch{}
+		}
+
+		err := root.Execute()
+		assert.Nil(t, err)
+	})
+
+	t.Run("Success build cmd with --from-step", func(t *testing.T) {
+		origAPINew := apiNew
+		t.Cleanup(func() { apiNew = origAPINew })
+		apiNew = func(url, token string, option screwdriver.Option) screwdriver.API { return mockStepsAPI{} }
+
+		root := newBuildCmd()
+		root.SetArgs([]string{"test", "--from-step", "test"})
+		root.SetOut(bytes.NewBuffer(nil))
+
+		origLaunchNew := launchNew
+		t.Cleanup(func() { launchNew = origLaunchNew })
+		launchNew = func(option launch.Option) launch.Launcher {
+			assert.Equal(t, []screwdriver.Step{{Name: "test", Command: "npm test"}, {Name: "publish", Command: "npm publish"}}, option.Job.Steps)
+			return mockLaunch{}
+		}
+
+		err := root.Execute()
+		assert.Nil(t, err)
+	})
+
+	t.Run("Success build cmd with --skip-step", func(t *testing.T) {
+		origAPINew := apiNew
+		t.Cleanup(func() { apiNew = origAPINew })
+		apiNew = func(url, token string, option screwdriver.Option) screwdriver.API { return mockStepsAPI{} }
+
+		root := newBuildCmd()
+		root.SetArgs([]string{"test", "--skip-step", "pub*", "--skip-step", "install", "--no-color"})
+		buf := bytes.NewBuffer(nil)
+		root.SetOut(buf)
+
+		origLaunchNew := launchNew
+		t.Cleanup(func() { launchNew = origLaunchNew })
+		launchNew = func(option launch.Option) launch.Launcher {
+			assert.Equal(t, []screwdriver.Step{{Name: "test", Command: "npm test"}}, option.Job.Steps)
+			return mockLaunch{}
+		}
+
+		err := root.Execute()
+		assert.Nil(t, err)
+		assert.Equal(t, "JOB   STEP     EXIT CODE  DURATION  STATUS\ntest  install  -          -         SKIPPED\ntest  publish  -          -         SKIPPED\n", buf.String())
+	})
+
+	t.Run("Failed build cmd with invalid --skip-step", func(t *testing.T) {
+		root := newBuildCmd()
+		root.SetArgs([]string{"test", "--skip-step", "notify-["})
+		buf := bytes.NewBuffer(nil)
+		root.SetOut(buf)
+		err := root.Execute()
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Success build cmd with --timeout and --step-timeout", func(t *testing.T) {
+		root := newBuildCmd()
+		root.SetArgs([]string{"test", "--timeout", "30m", "--step-timeout", "install=5m"})
+		root.SetOut(bytes.NewBuffer(nil))
+
+		origLaunchNew := launchNew
+		t.Cleanup(func() { launchNew = origLaunchNew })
+		launchNew = func(option launch.Option) launch.Launcher {
+			assert.Equal(t, launch.TimeoutOption{Build: 30 * time.Minute, Steps: map[string]time.Duration{"install": 5 * time.Minute}}, option.Timeout)
+			return mockLaunch{}
+		}
+
+		err := root.Execute()
+		assert.Nil(t, err)
+	})
+
+	t.Run("Failed build cmd with invalid --step-timeout", func(t *testing.T) {
+		root := newBuildCmd()
+		root.SetArgs([]string{"test", "--step-timeout", "install=forever"})
+		root.SetOut(bytes.NewBuffer(nil))
+		err := root.Execute()
+		assert.Contains(t, err.Error(), "failed to parse timeout of step install")
+	})
+
+	t.Run("Failed build cmd with not found --step", func(t *testing.T) {
+		root := newBuildCmd()
+		root.SetArgs([]string{"test", "--step", "lint"})
+		buf := bytes.NewBuffer(nil)
+		root.SetOut(buf)
+		err := root.Execute()
+		assert.Equal(t, "not found step 'lint' in the job", err.Error())
+	})
+
+	t.Run("Failed build cmd with --step and --from-step", func(t *testing.T) {
+		root := newBuildCmd()
+		root.SetArgs([]string{"test", "--step", "test", "--from-step", "test"})
+		buf := bytes.NewBuffer(nil)
+		root.SetOut(buf)
+		err := root.Execute()
+		want := "Error: can't pass the both options `step` and `from-step`, please specify only one of them" + buildUsage
+		assert.Equal(t, want, buf.String())
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Failed build cmd with --step and multiple jobs", func(t *testing.T) {
+		root := newBuildCmd()
+		root.SetArgs([]string{"main,test", "--step", "test"})
+		buf := bytes.NewBuffer(nil)
+		root.SetOut(buf)
+		err := root.Execute()
+		want := "Error: `step` and `from-step` can be used only with a single job" + buildUsage
+		assert.Equal(t, want, buf.String())
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Success build cmd with --offline", func(t *testing.T) {
+		origAPINew := apiNew
+		t.Cleanup(func() { apiNew = origAPINew })
+		origLocalAPINew := localAPINew
+		t.Cleanup(func() { localAPINew = origLocalAPINew })
+
+		apiNew = func(url, token string, option screwdriver.Option) screwdriver.API {
+			t.Fatal("API must not be called in offline mode")
+			return nil
+		}
+		localAPINew = func(templateDir string) screwdriver.API { return mockAPI{} }
+
+		root := newBuildCmd()
+		root.SetArgs([]string{"test", "--offline"})
+		buf := bytes.NewBuffer(nil)
+		root.SetOut(buf)
+
+		err := root.Execute()
+		assert.Equal(t, "", buf.String())
+		assert.Nil(t, err)
+	})
+
+	t.Run("Failed build cmd with --strict and warnings", func(t *testing.T) {
+		origAPINew := apiNew
+		t.Cleanup(func() { apiNew = origAPINew })
+		apiNew = func(url, token string, option screwdriver.Option) screwdriver.API { return mockWarningsAPI{} }
+
+		root := newBuildCmd()
+		root.SetArgs([]string{"test", "--strict"})
+		root.SetOut(bytes.NewBuffer(nil))
+
+		err := root.Execute()
+		assert.Equal(t, "screwdriver.yaml has 1 warnings, which fail the build with --strict", err.Error())
+	})
+
+	t.Run("Success build cmd when API is unreachable", func(t *testing.T) {
+		origAPINew := apiNew
+		t.Cleanup(func() { apiNew = origAPINew })
+		origLocalAPINew := localAPINew
+		t.Cleanup(func() { localAPINew = origLocalAPINew })
+
+		localCalled := false
+		apiNew = func(url, token string, option screwdriver.Option) screwdriver.API { return mockUnreachableAPI{} }
+		localAPINew = func(templateDir string) screwdriver.API {
+			localCalled = true
+			return mockAPI{}
+		}
+
+		root := newBuildCmd()
+		root.SetArgs([]string{"test"})
+		buf := bytes.NewBuffer(nil)
+		root.SetOut(buf)
+
+		err := root.Execute()
+		assert.Nil(t, err)
+		assert.True(t, localCalled)
+	})
+
+	t.Run("Success build cmd with --all", func(t *testing.T) {
+		root := newBuildCmd()
+		root.SetArgs([]string{"--all"})
+		buf := bytes.NewBuffer(nil)
+		root.SetOut(buf)
+
+		artifactsPath, _ := filepath.Abs("sd-artifacts")
+		jobNames := make([]string, 0)
+		origLaunchNew := launchNew
+		t.Cleanup(func() { launchNew = origLaunchNew })
+		launchNew = func(option launch.Option) launch.Launcher {
+			jobNames = append(jobNames, option.JobName)
+			assert.Equal(t, filepath.Join(artifactsPath, option.JobName), option.ArtifactsPath)
+			return mockLaunch{}
+		}
+
+		err := root.Execute()
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"main", "test", "publish"}, jobNames)
+	})
+
+	t.Run("Success build cmd with PR job name", func(t *testing.T) {
+		root := newBuildCmd()
+		root.SetArgs([]string{"PR-12:test", "--pr-branch", "develop"})
+		buf := bytes.NewBuffer(nil)
+		root.SetOut(buf)
+
+		var option launch.Option
+		origLaunchNew := launchNew
+		t.Cleanup(func() { launchNew = origLaunchNew })
+		launchNew = func(o launch.Option) launch.Launcher {
+			option = o
+			return mockLaunch{}
+		}
+
+		err := root.Execute()
+		assert.Nil(t, err)
+		assert.Equal(t, "test", option.JobName)
+		assert.Equal(t, launch.PullRequest{Number: 12, BaseBranch: "develop"}, option.PullRequest)
+	})
+
+	t.Run("Success build cmd with source options", func(t *testing.T) {
+		defer func() {
+			setup()
+		}()
+
+		readCheckout = func(dir string) scm.Checkout {
+			return scm.Checkout{URL: "git@github.com:screwdriver-cd/sd-local.git", Branch: "feature", SHA: "abc123"}
+		}
+
+		root := newBuildCmd()
+		root.SetArgs([]string{"test", "--sha", "def456", "--pipeline-id", "123"})
+		buf := bytes.NewBuffer(nil)
+		root.SetOut(buf)
+
+		var source launch.SourceOption
+		origLaunchNew := launchNew
+		t.Cleanup(func() { launchNew = origLaunchNew })
+		launchNew = func(option launch.Option) launch.Launcher {
+			source = option.Source
+			return mockLaunch{}
+		}
+
+		err := root.Execute()
+		assert.Nil(t, err)
+		assert.Equal(t, launch.SourceOption{
+			URL:        "git@github.com:screwdriver-cd/sd-local.git",
+			Branch:     "feature",
+			SHA:        "def456",
+			PipelineID: 123,
+		}, source)
+	})
+
+	t.Run("Success build cmd with --src-url, --shallow and --sha", func(t *testing.T) {
+		defer func() {
+			scmNew = scm.New
+		}()
+
+		var clone scm.CloneOption
+		scmNew = func(baseDir, srcURL string, sudo bool, option scm.CloneOption) (scm.SCM, error) {
+			assert.Equal(t, "git@github.com:screwdriver-cd/sd-local.git", srcURL)
+			clone = option
+			return mockSCM{}, nil
+		}
+
+		root := newBuildCmd()
+		root.SetArgs([]string{"test", "--src-url", "git@github.com:screwdriver-cd/sd-local.git", "--shallow", "--sha", "abc123"})
+		buf := bytes.NewBuffer(nil)
+		root.SetOut(buf)
+
+		err := root.Execute()
+		assert.Nil(t, err)
+		assert.Equal(t, scm.CloneOption{Shallow: true, SHA: "abc123"}, clone)
+	})
+
+	t.Run("Failed build cmd with --all and --pr without PR jobs", func(t *testing.T) {
+		root := newBuildCmd()
+		root.SetArgs([]string{"--all", "--pr", "12"})
+		buf := bytes.NewBuffer(nil)
+		root.SetOut(buf)
+
+		err := root.Execute()
+		assert.Equal(t, "no jobs are triggered by pull requests in screwdriver.yaml", err.Error())
+	})
+
+	t.Run("Failed build cmd with --all stops on first failure", func(t *testing.T) {
+		root := newBuildCmd()
+		root.SetArgs([]string{"--all"})
+		buf := bytes.NewBuffer(nil)
+		root.SetOut(buf)
+
+		jobNames := make([]string, 0)
+		origLaunchNew := launchNew
+		t.Cleanup(func() { launchNew = origLaunchNew })
+		launchNew = func(option launch.Option) launch.Launcher {
+			jobNames = append(jobNames, option.JobName)
+			if option.JobName == "test" {
+				return mockFailedLaunch{}
+			}
+			return mockLaunch{}
+		}
+
+		err := root.Execute()
+		assert.Equal(t, "job test failed: build failed", err.Error())
+		assert.Equal(t, []string{"main", "test"}, jobNames)
+	})
+
+	t.Run("Failed build cmd with --all and --continue-on-error", func(t *testing.T) {
+		root := newBuildCmd()
+		root.SetArgs([]string{"--all", "--continue-on-error"})
+		buf := bytes.NewBuffer(nil)
+		root.SetOut(buf)
+
+		jobNames := make([]string, 0)
+		origLaunchNew := launchNew
+		t.Cleanup(func() { launchNew = origLaunchNew })
+		launchNew = func(option launch.Option) launch.Launcher {
+			jobNames = append(jobNames, option.JobName)
+			if option.JobName == "test" {
+				return mockFailedLaunch{}
+			}
+			return mockLaunch{}
+		}
+
+		err := root.Execute()
+		assert.Equal(t, "failed jobs: [test]", err.Error())
+		assert.Equal(t, []string{"main", "test"}, jobNames)
+		assert.Contains(t, buf.String(), "publish  SKIPPED  -\n")
+	})
+
+	t.Run("Failed build cmd with --continue-on-error but without --all", func(t *testing.T) {
+		root := newBuildCmd()
+		root.SetArgs([]string{"test", "--continue-on-error"})
+		buf := bytes.NewBuffer(nil)
+		root.SetOut(buf)
+
+		err := root.Execute()
+		want := "Error: `continue-on-error` can be used only with multiple jobs" + buildUsage
+		assert.Equal(t, want, buf.String())
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Success build cmd with --secrets-file", func(t *testing.T) {
+		root := newBuildCmd()
+
+		root.SetArgs([]string{"test", "--secrets-file", "./testdata/test_secrets.yaml"})
+		buf := bytes.NewBuffer(nil)
+		root.SetOut(buf)
+
+		expected := launch.EnvVar{
+			"GIT_KEY":   "git-key",
+			"NPM_TOKEN": "npm-token",
+		}
+
+		origLaunchNew := launchNew
+		t.Cleanup(func() { launchNew = origLaunchNew })
+		launchNew = func(option launch.Option) launch.Launcher {
+			assert.Equal(t, expected, option.Secrets)
+			return mockLaunch{}
+		}
+
+		err := root.Execute()
+		want := ""
+		assert.Equal(t, want, buf.String())
+		assert.Nil(t, err)
+	})
+
+	t.Run("Failed build cmd with nonexistent --secrets-file", func(t *testing.T) {
+		root := newBuildCmd()
+
+		root.SetArgs([]string{"test", "--secrets-file", "./testdata/nonexistent.yaml"})
+		buf := bytes.NewBuffer(nil)
+		root.SetOut(buf)
+
+		err := root.Execute()
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "failed to read secrets file")
+	})
+
+	t.Run("Success build cmd with secrets in Vault", func(t *testing.T) {
+		defer func() {
+			setup()
+		}()
+
+		configNew = func(confPath string) (config.Config, error) {
+			return config.Config{
+				Entries: map[string]*config.Entry{
+					"default": {VaultAddr: "https://vault.example.com", VaultPath: "secret/data/sd-local"},
+				},
+				Current: "default",
+			}, nil
+		}
+		vaultNew = func(addr, secretPath string) secrets.Provider {
+			assert.Equal(t, "https://vault.example.com", addr)
+			assert.Equal(t, "secret/data/sd-local", secretPath)
+			return mockSecretsProvider{"GIT_KEY": "vault-git-key", "AWS_KEY": "vault-aws-key"}
+		}
+
+		root := newBuildCmd()
+
+		root.SetArgs([]string{"test", "--secrets-file", "./testdata/test_secrets.yaml"})
+		buf := bytes.NewBuffer(nil)
+		root.SetOut(buf)
+
+		expected := launch.EnvVar{
+			"GIT_KEY":   "git-key",
+			"NPM_TOKEN": "npm-token",
+			"AWS_KEY":   "vault-aws-key",
+		}
+
+		origLaunchNew := launchNew
+		t.Cleanup(func() { launchNew = origLaunchNew })
+		launchNew = func(option launch.Option) launch.Launcher {
+			assert.Equal(t, expected, option.Secrets)
+			return mockLaunch{}
+		}
+
+		err := root.Execute()
+		assert.Nil(t, err)
+	})
+
+	t.Run("Failed build cmd with vault-addr but without vault-path", func(t *testing.T) {
+		defer func() {
+			setup()
+		}()
+
+		configNew = func(confPath string) (config.Config, error) {
+			return config.Config{
+				Entries: map[string]*config.Entry{
+					"default": {VaultAddr: "https://vault.example.com"},
+				},
+				Current: "default",
+			}, nil
+		}
+
+		root := newBuildCmd()
+
+		root.SetArgs([]string{"test"})
+		buf := bytes.NewBuffer(nil)
+		root.SetOut(buf)
+
+		err := root.Execute()
+		assert.Equal(t, "both of `vault-addr` and `vault-path` must be set to read secrets from Vault", err.Error())
+	})
+
+	t.Run("Success build cmd with cache", func(t *testing.T) {
+		defer func() {
+			setup()
+		}()
+
+		settings := screwdriver.Cache{
+			Pipeline: []string{"~/.gradle"},
+			Job:      map[string][]string{"test": {"node_modules"}},
+		}
+		readCache = func(filePath string) (screwdriver.Cache, error) { return settings, nil }
+
+		var mkdirs []string
+		osMkdirAll = func(path string, filemode os.FileMode) error {
+			mkdirs = append(mkdirs, path)
+			return nil
+		}
+
+		root := newBuildCmd()
+		root.SetArgs([]string{"test"})
+		buf := bytes.NewBuffer(nil)
+		root.SetOut(buf)
+
+		var cache launch.CacheOption
+		origLaunchNew := launchNew
+		t.Cleanup(func() { launchNew = origLaunchNew })
+		launchNew = func(option launch.Option) launch.Launcher {
+			cache = option.Cache
+			return mockLaunch{}
+		}
+
+		err := root.Execute()
+		assert.Nil(t, err)
+		assert.Equal(t, settings, cache.Settings)
+		assert.Contains(t, cache.PipelineDir, filepath.Join(".sdlocal", "cache"))
+		assert.Contains(t, mkdirs, cache.PipelineDir)
+		assert.Contains(t, mkdirs, filepath.Join(cache.JobDir, "test"))
+	})
+
+	t.Run("Success build cmd with --param", func(t *testing.T) {
+		defer func() {
+			setup()
+		}()
+
+		readParameters = func(filePath string) (map[string]screwdriver.Parameter, error) {
+			return map[string]screwdriver.Parameter{
+				"region": {Value: "us-west-1"},
+				"az":     {Value: "zone-a"},
+			}, nil
+		}
+
+		root := newBuildCmd()
+		root.SetArgs([]string{"test", "--param", "az=zone-c"})
+		buf := bytes.NewBuffer(nil)
+		root.SetOut(buf)
+
+		var parameters map[string]string
+		origLaunchNew := launchNew
+		t.Cleanup(func() { launchNew = origLaunchNew })
+		launchNew = func(option launch.Option) launch.Launcher {
+			parameters = option.Parameters
+			return mockLaunch{}
+		}
+
+		err := root.Execute()
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]string{"region": "us-west-1", "az": "zone-c"}, parameters)
+	})
+
+	t.Run("Failure build cmd with undefined --param", func(t *testing.T) {
+		root := newBuildCmd()
+		root.SetArgs([]string{"test", "--param", "region=us-west-1"})
+		buf := bytes.NewBuffer(nil)
+		root.SetOut(buf)
+
+		err := root.Execute()
+		assert.Equal(t, "parameter region is not defined in screwdriver.yaml", err.Error())
+	})
+
+	t.Run("Success build cmd with --use-pipeline-secrets", func(t *testing.T) {
+		defer func() {
+			stdin = os.Stdin
+		}()
+		stdin = strings.NewReader("y\n")
+
+		root := newBuildCmd()
+
+		root.SetArgs([]string{"test", "--use-pipeline-secrets", "123", "--secrets-file", "./testdata/test_secrets.yaml"})
+		buf := bytes.NewBuffer(nil)
+		root.SetOut(buf)
+
+		expected := launch.EnvVar{
+			"GIT_KEY":   "git-key",
+			"NPM_TOKEN": "npm-token",
+		}
+
+		origLaunchNew := launchNew
+		t.Cleanup(func() { launchNew = origLaunchNew })
+		launchNew = func(option launch.Option) launch.Launcher {
+			assert.Equal(t, expected, option.Secrets)
+			return mockLaunch{}
+		}
+
+		err := root.Execute()
+		want := "Secrets [GIT_KEY NPM_TOKEN] of pipeline 123 are set in the build container if the job lists them. Continue? [y/N]: "
+		assert.Equal(t, want, buf.String())
+		assert.Nil(t, err)
+	})
+
+	t.Run("Success build cmd with --use-pipeline-secrets aborted", func(t *testing.T) {
+		defer func() {
+			stdin = os.Stdin
+		}()
+		stdin = strings.NewReader("n\n")
+
+		root := newBuildCmd()
+
+		root.SetArgs([]string{"test", "--use-pipeline-secrets", "123"})
+		buf := bytes.NewBuffer(nil)
+		root.SetOut(buf)
+
+		origLaunchNew := launchNew
+		t.Cleanup(func() { launchNew = origLaunchNew })
+		launchNew = func(option launch.Option) launch.Launcher {
+			t.Error("build must not be launched")
+			return mockLaunch{}
+		}
+
+		err := root.Execute()
+		assert.Nil(t, err)
+	})
+
+	t.Run("Failed build cmd with --use-pipeline-secrets and --offline", func(t *testing.T) {
+		root := newBuildCmd()
+		root.SetArgs([]string{"test", "--use-pipeline-secrets", "123", "--offline"})
+		buf := bytes.NewBuffer(nil)
+		root.SetOut(buf)
+
+		err := root.Execute()
+		want := "Error: `use-pipeline-secrets` can not be used in offline mode" + buildUsage
+		assert.Equal(t, want, buf.String())
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Failed build cmd with --meta and --meta-file", func(t *testing.T) {
+		root := newBuildCmd()
+
+		root.SetArgs([]string{"test", "--meta-file", "./testdata/test_meta.json", "--meta", "{\"hoge\":\"fuga\"}"})
+		buf := bytes.NewBuffer(nil)
+		root.SetOut(buf)
+
+		origLaunchNew := launchNew
+		t.Cleanup(func() { launchNew = origLaunchNew })
+		launchNew = func(option launch.Option) launch.Launcher {
+			return mockLaunch{}
+		}
+
+		err := root.Execute()
+		want := "Error: can't pass the both options `meta` and `meta-file`, please specify only one of them" + buildUsage
+		assert.Equal(t, want, buf.String())
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Failed build cmd when too many args", func(t *testing.T) {
+		root := newBuildCmd()
+		root.SetArgs([]string{"test", "main"})
+		buf := bytes.NewBuffer(nil)
+		root.SetOut(buf)
+		err := root.Execute()
+		want := "Error: accepts 1 arg(s), received 2" + buildUsage
+		assert.Equal(t, want, buf.String())
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Failed build cmd by invalid volume", func(t *testing.T) {
+		root := newBuildCmd()
+		root.SetArgs([]string{"test", "--volume", "/data"})
+
+		buf := bytes.NewBuffer(nil)
+		root.SetOut(buf)
+		err := root.Execute()
+		want := "Error: invalid volume /data, must be <host path>:<container path>[:ro]" + buildUsage
+		assert.Equal(t, want, buf.String())
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Failed build cmd by invalid cpus", func(t *testing.T) {
+		root := newBuildCmd()
+		root.SetArgs([]string{"test", "--cpus", "many"})
+
+		buf := bytes.NewBuffer(nil)
+		root.SetOut(buf)
+		err := root.Execute()
+		want := "Error: invalid cpus many, must be a positive number (e.g. 0.5, 2)" + buildUsage
+		assert.Equal(t, want, buf.String())
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Failed build cmd by invalid platform", func(t *testing.T) {
+		root := newBuildCmd()
+		root.SetArgs([]string{"test", "--platform", "linux/s390x"})
+
+		buf := bytes.NewBuffer(nil)
+		root.SetOut(buf)
+		err := root.Execute()
+		want := "Error: invalid platform linux/s390x, must be one of [linux/amd64 linux/arm64]" + buildUsage
+		assert.Equal(t, want, buf.String())
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Failed build cmd by invalid pull policy", func(t *testing.T) {
+		root := newBuildCmd()
+		root.SetArgs([]string{"test", "--pull", "sometimes"})
+
+		buf := bytes.NewBuffer(nil)
+		root.SetOut(buf)
+		err := root.Execute()
+		want := "Error: `pull` must be one of [always missing never]" + buildUsage
+		assert.Equal(t, want, buf.String())
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Failed build cmd when too little args", func(t *testing.T) {
+		root := newBuildCmd()
+		root.SetArgs([]string{})
+
+		buf := bytes.NewBuffer(nil)
+		root.SetOut(buf)
+		err := root.Execute()
+		want := "Error: requires a job name, or job in .sd-local.yaml" + buildUsage
+		assert.Equal(t, want, buf.String())
+		assert.NotNil(t, err)
+	})
+}
+
+func TestConfirmPipelineSecrets(t *testing.T) {
+	defer func() {
+		stdin = os.Stdin
+	}()
+
+	testCases := []struct {
+		name      string
+		input     string
+		expect    bool
+		expectErr bool
+	}{
+		{name: "yes", input: "y\n", expect: true},
+		{name: "no", input: "N\n", expect: false},
+		{name: "empty", input: "\n", expect: false},
+		{name: "EOF", input: "", expect: false},
+		{name: "invalid input", input: "maybe\n", expect: false, expectErr: true},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			stdin = strings.NewReader(tt.input)
+			buf := bytes.NewBuffer(nil)
+
+			ok, err := confirmPipelineSecrets(123, map[string]string{"B": "b", "A": "a"}, buf)
+			assert.Equal(t, tt.expect, ok)
+			if tt.expectErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.Equal(t, "Secrets [A B] of pipeline 123 are set in the build container if the job lists them. Continue? [y/N]: ", buf.String())
+		})
+	}
+}
+
+func TestDefaultJobName(t *testing.T) {
+	defer func() {
+		setup()
+	}()
+
+	t.Run("success", func(t *testing.T) {
+		readProject = func(dir string) (config.Project, error) { return config.Project{Job: "main"}, nil }
+		name, err := defaultJobName()
+		assert.Nil(t, err)
+		assert.Equal(t, "main", name)
+	})
+
+	t.Run("failure without job", func(t *testing.T) {
+		readProject = func(dir string) (config.Project, error) { return config.Project{}, nil }
+		_, err := defaultJobName()
+		assert.Equal(t, "requires a job name, or job in .sd-local.yaml", err.Error())
+	})
+
+	t.Run("failure by reading project file", func(t *testing.T) {
+		readProject = func(dir string) (config.Project, error) {
+			return config.Project{}, errors.New("failed to parse .sd-local.yaml: broken")
+		}
+		_, err := defaultJobName()
+		assert.Equal(t, "failed to parse .sd-local.yaml: broken", err.Error())
+	})
+}
+
+func TestBuildVolumes(t *testing.T) {
+	volumes := buildVolumes("/repo", []string{"/opt/sdk:/opt/sdk:ro"}, []string{"/repo/data:/data"}, []string{"./cache:/cache", "gocache:/root/.cache/go-build"})
+	assert.Equal(t, []string{"/opt/sdk:/opt/sdk:ro", "/repo/data:/data", "/repo/cache:/cache", "gocache:/root/.cache/go-build"}, volumes)
+
+	assert.Equal(t, []string{}, buildVolumes("/repo", nil, nil, nil))
+}
+
+func TestBuildServices(t *testing.T) {
+	projectServices := []config.Service{{Name: "db", Image: "postgres:13", HealthCheck: "pg_isready"}}
+
+	services, err := buildServices(projectServices, []string{"redis:7", "queue=rabbitmq:3"})
+	assert.Nil(t, err)
+	assert.Equal(t, []config.Service{
+		{Name: "db", Image: "postgres:13", HealthCheck: "pg_isready"},
+		{Name: "redis", Image: "redis:7"},
+		{Name: "queue", Image: "rabbitmq:3"},
+	}, services)
+
+	services, err = buildServices(nil, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, services)
+
+	_, err = buildServices(projectServices, []string{"db=mysql:8"})
+	assert.EqualError(t, err, "service db is defined more than once, so name the other one with --service <name>=<image>")
+
+	_, err = buildServices(nil, []string{"db="})
+	assert.EqualError(t, err, "invalid service db, must have the image")
+}
+
+func TestCheckPrivileges(t *testing.T) {
+	testCases := []struct {
+		name         string
+		entry        config.Entry
+		privileged   bool
+		capAdd       []string
+		securityOpts []string
+		expectErr    string
+	}{
+		{name: "default"},
+		{name: "capability dropped by default", capAdd: []string{"net_raw"}, securityOpts: []string{"no-new-privileges"}},
+		{name: "allowed", entry: config.Entry{AllowPrivileged: true}, privileged: true, capAdd: []string{"SYS_ADMIN"}, securityOpts: []string{"seccomp=unconfined"}},
+		{name: "privileged", privileged: true,
+			expectErr: "`privileged` gives the build privileges on the host, so it must be allowed with `sd-local config set allow-privileged true`"},
+		{name: "capability", capAdd: []string{"NET_RAW", "SYS_ADMIN"},
+			expectErr: "`cap-add SYS_ADMIN` gives the build privileges on the host, so it must be allowed with `sd-local config set allow-privileged true`"},
+		{name: "security option", securityOpts: []string{"apparmor=unconfined"},
+			expectErr: "`security-opt apparmor=unconfined` gives the build privileges on the host, so it must be allowed with `sd-local config set allow-privileged true`"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkPrivileges(tt.entry, tt.privileged, tt.capAdd, tt.securityOpts)
+			if tt.expectErr == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.EqualError(t, err, tt.expectErr)
+			}
+		})
+	}
+}
+
+func TestOverrideImage(t *testing.T) {
+	images := map[string]string{"main": "node:18"}
+
+	job := screwdriver.Job{Image: "node:12"}
+	overrideImage("main", &job, images, "")
+	assert.Equal(t, "node:18", job.Image)
+
+	job = screwdriver.Job{Image: "node:12"}
+	overrideImage("test", &job, images, "")
+	assert.Equal(t, "node:12", job.Image)
+
+	job = screwdriver.Job{Image: "node:12"}
+	overrideImage("main", &job, images, "/repo/Dockerfile")
+	assert.Equal(t, "/repo/Dockerfile", job.Image)
+}
+
+func TestAbsDockerfileImages(t *testing.T) {
+	dir, err := ioutil.TempDir("", "images")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "Dockerfile"), []byte("FROM node:18\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	images, err := absDockerfileImages(dir, map[string]string{"main": "node:18", "test": "./Dockerfile:ci"})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"main": "node:18", "test": filepath.Join(dir, "Dockerfile") + ":ci"}, images)
+
+	_, err = absDockerfileImages(dir, map[string]string{"test": "./ci/Dockerfile"})
+	assert.Contains(t, err.Error(), "invalid image of test in .sd-local.yaml: failed to find Dockerfile")
+}
+
+type mockSecretsProvider map[string]string
+
+func (mock mockSecretsProvider) Secrets() (map[string]string, error) { return mock, nil }
+
+type mockWritingLogger struct {
+	writer io.Writer
+	done   chan<- struct{}
+}
+
+func (mock mockWritingLogger) Run() {
+	fmt.Fprintln(mock.writer, "test: token is jwt-token and secret is git-key")
+	close(mock.done)
+}
+
+func (mock mockWritingLogger) Stop() {}
+
+func TestRunJob(t *testing.T) {
+	t.Run("success with masking secrets", func(t *testing.T) {
+		defer func() {
+			setup()
+		}()
+
+		var logOption buildlog.Option
+		buildLogNew = func(filepath string, writer io.Writer, done chan<- struct{}, option buildlog.Option) (buildlog.Logger, error) {
+			logOption = option
+			return mockWritingLogger{writer: writer, done: done}, nil
+		}
+
+		maskedFiles := make([]string, 0)
+		var maskedSecrets []string
+		maskFile = func(filepath string, secrets []string) error {
+			maskedFiles = append(maskedFiles, filepath)
+			maskedSecrets = secrets
+			return nil
+		}
+
+		option := launch.Option{
+			JWT:           "jwt-token",
+			Entry:         config.Entry{Token: "user-token"},
+			Secrets:       launch.EnvVar{"GIT_KEY": "git-key"},
+			ArtifactsPath: "sd-artifacts",
+		}
+
+		err := runJob(option, nil, bytes.NewBuffer(nil))
+		assert.Nil(t, err)
+		assert.ElementsMatch(t, []string{"jwt-token", "user-token", "git-key"}, logOption.Secrets)
+		assert.Equal(t, []string{filepath.Join("sd-artifacts", launch.LogFile)}, maskedFiles)
+		assert.ElementsMatch(t, []string{"jwt-token", "user-token", "git-key"}, maskedSecrets)
+	})
+
+	t.Run("failure by masking build log", func(t *testing.T) {
+		defer func() {
+			setup()
+		}()
+
+		buildLogNew = func(filepath string, writer io.Writer, done chan<- struct{}, option buildlog.Option) (buildlog.Logger, error) {
+			return mockWritingLogger{writer: writer, done: done}, nil
+		}
+		maskFile = func(filepath string, secrets []string) error {
+			return errors.New("failed to mask build log file: permission denied")
+		}
+
+		err := runJob(launch.Option{JWT: "jwt-token", ArtifactsPath: "sd-artifacts"}, nil, bytes.NewBuffer(nil))
+		assert.Equal(t, "failed to mask build log file: permission denied", err.Error())
+	})
+
+	t.Run("success with log options", func(t *testing.T) {
+		defer func() {
+			logFormat = string(buildlog.FormatText)
+			setup()
+		}()
+
+		logFormat = string(buildlog.FormatJSON)
+		var logOption buildlog.Option
+		buildLogNew = func(filepath string, writer io.Writer, done chan<- struct{}, option buildlog.Option) (buildlog.Logger, error) {
+			logOption = option
+			return mockWritingLogger{writer: writer, done: done}, nil
+		}
+
+		err := runJob(launch.Option{JobName: "main", ArtifactsPath: "sd-artifacts", Entry: config.Entry{Timestamps: true}}, nil, bytes.NewBuffer(nil))
+		assert.Nil(t, err)
+		assert.Equal(t, buildlog.Option{Format: buildlog.FormatJSON, JobName: "main", StepsDir: filepath.Join("sd-artifacts", buildlog.StepsDir), Timestamps: true, Secrets: []string{"", ""}}, logOption)
+	})
 }
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/screwdriver-cd/sd-local/cache"
+	"github.com/screwdriver-cd/sd-local/launch"
+	"github.com/screwdriver-cd/sd-local/screwdriver"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	cacheDirName = "cache"
+	// commandsDirName is the directory storing the binaries of the shared commands downloaded by sd-cmd.
+	commandsDirName = "commands"
+	// templatesDirName is the directory storing the templates used in offline mode.
+	templatesDirName = "templates"
+	// jwtDirName is the directory storing JWT reused until it expires.
+	jwtDirName = "jwt"
+)
+
+var (
+	timeNow         = time.Now
+	cacheBackendNew = cache.NewBackend
+	gitRemoteURL    = defaultGitRemoteURL
+	remoteURLPrefix = regexp.MustCompile(`^([a-z+]+://)?([^@/]+@)?`)
+)
+
+func defaultGitRemoteURL(dir string) (string, error) {
+	out, err := exec.Command("git", "-C", dir, "config", "--get", "remote.origin.url").Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+// remoteCache shares the pipeline and job caches through the cache backend.
+// Failures are only warned because builds can run without caches.
+type remoteCache struct {
+	backend  cache.Backend
+	key      string
+	settings screwdriver.Cache
+}
+
+// remoteCacheKey returns the key of the caches for the source, which is the same among machines cloning the same repository.
+func remoteCacheKey(srcPath string) string {
+	u, err := gitRemoteURL(srcPath)
+	if err != nil || u == "" {
+		return filepath.Base(srcPath)
+	}
+
+	u = remoteURLPrefix.ReplaceAllString(u, "")
+	u = strings.TrimSuffix(u, ".git")
+	return strings.Replace(u, ":", "/", 1)
+}
+
+func (r *remoteCache) restore(key, dir string) {
+	if r == nil {
+		return
+	}
+
+	logrus.Infof("Restoring cache %s...", key)
+	err := r.backend.Restore(r.key+"/"+key, dir)
+	if err != nil {
+		logrus.Warnf("failed to restore cache %s: %v", key, err)
+	}
+}
+
+func (r *remoteCache) store(key, dir string) {
+	if r == nil {
+		return
+	}
+
+	logrus.Infof("Storing cache %s...", key)
+	err := r.backend.Store(r.key+"/"+key, dir)
+	if err != nil {
+		logrus.Warnf("failed to store cache %s: %v", key, err)
+	}
+}
+
+// runJobWithCache runs the job restoring and storing its job cache around it.
+// The cache is stored even if the job fails, as the teardown of Screwdriver.cd does.
+func (r *remoteCache) runJobWithCache(option launch.Option, skipped []string, writer io.Writer) error {
+	if r == nil || len(r.settings.Job[option.JobName]) == 0 {
+		return runJob(option, skipped, writer)
+	}
+
+	key := "job/" + option.JobName
+	dir := filepath.Join(option.Cache.JobDir, option.JobName)
+
+	r.restore(key, dir)
+	defer r.store(key, dir)
+
+	return runJob(option, skipped, writer)
+}
+
+// cacheKey returns the directory name of the caches for the source, which is unique to its path.
+func cacheKey(srcPath string) string {
+	sum := sha1.Sum([]byte(srcPath))
+	return fmt.Sprintf("%s-%x", filepath.Base(srcPath), sum[:4])
+}
+
+// newCacheOption returns the cache option storing caches under ~/.sdlocal/cache.
+// The event caches are stored only during the invocation of sd-local.
+func newCacheOption(sdlocalDir, srcPath string, settings screwdriver.Cache) launch.CacheOption {
+	dir := filepath.Join(sdlocalDir, cacheDirName, cacheKey(srcPath))
+
+	return launch.CacheOption{
+		Settings:    settings,
+		PipelineDir: filepath.Join(dir, "pipeline"),
+		EventDir:    filepath.Join(dir, "event", strconv.FormatInt(timeNow().UnixNano(), 10)),
+		JobDir:      filepath.Join(dir, "job"),
+	}
+}
@@ -0,0 +1,200 @@
+package cache
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/screwdriver-cd/sd-local/launch"
+	"github.com/spf13/cobra"
+)
+
+const (
+	cacheDirName  = "cache"
+	configDirName = ".sdlocal"
+)
+
+var (
+	cacheDir = func() (string, error) {
+		home, err := homedir.Dir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(home, configDirName, cacheDirName), nil
+	}
+	launchNew = launch.New
+	timeNow   = time.Now
+)
+
+// entry is a cache of a scope of the pipeline, such as pipeline, job/<name> or event/<id>.
+type entry struct {
+	pipeline string
+	scope    string
+	path     string
+	size     int64
+	modTime  time.Time
+}
+
+// readEntries returns the caches stored under dir, which are sorted by the pipeline and the scope.
+// The event caches are listed only when they are left by interrupted builds.
+func readEntries(dir string) ([]entry, error) {
+	pipelines, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read cache directory: %v", err)
+	}
+
+	entries := make([]entry, 0)
+	for _, p := range pipelines {
+		if !p.IsDir() {
+			continue
+		}
+		pipelineDir := filepath.Join(dir, p.Name())
+
+		scopes := []string{"pipeline"}
+		for _, parent := range []string{"event", "job"} {
+			children, err := ioutil.ReadDir(filepath.Join(pipelineDir, parent))
+			if err != nil && !os.IsNotExist(err) {
+				return nil, fmt.Errorf("failed to read cache directory: %v", err)
+			}
+			for _, c := range children {
+				if c.IsDir() {
+					scopes = append(scopes, parent+"/"+c.Name())
+				}
+			}
+		}
+
+		for _, scope := range scopes {
+			path := filepath.Join(pipelineDir, filepath.FromSlash(scope))
+			size, modTime, err := usage(path)
+			if err != nil {
+				if os.IsNotExist(err) {
+					continue
+				}
+				return nil, fmt.Errorf("failed to read cache %s: %v", path, err)
+			}
+
+			entries = append(entries, entry{
+				pipeline: p.Name(),
+				scope:    scope,
+				path:     path,
+				size:     size,
+				modTime:  modTime,
+			})
+		}
+	}
+
+	return entries, nil
+}
+
+// usage returns the total size of the files under path and the latest modification time of them.
+// The modification time of path itself is used when it has no files.
+func usage(path string) (int64, time.Time, error) {
+	var size int64
+	var modTime time.Time
+	hasFiles := false
+
+	err := filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == path {
+			modTime = info.ModTime()
+		}
+		if info.IsDir() {
+			return nil
+		}
+		if !hasFiles || info.ModTime().After(modTime) {
+			modTime = info.ModTime()
+		}
+		hasFiles = true
+		size += info.Size()
+		return nil
+	})
+
+	return size, modTime, err
+}
+
+// removeEmptyDirs removes the pipeline directories which have no caches any more.
+func removeEmptyDirs(dir string) {
+	pipelines, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	for _, p := range pipelines {
+		pipelineDir := filepath.Join(dir, p.Name())
+		for _, parent := range []string{"event", "job"} {
+			os.Remove(filepath.Join(pipelineDir, parent))
+		}
+		os.Remove(pipelineDir)
+	}
+}
+
+// removeVolumes removes the volumes of the launcher left by interrupted builds.
+func removeVolumes(runtime string, useSudo bool) {
+	launchNew(launch.Option{Runtime: runtime, UseSudo: useSudo}).Clean()
+}
+
+func addVolumeFlags(cmd *cobra.Command, runtime *string, useSudo *bool) {
+	cmd.Flags().StringVar(
+		runtime,
+		"runtime",
+		"",
+		"Container runtime whose volumes are removed, docker, podman or nerdctl.")
+
+	cmd.Flags().BoolVar(
+		useSudo,
+		"sudo",
+		false,
+		"Use sudo command for container runtime.")
+}
+
+// parseAge parses the duration which can also be specified in days (e.g. 7d).
+func parseAge(s string) (time.Duration, error) {
+	if strings.HasSuffix(s, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(s, "d"))
+		if err != nil || days < 0 {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	return d, nil
+}
+
+// NewCacheCmd return cache command.
+func NewCacheCmd() *cobra.Command {
+	cacheCmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage caches of local builds.",
+		Long: `Manage caches of local builds.
+The caches are stored in ~/.sdlocal/cache per pipeline, which is the source directory of builds.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := cmd.Help()
+			if err != nil {
+				return err
+			}
+			return nil
+		},
+	}
+
+	cacheCmd.AddCommand(
+		newCacheListCmd(),
+		newCacheClearCmd(),
+		newCachePruneCmd(),
+	)
+
+	return cacheCmd
+}
@@ -0,0 +1,117 @@
+package cache
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/screwdriver-cd/sd-local/launch"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockLaunch struct {
+	cleaned *bool
+}
+
+func (mock mockLaunch) Run() error { return nil }
+
+func (mock mockLaunch) Kill(os.Signal) {}
+
+func (mock mockLaunch) Clean() { *mock.cleaned = true }
+
+var baseTime = time.Date(2020, 1, 10, 0, 0, 0, 0, time.UTC)
+
+// setupCacheDir creates the caches of a pipeline whose files are modified at the specified time.
+func setupCacheDir(t *testing.T, files map[string]time.Time) string {
+	dir, err := ioutil.TempDir("", "cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, modTime := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, make([]byte, 1024), 0666); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func mockCacheDir(t *testing.T, dir string) {
+	origCacheDir, origTimeNow, origLaunchNew := cacheDir, timeNow, launchNew
+	t.Cleanup(func() {
+		cacheDir, timeNow, launchNew = origCacheDir, origTimeNow, origLaunchNew
+	})
+
+	cacheDir = func() (string, error) { return dir, nil }
+	timeNow = func() time.Time { return baseTime }
+	launchNew = func(option launch.Option) launch.Launcher {
+		cleaned := false
+		return mockLaunch{cleaned: &cleaned}
+	}
+}
+
+func TestReadEntries(t *testing.T) {
+	old := baseTime.Add(-10 * 24 * time.Hour)
+	dir := setupCacheDir(t, map[string]time.Time{
+		"src-1234abcd/pipeline/node_modules/a": baseTime,
+		"src-1234abcd/job/main/.m2/b":          old,
+		"src-1234abcd/job/main/.m2/c":          old,
+		"src-1234abcd/event/1/d":               old,
+	})
+	defer os.RemoveAll(dir)
+
+	entries, err := readEntries(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(entries))
+
+	assert.Equal(t, "src-1234abcd", entries[0].pipeline)
+	assert.Equal(t, "pipeline", entries[0].scope)
+	assert.Equal(t, int64(1024), entries[0].size)
+	assert.True(t, entries[0].modTime.Equal(baseTime))
+
+	assert.Equal(t, "event/1", entries[1].scope)
+	assert.Equal(t, "job/main", entries[2].scope)
+	assert.Equal(t, int64(2048), entries[2].size)
+	assert.Equal(t, filepath.Join(dir, "src-1234abcd", "job", "main"), entries[2].path)
+
+	entries, err = readEntries(filepath.Join(dir, "none"))
+	assert.Nil(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestParseAge(t *testing.T) {
+	testCases := []struct {
+		name     string
+		age      string
+		expect   time.Duration
+		checkErr bool
+	}{
+		{name: "days", age: "7d", expect: 7 * 24 * time.Hour},
+		{name: "hours", age: "12h", expect: 12 * time.Hour},
+		{name: "invalid days", age: "xd", checkErr: true},
+		{name: "negative", age: "-1h", checkErr: true},
+		{name: "invalid", age: "week", checkErr: true},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := parseAge(tt.age)
+			if tt.checkErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.expect, d)
+			}
+		})
+	}
+}
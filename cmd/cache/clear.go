@@ -0,0 +1,53 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+func newCacheClearCmd() *cobra.Command {
+	var (
+		runtime string
+		useSudo bool
+	)
+
+	cacheClearCmd := &cobra.Command{
+		Use:   "clear [pipeline]",
+		Short: "Remove caches.",
+		Long: `Remove all caches, or the caches of the specified pipeline.
+The volumes of the launcher left by interrupted builds are also removed.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			dir, err := cacheDir()
+			if err != nil {
+				return err
+			}
+
+			if len(args) == 1 {
+				pipelineDir := filepath.Join(dir, filepath.Base(args[0]))
+				if _, err := os.Stat(pipelineDir); err != nil {
+					return fmt.Errorf("not found caches of pipeline %s", args[0])
+				}
+				dir = pipelineDir
+			}
+
+			err = os.RemoveAll(dir)
+			if err != nil {
+				return fmt.Errorf("failed to remove caches: %v", err)
+			}
+
+			removeVolumes(runtime, useSudo)
+
+			return nil
+		},
+	}
+
+	addVolumeFlags(cacheClearCmd, &runtime, &useSudo)
+
+	return cacheClearCmd
+}
@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/screwdriver-cd/sd-local/launch"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheClearCmd(t *testing.T) {
+	testCase := []struct {
+		name      string
+		args      []string
+		removed   []string
+		remaining []string
+		checkErr  bool
+	}{
+		{
+			name:      "success",
+			args:      []string{"clear"},
+			removed:   []string{"src-1234abcd", "other-5678abcd"},
+			remaining: []string{},
+			checkErr:  false,
+		},
+		{
+			name:      "success with pipeline",
+			args:      []string{"clear", "src-1234abcd"},
+			removed:   []string{"src-1234abcd"},
+			remaining: []string{"other-5678abcd"},
+			checkErr:  false,
+		},
+		{
+			name:     "failure by not found pipeline",
+			args:     []string{"clear", "none"},
+			checkErr: true,
+		},
+	}
+
+	for _, tt := range testCase {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := setupCacheDir(t, map[string]time.Time{
+				"src-1234abcd/pipeline/a":   baseTime,
+				"other-5678abcd/job/main/b": baseTime,
+			})
+			defer os.RemoveAll(dir)
+			mockCacheDir(t, dir)
+
+			cleaned := false
+			launchNew = func(option launch.Option) launch.Launcher {
+				assert.Equal(t, "podman", option.Runtime)
+				return mockLaunch{cleaned: &cleaned}
+			}
+
+			cmd := NewCacheCmd()
+			cmd.SetArgs(append(tt.args, "--runtime", "podman"))
+			cmd.SetOut(bytes.NewBuffer(nil))
+			err := cmd.Execute()
+			if tt.checkErr {
+				assert.NotNil(t, err)
+				assert.False(t, cleaned)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.True(t, cleaned)
+			for _, p := range tt.removed {
+				assert.NoDirExists(t, filepath.Join(dir, p))
+			}
+			for _, p := range tt.remaining {
+				assert.DirExists(t, filepath.Join(dir, p))
+			}
+		})
+	}
+}
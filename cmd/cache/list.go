@@ -0,0 +1,48 @@
+package cache
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/screwdriver-cd/sd-local/launch"
+	"github.com/spf13/cobra"
+)
+
+const timeFormat = "2006-01-02 15:04:05"
+
+func newCacheListCmd() *cobra.Command {
+	cacheListCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List caches with their sizes.",
+		Long: `List caches of each pipeline and job with their sizes and last modified times.
+Event caches are listed only when they are left by interrupted builds.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			dir, err := cacheDir()
+			if err != nil {
+				return err
+			}
+
+			entries, err := readEntries(dir)
+			if err != nil {
+				return err
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+			fmt.Fprintln(w, "PIPELINE\tSCOPE\tSIZE\tMODIFIED")
+
+			var total int64
+			for _, e := range entries {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", e.pipeline, e.scope, launch.FormatSize(e.size), e.modTime.Format(timeFormat))
+				total += e.size
+			}
+			fmt.Fprintf(w, "TOTAL\t\t%s\t\n", launch.FormatSize(total))
+
+			return w.Flush()
+		},
+	}
+
+	return cacheListCmd
+}
@@ -0,0 +1,57 @@
+package cache
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheListCmd(t *testing.T) {
+	dir := setupCacheDir(t, map[string]time.Time{
+		"src-1234abcd/pipeline/a": baseTime,
+		"src-1234abcd/job/main/b": baseTime,
+	})
+	defer os.RemoveAll(dir)
+	mockCacheDir(t, dir)
+
+	testCase := []struct {
+		name     string
+		args     []string
+		wantOut  string
+		checkErr bool
+	}{
+		{
+			name: "success",
+			args: []string{"list"},
+			wantOut: "PIPELINE      SCOPE     SIZE    MODIFIED\n" +
+				"src-1234abcd  pipeline  1.0 KB  " + baseTime.Local().Format(timeFormat) + "\n" +
+				"src-1234abcd  job/main  1.0 KB  " + baseTime.Local().Format(timeFormat) + "\n" +
+				"TOTAL                   2.0 KB  \n",
+			checkErr: false,
+		},
+		{
+			name:     "failure by too many args",
+			args:     []string{"list", "foo"},
+			checkErr: true,
+		},
+	}
+
+	for _, tt := range testCase {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := NewCacheCmd()
+			cmd.SetArgs(tt.args)
+			buf := bytes.NewBuffer(nil)
+			cmd.SetOut(buf)
+			err := cmd.Execute()
+			if tt.checkErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.wantOut, buf.String())
+			}
+		})
+	}
+}
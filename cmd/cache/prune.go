@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/screwdriver-cd/sd-local/launch"
+	"github.com/spf13/cobra"
+)
+
+func newCachePruneCmd() *cobra.Command {
+	var (
+		olderThan string
+		runtime   string
+		useSudo   bool
+	)
+
+	cachePruneCmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Remove caches not used recently.",
+		Long: `Remove the caches of pipelines and jobs which are not modified within the period specified by --older-than.
+The volumes of the launcher left by interrupted builds are also removed.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			age, err := parseAge(olderThan)
+			if err != nil {
+				return err
+			}
+			cmd.SilenceUsage = true
+
+			dir, err := cacheDir()
+			if err != nil {
+				return err
+			}
+
+			entries, err := readEntries(dir)
+			if err != nil {
+				return err
+			}
+
+			var freed int64
+			deadline := timeNow().Add(-age)
+			for _, e := range entries {
+				if e.modTime.After(deadline) {
+					continue
+				}
+
+				err := os.RemoveAll(e.path)
+				if err != nil {
+					return fmt.Errorf("failed to remove cache %s: %v", e.path, err)
+				}
+				freed += e.size
+				fmt.Fprintf(cmd.OutOrStdout(), "Removed %s %s (%s)\n", e.pipeline, e.scope, launch.FormatSize(e.size))
+			}
+			removeEmptyDirs(dir)
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Total reclaimed space: %s\n", launch.FormatSize(freed))
+
+			removeVolumes(runtime, useSudo)
+
+			return nil
+		},
+	}
+
+	cachePruneCmd.Flags().StringVar(
+		&olderThan,
+		"older-than",
+		"7d",
+		"Remove caches not modified within this period, which takes a number followed by d, h, m or s (e.g. 7d, 12h).")
+
+	addVolumeFlags(cachePruneCmd, &runtime, &useSudo)
+
+	return cachePruneCmd
+}
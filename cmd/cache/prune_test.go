@@ -0,0 +1,86 @@
+package cache
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/screwdriver-cd/sd-local/launch"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCachePruneCmd(t *testing.T) {
+	old := baseTime.Add(-10 * 24 * time.Hour)
+
+	testCase := []struct {
+		name      string
+		args      []string
+		wantOut   string
+		removed   []string
+		remaining []string
+		checkErr  bool
+	}{
+		{
+			name:      "success",
+			args:      []string{"prune"},
+			wantOut:   "Removed old-5678abcd pipeline (1.0 KB)\nRemoved src-1234abcd job/main (1.0 KB)\nTotal reclaimed space: 2.0 KB\n",
+			removed:   []string{"old-5678abcd", "src-1234abcd/job/main"},
+			remaining: []string{"src-1234abcd/pipeline", "src-1234abcd/job/test"},
+			checkErr:  false,
+		},
+		{
+			name:      "success with older than",
+			args:      []string{"prune", "--older-than", "30d"},
+			wantOut:   "Total reclaimed space: 0 B\n",
+			removed:   []string{},
+			remaining: []string{"old-5678abcd", "src-1234abcd/job/main"},
+			checkErr:  false,
+		},
+		{
+			name:     "failure by invalid older than",
+			args:     []string{"prune", "--older-than", "week"},
+			checkErr: true,
+		},
+	}
+
+	for _, tt := range testCase {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := setupCacheDir(t, map[string]time.Time{
+				"src-1234abcd/pipeline/a": baseTime,
+				"src-1234abcd/job/main/b": old,
+				"src-1234abcd/job/test/c": baseTime,
+				"old-5678abcd/pipeline/d": old,
+			})
+			defer os.RemoveAll(dir)
+			mockCacheDir(t, dir)
+
+			cleaned := false
+			launchNew = func(option launch.Option) launch.Launcher {
+				return mockLaunch{cleaned: &cleaned}
+			}
+
+			cmd := NewCacheCmd()
+			cmd.SetArgs(tt.args)
+			buf := bytes.NewBuffer(nil)
+			cmd.SetOut(buf)
+			err := cmd.Execute()
+			if tt.checkErr {
+				assert.NotNil(t, err)
+				assert.False(t, cleaned)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.True(t, cleaned)
+			assert.Equal(t, tt.wantOut, buf.String())
+			for _, p := range tt.removed {
+				assert.NoDirExists(t, filepath.Join(dir, filepath.FromSlash(p)))
+			}
+			for _, p := range tt.remaining {
+				assert.DirExists(t, filepath.Join(dir, filepath.FromSlash(p)))
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/screwdriver-cd/sd-local/launch"
+	"github.com/screwdriver-cd/sd-local/screwdriver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheKey(t *testing.T) {
+	assert.Equal(t, cacheKey("/home/user/repo"), cacheKey("/home/user/repo"))
+	assert.NotEqual(t, cacheKey("/home/user/repo"), cacheKey("/home/other/repo"))
+	assert.Regexp(t, "^repo-[0-9a-f]{8}$", cacheKey("/home/user/repo"))
+}
+
+func TestNewCacheOption(t *testing.T) {
+	defer func() {
+		timeNow = time.Now
+	}()
+	timeNow = func() time.Time { return time.Unix(0, 123) }
+
+	settings := screwdriver.Cache{Pipeline: []string{"~/.gradle"}}
+	key := cacheKey("/home/user/repo")
+
+	assert.Equal(t, launch.CacheOption{
+		Settings:    settings,
+		PipelineDir: "/home/user/.sdlocal/cache/" + key + "/pipeline",
+		EventDir:    "/home/user/.sdlocal/cache/" + key + "/event/123",
+		JobDir:      "/home/user/.sdlocal/cache/" + key + "/job",
+	}, newCacheOption("/home/user/.sdlocal", "/home/user/repo", settings))
+}
+
+func TestRemoteCacheKey(t *testing.T) {
+	defer func() {
+		gitRemoteURL = defaultGitRemoteURL
+	}()
+
+	testCases := []struct {
+		url    string
+		err    error
+		expect string
+	}{
+		{"git@github.com:screwdriver-cd/sd-local.git", nil, "github.com/screwdriver-cd/sd-local"},
+		{"https://github.com/screwdriver-cd/sd-local.git", nil, "github.com/screwdriver-cd/sd-local"},
+		{"ssh://git@github.com/screwdriver-cd/sd-local", nil, "github.com/screwdriver-cd/sd-local"},
+		{"", errors.New("exit status 1"), "repo"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.expect, func(t *testing.T) {
+			gitRemoteURL = func(dir string) (string, error) { return tt.url, tt.err }
+			assert.Equal(t, tt.expect, remoteCacheKey("/home/user/repo"))
+		})
+	}
+}
+
+type mockBackend struct {
+	calls []string
+}
+
+func (m *mockBackend) Restore(key, dir string) error {
+	m.calls = append(m.calls, "restore "+key+" "+dir)
+	return nil
+}
+
+func (m *mockBackend) Store(key, dir string) error {
+	m.calls = append(m.calls, "store "+key+" "+dir)
+	return errors.New("failed to store")
+}
+
+func TestRunJobWithCache(t *testing.T) {
+	option := launch.Option{
+		JobName:       "main",
+		ArtifactsPath: "sd-artifacts",
+		Cache:         launch.CacheOption{JobDir: "/cache/job"},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		backend := &mockBackend{}
+		r := &remoteCache{
+			backend:  backend,
+			key:      "github.com/org/repo",
+			settings: screwdriver.Cache{Job: map[string][]string{"main": {"node_modules"}}},
+		}
+
+		err := r.runJobWithCache(option, nil, bytes.NewBuffer(nil))
+		assert.Nil(t, err)
+		assert.Equal(t, []string{
+			"restore github.com/org/repo/job/main /cache/job/main",
+			"store github.com/org/repo/job/main /cache/job/main",
+		}, backend.calls)
+	})
+
+	t.Run("success without job cache", func(t *testing.T) {
+		backend := &mockBackend{}
+		r := &remoteCache{backend: backend, key: "github.com/org/repo"}
+
+		err := r.runJobWithCache(option, nil, bytes.NewBuffer(nil))
+		assert.Nil(t, err)
+		assert.Empty(t, backend.calls)
+	})
+
+	t.Run("success without remote cache", func(t *testing.T) {
+		var r *remoteCache
+
+		err := r.runJobWithCache(option, nil, bytes.NewBuffer(nil))
+		assert.Nil(t, err)
+	})
+}
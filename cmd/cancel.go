@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/screwdriver-cd/sd-local/state"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cancelPollInterval = 500 * time.Millisecond
+	signalProcess      = func(pid int, sig os.Signal) error {
+		p, err := os.FindProcess(pid)
+		if err != nil {
+			return err
+		}
+		return p.Signal(sig)
+	}
+)
+
+// running reports whether the build of id is still recorded as running.
+func running(s state.Store, id int) (bool, error) {
+	builds, err := s.List()
+	if err != nil {
+		return false, err
+	}
+	for _, b := range builds {
+		if b.ID == id {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// cancelBuild interrupts the build as Ctrl-C does, so that the build removes its containers before exiting.
+// It waits for the build to exit up to timeout.
+func cancelBuild(s state.Store, id int, timeout time.Duration) error {
+	b, err := s.Get(id)
+	if err != nil {
+		return err
+	}
+
+	if err := signalProcess(b.PID, os.Interrupt); err != nil {
+		return fmt.Errorf("failed to cancel build %d: %v", id, err)
+	}
+
+	deadline := timeNow().Add(timeout)
+	for {
+		ok, err := running(s, id)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+		if timeNow().After(deadline) {
+			return fmt.Errorf("build %d did not stop in %v", id, timeout)
+		}
+		time.Sleep(cancelPollInterval)
+	}
+}
+
+func newCancelCmd() *cobra.Command {
+	var timeout time.Duration
+
+	cancelCmd := &cobra.Command{
+		Use:   "cancel [id]",
+		Short: "Cancel a running build.",
+		Long: `Cancel the running build of the ID listed by ` + "`sd-local ps`" + `.
+The build is interrupted as with Ctrl-C, so its containers are removed before it exits.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			id, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid build id %s", args[0])
+			}
+
+			path, err := stateFile()
+			if err != nil {
+				return err
+			}
+
+			if err := cancelBuild(stateNew(path), id, timeout); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Canceled build %d\n", id)
+			return nil
+		},
+	}
+
+	cancelCmd.Flags().DurationVar(
+		&timeout,
+		"timeout",
+		time.Minute,
+		"Time to wait for the build to remove its containers and exit.")
+
+	return cancelCmd
+}
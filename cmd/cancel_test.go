@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/screwdriver-cd/sd-local/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCancelBuild(t *testing.T) {
+	defer func(f func(int, os.Signal) error, interval time.Duration) {
+		signalProcess = f
+		cancelPollInterval = interval
+	}(signalProcess, cancelPollInterval)
+	cancelPollInterval = time.Millisecond
+
+	t.Run("success", func(t *testing.T) {
+		s := &mockStore{builds: []state.Build{{ID: 1, PID: 100}, {ID: 2, PID: 200}}}
+		var pid int
+		var sig os.Signal
+		signalProcess = func(p int, s2 os.Signal) error {
+			pid, sig = p, s2
+			// The build removes itself on exit
+			return s.Remove(2)
+		}
+
+		err := cancelBuild(s, 2, time.Second)
+		assert.Nil(t, err)
+		assert.Equal(t, 200, pid)
+		assert.Equal(t, os.Interrupt, sig)
+	})
+
+	t.Run("failure by not running build", func(t *testing.T) {
+		err := cancelBuild(&mockStore{}, 1, time.Second)
+		assert.Equal(t, "not found running build", err.Error())
+	})
+
+	t.Run("failure by signal", func(t *testing.T) {
+		signalProcess = func(p int, sig os.Signal) error { return errors.New("operation not permitted") }
+
+		err := cancelBuild(&mockStore{builds: []state.Build{{ID: 1, PID: 100}}}, 1, time.Second)
+		assert.Equal(t, "failed to cancel build 1: operation not permitted", err.Error())
+	})
+
+	t.Run("failure by timeout", func(t *testing.T) {
+		signalProcess = func(p int, sig os.Signal) error { return nil }
+
+		err := cancelBuild(&mockStore{builds: []state.Build{{ID: 1, PID: 100}}}, 1, 10*time.Millisecond)
+		assert.Equal(t, "build 1 did not stop in 10ms", err.Error())
+	})
+}
+
+func TestCancelCmd(t *testing.T) {
+	defer func(f func(int, os.Signal) error) {
+		signalProcess = f
+		stateNew = func(path string) state.Store { return &mockStore{} }
+	}(signalProcess)
+
+	t.Run("success", func(t *testing.T) {
+		s := &mockStore{builds: []state.Build{{ID: 1, PID: 100}}}
+		stateNew = func(path string) state.Store { return s }
+		signalProcess = func(p int, sig os.Signal) error { return s.Remove(1) }
+
+		cmd := newCancelCmd()
+		cmd.SetArgs([]string{"1"})
+		buf := bytes.NewBuffer(nil)
+		cmd.SetOut(buf)
+		err := cmd.Execute()
+		assert.Nil(t, err)
+		assert.Equal(t, "Canceled build 1\n", buf.String())
+	})
+
+	t.Run("failure by invalid id", func(t *testing.T) {
+		cmd := newCancelCmd()
+		cmd.SetArgs([]string{"abc"})
+		cmd.SetOut(bytes.NewBuffer(nil))
+		err := cmd.Execute()
+		assert.Equal(t, "invalid build id abc", err.Error())
+	})
+}
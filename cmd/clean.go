@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/screwdriver-cd/sd-local/launch"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var launchClean = launch.Clean
+
+// activeBuilds returns the pids of the running builds, whose resources are kept by clean.
+func activeBuilds() []int {
+	path, err := stateFile()
+	if err != nil {
+		logrus.Warnf("failed to read running builds: %v", err)
+		return nil
+	}
+	builds, err := stateNew(path).List()
+	if err != nil {
+		logrus.Warnf("failed to read running builds: %v", err)
+		return nil
+	}
+
+	pids := make([]int, 0, len(builds))
+	for _, b := range builds {
+		pids = append(pids, b.PID)
+	}
+	return pids
+}
+
+func printRemoved(w io.Writer, kind string, names []string) {
+	fmt.Fprintf(w, "Removed %d %s\n", len(names), kind)
+	for _, n := range names {
+		fmt.Fprintf(w, "  %s\n", n)
+	}
+}
+
+func newCleanCmd() *cobra.Command {
+	var runtime string
+	var sudo bool
+	var all bool
+
+	cleanCmd := &cobra.Command{
+		Use:   "clean",
+		Short: "Remove the containers, volumes and files left by sd-local.",
+		Long: `Remove the build containers kept with "sd-local build --reuse", the stopped containers and the dangling volumes of the builds,
+and the temporary files left by sd-local. The resources of the running builds are kept.
+With --all, the launcher images, the snapshots, the images built from Dockerfile and the volumes of the language caches are also removed,
+so the next builds download and build them again.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			home, err := homedir.Dir()
+			if err != nil {
+				return err
+			}
+			c, err := configNew(filepath.Join(home, ".sdlocal", "config"))
+			if err != nil {
+				return err
+			}
+			current, err := c.Entry(c.Current)
+			if err != nil {
+				return err
+			}
+			if runtime == "" {
+				runtime = current.Runtime
+			}
+
+			result, err := launchClean(launch.CleanOption{
+				Runtime:       runtime,
+				UseSudo:       sudo,
+				FlagVerbose:   flagVerbose,
+				All:           all,
+				LauncherImage: current.Launcher.Image,
+				Active:        activeBuilds(),
+			})
+			if err != nil {
+				return err
+			}
+
+			w := cmd.OutOrStdout()
+			printRemoved(w, "containers", result.Containers)
+			printRemoved(w, "volumes", result.Volumes)
+			if all {
+				printRemoved(w, "images", result.Images)
+			}
+			printRemoved(w, "temporary files", result.TempFiles)
+			fmt.Fprintf(w, "Reclaimed %s\n", launch.FormatSize(result.Reclaimed))
+			return nil
+		},
+	}
+
+	cleanCmd.Flags().StringVar(
+		&runtime,
+		"runtime",
+		"",
+		"Container runtime of the containers, docker, podman or nerdctl. Defaults to the runtime of the current config.")
+
+	cleanCmd.Flags().BoolVar(
+		&sudo,
+		"sudo",
+		false,
+		"Use sudo command for container runtime.")
+
+	cleanCmd.Flags().BoolVar(
+		&all,
+		"all",
+		false,
+		"Also remove the launcher images, the snapshots, the images built from Dockerfile and the volumes of the language caches.")
+
+	return cleanCmd
+}
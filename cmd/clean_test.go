@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/screwdriver-cd/sd-local/launch"
+	"github.com/screwdriver-cd/sd-local/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCleanCmd(t *testing.T) {
+	defer func() {
+		launchClean = launch.Clean
+		stateNew = state.New
+	}()
+	setup()
+	stateNew = func(path string) state.Store {
+		return &mockStore{builds: []state.Build{{ID: 1, PID: 100}}}
+	}
+
+	t.Run("success", func(t *testing.T) {
+		var option launch.CleanOption
+		launchClean = func(o launch.CleanOption) (launch.CleanResult, error) {
+			option = o
+			return launch.CleanResult{
+				Containers: []string{"sd-local-reuse-main-0123456789ab"},
+				Volumes:    []string{"sd-local-main-123_data"},
+				Reclaimed:  2048,
+			}, nil
+		}
+
+		cmd := newCleanCmd()
+		cmd.SetArgs([]string{"--runtime", "podman", "--sudo"})
+		buf := bytes.NewBuffer(nil)
+		cmd.SetOut(buf)
+
+		err := cmd.Execute()
+		assert.Nil(t, err)
+		assert.Equal(t, launch.CleanOption{Runtime: "podman", UseSudo: true, LauncherImage: "screwdrivercd/launcher", Active: []int{100}}, option)
+		assert.Equal(t, "Removed 1 containers\n  sd-local-reuse-main-0123456789ab\n"+
+			"Removed 1 volumes\n  sd-local-main-123_data\n"+
+			"Removed 0 temporary files\n"+
+			"Reclaimed 2.0 KB\n", buf.String())
+	})
+
+	t.Run("success with all", func(t *testing.T) {
+		var option launch.CleanOption
+		launchClean = func(o launch.CleanOption) (launch.CleanResult, error) {
+			option = o
+			return launch.CleanResult{
+				Images:    []string{"screwdrivercd/launcher:stable"},
+				TempFiles: []string{"/tmp/sd-local-cache123"},
+				Reclaimed: 100,
+			}, nil
+		}
+
+		cmd := newCleanCmd()
+		cmd.SetArgs([]string{"--all"})
+		buf := bytes.NewBuffer(nil)
+		cmd.SetOut(buf)
+
+		err := cmd.Execute()
+		assert.Nil(t, err)
+		assert.True(t, option.All)
+		assert.Equal(t, "Removed 0 containers\nRemoved 0 volumes\n"+
+			"Removed 1 images\n  screwdrivercd/launcher:stable\n"+
+			"Removed 1 temporary files\n  /tmp/sd-local-cache123\n"+
+			"Reclaimed 100 B\n", buf.String())
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		launchClean = func(o launch.CleanOption) (launch.CleanResult, error) {
+			return launch.CleanResult{}, errors.New("failed to list kept containers: exit status 1")
+		}
+
+		cmd := newCleanCmd()
+		cmd.SetArgs([]string{"--runtime", "docker"})
+		cmd.SetOut(bytes.NewBuffer(nil))
+
+		err := cmd.Execute()
+		assert.Equal(t, "failed to list kept containers: exit status 1", err.Error())
+	})
+}
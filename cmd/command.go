@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultCommandFile is the spec of the command read when the path is omitted
+const defaultCommandFile = "sd-command.yaml"
+
+func commandFileArg(args []string) string {
+	if len(args) == 1 {
+		return args[0]
+	}
+	return defaultCommandFile
+}
+
+func newCommandValidateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate [path]",
+		Short: "Validate a shared command.",
+		Long: `Validate the spec of the shared command with Screwdriver.cd API.
+The path defaults to ` + defaultCommandFile + ` in the current directory.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			api, err := remoteAPI()
+			if err != nil {
+				return err
+			}
+
+			c, err := api.ValidateCommand(commandFileArg(args))
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Command %s@%s (%s) is valid\n", c.Name, c.Version, c.Format)
+
+			return nil
+		},
+	}
+}
+
+func newCommandPublishCmd() *cobra.Command {
+	var dryRun bool
+
+	commandPublishCmd := &cobra.Command{
+		Use:   "publish [path]",
+		Short: "Publish a shared command.",
+		Long: `Publish the shared command of the spec to Screwdriver.cd as sd-cmd does.
+The path defaults to ` + defaultCommandFile + ` in the current directory,
+and the binary of the command in binary format is read from the path relative to the spec.
+Screwdriver.cd may allow only the builds of the pipeline of the command to publish it.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			api, err := remoteAPI()
+			if err != nil {
+				return err
+			}
+
+			path := commandFileArg(args)
+			c, err := api.ValidateCommand(path)
+			if err != nil {
+				return err
+			}
+
+			if dryRun {
+				fmt.Fprintf(cmd.OutOrStdout(), "Command %s@%s (%s) is valid, and is not published with --dry-run\n", c.Name, c.Version, c.Format)
+				return nil
+			}
+
+			c, err = api.PublishCommand(path)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Published command %s@%s\n", c.Name, c.Version)
+
+			return nil
+		},
+	}
+
+	commandPublishCmd.Flags().BoolVar(
+		&dryRun,
+		"dry-run",
+		false,
+		"Validate the command without publishing it.")
+
+	return commandPublishCmd
+}
+
+func newCommandCmd() *cobra.Command {
+	commandCmd := &cobra.Command{
+		Use:   "command",
+		Short: "Develop shared commands.",
+		Long:  `Validate and publish shared commands run with sd-cmd, with Screwdriver.cd API of the current config.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := cmd.Help()
+			if err != nil {
+				return err
+			}
+			return nil
+		},
+	}
+
+	commandCmd.AddCommand(
+		newCommandValidateCmd(),
+		newCommandPublishCmd(),
+	)
+
+	return commandCmd
+}
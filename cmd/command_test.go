@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/screwdriver-cd/sd-local/screwdriver"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockCommandAPI struct {
+	mockAPI
+	published bool
+}
+
+func (mock *mockCommandAPI) PublishCommand(filePath string) (screwdriver.Command, error) {
+	mock.published = true
+	return mock.mockAPI.PublishCommand(filePath)
+}
+
+func TestCommandCmd(t *testing.T) {
+	origAPINew := apiNew
+	t.Cleanup(func() { apiNew = origAPINew })
+
+	testCases := []struct {
+		name      string
+		args      []string
+		want      string
+		published bool
+	}{
+		{name: "validate", args: []string{"validate"}, want: "Command sd/hello@1.0 (binary) is valid\n"},
+		{name: "validate with path", args: []string{"validate", "hello/sd-command.yaml"}, want: "Command sd/hello@1.0 (binary) is valid\n"},
+		{name: "publish", args: []string{"publish"}, want: "Published command sd/hello@1.0.2\n", published: true},
+		{name: "publish with --dry-run", args: []string{"publish", "--dry-run"}, want: "Command sd/hello@1.0 (binary) is valid, and is not published with --dry-run\n"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &mockCommandAPI{}
+			apiNew = func(url, token string, option screwdriver.Option) screwdriver.API { return api }
+
+			cmd := newCommandCmd()
+			cmd.SetArgs(tt.args)
+			buf := bytes.NewBuffer(nil)
+			cmd.SetOut(buf)
+
+			err := cmd.Execute()
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, buf.String())
+			assert.Equal(t, tt.published, api.published)
+		})
+	}
+}
@@ -37,7 +37,9 @@ func NewConfigCmd() *cobra.Command {
 
 	configCmd.AddCommand(
 		newConfigSetCmd(),
+		newConfigUnsetCmd(),
 		newConfigViewCmd(),
+		newConfigEditCmd(),
 		newConfigCreateCmd(),
 		newConfigDeleteCmd(),
 		newConfigUseCmd(),
@@ -6,7 +6,8 @@ import (
 )
 
 var (
-	configNew = config.New
+	configNew      = config.New
+	configValidate = config.Validate
 )
 
 func newConfigCreateCmd() *cobra.Command {
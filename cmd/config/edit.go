@@ -0,0 +1,105 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/screwdriver-cd/sd-local/config"
+	"github.com/spf13/cobra"
+)
+
+const defaultEditor = "vi"
+
+var execCommand = exec.Command
+
+func editorCommand() []string {
+	editor := os.Getenv("EDITOR")
+	if strings.TrimSpace(editor) == "" {
+		editor = defaultEditor
+	}
+	return strings.Fields(editor)
+}
+
+// editConfig opens the copy of the config file with the editor, and replaces the config file with it if it is valid.
+// The invalid copy is kept so that the changes are not lost.
+func editConfig(path string) error {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %v", err)
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), "config-*.yaml")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	_, err = tmp.Write(raw)
+	tmp.Close()
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	editor := editorCommand()
+	c := execCommand(editor[0], append(editor[1:], tmpPath)...)
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := c.Run(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to run editor %s: %v", editor[0], err)
+	}
+
+	err = configValidate(tmpPath)
+	if err == nil {
+		var edited config.Config
+		edited, err = configNew(tmpPath)
+		if err == nil && edited.Current != "" {
+			_, err = edited.Entry(edited.Current)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("invalid config, the edited file is kept in %s: %v", tmpPath, err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to save config file: %v", err)
+	}
+
+	// The tokens written in the file are moved into the credential store of the OS
+	saved, err := configNew(path)
+	if err != nil {
+		return err
+	}
+	return saved.Save()
+}
+
+func newConfigEditCmd() *cobra.Command {
+	configEditCmd := &cobra.Command{
+		Use:   "edit",
+		Short: "Edit the config file of sd-local",
+		Long: `Edit the config file of sd-local with $EDITOR, or vi if it is not set.
+The config file is replaced when the edited one is valid, otherwise it is kept as is.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			path, err := filePath()
+			if err != nil {
+				return err
+			}
+
+			// The config file is created if it does not exist
+			if _, err := configNew(path); err != nil {
+				return err
+			}
+
+			return editConfig(path)
+		},
+	}
+
+	return configEditCmd
+}
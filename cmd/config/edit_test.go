@@ -0,0 +1,152 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/screwdriver-cd/sd-local/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeEditor returns the command which writes the content into the edited file instead of opening the editor
+func fakeEditor(content string, editorArgs *[]string) func(string, ...string) *exec.Cmd {
+	return func(name string, args ...string) *exec.Cmd {
+		*editorArgs = append([]string{name}, args...)
+		return exec.Command("sh", "-c", `printf '%s' "$1" > "$2"`, "sh", content, args[len(args)-1])
+	}
+}
+
+func TestEditorCommand(t *testing.T) {
+	defer os.Setenv("EDITOR", os.Getenv("EDITOR"))
+
+	os.Setenv("EDITOR", "code --wait")
+	assert.Equal(t, []string{"code", "--wait"}, editorCommand())
+
+	os.Setenv("EDITOR", "")
+	assert.Equal(t, []string{"vi"}, editorCommand())
+}
+
+func TestEditConfig(t *testing.T) {
+	defer func() {
+		execCommand = exec.Command
+		configNew = config.New
+		configValidate = config.Validate
+	}()
+	defer os.Setenv("EDITOR", os.Getenv("EDITOR"))
+	os.Setenv("EDITOR", "vim")
+
+	original, err := ioutil.ReadFile("./testdata/config")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name      string
+		content   string
+		configNew func(string) (config.Config, error)
+		validate  func(string) error
+		expectErr string
+		expect    string
+	}{
+		{
+			name:      "success",
+			content:   "configs:\n  default:\n    api-url: api-edited.screwdriver.com\ncurrent: default\n",
+			configNew: config.New,
+			expect:    "api-edited.screwdriver.com",
+		},
+		{
+			name:      "failure by validation",
+			content:   "configs:\n  default:\n    runtime: dockr\ncurrent: default\n",
+			configNew: config.New,
+			validate: func(path string) error {
+				return fmt.Errorf("config default: invalid runtime dockr, must be one of [docker podman nerdctl]")
+			},
+			expectErr: "config default: invalid runtime dockr",
+		},
+		{
+			name:    "failure by unknown current config",
+			content: "configs:\n  default:\n    api-url: api-edited.screwdriver.com\ncurrent: unknown\n",
+			configNew: func(path string) (config.Config, error) {
+				return config.Config{Entries: map[string]*config.Entry{"default": {}}, Current: "unknown"}, nil
+			},
+			expectErr: "config `unknown` does not exist",
+		},
+		{
+			name:    "failure by parsing config",
+			content: "configs: [",
+			configNew: func(path string) (config.Config, error) {
+				return config.Config{}, fmt.Errorf("failed to parse config file: broken")
+			},
+			expectErr: "failed to parse config file: broken",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "config")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+
+			path := filepath.Join(dir, "config")
+			if err := ioutil.WriteFile(path, original, 0666); err != nil {
+				t.Fatal(err)
+			}
+
+			var editorArgs []string
+			execCommand = fakeEditor(tt.content, &editorArgs)
+			configNew = tt.configNew
+			configValidate = func(path string) error { return nil }
+			if tt.validate != nil {
+				configValidate = tt.validate
+			}
+
+			err = editConfig(path)
+			assert.Equal(t, "vim", editorArgs[0])
+
+			files, _ := filepath.Glob(filepath.Join(dir, "config-*.yaml"))
+			saved, _ := ioutil.ReadFile(path)
+			if tt.expectErr != "" {
+				assert.Contains(t, err.Error(), tt.expectErr)
+				assert.Contains(t, err.Error(), editorArgs[1])
+				assert.Equal(t, string(original), string(saved))
+				// The edited file is kept not to lose the changes
+				assert.Equal(t, []string{editorArgs[1]}, files)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Contains(t, string(saved), tt.expect)
+			assert.Empty(t, files)
+		})
+	}
+
+	t.Run("failure by editor", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "config")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+
+		path := filepath.Join(dir, "config")
+		if err := ioutil.WriteFile(path, original, 0666); err != nil {
+			t.Fatal(err)
+		}
+
+		execCommand = func(name string, args ...string) *exec.Cmd {
+			return exec.Command("false")
+		}
+
+		err = editConfig(path)
+		assert.Equal(t, "failed to run editor vim: exit status 1", err.Error())
+		saved, _ := ioutil.ReadFile(path)
+		assert.Equal(t, string(original), string(saved))
+		files, _ := filepath.Glob(filepath.Join(dir, "config-*.yaml"))
+		assert.Empty(t, files)
+	})
+}
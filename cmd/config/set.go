@@ -1,26 +1,66 @@
 package config
 
 import (
-	"strings"
-
 	"github.com/spf13/cobra"
 )
 
-func isInvalidKeyError(err error) bool {
-	return strings.Contains(err.Error(), "invalid key")
-}
-
 func newConfigSetCmd() *cobra.Command {
+	var profile string
+
 	configSetCmd := &cobra.Command{
 		Use:   "set [key] [value]",
 		Short: "Set the config of sd-local",
 		Long: `Set the config of sd-local.
+The current config is set unless --profile is specified, and the specified config is created if it does not exist.
 Can set the below settings:
 * Screwdriver.cd API URL as "api-url"
 * Screwdriver.cd Store URL as "store-url"
 * Screwdriver.cd Token as "token"
+* Type of the token (user or pipeline) as "token-type"
+* Whether to write the token into the config file instead of the credential store of the OS (true or false) as "plaintext-token"
 * Screwdriver.cd launcher version as "launcher-version"
-* Screwdriver.cd launcher image as "launcher-image"`,
+* Screwdriver.cd launcher image as "launcher-image"
+* Container runtime (docker, podman or nerdctl) as "runtime"
+* Platform of the images, which is linux/amd64 or linux/arm64, such as to emulate the architecture of the cluster on Apple Silicon as "platform"
+* Mirror of Docker Hub which the images on Docker Hub are pulled from (e.g. mirror.example.com) as "registry-mirror"
+* Aliases of the images which are pulled instead, which are comma separated <image>=<image> or <prefix>*=<prefix>* (e.g. docker.io/library/*=registry.example.com/library/*) as "image-aliases"
+* Volumes mounted into the build container, which are comma separated <host path>:<container path>[:ro] as "volumes"
+* Whether to allow builds to mount the docker socket of the host with --mount-docker-socket, which gives them root access to the host (true or false) as "allow-docker-socket"
+* Whether to allow builds to run with --privileged, --cap-add and --security-opt, which give them privileges on the host (true or false) as "allow-privileged"
+* Memory limit of the build container (e.g. 4g), which is used unless it is set with --memory, in .sd-local.yaml or with screwdriver.cd/ram annotation as "memory"
+* Number of CPUs of the build container (e.g. 2), which is used unless it is set with --cpus, in .sd-local.yaml or with screwdriver.cd/cpu annotation as "cpus"
+* User of the build container, which is <name|uid>[:<group|gid>] or host for the user of the host, such as to own the files written into the volumes as "user"
+* Label of SELinux of the bind mounts, which is z to share them with the other containers, Z to make them private or off not to relabel them. The volumes are relabeled with z when SELinux is enforcing by default as "selinux-label"
+* Whether to pass $HTTP_PROXY, $HTTPS_PROXY and $NO_PROXY of the host into the build container (true or false) as "proxy-env"
+* Proxy of the build container, which overrides $HTTP_PROXY of the host as "http-proxy"
+* Proxy of the build container, which overrides $HTTPS_PROXY of the host as "https-proxy"
+* Hosts which the build container does not access through the proxy, which overrides $NO_PROXY of the host as "no-proxy"
+* DNS servers of the build container, which are comma separated IP addresses, or host for the DNS servers of the host as "dns"
+* Whether to mount the named volumes of the caches of npm, Maven, Go, pip and Gradle detected in the source directory into the build container (true or false) as "lang-cache"
+* Path to the secrets file as "secrets-file"
+* Vault address to read secrets from as "vault-addr"
+* Vault path of secrets (e.g. secret/data/sd-local) as "vault-path"
+* Cache backend (local or s3) as "cache-backend"
+* Bucket of s3 cache backend as "cache-bucket"
+* Endpoint of S3 compatible storage (e.g. MinIO) as "cache-endpoint"
+* Region of s3 cache backend as "cache-region"
+* Size of the source directory to warn about (e.g. 500m) as "src-size-warning"
+* Whether to show timestamps in the build log (true or false) as "timestamps"
+* Number of times to retry the requests to Screwdriver.cd API on transient failures (default 3) as "api-retries"
+* Time limit of each request to Screwdriver.cd API (default 60s) as "api-timeout"
+* How long the idle connections to Screwdriver.cd API are kept (e.g. 90s, 0 to disable keep-alive) as "api-keep-alive"
+* Proxy of the requests to Screwdriver.cd API, which overrides $HTTP_PROXY, $HTTPS_PROXY and $NO_PROXY as "api-proxy"
+* Path to the CA certificate file to verify Screwdriver.cd API in addition to the system ones as "api-ca-cert"
+* Path to the client certificate file sent to Screwdriver.cd API as "api-client-cert"
+* Path to the key file of the client certificate as "api-client-key"
+* Whether to skip verifying the certificate of Screwdriver.cd API, which is insecure (true or false) as "insecure-skip-tls-verify"
+* Whether to disable the daily check for a newer release of sd-local (true or false) as "disable-update-check"
+* Whether to show a desktop notification when the builds finish (true or false) as "notify-desktop"
+* URL of the incoming webhook of Slack which the results of the builds are posted to as "slack-webhook"
+* URL of the incoming webhook of Microsoft Teams which the results of the builds are posted to as "teams-webhook"
+* URL of the webhook of Discord which the results of the builds are posted to as "discord-webhook"
+* URL of Prometheus Pushgateway which the metrics of the builds are pushed to (e.g. http://pushgateway.example.com:9091) as "metrics-pushgateway"
+* Address of StatsD which the metrics of the builds are sent to (e.g. statsd.example.com:8125) as "metrics-statsd"`,
 		Args: cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cmd.SilenceUsage = true
@@ -37,21 +77,22 @@ Can set the below settings:
 				return err
 			}
 
-			entry, err := config.Entry(config.Current)
+			if profile == "" {
+				profile = config.Current
+			} else if _, exists := config.Entries[profile]; !exists {
+				if err := config.AddEntry(profile); err != nil {
+					return err
+				}
+			}
+
+			entry, err := config.Entry(profile)
 			if err != nil {
 				return err
 			}
 
 			err = entry.Set(key, value)
 			if err != nil {
-				if isInvalidKeyError(err) {
-					err := cmd.Help()
-					if err != nil {
-						return err
-					}
-				} else {
-					return err
-				}
+				return err
 			}
 
 			err = config.Save()
@@ -62,5 +103,11 @@ Can set the below settings:
 		},
 	}
 
+	configSetCmd.Flags().StringVar(
+		&profile,
+		"profile",
+		"",
+		"Name of the config to set instead of the current config.")
+
 	return configSetCmd
 }
@@ -36,6 +36,12 @@ func TestConfigSetCmd(t *testing.T) {
 			wantOut:  "",
 			checkErr: false,
 		},
+		{
+			name:     "failure by invalid key",
+			args:     []string{"set", "launcher-verison", "1.0.0"},
+			wantOut:  "",
+			checkErr: true,
+		},
 		{
 			name:     "failure by too many args",
 			args:     []string{"set", "api-url", "example.com", "many"},
@@ -67,3 +73,38 @@ func TestConfigSetCmd(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigSetCmdWithProfile(t *testing.T) {
+	rand.Seed(time.Now().UnixNano())
+	cnfPath := fmt.Sprintf("%vconfig", rand.Int())
+	defer os.Remove(cnfPath)
+
+	defFilePath := filePath
+	defer func() {
+		filePath = defFilePath
+	}()
+	filePath = func() (string, error) {
+		return cnfPath, nil
+	}
+
+	cmd := NewConfigCmd()
+	cmd.SetArgs([]string{"set", "--profile", "prod", "api-url", "prod.example.com"})
+	cmd.SetOut(bytes.NewBuffer(nil))
+	err := cmd.Execute()
+	assert.Nil(t, err)
+
+	cnf, err := configNew(cnfPath)
+	assert.Nil(t, err)
+	assert.Equal(t, "default", cnf.Current)
+	def, err := cnf.Entry("default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "", def.APIURL)
+	prod, err := cnf.Entry("prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "prod.example.com", prod.APIURL)
+	assert.Equal(t, "screwdrivercd/launcher", prod.Launcher.Image)
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func newConfigUnsetCmd() *cobra.Command {
+	var profile string
+
+	configUnsetCmd := &cobra.Command{
+		Use:   "unset [key]",
+		Short: "Unset the config of sd-local",
+		Long: `Unset the config of sd-local.
+The value of the key is reset to the default, such as "stable" for "launcher-version".
+The current config is unset unless --profile is specified.
+The keys are the same as the set sub command.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			key := args[0]
+
+			path, err := filePath()
+			if err != nil {
+				return err
+			}
+
+			config, err := configNew(path)
+			if err != nil {
+				return err
+			}
+
+			if profile == "" {
+				profile = config.Current
+			}
+
+			entry, err := config.Entry(profile)
+			if err != nil {
+				return err
+			}
+
+			err = entry.Unset(key)
+			if err != nil {
+				return err
+			}
+
+			err = config.Save()
+			if err != nil {
+				return err
+			}
+			return nil
+		},
+	}
+
+	configUnsetCmd.Flags().StringVar(
+		&profile,
+		"profile",
+		"",
+		"Name of the config to unset instead of the current config.")
+
+	return configUnsetCmd
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/screwdriver-cd/sd-local/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigUnsetCmd(t *testing.T) {
+	f, err := os.Open("./testdata/config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	cnfPath, err := createRandNameConfig(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(cnfPath)
+
+	preconf := configNew
+	defer func() {
+		configNew = preconf
+	}()
+	configNew = func(configPath string) (c config.Config, err error) {
+		return config.New(cnfPath)
+	}
+
+	testCase := []struct {
+		name     string
+		args     []string
+		wantOut  string
+		checkErr bool
+	}{
+		{
+			name:     "success",
+			args:     []string{"unset", "launcher-version"},
+			wantOut:  "",
+			checkErr: false,
+		},
+		{
+			name:     "success with profile",
+			args:     []string{"unset", "--profile", "test", "api-url"},
+			wantOut:  "",
+			checkErr: false,
+		},
+		{
+			name:     "failure by invalid key",
+			args:     []string{"unset", "launcher-versions"},
+			wantOut:  "Error: invalid key launcher-versions, did you mean launcher-version?\n",
+			checkErr: true,
+		},
+		{
+			name:     "failure by unknown profile",
+			args:     []string{"unset", "--profile", "unknownconfig", "api-url"},
+			wantOut:  "Error: config `unknownconfig` does not exist\n",
+			checkErr: true,
+		},
+		{
+			name:     "failure without args",
+			args:     []string{"unset"},
+			wantOut:  "Error: accepts 1 arg(s), received 0\n",
+			checkErr: true,
+		},
+	}
+
+	for _, tt := range testCase {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := NewConfigCmd()
+			cmd.SilenceUsage = true
+			cmd.SetArgs(tt.args)
+			buf := bytes.NewBuffer(nil)
+			cmd.SetOut(buf)
+			err := cmd.Execute()
+			if tt.checkErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.Equal(t, tt.wantOut, buf.String())
+		})
+	}
+
+	c, err := config.New(cnfPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	def, err := c.Entry("default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "stable", def.Launcher.Version)
+	test, err := c.Entry("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "", test.APIURL)
+}
@@ -1,14 +1,99 @@
 package config
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"sort"
 	"strings"
 
 	"github.com/go-yaml/yaml"
+	"github.com/screwdriver-cd/sd-local/config"
 	"github.com/spf13/cobra"
 )
 
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+// viewResult is the config shown in JSON
+type viewResult struct {
+	Current   string                   `json:"current"`
+	Configs   map[string]*config.Entry `json:"configs"`
+	Overrides map[string]string        `json:"overrides,omitempty"`
+}
+
+func writeEntry(out io.Writer, entry *config.Entry) error {
+	yaml, err := yaml.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	for _, line := range strings.Split(string(yaml), "\n") {
+		if line != "" {
+			fmt.Fprintf(out, "    %s\n", line)
+		}
+	}
+
+	return nil
+}
+
+// newViewResult returns the config to be shown, whose tokens are redacted.
+// With resolved, only the current config is returned with the values overridden by the environment variables.
+func newViewResult(c config.Config, resolved bool) (viewResult, error) {
+	result := viewResult{Current: c.Current, Configs: make(map[string]*config.Entry, len(c.Entries))}
+
+	if resolved {
+		current, err := c.Entry(c.Current)
+		if err != nil {
+			return result, err
+		}
+		entry := current.Resolved().Redacted()
+		result.Configs[c.Current] = &entry
+		result.Overrides = config.Overrides()
+		return result, nil
+	}
+
+	for name, e := range c.Entries {
+		entry := e.Redacted()
+		result.Configs[name] = &entry
+	}
+
+	return result, nil
+}
+
+func writeText(out io.Writer, result viewResult) error {
+	for name, entry := range result.Configs {
+		if name == result.Current {
+			fmt.Fprintf(out, "* %s:\n", name)
+		} else {
+			fmt.Fprintf(out, "  %s:\n", name)
+		}
+
+		if err := writeEntry(out, entry); err != nil {
+			return err
+		}
+
+	}
+
+	keys := make([]string, 0, len(result.Overrides))
+	for key := range result.Overrides {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(out, "    # %s is overridden by $%s\n", key, result.Overrides[key])
+	}
+
+	return nil
+}
+
 func newConfigViewCmd() *cobra.Command {
+	var resolved bool
+	var output string
+
 	configViewCmd := &cobra.Command{
 		Use:   "view",
 		Short: "View the config of sd-local.",
@@ -16,9 +101,17 @@ func newConfigViewCmd() *cobra.Command {
 Can see the below settings:
 * Screwdriver.cd API URL
 * Screwdriver.cd Store URL
-* Screwdriver.cd Token
+* Screwdriver.cd Token, which is redacted
 * Screwdriver.cd launcher version
-* Screwdriver.cd launcher image`,
+* Screwdriver.cd launcher image
+With --resolved, the current config is shown with the values of $SD_LOCAL_API_URL, $SD_LOCAL_STORE_URL and $SD_LOCAL_TOKEN,
+which take precedence over the config file.`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if output != outputText && output != outputJSON {
+				return errors.New("`output` must be either text or json")
+			}
+			return nil
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cmd.SilenceUsage = true
 
@@ -32,29 +125,35 @@ Can see the below settings:
 				return err
 			}
 
-			for name, entry := range config.Entries {
-				if name == config.Current {
-					fmt.Fprintf(cmd.OutOrStdout(), "* %s:\n", name)
-				} else {
-					fmt.Fprintf(cmd.OutOrStdout(), "  %s:\n", name)
-				}
-
-				yaml, err := yaml.Marshal(entry)
-				if err != nil {
-					return err
-				}
-
-				for _, line := range strings.Split(string(yaml), "\n") {
-					if line != "" {
-						fmt.Fprintf(cmd.OutOrStdout(), "    %s\n", line)
-					}
-				}
+			result, err := newViewResult(config, resolved)
+			if err != nil {
+				return err
+			}
 
+			if output == outputJSON {
+				encoder := json.NewEncoder(cmd.OutOrStdout())
+				encoder.SetIndent("", "  ")
+				// The redacted tokens are not escaped as HTML
+				encoder.SetEscapeHTML(false)
+				return encoder.Encode(result)
 			}
 
-			return nil
+			return writeText(cmd.OutOrStdout(), result)
 		},
 	}
 
+	configViewCmd.Flags().BoolVar(
+		&resolved,
+		"resolved",
+		false,
+		"Show the current config whose values are overridden by the environment variables, which are used at runtime.")
+
+	configViewCmd.Flags().StringVarP(
+		&output,
+		"output",
+		"o",
+		outputText,
+		"Output format of the config, which is either text or json.")
+
 	return configViewCmd
 }
@@ -2,9 +2,11 @@ package config
 
 import (
 	"bytes"
+	"os"
 	"strings"
 	"testing"
 
+	"github.com/screwdriver-cd/sd-local/config"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -23,6 +25,7 @@ func TestViewCmd(t *testing.T) {
 	testCase := []struct {
 		name   string
 		args   []string
+		env    map[string]string
 		expect []string
 		config string
 	}{
@@ -32,14 +35,14 @@ func TestViewCmd(t *testing.T) {
 			expect: []string{`* default:
     api-url: api.screwdriver.com
     store-url: store.screwdriver.com
-    token: sd-token
+    token: <redacted>
     launcher:
       version: 1.0.0
       image: screwdrivercd/launcher`,
 				`  test:
     api-url: api-test.screwdriver.com
     store-url: store-test.screwdriver.com
-    token: sd-token-test
+    token: <redacted>
     launcher:
       version: 1.0.0-test
       image: screwdrivercd/launcher
@@ -52,25 +55,63 @@ func TestViewCmd(t *testing.T) {
 			expect: []string{`  default:
     api-url: api.screwdriver.com
     store-url: store.screwdriver.com
-    token: sd-token
+    token: <redacted>
     launcher:
       version: 1.0.0
       image: screwdrivercd/launcher`,
 				`  test:
     api-url: api-test.screwdriver.com
     store-url: store-test.screwdriver.com
-    token: sd-token-test
+    token: <redacted>
     launcher:
       version: 1.0.0-test
       image: screwdrivercd/launcher
 `},
 			config: "./testdata/config_no_current",
 		},
+		{
+			name: "success with resolved",
+			args: []string{"view", "--resolved"},
+			env: map[string]string{
+				"SD_LOCAL_API_URL": "api-env.screwdriver.com",
+				"SD_LOCAL_TOKEN":   "sd-token-env",
+			},
+			expect: []string{`* default:
+    api-url: api-env.screwdriver.com
+    store-url: store.screwdriver.com
+    token: <redacted>
+    launcher:
+      version: 1.0.0
+      image: screwdrivercd/launcher
+    # api-url is overridden by $SD_LOCAL_API_URL
+    # token is overridden by $SD_LOCAL_TOKEN
+`},
+			config: "./testdata/config",
+		},
+		{
+			name: "success with json",
+			args: []string{"view", "--output", "json"},
+			expect: []string{`"current": "default"`,
+				`"default": {
+      "api-url": "api.screwdriver.com",
+      "store-url": "store.screwdriver.com",
+      "token": "<redacted>",
+      "launcher": {
+        "version": "1.0.0",
+        "image": "screwdrivercd/launcher"
+      }
+    }`},
+			config: "./testdata/config",
+		},
 	}
 
 	for _, tt := range testCase {
 		t.Run(tt.name, func(t *testing.T) {
 			testConfig = tt.config
+			for k, v := range tt.env {
+				os.Setenv(k, v)
+				defer os.Unsetenv(k)
+			}
 			cmd := NewConfigCmd()
 			cmd.SetArgs(tt.args)
 			buf := bytes.NewBuffer(nil)
@@ -89,3 +130,39 @@ func TestViewCmd(t *testing.T) {
 		})
 	}
 }
+
+func TestNewViewResult(t *testing.T) {
+	defer os.Unsetenv("SD_LOCAL_TOKEN")
+	os.Setenv("SD_LOCAL_TOKEN", "sd-token-env")
+
+	c := config.Config{
+		Entries: map[string]*config.Entry{
+			"default": {APIURL: "api.screwdriver.com", Token: "sd-token"},
+			"test":    {APIURL: "api-test.screwdriver.com"},
+		},
+		Current: "default",
+	}
+
+	result, err := newViewResult(c, false)
+	assert.Nil(t, err)
+	assert.Equal(t, viewResult{
+		Current: "default",
+		Configs: map[string]*config.Entry{
+			"default": {APIURL: "api.screwdriver.com", Token: config.RedactedToken},
+			"test":    {APIURL: "api-test.screwdriver.com"},
+		},
+	}, result)
+	assert.Equal(t, "sd-token", c.Entries["default"].Token)
+
+	result, err = newViewResult(c, true)
+	assert.Nil(t, err)
+	assert.Equal(t, viewResult{
+		Current:   "default",
+		Configs:   map[string]*config.Entry{"default": {APIURL: "api.screwdriver.com", Token: config.RedactedToken}},
+		Overrides: map[string]string{"token": "SD_LOCAL_TOKEN"},
+	}, result)
+
+	c.Current = "unknown"
+	_, err = newViewResult(c, true)
+	assert.Equal(t, "config `unknown` does not exist", err.Error())
+}
@@ -0,0 +1,493 @@
+package cmd
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+const (
+	buildRunning  = "RUNNING"
+	buildCanceled = "CANCELED"
+	// daemonKillTimeout is the time to wait for the builds to clean up their containers when the daemon is stopped.
+	daemonKillTimeout = 30 * time.Second
+	// daemonTokenFileName is the file in ~/.sdlocal storing the token of the running daemon.
+	daemonTokenFileName = "daemon.token"
+)
+
+var (
+	execCommand     = exec.Command
+	osExecutable    = os.Executable
+	listenAndServe  = http.ListenAndServe
+	daemonTokenFile = func() (string, error) {
+		home, err := homedir.Dir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(home, ".sdlocal", daemonTokenFileName), nil
+	}
+)
+
+// daemonJobNamePattern is the job names which can be requested, so that the job is not parsed as a flag of the build command.
+var daemonJobNamePattern = regexp.MustCompile(`^(PR-[0-9]+:)?[a-zA-Z0-9_][a-zA-Z0-9_-]*$`)
+
+// daemonAllowedFlags is the flags of the build command which can be passed in the request.
+// The flags which give the build access to the host, such as mounting its files or sockets,
+// and the flags which read or write the files of the host are not allowed.
+var daemonAllowedFlags = map[string]bool{
+	"all":               true,
+	"continue-on-error": true,
+	"cpus":              true,
+	"dry-run":           true,
+	"env":               true,
+	"from-step":         true,
+	"log-format":        true,
+	"memory":            true,
+	"meta":              true,
+	"no-color":          true,
+	"offline":           true,
+	"parallel":          true,
+	"param":             true,
+	"pr":                true,
+	"pr-branch":         true,
+	"pull":              true,
+	"skip-step":         true,
+	"step":              true,
+	"step-timeout":      true,
+	"strict":            true,
+	"timeout":           true,
+	"timestamps":        true,
+}
+
+// daemonBuild is a build started by the daemon. It runs `sd-local build` in another process.
+type daemonBuild struct {
+	ID        int        `json:"id"`
+	Job       string     `json:"job"`
+	SrcDir    string     `json:"srcDir"`
+	Args      []string   `json:"args"`
+	Status    string     `json:"status"`
+	ExitCode  *int       `json:"exitCode,omitempty"`
+	StartTime time.Time  `json:"startTime"`
+	EndTime   *time.Time `json:"endTime,omitempty"`
+
+	cmd      *exec.Cmd
+	stream   *logStream
+	canceled bool
+	done     chan struct{}
+}
+
+// startRequest is the body of the request to start a build.
+type startRequest struct {
+	Job    string   `json:"job"`
+	SrcDir string   `json:"srcDir"`
+	Args   []string `json:"args"`
+}
+
+type daemon struct {
+	mutex  *sync.Mutex
+	token  string
+	nextID int
+	builds map[int]*daemonBuild
+}
+
+// newDaemon returns the daemon which accepts the requests with the token only.
+func newDaemon(token string) *daemon {
+	return &daemon{
+		mutex:  &sync.Mutex{},
+		token:  token,
+		nextID: 1,
+		builds: make(map[int]*daemonBuild),
+	}
+}
+
+func newDaemonToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to issue token of daemon: %v", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// writeDaemonToken writes the token into the file which only the user can read.
+func writeDaemonToken(path, token string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create directory of daemon token: %v", err)
+	}
+	// The existing file is removed, since WriteFile keeps its permission
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove old daemon token: %v", err)
+	}
+	if err := ioutil.WriteFile(path, []byte(token+"\n"), 0600); err != nil {
+		return fmt.Errorf("failed to write daemon token: %v", err)
+	}
+	return nil
+}
+
+// validateBuildArgs checks that args are the flags of the build command allowed in daemonAllowedFlags.
+// The job is given by the job of the request instead of args.
+func validateBuildArgs(args []string) error {
+	flags := newBuildCmd().Flags()
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") || arg == "-" || arg == "--" {
+			return fmt.Errorf("args must be flags, but got %s", arg)
+		}
+
+		name, hasValue := strings.TrimLeft(arg, "-"), false
+		if n := strings.Index(name, "="); n >= 0 {
+			name, hasValue = name[:n], true
+		}
+
+		flag := flags.Lookup(name)
+		if !strings.HasPrefix(arg, "--") {
+			flag = nil
+			if len(name) == 1 {
+				flag = flags.ShorthandLookup(name)
+			}
+		}
+		if flag == nil {
+			return fmt.Errorf("unknown flag %s", arg)
+		}
+		if !daemonAllowedFlags[flag.Name] {
+			return fmt.Errorf("flag --%s is not allowed in the daemon", flag.Name)
+		}
+
+		if !hasValue && flag.NoOptDefVal == "" {
+			if i+1 >= len(args) {
+				return fmt.Errorf("flag --%s needs an argument", flag.Name)
+			}
+			i++
+		}
+	}
+	return nil
+}
+
+// start runs the build in another process, since the build command keeps its state in the package.
+func (d *daemon) start(req startRequest) (*daemonBuild, error) {
+	srcDir := req.SrcDir
+	if srcDir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get working directory: %v", err)
+		}
+		srcDir = wd
+	}
+	if info, err := os.Stat(srcDir); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("srcDir %s is not a directory", srcDir)
+	}
+	if req.Job != "" && !daemonJobNamePattern.MatchString(req.Job) {
+		return nil, fmt.Errorf("invalid job name %s", req.Job)
+	}
+	if err := validateBuildArgs(req.Args); err != nil {
+		return nil, err
+	}
+
+	exe, err := osExecutable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get executable: %v", err)
+	}
+
+	args := []string{"build"}
+	if req.Job != "" {
+		args = append(args, req.Job)
+	}
+	args = append(args, req.Args...)
+
+	b := &daemonBuild{
+		Job:    req.Job,
+		SrcDir: srcDir,
+		Args:   append([]string{}, req.Args...),
+		Status: buildRunning,
+		stream: newLogStream(),
+		done:   make(chan struct{}),
+	}
+	b.cmd = execCommand(exe, args...)
+	b.cmd.Dir = srcDir
+	b.cmd.Stdout = b.stream
+	b.cmd.Stderr = b.stream
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	b.StartTime = time.Now()
+	if err := b.cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start build: %v", err)
+	}
+	b.ID = d.nextID
+	d.nextID++
+	d.builds[b.ID] = b
+	logrus.Infof("Start build %d of job %s in %s", b.ID, req.Job, srcDir)
+
+	go d.wait(b)
+
+	return b, nil
+}
+
+func (d *daemon) wait(b *daemonBuild) {
+	err := b.cmd.Wait()
+
+	d.mutex.Lock()
+	now := time.Now()
+	code := b.cmd.ProcessState.ExitCode()
+	b.EndTime = &now
+	b.ExitCode = &code
+	switch {
+	case b.canceled:
+		b.Status = buildCanceled
+	case err != nil:
+		b.Status = jobFailure
+	default:
+		b.Status = jobSuccess
+	}
+	logrus.Infof("Build %d finished with %s", b.ID, b.Status)
+	d.mutex.Unlock()
+
+	b.stream.Close()
+	close(b.done)
+}
+
+// cancel interrupts the build, which removes its containers before exiting as the build command does on Ctrl-C.
+func (d *daemon) cancel(b *daemonBuild) error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if b.Status != buildRunning {
+		return fmt.Errorf("build %d is not running", b.ID)
+	}
+	if err := b.cmd.Process.Signal(os.Interrupt); err != nil {
+		return fmt.Errorf("failed to cancel build %d: %v", b.ID, err)
+	}
+	b.canceled = true
+
+	return nil
+}
+
+func (d *daemon) get(id int) (*daemonBuild, bool) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	b, ok := d.builds[id]
+	return b, ok
+}
+
+// list returns the builds in the order they were started.
+func (d *daemon) list() []*daemonBuild {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	builds := make([]*daemonBuild, 0, len(d.builds))
+	for _, b := range d.builds {
+		builds = append(builds, b)
+	}
+	sort.Slice(builds, func(i, j int) bool { return builds[i].ID < builds[j].ID })
+
+	return builds
+}
+
+// Kill passes the signal to the running builds and waits for them to clean up.
+func (d *daemon) Kill(sig os.Signal) {
+	d.mutex.Lock()
+	running := make([]*daemonBuild, 0)
+	for _, b := range d.builds {
+		if b.Status == buildRunning {
+			b.canceled = true
+			b.cmd.Process.Signal(sig)
+			running = append(running, b)
+		}
+	}
+	d.mutex.Unlock()
+
+	timeout := time.After(daemonKillTimeout)
+	for _, b := range running {
+		select {
+		case <-b.done:
+		case <-timeout:
+			logrus.Warnf("build %d did not stop in %v", b.ID, daemonKillTimeout)
+			return
+		}
+	}
+}
+
+// Clean does nothing since each build cleans up by itself.
+func (d *daemon) Clean() {}
+
+// writeJSON writes v as the response. It holds the mutex so that the builds are not updated while encoding.
+func (d *daemon) writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	d.mutex.Lock()
+	body, err := json.Marshal(v)
+	d.mutex.Unlock()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("failed to encode response: %v", err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(append(body, '\n'))
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
+
+func (d *daemon) serveBuilds(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		d.writeJSON(w, http.StatusOK, d.list())
+	case http.MethodPost:
+		var req startRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("failed to parse request: %v", err))
+			return
+		}
+		b, err := d.start(req)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		d.writeJSON(w, http.StatusCreated, b)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s is not allowed", r.Method))
+	}
+}
+
+// serveBuild handles /builds/{id}, /builds/{id}/logs and /builds/{id}/cancel.
+func (d *daemon) serveBuild(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/builds/"), "/")
+	id, err := strconv.Atoi(parts[0])
+	if err != nil || len(parts) > 2 {
+		writeError(w, http.StatusNotFound, fmt.Errorf("not found %s", r.URL.Path))
+		return
+	}
+	b, ok := d.get(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("not found build %d", id))
+		return
+	}
+
+	action := ""
+	if len(parts) == 2 {
+		action = parts[1]
+	}
+
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		d.writeJSON(w, http.StatusOK, b)
+	case action == "logs" && r.Method == http.MethodGet:
+		b.stream.serveLogs(w, r)
+	case action == "cancel" && r.Method == http.MethodPost:
+		if err := d.cancel(b); err != nil {
+			writeError(w, http.StatusConflict, err)
+			return
+		}
+		d.writeJSON(w, http.StatusAccepted, b)
+	case action == "" || action == "logs" || action == "cancel":
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s is not allowed", r.Method))
+	default:
+		writeError(w, http.StatusNotFound, fmt.Errorf("not found %s", r.URL.Path))
+	}
+}
+
+// Handler returns the handler of the REST API.
+// The requests without the token are rejected, and so are the requests from the pages of other origins,
+// such as the POST requests of the forms which browsers send without asking.
+func (d *daemon) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/builds", d.serveBuilds)
+	mux.HandleFunc("/builds/", d.serveBuild)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if origin := r.Header.Get("Origin"); origin != "" {
+			if u, err := url.Parse(origin); err != nil || u.Host != r.Host {
+				writeError(w, http.StatusForbidden, fmt.Errorf("origin %s is not allowed", origin))
+				return
+			}
+		}
+
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+d.token)) != 1 {
+			writeError(w, http.StatusUnauthorized, fmt.Errorf("missing authentication"))
+			return
+		}
+
+		if r.Method == http.MethodPost {
+			if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+				writeError(w, http.StatusUnsupportedMediaType, fmt.Errorf("Content-Type must be application/json"))
+				return
+			}
+		}
+
+		mux.ServeHTTP(w, r)
+	})
+}
+
+func newDaemonCmd() *cobra.Command {
+	var addr string
+
+	daemonCmd := &cobra.Command{
+		Use:   "daemon",
+		Short: "Run a server to start builds over HTTP.",
+		Long: `Run a server which provides the REST API to start, list, follow and cancel local builds.
+Each build runs ` + "`sd-local build`" + ` in the source directory given in the request.
+The requests must have the token written in ~/.sdlocal/` + daemonTokenFileName + ` as "Authorization: Bearer <token>",
+and the POST requests must be sent with "Content-Type: application/json".
+Only the flags of the build command which don't give the build access to the host can be given in args.
+
+  POST /builds               Start a build. The body is {"job": "main", "srcDir": "/path/to/repo", "args": ["--all"]}.
+  GET  /builds               List the builds.
+  GET  /builds/{id}          Show the build.
+  GET  /builds/{id}/logs     Stream the build log with Server-Sent Events.
+  POST /builds/{id}/cancel   Cancel the build.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			token, err := newDaemonToken()
+			if err != nil {
+				return err
+			}
+			tokenFile, err := daemonTokenFile()
+			if err != nil {
+				return err
+			}
+			if err := writeDaemonToken(tokenFile, token); err != nil {
+				return err
+			}
+
+			d := newDaemon(token)
+			addCleaner(d)
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Listening on http://%s\n", addr)
+			fmt.Fprintf(cmd.OutOrStdout(), "The token is written in %s\n", tokenFile)
+			return listenAndServe(addr, d.Handler())
+		},
+	}
+
+	daemonCmd.Flags().StringVar(
+		&addr,
+		"addr",
+		"localhost:8090",
+		"Address to listen on.")
+
+	return daemonCmd
+}
@@ -0,0 +1,344 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func fakeDaemonCommand(name string, args ...string) *exec.Cmd {
+	cs := []string{"-test.run=TestDaemonHelperProcess", "--", name}
+	cs = append(cs, args...)
+	cmd := exec.Command(os.Args[0], cs...)
+	cmd.Env = []string{"GO_WANT_DAEMON_HELPER_PROCESS=1"}
+	return cmd
+}
+
+// TestDaemonHelperProcess behaves as the build command depending on the job name.
+func TestDaemonHelperProcess(t *testing.T) {
+	if os.Getenv("GO_WANT_DAEMON_HELPER_PROCESS") != "1" {
+		return
+	}
+
+	args := os.Args
+	for len(args) > 0 && args[0] != "--" {
+		args = args[1:]
+	}
+	job := args[3]
+
+	switch job {
+	case "main":
+		fmt.Println("install: npm install")
+		fmt.Print("test: npm test")
+		os.Exit(0)
+	case "fail":
+		fmt.Fprintln(os.Stderr, "job failed")
+		os.Exit(1)
+	case "wait":
+		quit := make(chan os.Signal, 1)
+		signal.Notify(quit, os.Interrupt)
+		fmt.Println("waiting")
+		<-quit
+		fmt.Println("canceled")
+		os.Exit(1)
+	}
+	os.Exit(2)
+}
+
+const testDaemonToken = "daemon-token"
+
+// daemonRequest sends the request to the daemon with the token.
+func daemonRequest(t *testing.T, method, url string, body io.Reader) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+testDaemonToken)
+	if method == http.MethodPost {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func startDaemonBuild(t *testing.T, url string, req startRequest) (int, daemonBuild) {
+	t.Helper()
+
+	body, _ := json.Marshal(req)
+	res := daemonRequest(t, http.MethodPost, url+"/builds", bytes.NewReader(body))
+	defer res.Body.Close()
+
+	var b daemonBuild
+	json.NewDecoder(res.Body).Decode(&b)
+	return res.StatusCode, b
+}
+
+func getDaemonBody(t *testing.T, url string) (int, string) {
+	t.Helper()
+
+	res := daemonRequest(t, http.MethodGet, url, nil)
+	defer res.Body.Close()
+
+	body, _ := ioutil.ReadAll(res.Body)
+	return res.StatusCode, string(body)
+}
+
+func TestDaemon(t *testing.T) {
+	defer func() {
+		execCommand = exec.Command
+	}()
+	execCommand = fakeDaemonCommand
+
+	d := newDaemon(testDaemonToken)
+	server := httptest.NewServer(d.Handler())
+	defer server.Close()
+
+	t.Run("success", func(t *testing.T) {
+		status, b := startDaemonBuild(t, server.URL, startRequest{Job: "main", SrcDir: ".", Args: []string{"--no-color"}})
+		assert.Equal(t, http.StatusCreated, status)
+		assert.Equal(t, 1, b.ID)
+		assert.Equal(t, "main", b.Job)
+		assert.Equal(t, []string{"--no-color"}, b.Args)
+		assert.Equal(t, buildRunning, b.Status)
+
+		status, body := getDaemonBody(t, fmt.Sprintf("%s/builds/%d/logs", server.URL, b.ID))
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "data: install: npm install\n\ndata: test: npm test\n\nevent: end\ndata: \n\n", body)
+
+		status, body = getDaemonBody(t, fmt.Sprintf("%s/builds/%d", server.URL, b.ID))
+		assert.Equal(t, http.StatusOK, status)
+		assert.Contains(t, body, `"status":"SUCCESS","exitCode":0`)
+	})
+
+	t.Run("success with failed build", func(t *testing.T) {
+		status, b := startDaemonBuild(t, server.URL, startRequest{Job: "fail", SrcDir: "."})
+		assert.Equal(t, http.StatusCreated, status)
+
+		getDaemonBody(t, fmt.Sprintf("%s/builds/%d/logs", server.URL, b.ID))
+		status, body := getDaemonBody(t, fmt.Sprintf("%s/builds/%d", server.URL, b.ID))
+		assert.Equal(t, http.StatusOK, status)
+		assert.Contains(t, body, `"status":"FAILURE","exitCode":1`)
+	})
+
+	t.Run("success with cancel", func(t *testing.T) {
+		status, b := startDaemonBuild(t, server.URL, startRequest{Job: "wait", SrcDir: "."})
+		assert.Equal(t, http.StatusCreated, status)
+
+		// Wait for the build to be ready to receive the signal
+		res := daemonRequest(t, http.MethodGet, fmt.Sprintf("%s/builds/%d/logs", server.URL, b.ID), nil)
+		defer res.Body.Close()
+		readEvents(t, bufio.NewReader(res.Body), 1)
+
+		res = daemonRequest(t, http.MethodPost, fmt.Sprintf("%s/builds/%d/cancel", server.URL, b.ID), nil)
+		res.Body.Close()
+		assert.Equal(t, http.StatusAccepted, res.StatusCode)
+
+		build, _ := d.get(b.ID)
+		select {
+		case <-build.done:
+		case <-time.After(10 * time.Second):
+			t.Fatal("build is not canceled")
+		}
+		_, body := getDaemonBody(t, fmt.Sprintf("%s/builds/%d", server.URL, b.ID))
+		assert.Contains(t, body, `"status":"CANCELED"`)
+
+		res = daemonRequest(t, http.MethodPost, fmt.Sprintf("%s/builds/%d/cancel", server.URL, b.ID), nil)
+		res.Body.Close()
+		assert.Equal(t, http.StatusConflict, res.StatusCode)
+	})
+
+	t.Run("success with list", func(t *testing.T) {
+		status, body := getDaemonBody(t, server.URL+"/builds")
+		assert.Equal(t, http.StatusOK, status)
+
+		var builds []daemonBuild
+		assert.Nil(t, json.Unmarshal([]byte(body), &builds))
+		jobs := make([]string, 0, len(builds))
+		for _, b := range builds {
+			jobs = append(jobs, b.Job)
+		}
+		assert.Equal(t, []string{"main", "fail", "wait"}, jobs)
+	})
+
+	t.Run("failure by not existing source directory", func(t *testing.T) {
+		status, _ := startDaemonBuild(t, server.URL, startRequest{Job: "main", SrcDir: "./not-exist"})
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+
+	t.Run("failure by invalid request", func(t *testing.T) {
+		res := daemonRequest(t, http.MethodPost, server.URL+"/builds", strings.NewReader("{"))
+		res.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+
+	t.Run("failure by not existing build", func(t *testing.T) {
+		for _, path := range []string{"/builds/100", "/builds/abc", "/builds/1/none"} {
+			status, _ := getDaemonBody(t, server.URL+path)
+			assert.Equal(t, http.StatusNotFound, status, path)
+		}
+	})
+
+	t.Run("failure by method", func(t *testing.T) {
+		status, _ := getDaemonBody(t, server.URL+"/builds/1/cancel")
+		assert.Equal(t, http.StatusMethodNotAllowed, status)
+	})
+
+	t.Run("failure by args", func(t *testing.T) {
+		testCases := []struct {
+			args    []string
+			message string
+		}{
+			{[]string{"--volume", "/:/host"}, "flag --volume is not allowed in the daemon"},
+			{[]string{"--mount-docker-socket"}, "flag --mount-docker-socket is not allowed in the daemon"},
+			{[]string{"--secrets-file=/etc/shadow"}, "flag --secrets-file is not allowed in the daemon"},
+			{[]string{"--env", "FOO=bar", "--privileged"}, "flag --privileged is not allowed in the daemon"},
+			{[]string{"-i"}, "flag --interactive is not allowed in the daemon"},
+			{[]string{"--nyancat"}, "unknown flag --nyancat"},
+			{[]string{"test"}, "args must be flags, but got test"},
+			{[]string{"--timeout"}, "flag --timeout needs an argument"},
+		}
+
+		for _, tt := range testCases {
+			body, _ := json.Marshal(startRequest{Job: "main", SrcDir: ".", Args: tt.args})
+			res := daemonRequest(t, http.MethodPost, server.URL+"/builds", bytes.NewReader(body))
+			msg, _ := ioutil.ReadAll(res.Body)
+			res.Body.Close()
+			assert.Equal(t, http.StatusBadRequest, res.StatusCode, tt.args)
+			assert.Contains(t, string(msg), tt.message, tt.args)
+		}
+	})
+
+	t.Run("failure by job", func(t *testing.T) {
+		for _, job := range []string{"--mount-docker-socket", "--volume=/:/host", "-i", "main test"} {
+			body, _ := json.Marshal(startRequest{Job: job, SrcDir: "."})
+			res := daemonRequest(t, http.MethodPost, server.URL+"/builds", bytes.NewReader(body))
+			msg, _ := ioutil.ReadAll(res.Body)
+			res.Body.Close()
+			assert.Equal(t, http.StatusBadRequest, res.StatusCode, job)
+			assert.Contains(t, string(msg), "invalid job name "+job, job)
+		}
+	})
+
+	t.Run("failure without token", func(t *testing.T) {
+		for _, auth := range []string{"", "Bearer wrong"} {
+			req, _ := http.NewRequest(http.MethodGet, server.URL+"/builds", nil)
+			if auth != "" {
+				req.Header.Set("Authorization", auth)
+			}
+			res, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res.Body.Close()
+			assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+		}
+	})
+
+	t.Run("failure by other origin", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"/builds", nil)
+		req.Header.Set("Authorization", "Bearer "+testDaemonToken)
+		req.Header.Set("Origin", "https://example.com")
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+
+		req.Header.Set("Origin", server.URL)
+		res, err = http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	})
+
+	t.Run("failure by content type", func(t *testing.T) {
+		for _, contentType := range []string{"", "text/plain", "application/x-www-form-urlencoded"} {
+			req, _ := http.NewRequest(http.MethodPost, server.URL+"/builds", strings.NewReader(`{"job": "main", "srcDir": "."}`))
+			req.Header.Set("Authorization", "Bearer "+testDaemonToken)
+			if contentType != "" {
+				req.Header.Set("Content-Type", contentType)
+			}
+			res, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res.Body.Close()
+			assert.Equal(t, http.StatusUnsupportedMediaType, res.StatusCode, contentType)
+		}
+	})
+}
+
+func TestDaemonCmd(t *testing.T) {
+	defer func() {
+		listenAndServe = http.ListenAndServe
+	}()
+
+	tokenFile := filepath.Join(t.TempDir(), ".sdlocal", daemonTokenFileName)
+	origDaemonTokenFile := daemonTokenFile
+	t.Cleanup(func() { daemonTokenFile = origDaemonTokenFile })
+	daemonTokenFile = func() (string, error) { return tokenFile, nil }
+
+	testCases := []struct {
+		name     string
+		args     []string
+		wantAddr string
+	}{
+		{
+			name:     "success",
+			args:     []string{},
+			wantAddr: "localhost:8090",
+		},
+		{
+			name:     "success with addr",
+			args:     []string{"--addr", ":9000"},
+			wantAddr: ":9000",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			var addr string
+			listenAndServe = func(a string, handler http.Handler) error {
+				addr = a
+				return nil
+			}
+
+			cmd := newDaemonCmd()
+			cmd.SetArgs(tt.args)
+			buf := bytes.NewBuffer(nil)
+			cmd.SetOut(buf)
+			err := cmd.Execute()
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantAddr, addr)
+			assert.Equal(t, fmt.Sprintf("Listening on http://%s\nThe token is written in %s\n", tt.wantAddr, tokenFile), buf.String())
+
+			info, err := os.Stat(tokenFile)
+			assert.Nil(t, err)
+			assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+			token, _ := ioutil.ReadFile(tokenFile)
+			assert.Regexp(t, "^[0-9a-f]{64}\n$", string(token))
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/screwdriver-cd/sd-local/screwdriver"
+	"github.com/spf13/cobra"
+)
+
+func printChanges(out io.Writer, title string, changes []screwdriver.Change) {
+	if len(changes) == 0 {
+		return
+	}
+	fmt.Fprintf(out, "    %s:\n", title)
+	for _, c := range changes {
+		fmt.Fprintf(out, "      %s\n", c)
+	}
+}
+
+// printJobDiffs prints the differences of the jobs from the remote pipeline to the local screwdriver.yaml.
+func printJobDiffs(out io.Writer, pipelineID int, diffs []screwdriver.JobDiff) {
+	if len(diffs) == 0 {
+		fmt.Fprintf(out, "No differences from the jobs of pipeline %d\n", pipelineID)
+		return
+	}
+
+	fmt.Fprintf(out, "Differences from the jobs of pipeline %d (- remote, + local)\n", pipelineID)
+	for _, d := range diffs {
+		fmt.Fprintf(out, "%s %s\n", d.Kind, d.Name)
+		for _, c := range d.Fields {
+			fmt.Fprintf(out, "    %s\n", c)
+		}
+		printChanges(out, "steps", d.Steps)
+		printChanges(out, "environment", d.Environment)
+	}
+}
+
+func newDiffCmd() *cobra.Command {
+	var pipelineID int
+
+	diffCmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Show differences of screwdriver.yaml from a pipeline.",
+		Long: `Validate the local screwdriver.yaml and show the differences of its jobs from the current jobs of the pipeline,
+which are the jobs, their image, requires, secrets, steps and environment variables.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if pipelineID == 0 {
+				return errors.New("`pipeline` must be specified")
+			}
+			cmd.SilenceUsage = true
+
+			cwd, err := os.Getwd()
+			if err != nil {
+				return err
+			}
+
+			api, err := remoteAPI()
+			if err != nil {
+				return err
+			}
+
+			local, err := api.Jobs(filepath.Join(cwd, "screwdriver.yaml"))
+			if err != nil {
+				return err
+			}
+
+			remote, err := api.PipelineJobs(pipelineID)
+			if err != nil {
+				return err
+			}
+
+			printJobDiffs(cmd.OutOrStdout(), pipelineID, screwdriver.DiffJobs(remote, local))
+			return nil
+		},
+	}
+
+	diffCmd.Flags().IntVar(
+		&pipelineID,
+		"pipeline",
+		0,
+		"ID of the pipeline to compare with.")
+
+	return diffCmd
+}
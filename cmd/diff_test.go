@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/screwdriver-cd/sd-local/screwdriver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrintJobDiffs(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		buf := bytes.NewBuffer(nil)
+		printJobDiffs(buf, 1, []screwdriver.JobDiff{
+			{Kind: screwdriver.Added, Name: "deploy"},
+			{
+				Kind:        screwdriver.Changed,
+				Name:        "main",
+				Fields:      []screwdriver.Change{{Kind: screwdriver.Changed, Name: "image", Old: "node:10", New: "node:12"}},
+				Steps:       []screwdriver.Change{{Kind: screwdriver.Added, Name: "coverage", New: "npm run cover"}},
+				Environment: []screwdriver.Change{{Kind: screwdriver.Removed, Name: "DEBUG", Old: "1"}},
+			},
+			{Kind: screwdriver.Removed, Name: "old"},
+		})
+
+		want := `Differences from the jobs of pipeline 1 (- remote, + local)
++ deploy
+~ main
+    ~ image: node:10 -> node:12
+    steps:
+      + coverage: npm run cover
+    environment:
+      - DEBUG: 1
+- old
+`
+		assert.Equal(t, want, buf.String())
+	})
+
+	t.Run("success without differences", func(t *testing.T) {
+		buf := bytes.NewBuffer(nil)
+		printJobDiffs(buf, 1, []screwdriver.JobDiff{})
+		assert.Equal(t, "No differences from the jobs of pipeline 1\n", buf.String())
+	})
+}
+
+func TestDiffCmd(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		cmd := newDiffCmd()
+		cmd.SetArgs([]string{"--pipeline", "1"})
+		buf := bytes.NewBuffer(nil)
+		cmd.SetOut(buf)
+		err := cmd.Execute()
+		assert.Nil(t, err)
+		assert.Contains(t, buf.String(), "~ main\n")
+	})
+
+	t.Run("failure by no pipeline", func(t *testing.T) {
+		cmd := newDiffCmd()
+		cmd.SetArgs([]string{})
+		cmd.SetOut(bytes.NewBuffer(nil))
+		err := cmd.Execute()
+		assert.Equal(t, "`pipeline` must be specified", err.Error())
+	})
+}
@@ -0,0 +1,219 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/screwdriver-cd/sd-local/config"
+	"github.com/screwdriver-cd/sd-local/launch"
+	"github.com/screwdriver-cd/sd-local/screwdriver"
+	"github.com/spf13/cobra"
+)
+
+// minDiskFree is the free disk space under which the builds can fail by pulling the images and writing the artifacts.
+const minDiskFree = 2 * 1024 * 1024 * 1024
+
+var (
+	launchCheckRuntime = launch.CheckRuntime
+	launchHasImage     = launch.HasImage
+	configValidate     = config.Validate
+	diskFree           = statfsFree
+)
+
+// statfsFree returns the disk space in the file system of the path which is available to the user.
+func statfsFree(path string) (int64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, err
+	}
+	return int64(stat.Bavail) * int64(stat.Bsize), nil
+}
+
+const (
+	checkOK   = "OK"
+	checkWarn = "WARN"
+	checkFail = "FAIL"
+)
+
+// check is the result of a check of sd-local doctor, where fix is the suggestion to fix the failure.
+type check struct {
+	name   string
+	status string
+	detail string
+	fix    string
+}
+
+type doctor struct {
+	entry      config.Entry
+	configPath string
+	sdlocalDir string
+	dir        string
+	sudo       bool
+	checks     []check
+}
+
+func (d *doctor) add(name, status, detail, fix string) {
+	d.checks = append(d.checks, check{name: name, status: status, detail: detail, fix: fix})
+}
+
+func (d *doctor) checkConfig() {
+	if err := configValidate(d.configPath); err != nil {
+		d.add("config", checkFail, err.Error(), `Fix the config with "sd-local config edit".`)
+		return
+	}
+	d.add("config", checkOK, d.configPath, "")
+}
+
+// checkRuntime checks the container runtime, and the launcher image when the runtime is available.
+func (d *doctor) checkRuntime() {
+	option := launch.CheckOption{Runtime: d.entry.Runtime, UseSudo: d.sudo, FlagVerbose: flagVerbose}
+	status, err := launchCheckRuntime(option)
+	if err != nil {
+		d.add("container runtime", checkFail, err.Error(),
+			fmt.Sprintf(`Install and start %s, or set the installed one with "sd-local config set runtime <docker|podman|nerdctl>". Use --sudo if the runtime requires root.`, status.Runtime))
+		return
+	}
+	d.add("container runtime", checkOK, fmt.Sprintf("%s %s", status.Runtime, status.Version), "")
+
+	image := fmt.Sprintf("%s:%s", d.entry.Launcher.Image, d.entry.Launcher.Version)
+	if !launchHasImage(option, image) {
+		d.add("launcher image", checkWarn, fmt.Sprintf("%s is not pulled", image),
+			fmt.Sprintf(`It is pulled by the first build, or pull it beforehand with "%s pull %s".`, status.Runtime, image))
+		return
+	}
+	d.add("launcher image", checkOK, image, "")
+}
+
+func (d *doctor) checkDisk() {
+	free, err := diskFree(d.dir)
+	if err != nil {
+		d.add("disk space", checkWarn, fmt.Sprintf("failed to get free disk space: %v", err), "")
+		return
+	}
+
+	detail := fmt.Sprintf("%s free in %s", launch.FormatSize(free), d.dir)
+	if free < minDiskFree {
+		d.add("disk space", checkFail, detail,
+			`Free up the disk space, such as removing the containers, the images and the caches of sd-local with "sd-local clean --all".`)
+		return
+	}
+	d.add("disk space", checkOK, detail, "")
+}
+
+// checkAPI checks that Screwdriver.cd API is reachable and the token is valid by issuing JWT without the cache.
+func (d *doctor) checkAPI() {
+	if d.entry.APIURL == "" {
+		d.add("api", checkFail, "api-url is not set", `Set the URL of Screwdriver.cd API with "sd-local config set api-url <url>".`)
+		return
+	}
+	if d.entry.Token == "" {
+		d.add("api", checkOK, d.entry.APIURL, "")
+		d.add("token", checkFail, "token is not set", `Log in with "sd-local auth login".`)
+		return
+	}
+
+	option, err := apiOption(&d.entry, d.sdlocalDir)
+	if err != nil {
+		d.add("api", checkFail, err.Error(), `Fix the certificates of the API with "sd-local config set api-ca-cert <path>".`)
+		return
+	}
+	option.JWTCacheDir = ""
+
+	err = apiNew(d.entry.APIURL, d.entry.Token, option).InitJWT()
+	if screwdriver.IsUnreachable(err) {
+		d.add("api", checkFail, err.Error(), `Check the network and api-url of the config, or run the builds with --offline.`)
+		return
+	}
+	d.add("api", checkOK, d.entry.APIURL, "")
+
+	if err != nil {
+		d.add("token", checkFail, err.Error(), `Log in again with "sd-local auth login", or set a valid token with "sd-local config set token <token>".`)
+		return
+	}
+	d.add("token", checkOK, "JWT is issued", "")
+}
+
+// run runs all checks and returns their results.
+func (d *doctor) run() []check {
+	d.checkConfig()
+	d.checkRuntime()
+	d.checkDisk()
+	d.checkAPI()
+	return d.checks
+}
+
+// printChecks prints the results of the checks with the suggestions to fix them, and returns the number of the failed checks.
+func printChecks(out io.Writer, checks []check) int {
+	failed := 0
+	for _, c := range checks {
+		fmt.Fprintf(out, "[%s] %s: %s\n", c.status, c.name, c.detail)
+		if c.fix != "" {
+			fmt.Fprintf(out, "       %s\n", c.fix)
+		}
+		if c.status == checkFail {
+			failed++
+		}
+	}
+	return failed
+}
+
+func newDoctorCmd() *cobra.Command {
+	var sudo bool
+
+	doctorCmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnose problems of the environment of sd-local.",
+		Long: `Check the environment which the builds of sd-local require, which are the config,
+the container runtime and its version, the launcher image, the free disk space,
+the reachability of Screwdriver.cd API and the token, and show how to fix the failed checks.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			home, err := homedir.Dir()
+			if err != nil {
+				return err
+			}
+			sdlocalDir := filepath.Join(home, ".sdlocal")
+			configPath := filepath.Join(sdlocalDir, "config")
+
+			c, err := configNew(configPath)
+			if err != nil {
+				return err
+			}
+			current, err := c.Entry(c.Current)
+			if err != nil {
+				return err
+			}
+
+			cwd, err := os.Getwd()
+			if err != nil {
+				return err
+			}
+
+			d := &doctor{
+				entry:      current.Resolved(),
+				configPath: configPath,
+				sdlocalDir: sdlocalDir,
+				dir:        cwd,
+				sudo:       sudo,
+			}
+			if failed := printChecks(cmd.OutOrStdout(), d.run()); failed != 0 {
+				return fmt.Errorf("%d checks failed", failed)
+			}
+			return nil
+		},
+	}
+
+	doctorCmd.Flags().BoolVar(
+		&sudo,
+		"sudo",
+		false,
+		"Use sudo command for container runtime.")
+
+	return doctorCmd
+}
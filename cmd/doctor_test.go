@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/screwdriver-cd/sd-local/config"
+	"github.com/screwdriver-cd/sd-local/launch"
+	"github.com/screwdriver-cd/sd-local/screwdriver"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockInvalidTokenAPI struct{ mockAPI }
+
+func (mock mockInvalidTokenAPI) InitJWT() error {
+	return errors.New("failed to get JWT: StatusCode 401")
+}
+
+func TestDoctor(t *testing.T) {
+	defer func() {
+		launchCheckRuntime = launch.CheckRuntime
+		launchHasImage = launch.HasImage
+		configValidate = config.Validate
+		diskFree = statfsFree
+	}()
+	origAPINew := apiNew
+	t.Cleanup(func() { apiNew = origAPINew })
+
+	entry := config.Entry{
+		APIURL:   "https://api.screwdriver.cd",
+		Token:    "token",
+		Launcher: config.Launcher{Image: "screwdrivercd/launcher", Version: "stable"},
+	}
+	newDoctor := func() *doctor {
+		return &doctor{entry: entry, configPath: "/home/user/.sdlocal/config", sdlocalDir: "/home/user/.sdlocal", dir: "/home/user/repo"}
+	}
+
+	t.Run("success", func(t *testing.T) {
+		var jwtCacheDir string
+		configValidate = func(path string) error { return nil }
+		launchCheckRuntime = func(option launch.CheckOption) (launch.RuntimeStatus, error) {
+			return launch.RuntimeStatus{Runtime: "docker", Version: "24.0.7"}, nil
+		}
+		launchHasImage = func(option launch.CheckOption, image string) bool { return true }
+		diskFree = func(path string) (int64, error) { return 10 * 1024 * 1024 * 1024, nil }
+		apiNew = func(url, token string, option screwdriver.Option) screwdriver.API {
+			jwtCacheDir = option.JWTCacheDir
+			return mockAPI{}
+		}
+
+		buf := bytes.NewBuffer(nil)
+		failed := printChecks(buf, newDoctor().run())
+		assert.Equal(t, 0, failed)
+		assert.Equal(t, "", jwtCacheDir)
+		assert.Equal(t, `[OK] config: /home/user/.sdlocal/config
+[OK] container runtime: docker 24.0.7
+[OK] launcher image: screwdrivercd/launcher:stable
+[OK] disk space: 10.0 GB free in /home/user/repo
+[OK] api: https://api.screwdriver.cd
+[OK] token: JWT is issued
+`, buf.String())
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		configValidate = func(path string) error {
+			return errors.New(`unknown key "tokn" in entry default, did you mean "token"?`)
+		}
+		launchCheckRuntime = func(option launch.CheckOption) (launch.RuntimeStatus, error) {
+			return launch.RuntimeStatus{Runtime: "docker"}, errors.New("failed to get version of docker: Cannot connect to the Docker daemon")
+		}
+		launchHasImage = func(option launch.CheckOption, image string) bool {
+			t.Error("the launcher image must not be checked without the container runtime")
+			return false
+		}
+		diskFree = func(path string) (int64, error) { return 1024 * 1024 * 1024, nil }
+		apiNew = func(url, token string, option screwdriver.Option) screwdriver.API { return mockInvalidTokenAPI{} }
+
+		checks := newDoctor().run()
+		failed := printChecks(bytes.NewBuffer(nil), checks)
+		assert.Equal(t, 4, failed)
+
+		statuses := make(map[string]string, len(checks))
+		for _, c := range checks {
+			statuses[c.name] = c.status
+			if c.status == checkFail {
+				assert.NotEqual(t, "", c.fix)
+			}
+		}
+		assert.Equal(t, map[string]string{
+			"config":            checkFail,
+			"container runtime": checkFail,
+			"disk space":        checkFail,
+			"api":               checkOK,
+			"token":             checkFail,
+		}, statuses)
+	})
+
+	t.Run("warning without launcher image", func(t *testing.T) {
+		launchCheckRuntime = func(option launch.CheckOption) (launch.RuntimeStatus, error) {
+			return launch.RuntimeStatus{Runtime: "podman", Version: "4.9.3"}, nil
+		}
+		launchHasImage = func(option launch.CheckOption, image string) bool { return false }
+
+		d := newDoctor()
+		d.checkRuntime()
+		assert.Equal(t, check{
+			name:   "launcher image",
+			status: checkWarn,
+			detail: "screwdrivercd/launcher:stable is not pulled",
+			fix:    `It is pulled by the first build, or pull it beforehand with "podman pull screwdrivercd/launcher:stable".`,
+		}, d.checks[1])
+	})
+
+	t.Run("failure by unreachable API", func(t *testing.T) {
+		apiNew = func(url, token string, option screwdriver.Option) screwdriver.API { return mockUnreachableAPI{} }
+
+		d := newDoctor()
+		d.checkAPI()
+		assert.Equal(t, 1, len(d.checks))
+		assert.Equal(t, checkFail, d.checks[0].status)
+		assert.Equal(t, fmt.Sprintf("failed to send request: %v", &url.Error{Op: "Get", URL: "http://localhost", Err: errors.New("connection refused")}), d.checks[0].detail)
+	})
+
+	t.Run("failure without token", func(t *testing.T) {
+		d := newDoctor()
+		d.entry.Token = ""
+		d.checkAPI()
+		assert.Equal(t, []check{
+			{name: "api", status: checkOK, detail: "https://api.screwdriver.cd"},
+			{name: "token", status: checkFail, detail: "token is not set", fix: `Log in with "sd-local auth login".`},
+		}, d.checks)
+	})
+}
+
+func TestPrintChecks(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	failed := printChecks(buf, []check{
+		{name: "config", status: checkOK, detail: "/home/user/.sdlocal/config"},
+		{name: "disk space", status: checkFail, detail: "1.0 GB free in /home/user/repo", fix: `Free up the disk space with "sd-local clean --all".`},
+	})
+
+	assert.Equal(t, 1, failed)
+	assert.Equal(t, `[OK] config: /home/user/.sdlocal/config
+[FAIL] disk space: 1.0 GB free in /home/user/repo
+       Free up the disk space with "sd-local clean --all".
+`, buf.String())
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	envFormatShell = "shell"
+	envFormatJSON  = "json"
+)
+
+// newEnvCmd returns the build command which prints the environment variables of the build instead of running it.
+// The environment variables are resolved in the same way as the build, so the flags of the build can be used.
+func newEnvCmd() *cobra.Command {
+	var format string
+
+	envCmd := newBuildCmd()
+	envCmd.Use = "env [job name]"
+	envCmd.Short = "Print the environment variables of the build."
+	envCmd.Long = `Print the environment variables of the build of the specified job name,
+which are resolved from the environment of the job and its template, the variables set by sd-local such as $SD_TOKEN,
+the parameters, the secrets and the flags such as --env in the same way as "sd-local build".
+The secrets and the tokens are masked.`
+
+	args := envCmd.Args
+	envCmd.Args = func(cmd *cobra.Command, a []string) error {
+		if err := args(cmd, a); err != nil {
+			return err
+		}
+		if len(a) != 0 && strings.Contains(a[0], ",") {
+			return errors.New("the environment variables can be printed only for a single job")
+		}
+		if format != envFormatShell && format != envFormatJSON {
+			return fmt.Errorf("`format` must be either %s or %s", envFormatShell, envFormatJSON)
+		}
+		dryRun, envFormat = true, format
+		return nil
+	}
+
+	for _, name := range buildOnlyFlags {
+		envCmd.Flags().MarkHidden(name)
+	}
+
+	envCmd.Flags().StringVar(
+		&format,
+		"format",
+		envFormatShell,
+		"Format of the environment variables, shell for the export statements or json.")
+
+	return envCmd
+}
+
+// printEnv prints the environment variables in the format, sorted by their names.
+func printEnv(w io.Writer, env map[string]string, format string) error {
+	if format == envFormatJSON {
+		out, err := json.MarshalIndent(env, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", out)
+		return err
+	}
+
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "export %s='%s'\n", name, strings.ReplaceAll(env[name], "'", `'"'"'`))
+	}
+	return nil
+}
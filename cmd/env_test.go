@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/screwdriver-cd/sd-local/launch"
+	"github.com/screwdriver-cd/sd-local/screwdriver"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockJWTAPI struct{ mockAPI }
+
+func (mock mockJWTAPI) JWT() string { return "jwt" }
+
+func TestEnvCmd(t *testing.T) {
+	defer func() {
+		dryRun, envFormat = false, ""
+		launchPlan = launch.NewPlan
+	}()
+
+	t.Run("Success env cmd", func(t *testing.T) {
+		origAPINew := apiNew
+		t.Cleanup(func() { apiNew = origAPINew })
+		apiNew = func(url, token string, option screwdriver.Option) screwdriver.API { return mockJWTAPI{} }
+
+		root := newEnvCmd()
+		root.SetArgs([]string{"test", "--env", "FOO=bar", "--format", "json"})
+		buf := bytes.NewBuffer(nil)
+		root.SetOut(buf)
+
+		origLaunchNew := launchNew
+		t.Cleanup(func() { launchNew = origLaunchNew })
+		launchNew = func(option launch.Option) launch.Launcher {
+			t.Error("the build must not be run by env cmd")
+			return mockLaunch{}
+		}
+		launchPlan = func(option launch.Option) (launch.Plan, error) {
+			assert.Equal(t, "test", option.JobName)
+			assert.Equal(t, launch.EnvVar{"FOO": "bar"}, option.OptionEnv)
+			return launch.Plan{Env: launch.EnvVar{"FOO": "bar", "SD_TOKEN": option.JWT}}, nil
+		}
+
+		err := root.Execute()
+		assert.Nil(t, err)
+		assert.Equal(t, "{\n  \"FOO\": \"bar\",\n  \"SD_TOKEN\": \"***\"\n}\n", buf.String())
+	})
+
+	t.Run("Failed env cmd with multiple jobs", func(t *testing.T) {
+		root := newEnvCmd()
+		root.SetArgs([]string{"main,test"})
+		root.SetOut(bytes.NewBuffer(nil))
+		err := root.Execute()
+		assert.Equal(t, "the environment variables can be printed only for a single job", err.Error())
+	})
+
+	t.Run("Failed env cmd with invalid format", func(t *testing.T) {
+		root := newEnvCmd()
+		root.SetArgs([]string{"test", "--format", "yaml"})
+		root.SetOut(bytes.NewBuffer(nil))
+		err := root.Execute()
+		assert.Equal(t, "`format` must be either shell or json", err.Error())
+	})
+}
+
+func TestPrintEnv(t *testing.T) {
+	env := map[string]string{"FOO": "foo bar", "QUOTE": "it's", "EMPTY": ""}
+
+	t.Run("success with shell", func(t *testing.T) {
+		buf := bytes.NewBuffer(nil)
+		err := printEnv(buf, env, envFormatShell)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "export EMPTY=''\nexport FOO='foo bar'\nexport QUOTE='it'\"'\"'s'\n", buf.String())
+	})
+
+	t.Run("success with json", func(t *testing.T) {
+		buf := bytes.NewBuffer(nil)
+		err := printEnv(buf, env, envFormatJSON)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "{\n  \"EMPTY\": \"\",\n  \"FOO\": \"foo bar\",\n  \"QUOTE\": \"it's\"\n}\n", buf.String())
+	})
+}
@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/screwdriver-cd/sd-local/state"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	historyNew  = state.NewHistory
+	historyFile = func() (string, error) {
+		home, err := homedir.Dir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(home, ".sdlocal", state.HistoryFileName), nil
+	}
+)
+
+// buildRecord records the build into the history when sd-local exits.
+// The build is recorded as canceled when sd-local is interrupted.
+type buildRecord struct {
+	mutex    *sync.Mutex
+	history  state.History
+	record   state.Record
+	recorded bool
+}
+
+// recordBuild starts recording the build run with args in dir.
+func recordBuild(job, dir, artifactsPath string, args []string) *buildRecord {
+	path, err := historyFile()
+	if err != nil {
+		logrus.Warnf("failed to record build history: %v", err)
+		return nil
+	}
+
+	b := &buildRecord{
+		mutex:   &sync.Mutex{},
+		history: historyNew(path),
+		record: state.Record{
+			Job:           job,
+			Args:          append([]string{}, args...),
+			Dir:           dir,
+			ArtifactsPath: artifactsPath,
+			Status:        jobFailure,
+			StartTime:     timeNow(),
+		},
+	}
+	addCleaner(b)
+
+	return b
+}
+
+// finish sets the status of the build from the error it returned.
+func (b *buildRecord) finish(err error) {
+	if b == nil {
+		return
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.record.Status == buildCanceled {
+		return
+	}
+	b.record.Status = jobSuccess
+	if err != nil {
+		b.record.Status = jobFailure
+	}
+}
+
+func (b *buildRecord) Kill(os.Signal) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.record.Status = buildCanceled
+}
+
+func (b *buildRecord) Clean() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.recorded {
+		return
+	}
+	b.recorded = true
+	b.record.EndTime = timeNow()
+
+	if _, err := b.history.Add(b.record); err != nil {
+		logrus.Warnf("failed to record build history: %v", err)
+	}
+}
+
+// historyFilter selects the builds shown in the history.
+type historyFilter struct {
+	job    string
+	status string
+	since  time.Duration
+	limit  int
+}
+
+func (f historyFilter) match(r state.Record) bool {
+	if f.job != "" {
+		matched := false
+		for _, job := range strings.Split(r.Job, ",") {
+			if job == f.job {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if f.status != "" && !strings.EqualFold(f.status, r.Status) {
+		return false
+	}
+
+	if f.since != 0 && r.StartTime.Before(timeNow().Add(-f.since)) {
+		return false
+	}
+
+	return true
+}
+
+// filterHistory returns the builds matching the filter from the newest.
+func filterHistory(records []state.Record, f historyFilter) []state.Record {
+	filtered := make([]state.Record, 0)
+	for i := len(records) - 1; i >= 0; i-- {
+		if f.limit > 0 && len(filtered) >= f.limit {
+			break
+		}
+		if f.match(records[i]) {
+			filtered = append(filtered, records[i])
+		}
+	}
+
+	return filtered
+}
+
+func printHistory(out io.Writer, records []state.Record) {
+	tw := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tJOB\tSTATUS\tSTARTED\tDURATION\tARTIFACTS")
+	for _, r := range records {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\n", r.ID, r.Job, r.Status, r.StartTime.Local().Format("2006-01-02 15:04:05"), r.EndTime.Sub(r.StartTime).Round(time.Second), r.ArtifactsPath)
+	}
+	tw.Flush()
+}
+
+func newHistoryCmd() *cobra.Command {
+	var filter historyFilter
+
+	historyCmd := &cobra.Command{
+		Use:   "history",
+		Short: "Show the history of local builds.",
+		Long: `Show the builds run on this machine from the newest.
+The history is recorded in ~/.sdlocal/history.jsonl, and a build can be run again with ` + "`sd-local rerun`" + `.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			path, err := historyFile()
+			if err != nil {
+				return err
+			}
+
+			records, err := historyNew(path).List()
+			if err != nil {
+				return err
+			}
+
+			printHistory(cmd.OutOrStdout(), filterHistory(records, filter))
+			return nil
+		},
+	}
+
+	historyCmd.Flags().StringVar(
+		&filter.job,
+		"job",
+		"",
+		"Show only the builds of the job.")
+
+	historyCmd.Flags().StringVar(
+		&filter.status,
+		"status",
+		"",
+		"Show only the builds of the status, SUCCESS, FAILURE or CANCELED.")
+
+	historyCmd.Flags().DurationVar(
+		&filter.since,
+		"since",
+		0,
+		"Show only the builds started within this period (e.g. 24h).")
+
+	historyCmd.Flags().IntVarP(
+		&filter.limit,
+		"limit",
+		"n",
+		20,
+		"Maximum number of builds to show. 0 shows all.")
+
+	return historyCmd
+}
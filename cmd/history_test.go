@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/screwdriver-cd/sd-local/state"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockHistory struct {
+	records []state.Record
+	err     error
+}
+
+func (h *mockHistory) Add(r state.Record) (state.Record, error) {
+	r.ID = len(h.records) + 1
+	h.records = append(h.records, r)
+	return r, h.err
+}
+
+func (h *mockHistory) List() ([]state.Record, error) {
+	return h.records, h.err
+}
+
+func TestRecordBuild(t *testing.T) {
+	defer func() {
+		timeNow = time.Now
+		cleaners = nil
+		historyNew = func(path string) state.History { return &mockHistory{} }
+	}()
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name       string
+		err        error
+		sig        os.Signal
+		wantStatus string
+	}{
+		{
+			name:       "success",
+			wantStatus: jobSuccess,
+		},
+		{
+			name:       "success with failed build",
+			err:        errors.New("failed to run build"),
+			wantStatus: jobFailure,
+		},
+		{
+			name:       "success with canceled build",
+			sig:        os.Interrupt,
+			wantStatus: buildCanceled,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &mockHistory{}
+			historyNew = func(path string) state.History { return h }
+			cleaners = nil
+			timeNow = func() time.Time { return start }
+
+			b := recordBuild("main", "/src", "/src/sd-artifacts", []string{"build", "main", "--offline"})
+			if tt.sig != nil {
+				kill(tt.sig)
+			}
+			b.finish(tt.err)
+			timeNow = func() time.Time { return start.Add(time.Minute) }
+			clean()
+			clean()
+
+			assert.Equal(t, []state.Record{{
+				ID:            1,
+				Job:           "main",
+				Args:          []string{"build", "main", "--offline"},
+				Dir:           "/src",
+				ArtifactsPath: "/src/sd-artifacts",
+				Status:        tt.wantStatus,
+				StartTime:     start,
+				EndTime:       start.Add(time.Minute),
+			}}, h.records)
+		})
+	}
+}
+
+func TestFilterHistory(t *testing.T) {
+	defer func() {
+		timeNow = time.Now
+	}()
+	now := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+
+	records := []state.Record{
+		{ID: 1, Job: "main", Status: jobSuccess, StartTime: now.Add(-48 * time.Hour)},
+		{ID: 2, Job: "main,test", Status: jobFailure, StartTime: now.Add(-2 * time.Hour)},
+		{ID: 3, Job: "test", Status: buildCanceled, StartTime: now.Add(-time.Hour)},
+		{ID: 4, Job: "main", Status: jobSuccess, StartTime: now.Add(-time.Minute)},
+	}
+
+	testCases := []struct {
+		name    string
+		filter  historyFilter
+		wantIDs []int
+	}{
+		{"success", historyFilter{}, []int{4, 3, 2, 1}},
+		{"success with job", historyFilter{job: "test"}, []int{3, 2}},
+		{"success with status", historyFilter{status: "success"}, []int{4, 1}},
+		{"success with since", historyFilter{since: 3 * time.Hour}, []int{4, 3, 2}},
+		{"success with limit", historyFilter{job: "main", limit: 2}, []int{4, 2}},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			ids := make([]int, 0)
+			for _, r := range filterHistory(records, tt.filter) {
+				ids = append(ids, r.ID)
+			}
+			assert.Equal(t, tt.wantIDs, ids)
+		})
+	}
+}
+
+func TestPrintHistory(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.Local)
+
+	buf := bytes.NewBuffer(nil)
+	printHistory(buf, []state.Record{
+		{ID: 12, Job: "main", Status: jobSuccess, StartTime: start, EndTime: start.Add(90 * time.Second), ArtifactsPath: "/src/sd-artifacts"},
+		{ID: 3, Job: "all", Status: buildCanceled, StartTime: start, EndTime: start.Add(5 * time.Second), ArtifactsPath: "/src/sd-artifacts"},
+	})
+	want := "ID  JOB   STATUS    STARTED              DURATION  ARTIFACTS\n" +
+		"12  main  SUCCESS   2020-01-01 00:00:00  1m30s     /src/sd-artifacts\n" +
+		"3   all   CANCELED  2020-01-01 00:00:00  5s        /src/sd-artifacts\n"
+	assert.Equal(t, want, buf.String())
+}
+
+func TestHistoryCmd(t *testing.T) {
+	defer func() {
+		historyNew = func(path string) state.History { return &mockHistory{} }
+	}()
+
+	t.Run("success", func(t *testing.T) {
+		historyNew = func(path string) state.History {
+			return &mockHistory{records: []state.Record{{ID: 1, Job: "main", Status: jobSuccess}, {ID: 2, Job: "test", Status: jobFailure}}}
+		}
+
+		cmd := newHistoryCmd()
+		cmd.SetArgs([]string{"--job", "test"})
+		buf := bytes.NewBuffer(nil)
+		cmd.SetOut(buf)
+		err := cmd.Execute()
+		assert.Nil(t, err)
+		assert.Contains(t, buf.String(), "2   test  FAILURE")
+		assert.NotContains(t, buf.String(), "main")
+	})
+
+	t.Run("failure by history file", func(t *testing.T) {
+		historyNew = func(path string) state.History { return &mockHistory{err: errors.New("failed to read history file")} }
+
+		cmd := newHistoryCmd()
+		cmd.SetArgs([]string{})
+		cmd.SetOut(bytes.NewBuffer(nil))
+		err := cmd.Execute()
+		assert.Equal(t, "failed to read history file", err.Error())
+	})
+}
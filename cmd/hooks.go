@@ -0,0 +1,253 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/screwdriver-cd/sd-local/config"
+	"github.com/spf13/cobra"
+)
+
+// hookMarker is written into the hooks installed by sd-local, so that they are overwritten and uninstalled without --force.
+const hookMarker = "# Installed by sd-local hooks install."
+
+// gitHooks is the list of the git hooks which sd-local can install
+var gitHooks = []string{"pre-push", "pre-commit"}
+
+var gitHooksDir = defaultGitHooksDir
+
+// defaultGitHooksDir returns the hooks directory of the repository in dir, which respects core.hooksPath and the worktrees.
+func defaultGitHooksDir(dir string) (string, error) {
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "--git-path", "hooks").Output()
+	if err != nil {
+		return "", fmt.Errorf("%s is not a git repository: %v", dir, err)
+	}
+
+	// The path is relative to dir unless core.hooksPath is absolute
+	hooksDir := strings.TrimSpace(string(out))
+	if !filepath.IsAbs(hooksDir) {
+		hooksDir = filepath.Join(dir, hooksDir)
+	}
+	return hooksDir, nil
+}
+
+func hookQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
+
+// hookScript returns the hook which runs the job with sd-local, whose failure blocks the push or the commit.
+func hookScript(exe, hook, jobName string) string {
+	return fmt.Sprintf(`#!/bin/sh
+%s
+# The %s is blocked when the job fails, which can be skipped with --no-verify.
+exec %s build %s </dev/null
+`, hookMarker, strings.TrimPrefix(hook, "pre-"), hookQuote(exe), hookQuote(jobName))
+}
+
+// isSDLocalHook reports whether the hook at path was installed by sd-local.
+func isSDLocalHook(path string) (bool, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	return strings.Contains(string(content), hookMarker), nil
+}
+
+func validateHook(hook string) error {
+	for _, h := range gitHooks {
+		if h == hook {
+			return nil
+		}
+	}
+	return fmt.Errorf("`hook` must be one of %v", gitHooks)
+}
+
+// installHook writes the hook running the job into the hooks directory of the repository in dir, and returns its path.
+// The existing hook which was not installed by sd-local is not overwritten unless force is set.
+func installHook(dir, hook, jobName, exe string, force bool) (string, error) {
+	hooksDir, err := gitHooksDir(dir)
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(hooksDir, hook)
+
+	if !force {
+		ours, err := isSDLocalHook(path)
+		if err != nil && !os.IsNotExist(err) {
+			return "", err
+		}
+		if err == nil && !ours {
+			return "", fmt.Errorf("%s hook already exists, use --force to overwrite it", hook)
+		}
+	}
+
+	if err := os.MkdirAll(hooksDir, 0777); err != nil {
+		return "", err
+	}
+	if err := ioutil.WriteFile(path, []byte(hookScript(exe, hook, jobName)), 0755); err != nil {
+		return "", fmt.Errorf("failed to write %s hook: %v", hook, err)
+	}
+	// WriteFile does not change the mode of the existing file
+	if err := os.Chmod(path, 0755); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// uninstallHook removes the hook installed by sd-local from the repository in dir.
+func uninstallHook(dir, hook string) (string, error) {
+	hooksDir, err := gitHooksDir(dir)
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(hooksDir, hook)
+
+	ours, err := isSDLocalHook(path)
+	if os.IsNotExist(err) {
+		return "", fmt.Errorf("%s hook is not installed", hook)
+	}
+	if err != nil {
+		return "", err
+	}
+	if !ours {
+		return "", fmt.Errorf("%s hook was not installed by sd-local", hook)
+	}
+
+	return path, os.Remove(path)
+}
+
+func newHooksInstallCmd() *cobra.Command {
+	var jobName string
+	var hook string
+	var force bool
+
+	hooksInstallCmd := &cobra.Command{
+		Use:   "install",
+		Short: "Install the git hook running a job.",
+		Long: `Install the git hook into the repository of the current directory, which runs the job with "sd-local build"
+and blocks the push (or the commit with --hook pre-commit) when the job fails.
+The job defaults to job in .sd-local.yaml of the current directory.`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if err := cobra.NoArgs(cmd, args); err != nil {
+				return err
+			}
+			return validateHook(hook)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			if jobName == "" {
+				name, err := defaultJobName()
+				if err == errNoJobName {
+					return fmt.Errorf("requires --job, or job in %s", config.ProjectFileName)
+				}
+				if err != nil {
+					return err
+				}
+				jobName = name
+			}
+
+			exe, err := osExecutable()
+			if err != nil {
+				return fmt.Errorf("failed to get executable: %v", err)
+			}
+
+			cwd, err := os.Getwd()
+			if err != nil {
+				return err
+			}
+
+			path, err := installHook(cwd, hook, jobName, exe, force)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Installed %s hook running job %s into %s\n", hook, jobName, path)
+			return nil
+		},
+	}
+
+	hooksInstallCmd.Flags().StringVar(
+		&jobName,
+		"job",
+		"",
+		"Job run by the hook, such as lint. Defaults to job in .sd-local.yaml.")
+
+	hooksInstallCmd.Flags().StringVar(
+		&hook,
+		"hook",
+		"pre-push",
+		"Git hook to install, which is pre-push or pre-commit.")
+
+	hooksInstallCmd.Flags().BoolVar(
+		&force,
+		"force",
+		false,
+		"Overwrite the existing hook which was not installed by sd-local.")
+
+	return hooksInstallCmd
+}
+
+func newHooksUninstallCmd() *cobra.Command {
+	var hook string
+
+	hooksUninstallCmd := &cobra.Command{
+		Use:   "uninstall",
+		Short: "Remove the git hook installed by sd-local.",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if err := cobra.NoArgs(cmd, args); err != nil {
+				return err
+			}
+			return validateHook(hook)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			cwd, err := os.Getwd()
+			if err != nil {
+				return err
+			}
+
+			path, err := uninstallHook(cwd, hook)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Removed %s\n", path)
+			return nil
+		},
+	}
+
+	hooksUninstallCmd.Flags().StringVar(
+		&hook,
+		"hook",
+		"pre-push",
+		"Git hook to remove, which is pre-push or pre-commit.")
+
+	return hooksUninstallCmd
+}
+
+func newHooksCmd() *cobra.Command {
+	hooksCmd := &cobra.Command{
+		Use:   "hooks",
+		Short: "Install the git hooks running the jobs.",
+		Long: `Install the git hooks which run a job before the push or the commit,
+so that the changes failing the job are not pushed.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := cmd.Help()
+			if err != nil {
+				return err
+			}
+			return nil
+		},
+	}
+
+	hooksCmd.AddCommand(
+		newHooksInstallCmd(),
+		newHooksUninstallCmd(),
+	)
+
+	return hooksCmd
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHookScript(t *testing.T) {
+	assert.Equal(t, `#!/bin/sh
+# Installed by sd-local hooks install.
+# The push is blocked when the job fails, which can be skipped with --no-verify.
+exec '/usr/local/bin/sd-local' build 'lint' </dev/null
+`, hookScript("/usr/local/bin/sd-local", "pre-push", "lint"))
+}
+
+func TestInstallHook(t *testing.T) {
+	defer func() {
+		gitHooksDir = defaultGitHooksDir
+	}()
+
+	dir, err := ioutil.TempDir("", "hooks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	hooksDir := filepath.Join(dir, ".git", "hooks")
+	gitHooksDir = func(d string) (string, error) {
+		assert.Equal(t, dir, d)
+		return hooksDir, nil
+	}
+	path := filepath.Join(hooksDir, "pre-commit")
+
+	t.Run("success", func(t *testing.T) {
+		p, err := installHook(dir, "pre-commit", "lint", "/usr/local/bin/sd-local", false)
+		assert.Nil(t, err)
+		assert.Equal(t, path, p)
+
+		content, err := ioutil.ReadFile(path)
+		assert.Nil(t, err)
+		assert.Equal(t, hookScript("/usr/local/bin/sd-local", "pre-commit", "lint"), string(content))
+		info, err := os.Stat(path)
+		assert.Nil(t, err)
+		assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+	})
+
+	t.Run("success by overwriting the hook of sd-local", func(t *testing.T) {
+		_, err := installHook(dir, "pre-commit", "test", "/usr/local/bin/sd-local", false)
+		assert.Nil(t, err)
+
+		content, _ := ioutil.ReadFile(path)
+		assert.Contains(t, string(content), "build 'test'")
+	})
+
+	t.Run("failure by the other hook", func(t *testing.T) {
+		if err := ioutil.WriteFile(path, []byte("#!/bin/sh\nnpm run lint\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := installHook(dir, "pre-commit", "lint", "/usr/local/bin/sd-local", false)
+		assert.EqualError(t, err, "pre-commit hook already exists, use --force to overwrite it")
+
+		_, err = uninstallHook(dir, "pre-commit")
+		assert.EqualError(t, err, "pre-commit hook was not installed by sd-local")
+	})
+
+	t.Run("success with force", func(t *testing.T) {
+		_, err := installHook(dir, "pre-commit", "lint", "/usr/local/bin/sd-local", true)
+		assert.Nil(t, err)
+
+		p, err := uninstallHook(dir, "pre-commit")
+		assert.Nil(t, err)
+		assert.Equal(t, path, p)
+		_, err = os.Stat(path)
+		assert.True(t, os.IsNotExist(err))
+
+		_, err = uninstallHook(dir, "pre-commit")
+		assert.EqualError(t, err, "pre-commit hook is not installed")
+	})
+}
+
+func TestHooksInstallCmd(t *testing.T) {
+	defer func() {
+		setup()
+	}()
+
+	t.Run("failure by invalid hook", func(t *testing.T) {
+		cmd := newHooksInstallCmd()
+		cmd.SetArgs([]string{"--job", "lint", "--hook", "post-merge"})
+		cmd.SetOut(ioutil.Discard)
+		assert.EqualError(t, cmd.Execute(), "`hook` must be one of [pre-push pre-commit]")
+	})
+
+	t.Run("failure without job", func(t *testing.T) {
+		cmd := newHooksInstallCmd()
+		cmd.SetArgs([]string{})
+		cmd.SetOut(ioutil.Discard)
+		assert.EqualError(t, cmd.Execute(), "requires --job, or job in .sd-local.yaml")
+	})
+}
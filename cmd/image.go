@@ -0,0 +1,222 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/screwdriver-cd/sd-local/config"
+	"github.com/screwdriver-cd/sd-local/launch"
+	"github.com/screwdriver-cd/sd-local/screwdriver"
+	"github.com/spf13/cobra"
+)
+
+// defaultBundleFile is the tarball of the images used when the path is omitted
+const defaultBundleFile = "sd-local-images.tar"
+
+var (
+	saveImages = launch.SaveImages
+	loadImages = launch.LoadImages
+)
+
+// bundleImages returns the images which the builds of the jobs pull, which are the launcher image and the images of the jobs.
+// The images in the project file take precedence over the images of the jobs as in the builds,
+// and they are rewritten with the image aliases and the registry mirror of the config.
+func bundleImages(entry config.Entry, jobs map[string]screwdriver.Job, projectImages map[string]string) []string {
+	names := make([]string, 0, len(jobs))
+	for name := range jobs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	images := make([]string, 0, len(jobs)+1)
+	seen := make(map[string]bool, len(jobs)+1)
+	add := func(image string) {
+		image = launch.ResolveImage(image, entry)
+		// The images built from the Dockerfiles are not pulled, so they are built on the machines
+		if image == "" || seen[image] || launch.IsDockerfileImage(image) {
+			return
+		}
+		seen[image] = true
+		images = append(images, image)
+	}
+
+	add(fmt.Sprintf("%s:%s", entry.Launcher.Image, entry.Launcher.Version))
+	for _, name := range names {
+		image := jobs[name].Image
+		if i, ok := projectImages[name]; ok {
+			image = i
+		}
+		add(image)
+	}
+
+	return images
+}
+
+func newImageSaveCmd() *cobra.Command {
+	var output string
+	var offline bool
+	var runtime string
+	var platform string
+
+	imageSaveCmd := &cobra.Command{
+		Use:   "save [path]",
+		Short: "Save the images used by the builds into a tarball.",
+		Long: `Save the launcher image and the images of the jobs in screwdriver.yaml into a tarball,
+which can be loaded with "sd-local image load" on the machines without network access.
+The path of screwdriver.yaml defaults to the one in the current directory.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			cwd, err := os.Getwd()
+			if err != nil {
+				return err
+			}
+
+			sdYAMLPath := filepath.Join(cwd, "screwdriver.yaml")
+			if len(args) == 1 {
+				sdYAMLPath = args[0]
+			}
+
+			api, entry, err := validatorAPI(offline)
+			if err != nil {
+				return err
+			}
+
+			jobs, err := api.Jobs(sdYAMLPath)
+			if err != nil {
+				return err
+			}
+
+			project, err := readProject(cwd)
+			if err != nil {
+				return err
+			}
+
+			if runtime == "" {
+				runtime = entry.Runtime
+			}
+			if platform == "" {
+				platform = entry.Platform
+			}
+
+			images := bundleImages(entry, jobs, project.Images)
+			if err := saveImages(output, images, launch.BundleOption{Runtime: runtime, Platform: platform, FlagVerbose: flagVerbose}); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Saved %d images into %s\n", len(images), output)
+			for _, image := range images {
+				fmt.Fprintf(cmd.OutOrStdout(), "  %s\n", image)
+			}
+
+			return nil
+		},
+	}
+
+	imageSaveCmd.Flags().StringVarP(
+		&output,
+		"output",
+		"o",
+		defaultBundleFile,
+		"Path to the tarball of the images.")
+
+	imageSaveCmd.Flags().BoolVar(
+		&offline,
+		"offline",
+		false,
+		"Resolve the jobs in screwdriver.yaml locally without calling Screwdriver.cd API. Only the templates cached by the previous builds can be used in offline mode.")
+
+	imageSaveCmd.Flags().StringVar(
+		&runtime,
+		"runtime",
+		"",
+		"Container runtime to save the images, docker, podman or nerdctl. Defaults to the runtime of the current config.")
+
+	imageSaveCmd.Flags().StringVar(
+		&platform,
+		"platform",
+		"",
+		"Platform of the images to pull, linux/amd64 or linux/arm64. Defaults to the platform of the current config, or the platform of the host.")
+
+	return imageSaveCmd
+}
+
+func newImageLoadCmd() *cobra.Command {
+	var runtime string
+
+	imageLoadCmd := &cobra.Command{
+		Use:   "load [path]",
+		Short: "Load the images saved by the save command.",
+		Long: `Load the images in the tarball saved by "sd-local image save".
+The builds use the loaded images when they fail to pull the images, such as without network access.
+The path defaults to ` + defaultBundleFile + ` in the current directory.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			path := defaultBundleFile
+			if len(args) == 1 {
+				path = args[0]
+			}
+
+			if runtime == "" {
+				home, err := homedir.Dir()
+				if err != nil {
+					return err
+				}
+				c, err := configNew(filepath.Join(home, ".sdlocal", "config"))
+				if err != nil {
+					return err
+				}
+				current, err := c.Entry(c.Current)
+				if err != nil {
+					return err
+				}
+				runtime = current.Runtime
+			}
+
+			out, err := loadImages(path, launch.BundleOption{Runtime: runtime, FlagVerbose: flagVerbose})
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), out)
+			return nil
+		},
+	}
+
+	imageLoadCmd.Flags().StringVar(
+		&runtime,
+		"runtime",
+		"",
+		"Container runtime to load the images, docker, podman or nerdctl. Defaults to the runtime of the current config.")
+
+	return imageLoadCmd
+}
+
+func newImageCmd() *cobra.Command {
+	imageCmd := &cobra.Command{
+		Use:   "image",
+		Short: "Save and load the images used by the builds.",
+		Long: `Save the images used by the builds into a tarball, and load it on the machines without network access,
+such as air-gapped machines.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := cmd.Help()
+			if err != nil {
+				return err
+			}
+			return nil
+		},
+	}
+
+	imageCmd.AddCommand(
+		newImageSaveCmd(),
+		newImageLoadCmd(),
+	)
+
+	return imageCmd
+}
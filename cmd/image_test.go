@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/screwdriver-cd/sd-local/config"
+	"github.com/screwdriver-cd/sd-local/launch"
+	"github.com/screwdriver-cd/sd-local/screwdriver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBundleImages(t *testing.T) {
+	jobs := map[string]screwdriver.Job{
+		"main":    {Image: "node:18"},
+		"test":    {Image: "node:18"},
+		"publish": {Image: "alpine"},
+	}
+	launcher := config.Launcher{Image: "screwdrivercd/launcher", Version: "stable"}
+
+	testCases := []struct {
+		name          string
+		entry         config.Entry
+		projectImages map[string]string
+		expect        []string
+	}{
+		{
+			name:   "launcher and jobs",
+			entry:  config.Entry{Launcher: launcher},
+			expect: []string{"screwdrivercd/launcher:stable", "node:18", "alpine"},
+		},
+		{
+			name:          "project images",
+			entry:         config.Entry{Launcher: launcher},
+			projectImages: map[string]string{"publish": "node:18", "test": "node:20"},
+			expect:        []string{"screwdrivercd/launcher:stable", "node:18", "node:20"},
+		},
+		{
+			name:          "Dockerfile",
+			entry:         config.Entry{Launcher: launcher},
+			projectImages: map[string]string{"publish": "/repo/Dockerfile"},
+			expect:        []string{"screwdrivercd/launcher:stable", "node:18"},
+		},
+		{
+			name:   "registry mirror",
+			entry:  config.Entry{Launcher: launcher, RegistryMirror: "mirror.example.com"},
+			expect: []string{"mirror.example.com/screwdrivercd/launcher:stable", "mirror.example.com/library/node:18", "mirror.example.com/library/alpine"},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			images := bundleImages(tt.entry, jobs, tt.projectImages)
+			assert.Equal(t, tt.expect[0], images[0])
+			assert.ElementsMatch(t, tt.expect, images)
+		})
+	}
+}
+
+func TestImageCmd(t *testing.T) {
+	defer func() {
+		saveImages = launch.SaveImages
+		loadImages = launch.LoadImages
+	}()
+
+	origLocalAPINew := localAPINew
+	t.Cleanup(func() { localAPINew = origLocalAPINew })
+	localAPINew = func(templateDir string) screwdriver.API { return mockAPI{} }
+
+	t.Run("save", func(t *testing.T) {
+		var savedPath string
+		var savedImages []string
+		saveImages = func(path string, images []string, option launch.BundleOption) error {
+			savedPath, savedImages = path, images
+			return nil
+		}
+
+		cmd := newImageSaveCmd()
+		cmd.SetArgs([]string{"-o", "images.tar", "--offline"})
+		buf := bytes.NewBuffer(nil)
+		cmd.SetOut(buf)
+
+		err := cmd.Execute()
+		assert.Nil(t, err)
+		assert.Equal(t, "images.tar", savedPath)
+		assert.Equal(t, []string{"screwdrivercd/launcher:stable"}, savedImages)
+		assert.Equal(t, "Saved 1 images into images.tar\n  screwdrivercd/launcher:stable\n", buf.String())
+	})
+
+	t.Run("failure by save", func(t *testing.T) {
+		saveImages = func(path string, images []string, option launch.BundleOption) error {
+			return errors.New("failed to save images: no space left on device")
+		}
+
+		cmd := newImageSaveCmd()
+		cmd.SetArgs([]string{"--offline"})
+		cmd.SetOut(bytes.NewBuffer(nil))
+
+		err := cmd.Execute()
+		assert.Equal(t, "failed to save images: no space left on device", err.Error())
+	})
+
+	t.Run("load", func(t *testing.T) {
+		var loadedPath, runtime string
+		loadImages = func(path string, option launch.BundleOption) (string, error) {
+			loadedPath, runtime = path, option.Runtime
+			return "Loaded image: screwdrivercd/launcher:stable", nil
+		}
+
+		cmd := newImageLoadCmd()
+		cmd.SetArgs([]string{"--runtime", "podman"})
+		buf := bytes.NewBuffer(nil)
+		cmd.SetOut(buf)
+
+		err := cmd.Execute()
+		assert.Nil(t, err)
+		assert.Equal(t, defaultBundleFile, loadedPath)
+		assert.Equal(t, "podman", runtime)
+		assert.Equal(t, "Loaded image: screwdrivercd/launcher:stable\n", buf.String())
+	})
+}
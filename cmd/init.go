@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/screwdriver-cd/sd-local/config"
+	"github.com/screwdriver-cd/sd-local/screwdriver"
+	"github.com/spf13/cobra"
+)
+
+// starter is the job generated by sd-local init for the language, which is detected when any of the files exists in the directory.
+type starter struct {
+	language string
+	files    []string
+	image    string
+	steps    []screwdriver.Step
+}
+
+// starters are the languages which sd-local init detects in this order. The last one is used when none of them are detected.
+var starters = []starter{
+	{"node", []string{"package.json"}, "node:18", []screwdriver.Step{{Name: "install", Command: "npm install"}, {Name: "test", Command: "npm test"}}},
+	{"go", []string{"go.mod"}, "golang:1.21", []screwdriver.Step{{Name: "vet", Command: "go vet ./..."}, {Name: "test", Command: "go test ./..."}}},
+	{"python", []string{"requirements.txt", "pyproject.toml", "setup.py"}, "python:3.11", []screwdriver.Step{{Name: "install", Command: "pip install -r requirements.txt"}, {Name: "test", Command: "python -m pytest"}}},
+	{"maven", []string{"pom.xml"}, "maven:3-eclipse-temurin-17", []screwdriver.Step{{Name: "test", Command: "mvn -B test"}}},
+	{"gradle", []string{"build.gradle", "build.gradle.kts"}, "gradle:jdk17", []screwdriver.Step{{Name: "test", Command: "gradle test"}}},
+	{"ruby", []string{"Gemfile"}, "ruby:3.2", []screwdriver.Step{{Name: "install", Command: "bundle install"}, {Name: "test", Command: "bundle exec rake"}}},
+	{"other", nil, "alpine", []screwdriver.Step{{Name: "hello", Command: "echo hello"}}},
+}
+
+// detectStarter returns the starter of the language of the project in dir.
+func detectStarter(dir string) starter {
+	for _, s := range starters {
+		for _, f := range s.files {
+			if _, err := os.Stat(filepath.Join(dir, f)); err == nil {
+				return s
+			}
+		}
+	}
+	return starters[len(starters)-1]
+}
+
+func starterLanguages() string {
+	names := make([]string, 0, len(starters))
+	for _, s := range starters {
+		names = append(names, s.language)
+	}
+	return strings.Join(names, ", ")
+}
+
+func findStarter(language string) (starter, error) {
+	for _, s := range starters {
+		if s.language == language {
+			return s, nil
+		}
+	}
+	return starter{}, fmt.Errorf("language must be one of %s", starterLanguages())
+}
+
+// starterYAML returns screwdriver.yaml of the job which is triggered by commits and pull requests.
+func starterYAML(jobName, image string, steps []screwdriver.Step) string {
+	b := new(strings.Builder)
+	fmt.Fprintf(b, "shared:\n    image: %s\n\njobs:\n    %s:\n        requires: [~commit, ~pr]\n        steps:\n", image, jobName)
+	for _, s := range steps {
+		fmt.Fprintf(b, "            - %s: %s\n", s.Name, s.Command)
+	}
+	return b.String()
+}
+
+// starterProject returns .sd-local.yaml which runs the job with "sd-local build" without the job name.
+func starterProject(jobName string) string {
+	return fmt.Sprintf("# Settings of sd-local for this repository\njob: %s\n", jobName)
+}
+
+// ask prints the question and returns the answer read from r, which defaults to def when it is empty.
+// The default is answered without reading r when yes is set.
+func ask(r *bufio.Reader, out io.Writer, question, def string, yes bool) (string, error) {
+	if yes {
+		return def, nil
+	}
+
+	fmt.Fprintf(out, "%s [%s]: ", question, def)
+	input, err := r.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+
+	if answer := strings.TrimSpace(input); answer != "" {
+		return answer, nil
+	}
+	return def, nil
+}
+
+// initProject generates screwdriver.yaml and .sd-local.yaml in dir with the answers read from in.
+// The existing files are not overwritten unless force is set.
+func initProject(dir string, in io.Reader, out io.Writer, yes, force bool) error {
+	files := []string{"screwdriver.yaml", config.ProjectFileName}
+	if !force {
+		for _, f := range files {
+			if _, err := os.Stat(filepath.Join(dir, f)); err == nil {
+				return fmt.Errorf("%s already exists, use --force to overwrite it", f)
+			}
+		}
+	}
+
+	r := bufio.NewReader(in)
+	detected := detectStarter(dir)
+
+	language, err := ask(r, out, fmt.Sprintf("Language (%s)", starterLanguages()), detected.language, yes)
+	if err != nil {
+		return err
+	}
+	s, err := findStarter(language)
+	if err != nil {
+		return err
+	}
+
+	jobName, err := ask(r, out, "Job name", "main", yes)
+	if err != nil {
+		return err
+	}
+	if strings.ContainsAny(jobName, " ,:") {
+		return errors.New("job name must not contain spaces, commas or colons")
+	}
+
+	image, err := ask(r, out, "Image", s.image, yes)
+	if err != nil {
+		return err
+	}
+
+	contents := []string{starterYAML(jobName, image, s.steps), starterProject(jobName)}
+	for i, f := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, f), []byte(contents[i]), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %v", f, err)
+		}
+		fmt.Fprintf(out, "Created %s\n", f)
+	}
+
+	fmt.Fprintf(out, "Run \"sd-local build\" to run the job %s locally.\n", jobName)
+	return nil
+}
+
+func newInitCmd() *cobra.Command {
+	var yes bool
+	var force bool
+
+	initCmd := &cobra.Command{
+		Use:   "init",
+		Short: "Generate screwdriver.yaml and .sd-local.yaml.",
+		Long: `Generate a starter screwdriver.yaml and .sd-local.yaml in the current directory.
+The language of the project is detected from its files such as package.json and go.mod,
+and the job of the language, its name and its image can be changed interactively.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			cwd, err := os.Getwd()
+			if err != nil {
+				return err
+			}
+
+			return initProject(cwd, stdin, cmd.OutOrStdout(), yes, force)
+		},
+	}
+
+	initCmd.Flags().BoolVarP(
+		&yes,
+		"yes",
+		"y",
+		false,
+		"Generate the files with the detected language and the default answers without asking.")
+
+	initCmd.Flags().BoolVar(
+		&force,
+		"force",
+		false,
+		"Overwrite screwdriver.yaml and .sd-local.yaml if they exist.")
+
+	return initCmd
+}
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectStarter(t *testing.T) {
+	testCases := []struct {
+		name   string
+		files  []string
+		expect string
+	}{
+		{"node", []string{"package.json", "README.md"}, "node"},
+		{"go", []string{"go.mod", "go.sum"}, "go"},
+		{"python", []string{"pyproject.toml"}, "python"},
+		{"gradle", []string{"build.gradle.kts"}, "gradle"},
+		{"other", []string{"README.md"}, "other"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "init")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+
+			for _, f := range tt.files {
+				if err := ioutil.WriteFile(filepath.Join(dir, f), nil, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			assert.Equal(t, tt.expect, detectStarter(dir).language)
+		})
+	}
+}
+
+func TestInitProject(t *testing.T) {
+	newDir := func(t *testing.T, files ...string) string {
+		dir, err := ioutil.TempDir("", "init")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, f := range files {
+			if err := ioutil.WriteFile(filepath.Join(dir, f), []byte("{}"), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return dir
+	}
+
+	t.Run("success with defaults", func(t *testing.T) {
+		dir := newDir(t, "package.json")
+		defer os.RemoveAll(dir)
+
+		buf := bytes.NewBuffer(nil)
+		err := initProject(dir, strings.NewReader("\n\n\n"), buf, false, false)
+		assert.Nil(t, err)
+		assert.Equal(t, "Language (node, go, python, maven, gradle, ruby, other) [node]: Job name [main]: Image [node:18]: "+
+			"Created screwdriver.yaml\nCreated .sd-local.yaml\nRun \"sd-local build\" to run the job main locally.\n", buf.String())
+
+		sdYAML, err := ioutil.ReadFile(filepath.Join(dir, "screwdriver.yaml"))
+		assert.Nil(t, err)
+		assert.Equal(t, `shared:
+    image: node:18
+
+jobs:
+    main:
+        requires: [~commit, ~pr]
+        steps:
+            - install: npm install
+            - test: npm test
+`, string(sdYAML))
+
+		project, err := ioutil.ReadFile(filepath.Join(dir, ".sd-local.yaml"))
+		assert.Nil(t, err)
+		assert.Equal(t, "# Settings of sd-local for this repository\njob: main\n", string(project))
+	})
+
+	t.Run("success with answers", func(t *testing.T) {
+		dir := newDir(t, "package.json")
+		defer os.RemoveAll(dir)
+
+		err := initProject(dir, strings.NewReader("go\ntest\ngolang:1.20\n"), bytes.NewBuffer(nil), false, false)
+		assert.Nil(t, err)
+
+		sdYAML, err := ioutil.ReadFile(filepath.Join(dir, "screwdriver.yaml"))
+		assert.Nil(t, err)
+		assert.Contains(t, string(sdYAML), "    image: golang:1.20\n")
+		assert.Contains(t, string(sdYAML), "    test:\n")
+		assert.Contains(t, string(sdYAML), "            - test: go test ./...\n")
+	})
+
+	t.Run("success with yes", func(t *testing.T) {
+		dir := newDir(t, "go.mod")
+		defer os.RemoveAll(dir)
+
+		buf := bytes.NewBuffer(nil)
+		err := initProject(dir, strings.NewReader(""), buf, true, false)
+		assert.Nil(t, err)
+		assert.Equal(t, "Created screwdriver.yaml\nCreated .sd-local.yaml\nRun \"sd-local build\" to run the job main locally.\n", buf.String())
+	})
+
+	t.Run("success with force", func(t *testing.T) {
+		dir := newDir(t, "screwdriver.yaml")
+		defer os.RemoveAll(dir)
+
+		err := initProject(dir, strings.NewReader(""), bytes.NewBuffer(nil), true, true)
+		assert.Nil(t, err)
+
+		sdYAML, err := ioutil.ReadFile(filepath.Join(dir, "screwdriver.yaml"))
+		assert.Nil(t, err)
+		assert.Contains(t, string(sdYAML), "    image: alpine\n")
+	})
+
+	t.Run("failure by existing file", func(t *testing.T) {
+		dir := newDir(t, ".sd-local.yaml")
+		defer os.RemoveAll(dir)
+
+		err := initProject(dir, strings.NewReader(""), bytes.NewBuffer(nil), true, false)
+		assert.Equal(t, ".sd-local.yaml already exists, use --force to overwrite it", err.Error())
+	})
+
+	t.Run("failure by unknown language", func(t *testing.T) {
+		dir := newDir(t)
+		defer os.RemoveAll(dir)
+
+		err := initProject(dir, strings.NewReader("rust\n"), bytes.NewBuffer(nil), false, false)
+		assert.Equal(t, "language must be one of node, go, python, maven, gradle, ruby, other", err.Error())
+	})
+
+	t.Run("failure by invalid job name", func(t *testing.T) {
+		dir := newDir(t)
+		defer os.RemoveAll(dir)
+
+		err := initProject(dir, strings.NewReader("\nmain,test\n"), bytes.NewBuffer(nil), false, false)
+		assert.Equal(t, "job name must not contain spaces, commas or colons", err.Error())
+	})
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/screwdriver-cd/sd-local/screwdriver"
+	"github.com/spf13/cobra"
+)
+
+// printJobs prints the jobs in the order of the workflow with their images and requires, which are the edges of the workflow.
+func printJobs(out io.Writer, jobs map[string]screwdriver.Job) error {
+	order, err := screwdriver.WorkflowOrder(jobs)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "JOB\tIMAGE\tREQUIRES")
+	for _, name := range order {
+		requires := "-"
+		if r := jobs[name].Requires; len(r) != 0 {
+			requires = strings.Join(r, ",")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", name, jobs[name].Image, requires)
+	}
+	return tw.Flush()
+}
+
+func newJobsCmd() *cobra.Command {
+	var offline bool
+
+	jobsCmd := &cobra.Command{
+		Use:   "jobs [path]",
+		Short: "List the jobs in screwdriver.yaml.",
+		Long: `Validate screwdriver.yaml and list its jobs in the order of the workflow,
+with their images and the jobs or the events which trigger them.
+The path of screwdriver.yaml defaults to the one in the current directory.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			sdYAMLPath, err := screwdriverYAMLPath(args)
+			if err != nil {
+				return err
+			}
+
+			api, _, err := validatorAPI(offline)
+			if err != nil {
+				return err
+			}
+
+			jobs, err := api.Jobs(sdYAMLPath)
+			if err != nil {
+				return err
+			}
+
+			return printJobs(cmd.OutOrStdout(), jobs)
+		},
+	}
+
+	jobsCmd.Flags().BoolVar(
+		&offline,
+		"offline",
+		false,
+		"Validate screwdriver.yaml locally without calling Screwdriver.cd API. Only the templates cached by the previous builds can be used in offline mode.")
+
+	return jobsCmd
+}
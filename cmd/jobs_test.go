@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/screwdriver-cd/sd-local/screwdriver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrintJobs(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		buf := bytes.NewBuffer(nil)
+		err := printJobs(buf, map[string]screwdriver.Job{
+			"main":    {Image: "node:18", Requires: []string{"~commit", "~pr"}},
+			"test":    {Image: "node:18", Requires: []string{"main"}},
+			"publish": {Image: "alpine", Requires: []string{"test"}},
+			"cleanup": {Image: "alpine"},
+		})
+
+		assert.Nil(t, err)
+		assert.Equal(t, `JOB      IMAGE    REQUIRES
+cleanup  alpine   -
+main     node:18  ~commit,~pr
+test     node:18  main
+publish  alpine   test
+`, buf.String())
+	})
+
+	t.Run("failure by cycle", func(t *testing.T) {
+		err := printJobs(bytes.NewBuffer(nil), map[string]screwdriver.Job{
+			"main": {Requires: []string{"test"}},
+			"test": {Requires: []string{"main"}},
+		})
+		assert.Equal(t, "workflow has a cycle in jobs: [main test]", err.Error())
+	})
+}
+
+func TestJobsCmd(t *testing.T) {
+	origLocalAPINew := localAPINew
+	t.Cleanup(func() { localAPINew = origLocalAPINew })
+	localAPINew = func(templateDir string) screwdriver.API { return mockAPI{} }
+
+	cmd := newJobsCmd()
+	cmd.SetArgs([]string{"--offline"})
+	buf := bytes.NewBuffer(nil)
+	cmd.SetOut(buf)
+
+	err := cmd.Execute()
+	assert.Nil(t, err)
+	assert.Equal(t, "JOB      IMAGE  REQUIRES\nmain            ~commit\ntest            main\npublish         test\n", buf.String())
+}
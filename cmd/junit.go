@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/screwdriver-cd/sd-local/launch"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	readJUnitReports = launch.ReadJUnitReports
+	writeJUnitReport = launch.WriteJUnitReport
+)
+
+// printJUnitSummary prints the results of the test suites in the JUnit XML reports written by the build,
+// and consolidates them into a report in the artifacts directory.
+func printJUnitSummary(out io.Writer, artifactsPath, pattern string, since time.Time) {
+	if pattern == "" {
+		return
+	}
+
+	suites, err := readJUnitReports(artifactsPath, pattern, since)
+	if err != nil {
+		logrus.Warn(err)
+		return
+	}
+	if len(suites) == 0 {
+		return
+	}
+
+	reportPath := filepath.Join(artifactsPath, launch.JUnitReportFile)
+	if err := writeJUnitReport(reportPath, suites); err != nil {
+		logrus.Warn(err)
+		reportPath = ""
+	}
+
+	fmt.Fprintln(out, "\nTest reports")
+	tw := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "SUITE\tTESTS\tFAILURES\tERRORS\tSKIPPED\tRESULT\tFILE")
+	var total launch.JUnitTestSuite
+	for _, s := range suites {
+		result := "PASS"
+		if !s.Passed() {
+			result = "FAIL"
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%s\t%s\n", s.Name, s.Tests, s.Failures, s.Errors, s.Skipped, result, s.File)
+		total.Tests += s.Tests
+		total.Failures += s.Failures
+		total.Errors += s.Errors
+		total.Skipped += s.Skipped
+	}
+	result := "PASS"
+	if !total.Passed() {
+		result = "FAIL"
+	}
+	fmt.Fprintf(tw, "TOTAL\t%d\t%d\t%d\t%d\t%s\t\n", total.Tests, total.Failures, total.Errors, total.Skipped, result)
+	tw.Flush()
+
+	if reportPath != "" {
+		fmt.Fprintf(out, "Consolidated report is written to %s\n", reportPath)
+	}
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/screwdriver-cd/sd-local/launch"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrintJUnitSummary(t *testing.T) {
+	defer func() {
+		readJUnitReports = launch.ReadJUnitReports
+		writeJUnitReport = launch.WriteJUnitReport
+	}()
+
+	since := time.Date(2020, 1, 10, 0, 0, 0, 0, time.UTC)
+	suites := []launch.JUnitTestSuite{
+		{Name: "api", Tests: 2, Failures: 1, File: "reports/junit.xml"},
+		{Name: "ui", Tests: 3, Skipped: 1, File: "reports/TEST-ui.xml"},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		readJUnitReports = func(artifactsPath, pattern string, s time.Time) ([]launch.JUnitTestSuite, error) {
+			assert.Equal(t, "/sd-artifacts", artifactsPath)
+			assert.Equal(t, "*.xml", pattern)
+			assert.Equal(t, since, s)
+			return suites, nil
+		}
+		var written []launch.JUnitTestSuite
+		writeJUnitReport = func(filePath string, s []launch.JUnitTestSuite) error {
+			assert.Equal(t, "/sd-artifacts/junit-report.xml", filePath)
+			written = s
+			return nil
+		}
+
+		buf := bytes.NewBuffer(nil)
+		printJUnitSummary(buf, "/sd-artifacts", "*.xml", since)
+		want := "\nTest reports\n" +
+			"SUITE  TESTS  FAILURES  ERRORS  SKIPPED  RESULT  FILE\n" +
+			"api    2      1         0       0        FAIL    reports/junit.xml\n" +
+			"ui     3      0         0       1        PASS    reports/TEST-ui.xml\n" +
+			"TOTAL  5      1         0       1        FAIL    \n" +
+			"Consolidated report is written to /sd-artifacts/junit-report.xml\n"
+		assert.Equal(t, want, buf.String())
+		assert.Equal(t, suites, written)
+	})
+
+	t.Run("success without pattern", func(t *testing.T) {
+		readJUnitReports = func(artifactsPath, pattern string, s time.Time) ([]launch.JUnitTestSuite, error) {
+			t.Fatal("JUnit reports must not be read without pattern")
+			return nil, nil
+		}
+
+		buf := bytes.NewBuffer(nil)
+		printJUnitSummary(buf, "/sd-artifacts", "", since)
+		assert.Equal(t, "", buf.String())
+	})
+
+	t.Run("success without reports", func(t *testing.T) {
+		readJUnitReports = func(artifactsPath, pattern string, s time.Time) ([]launch.JUnitTestSuite, error) { return nil, nil }
+		writeJUnitReport = func(filePath string, s []launch.JUnitTestSuite) error {
+			t.Fatal("JUnit report must not be written without reports")
+			return nil
+		}
+
+		buf := bytes.NewBuffer(nil)
+		printJUnitSummary(buf, "/sd-artifacts", "*.xml", since)
+		assert.Equal(t, "", buf.String())
+	})
+
+	t.Run("success with failure by writing report", func(t *testing.T) {
+		readJUnitReports = func(artifactsPath, pattern string, s time.Time) ([]launch.JUnitTestSuite, error) {
+			return suites[1:], nil
+		}
+		writeJUnitReport = func(filePath string, s []launch.JUnitTestSuite) error {
+			return errors.New("failed to write JUnit report")
+		}
+
+		buf := bytes.NewBuffer(nil)
+		printJUnitSummary(buf, "/sd-artifacts", "*.xml", since)
+		want := "\nTest reports\n" +
+			"SUITE  TESTS  FAILURES  ERRORS  SKIPPED  RESULT  FILE\n" +
+			"ui     3      0         0       1        PASS    reports/TEST-ui.xml\n" +
+			"TOTAL  3      0         0       1        PASS    \n"
+		assert.Equal(t, want, buf.String())
+	})
+
+	t.Run("failure by reading reports", func(t *testing.T) {
+		readJUnitReports = func(artifactsPath, pattern string, s time.Time) ([]launch.JUnitTestSuite, error) {
+			return nil, errors.New("failed to read JUnit report")
+		}
+
+		buf := bytes.NewBuffer(nil)
+		printJUnitSummary(buf, "/sd-artifacts", "*.xml", since)
+		assert.Equal(t, "", buf.String())
+	})
+}
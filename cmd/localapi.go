@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	goruntime "runtime"
+
+	"github.com/screwdriver-cd/sd-local/launch"
+	"github.com/screwdriver-cd/sd-local/localapi"
+)
+
+// localAPIHost is the host name of the machine running sd-local in the build container
+const localAPIHost = "host.docker.internal"
+
+var (
+	localapiNew = localapi.New
+	// localAPIAddr listens on all the interfaces, because the container reaches the server through the gateway of its network.
+	// The requests without the token issued for the build are rejected.
+	localAPIAddr = ":0"
+	goos         = goruntime.GOOS
+)
+
+func newLocalAPIToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to issue token of local API: %v", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// startLocalAPI starts the stub of Screwdriver.cd API and the store serving the meta, the caches and the artifacts of the build,
+// and points $SD_API_URL, $SD_STORE_URL and $SD_TOKEN of the build at it. The returned function stops the stub.
+func startLocalAPI(option *launch.Option) (func(), error) {
+	token, err := newLocalAPIToken()
+	if err != nil {
+		return nil, err
+	}
+
+	jobCacheDir := ""
+	if option.Cache.JobDir != "" {
+		jobCacheDir = filepath.Join(option.Cache.JobDir, option.JobName)
+	}
+
+	server := localapiNew(localapi.Option{
+		Token:         token,
+		MetaPath:      option.MetaPath,
+		ArtifactsPath: option.ArtifactsPath,
+		CacheDirs: map[string]string{
+			"pipelines": option.Cache.PipelineDir,
+			"events":    option.Cache.EventDir,
+			"jobs":      jobCacheDir,
+		},
+	})
+	if err := server.Start(localAPIAddr); err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("http://%s:%d", localAPIHost, server.Port())
+	option.Entry.APIURL = url
+	option.Entry.StoreURL = url
+	option.JWT = token
+	// Docker Desktop resolves the host name by itself, but Docker Engine on Linux needs it to be added
+	// The hosts of --add-host are copied, because they are shared by the jobs run in parallel
+	if goos == "linux" {
+		hosts := make([]string, 0, len(option.ExtraHosts)+1)
+		option.ExtraHosts = append(append(hosts, option.ExtraHosts...), localAPIHost+":host-gateway")
+	}
+
+	return func() { server.Close() }, nil
+}
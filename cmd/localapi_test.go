@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/screwdriver-cd/sd-local/launch"
+	"github.com/screwdriver-cd/sd-local/localapi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStartLocalAPI(t *testing.T) {
+	defaultGOOS := goos
+	defer func() {
+		localAPIAddr = ":0"
+		goos = defaultGOOS
+	}()
+
+	testCases := []struct {
+		name       string
+		goos       string
+		extraHosts []string
+	}{
+		{name: "success on linux", goos: "linux", extraHosts: []string{"host.docker.internal:host-gateway"}},
+		{name: "success on darwin", goos: "darwin"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			localAPIAddr = "127.0.0.1:0"
+			goos = tt.goos
+
+			option := launch.Option{
+				JobName:       "main",
+				MetaPath:      "/sdlocal/meta",
+				ArtifactsPath: "/sdlocal/artifacts",
+				Cache:         launch.CacheOption{PipelineDir: "/sdlocal/cache/pipeline", EventDir: "/sdlocal/cache/event"},
+			}
+			stop, err := startLocalAPI(&option)
+			assert.Nil(t, err)
+			defer stop()
+
+			assert.Regexp(t, `^http://host\.docker\.internal:\d+$`, option.Entry.APIURL)
+			assert.Equal(t, option.Entry.APIURL, option.Entry.StoreURL)
+			assert.Len(t, option.JWT, 32)
+			assert.Equal(t, tt.extraHosts, option.ExtraHosts)
+		})
+	}
+}
+
+func TestStartLocalAPIServing(t *testing.T) {
+	defer func() {
+		localapiNew = localapi.New
+		localAPIAddr = ":0"
+	}()
+
+	var server *localapi.Server
+	localapiNew = func(o localapi.Option) *localapi.Server {
+		assert.Equal(t, map[string]string{"pipelines": "/p", "events": "/e", "jobs": "/j/main"}, o.CacheDirs)
+		server = localapi.New(o)
+		return server
+	}
+	localAPIAddr = "127.0.0.1:0"
+
+	option := launch.Option{JobName: "main", Cache: launch.CacheOption{PipelineDir: "/p", EventDir: "/e", JobDir: "/j"}}
+	stop, err := startLocalAPI(&option)
+	assert.Nil(t, err)
+
+	res, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d/v4/builds/1", server.Port()))
+	assert.Nil(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+	stop()
+	_, err = http.Get(fmt.Sprintf("http://127.0.0.1:%d/v4/builds/1", server.Port()))
+	assert.NotNil(t, err)
+}
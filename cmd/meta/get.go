@@ -0,0 +1,38 @@
+package meta
+
+import (
+	"fmt"
+
+	"github.com/screwdriver-cd/sd-local/launch"
+	"github.com/spf13/cobra"
+)
+
+func newMetaGetCmd() *cobra.Command {
+	metaGetCmd := &cobra.Command{
+		Use:   "get [key]",
+		Short: "Get the value of metadata.",
+		Long: `Get the value of metadata.
+Nested values can be specified with dot separated keys (e.g. foo.bar).
+Strings are printed as is and the others are printed in JSON format.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			meta, err := launch.ReadMeta(metaPath())
+			if err != nil {
+				return err
+			}
+
+			value, ok := meta.Get(args[0])
+			if !ok {
+				return fmt.Errorf("not found '%s' in meta", args[0])
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), launch.FormatMetaValue(value))
+
+			return nil
+		},
+	}
+
+	return metaGetCmd
+}
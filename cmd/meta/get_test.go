@@ -0,0 +1,64 @@
+package meta
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetaGetCmd(t *testing.T) {
+	testCase := []struct {
+		name     string
+		args     []string
+		wantOut  string
+		checkErr bool
+	}{
+		{
+			name:     "success",
+			args:     []string{"get", "foo", "--artifacts-dir", "./testdata/sd-artifacts"},
+			wantOut:  "bar\n",
+			checkErr: false,
+		},
+		{
+			name:     "success with nested key",
+			args:     []string{"get", "nested", "--artifacts-dir", "./testdata/sd-artifacts"},
+			wantOut:  "{\"num\":1}\n",
+			checkErr: false,
+		},
+		{
+			name:     "success with job",
+			args:     []string{"get", "status", "--artifacts-dir", "./testdata/sd-artifacts", "--job", "test"},
+			wantOut:  "tested\n",
+			checkErr: false,
+		},
+		{
+			name:     "failure by unknown key",
+			args:     []string{"get", "none", "--artifacts-dir", "./testdata/sd-artifacts"},
+			wantOut:  "",
+			checkErr: true,
+		},
+		{
+			name:     "failure by too little args",
+			args:     []string{"get"},
+			wantOut:  "",
+			checkErr: true,
+		},
+	}
+
+	for _, tt := range testCase {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := NewMetaCmd()
+			cmd.SetArgs(tt.args)
+			buf := bytes.NewBuffer(nil)
+			cmd.SetOut(buf)
+			err := cmd.Execute()
+			if tt.checkErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.wantOut, buf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package meta
+
+import (
+	"fmt"
+
+	"github.com/screwdriver-cd/sd-local/launch"
+	"github.com/spf13/cobra"
+)
+
+func newMetaListCmd() *cobra.Command {
+	metaListCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List all metadata.",
+		Long: `List all metadata as key=value.
+Nested values are listed with dot separated keys.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			meta, err := launch.ReadMeta(metaPath())
+			if err != nil {
+				return err
+			}
+
+			for _, line := range meta.Flatten() {
+				fmt.Fprintln(cmd.OutOrStdout(), line)
+			}
+
+			return nil
+		},
+	}
+
+	return metaListCmd
+}
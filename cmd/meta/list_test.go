@@ -0,0 +1,52 @@
+package meta
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetaListCmd(t *testing.T) {
+	testCase := []struct {
+		name     string
+		args     []string
+		wantOut  string
+		checkErr bool
+	}{
+		{
+			name:     "success",
+			args:     []string{"list", "--artifacts-dir", "./testdata/sd-artifacts"},
+			wantOut:  "foo=bar\nnested.num=1\n",
+			checkErr: false,
+		},
+		{
+			name:     "success without meta",
+			args:     []string{"list", "--artifacts-dir", "./testdata/none"},
+			wantOut:  "",
+			checkErr: false,
+		},
+		{
+			name:     "failure by too many args",
+			args:     []string{"list", "foo"},
+			wantOut:  "",
+			checkErr: true,
+		},
+	}
+
+	for _, tt := range testCase {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := NewMetaCmd()
+			cmd.SetArgs(tt.args)
+			buf := bytes.NewBuffer(nil)
+			cmd.SetOut(buf)
+			err := cmd.Execute()
+			if tt.checkErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.wantOut, buf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package meta
+
+import (
+	"path/filepath"
+
+	"github.com/screwdriver-cd/sd-local/launch"
+	"github.com/spf13/cobra"
+)
+
+var (
+	artifactsDir = launch.ArtifactsDir
+	jobName      = ""
+)
+
+// metaPath returns the directory of the meta store.
+// The meta of a job run with multiple jobs is stored under the sub directory named after the job.
+func metaPath() string {
+	if jobName != "" {
+		return filepath.Join(artifactsDir, jobName, launch.MetaDir)
+	}
+	return filepath.Join(artifactsDir, launch.MetaDir)
+}
+
+// NewMetaCmd return meta command.
+func NewMetaCmd() *cobra.Command {
+	metaCmd := &cobra.Command{
+		Use:   "meta",
+		Short: "Manage metadata of local builds.",
+		Long: `Manage metadata of local builds.
+The metadata is stored in the meta directory under the artifacts directory,
+and is passed into the next build.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := cmd.Help()
+			if err != nil {
+				return err
+			}
+			return nil
+		},
+	}
+
+	metaCmd.PersistentFlags().StringVar(
+		&artifactsDir,
+		"artifacts-dir",
+		launch.ArtifactsDir,
+		"Path to the host side directory which is mounted into $SD_ARTIFACTS_DIR.")
+
+	metaCmd.PersistentFlags().StringVar(
+		&jobName,
+		"job",
+		"",
+		"Name of the job run with multiple jobs. Its metadata is stored separately.")
+
+	metaCmd.AddCommand(
+		newMetaGetCmd(),
+		newMetaSetCmd(),
+		newMetaListCmd(),
+	)
+
+	return metaCmd
+}
@@ -0,0 +1,52 @@
+package meta
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/screwdriver-cd/sd-local/launch"
+	"github.com/spf13/cobra"
+)
+
+func newMetaSetCmd() *cobra.Command {
+	var jsonValue bool
+
+	metaSetCmd := &cobra.Command{
+		Use:   "set [key] [value]",
+		Short: "Set the value of metadata.",
+		Long: `Set the value of metadata.
+Nested values can be specified with dot separated keys (e.g. foo.bar).
+The value is stored as a string unless --json-value is specified.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			key := args[0]
+			var value interface{} = args[1]
+			if jsonValue {
+				err := json.Unmarshal([]byte(args[1]), &value)
+				if err != nil {
+					return fmt.Errorf("failed to parse value %s, value must be formated with JSON: %v", args[1], err)
+				}
+			}
+
+			path := metaPath()
+			meta, err := launch.ReadMeta(path)
+			if err != nil {
+				return err
+			}
+
+			meta.Set(key, value)
+
+			return launch.WriteMeta(path, meta)
+		},
+	}
+
+	metaSetCmd.Flags().BoolVar(
+		&jsonValue,
+		"json-value",
+		false,
+		"Parse the value as JSON.")
+
+	return metaSetCmd
+}
@@ -0,0 +1,73 @@
+package meta
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/screwdriver-cd/sd-local/launch"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetaSetCmd(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sd-artifacts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	testCase := []struct {
+		name     string
+		args     []string
+		expect   launch.Meta
+		checkErr bool
+	}{
+		{
+			name:     "success",
+			args:     []string{"set", "foo", "bar", "--artifacts-dir", dir},
+			expect:   launch.Meta{"foo": "bar"},
+			checkErr: false,
+		},
+		{
+			name:     "success with nested key",
+			args:     []string{"set", "nested.num", "1", "--artifacts-dir", dir},
+			expect:   launch.Meta{"foo": "bar", "nested": map[string]interface{}{"num": "1"}},
+			checkErr: false,
+		},
+		{
+			name:     "success with json value",
+			args:     []string{"set", "nested.num", "1", "--json-value", "--artifacts-dir", dir},
+			expect:   launch.Meta{"foo": "bar", "nested": map[string]interface{}{"num": float64(1)}},
+			checkErr: false,
+		},
+		{
+			name:     "failure by invalid json value",
+			args:     []string{"set", "foo", "{", "--json-value", "--artifacts-dir", dir},
+			checkErr: true,
+		},
+		{
+			name:     "failure by too little args",
+			args:     []string{"set", "foo"},
+			checkErr: true,
+		},
+	}
+
+	for _, tt := range testCase {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := NewMetaCmd()
+			cmd.SetArgs(tt.args)
+			buf := bytes.NewBuffer(nil)
+			cmd.SetOut(buf)
+			err := cmd.Execute()
+			if tt.checkErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+				meta, err := launch.ReadMeta(metaPath())
+				assert.Nil(t, err)
+				assert.Equal(t, tt.expect, meta)
+			}
+		})
+	}
+}
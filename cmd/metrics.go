@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/screwdriver-cd/sd-local/config"
+	"github.com/screwdriver-cd/sd-local/launch"
+	"github.com/screwdriver-cd/sd-local/metrics"
+	"github.com/sirupsen/logrus"
+)
+
+var buildEmitters = emitters
+
+// emitters returns the emitters of the metrics configured in the config.
+func emitters(entry config.Entry) []metrics.Emitter {
+	var es []metrics.Emitter
+	if entry.MetricsPushgateway != "" {
+		instance, err := os.Hostname()
+		if err != nil {
+			instance = "unknown"
+		}
+		es = append(es, metrics.NewPushgateway(entry.MetricsPushgateway, instance))
+	}
+	if entry.MetricsStatsD != "" {
+		es = append(es, metrics.NewStatsD(entry.MetricsStatsD))
+	}
+	return es
+}
+
+// emitMetrics sends the metrics of the builds with the emitters configured in the config.
+// The failure of sending them is warned and does not fail the command.
+func emitMetrics(entry config.Entry, builds []launch.Result) {
+	if len(builds) == 0 {
+		return
+	}
+
+	for _, e := range buildEmitters(entry) {
+		if err := e.Emit(builds); err != nil {
+			logrus.Warnf("failed to emit the metrics of the build: %v", err)
+		}
+	}
+}
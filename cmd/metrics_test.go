@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/screwdriver-cd/sd-local/config"
+	"github.com/screwdriver-cd/sd-local/launch"
+	"github.com/screwdriver-cd/sd-local/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeEmitter struct {
+	builds [][]launch.Result
+}
+
+func (f *fakeEmitter) Emit(builds []launch.Result) error {
+	f.builds = append(f.builds, builds)
+	return errors.New("connection refused")
+}
+
+func TestEmitters(t *testing.T) {
+	assert.Len(t, emitters(config.Entry{}), 0)
+	assert.Len(t, emitters(config.Entry{MetricsPushgateway: "http://localhost:9091", MetricsStatsD: "localhost:8125"}), 2)
+}
+
+func TestEmitMetrics(t *testing.T) {
+	defer func() {
+		buildEmitters = emitters
+	}()
+
+	e := &fakeEmitter{}
+	buildEmitters = func(entry config.Entry) []metrics.Emitter {
+		return []metrics.Emitter{e}
+	}
+
+	builds := []launch.Result{
+		{Job: "main", Status: launch.StepFailure, Duration: 30, Steps: []launch.StepJSON{
+			{Name: "install", Status: launch.StepSuccess, Duration: 12.5},
+			{Name: "test", Status: launch.StepFailure, Duration: 8},
+		}},
+	}
+	emitMetrics(config.Entry{}, builds)
+	assert.Equal(t, [][]launch.Result{builds}, e.builds)
+
+	e.builds = nil
+	emitMetrics(config.Entry{}, []launch.Result{})
+	assert.Nil(t, e.builds)
+}
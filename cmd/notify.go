@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"github.com/screwdriver-cd/sd-local/config"
+	"github.com/screwdriver-cd/sd-local/launch"
+	"github.com/screwdriver-cd/sd-local/notify"
+	"github.com/sirupsen/logrus"
+)
+
+var buildNotifiers = notifiers
+
+// notifiers returns the notifiers enabled in the config.
+func notifiers(entry config.Entry) []notify.Notifier {
+	var ns []notify.Notifier
+	if entry.NotifyDesktop {
+		ns = append(ns, notify.NewDesktop())
+	}
+	if entry.SlackWebhook != "" {
+		ns = append(ns, notify.NewWebhook(notify.Slack, entry.SlackWebhook))
+	}
+	if entry.TeamsWebhook != "" {
+		ns = append(ns, notify.NewWebhook(notify.Teams, entry.TeamsWebhook))
+	}
+	if entry.DiscordWebhook != "" {
+		ns = append(ns, notify.NewWebhook(notify.Discord, entry.DiscordWebhook))
+	}
+	return ns
+}
+
+// notifyBuilds sends the results of the builds with the notifiers enabled in the config.
+// The failure of the notifications is warned and does not fail the command.
+func notifyBuilds(entry config.Entry, builds []launch.Result) {
+	if len(builds) == 0 {
+		return
+	}
+
+	for _, n := range buildNotifiers(entry) {
+		if err := n.Notify(builds); err != nil {
+			logrus.Warnf("failed to notify the result of the build: %v", err)
+		}
+	}
+}
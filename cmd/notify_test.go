@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/screwdriver-cd/sd-local/config"
+	"github.com/screwdriver-cd/sd-local/launch"
+	"github.com/screwdriver-cd/sd-local/notify"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeNotifier struct {
+	builds [][]launch.Result
+	err    error
+}
+
+func (f *fakeNotifier) Notify(builds []launch.Result) error {
+	f.builds = append(f.builds, builds)
+	return f.err
+}
+
+func TestNotifiers(t *testing.T) {
+	assert.Len(t, notifiers(config.Entry{}), 0)
+	assert.Len(t, notifiers(config.Entry{NotifyDesktop: true, SlackWebhook: "https://hooks.slack.com/services/xxx", DiscordWebhook: "https://discord.com/api/webhooks/xxx"}), 3)
+}
+
+func TestNotifyBuilds(t *testing.T) {
+	defer func() {
+		buildNotifiers = notifiers
+	}()
+
+	n := &fakeNotifier{err: errors.New("failed to post")}
+	buildNotifiers = func(entry config.Entry) []notify.Notifier {
+		return []notify.Notifier{n}
+	}
+
+	builds := []launch.Result{
+		{Job: "main", Status: launch.StepSuccess, Duration: 42.5},
+		{Job: "test", Status: launch.StepFailure, Duration: 10},
+	}
+	notifyBuilds(config.Entry{}, builds)
+	assert.Equal(t, [][]launch.Result{builds}, n.builds)
+
+	n.builds = nil
+	notifyBuilds(config.Entry{}, []launch.Result{})
+	assert.Nil(t, n.builds)
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/screwdriver-cd/sd-local/screwdriver"
+)
+
+// resolveParameters returns the values of the parameters defined in screwdriver.yaml.
+// The values passed with the param option (<name>=<value>) take precedence over the default values.
+func resolveParameters(defined map[string]screwdriver.Parameter, values []string) (map[string]string, error) {
+	parameters := make(map[string]string, len(defined))
+	for name, p := range defined {
+		parameters[name] = p.Value
+	}
+
+	for _, v := range values {
+		kv := strings.SplitN(v, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("failed to parse param %s, param must be formatted with <name>=<value>", v)
+		}
+		if _, ok := defined[kv[0]]; !ok {
+			return nil, fmt.Errorf("parameter %s is not defined in screwdriver.yaml", kv[0])
+		}
+		parameters[kv[0]] = kv[1]
+	}
+
+	return parameters, nil
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/screwdriver-cd/sd-local/screwdriver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveParameters(t *testing.T) {
+	defined := map[string]screwdriver.Parameter{
+		"region": {Value: "us-west-1"},
+		"az":     {Value: "zone-a", Description: "availability zone"},
+	}
+
+	testCases := []struct {
+		name   string
+		values []string
+		expect map[string]string
+		err    string
+	}{
+		{"default values", nil, map[string]string{"region": "us-west-1", "az": "zone-a"}, ""},
+		{"overridden values", []string{"region=ap-northeast-1", "az=zone=c"}, map[string]string{"region": "ap-northeast-1", "az": "zone=c"}, ""},
+		{"empty value", []string{"az="}, map[string]string{"region": "us-west-1", "az": ""}, ""},
+		{"invalid format", []string{"region"}, nil, "failed to parse param region, param must be formatted with <name>=<value>"},
+		{"undefined parameter", []string{"zone=zone-a"}, nil, "parameter zone is not defined in screwdriver.yaml"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			parameters, err := resolveParameters(defined, tt.values)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expect, parameters)
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/screwdriver-cd/sd-local/screwdriver"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// lastJobsFileName is the file in ~/.sdlocal which records the job picked last time in each repository
+const lastJobsFileName = "last-jobs.json"
+
+var stdinIsTerminal = func() bool {
+	return terminal.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// fuzzyMatch reports whether the characters of pattern appear in name in the same order, ignoring the case,
+// such as "uts" for "unit-test".
+func fuzzyMatch(pattern, name string) bool {
+	name = strings.ToLower(name)
+	for _, c := range strings.ToLower(pattern) {
+		i := strings.IndexRune(name, c)
+		if i == -1 {
+			return false
+		}
+		name = name[i+len(string(c)):]
+	}
+	return true
+}
+
+// pickJob lists the jobs in the order of the workflow and returns the job picked with its number or its name read from r.
+// Any other input narrows down the list to the jobs which match it fuzzily, and the job is picked when only one matches.
+// The empty input picks last, which is the job picked last time.
+func pickJob(r *bufio.Reader, out io.Writer, jobs map[string]screwdriver.Job, last string) (string, error) {
+	order, err := screwdriver.WorkflowOrder(jobs)
+	if err != nil {
+		return "", err
+	}
+	if len(order) == 0 {
+		return "", errors.New("no jobs in screwdriver.yaml")
+	}
+	if _, ok := jobs[last]; !ok {
+		last = ""
+	}
+
+	candidates := order
+	for {
+		for i, name := range candidates {
+			fmt.Fprintf(out, "%3d) %s\n", i+1, name)
+		}
+		if last != "" {
+			fmt.Fprintf(out, "Job (number or search) [%s]: ", last)
+		} else {
+			fmt.Fprint(out, "Job (number or search): ")
+		}
+
+		input, err := r.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return "", err
+		}
+		answer := strings.TrimSpace(input)
+
+		switch {
+		case answer == "" && err == io.EOF:
+			return "", errors.New("no job is picked")
+		case answer == "" && last != "":
+			return last, nil
+		case answer == "":
+			continue
+		}
+
+		if n, convErr := strconv.Atoi(answer); convErr == nil {
+			if n >= 1 && n <= len(candidates) {
+				return candidates[n-1], nil
+			}
+			fmt.Fprintf(out, "%d is out of the list\n", n)
+			continue
+		}
+		if _, ok := jobs[answer]; ok {
+			return answer, nil
+		}
+
+		var matched []string
+		for _, name := range order {
+			if fuzzyMatch(answer, name) {
+				matched = append(matched, name)
+			}
+		}
+		switch len(matched) {
+		case 0:
+			fmt.Fprintf(out, "No jobs match %q\n", answer)
+			candidates = order
+		case 1:
+			return matched[0], nil
+		default:
+			candidates = matched
+		}
+		if err == io.EOF {
+			return "", errors.New("no job is picked")
+		}
+	}
+}
+
+func readLastJobs(path string) map[string]string {
+	lastJobs := make(map[string]string)
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return lastJobs
+	}
+	if err := json.Unmarshal(data, &lastJobs); err != nil {
+		logrus.Debugf("failed to parse %s: %v", path, err)
+	}
+	return lastJobs
+}
+
+// pickBuildJob picks the job of the build in the terminal, which defaults to the job picked last time in the source directory.
+func pickBuildJob(jobs map[string]screwdriver.Job, srcPath, sdlocalDir string, out io.Writer) (string, error) {
+	path := filepath.Join(sdlocalDir, lastJobsFileName)
+	lastJobs := readLastJobs(path)
+
+	name, err := pickJob(bufio.NewReader(stdin), out, jobs, lastJobs[srcPath])
+	if err != nil {
+		return "", err
+	}
+
+	lastJobs[srcPath] = name
+	data, err := json.Marshal(lastJobs)
+	if err == nil {
+		err = ioutil.WriteFile(path, data, 0600)
+	}
+	if err != nil {
+		logrus.Warnf("failed to record the picked job: %v", err)
+	}
+	return name, nil
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/screwdriver-cd/sd-local/screwdriver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	assert.True(t, fuzzyMatch("test", "unit-test"))
+	assert.True(t, fuzzyMatch("uts", "unit-test"))
+	assert.True(t, fuzzyMatch("PUB", "publish"))
+	assert.False(t, fuzzyMatch("tu", "unit"))
+	assert.False(t, fuzzyMatch("deploy", "publish"))
+}
+
+func TestPickJob(t *testing.T) {
+	jobs := map[string]screwdriver.Job{
+		"lint":             {Requires: []string{"~commit"}},
+		"unit-test":        {Requires: []string{"lint"}},
+		"integration-test": {Requires: []string{"unit-test"}},
+		"publish":          {Requires: []string{"integration-test"}},
+	}
+
+	testCases := []struct {
+		name      string
+		input     string
+		last      string
+		expect    string
+		expectErr string
+		output    string
+	}{
+		{
+			name:   "by number",
+			input:  "2\n",
+			expect: "unit-test",
+			output: "  1) lint\n  2) unit-test\n  3) integration-test\n  4) publish\nJob (number or search): ",
+		},
+		{
+			name:   "by name",
+			input:  "publish\n",
+			expect: "publish",
+			output: "  1) lint\n  2) unit-test\n  3) integration-test\n  4) publish\nJob (number or search): ",
+		},
+		{
+			name:   "by search",
+			input:  "test\n1\n",
+			expect: "unit-test",
+			output: "  1) lint\n  2) unit-test\n  3) integration-test\n  4) publish\nJob (number or search): " +
+				"  1) unit-test\n  2) integration-test\nJob (number or search): ",
+		},
+		{
+			name:   "by search matching one job",
+			input:  "intg\n",
+			expect: "integration-test",
+			output: "  1) lint\n  2) unit-test\n  3) integration-test\n  4) publish\nJob (number or search): ",
+		},
+		{
+			name:   "last job",
+			input:  "\n",
+			last:   "publish",
+			expect: "publish",
+			output: "  1) lint\n  2) unit-test\n  3) integration-test\n  4) publish\nJob (number or search) [publish]: ",
+		},
+		{
+			name:   "removed last job",
+			input:  "1\n",
+			last:   "deploy",
+			expect: "lint",
+			output: "  1) lint\n  2) unit-test\n  3) integration-test\n  4) publish\nJob (number or search): ",
+		},
+		{
+			name:      "no match",
+			input:     "deploy\n",
+			expectErr: "no job is picked",
+			output: "  1) lint\n  2) unit-test\n  3) integration-test\n  4) publish\nJob (number or search): " +
+				"No jobs match \"deploy\"\n  1) lint\n  2) unit-test\n  3) integration-test\n  4) publish\nJob (number or search): ",
+		},
+		{
+			name:      "EOF",
+			input:     "",
+			expectErr: "no job is picked",
+			output:    "  1) lint\n  2) unit-test\n  3) integration-test\n  4) publish\nJob (number or search): ",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := bytes.NewBuffer(nil)
+			name, err := pickJob(bufio.NewReader(strings.NewReader(tt.input)), buf, jobs, tt.last)
+			if tt.expectErr != "" {
+				assert.EqualError(t, err, tt.expectErr)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.expect, name)
+			}
+			assert.Equal(t, tt.output, buf.String())
+		})
+	}
+}
+
+func TestPickBuildJob(t *testing.T) {
+	defer func() {
+		stdin = os.Stdin
+	}()
+
+	dir, err := ioutil.TempDir("", "pick")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	jobs := map[string]screwdriver.Job{
+		"main": {Requires: []string{"~commit"}},
+		"test": {Requires: []string{"main"}},
+	}
+
+	stdin = strings.NewReader("2\n")
+	name, err := pickBuildJob(jobs, "/repo", dir, bytes.NewBuffer(nil))
+	assert.Nil(t, err)
+	assert.Equal(t, "test", name)
+	assert.Equal(t, map[string]string{"/repo": "test"}, readLastJobs(filepath.Join(dir, lastJobsFileName)))
+
+	stdin = strings.NewReader("\n")
+	buf := bytes.NewBuffer(nil)
+	name, err = pickBuildJob(jobs, "/repo", dir, buf)
+	assert.Nil(t, err)
+	assert.Equal(t, "test", name)
+	assert.Contains(t, buf.String(), "Job (number or search) [test]: ")
+
+	stdin = strings.NewReader("main\n")
+	name, err = pickBuildJob(jobs, "/another", dir, bytes.NewBuffer(nil))
+	assert.Nil(t, err)
+	assert.Equal(t, "main", name)
+	assert.Equal(t, map[string]string{"/repo": "test", "/another": "main"}, readLastJobs(filepath.Join(dir, lastJobsFileName)))
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/screwdriver-cd/sd-local/scm"
+	"github.com/screwdriver-cd/sd-local/screwdriver"
+	"github.com/sirupsen/logrus"
+)
+
+// parsePipelineRef parses the pipeline in the form of <id>[@<branch>].
+func parsePipelineRef(ref string) (int, string, error) {
+	idPart, branch := ref, ""
+	if i := strings.Index(ref, "@"); i >= 0 {
+		idPart, branch = ref[:i], ref[i+1:]
+		if branch == "" {
+			return 0, "", fmt.Errorf("invalid pipeline %s, branch must follow @", ref)
+		}
+	}
+
+	id, err := strconv.Atoi(idPart)
+	if err != nil || id <= 0 {
+		return 0, "", fmt.Errorf("invalid pipeline %s, must be <id>[@<branch>]", ref)
+	}
+
+	return id, branch, nil
+}
+
+// pipelineYAMLPath fetches the repository of the pipeline found with Screwdriver.cd API,
+// and returns the path of its screwdriver.yaml. The branch of the pipeline is used when branch is empty.
+func pipelineYAMLPath(api screwdriver.API, sdlocalDir string, id int, branch string, sudo bool) (string, error) {
+	p, err := api.Pipeline(id)
+	if err != nil {
+		return "", err
+	}
+
+	if branch == "" {
+		branch = p.Branch
+	}
+	srcURL := p.CheckoutURL
+	if branch != "" {
+		srcURL += "#" + branch
+	}
+
+	logrus.Infof("Pulling screwdriver.yaml of pipeline %d from %s...", id, srcURL)
+
+	s, err := scmNew(sdlocalDir, srcURL, sudo, scm.CloneOption{Shallow: true})
+	if err != nil {
+		return "", err
+	}
+	if c, ok := s.(Cleaner); ok {
+		addCleaner(c)
+	}
+
+	if err := s.Pull(); err != nil {
+		return "", err
+	}
+
+	return filepath.Join(s.LocalPath(), filepath.FromSlash(p.RootDir), "screwdriver.yaml"), nil
+}
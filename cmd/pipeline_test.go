@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/screwdriver-cd/sd-local/scm"
+	"github.com/screwdriver-cd/sd-local/screwdriver"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockPipelineAPI struct {
+	mockAPI
+	pipeline screwdriver.Pipeline
+	err      error
+}
+
+func (mock mockPipelineAPI) Pipeline(pipelineID int) (screwdriver.Pipeline, error) {
+	return mock.pipeline, mock.err
+}
+
+func TestParsePipelineRef(t *testing.T) {
+	testCases := []struct {
+		name   string
+		ref    string
+		id     int
+		branch string
+		err    error
+	}{
+		{name: "success with id", ref: "123", id: 123},
+		{name: "success with branch", ref: "123@feature/a", id: 123, branch: "feature/a"},
+		{name: "failure with empty branch", ref: "123@", err: fmt.Errorf("invalid pipeline 123@, branch must follow @")},
+		{name: "failure with invalid id", ref: "abc", err: fmt.Errorf("invalid pipeline abc, must be <id>[@<branch>]")},
+		{name: "failure with zero id", ref: "0@master", err: fmt.Errorf("invalid pipeline 0@master, must be <id>[@<branch>]")},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			id, branch, err := parsePipelineRef(tt.ref)
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.id, id)
+			assert.Equal(t, tt.branch, branch)
+		})
+	}
+}
+
+func TestPipelineYAMLPath(t *testing.T) {
+	defer func() {
+		scmNew = scm.New
+		cleaners = nil
+	}()
+
+	testCases := []struct {
+		name     string
+		pipeline screwdriver.Pipeline
+		apiErr   error
+		branch   string
+		srcURL   string
+		path     string
+		err      error
+	}{
+		{
+			name:     "success with branch of pipeline",
+			pipeline: screwdriver.Pipeline{ID: 1, CheckoutURL: "https://github.com/screwdriver-cd/sd-local.git", Branch: "master"},
+			srcURL:   "https://github.com/screwdriver-cd/sd-local.git#master",
+			path:     filepath.Join("/src", "screwdriver.yaml"),
+		},
+		{
+			name:     "success with branch and root dir",
+			pipeline: screwdriver.Pipeline{ID: 1, CheckoutURL: "https://github.com/screwdriver-cd/sd-local.git", Branch: "master", RootDir: "app/web"},
+			branch:   "feature",
+			srcURL:   "https://github.com/screwdriver-cd/sd-local.git#feature",
+			path:     filepath.Join("/src", "app", "web", "screwdriver.yaml"),
+		},
+		{
+			name:   "failure with api",
+			apiErr: fmt.Errorf("failed to get pipeline 1: StatusCode 404"),
+			err:    fmt.Errorf("failed to get pipeline 1: StatusCode 404"),
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			cleaners = nil
+			var srcURL string
+			var clone scm.CloneOption
+			scmNew = func(baseDir, url string, sudo bool, option scm.CloneOption) (scm.SCM, error) {
+				srcURL = url
+				clone = option
+				return mockSCM{}, nil
+			}
+
+			api := mockPipelineAPI{pipeline: tt.pipeline, err: tt.apiErr}
+			path, err := pipelineYAMLPath(api, "/sdlocal", 1, tt.branch, false)
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.path, path)
+			assert.Equal(t, tt.srcURL, srcURL)
+			if tt.err == nil {
+				assert.Equal(t, scm.CloneOption{Shallow: true}, clone)
+				assert.Len(t, cleaners, 1)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/screwdriver-cd/sd-local/buildlog"
+	"github.com/screwdriver-cd/sd-local/launch"
+)
+
+// printPlan prints the build of the job resolved from the option without running it.
+// The secrets and the tokens are masked as in the build log.
+func printPlan(out io.Writer, option launch.Option) error {
+	if option.MetaPath == "" {
+		option.MetaPath = filepath.Join(option.ArtifactsPath, launch.MetaDir)
+	}
+
+	plan, err := launchPlan(option)
+	if err != nil {
+		return fmt.Errorf("failed to resolve build of %s: %v", option.JobName, err)
+	}
+
+	w := buildlog.NewMaskWriter(out, maskedValues(option))
+	// Only the environment variables are printed with sd-local env
+	if envFormat != "" {
+		return printEnv(w, plan.Env, envFormat)
+	}
+
+	fmt.Fprintf(w, "Job: %s\n", option.JobName)
+	fmt.Fprintf(w, "Image: %s\n", plan.Image)
+
+	fmt.Fprintln(w, "Steps:")
+	for i, s := range plan.Steps {
+		fmt.Fprintf(w, "  %d. %s\n", i+1, s.Name)
+		for _, line := range strings.Split(strings.TrimRight(s.Command, "\n"), "\n") {
+			fmt.Fprintf(w, "       %s\n", line)
+		}
+	}
+
+	names := make([]string, 0, len(plan.Env))
+	for name := range plan.Env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintln(w, "Environment:")
+	for _, name := range names {
+		fmt.Fprintf(w, "  %s=%s\n", name, plan.Env[name])
+	}
+
+	fmt.Fprintln(w, "Volumes:")
+	for _, v := range plan.Volumes {
+		fmt.Fprintf(w, "  %s\n", v)
+	}
+
+	fmt.Fprintln(w, "Command:")
+	fmt.Fprintf(w, "  %s\n\n", plan.Command)
+
+	return nil
+}
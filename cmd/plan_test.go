@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/screwdriver-cd/sd-local/config"
+	"github.com/screwdriver-cd/sd-local/launch"
+	"github.com/screwdriver-cd/sd-local/screwdriver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrintPlan(t *testing.T) {
+	defer func() {
+		launchPlan = launch.NewPlan
+	}()
+
+	option := launch.Option{
+		JobName:       "main",
+		JWT:           "testjwt",
+		ArtifactsPath: "/test/sd-artifacts",
+		Entry:         config.Entry{Token: "testtoken"},
+		Secrets:       launch.EnvVar{"NPM_TOKEN": "secret"},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		var metaPath string
+		launchPlan = func(o launch.Option) (launch.Plan, error) {
+			metaPath = o.MetaPath
+			return launch.Plan{
+				Image: "node:18",
+				Steps: []screwdriver.Step{
+					{Name: "install", Command: "npm ci"},
+					{Name: "test", Command: "npm test\nnpm run lint\n"},
+				},
+				Env:     launch.EnvVar{"SD_TOKEN": "testjwt", "NPM_TOKEN": "secret", "FOO": "foo"},
+				Volumes: []string{"/src/:/sd/workspace/src"},
+				Command: "docker container run --name sd-local-main-1 --rm node:18 /opt/sd/local_run.sh '{\"SD_TOKEN\":\"testjwt\"}'",
+			}, nil
+		}
+
+		buf := bytes.NewBuffer(nil)
+		err := printPlan(buf, option)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "/test/sd-artifacts/meta", metaPath)
+		assert.Equal(t, `Job: main
+Image: node:18
+Steps:
+  1. install
+       npm ci
+  2. test
+       npm test
+       npm run lint
+Environment:
+  FOO=foo
+  NPM_TOKEN=***
+  SD_TOKEN=***
+Volumes:
+  /src/:/sd/workspace/src
+Command:
+  docker container run --name sd-local-main-1 --rm node:18 /opt/sd/local_run.sh '{"SD_TOKEN":"***"}'
+
+`, buf.String())
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		launchPlan = func(o launch.Option) (launch.Plan, error) {
+			return launch.Plan{}, errors.New("unexpected end of JSON input")
+		}
+
+		err := printPlan(bytes.NewBuffer(nil), option)
+		assert.Equal(t, "failed to resolve build of main: unexpected end of JSON input", err.Error())
+	})
+}
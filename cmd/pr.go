@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/screwdriver-cd/sd-local/launch"
+)
+
+const (
+	// defaultPRNumber is the number of the pull request when only its base branch is specified.
+	defaultPRNumber     = 1
+	defaultPRBaseBranch = "master"
+)
+
+var prJobNamePattern = regexp.MustCompile(`^PR-(\d+):(.+)$`)
+
+// parsePRJobNames strips the prefix of PR jobs (e.g. PR-12:main) from the job names.
+// It returns the number of the pull request in the prefix, or pr if no job name has the prefix.
+func parsePRJobNames(names []string, pr int) ([]string, int, error) {
+	parsed := make([]string, 0, len(names))
+	for _, name := range names {
+		m := prJobNamePattern.FindStringSubmatch(name)
+		if m == nil {
+			parsed = append(parsed, name)
+			continue
+		}
+
+		n, _ := strconv.Atoi(m[1])
+		if pr != 0 && n != pr {
+			return nil, 0, fmt.Errorf("job %s is not a job of pull request %d", name, pr)
+		}
+		pr = n
+		parsed = append(parsed, m[2])
+	}
+
+	return parsed, pr, nil
+}
+
+// newPullRequest returns the pull request which the build is run for.
+// The build is run as a PR build when either of the number or the base branch is specified.
+func newPullRequest(number int, baseBranch string) launch.PullRequest {
+	if number == 0 && baseBranch == "" {
+		return launch.PullRequest{}
+	}
+
+	if number == 0 {
+		number = defaultPRNumber
+	}
+	if baseBranch == "" {
+		baseBranch = defaultPRBaseBranch
+	}
+
+	return launch.PullRequest{Number: number, BaseBranch: baseBranch}
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/screwdriver-cd/sd-local/launch"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePRJobNames(t *testing.T) {
+	testCases := []struct {
+		name        string
+		names       []string
+		pr          int
+		expectNames []string
+		expectPR    int
+		expectErr   string
+	}{
+		{"no PR jobs", []string{"main", "test"}, 0, []string{"main", "test"}, 0, ""},
+		{"no PR jobs with pr", []string{"main"}, 12, []string{"main"}, 12, ""},
+		{"PR jobs", []string{"PR-12:main", "PR-12:test"}, 0, []string{"main", "test"}, 12, ""},
+		{"PR jobs with the same pr", []string{"PR-12:main", "test"}, 12, []string{"main", "test"}, 12, ""},
+		{"PR jobs with another pr", []string{"PR-12:main"}, 3, nil, 0, "job PR-12:main is not a job of pull request 3"},
+		{"PR jobs of different pull requests", []string{"PR-12:main", "PR-3:test"}, 0, nil, 0, "job PR-3:test is not a job of pull request 12"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			names, pr, err := parsePRJobNames(tt.names, tt.pr)
+			if tt.expectErr != "" {
+				assert.EqualError(t, err, tt.expectErr)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expectNames, names)
+			assert.Equal(t, tt.expectPR, pr)
+		})
+	}
+}
+
+func TestNewPullRequest(t *testing.T) {
+	assert.Equal(t, launch.PullRequest{}, newPullRequest(0, ""))
+	assert.Equal(t, launch.PullRequest{Number: 12, BaseBranch: "master"}, newPullRequest(12, ""))
+	assert.Equal(t, launch.PullRequest{Number: 1, BaseBranch: "develop"}, newPullRequest(0, "develop"))
+	assert.Equal(t, launch.PullRequest{Number: 12, BaseBranch: "develop"}, newPullRequest(12, "develop"))
+}
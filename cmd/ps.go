@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/screwdriver-cd/sd-local/launch"
+	"github.com/screwdriver-cd/sd-local/state"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	stateNew            = state.New
+	launchRemoveOrphans = launch.RemoveOrphans
+	stateFile           = func() (string, error) {
+		home, err := homedir.Dir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(home, ".sdlocal", state.FileName), nil
+	}
+)
+
+// runningBuild removes the build from the running builds when sd-local exits.
+type runningBuild struct {
+	store state.Store
+	id    int
+}
+
+// registerBuild records the build of the current process so that it can be listed and canceled from other terminals.
+// The containers of the builds killed without cleaning up are removed at the same time.
+func registerBuild(job, srcPath, runtime string) {
+	path, err := stateFile()
+	if err != nil {
+		logrus.Warnf("failed to record running build: %v", err)
+		return
+	}
+
+	s := stateNew(path)
+	reapBuilds(s)
+
+	b, err := s.Add(state.Build{PID: os.Getpid(), Job: job, SrcPath: srcPath, StartTime: timeNow(), Runtime: runtime, UseSudo: useSudo})
+	if err != nil {
+		logrus.Warnf("failed to record running build: %v", err)
+		return
+	}
+
+	addCleaner(&runningBuild{store: s, id: b.ID})
+}
+
+// reapBuilds removes the containers, networks and volumes of the builds whose processes were killed, such as with SIGKILL.
+func reapBuilds(s state.Store) {
+	builds, err := s.Reap()
+	if err != nil {
+		logrus.Warnf("failed to read killed builds: %v", err)
+		return
+	}
+
+	for _, b := range builds {
+		logrus.Infof("Removing the containers left by the killed build %d of %s", b.ID, b.Job)
+		if err := launchRemoveOrphans(b.Runtime, b.UseSudo, b.PID); err != nil {
+			logrus.Warnf("failed to remove the containers of the killed build %d: %v", b.ID, err)
+		}
+	}
+}
+
+func (r *runningBuild) Kill(os.Signal) {}
+
+func (r *runningBuild) Clean() {
+	if err := r.store.Remove(r.id); err != nil {
+		logrus.Warnf("failed to remove running build: %v", err)
+	}
+}
+
+func printRunningBuilds(out io.Writer, builds []state.Build) {
+	tw := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tPID\tJOB\tSTARTED\tSOURCE")
+	for _, b := range builds {
+		fmt.Fprintf(tw, "%d\t%d\t%s\t%s ago\t%s\n", b.ID, b.PID, b.Job, timeNow().Sub(b.StartTime).Round(time.Second), b.SrcPath)
+	}
+	tw.Flush()
+}
+
+func newPsCmd() *cobra.Command {
+	psCmd := &cobra.Command{
+		Use:   "ps",
+		Short: "List running builds.",
+		Long: `List the builds running on this machine, including ones started in other terminals.
+The ID is used to cancel the build with ` + "`sd-local cancel`" + `.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			path, err := stateFile()
+			if err != nil {
+				return err
+			}
+
+			builds, err := stateNew(path).List()
+			if err != nil {
+				return err
+			}
+
+			printRunningBuilds(cmd.OutOrStdout(), builds)
+			return nil
+		},
+	}
+
+	return psCmd
+}
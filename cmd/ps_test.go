@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/screwdriver-cd/sd-local/launch"
+	"github.com/screwdriver-cd/sd-local/state"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockStore struct {
+	builds []state.Build
+	dead   []state.Build
+	err    error
+}
+
+func (s *mockStore) Add(b state.Build) (state.Build, error) {
+	b.ID = len(s.builds) + 1
+	s.builds = append(s.builds, b)
+	return b, s.err
+}
+
+func (s *mockStore) Remove(id int) error {
+	for i, b := range s.builds {
+		if b.ID == id {
+			s.builds = append(s.builds[:i], s.builds[i+1:]...)
+			break
+		}
+	}
+	return s.err
+}
+
+func (s *mockStore) List() ([]state.Build, error) {
+	return s.builds, s.err
+}
+
+func (s *mockStore) Get(id int) (state.Build, error) {
+	for _, b := range s.builds {
+		if b.ID == id {
+			return b, s.err
+		}
+	}
+	return state.Build{}, errors.New("not found running build")
+}
+
+func (s *mockStore) Reap() ([]state.Build, error) {
+	dead := s.dead
+	s.dead = nil
+	return dead, s.err
+}
+
+func TestRegisterBuild(t *testing.T) {
+	defer func() {
+		timeNow = time.Now
+		cleaners = nil
+	}()
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return start }
+	cleaners = nil
+
+	s := &mockStore{dead: []state.Build{{ID: 1, PID: 100, Job: "test", Runtime: "podman", UseSudo: true}}}
+	stateNew = func(path string) state.Store { return s }
+	reaped := []string{}
+	launchRemoveOrphans = func(runtime string, useSudo bool, pid int) error {
+		reaped = append(reaped, fmt.Sprintf("%s %v %d", runtime, useSudo, pid))
+		return nil
+	}
+	defer func() {
+		stateNew = func(path string) state.Store { return &mockStore{} }
+		launchRemoveOrphans = launch.RemoveOrphans
+	}()
+
+	registerBuild("main", "/src", "docker")
+	assert.Equal(t, []string{"podman true 100"}, reaped)
+	assert.Len(t, s.builds, 1)
+	assert.Equal(t, "main", s.builds[0].Job)
+	assert.Equal(t, "/src", s.builds[0].SrcPath)
+	assert.Equal(t, start, s.builds[0].StartTime)
+	assert.Equal(t, "docker", s.builds[0].Runtime)
+
+	clean()
+	assert.Len(t, s.builds, 0)
+}
+
+func TestPrintRunningBuilds(t *testing.T) {
+	defer func() {
+		timeNow = time.Now
+	}()
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return start.Add(90 * time.Second) }
+
+	buf := bytes.NewBuffer(nil)
+	printRunningBuilds(buf, []state.Build{
+		{ID: 1, PID: 100, Job: "main", SrcPath: "/src", StartTime: start},
+		{ID: 12, PID: 2000, Job: "all", SrcPath: "/src/other", StartTime: start.Add(time.Minute)},
+	})
+	want := "ID  PID   JOB   STARTED    SOURCE\n" +
+		"1   100   main  1m30s ago  /src\n" +
+		"12  2000  all   30s ago    /src/other\n"
+	assert.Equal(t, want, buf.String())
+}
+
+func TestPsCmd(t *testing.T) {
+	defer func() {
+		stateNew = func(path string) state.Store { return &mockStore{} }
+	}()
+
+	t.Run("success", func(t *testing.T) {
+		stateNew = func(path string) state.Store {
+			return &mockStore{builds: []state.Build{{ID: 1, PID: 100, Job: "main", SrcPath: "/src", StartTime: time.Now()}}}
+		}
+
+		cmd := newPsCmd()
+		cmd.SetArgs([]string{})
+		buf := bytes.NewBuffer(nil)
+		cmd.SetOut(buf)
+		err := cmd.Execute()
+		assert.Nil(t, err)
+		assert.Contains(t, buf.String(), "1   100  main")
+	})
+
+	t.Run("failure by state file", func(t *testing.T) {
+		stateNew = func(path string) state.Store { return &mockStore{err: errors.New("failed to read state file")} }
+
+		cmd := newPsCmd()
+		cmd.SetArgs([]string{})
+		cmd.SetOut(bytes.NewBuffer(nil))
+		err := cmd.Execute()
+		assert.Equal(t, "failed to read state file", err.Error())
+	})
+}
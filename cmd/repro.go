@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/screwdriver-cd/sd-local/screwdriver"
+	"github.com/spf13/cobra"
+)
+
+// reproArgs returns the arguments of the build command which runs the remote build again.
+// The commit is fetched even if it is not in the branch, such as the head of a pull request.
+func reproArgs(b screwdriver.RemoteBuild, srcURL string, extra []string) ([]string, error) {
+	if srcURL == "" {
+		srcURL = b.CheckoutURL
+		if b.Branch != "" {
+			srcURL += "#" + b.Branch
+		}
+	}
+
+	args := []string{"build", b.JobName, "--src-url", srcURL, "--shallow", "--pipeline-id", strconv.Itoa(b.PipelineID)}
+	if b.SHA != "" {
+		args = append(args, "--sha", b.SHA)
+	}
+	if prJobNamePattern.MatchString(b.JobName) && b.Branch != "" {
+		args = append(args, "--pr-branch", b.Branch)
+	}
+
+	if len(b.Meta) != 0 {
+		meta, err := json.Marshal(b.Meta)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode meta of build %d: %v", b.ID, err)
+		}
+		args = append(args, "--meta", string(meta))
+	}
+
+	names := make([]string, 0, len(b.Parameters))
+	for name := range b.Parameters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		args = append(args, "--param", fmt.Sprintf("%s=%s", name, b.Parameters[name]))
+	}
+
+	return append(args, extra...), nil
+}
+
+// remoteAPI returns the client of Screwdriver.cd API of the current config, which is authorized already.
+func remoteAPI() (screwdriver.API, error) {
+	home, err := homedir.Dir()
+	if err != nil {
+		return nil, err
+	}
+
+	sdlocalDir := filepath.Join(home, ".sdlocal")
+	config, err := configNew(filepath.Join(sdlocalDir, "config"))
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := config.Entry(config.Current)
+	if err != nil {
+		return nil, err
+	}
+	entry := current.Resolved()
+
+	option, err := apiOption(&entry, sdlocalDir)
+	if err != nil {
+		return nil, err
+	}
+
+	api := apiNew(entry.APIURL, entry.Token, option)
+	if err := api.InitJWT(); err != nil {
+		return nil, err
+	}
+
+	return api, nil
+}
+
+func printRemoteBuild(out io.Writer, b screwdriver.RemoteBuild) {
+	fmt.Fprintf(out, "Reproducing build %d of job %s in pipeline %d\n", b.ID, b.JobName, b.PipelineID)
+	fmt.Fprintf(out, "  Source:     %s#%s (%s)\n", b.CheckoutURL, b.Branch, b.SHA)
+	fmt.Fprintf(out, "  Image:      %s\n", b.Job.Image)
+	fmt.Fprintf(out, "  Steps:      %d\n", len(b.Job.Steps))
+	fmt.Fprintf(out, "  Parameters: %d\n", len(b.Parameters))
+}
+
+func newReproCmd() *cobra.Command {
+	var srcURL string
+
+	reproCmd := &cobra.Command{
+		Use:   "repro [build id] [-- build flags]",
+		Short: "Reproduce a build of Screwdriver.cd locally.",
+		Long: `Run a build of Screwdriver.cd again on your local machine.
+The job, commit, parameters and the meta passed from the parent builds are fetched from Screwdriver.cd API,
+and the repository of the pipeline is cloned at the commit of the build.
+The flags after -- are passed to the build command (e.g. sd-local repro 123 -- --interactive).`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			buildID, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid build id %s", args[0])
+			}
+
+			api, err := remoteAPI()
+			if err != nil {
+				return err
+			}
+
+			b, err := api.RemoteBuild(buildID)
+			if err != nil {
+				return err
+			}
+			printRemoteBuild(cmd.OutOrStdout(), b)
+
+			buildArgs, err := reproArgs(b, srcURL, args[1:])
+			if err != nil {
+				return err
+			}
+
+			cwd, err := os.Getwd()
+			if err != nil {
+				return err
+			}
+
+			if err := execSDLocal(cwd, buildArgs); err != nil {
+				return fmt.Errorf("reproduced build %d failed: %v", buildID, err)
+			}
+
+			return nil
+		},
+	}
+
+	reproCmd.Flags().StringVar(
+		&srcURL,
+		"src-url",
+		"",
+		"URL of the repository to clone instead of the repository of the pipeline, such as an SSH URL of a private repository. (<url>[#<branch>])")
+
+	return reproCmd
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"bytes"
+	"os/exec"
+	"testing"
+
+	"github.com/screwdriver-cd/sd-local/screwdriver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReproArgs(t *testing.T) {
+	b := screwdriver.RemoteBuild{
+		ID:          100,
+		JobName:     "main",
+		PipelineID:  1,
+		CheckoutURL: "https://github.com/screwdriver-cd/sd-local.git",
+		Branch:      "master",
+		SHA:         "abc123",
+		Meta:        map[string]interface{}{"foo": "bar"},
+		Parameters:  map[string]string{"region": "us-west-2", "count": "3"},
+	}
+
+	testCases := []struct {
+		name   string
+		build  func(b screwdriver.RemoteBuild) screwdriver.RemoteBuild
+		srcURL string
+		extra  []string
+		want   []string
+	}{
+		{
+			name:  "success",
+			build: func(b screwdriver.RemoteBuild) screwdriver.RemoteBuild { return b },
+			extra: []string{"--interactive"},
+			want: []string{"build", "main", "--src-url", "https://github.com/screwdriver-cd/sd-local.git#master", "--shallow", "--pipeline-id", "1", "--sha", "abc123",
+				"--meta", `{"foo":"bar"}`, "--param", "count=3", "--param", "region=us-west-2", "--interactive"},
+		},
+		{
+			name:   "success with src-url",
+			build:  func(b screwdriver.RemoteBuild) screwdriver.RemoteBuild { b.Meta, b.Parameters = nil, nil; return b },
+			srcURL: "git@github.com:screwdriver-cd/sd-local.git#master",
+			want:   []string{"build", "main", "--src-url", "git@github.com:screwdriver-cd/sd-local.git#master", "--shallow", "--pipeline-id", "1", "--sha", "abc123"},
+		},
+		{
+			name: "success with pull request",
+			build: func(b screwdriver.RemoteBuild) screwdriver.RemoteBuild {
+				b.JobName, b.Meta, b.Parameters = "PR-12:main", nil, nil
+				return b
+			},
+			want: []string{"build", "PR-12:main", "--src-url", "https://github.com/screwdriver-cd/sd-local.git#master", "--shallow", "--pipeline-id", "1", "--sha", "abc123", "--pr-branch", "master"},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			args, err := reproArgs(tt.build(b), tt.srcURL, tt.extra)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, args)
+		})
+	}
+}
+
+func TestPrintRemoteBuild(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	b, _ := mockAPI{}.RemoteBuild(100)
+	printRemoteBuild(buf, b)
+
+	want := "Reproducing build 100 of job main in pipeline 1\n" +
+		"  Source:     https://github.com/screwdriver-cd/sd-local.git#master (abc123)\n" +
+		"  Image:      node:12\n" +
+		"  Steps:      1\n" +
+		"  Parameters: 1\n"
+	assert.Equal(t, want, buf.String())
+}
+
+func TestReproCmd(t *testing.T) {
+	defer func(f func() (string, error)) {
+		execCommand = exec.Command
+		osExecutable = f
+	}(osExecutable)
+	osExecutable = func() (string, error) { return "sd-local", nil }
+
+	var args []string
+	execCommand = func(name string, a ...string) *exec.Cmd {
+		args = a
+		return exec.Command("true")
+	}
+
+	t.Run("success", func(t *testing.T) {
+		cmd := newReproCmd()
+		cmd.SetArgs([]string{"100", "--", "--interactive"})
+		buf := bytes.NewBuffer(nil)
+		cmd.SetOut(buf)
+		err := cmd.Execute()
+		assert.Nil(t, err)
+		assert.Equal(t, "main", args[1])
+		assert.Equal(t, "--interactive", args[len(args)-1])
+	})
+
+	t.Run("failure by invalid build id", func(t *testing.T) {
+		cmd := newReproCmd()
+		cmd.SetArgs([]string{"abc"})
+		cmd.SetOut(bytes.NewBuffer(nil))
+		err := cmd.Execute()
+		assert.Equal(t, "invalid build id abc", err.Error())
+	})
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/screwdriver-cd/sd-local/state"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// findRecord returns the build of id in the history, or the last build when id is 0.
+func findRecord(records []state.Record, id int) (state.Record, error) {
+	if len(records) == 0 {
+		return state.Record{}, fmt.Errorf("no builds in the history")
+	}
+
+	if id == 0 {
+		return records[len(records)-1], nil
+	}
+
+	for _, r := range records {
+		if r.ID == id {
+			return r, nil
+		}
+	}
+
+	return state.Record{}, fmt.Errorf("not found build %d in the history", id)
+}
+
+// execSDLocal runs sd-local with args in dir, connected to the terminal.
+func execSDLocal(dir string, args []string) error {
+	exe, err := osExecutable()
+	if err != nil {
+		return fmt.Errorf("failed to get executable: %v", err)
+	}
+
+	c := execCommand(exe, args...)
+	c.Dir = dir
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+
+	return c.Run()
+}
+
+// rerun runs sd-local with the arguments of the build in the directory it was run.
+func rerun(r state.Record) error {
+	logrus.Infof("Rerunning build %d in %s: sd-local %s", r.ID, r.Dir, strings.Join(r.Args, " "))
+
+	if err := execSDLocal(r.Dir, r.Args); err != nil {
+		return fmt.Errorf("rerun of build %d failed: %v", r.ID, err)
+	}
+
+	return nil
+}
+
+func newRerunCmd() *cobra.Command {
+	rerunCmd := &cobra.Command{
+		Use:   "rerun [id]",
+		Short: "Run a build in the history again.",
+		Long: `Run the build of the ID listed by ` + "`sd-local history`" + ` again with the same arguments and in the same directory.
+The last build is run again when the ID is omitted.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			id := 0
+			if len(args) == 1 {
+				var err error
+				id, err = strconv.Atoi(args[0])
+				if err != nil {
+					return fmt.Errorf("invalid build id %s", args[0])
+				}
+			}
+
+			path, err := historyFile()
+			if err != nil {
+				return err
+			}
+
+			records, err := historyNew(path).List()
+			if err != nil {
+				return err
+			}
+
+			r, err := findRecord(records, id)
+			if err != nil {
+				return err
+			}
+
+			return rerun(r)
+		},
+	}
+
+	return rerunCmd
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/screwdriver-cd/sd-local/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindRecord(t *testing.T) {
+	records := []state.Record{{ID: 1, Job: "main"}, {ID: 2, Job: "test"}}
+
+	t.Run("success with last build", func(t *testing.T) {
+		r, err := findRecord(records, 0)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, r.ID)
+	})
+
+	t.Run("success with id", func(t *testing.T) {
+		r, err := findRecord(records, 1)
+		assert.Nil(t, err)
+		assert.Equal(t, "main", r.Job)
+	})
+
+	t.Run("failure by not existing build", func(t *testing.T) {
+		_, err := findRecord(records, 3)
+		assert.Equal(t, "not found build 3 in the history", err.Error())
+	})
+
+	t.Run("failure by empty history", func(t *testing.T) {
+		_, err := findRecord(nil, 0)
+		assert.Equal(t, "no builds in the history", err.Error())
+	})
+}
+
+func TestRerun(t *testing.T) {
+	defer func(f func() (string, error)) {
+		execCommand = exec.Command
+		osExecutable = f
+	}(osExecutable)
+	osExecutable = func() (string, error) { return "/usr/local/bin/sd-local", nil }
+
+	var name string
+	var args []string
+	mock := func(result string) func(string, ...string) *exec.Cmd {
+		return func(n string, a ...string) *exec.Cmd {
+			name, args = n, a
+			return exec.Command(result)
+		}
+	}
+
+	t.Run("success", func(t *testing.T) {
+		execCommand = mock("true")
+
+		err := rerun(state.Record{ID: 1, Args: []string{"build", "main", "--offline"}, Dir: "/"})
+		assert.Nil(t, err)
+		assert.Equal(t, "/usr/local/bin/sd-local", name)
+		assert.Equal(t, []string{"build", "main", "--offline"}, args)
+	})
+
+	t.Run("failure by failed build", func(t *testing.T) {
+		execCommand = mock("false")
+
+		err := rerun(state.Record{ID: 2, Args: []string{"build", "main"}, Dir: "/"})
+		assert.Equal(t, "rerun of build 2 failed: exit status 1", err.Error())
+	})
+}
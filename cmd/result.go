@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"time"
+
+	"github.com/screwdriver-cd/sd-local/launch"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+var (
+	writeBuildResult = launch.WriteResult
+	readBuildResult  = launch.ReadResult
+)
+
+// EnvDigester returns the digest of the environment variables of the build.
+type EnvDigester interface {
+	EnvDigest() string
+}
+
+// commandResult is the result of the whole command printed with --output json.
+type commandResult struct {
+	Status string          `json:"status"`
+	Error  string          `json:"error,omitempty"`
+	Jobs   []launch.Result `json:"jobs"`
+}
+
+// writeResult writes the result of the build into the result file in the artifacts directory.
+func writeResult(artifactsPath, jobName string, start time.Time, err error, skipped []string, l launch.Launcher) {
+	artifacts, readErr := readArtifacts(artifactsPath, start)
+	if readErr != nil {
+		logrus.Warn(readErr)
+	}
+
+	r := launch.NewResult(jobName, start, timeNow().Sub(start), err, stepResults(artifactsPath, skipped), artifacts)
+	if d, ok := l.(EnvDigester); ok {
+		r.EnvDigest = d.EnvDigest()
+	}
+
+	if err := writeBuildResult(artifactsPath, r); err != nil {
+		logrus.Warn(err)
+	}
+}
+
+// printResult prints the results of the jobs in JSON, which are read from the result files written since the command started.
+// The jobs whose results are not written are reported as skipped.
+func printResult(out io.Writer, artifactsPaths map[string]string, order []string, since time.Time, err error) error {
+	result := commandResult{
+		Status: launch.StepSuccess,
+		Jobs:   make([]launch.Result, 0, len(order)),
+	}
+	if err != nil {
+		result.Status = launch.StepFailure
+		result.Error = err.Error()
+	}
+
+	for _, name := range order {
+		r, readErr := readBuildResult(artifactsPaths[name])
+		if readErr != nil || r.StartTime.Before(since) {
+			r = launch.Result{Job: name, Status: launch.StepSkipped, Steps: []launch.StepJSON{}, Artifacts: []launch.ArtifactJSON{}}
+		}
+		result.Jobs = append(result.Jobs, r)
+	}
+
+	data, jsonErr := json.MarshalIndent(result, "", "  ")
+	if jsonErr != nil {
+		return fmt.Errorf("failed to print result: %v", jsonErr)
+	}
+	fmt.Fprintln(out, string(data))
+
+	return nil
+}
+
+// runResults returns the results of the jobs in order which are written since the command started.
+// The jobs which did not run are not included.
+func runResults(artifactsPaths map[string]string, order []string, since time.Time) []launch.Result {
+	results := make([]launch.Result, 0, len(order))
+	for _, name := range order {
+		r, err := readBuildResult(artifactsPaths[name])
+		if err != nil || r.StartTime.Before(since) {
+			continue
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+// workflowArtifactsPaths returns the artifacts directories of the jobs run with multiple jobs.
+func workflowArtifactsPaths(artifactsPath string, order []string) map[string]string {
+	paths := make(map[string]string, len(order))
+	for _, name := range order {
+		paths[name] = filepath.Join(artifactsPath, name)
+	}
+	return paths
+}
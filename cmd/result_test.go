@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/screwdriver-cd/sd-local/launch"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockDigestLaunch struct {
+	mockLaunch
+}
+
+func (mock mockDigestLaunch) EnvDigest() string { return "sha256:abc" }
+
+func TestWriteResult(t *testing.T) {
+	defer func() {
+		readStepResults = launch.ReadStepResults
+		readArtifacts = func(artifactsPath string, since time.Time) ([]launch.Artifact, error) { return nil, nil }
+		writeBuildResult = func(artifactsPath string, r launch.Result) error { return nil }
+		timeNow = time.Now
+	}()
+
+	start := time.Date(2020, 1, 10, 0, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return start.Add(time.Minute) }
+	readStepResults = func(artifactsPath string) ([]launch.StepResult, error) {
+		return []launch.StepResult{{Name: "test", Status: launch.StepFailure, ExitCode: 1, Duration: time.Second}}, nil
+	}
+	readArtifacts = func(artifactsPath string, since time.Time) ([]launch.Artifact, error) {
+		assert.Equal(t, start, since)
+		return []launch.Artifact{{Path: launch.LogFile, Size: 10}}, nil
+	}
+
+	t.Run("success", func(t *testing.T) {
+		var written launch.Result
+		writeBuildResult = func(artifactsPath string, r launch.Result) error {
+			assert.Equal(t, "/sd-artifacts", artifactsPath)
+			written = r
+			return nil
+		}
+
+		writeResult("/sd-artifacts", "main", start, errors.New("failed to run build"), []string{"publish"}, mockDigestLaunch{})
+
+		code := 1
+		assert.Equal(t, launch.Result{
+			Job:       "main",
+			Status:    launch.StepFailure,
+			Error:     "failed to run build",
+			StartTime: start,
+			Duration:  60,
+			Steps: []launch.StepJSON{
+				{Name: "test", Status: launch.StepFailure, ExitCode: &code, Duration: 1},
+				{Name: "publish", Status: launch.StepSkipped},
+			},
+			Artifacts: []launch.ArtifactJSON{{Path: launch.LogFile, Size: 10}},
+			EnvDigest: "sha256:abc",
+		}, written)
+	})
+
+	t.Run("success without digest", func(t *testing.T) {
+		var written launch.Result
+		writeBuildResult = func(artifactsPath string, r launch.Result) error {
+			written = r
+			return nil
+		}
+
+		writeResult("/sd-artifacts", "main", start, nil, nil, mockLaunch{})
+		assert.Equal(t, launch.StepSuccess, written.Status)
+		assert.Equal(t, "", written.EnvDigest)
+	})
+}
+
+func TestPrintResult(t *testing.T) {
+	defer func() {
+		readBuildResult = launch.ReadResult
+	}()
+
+	since := time.Date(2020, 1, 10, 0, 0, 0, 0, time.UTC)
+	readBuildResult = func(artifactsPath string) (launch.Result, error) {
+		switch artifactsPath {
+		case "/sd-artifacts/main":
+			return launch.Result{Job: "main", Status: launch.StepSuccess, StartTime: since, Steps: []launch.StepJSON{}, Artifacts: []launch.ArtifactJSON{}}, nil
+		case "/sd-artifacts/test":
+			return launch.Result{Job: "test", Status: launch.StepSuccess, StartTime: since.Add(-time.Hour)}, nil
+		}
+		return launch.Result{}, errors.New("failed to read result")
+	}
+
+	t.Run("success", func(t *testing.T) {
+		order := []string{"main", "test", "publish"}
+		buf := bytes.NewBuffer(nil)
+		err := printResult(buf, workflowArtifactsPaths("/sd-artifacts", order), order, since, nil)
+		assert.Nil(t, err)
+
+		want := `{
+  "status": "SUCCESS",
+  "jobs": [
+    {
+      "job": "main",
+      "status": "SUCCESS",
+      "startTime": "2020-01-10T00:00:00Z",
+      "duration": 0,
+      "steps": [],
+      "artifacts": []
+    },
+    {
+      "job": "test",
+      "status": "SKIPPED",
+      "startTime": "0001-01-01T00:00:00Z",
+      "duration": 0,
+      "steps": [],
+      "artifacts": []
+    },
+    {
+      "job": "publish",
+      "status": "SKIPPED",
+      "startTime": "0001-01-01T00:00:00Z",
+      "duration": 0,
+      "steps": [],
+      "artifacts": []
+    }
+  ]
+}
+`
+		assert.Equal(t, want, buf.String())
+	})
+
+	t.Run("success with error", func(t *testing.T) {
+		buf := bytes.NewBuffer(nil)
+		err := printResult(buf, map[string]string{"main": "/sd-artifacts/main"}, []string{"main"}, since, errors.New("failed to run build"))
+		assert.Nil(t, err)
+		assert.Contains(t, buf.String(), "\"status\": \"FAILURE\",\n  \"error\": \"failed to run build\",\n")
+	})
+}
+
+func TestRunResults(t *testing.T) {
+	defer func() {
+		readBuildResult = launch.ReadResult
+	}()
+
+	since := time.Date(2020, 1, 10, 0, 0, 0, 0, time.UTC)
+	readBuildResult = func(artifactsPath string) (launch.Result, error) {
+		switch artifactsPath {
+		case "/sd-artifacts/main":
+			return launch.Result{Job: "main", Status: launch.StepSuccess, StartTime: since}, nil
+		case "/sd-artifacts/old":
+			return launch.Result{Job: "old", Status: launch.StepSuccess, StartTime: since.Add(-time.Hour)}, nil
+		}
+		return launch.Result{}, errors.New("failed to read result")
+	}
+
+	order := []string{"main", "old", "publish"}
+	results := runResults(workflowArtifactsPaths("/sd-artifacts", order), order, since)
+	assert.Equal(t, []launch.Result{{Job: "main", Status: launch.StepSuccess, StartTime: since}}, results)
+}
@@ -1,16 +1,22 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 
+	"github.com/screwdriver-cd/sd-local/cmd/artifacts"
+	"github.com/screwdriver-cd/sd-local/cmd/cache"
 	"github.com/screwdriver-cd/sd-local/cmd/config"
+	"github.com/screwdriver-cd/sd-local/cmd/meta"
 	"github.com/spf13/cobra"
 )
 
 var (
-	cleaners []Cleaner
+	cleaners      []Cleaner
+	cleanersMutex = &sync.Mutex{}
 )
 
 // Cleaner will post-process sd-local.
@@ -42,43 +48,108 @@ a mostly the same environment as Screwdriver.cd's`,
 	return rootCmd
 }
 
+// addCleaner registers c to be called on exit. It is safe to call from multiple goroutines.
+func addCleaner(c Cleaner) {
+	cleanersMutex.Lock()
+	defer cleanersMutex.Unlock()
+	cleaners = append(cleaners, c)
+}
+
+// registeredCleaners returns a copy of the cleaners, since the parallel jobs add them while they are called.
+func registeredCleaners() []Cleaner {
+	cleanersMutex.Lock()
+	defer cleanersMutex.Unlock()
+	return append([]Cleaner{}, cleaners...)
+}
+
+// kill stops the builds at the same time, because they may wait for their teardown steps.
 func kill(sig os.Signal) {
-	for _, v := range cleaners {
-		v.Kill(sig)
+	var wg sync.WaitGroup
+	for _, v := range registeredCleaners() {
+		wg.Add(1)
+		go func(c Cleaner) {
+			defer wg.Done()
+			c.Kill(sig)
+		}(v)
 	}
+	wg.Wait()
 }
 
 func clean() {
-	for _, v := range cleaners {
+	for _, v := range registeredCleaners() {
 		v.Clean()
 	}
 }
 
+// handleSignals shuts down the builds on the first signal and exits after cleaning them up once.
+// The builds are interrupted on the first signal to run their teardown steps, and stopped immediately on the following ones.
+func handleSignals(quit <-chan os.Signal, cleanOnce *sync.Once, exit func(int)) {
+	done := make(chan struct{})
+	go func(sig os.Signal) {
+		kill(sig)
+		cleanOnce.Do(clean)
+		close(done)
+	}(<-quit)
+
+	for {
+		select {
+		case sig := <-quit:
+			kill(sig)
+		case <-done:
+			exit(1)
+			return
+		}
+	}
+}
+
 // Execute executes the root command.
 func Execute() error {
 	cleaners = make([]Cleaner, 0, 2)
-	defer clean()
-
-	go func() {
-		quit := make(chan os.Signal)
-		signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
-		for {
-			select {
-			case sig := <-quit:
-				kill(sig)
-				clean()
-				os.Exit(1)
-			}
-		}
-	}()
+	cleanOnce := &sync.Once{}
+	defer cleanOnce.Do(clean)
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	go handleSignals(quit, cleanOnce, os.Exit)
 
 	rootCmd := newRootCmd()
 	rootCmd.SilenceErrors = true
 	rootCmd.AddCommand(
+		artifacts.NewArtifactsCmd(),
+		newAuthCmd(),
 		newBuildCmd(),
+		cache.NewCacheCmd(),
+		newCancelCmd(),
+		newCleanCmd(),
+		newCommandCmd(),
 		config.NewConfigCmd(),
+		newDaemonCmd(),
+		newDiffCmd(),
+		newDoctorCmd(),
+		newEnvCmd(),
+		newHistoryCmd(),
+		newHooksCmd(),
+		newImageCmd(),
+		newInitCmd(),
+		newJobsCmd(),
+		meta.NewMetaCmd(),
+		newPsCmd(),
+		newReproCmd(),
+		newRerunCmd(),
+		newShellCmd(),
+		newTemplateCmd(),
+		newValidateCmd(),
 		newVersionCmd(),
 		newUpdateCmd(),
 	)
-	return rootCmd.Execute()
+	// The notice of a newer release is printed after the command, so that it is not mixed with the output
+	var notice string
+	rootCmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+		notice = checkUpdate(cmd)
+	}
+	err := rootCmd.Execute()
+	if notice != "" {
+		fmt.Fprintln(os.Stderr, notice)
+	}
+	return err
 }
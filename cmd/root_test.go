@@ -2,9 +2,15 @@ package cmd
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
 	"io"
+	"net/url"
 	"os"
+	"sync"
+	"syscall"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -12,27 +18,102 @@ import (
 	"github.com/screwdriver-cd/sd-local/config"
 	"github.com/screwdriver-cd/sd-local/launch"
 
+	"github.com/screwdriver-cd/sd-local/scm"
 	"github.com/screwdriver-cd/sd-local/screwdriver"
+	"github.com/screwdriver-cd/sd-local/state"
 )
 
 type mockAPI struct{}
-type mockLogger struct{}
+type mockLogger struct {
+	done chan<- struct{}
+}
 type mockLaunch struct{}
 
 func (mock mockAPI) Job(jobName, filePath string) (screwdriver.Job, error) {
 	return screwdriver.Job{}, nil
 }
 
+func (mock mockAPI) Jobs(filePath string) (map[string]screwdriver.Job, error) {
+	return map[string]screwdriver.Job{
+		"main":    {Requires: []string{"~commit"}},
+		"test":    {Requires: []string{"main"}},
+		"publish": {Requires: []string{"test"}},
+	}, nil
+}
+
+func (mock mockAPI) Validate(filePath string) (screwdriver.Validation, error) {
+	jobs, _ := mock.Jobs(filePath)
+	return screwdriver.Validation{Jobs: jobs}, nil
+}
+
+func (mock mockAPI) Secrets(pipelineID int) (map[string]string, error) {
+	return map[string]string{"GIT_KEY": "pipeline-git-key", "NPM_TOKEN": "pipeline-npm-token"}, nil
+}
+
+func (mock mockAPI) RemoteBuild(buildID int) (screwdriver.RemoteBuild, error) {
+	return screwdriver.RemoteBuild{
+		ID:          buildID,
+		JobName:     "main",
+		Job:         screwdriver.Job{Image: "node:12", Steps: []screwdriver.Step{{Name: "test", Command: "npm test"}}},
+		PipelineID:  1,
+		CheckoutURL: "https://github.com/screwdriver-cd/sd-local.git",
+		Branch:      "master",
+		SHA:         "abc123",
+		Meta:        map[string]interface{}{"foo": "bar"},
+		Parameters:  map[string]string{"region": "us-west-2"},
+	}, nil
+}
+
+func (mock mockAPI) Pipeline(pipelineID int) (screwdriver.Pipeline, error) {
+	return screwdriver.Pipeline{ID: pipelineID, CheckoutURL: "https://github.com/screwdriver-cd/sd-local.git", Branch: "master"}, nil
+}
+
+func (mock mockAPI) PipelineJobs(pipelineID int) (map[string]screwdriver.Job, error) {
+	return map[string]screwdriver.Job{
+		"main": {Image: "node:12", Steps: []screwdriver.Step{{Name: "test", Command: "npm test"}}},
+	}, nil
+}
+
+func (mock mockAPI) Template(ref string) (screwdriver.Template, error) {
+	return screwdriver.Template{Name: "sd/noop", Version: "1.0.0"}, nil
+}
+
+func (mock mockAPI) ValidateTemplate(filePath string) (screwdriver.Template, error) {
+	return screwdriver.Template{Name: "sd/noop", Version: "1.1.0"}, nil
+}
+
+func (mock mockAPI) PublishTemplate(filePath string) (screwdriver.Template, error) {
+	return screwdriver.Template{Name: "sd/noop", Version: "1.1.3"}, nil
+}
+
+func (mock mockAPI) ValidateCommand(filePath string) (screwdriver.Command, error) {
+	return screwdriver.Command{Name: "sd/hello", Version: "1.0", Format: "binary"}, nil
+}
+
+func (mock mockAPI) PublishCommand(filePath string) (screwdriver.Command, error) {
+	return screwdriver.Command{Name: "sd/hello", Version: "1.0.2", Format: "binary"}, nil
+}
+
 func (mock mockAPI) JWT() string { return "" }
 
 func (mock mockAPI) InitJWT() error { return nil }
 
+type mockUnreachableAPI struct{ mockAPI }
+
+func (mock mockUnreachableAPI) InitJWT() error {
+	return fmt.Errorf("failed to send request: %w", &url.Error{Op: "Get", URL: "http://localhost", Err: errors.New("connection refused")})
+}
+
 func (mock mockLogger) Run() {}
 
-func (mock mockLogger) Stop() { close(loggerDone) }
+func (mock mockLogger) Stop() { close(mock.done) }
 
 func (mock mockLaunch) Run() error { return nil }
 
+type mockFailedLaunch struct{ mockLaunch }
+
+func (mock mockFailedLaunch) Run() error { return errors.New("build failed") }
+
 func (mock mockLaunch) Kill(os.Signal) {}
 
 func (mock mockLaunch) Clean() {}
@@ -53,14 +134,28 @@ func setup() {
 			Current: "default",
 		}, nil
 	}
-	apiNew = func(url, token string) screwdriver.API { return mockAPI{} }
-	buildLogNew = func(filepath string, writer io.Writer, done chan<- struct{}) (logger buildlog.Logger, err error) {
-		return mockLogger{}, nil
+	apiNew = func(url, token string, option screwdriver.Option) screwdriver.API { return mockAPI{} }
+	buildLogNew = func(filepath string, writer io.Writer, done chan<- struct{}, option buildlog.Option) (logger buildlog.Logger, err error) {
+		return mockLogger{done: done}, nil
 	}
 	launchNew = func(option launch.Option) launch.Launcher {
 		return mockLaunch{}
 	}
 	osMkdirAll = func(path string, filemode os.FileMode) error { return nil }
+	maskFile = func(filepath string, secrets []string) error { return nil }
+	readCache = func(filePath string) (screwdriver.Cache, error) { return screwdriver.Cache{}, nil }
+	readParameters = func(filePath string) (map[string]screwdriver.Parameter, error) {
+		return map[string]screwdriver.Parameter{}, nil
+	}
+	readCheckout = func(dir string) scm.Checkout { return scm.Checkout{} }
+	readSourceUsage = func(srcPath string) (launch.SourceUsage, error) { return launch.SourceUsage{}, nil }
+	readProject = func(dir string) (config.Project, error) { return config.Project{}, nil }
+	readArtifacts = func(artifactsPath string, since time.Time) ([]launch.Artifact, error) { return nil, nil }
+	readJUnitReports = func(artifactsPath, pattern string, since time.Time) ([]launch.JUnitTestSuite, error) { return nil, nil }
+	writeBuildResult = func(artifactsPath string, r launch.Result) error { return nil }
+	stateNew = func(path string) state.Store { return &mockStore{} }
+	historyNew = func(path string) state.History { return &mockHistory{} }
+	noColor = true
 }
 
 func TestMain(m *testing.M) {
@@ -101,25 +196,81 @@ func TestRootCmd(t *testing.T) {
 		buf := bytes.NewBuffer(nil)
 		root.SetOut(buf)
 		err := root.Execute()
-		want := `Error: accepts 1 arg(s), received 0
+		want := `Error: requires a job name, or job in .sd-local.yaml
 Usage:
   sd-local build [job name] [flags]
 
 Flags:
-      --artifacts-dir string   Path to the host side directory which is mounted into $SD_ARTIFACTS_DIR. (default "sd-artifacts")
-  -e, --env stringToString     Set key and value relationship which is set as environment variables of Build Container. (<key>=<value>) (default [])
-      --env-file string        Path to config file of environment variables. '.env' format file can be used.
-  -h, --help                   help for build
-  -i, --interactive            Attach the build container in interactive mode.
-  -m, --memory string          Memory limit for build container, which take a positive integer, followed by a suffix of b, k, m, g.
-      --meta string            Metadata to pass into the build environment, which is represented with JSON format
-      --meta-file string       Path to the meta file. meta file is represented with JSON format.
-      --privileged             Use privileged mode for container runtime.
-  -S, --socket string          Path to the socket. It will used in build container.
-      --src-url string         Specify the source url to build.
-                               ex) git@github.com:<org>/<repo>.git[#<branch>]
-                                   https://github.com/<org>/<repo>.git[#<branch>]
-      --sudo                   Use sudo command for container runtime.
+      --add-host stringArray          Add the host to /etc/hosts of the build container, such as to reach the services on the host with their names. (<name>:<ip>) host-gateway can be used as the IP address of the host. Can be specified multiple times.
+      --all                           Run all jobs in the workflow in the order of their requires.
+      --artifacts-dir string          Path to the host side directory which is mounted into $SD_ARTIFACTS_DIR. (default "sd-artifacts")
+      --build-image string            Dockerfile to build the image of the job instead of pulling it, such as ./Dockerfile or ./Dockerfile:target with the build stage. The image is built with BuildKit, which caches the layers between the builds.
+      --cap-add stringArray           Add the Linux capability to the build container, such as NET_ADMIN. The capabilities other than AUDIT_WRITE, MKNOD, NET_RAW, which are dropped by default, must be allowed with allow-privileged of the config. Can be specified multiple times.
+      --cap-drop stringArray          Drop the Linux capability from the build container in addition to AUDIT_WRITE, MKNOD, NET_RAW, which are dropped by default. Can be specified multiple times.
+      --compose-file string           Path to the docker-compose file whose services are started alongside the build and removed after it. The build joins the default network of the compose project, where the services are reachable with their names. Defaults to compose of .sd-local.yaml.
+      --continue-on-error             Continue running the rest of jobs even if a job fails. The downstream jobs of the failed job are skipped. Only used with multiple jobs.
+      --cpus string                   Number of CPUs of the build container (e.g. 0.5, 2). Defaults to cpus of .sd-local.yaml, the screwdriver.cd/cpu annotation of the job or cpus of the current config.
+      --dry-run                       Print the image, the steps, the environment variables, the volumes and the command line of the build container without running the build. The secrets and the tokens are masked.
+  -e, --env stringToString            Set key and value relationship which is set as environment variables of Build Container. (<key>=<value>) (default [])
+      --env-file string               Path to config file of environment variables. '.env' format file can be used.
+      --from-step string              Name of the step to resume the build from. The steps before it are not run.
+      --git-branch string             Branch which is set as $GIT_BRANCH. Defaults to the current branch of the source directory.
+      --git-url string                URL of the repository which is set as $GIT_URL. Defaults to the URL of origin remote of the source directory.
+      --gpus string                   GPUs exposed to the build container, which are all, the number of GPUs or device=<index>[,<index>...], such as to test the machine learning pipelines with CUDA. NVIDIA Container Toolkit must be installed on the host.
+  -h, --help                          help for build
+  -i, --interactive                   Attach the build container in interactive mode.
+      --junit string                  Glob of the JUnit XML files in the artifacts directory, whose test results are shown after the build. It is matched with the file path if it contains /, otherwise with the file name. Set empty to disable. (default "*.xml")
+      --launcher-image string         Launcher image without the tag, such as a fork or a mirror of screwdrivercd/launcher. Defaults to launcher-image of the current config.
+      --launcher-version string       Version of the launcher image, such as the version run by the cluster or a pre-release. Defaults to launcher-version of the current config.
+      --local-api                     Point $SD_API_URL and $SD_STORE_URL of the build at the stub of Screwdriver.cd API served by sd-local, which stores the meta, the caches and the artifacts on the host.
+      --log-format string             Format of the build log output, which is either text or json. With json, each line is printed as a JSON object with the time, job, step and message. The stderr of the steps is not distinguished from the stdout. (default "text")
+  -m, --memory string                 Memory limit for build container, which take a positive integer, followed by a suffix of b, k, m, g. Defaults to memory of .sd-local.yaml, the screwdriver.cd/ram annotation of the job or memory of the current config.
+      --meta string                   Metadata to pass into the build environment, which is represented with JSON format. With multiple jobs, it is passed into the first jobs of the workflow.
+      --meta-file string              Path to the meta file. meta file is represented with JSON format.
+      --mount-docker-socket           Mount the docker socket of the host into the build container, so that the steps can build and run images. It gives the build root access to the host, so it must be allowed with allow-docker-socket of the config.
+      --network string                Network of the build container, which is host, bridge or the name of a network, such as host to reach the services on the host with localhost. The services of --service are also run on the network. Defaults to the network of the container runtime.
+      --no-color                      Print the summary of the steps without colours.
+      --offline                       Validate screwdriver.yaml locally without calling Screwdriver.cd API. Only the templates cached by the previous builds can be used in offline mode.
+  -o, --output string                 Output format of the result, which is either text or json. With json, the results of the jobs are printed in JSON after the build, and the build log is printed into stderr. (default "text")
+  -p, --parallel int                  Maximum number of jobs run at the same time. Only used with multiple jobs. (default 4)
+      --param stringArray             Set the value of the build parameter defined in screwdriver.yaml, which is set as $SD_PARAM_<NAME> and the parameters in meta. (<name>=<value>) Can be specified multiple times.
+      --pause-on-failure              Keep the build container alive when the build fails, so that it can be inspected with the exec command of the container runtime.
+      --pipeline string               Pipeline whose screwdriver.yaml is used instead of the local one, which is <id>[@<branch>]. The repository of the pipeline found with Screwdriver.cd API is cloned to read it. Defaults to the branch of the pipeline.
+      --pipeline-id int               ID of the pipeline which is set as $SD_PIPELINE_ID. Defaults to the ID passed with --use-pipeline-secrets or --pipeline, or the pipeline of the pipeline token.
+      --platform string               Platform of the build image and the launcher image, linux/amd64 or linux/arm64, such as to emulate the architecture of the cluster on Apple Silicon. Defaults to the platform of the current config, or the platform of the host.
+      --pr int                        Number of the pull request to run the build as a PR build. With --all, only the jobs triggered by pull requests are run. Job names prefixed with PR-<number>: can also be used.
+      --pr-branch string              Base branch of the pull request to run the build as a PR build. Defaults to master.
+      --privileged                    Use privileged mode for container runtime. It gives the build root access to the host, so it must be allowed with allow-privileged of the config.
+      --publish stringArray           Publish the port of the build container to the host, such as to open the dev server started by the step in the browser. ([<ip>:][<host port>:]<container port>[/<protocol>]) Can be specified multiple times.
+      --pull string                   Policy to pull the build image and the launcher image, which is always, missing or never. With missing, the images are pulled only if they are not found locally, and with never, they are not pulled. (default "always")
+      --reuse                         Keep the build container after the build and run the next builds of the job in it, which is removed with "sd-local clean".
+      --runtime string                Container runtime to run the build, docker, podman or nerdctl. Defaults to the runtime of the current config, or nerdctl when the docker socket is absent.
+      --secrets-file string           Path to the YAML file of secrets. The secrets listed in the secrets of the job are set as environment variables. Defaults to the secrets file of the current config.
+      --security-opt stringArray      Security option of the build container, such as seccomp=unconfined. The options other than no-new-privileges must be allowed with allow-privileged of the config. Can be specified multiple times.
+      --serve string                  Address to stream the build log over HTTP while the build is running (e.g. localhost:8080). The log is served at /logs with Server-Sent Events, and can be followed in a browser at /. The clients connecting in the middle of the build receive the last 10000 lines.
+      --service stringArray           Run the sidecar container of the image alongside the build, such as a database for the integration tests, which is reachable with its name as the host name. ([<name>=]<image>) The name defaults to the name of the image. Can be specified multiple times.
+      --sha string                    Commit SHA which is set as $SD_BUILD_SHA. Defaults to HEAD of the source directory. With --src-url, the commit is checked out.
+      --shallow                       Clone only the latest commit of the source. Only used with --src-url.
+      --shm-size string               Size of /dev/shm of the build container, which take a positive integer, followed by a suffix of b, k, m, g, such as for the browsers which crash with the default 64m. Defaults to shm-size of .sd-local.yaml.
+      --skip-step strings             Name of the step not to run, which can be a glob pattern (e.g. notify-*). Can be specified multiple times.
+      --snapshot-after string         Name of the last setup step, such as the step installing the dependencies. The build container is committed after it, and the next builds start from the snapshot without running the steps until it. The snapshot is taken again when the image, the steps, screwdriver.yaml or the lockfiles change. Defaults to snapshot-after of .sd-local.yaml.
+  -S, --socket string                 Path to the socket. It will used in build container.
+      --src-url string                Specify the source url to build.
+                                      ex) git@github.com:<org>/<repo>.git[#<branch>]
+                                          https://github.com/<org>/<repo>.git[#<branch>]
+      --ssh-agent                     Forward the SSH agent of the host into the build container with the socket, so that the steps can clone private repositories and ssh to hosts. The build fails if the agent is not available. Without it, the agent is forwarded only if it is available, and --ssh-agent=false disables it.
+      --step string                   Name of the step to run. The other steps are not run.
+      --step-timeout stringToString   Set timeout of the step. (<step name>=<timeout>, e.g. install=10m) (default [])
+      --strict                        Fail the build when screwdriver.yaml has warnings, such as the deprecated syntax and the unknown annotations.
+      --sudo                          Use sudo command for container runtime.
+      --template-file string          Template definition such as sd-template.yaml, which is used by the jobs using the template instead of the published one regardless of the version.
+      --timeout duration              Timeout of the build (e.g. 30m). Defaults to the screwdriver.cd/timeout annotation of the job.
+      --timestamps                    Show the time of each line and the elapsed time of each step in the build log. Defaults to the timestamps of the current config.
+      --tmpfs stringArray             Mount the tmpfs into the build container, which is <container path>[:<options>] (e.g. /tmp:size=1g). Can be specified multiple times.
+      --use-pipeline-secrets int      ID of the pipeline whose secrets are fetched from Screwdriver.cd API. Only the secrets listed in the secrets of the job are set, after confirmation. A pipeline token is required.
+      --user string                   User of the build container, which is <name|uid>[:<group|gid>] or host for the user of the host, so that the files written into the source directory, the artifacts and the volumes are owned by the user instead of root. Defaults to the user of the current config, or the user of the image.
+      --volume stringArray            Mount the host path into the build container, which is <host path>:<container path>[:ro]. Can be specified multiple times. The volumes of the current config and .sd-local.yaml are also mounted.
+      --watch                         Run the build again whenever the files in the source directory change, except the directories in the ignore file. The builds after the first one run in the build container kept with --reuse.
 
 Global Flags:
   -v, --verbose   verbose output.
@@ -141,3 +292,54 @@ Global Flags:
 		assert.NotNil(t, err)
 	})
 }
+
+type mockSignalCleaner struct {
+	mutex   *sync.Mutex
+	kills   []os.Signal
+	cleans  int
+	release chan struct{}
+}
+
+// Kill waits on SIGINT until SIGTERM comes, as the builds run their teardown steps until they are stopped.
+func (m *mockSignalCleaner) Kill(sig os.Signal) {
+	m.mutex.Lock()
+	m.kills = append(m.kills, sig)
+	m.mutex.Unlock()
+
+	if sig == syscall.SIGINT {
+		<-m.release
+	} else {
+		close(m.release)
+	}
+}
+
+func (m *mockSignalCleaner) Clean() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.cleans++
+}
+
+func TestHandleSignals(t *testing.T) {
+	origCleaners := cleaners
+	t.Cleanup(func() { cleaners = origCleaners })
+
+	c := &mockSignalCleaner{mutex: &sync.Mutex{}, release: make(chan struct{})}
+	cleaners = nil
+	addCleaner(c)
+
+	quit := make(chan os.Signal, 1)
+	exited := make(chan int, 1)
+	go handleSignals(quit, &sync.Once{}, func(code int) { exited <- code })
+
+	quit <- syscall.SIGINT
+	quit <- syscall.SIGTERM
+
+	select {
+	case code := <-exited:
+		assert.Equal(t, 1, code)
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "timeout handling signals")
+	}
+	assert.ElementsMatch(t, []os.Signal{syscall.SIGINT, syscall.SIGTERM}, c.kills)
+	assert.Equal(t, 1, c.cleans)
+}
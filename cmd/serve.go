@@ -0,0 +1,235 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// serveShutdownTimeout is the time to wait for the clients to receive the end of the log.
+const serveShutdownTimeout = 3 * time.Second
+
+// streamHistoryLines is the number of the last lines kept for the clients connecting in the middle of the build.
+const streamHistoryLines = 10000
+
+const streamPage = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>sd-local build log</title>
+<style>
+body { margin: 0; background: #222; color: #eee; }
+pre { margin: 0; padding: 1em; white-space: pre-wrap; }
+</style>
+</head>
+<body>
+<pre id="log"></pre>
+<script>
+var log = document.getElementById("log");
+var source = new EventSource("/logs");
+source.onmessage = function(e) {
+  log.textContent += e.data + "\n";
+  window.scrollTo(0, document.body.scrollHeight);
+};
+source.addEventListener("end", function() {
+  log.textContent += "--- build finished ---\n";
+  source.close();
+});
+</script>
+</body>
+</html>
+`
+
+// logStream is a writer which streams the lines of the build log to the clients with Server-Sent Events.
+// The clients connecting in the middle of the build receive the last lines written before they connect,
+// which are kept in a ring buffer so that the memory does not grow with the build log.
+type logStream struct {
+	mutex   *sync.Mutex
+	partial []byte
+	lines   []string
+	history int
+	next    int
+	clients map[chan string]struct{}
+	done    chan struct{}
+}
+
+func newLogStream() *logStream {
+	return newLogStreamWithHistory(streamHistoryLines)
+}
+
+// newLogStreamWithHistory returns the stream keeping the last n lines.
+func newLogStreamWithHistory(n int) *logStream {
+	return &logStream{
+		mutex:   &sync.Mutex{},
+		history: n,
+		clients: make(map[chan string]struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// Write sends each line to the clients.
+// The last line without a newline is kept until the following write or Close completes it.
+func (s *logStream) Write(p []byte) (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	buf := append(s.partial, p...)
+	i := bytes.LastIndexByte(buf, '\n')
+	if i < 0 {
+		s.partial = buf
+		return len(p), nil
+	}
+	s.partial = append([]byte(nil), buf[i+1:]...)
+
+	for _, line := range bytes.Split(buf[:i], []byte("\n")) {
+		s.send(string(bytes.TrimSuffix(line, []byte("\r"))))
+	}
+
+	return len(p), nil
+}
+
+// send keeps the line and sends it to the clients. The caller must hold the mutex.
+func (s *logStream) send(l string) {
+	if len(s.lines) < s.history {
+		s.lines = append(s.lines, l)
+	} else if s.history != 0 {
+		// The oldest line is overwritten once the history is full
+		s.lines[s.next] = l
+		s.next = (s.next + 1) % len(s.lines)
+	}
+	for c := range s.clients {
+		select {
+		case c <- l:
+		default:
+			// The client which can't keep up is disconnected so that it doesn't block the build
+			delete(s.clients, c)
+			close(c)
+		}
+	}
+}
+
+// Close tells the clients that the build has finished.
+func (s *logStream) Close() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if len(s.partial) != 0 {
+		s.send(string(s.partial))
+		s.partial = nil
+	}
+
+	close(s.done)
+	for c := range s.clients {
+		delete(s.clients, c)
+		close(c)
+	}
+}
+
+// subscribe returns the last lines written so far and the channel receiving the following lines.
+// The channel is closed when the build finishes or the client is disconnected.
+func (s *logStream) subscribe() ([]string, chan string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	c := make(chan string, 1024)
+	select {
+	case <-s.done:
+		close(c)
+	default:
+		s.clients[c] = struct{}{}
+	}
+
+	lines := make([]string, 0, len(s.lines))
+	lines = append(lines, s.lines[s.next:]...)
+	lines = append(lines, s.lines[:s.next]...)
+	return lines, c
+}
+
+func (s *logStream) unsubscribe(c chan string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.clients[c]; ok {
+		delete(s.clients, c)
+		close(c)
+	}
+}
+
+func (s *logStream) serveLogs(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	lines, c := s.subscribe()
+	defer s.unsubscribe(c)
+
+	for _, l := range lines {
+		fmt.Fprintf(w, "data: %s\n\n", l)
+	}
+	flusher.Flush()
+
+	for {
+		select {
+		case l, ok := <-c:
+			if !ok {
+				select {
+				case <-s.done:
+					fmt.Fprint(w, "event: end\ndata: \n\n")
+					flusher.Flush()
+				default:
+				}
+				return
+			}
+			fmt.Fprintf(w, "data: %s\n\n", l)
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// Handler returns the handler serving the page following the log at / and the stream of the log at /logs.
+func (s *logStream) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/logs", s.serveLogs)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, streamPage)
+	})
+	return mux
+}
+
+// serveLogStream starts the HTTP server streaming the build log on addr.
+// The returned function ends the stream and stops the server.
+func serveLogStream(addr string, s *logStream) func() {
+	server := &http.Server{Addr: addr, Handler: s.Handler()}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logrus.Warnf("failed to serve build log: %v", err)
+		}
+	}()
+	logrus.Infof("Streaming build log on http://%s", addr)
+
+	return func() {
+		s.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), serveShutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(ctx); err != nil {
+			logrus.Warnf("failed to stop serving build log: %v", err)
+		}
+	}
+}
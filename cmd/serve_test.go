@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"bufio"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// readEvents reads the data of the events until the end event.
+func readEvents(t *testing.T, r *bufio.Reader, n int) []string {
+	t.Helper()
+
+	events := make([]string, 0, n)
+	for len(events) < n {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			continue
+		}
+		events = append(events, line)
+	}
+	return events
+}
+
+func TestLogStream(t *testing.T) {
+	s := newLogStream()
+	server := httptest.NewServer(s.Handler())
+	defer server.Close()
+
+	t.Run("success", func(t *testing.T) {
+		_, err := s.Write([]byte("install: npm install\n"))
+		assert.Nil(t, err)
+
+		res, err := http.Get(server.URL + "/logs")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+		assert.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+
+		r := bufio.NewReader(res.Body)
+		assert.Equal(t, []string{"data: install: npm install"}, readEvents(t, r, 1))
+
+		_, err = s.Write([]byte("test: npm test\ntest: passed\n"))
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"data: test: npm test", "data: test: passed"}, readEvents(t, r, 2))
+
+		s.Close()
+		assert.Equal(t, []string{"event: end", "data: "}, readEvents(t, r, 2))
+	})
+
+	t.Run("success after the build finished", func(t *testing.T) {
+		res, err := http.Get(server.URL + "/logs")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+
+		body, err := ioutil.ReadAll(res.Body)
+		assert.Nil(t, err)
+		assert.Equal(t, "data: install: npm install\n\ndata: test: npm test\n\ndata: test: passed\n\nevent: end\ndata: \n\n", string(body))
+	})
+
+	t.Run("success with page", func(t *testing.T) {
+		res, err := http.Get(server.URL + "/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+
+		body, err := ioutil.ReadAll(res.Body)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Contains(t, string(body), `new EventSource("/logs")`)
+	})
+
+	t.Run("failure by not existing path", func(t *testing.T) {
+		res, err := http.Get(server.URL + "/none")
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+}
+
+func TestLogStreamPartialLines(t *testing.T) {
+	s := newLogStream()
+
+	for _, p := range []string{"inst", "all: npm install\ntest: ", "npm test\r\n", "test: passed"} {
+		_, err := s.Write([]byte(p))
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, []string{"install: npm install", "test: npm test"}, s.lines)
+
+	s.Close()
+	assert.Equal(t, []string{"install: npm install", "test: npm test", "test: passed"}, s.lines)
+}
+
+func TestLogStreamHistory(t *testing.T) {
+	s := newLogStreamWithHistory(2)
+
+	_, err := s.Write([]byte("install: npm install\ntest: npm test\ntest: passed\n"))
+	assert.Nil(t, err)
+
+	lines, _ := s.subscribe()
+	assert.Equal(t, []string{"test: npm test", "test: passed"}, lines)
+
+	_, err = s.Write([]byte("publish: npm publish\n"))
+	assert.Nil(t, err)
+
+	lines, _ = s.subscribe()
+	assert.Equal(t, []string{"test: passed", "publish: npm publish"}, lines)
+	s.Close()
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// buildOnlyFlags are the flags of the build command which are only for running builds of the jobs,
+// so they are hidden in the commands derived from it.
+var buildOnlyFlags = []string{"interactive", "pause-on-failure", "reuse", "snapshot-after", "dry-run", "step", "from-step", "skip-step", "timeout", "step-timeout", "all", "continue-on-error", "parallel", "junit", "output", "log-format", "timestamps", "no-color", "serve", "local-api", "watch"}
+
+// newShellCmd returns the build command in interactive mode.
+// The build container is set up in the same way as the build, and the shell is attached instead of running steps.
+func newShellCmd() *cobra.Command {
+	shellCmd := newBuildCmd()
+	shellCmd.Use = "shell [job name]"
+	shellCmd.Short = "Open a shell in the build environment."
+	shellCmd.Long = `Open a shell in the build environment of the specified job name.
+The build container is set up with the same image, source directory, environment variables,
+secrets and caches as the build, and the shell is attached instead of running steps.
+This is the same as "sd-local build [job name] --interactive".`
+
+	args := shellCmd.Args
+	shellCmd.Args = func(cmd *cobra.Command, a []string) error {
+		interactiveMode = true
+		return args(cmd, a)
+	}
+
+	for _, name := range buildOnlyFlags {
+		shellCmd.Flags().MarkHidden(name)
+	}
+
+	return shellCmd
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/screwdriver-cd/sd-local/launch"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShellCmd(t *testing.T) {
+	defer func() {
+		interactiveMode = false
+	}()
+	origLaunchNew := launchNew
+	t.Cleanup(func() { launchNew = origLaunchNew })
+
+	t.Run("Success shell cmd", func(t *testing.T) {
+		root := newShellCmd()
+		root.SetArgs([]string{"test", "--env", "foo=bar"})
+		buf := bytes.NewBuffer(nil)
+		root.SetOut(buf)
+
+		launchNew = func(option launch.Option) launch.Launcher {
+			assert.True(t, option.InteractiveMode)
+			assert.Equal(t, "test", option.JobName)
+			assert.Equal(t, launch.EnvVar{"foo": "bar"}, option.OptionEnv)
+			return mockLaunch{}
+		}
+
+		err := root.Execute()
+		assert.Equal(t, "", buf.String())
+		assert.Nil(t, err)
+	})
+
+	t.Run("Failed shell cmd with multiple jobs", func(t *testing.T) {
+		root := newShellCmd()
+		root.SetArgs([]string{"main,test"})
+		buf := bytes.NewBuffer(nil)
+		root.SetOut(buf)
+		err := root.Execute()
+		assert.Equal(t, "interactive mode can be used only with a single job", err.Error())
+	})
+
+	t.Run("Failed shell cmd when too little args", func(t *testing.T) {
+		root := newShellCmd()
+		root.SetArgs([]string{})
+		buf := bytes.NewBuffer(nil)
+		root.SetOut(buf)
+		err := root.Execute()
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"github.com/screwdriver-cd/sd-local/config"
+	"github.com/screwdriver-cd/sd-local/launch"
+	"github.com/screwdriver-cd/sd-local/scm"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultSrcSizeWarning is the size of the source directory to warn about unless it is set in the config.
+const defaultSrcSizeWarning = "1g"
+
+var (
+	readCheckout    = scm.ReadCheckout
+	readSourceUsage = launch.ReadSourceUsage
+)
+
+// newSourceOption returns the information of the source read from the git checkout in srcPath.
+// The non-empty values of override, which are passed with the options, take precedence.
+func newSourceOption(srcPath string, override launch.SourceOption) launch.SourceOption {
+	checkout := readCheckout(srcPath)
+	source := launch.SourceOption{
+		URL:        checkout.URL,
+		Branch:     checkout.Branch,
+		SHA:        checkout.SHA,
+		PipelineID: override.PipelineID,
+	}
+
+	if override.URL != "" {
+		source.URL = override.URL
+	}
+	if override.Branch != "" {
+		source.Branch = override.Branch
+	}
+	if override.SHA != "" {
+		source.SHA = override.SHA
+	}
+
+	return source
+}
+
+// warnSourceSize warns that the source directory is so large that the build may be slow.
+func warnSourceSize(size int64, limit string) {
+	if limit == "" {
+		limit = defaultSrcSizeWarning
+	}
+
+	l, err := config.ParseSize(limit)
+	if err != nil {
+		logrus.Warn(err)
+		return
+	}
+
+	if size > l {
+		logrus.Warnf("The source directory is %d MB, which is larger than %s. Add the directories not used by the build to %s to exclude them", size>>20, limit, launch.IgnoreFile)
+	}
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/screwdriver-cd/sd-local/launch"
+	"github.com/screwdriver-cd/sd-local/scm"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSourceOption(t *testing.T) {
+	defer func() {
+		readCheckout = scm.ReadCheckout
+	}()
+	readCheckout = func(dir string) scm.Checkout {
+		assert.Equal(t, "/src", dir)
+		return scm.Checkout{URL: "git@github.com:screwdriver-cd/sd-local.git", Branch: "feature", SHA: "abc123"}
+	}
+
+	t.Run("success", func(t *testing.T) {
+		assert.Equal(t, launch.SourceOption{
+			URL:    "git@github.com:screwdriver-cd/sd-local.git",
+			Branch: "feature",
+			SHA:    "abc123",
+		}, newSourceOption("/src", launch.SourceOption{}))
+	})
+
+	t.Run("success with override", func(t *testing.T) {
+		assert.Equal(t, launch.SourceOption{
+			URL:        "git@github.com:screwdriver-cd/sd-local.git",
+			Branch:     "master",
+			SHA:        "def456",
+			PipelineID: 123,
+		}, newSourceOption("/src", launch.SourceOption{Branch: "master", SHA: "def456", PipelineID: 123}))
+	})
+}
+
+func TestWarnSourceSize(t *testing.T) {
+	defer logrus.SetOutput(os.Stderr)
+
+	testCases := []struct {
+		name   string
+		size   int64
+		limit  string
+		expect string
+	}{
+		{"small source", 1 << 20, "", ""},
+		{"large source", 2 << 30, "", "The source directory is 2048 MB, which is larger than 1g. Add the directories not used by the build to .sdlocalignore to exclude them"},
+		{"large source with config", 600 << 20, "500m", "The source directory is 600 MB, which is larger than 500m."},
+		{"invalid config", 1 << 20, "500x", "invalid size 500x"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := bytes.NewBuffer(nil)
+			logrus.SetOutput(buf)
+
+			warnSourceSize(tt.size, tt.limit)
+			if tt.expect == "" {
+				assert.Equal(t, "", buf.String())
+			} else {
+				assert.Contains(t, buf.String(), tt.expect)
+			}
+		})
+	}
+}
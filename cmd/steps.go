@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/screwdriver-cd/sd-local/launch"
+	"github.com/screwdriver-cd/sd-local/screwdriver"
+	"github.com/sirupsen/logrus"
+)
+
+func findStep(steps []screwdriver.Step, name string) (int, error) {
+	for i, s := range steps {
+		if s.Name == name {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("not found step '%s' in the job", name)
+}
+
+// selectSteps returns only the step named step, or the steps from the step named fromStep.
+// All steps are returned when both of them are empty.
+func selectSteps(steps []screwdriver.Step, step, fromStep string) ([]screwdriver.Step, error) {
+	switch {
+	case step != "":
+		i, err := findStep(steps, step)
+		if err != nil {
+			return nil, err
+		}
+		return steps[i : i+1], nil
+	case fromStep != "":
+		i, err := findStep(steps, fromStep)
+		if err != nil {
+			return nil, err
+		}
+		return steps[i:], nil
+	}
+
+	return steps, nil
+}
+
+// validateStepPatterns checks the glob patterns of the names of the steps to skip.
+func validateStepPatterns(patterns []string) error {
+	for _, p := range patterns {
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("invalid pattern of step '%s': %v", p, err)
+		}
+	}
+	return nil
+}
+
+// skipSteps removes the steps whose names match any of the glob patterns, and returns the names of the removed steps.
+func skipSteps(steps []screwdriver.Step, patterns []string) ([]screwdriver.Step, []string) {
+	kept := make([]screwdriver.Step, 0, len(steps))
+	skipped := make([]string, 0)
+
+	for _, s := range steps {
+		matched := false
+		for _, p := range patterns {
+			if ok, _ := path.Match(p, s.Name); ok {
+				matched = true
+				break
+			}
+		}
+
+		if matched {
+			skipped = append(skipped, s.Name)
+		} else {
+			kept = append(kept, s)
+		}
+	}
+
+	return kept, skipped
+}
+
+// stepResults returns the results of the steps run in the build, followed by the skipped steps.
+func stepResults(artifactsPath string, skipped []string) []launch.StepResult {
+	results, err := readStepResults(artifactsPath)
+	if err != nil {
+		logrus.Warn(err)
+	}
+
+	for _, name := range skipped {
+		results = append(results, launch.StepResult{Name: name, Status: launch.StepSkipped})
+	}
+
+	return results
+}
+
+// statusColors is the colours of the statuses of the steps in the summary.
+var statusColors = map[string]string{
+	launch.StepSuccess: "\x1b[32m",
+	launch.StepFailure: "\x1b[31m",
+	launch.StepSkipped: "\x1b[33m",
+}
+
+// colorStatus returns the status coloured for terminals unless the colours are disabled.
+func colorStatus(status string) string {
+	color, ok := statusColors[status]
+	if noColor || !ok {
+		return status
+	}
+	return color + status + "\x1b[0m"
+}
+
+// printStepSummary prints the results of the steps of each job with their exit codes and durations,
+// and the results of the teardown steps separately. Nothing is printed when there are no results.
+// The status is the last column so that its colour doesn't break the alignment of the table.
+func printStepSummary(out io.Writer, order []string, steps map[string][]launch.StepResult) {
+	tables := []struct {
+		header   string
+		teardown bool
+	}{
+		{"JOB\tSTEP\tEXIT CODE\tDURATION\tSTATUS", false},
+		{"JOB\tTEARDOWN\tEXIT CODE\tDURATION\tSTATUS", true},
+	}
+
+	printed := false
+	for _, table := range tables {
+		tw := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+		rows := 0
+		for _, name := range order {
+			for _, r := range steps[name] {
+				if launch.IsTeardown(r.Name) != table.teardown {
+					continue
+				}
+				if rows == 0 {
+					if printed {
+						fmt.Fprintln(tw)
+					}
+					fmt.Fprintln(tw, table.header)
+				}
+				exitCode, duration := "-", "-"
+				if r.Status != launch.StepSkipped {
+					exitCode, duration = strconv.Itoa(r.ExitCode), r.Duration.String()
+				}
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", name, r.Name, exitCode, duration, colorStatus(r.Status))
+				rows++
+			}
+		}
+		tw.Flush()
+		printed = printed || rows != 0
+	}
+}
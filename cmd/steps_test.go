@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/screwdriver-cd/sd-local/launch"
+	"github.com/screwdriver-cd/sd-local/screwdriver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectSteps(t *testing.T) {
+	steps := []screwdriver.Step{
+		{Name: "install", Command: "npm install"},
+		{Name: "test", Command: "npm test"},
+		{Name: "publish", Command: "npm publish"},
+	}
+
+	testCases := []struct {
+		name     string
+		step     string
+		fromStep string
+		expect   []screwdriver.Step
+		checkErr bool
+	}{
+		{
+			name:   "all steps",
+			expect: steps,
+		},
+		{
+			name:   "only the step",
+			step:   "test",
+			expect: []screwdriver.Step{{Name: "test", Command: "npm test"}},
+		},
+		{
+			name:     "from the step",
+			fromStep: "test",
+			expect:   []screwdriver.Step{{Name: "test", Command: "npm test"}, {Name: "publish", Command: "npm publish"}},
+		},
+		{
+			name:     "failure by not found step",
+			step:     "lint",
+			checkErr: true,
+		},
+		{
+			name:     "failure by not found from step",
+			fromStep: "lint",
+			checkErr: true,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := selectSteps(steps, tt.step, tt.fromStep)
+			if tt.checkErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.expect, actual)
+			}
+		})
+	}
+}
+
+func TestSkipSteps(t *testing.T) {
+	steps := []screwdriver.Step{
+		{Name: "install", Command: "npm install"},
+		{Name: "publish", Command: "npm publish"},
+		{Name: "notify-slack", Command: "notify slack"},
+		{Name: "notify-email", Command: "notify email"},
+	}
+
+	kept, skipped := skipSteps(steps, []string{"publish", "notify-*"})
+	assert.Equal(t, []screwdriver.Step{{Name: "install", Command: "npm install"}}, kept)
+	assert.Equal(t, []string{"publish", "notify-slack", "notify-email"}, skipped)
+
+	kept, skipped = skipSteps(steps, nil)
+	assert.Equal(t, steps, kept)
+	assert.Empty(t, skipped)
+
+	assert.Nil(t, validateStepPatterns([]string{"publish", "notify-*"}))
+	assert.NotNil(t, validateStepPatterns([]string{"notify-["}))
+}
+
+func TestStepResults(t *testing.T) {
+	defer func() { readStepResults = launch.ReadStepResults }()
+
+	readStepResults = func(artifactsPath string) ([]launch.StepResult, error) {
+		assert.Equal(t, "sd-artifacts/main", artifactsPath)
+		return []launch.StepResult{{Name: "install", Status: launch.StepSuccess}}, nil
+	}
+	assert.Equal(t, []launch.StepResult{
+		{Name: "install", Status: launch.StepSuccess},
+		{Name: "publish", Status: launch.StepSkipped},
+	}, stepResults("sd-artifacts/main", []string{"publish"}))
+
+	readStepResults = func(artifactsPath string) ([]launch.StepResult, error) {
+		return nil, errors.New("failed to read results of steps")
+	}
+	assert.Equal(t, []launch.StepResult{{Name: "publish", Status: launch.StepSkipped}}, stepResults("sd-artifacts/main", []string{"publish"}))
+}
+
+func TestPrintStepSummary(t *testing.T) {
+	// noColor is reset by the flag of the build commands created by the other tests
+	origNoColor := noColor
+	t.Cleanup(func() { noColor = origNoColor })
+	noColor = true
+
+	buf := bytes.NewBuffer(nil)
+	printStepSummary(buf, []string{"main", "test"}, map[string][]launch.StepResult{
+		"main": {
+			{Name: "install", Status: launch.StepSuccess, Duration: 12 * time.Second},
+			{Name: "test", Status: launch.StepFailure, ExitCode: 2, Duration: 3 * time.Second},
+			{Name: "teardown-notify", Status: launch.StepSuccess},
+			{Name: "publish", Status: launch.StepSkipped},
+		},
+		"test": {{Name: "publish", Status: launch.StepSkipped}},
+	})
+	assert.Equal(t, "JOB   STEP     EXIT CODE  DURATION  STATUS\n"+
+		"main  install  0          12s       SUCCESS\n"+
+		"main  test     2          3s        FAILURE\n"+
+		"main  publish  -          -         SKIPPED\n"+
+		"test  publish  -          -         SKIPPED\n"+
+		"\n"+
+		"JOB   TEARDOWN         EXIT CODE  DURATION  STATUS\n"+
+		"main  teardown-notify  0          0s        SUCCESS\n", buf.String())
+
+	buf = bytes.NewBuffer(nil)
+	printStepSummary(buf, []string{"main"}, map[string][]launch.StepResult{"main": {{Name: "teardown-notify", Status: launch.StepFailure, ExitCode: 1}}})
+	assert.Equal(t, "JOB   TEARDOWN         EXIT CODE  DURATION  STATUS\nmain  teardown-notify  1          0s        FAILURE\n", buf.String())
+
+	buf = bytes.NewBuffer(nil)
+	printStepSummary(buf, []string{"main"}, map[string][]launch.StepResult{"main": nil})
+	assert.Equal(t, "", buf.String())
+}
+
+func TestColorStatus(t *testing.T) {
+	origNoColor := noColor
+	t.Cleanup(func() { noColor = origNoColor })
+
+	noColor = false
+	assert.Equal(t, "\x1b[32mSUCCESS\x1b[0m", colorStatus(launch.StepSuccess))
+	assert.Equal(t, "\x1b[31mFAILURE\x1b[0m", colorStatus(launch.StepFailure))
+	assert.Equal(t, "\x1b[33mSKIPPED\x1b[0m", colorStatus(launch.StepSkipped))
+	assert.Equal(t, "UNKNOWN", colorStatus("UNKNOWN"))
+
+	noColor = true
+	assert.Equal(t, "SUCCESS", colorStatus(launch.StepSuccess))
+}
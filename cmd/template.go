@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/screwdriver-cd/sd-local/screwdriver"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// defaultTemplateFile is the template definition read when the path is omitted
+const defaultTemplateFile = "sd-template.yaml"
+
+func templateFileArg(args []string) string {
+	if len(args) == 1 {
+		return args[0]
+	}
+	return defaultTemplateFile
+}
+
+// cacheTemplates stores the templates used in screwdriver.yaml into dir, so that the jobs using them can be resolved in offline mode.
+// Failing to cache them doesn't stop the build, which is validated with Screwdriver.cd API.
+func cacheTemplates(api screwdriver.API, sdYAMLPath, dir string) {
+	// screwdriver.yaml which can not be read is reported by the validation of the job
+	refs, err := screwdriver.TemplateRefs(sdYAMLPath)
+	if err != nil {
+		return
+	}
+
+	for _, ref := range refs {
+		t, err := api.Template(ref)
+		if err != nil {
+			logrus.Warnf("failed to cache template %s: %v", ref, err)
+			continue
+		}
+		if err := screwdriver.SaveTemplate(dir, ref, t); err != nil {
+			logrus.Warnf("failed to cache template %s: %v", ref, err)
+		}
+	}
+}
+
+// inlineTemplateFile writes screwdriver.yaml whose jobs use the template in templateFile instead of the published one
+// into a temporary file, and returns its path. The caller must remove the file.
+func inlineTemplateFile(sdYAMLPath, templateFile string) (string, error) {
+	t, err := screwdriver.ReadTemplate(templateFile)
+	if err != nil {
+		return "", err
+	}
+
+	inlined, err := screwdriver.InlineTemplate(sdYAMLPath, t)
+	if err != nil {
+		return "", err
+	}
+
+	f, err := ioutil.TempFile("", "screwdriver*.yaml")
+	if err != nil {
+		return "", fmt.Errorf("failed to create screwdriver.yaml with template: %v", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(inlined); err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("failed to create screwdriver.yaml with template: %v", err)
+	}
+
+	logrus.Infof("Using template %s in %s instead of the published one", t.Name, templateFile)
+
+	return f.Name(), nil
+}
+
+func newTemplateValidateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate [path]",
+		Short: "Validate a template.",
+		Long: `Validate the template definition with Screwdriver.cd API.
+The path defaults to ` + defaultTemplateFile + ` in the current directory.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			api, err := remoteAPI()
+			if err != nil {
+				return err
+			}
+
+			t, err := api.ValidateTemplate(templateFileArg(args))
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Template %s@%s is valid\n", t.Name, t.Version)
+
+			return nil
+		},
+	}
+}
+
+func newTemplatePublishCmd() *cobra.Command {
+	var dryRun bool
+
+	templatePublishCmd := &cobra.Command{
+		Use:   "publish [path]",
+		Short: "Publish a template.",
+		Long: `Publish the template definition to Screwdriver.cd.
+The path defaults to ` + defaultTemplateFile + ` in the current directory.
+Screwdriver.cd may allow only the builds of the pipeline of the template to publish it.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			api, err := remoteAPI()
+			if err != nil {
+				return err
+			}
+
+			path := templateFileArg(args)
+			t, err := api.ValidateTemplate(path)
+			if err != nil {
+				return err
+			}
+
+			if dryRun {
+				fmt.Fprintf(cmd.OutOrStdout(), "Template %s@%s is valid, and is not published with --dry-run\n", t.Name, t.Version)
+				return nil
+			}
+
+			t, err = api.PublishTemplate(path)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Published template %s@%s\n", t.Name, t.Version)
+
+			return nil
+		},
+	}
+
+	templatePublishCmd.Flags().BoolVar(
+		&dryRun,
+		"dry-run",
+		false,
+		"Validate the template without publishing it.")
+
+	return templatePublishCmd
+}
+
+func newTemplateCmd() *cobra.Command {
+	templateCmd := &cobra.Command{
+		Use:   "template",
+		Short: "Develop templates.",
+		Long: `Validate and publish templates with Screwdriver.cd API of the current config.
+The jobs using the template can be run before it is published with ` + "`sd-local build --template-file`" + `.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := cmd.Help()
+			if err != nil {
+				return err
+			}
+			return nil
+		},
+	}
+
+	templateCmd.AddCommand(
+		newTemplateValidateCmd(),
+		newTemplatePublishCmd(),
+	)
+
+	return templateCmd
+}
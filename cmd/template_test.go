@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/screwdriver-cd/sd-local/screwdriver"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockTemplateAPI struct {
+	mockAPI
+	fetched []string
+}
+
+func (mock *mockTemplateAPI) Template(ref string) (screwdriver.Template, error) {
+	mock.fetched = append(mock.fetched, ref)
+	return screwdriver.Template{Name: "sd/noop", Version: "1.0.0"}, nil
+}
+
+func TestCacheTemplates(t *testing.T) {
+	dir, err := ioutil.TempDir("", "templates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	sdYAMLPath := filepath.Join(dir, "screwdriver.yaml")
+	yaml := "jobs:\n  main:\n    template: sd/noop@1\n  test:\n    template: sd/noop@1\n  publish:\n    image: alpine\n    steps:\n      - publish: echo publish\n"
+	if err := ioutil.WriteFile(sdYAMLPath, []byte(yaml), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("success", func(t *testing.T) {
+		api := &mockTemplateAPI{}
+		templateDir := filepath.Join(dir, "templates")
+		cacheTemplates(api, sdYAMLPath, templateDir)
+
+		assert.Equal(t, []string{"sd/noop@1"}, api.fetched)
+		files, err := ioutil.ReadDir(templateDir)
+		assert.Nil(t, err)
+		assert.Len(t, files, 1)
+	})
+
+	t.Run("success without screwdriver.yaml", func(t *testing.T) {
+		api := &mockTemplateAPI{}
+		cacheTemplates(api, filepath.Join(dir, "notFound.yaml"), filepath.Join(dir, "other"))
+
+		assert.Nil(t, api.fetched)
+		_, err := os.Stat(filepath.Join(dir, "other"))
+		assert.True(t, os.IsNotExist(err))
+	})
+}
+
+func TestInlineTemplateFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "templates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	sdYAMLPath := filepath.Join(dir, "screwdriver.yaml")
+	if err := ioutil.WriteFile(sdYAMLPath, []byte("jobs:\n  main:\n    template: sd/noop@1\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	templateFile := filepath.Join(dir, "sd-template.yaml")
+	if err := ioutil.WriteFile(templateFile, []byte("namespace: sd\nname: noop\nversion: 1.1.0\nconfig:\n  image: alpine\n  steps:\n    - noop: echo noop\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("success", func(t *testing.T) {
+		path, err := inlineTemplateFile(sdYAMLPath, templateFile)
+		assert.Nil(t, err)
+		defer os.Remove(path)
+
+		buf, err := ioutil.ReadFile(path)
+		assert.Nil(t, err)
+		assert.Equal(t, "jobs:\n  main:\n    image: alpine\n    steps:\n    - noop: echo noop\n", string(buf))
+	})
+
+	t.Run("failure by not found template file", func(t *testing.T) {
+		_, err := inlineTemplateFile(sdYAMLPath, filepath.Join(dir, "notFound.yaml"))
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "failed to read template")
+	})
+}
+
+type mockPublishAPI struct {
+	mockAPI
+	published bool
+}
+
+func (mock *mockPublishAPI) PublishTemplate(filePath string) (screwdriver.Template, error) {
+	mock.published = true
+	return mock.mockAPI.PublishTemplate(filePath)
+}
+
+func TestTemplateCmd(t *testing.T) {
+	origAPINew := apiNew
+	t.Cleanup(func() { apiNew = origAPINew })
+
+	testCases := []struct {
+		name      string
+		args      []string
+		want      string
+		published bool
+	}{
+		{name: "validate", args: []string{"validate"}, want: "Template sd/noop@1.1.0 is valid\n"},
+		{name: "validate with path", args: []string{"validate", "templates/sd-template.yaml"}, want: "Template sd/noop@1.1.0 is valid\n"},
+		{name: "publish", args: []string{"publish"}, want: "Published template sd/noop@1.1.3\n", published: true},
+		{name: "publish with --dry-run", args: []string{"publish", "--dry-run"}, want: "Template sd/noop@1.1.0 is valid, and is not published with --dry-run\n"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &mockPublishAPI{}
+			apiNew = func(url, token string, option screwdriver.Option) screwdriver.API { return api }
+
+			cmd := newTemplateCmd()
+			cmd.SetArgs(tt.args)
+			buf := bytes.NewBuffer(nil)
+			cmd.SetOut(buf)
+
+			err := cmd.Execute()
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, buf.String())
+			assert.Equal(t, tt.published, api.published)
+		})
+	}
+}
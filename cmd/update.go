@@ -2,25 +2,53 @@ package cmd
 
 import (
 	"bufio"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/blang/semver"
+	"github.com/mitchellh/go-homedir"
 	"github.com/rhysd/go-github-selfupdate/selfupdate"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
-const githubSlug = "screwdriver-cd/sd-local"
+const (
+	githubSlug = "screwdriver-cd/sd-local"
+	// updateCheckFileName is the file in ~/.sdlocal which records the latest release found by the daily check
+	updateCheckFileName = "update-check.json"
+	updateCheckInterval = 24 * time.Hour
+)
 
 var (
 	updateFlag   = false
-	detectLatest = selfupdate.DetectLatest
-	updateTo     = selfupdate.UpdateTo
+	detectLatest = func(slug string) (*selfupdate.Release, bool, error) {
+		updater, err := newUpdater()
+		if err != nil {
+			return nil, false, err
+		}
+		return updater.DetectLatest(slug)
+	}
+	updateTo = func(release *selfupdate.Release, cmdPath string) error {
+		updater, err := newUpdater()
+		if err != nil {
+			return err
+		}
+		return updater.UpdateTo(release, cmdPath)
+	}
 )
 
+// newUpdater returns the updater which verifies the downloaded binary with the SHA256 checksum released along with it,
+// which is the asset named <binary>.sha256, so that the releases without the checksum are not installed.
+func newUpdater() (*selfupdate.Updater, error) {
+	return selfupdate.NewUpdater(selfupdate.Config{Validator: &selfupdate.SHA2Validator{}})
+}
+
 func getLatestVersion() (*selfupdate.Release, error) {
 	latest, found, err := detectLatest(githubSlug)
 
@@ -90,17 +118,104 @@ func selfUpdate() error {
 		return err
 	}
 	logrus.Info("Updating ...")
-	if err := updateTo(latestVersion.AssetURL, exe); err != nil {
+	if err := updateTo(latestVersion, exe); err != nil {
 		return err
 	}
 	logrus.Info("Successfully updated to version ", latestVersion.Version)
 	return nil
 }
 
+// updateCheck is the result of the last check for a newer release.
+type updateCheck struct {
+	CheckedAt time.Time `json:"checkedAt"`
+	Latest    string    `json:"latest,omitempty"`
+}
+
+func readUpdateCheck(path string) updateCheck {
+	var check updateCheck
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return check
+	}
+	if err := json.Unmarshal(data, &check); err != nil {
+		logrus.Debugf("failed to parse %s: %v", path, err)
+	}
+	return check
+}
+
+// refreshUpdateCheck records the latest release. The time of the check is recorded even if it fails, such as offline,
+// so that the releases are not looked up by every command.
+func refreshUpdateCheck(path string, last updateCheck) {
+	check := updateCheck{CheckedAt: timeNow(), Latest: last.Latest}
+	if latest, found, err := detectLatest(githubSlug); err != nil {
+		logrus.Debugf("failed to check the latest release: %v", err)
+	} else if found {
+		check.Latest = latest.Version.String()
+	}
+
+	data, err := json.Marshal(check)
+	if err != nil {
+		return
+	}
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		logrus.Debugf("failed to write %s: %v", path, err)
+	}
+}
+
+// newerReleaseNotice returns the notice of the latest release if it is newer than the running one.
+func newerReleaseNotice(latest string) string {
+	if latest == "" || version == "dev" {
+		return ""
+	}
+	current, err := semver.Parse(version)
+	if err != nil {
+		return ""
+	}
+	v, err := semver.Parse(latest)
+	if err != nil || v.LTE(current) {
+		return ""
+	}
+	return fmt.Sprintf("A new version of sd-local is available: %s -> %s\n"+
+		`Run "sd-local update" to update, or "sd-local config set disable-update-check true" to disable this notice.`, current, v)
+}
+
+// checkUpdate returns the notice of the newer release found by the last check, and checks the latest release again
+// in the background if it was checked more than a day ago, so that the command does not wait for GitHub.
+// It is disabled with disable-update-check of the current config.
+func checkUpdate(cmd *cobra.Command) string {
+	if version == "dev" || cmd.Name() == "update" {
+		return ""
+	}
+
+	home, err := homedir.Dir()
+	if err != nil {
+		return ""
+	}
+	sdlocalDir := filepath.Join(home, ".sdlocal")
+	c, err := configNew(filepath.Join(sdlocalDir, "config"))
+	if err != nil {
+		return ""
+	}
+	current, err := c.Entry(c.Current)
+	if err != nil || current.DisableUpdateCheck {
+		return ""
+	}
+
+	path := filepath.Join(sdlocalDir, updateCheckFileName)
+	last := readUpdateCheck(path)
+	if timeNow().Sub(last.CheckedAt) >= updateCheckInterval {
+		go refreshUpdateCheck(path, last)
+	}
+	return newerReleaseNotice(last.Latest)
+}
+
 func newUpdateCmd() *cobra.Command {
 	updateCmd := &cobra.Command{
 		Use:   "update",
 		Short: "Update to the latest version",
+		Long: `Update sd-local to the latest release, whose binary is verified with the SHA256 checksum released along with it.
+A newer release is also checked once a day in the background by the other commands,
+which can be disabled with "sd-local config set disable-update-check true".`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return selfUpdate()
 		},
@@ -3,8 +3,11 @@ package cmd
 import (
 	"bytes"
 	"errors"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/blang/semver"
 	"github.com/rhysd/go-github-selfupdate/selfupdate"
@@ -100,7 +103,7 @@ func TestSelfUpdate(t *testing.T) {
 		}
 		return &selfupdate.Release{Version: latest}, true, nil
 	}
-	updateTo = func(url, path string) error {
+	updateTo = func(release *selfupdate.Release, path string) error {
 		return nil
 	}
 
@@ -163,3 +166,63 @@ func TestSelfUpdate(t *testing.T) {
 		})
 	}
 }
+
+func TestNewerReleaseNotice(t *testing.T) {
+	backupVersion := version
+	defer func() { version = backupVersion }()
+
+	testCases := []struct {
+		name    string
+		current string
+		latest  string
+		expect  string
+	}{
+		{name: "newer release", current: "1.0.4", latest: "1.0.5", expect: "A new version of sd-local is available: 1.0.4 -> 1.0.5\n" +
+			`Run "sd-local update" to update, or "sd-local config set disable-update-check true" to disable this notice.`},
+		{name: "latest release", current: "1.0.5", latest: "1.0.5", expect: ""},
+		{name: "not checked yet", current: "1.0.4", latest: "", expect: ""},
+		{name: "development version", current: "dev", latest: "1.0.5", expect: ""},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			version = tt.current
+			assert.Equal(t, tt.expect, newerReleaseNotice(tt.latest))
+		})
+	}
+}
+
+func TestRefreshUpdateCheck(t *testing.T) {
+	defaultDetectLatest := detectLatest
+	defer func() {
+		detectLatest = defaultDetectLatest
+		timeNow = time.Now
+	}()
+	checkedAt := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return checkedAt }
+
+	dir, err := ioutil.TempDir("", "update-check")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, updateCheckFileName)
+
+	t.Run("success", func(t *testing.T) {
+		detectLatest = func(slug string) (*selfupdate.Release, bool, error) {
+			return &selfupdate.Release{Version: semver.MustParse("1.0.5")}, true, nil
+		}
+
+		refreshUpdateCheck(path, updateCheck{})
+		assert.Equal(t, updateCheck{CheckedAt: checkedAt, Latest: "1.0.5"}, readUpdateCheck(path))
+	})
+
+	t.Run("failure keeps the last release", func(t *testing.T) {
+		detectLatest = func(slug string) (*selfupdate.Release, bool, error) {
+			return nil, false, errors.New("Failed finding validation file \"sd-local_linux_amd64.sha256\"")
+		}
+
+		refreshUpdateCheck(path, updateCheck{Latest: "1.0.4"})
+		assert.Equal(t, updateCheck{CheckedAt: checkedAt, Latest: "1.0.4"}, readUpdateCheck(path))
+	})
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/screwdriver-cd/sd-local/screwdriver"
+	"github.com/spf13/cobra"
+)
+
+// renderValidationMessage returns the message of the validation with the position of screwdriver.yaml which it refers to,
+// followed by the lines of screwdriver.yaml until the position with the caret at it.
+func renderValidationMessage(name, content, kind, message string) string {
+	p := screwdriver.MessagePosition(content, message)
+	if p.Line == 0 {
+		return fmt.Sprintf("%s: %s: %s\n", name, kind, message)
+	}
+
+	b := new(strings.Builder)
+	fmt.Fprintf(b, "%s:%d:%d: %s: %s\n", name, p.Line, p.Column, kind, message)
+
+	lines := strings.Split(content, "\n")
+	width := len(strconv.Itoa(p.Line))
+	for n := p.Line - 1; n <= p.Line; n++ {
+		if n >= 1 {
+			fmt.Fprintf(b, " %*d | %s\n", width, n, lines[n-1])
+		}
+	}
+	fmt.Fprintf(b, " %*s | %s^\n", width, "", strings.Repeat(" ", p.Column-1))
+
+	return b.String()
+}
+
+// validatedJobs returns the jobs in screwdriver.yaml after printing its validation warnings into out, which fail the build in strict mode.
+// The errors are also printed into out.
+func validatedJobs(api screwdriver.API, sdYAMLPath string, strict bool, out io.Writer) (map[string]screwdriver.Job, error) {
+	v, err := api.Validate(sdYAMLPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(v.Errors) == 0 && len(v.Warnings) == 0 {
+		return v.Jobs, nil
+	}
+
+	content, _ := ioutil.ReadFile(sdYAMLPath)
+	printValidation(out, "screwdriver.yaml", string(content), v)
+
+	if len(v.Errors) != 0 {
+		return nil, fmt.Errorf("failed to parse screwdriver.yaml: %d errors", len(v.Errors))
+	}
+	if strict {
+		return nil, fmt.Errorf("screwdriver.yaml has %d warnings, which fail the build with --strict", len(v.Warnings))
+	}
+
+	return v.Jobs, nil
+}
+
+// printValidation prints the warnings and the errors of screwdriver.yaml with the positions which they refer to.
+// content is the content of screwdriver.yaml, which is printed as name.
+func printValidation(out io.Writer, name, content string, v screwdriver.Validation) {
+	printMessages := func(kind string, messages []string) {
+		for _, m := range messages {
+			fmt.Fprint(out, renderValidationMessage(name, content, kind, m))
+		}
+	}
+
+	printMessages("warning", v.Warnings)
+	printMessages("error", v.Errors)
+}
+
+func newValidateCmd() *cobra.Command {
+	var offline bool
+
+	validateCmd := &cobra.Command{
+		Use:   "validate [path]",
+		Short: "Validate screwdriver.yaml without running the builds.",
+		Long: `Validate screwdriver.yaml with Screwdriver.cd API and print its warnings and errors with the lines of screwdriver.yaml which they refer to.
+The path of screwdriver.yaml defaults to the one in the current directory.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			sdYAMLPath, err := screwdriverYAMLPath(args)
+			if err != nil {
+				return err
+			}
+			name := "screwdriver.yaml"
+			if len(args) != 0 {
+				name = args[0]
+			}
+
+			api, _, err := validatorAPI(offline)
+			if err != nil {
+				return err
+			}
+
+			v, err := api.Validate(sdYAMLPath)
+			if err != nil {
+				return err
+			}
+
+			// The lines are not printed when screwdriver.yaml has been removed after the validation
+			content, _ := ioutil.ReadFile(sdYAMLPath)
+			printValidation(cmd.OutOrStdout(), name, string(content), v)
+
+			if len(v.Errors) != 0 {
+				return fmt.Errorf("%s has %d errors", name, len(v.Errors))
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "%s is valid: %d jobs\n", name, len(v.Jobs))
+			return nil
+		},
+	}
+
+	validateCmd.Flags().BoolVar(
+		&offline,
+		"offline",
+		false,
+		"Validate screwdriver.yaml locally without calling Screwdriver.cd API. Only the templates cached by the previous builds can be used in offline mode.")
+
+	return validateCmd
+}
@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/screwdriver-cd/sd-local/screwdriver"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockInvalidAPI struct {
+	mockAPI
+}
+
+func (mock mockInvalidAPI) Validate(filePath string) (screwdriver.Validation, error) {
+	return screwdriver.Validation{
+		Errors:   []string{`"jobs.main.image" is required`},
+		Warnings: []string{"deprecated syntax"},
+	}, nil
+}
+
+type mockWarningsAPI struct {
+	mockAPI
+}
+
+func (mock mockWarningsAPI) Validate(filePath string) (screwdriver.Validation, error) {
+	jobs, _ := mock.Jobs(filePath)
+	return screwdriver.Validation{Jobs: jobs, Warnings: []string{"jobs.main.annotations.screwdriver.cd/unknown is not a valid annotation"}}, nil
+}
+
+func TestValidatedJobs(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		buf := bytes.NewBuffer(nil)
+		jobs, err := validatedJobs(mockAPI{}, "screwdriver.yaml", true, buf)
+		assert.Nil(t, err)
+		assert.Equal(t, 3, len(jobs))
+		assert.Equal(t, "", buf.String())
+	})
+
+	t.Run("success with warnings", func(t *testing.T) {
+		buf := bytes.NewBuffer(nil)
+		jobs, err := validatedJobs(mockWarningsAPI{}, "screwdriver.yaml", false, buf)
+		assert.Nil(t, err)
+		assert.Equal(t, 3, len(jobs))
+		assert.Equal(t, "screwdriver.yaml: warning: jobs.main.annotations.screwdriver.cd/unknown is not a valid annotation\n", buf.String())
+	})
+
+	t.Run("failure by warnings in strict mode", func(t *testing.T) {
+		_, err := validatedJobs(mockWarningsAPI{}, "screwdriver.yaml", true, bytes.NewBuffer(nil))
+		assert.Equal(t, "screwdriver.yaml has 1 warnings, which fail the build with --strict", err.Error())
+	})
+
+	t.Run("failure by errors", func(t *testing.T) {
+		buf := bytes.NewBuffer(nil)
+		_, err := validatedJobs(mockInvalidAPI{}, "screwdriver.yaml", false, buf)
+		assert.Equal(t, "failed to parse screwdriver.yaml: 1 errors", err.Error())
+		assert.Equal(t, "screwdriver.yaml: warning: deprecated syntax\nscrewdriver.yaml: error: \"jobs.main.image\" is required\n", buf.String())
+	})
+}
+
+func TestPrintValidation(t *testing.T) {
+	content := "jobs:\n    main:\n        image: node:18\n        annotations:\n            screwdriver.cd/unknown: true\n"
+
+	buf := bytes.NewBuffer(nil)
+	printValidation(buf, "screwdriver.yaml", content, screwdriver.Validation{
+		Errors:   []string{"job 'main' has no steps"},
+		Warnings: []string{"jobs.main.annotations is not valid", "deprecated syntax"},
+	})
+
+	assert.Equal(t, `screwdriver.yaml:4:9: warning: jobs.main.annotations is not valid
+ 3 |         image: node:18
+ 4 |         annotations:
+   |         ^
+screwdriver.yaml: warning: deprecated syntax
+screwdriver.yaml:2:5: error: job 'main' has no steps
+ 1 | jobs:
+ 2 |     main:
+   |     ^
+`, buf.String())
+}
+
+func TestRenderValidationMessage(t *testing.T) {
+	content := "jobs:\n    main:\n        image: node:18\n        steps:\n            - test: npm test\n"
+
+	testCases := []struct {
+		name    string
+		message string
+		expect  string
+	}{
+		{
+			name:    "first line",
+			message: `"jobs" must have at least 1 job`,
+			expect:  "screwdriver.yaml:1:1: error: \"jobs\" must have at least 1 job\n 1 | jobs:\n   | ^\n",
+		},
+		{
+			name:    "line and column",
+			message: "YAMLException: bad indentation of a mapping entry at line 5, column 15",
+			expect:  "screwdriver.yaml:5:15: error: YAMLException: bad indentation of a mapping entry at line 5, column 15\n 4 |         steps:\n 5 |             - test: npm test\n   |               ^\n",
+		},
+		{
+			name:    "unknown position",
+			message: "validate error",
+			expect:  "screwdriver.yaml: error: validate error\n",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expect, renderValidationMessage("screwdriver.yaml", content, "error", tt.message))
+		})
+	}
+}
+
+func TestValidateCmd(t *testing.T) {
+	dir, err := ioutil.TempDir("", "validate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	sdYAMLPath := filepath.Join(dir, "screwdriver.yaml")
+	if err := ioutil.WriteFile(sdYAMLPath, []byte("jobs:\n    main:\n        steps:\n            - test: npm test\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	origLocalAPINew := localAPINew
+	t.Cleanup(func() { localAPINew = origLocalAPINew })
+
+	t.Run("success", func(t *testing.T) {
+		localAPINew = func(templateDir string) screwdriver.API { return mockAPI{} }
+
+		cmd := newValidateCmd()
+		cmd.SetArgs([]string{sdYAMLPath, "--offline"})
+		buf := bytes.NewBuffer(nil)
+		cmd.SetOut(buf)
+
+		err := cmd.Execute()
+		assert.Nil(t, err)
+		assert.Equal(t, sdYAMLPath+" is valid: 3 jobs\n", buf.String())
+	})
+
+	t.Run("failure by errors", func(t *testing.T) {
+		localAPINew = func(templateDir string) screwdriver.API { return mockInvalidAPI{} }
+
+		cmd := newValidateCmd()
+		cmd.SetArgs([]string{sdYAMLPath, "--offline"})
+		buf := bytes.NewBuffer(nil)
+		cmd.SetOut(buf)
+
+		err := cmd.Execute()
+		assert.Equal(t, sdYAMLPath+" has 1 errors", err.Error())
+		assert.Equal(t, sdYAMLPath+": warning: deprecated syntax\n"+sdYAMLPath+":2:5: error: \"jobs.main.image\" is required\n 1 | jobs:\n 2 |     main:\n   |     ^\n"+
+			"Error: "+sdYAMLPath+" has 1 errors\n", buf.String())
+	})
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"strings"
+	"time"
+
+	"github.com/screwdriver-cd/sd-local/launch"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	sourceDigest = launch.SourceDigest
+	// watchInterval is the interval of polling the source directory, and the time for which it must be unchanged before the build is run
+	watchInterval = time.Second
+)
+
+// watchArgs returns the arguments of the build run on each change, which are args without --watch.
+// --reuse is added so that the builds after the first one run in the warm build container,
+// unless it is already set or can not be used with the other flags.
+func watchArgs(args []string, reusable bool) []string {
+	watched := make([]string, 0, len(args)+1)
+	hasReuse := false
+	for _, a := range args {
+		if a == "--watch" || strings.HasPrefix(a, "--watch=") {
+			continue
+		}
+		if a == "--reuse" || strings.HasPrefix(a, "--reuse=") {
+			hasReuse = true
+		}
+		watched = append(watched, a)
+	}
+
+	if reusable && !hasReuse {
+		watched = append(watched, "--reuse")
+	}
+	return watched
+}
+
+// waitForChange polls the source directory until its digest differs from last and stays the same for watchInterval,
+// so that the build is run once for the files saved at the same time, and returns the new digest.
+func waitForChange(srcPath string, excludes []string, last string) (string, error) {
+	for {
+		time.Sleep(watchInterval)
+		digest, err := sourceDigest(srcPath, excludes)
+		if err != nil {
+			return "", err
+		}
+		if digest == last {
+			continue
+		}
+
+		for {
+			time.Sleep(watchInterval)
+			settled, err := sourceDigest(srcPath, excludes)
+			if err != nil {
+				return "", err
+			}
+			if settled == digest {
+				return digest, nil
+			}
+			digest = settled
+		}
+	}
+}
+
+// watchBuild runs sd-local with args in dir, and runs it again whenever the files in the source directory change
+// until it is interrupted. The failed builds do not stop watching.
+func watchBuild(dir, srcPath string, excludes []string, args []string) error {
+	digest, err := sourceDigest(srcPath, excludes)
+	if err != nil {
+		return err
+	}
+
+	for {
+		if err := execSDLocal(dir, args); err != nil {
+			logrus.Warnf("The build failed: %v", err)
+		}
+
+		logrus.Infof("Watching %s for changes. Press Ctrl-C to stop.", srcPath)
+		digest, err = waitForChange(srcPath, excludes, digest)
+		if err != nil {
+			return err
+		}
+		logrus.Info("Files changed, running the build again")
+	}
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/screwdriver-cd/sd-local/launch"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchArgs(t *testing.T) {
+	testCases := []struct {
+		name     string
+		args     []string
+		reusable bool
+		expect   []string
+	}{
+		{name: "with reuse", args: []string{"build", "test", "--watch"}, reusable: true, expect: []string{"build", "test", "--reuse"}},
+		{name: "with explicit watch value", args: []string{"build", "--watch=true", "test"}, reusable: true, expect: []string{"build", "test", "--reuse"}},
+		{name: "already reused", args: []string{"build", "test", "--reuse", "--watch"}, reusable: true, expect: []string{"build", "test", "--reuse"}},
+		{name: "not reusable", args: []string{"build", "test", "--watch", "--pause-on-failure"}, reusable: false, expect: []string{"build", "test", "--pause-on-failure"}},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expect, watchArgs(tt.args, tt.reusable))
+		})
+	}
+}
+
+func TestWaitForChange(t *testing.T) {
+	defer func() {
+		sourceDigest = launch.SourceDigest
+		watchInterval = time.Second
+	}()
+	watchInterval = time.Millisecond
+
+	dir, err := ioutil.TempDir("", "watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// The digest changes twice and then settles, so that the build is run once for both changes
+	digests := []string{"a", "a", "b", "c", "c"}
+	calls := 0
+	sourceDigest = func(srcPath string, excludes []string) (string, error) {
+		assert.Equal(t, dir, srcPath)
+		assert.Equal(t, []string{filepath.Join(dir, "sd-artifacts")}, excludes)
+		d := digests[calls]
+		calls++
+		return d, nil
+	}
+
+	digest, err := waitForChange(dir, []string{filepath.Join(dir, "sd-artifacts")}, "a")
+	assert.Nil(t, err)
+	assert.Equal(t, "c", digest)
+	assert.Equal(t, 5, calls)
+}
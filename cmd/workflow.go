@@ -0,0 +1,263 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/screwdriver-cd/sd-local/buildlog"
+	"github.com/screwdriver-cd/sd-local/launch"
+	"github.com/screwdriver-cd/sd-local/screwdriver"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	jobSuccess = "SUCCESS"
+	jobFailure = "FAILURE"
+	jobSkipped = "SKIPPED"
+)
+
+type jobResult struct {
+	name     string
+	status   string
+	duration time.Duration
+	meta     launch.Meta
+	steps    []launch.StepResult
+	err      error
+}
+
+type workflow struct {
+	option          launch.Option
+	jobs            map[string]screwdriver.Job
+	parallel        int
+	continueOnError bool
+	skippedSteps    map[string][]string
+	remote          *remoteCache
+	stdout          io.Writer
+	mutex           *sync.Mutex
+}
+
+// selectJobs returns the jobs named in names. An empty names means all jobs.
+func selectJobs(jobs map[string]screwdriver.Job, names []string) (map[string]screwdriver.Job, error) {
+	if len(names) == 0 {
+		return jobs, nil
+	}
+
+	selected := make(map[string]screwdriver.Job, len(names))
+	for _, name := range names {
+		job, ok := jobs[name]
+		if !ok {
+			return nil, fmt.Errorf("not found '%s' in parsed screwdriver.yaml", name)
+		}
+		selected[name] = job
+	}
+
+	return selected, nil
+}
+
+// runWorkflow runs the jobs along with the workflow.
+// Jobs which don't depend on each other are run at the same time up to parallel.
+// Artifacts of each job are stored in the sub directory named after the job.
+// Each job gets the metadata of its upstream jobs merged in the order of the workflow.
+// skippedSteps is the names of the steps removed from each job, which are reported in the summary.
+// The build logs are written into logWriter, and the summaries are written into out.
+func runWorkflow(option launch.Option, jobs map[string]screwdriver.Job, skippedSteps map[string][]string, parallel int, continueOnError bool, remote *remoteCache, logWriter, out io.Writer) error {
+	order, err := screwdriver.WorkflowOrder(jobs)
+	if err != nil {
+		return err
+	}
+
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	w := &workflow{
+		option:          option,
+		jobs:            jobs,
+		parallel:        parallel,
+		continueOnError: continueOnError,
+		skippedSteps:    skippedSteps,
+		remote:          remote,
+		stdout:          logWriter,
+		mutex:           &sync.Mutex{},
+	}
+
+	results := w.run(order)
+	printSummary(out, order, results)
+
+	steps := make(map[string][]launch.StepResult, len(order))
+	for _, name := range order {
+		if r := results[name]; len(r.steps) != 0 {
+			steps[name] = r.steps
+		}
+	}
+	if len(steps) != 0 {
+		fmt.Fprintln(out)
+		printStepSummary(out, order, steps)
+	}
+
+	failed := make([]string, 0)
+	for _, name := range order {
+		if r := results[name]; r.status == jobFailure {
+			if !continueOnError {
+				return fmt.Errorf("job %s failed: %v", name, r.err)
+			}
+			failed = append(failed, name)
+		}
+	}
+
+	if len(failed) != 0 {
+		return fmt.Errorf("failed jobs: %v", failed)
+	}
+
+	return nil
+}
+
+func (w *workflow) run(order []string) map[string]jobResult {
+	results := make(map[string]jobResult, len(order))
+	started := make(map[string]bool, len(order))
+	done := make(chan jobResult)
+	running := 0
+	stopped := false
+
+	for {
+		for _, name := range order {
+			if stopped || running >= w.parallel {
+				break
+			}
+			if started[name] {
+				continue
+			}
+			ready, skipped := w.runnable(name, results)
+			if skipped {
+				started[name] = true
+				results[name] = jobResult{name: name, status: jobSkipped}
+				continue
+			}
+			if !ready {
+				continue
+			}
+
+			started[name] = true
+			running++
+			meta := w.upstreamMeta(name, order, results)
+			go func(name string) {
+				done <- w.runJob(name, meta)
+			}(name)
+		}
+
+		if running == 0 {
+			break
+		}
+
+		r := <-done
+		running--
+		results[r.name] = r
+		if r.status == jobFailure && !w.continueOnError {
+			stopped = true
+		}
+	}
+
+	for _, name := range order {
+		if _, ok := results[name]; !ok {
+			results[name] = jobResult{name: name, status: jobSkipped}
+		}
+	}
+
+	return results
+}
+
+// runnable reports whether all upstream jobs of the job have finished successfully.
+// skipped is true when any of them has not succeeded, since the job must not run then.
+func (w *workflow) runnable(name string, results map[string]jobResult) (ready, skipped bool) {
+	ready = true
+	for _, up := range screwdriver.UpstreamJobs(w.jobs[name], w.jobs) {
+		r, ok := results[up]
+		if !ok {
+			ready = false
+			continue
+		}
+		if r.status != jobSuccess {
+			return false, true
+		}
+	}
+	return ready, false
+}
+
+// upstreamMeta returns the metadata passed into the job.
+// The initial metadata is used for the jobs which have no upstream jobs.
+func (w *workflow) upstreamMeta(name string, order []string, results map[string]jobResult) launch.Meta {
+	upstream := make(map[string]bool)
+	for _, up := range screwdriver.UpstreamJobs(w.jobs[name], w.jobs) {
+		upstream[up] = true
+	}
+
+	if len(upstream) == 0 {
+		return w.option.Meta
+	}
+
+	meta := w.option.Meta
+	for _, up := range order {
+		if upstream[up] {
+			meta = meta.Merge(results[up].meta)
+		}
+	}
+
+	return meta
+}
+
+func (w *workflow) runJob(name string, meta launch.Meta) jobResult {
+	logrus.Infof("Start job %s", name)
+
+	o := w.option
+	o.Job = w.jobs[name]
+	o.JobName = name
+	o.ArtifactsPath = filepath.Join(w.option.ArtifactsPath, name)
+	o.MetaPath = filepath.Join(o.ArtifactsPath, launch.MetaDir)
+	o.Meta = meta
+
+	// The lines in JSON format have the job name instead of the prefix
+	var writer io.Writer = w.stdout
+	if w.parallel > 1 && logFormat != string(buildlog.FormatJSON) {
+		writer = buildlog.NewPrefixWriter(w.stdout, fmt.Sprintf("[%s] ", name), w.mutex)
+	}
+
+	start := time.Now()
+	err := w.remote.runJobWithCache(o, w.skippedSteps[name], writer)
+	r := jobResult{
+		name:     name,
+		status:   jobSuccess,
+		duration: time.Since(start),
+		steps:    stepResults(o.ArtifactsPath, w.skippedSteps[name]),
+		err:      err,
+	}
+
+	if err == nil {
+		r.meta, err = readMeta(o.MetaPath)
+		r.err = err
+	}
+
+	if err != nil {
+		logrus.Errorf("job %s failed: %v", name, err)
+		r.status = jobFailure
+	}
+
+	return r
+}
+
+func printSummary(out io.Writer, order []string, results map[string]jobResult) {
+	tw := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "JOB\tSTATUS\tDURATION")
+	for _, name := range order {
+		r := results[name]
+		duration := "-"
+		if r.status != jobSkipped {
+			duration = r.duration.Round(time.Second).String()
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", name, r.status, duration)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,262 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/screwdriver-cd/sd-local/launch"
+	"github.com/screwdriver-cd/sd-local/screwdriver"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockSlowLaunch struct {
+	mockLaunch
+	running *int
+	max     *int
+	mutex   *sync.Mutex
+}
+
+func (mock mockSlowLaunch) Run() error {
+	mock.mutex.Lock()
+	*mock.running++
+	if *mock.running > *mock.max {
+		*mock.max = *mock.running
+	}
+	mock.mutex.Unlock()
+
+	time.Sleep(50 * time.Millisecond)
+
+	mock.mutex.Lock()
+	*mock.running--
+	mock.mutex.Unlock()
+	return nil
+}
+
+func TestSelectJobs(t *testing.T) {
+	jobs := map[string]screwdriver.Job{
+		"main": {},
+		"test": {},
+		"lint": {},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		selected, err := selectJobs(jobs, []string{"lint", "test"})
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]screwdriver.Job{"lint": {}, "test": {}}, selected)
+	})
+
+	t.Run("success with all jobs", func(t *testing.T) {
+		selected, err := selectJobs(jobs, []string{})
+		assert.Nil(t, err)
+		assert.Equal(t, jobs, selected)
+	})
+
+	t.Run("failure by unknown job", func(t *testing.T) {
+		_, err := selectJobs(jobs, []string{"lint", "nyancat"})
+		assert.Equal(t, "not found 'nyancat' in parsed screwdriver.yaml", err.Error())
+	})
+}
+
+func TestRunWorkflow(t *testing.T) {
+	// The summaries are compared without colours, which are enabled by the flag of the build commands created by the other tests
+	origNoColor := noColor
+	t.Cleanup(func() { noColor = origNoColor })
+	noColor = true
+
+	t.Run("success with independent jobs in parallel", func(t *testing.T) {
+		running, max := 0, 0
+		mutex := &sync.Mutex{}
+		origLaunchNew := launchNew
+		t.Cleanup(func() { launchNew = origLaunchNew })
+		launchNew = func(option launch.Option) launch.Launcher {
+			return mockSlowLaunch{running: &running, max: &max, mutex: mutex}
+		}
+
+		jobs := map[string]screwdriver.Job{
+			"lint":  {},
+			"test":  {},
+			"build": {},
+		}
+
+		buf := bytes.NewBuffer(nil)
+		err := runWorkflow(launch.Option{ArtifactsPath: "sd-artifacts"}, jobs, nil, 2, false, nil, os.Stdout, buf)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, max)
+		assert.Contains(t, buf.String(), "JOB    STATUS   DURATION\n")
+		assert.Contains(t, buf.String(), "build  SUCCESS  ")
+		assert.Contains(t, buf.String(), "lint   SUCCESS  ")
+		assert.Contains(t, buf.String(), "test   SUCCESS  ")
+	})
+
+	t.Run("success with dependent jobs", func(t *testing.T) {
+		mutex := &sync.Mutex{}
+		jobNames := make([]string, 0)
+		origLaunchNew := launchNew
+		t.Cleanup(func() { launchNew = origLaunchNew })
+		launchNew = func(option launch.Option) launch.Launcher {
+			mutex.Lock()
+			defer mutex.Unlock()
+			jobNames = append(jobNames, option.JobName)
+			return mockLaunch{}
+		}
+
+		jobs := map[string]screwdriver.Job{
+			"main":    {Requires: []string{"~commit"}},
+			"test":    {Requires: []string{"main"}},
+			"publish": {Requires: []string{"test"}},
+		}
+
+		err := runWorkflow(launch.Option{ArtifactsPath: "sd-artifacts"}, jobs, nil, 4, false, nil, os.Stdout, bytes.NewBuffer(nil))
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"main", "test", "publish"}, jobNames)
+	})
+
+	t.Run("success passes meta into downstream jobs", func(t *testing.T) {
+		defer func() {
+			readMeta = launch.ReadMeta
+		}()
+
+		mutex := &sync.Mutex{}
+		metas := make(map[string]launch.Meta)
+		origLaunchNew := launchNew
+		t.Cleanup(func() { launchNew = origLaunchNew })
+		launchNew = func(option launch.Option) launch.Launcher {
+			mutex.Lock()
+			defer mutex.Unlock()
+			metas[option.JobName] = option.Meta
+			return mockLaunch{}
+		}
+		readMeta = func(dir string) (launch.Meta, error) {
+			switch dir {
+			case filepath.Join("sd-artifacts", "lint", launch.MetaDir):
+				return launch.Meta{"seed": "foo", "lint": "done", "status": "lint"}, nil
+			case filepath.Join("sd-artifacts", "test", launch.MetaDir):
+				return launch.Meta{"seed": "foo", "test": "done", "status": "test"}, nil
+			}
+			return launch.Meta{}, nil
+		}
+
+		jobs := map[string]screwdriver.Job{
+			"lint":    {Requires: []string{"~commit"}},
+			"test":    {Requires: []string{"~commit"}},
+			"publish": {Requires: []string{"lint", "test"}},
+		}
+
+		option := launch.Option{ArtifactsPath: "sd-artifacts", Meta: launch.Meta{"seed": "foo"}}
+		err := runWorkflow(option, jobs, nil, 4, false, nil, os.Stdout, bytes.NewBuffer(nil))
+		assert.Nil(t, err)
+		assert.Equal(t, launch.Meta{"seed": "foo"}, metas["lint"])
+		assert.Equal(t, launch.Meta{"seed": "foo"}, metas["test"])
+		assert.Equal(t, launch.Meta{"seed": "foo", "lint": "done", "test": "done", "status": "test"}, metas["publish"])
+	})
+
+	t.Run("failure by invalid meta", func(t *testing.T) {
+		defer func() {
+			readMeta = launch.ReadMeta
+		}()
+
+		origLaunchNew := launchNew
+		t.Cleanup(func() { launchNew = origLaunchNew })
+		launchNew = func(option launch.Option) launch.Launcher { return mockLaunch{} }
+		readMeta = func(dir string) (launch.Meta, error) {
+			return nil, errors.New("failed to parse meta")
+		}
+
+		jobs := map[string]screwdriver.Job{
+			"main": {Requires: []string{"~commit"}},
+			"test": {Requires: []string{"main"}},
+		}
+
+		buf := bytes.NewBuffer(nil)
+		err := runWorkflow(launch.Option{ArtifactsPath: "sd-artifacts"}, jobs, nil, 4, false, nil, os.Stdout, buf)
+		assert.Equal(t, "job main failed: failed to parse meta", err.Error())
+		assert.Contains(t, buf.String(), "test  SKIPPED  -\n")
+	})
+
+	t.Run("success with skipped steps", func(t *testing.T) {
+		origLaunchNew := launchNew
+		t.Cleanup(func() { launchNew = origLaunchNew })
+		launchNew = func(option launch.Option) launch.Launcher { return mockLaunch{} }
+
+		jobs := map[string]screwdriver.Job{
+			"main": {Requires: []string{"~commit"}},
+			"test": {Requires: []string{"main"}},
+		}
+		skippedSteps := map[string][]string{"test": {"publish"}}
+
+		buf := bytes.NewBuffer(nil)
+		err := runWorkflow(launch.Option{ArtifactsPath: "sd-artifacts"}, jobs, skippedSteps, 4, false, nil, os.Stdout, buf)
+		assert.Nil(t, err)
+		assert.Contains(t, buf.String(), "test  SUCCESS  ")
+		assert.Contains(t, buf.String(), "\n\nJOB   STEP     EXIT CODE  DURATION  STATUS\ntest  publish  -          -         SKIPPED\n")
+	})
+
+	t.Run("failure skips the rest of jobs", func(t *testing.T) {
+		origLaunchNew := launchNew
+		t.Cleanup(func() { launchNew = origLaunchNew })
+		launchNew = func(option launch.Option) launch.Launcher {
+			if option.JobName == "main" {
+				return mockFailedLaunch{}
+			}
+			return mockLaunch{}
+		}
+
+		jobs := map[string]screwdriver.Job{
+			"main": {Requires: []string{"~commit"}},
+			"test": {Requires: []string{"main"}},
+		}
+
+		buf := bytes.NewBuffer(nil)
+		err := runWorkflow(launch.Option{ArtifactsPath: "sd-artifacts"}, jobs, nil, 4, false, nil, os.Stdout, buf)
+		assert.Equal(t, "job main failed: build failed", err.Error())
+		assert.Contains(t, buf.String(), "main  FAILURE  ")
+		assert.Contains(t, buf.String(), "test  SKIPPED  -\n")
+	})
+
+	t.Run("failure with continue on error skips only the downstream jobs", func(t *testing.T) {
+		mutex := &sync.Mutex{}
+		jobNames := make([]string, 0)
+		origLaunchNew := launchNew
+		t.Cleanup(func() { launchNew = origLaunchNew })
+		launchNew = func(option launch.Option) launch.Launcher {
+			mutex.Lock()
+			defer mutex.Unlock()
+			jobNames = append(jobNames, option.JobName)
+			if option.JobName == "main" {
+				return mockFailedLaunch{}
+			}
+			return mockLaunch{}
+		}
+
+		jobs := map[string]screwdriver.Job{
+			"main":    {Requires: []string{"~commit"}},
+			"lint":    {Requires: []string{"~commit"}},
+			"test":    {Requires: []string{"main", "lint"}},
+			"publish": {Requires: []string{"test"}},
+		}
+
+		buf := bytes.NewBuffer(nil)
+		err := runWorkflow(launch.Option{ArtifactsPath: "sd-artifacts"}, jobs, nil, 1, true, nil, os.Stdout, buf)
+		assert.Equal(t, "failed jobs: [main]", err.Error())
+		assert.ElementsMatch(t, []string{"main", "lint"}, jobNames)
+		assert.Contains(t, buf.String(), "main     FAILURE  ")
+		assert.Contains(t, buf.String(), "lint     SUCCESS  ")
+		assert.Contains(t, buf.String(), "test     SKIPPED  -\n")
+		assert.Contains(t, buf.String(), "publish  SKIPPED  -\n")
+	})
+
+	t.Run("failure with a cycle", func(t *testing.T) {
+		jobs := map[string]screwdriver.Job{
+			"a": {Requires: []string{"b"}},
+			"b": {Requires: []string{"a"}},
+		}
+
+		err := runWorkflow(launch.Option{ArtifactsPath: "sd-artifacts"}, jobs, nil, 4, false, nil, os.Stdout, bytes.NewBuffer(nil))
+		assert.Equal(t, "workflow has a cycle in jobs: [a b]", err.Error())
+	})
+}
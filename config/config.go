@@ -1,31 +1,115 @@
 package config
 
 import (
+	"bytes"
 	"fmt"
+	"io/ioutil"
+	"net"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-yaml/yaml"
+	"github.com/sirupsen/logrus"
 )
 
 // Launcher is launcher entity struct
 type Launcher struct {
-	Version string `yaml:"version"`
-	Image   string `yaml:"image"`
+	Version string `yaml:"version" json:"version"`
+	Image   string `yaml:"image" json:"image"`
 }
 
 // Entry is entity struct of sd-local config
 type Entry struct {
-	APIURL   string   `yaml:"api-url"`
-	StoreURL string   `yaml:"store-url"`
-	Token    string   `yaml:"token"`
-	Launcher Launcher `yaml:"launcher"`
+	APIURL                string            `yaml:"api-url" json:"api-url"`
+	StoreURL              string            `yaml:"store-url" json:"store-url"`
+	Token                 string            `yaml:"token" json:"token"`
+	TokenType             string            `yaml:"token-type,omitempty" json:"token-type,omitempty"`
+	Launcher              Launcher          `yaml:"launcher" json:"launcher"`
+	Runtime               string            `yaml:"runtime,omitempty" json:"runtime,omitempty"`
+	Platform              string            `yaml:"platform,omitempty" json:"platform,omitempty"`
+	RegistryMirror        string            `yaml:"registry-mirror,omitempty" json:"registry-mirror,omitempty"`
+	ImageAliases          map[string]string `yaml:"image-aliases,omitempty" json:"image-aliases,omitempty"`
+	Volumes               []string          `yaml:"volumes,omitempty" json:"volumes,omitempty"`
+	AllowDockerSocket     bool              `yaml:"allow-docker-socket,omitempty" json:"allow-docker-socket,omitempty"`
+	AllowPrivileged       bool              `yaml:"allow-privileged,omitempty" json:"allow-privileged,omitempty"`
+	Memory                string            `yaml:"memory,omitempty" json:"memory,omitempty"`
+	CPUs                  string            `yaml:"cpus,omitempty" json:"cpus,omitempty"`
+	User                  string            `yaml:"user,omitempty" json:"user,omitempty"`
+	SELinuxLabel          string            `yaml:"selinux-label,omitempty" json:"selinux-label,omitempty"`
+	ProxyEnv              bool              `yaml:"proxy-env,omitempty" json:"proxy-env,omitempty"`
+	HTTPProxy             string            `yaml:"http-proxy,omitempty" json:"http-proxy,omitempty"`
+	HTTPSProxy            string            `yaml:"https-proxy,omitempty" json:"https-proxy,omitempty"`
+	NoProxy               string            `yaml:"no-proxy,omitempty" json:"no-proxy,omitempty"`
+	DNS                   []string          `yaml:"dns,omitempty" json:"dns,omitempty"`
+	LangCache             bool              `yaml:"lang-cache,omitempty" json:"lang-cache,omitempty"`
+	SecretsFile           string            `yaml:"secrets-file,omitempty" json:"secrets-file,omitempty"`
+	VaultAddr             string            `yaml:"vault-addr,omitempty" json:"vault-addr,omitempty"`
+	VaultPath             string            `yaml:"vault-path,omitempty" json:"vault-path,omitempty"`
+	CacheBackend          string            `yaml:"cache-backend,omitempty" json:"cache-backend,omitempty"`
+	CacheBucket           string            `yaml:"cache-bucket,omitempty" json:"cache-bucket,omitempty"`
+	CacheEndpoint         string            `yaml:"cache-endpoint,omitempty" json:"cache-endpoint,omitempty"`
+	CacheRegion           string            `yaml:"cache-region,omitempty" json:"cache-region,omitempty"`
+	SrcSizeWarning        string            `yaml:"src-size-warning,omitempty" json:"src-size-warning,omitempty"`
+	Timestamps            bool              `yaml:"timestamps,omitempty" json:"timestamps,omitempty"`
+	APIRetries            string            `yaml:"api-retries,omitempty" json:"api-retries,omitempty"`
+	APITimeout            string            `yaml:"api-timeout,omitempty" json:"api-timeout,omitempty"`
+	APIKeepAlive          string            `yaml:"api-keep-alive,omitempty" json:"api-keep-alive,omitempty"`
+	APIProxy              string            `yaml:"api-proxy,omitempty" json:"api-proxy,omitempty"`
+	APICACert             string            `yaml:"api-ca-cert,omitempty" json:"api-ca-cert,omitempty"`
+	APIClientCert         string            `yaml:"api-client-cert,omitempty" json:"api-client-cert,omitempty"`
+	APIClientKey          string            `yaml:"api-client-key,omitempty" json:"api-client-key,omitempty"`
+	InsecureSkipTLSVerify bool              `yaml:"insecure-skip-tls-verify,omitempty" json:"insecure-skip-tls-verify,omitempty"`
+	PlaintextToken        bool              `yaml:"plaintext-token,omitempty" json:"plaintext-token,omitempty"`
+	TokenStorage          string            `yaml:"token-storage,omitempty" json:"token-storage,omitempty"`
+	DisableUpdateCheck    bool              `yaml:"disable-update-check,omitempty" json:"disable-update-check,omitempty"`
+	NotifyDesktop         bool              `yaml:"notify-desktop,omitempty" json:"notify-desktop,omitempty"`
+	SlackWebhook          string            `yaml:"slack-webhook,omitempty" json:"slack-webhook,omitempty"`
+	TeamsWebhook          string            `yaml:"teams-webhook,omitempty" json:"teams-webhook,omitempty"`
+	DiscordWebhook        string            `yaml:"discord-webhook,omitempty" json:"discord-webhook,omitempty"`
+	MetricsPushgateway    string            `yaml:"metrics-pushgateway,omitempty" json:"metrics-pushgateway,omitempty"`
+	MetricsStatsD         string            `yaml:"metrics-statsd,omitempty" json:"metrics-statsd,omitempty"`
+	// storedToken is the token read from the credential store of the OS, which is not written again unless it is changed
+	storedToken string
 }
 
+// RedactedToken is shown instead of the token
+const RedactedToken = "<redacted>"
+
+// CacheBackends is the list of backends which store caches
+var CacheBackends = []string{"local", "s3"}
+
+// TokenTypes is the list of the types of the tokens
+var TokenTypes = []string{"user", "pipeline"}
+
+// Runtimes is the list of container runtimes which sd-local can drive
+var Runtimes = []string{"docker", "podman", "nerdctl"}
+
+// The labels of the bind mounts on SELinux, which are applied automatically when it is enforcing unless it is off
+const (
+	SELinuxLabelShared  = "z"
+	SELinuxLabelPrivate = "Z"
+	SELinuxLabelOff     = "off"
+)
+
+// HostUser is the user of the build container which is replaced with the user of the host
+const HostUser = "host"
+
+// DNSHost is the DNS server of the config which is replaced with the DNS servers of the host
+const DNSHost = "host"
+
+// Platforms is the list of platforms of the images which the builds can run on
+var Platforms = []string{"linux/amd64", "linux/arm64"}
+
 // Config is a set of sd-local config entities
 type Config struct {
-	Entries  map[string]*Entry `yaml:"configs"`
-	Current  string            `yaml:"current"`
+	Entries  map[string]*Entry `yaml:"configs" json:"configs"`
+	Current  string            `yaml:"current" json:"current"`
 	filePath string            `yaml:"-"`
 }
 
@@ -76,28 +160,91 @@ func New(configPath string) (Config, error) {
 		return Config{}, err
 	}
 
-	file, err := os.Open(configPath)
+	raw, err := ioutil.ReadFile(configPath)
 	if err != nil {
 		return Config{}, fmt.Errorf("failed to read config file: %v", err)
 	}
-	defer file.Close()
 
 	var c = Config{
 		filePath: configPath,
 	}
 
-	err = yaml.NewDecoder(file).Decode(&c)
+	err = yaml.NewDecoder(bytes.NewReader(raw)).Decode(&c)
 	if err != nil {
 		return Config{}, fmt.Errorf("failed to parse config file: %v", err)
 	}
 
+	// The config which has problems can be still used, so that they can be fixed with the config commands
+	for _, err := range validate(raw) {
+		logrus.Warn(err)
+	}
+
 	if c.Entries == nil {
 		c.Entries = make(map[string]*Entry, 0)
 	}
 
+	c.loadTokens()
+
 	return c, nil
 }
 
+// loadTokens reads the tokens stored in the credential store of the OS.
+// The config can be used without the token which fails to be read, such as in offline mode.
+func (c *Config) loadTokens() {
+	for name, e := range c.Entries {
+		if e.TokenStorage != TokenStorageKeychain {
+			continue
+		}
+
+		kc := osKeychain()
+		if kc == nil {
+			logrus.Warnf("failed to read token of config %s: credential store of %s is not supported", name, goos)
+			continue
+		}
+		token, err := kc.Get(name)
+		if err != nil {
+			logrus.Warnf("failed to read token of config %s from the credential store of the OS: %v", name, err)
+			continue
+		}
+		e.Token = token
+		e.storedToken = token
+	}
+}
+
+// secured returns the copy of the config to be written into the file, whose tokens are moved into the credential store of the OS.
+// The token is written into the file when plaintext-token is set, or the credential store is not available such as on headless machines.
+func (c *Config) secured() Config {
+	kc := osKeychain()
+
+	saved := Config{Entries: make(map[string]*Entry, len(c.Entries)), Current: c.Current, filePath: c.filePath}
+	for name, e := range c.Entries {
+		copied := *e
+		saved.Entries[name] = &copied
+
+		if e.Token == "" || e.PlaintextToken || kc == nil {
+			if e.TokenStorage == TokenStorageKeychain && kc != nil {
+				kc.Delete(name)
+			}
+			copied.TokenStorage = ""
+			continue
+		}
+
+		if e.TokenStorage != TokenStorageKeychain || e.Token != e.storedToken {
+			if err := kc.Set(name, e.Token); err != nil {
+				logrus.Warnf("failed to store token of config %s in the credential store of the OS, so it is written into the config file: %v", name, err)
+				copied.TokenStorage = ""
+				continue
+			}
+			e.TokenStorage = TokenStorageKeychain
+			e.storedToken = e.Token
+		}
+		copied.Token = ""
+		copied.TokenStorage = TokenStorageKeychain
+	}
+
+	return saved
+}
+
 // AddEntry create new Entry and add it to Config
 func (c *Config) AddEntry(name string) error {
 	_, exist := c.Entries[name]
@@ -128,6 +275,11 @@ func (c *Config) DeleteEntry(name string) error {
 	if !exist {
 		return fmt.Errorf("config `%s` does not exist", name)
 	}
+	if c.Entries[name].TokenStorage == TokenStorageKeychain {
+		if kc := osKeychain(); kc != nil {
+			kc.Delete(name)
+		}
+	}
 	delete(c.Entries, name)
 	return nil
 }
@@ -152,7 +304,8 @@ func (c *Config) Save() error {
 	}
 	defer file.Close()
 
-	err = yaml.NewEncoder(file).Encode(c)
+	saved := c.secured()
+	err = yaml.NewEncoder(file).Encode(&saved)
 	if err != nil {
 		return err
 	}
@@ -169,6 +322,11 @@ func (e *Entry) Set(key, value string) error {
 		e.StoreURL = value
 	case "token":
 		e.Token = value
+	case "token-type":
+		if value != "" && !contains(TokenTypes, value) {
+			return fmt.Errorf("invalid token type %s, must be one of %v", value, TokenTypes)
+		}
+		e.TokenType = value
 	case "launcher-version":
 		if value == "" {
 			value = "stable"
@@ -179,9 +337,445 @@ func (e *Entry) Set(key, value string) error {
 			value = "screwdrivercd/launcher"
 		}
 		e.Launcher.Image = value
+	case "runtime":
+		if value != "" && !contains(Runtimes, value) {
+			return fmt.Errorf("invalid runtime %s, must be one of %v", value, Runtimes)
+		}
+		e.Runtime = value
+	case "platform":
+		if value != "" {
+			if err := ValidatePlatform(value); err != nil {
+				return err
+			}
+		}
+		e.Platform = value
+	case "registry-mirror":
+		if value != "" {
+			if err := ValidateRegistryMirror(value); err != nil {
+				return err
+			}
+		}
+		e.RegistryMirror = value
+	case "image-aliases":
+		var aliases map[string]string
+		if value != "" {
+			aliases = make(map[string]string)
+			for _, a := range strings.Split(value, ",") {
+				kv := strings.SplitN(a, "=", 2)
+				if len(kv) != 2 {
+					return fmt.Errorf("invalid image alias %s, must be <image>=<image>", a)
+				}
+				if err := ValidateImageAlias(kv[0], kv[1]); err != nil {
+					return err
+				}
+				aliases[kv[0]] = kv[1]
+			}
+		}
+		e.ImageAliases = aliases
+	case "volumes":
+		var volumes []string
+		if value != "" {
+			for _, v := range strings.Split(value, ",") {
+				if err := ValidateVolume(v); err != nil {
+					return err
+				}
+				volumes = append(volumes, v)
+			}
+		}
+		e.Volumes = volumes
+	case "allow-docker-socket":
+		if value == "" {
+			value = "false"
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid allow-docker-socket %s, must be true or false", value)
+		}
+		e.AllowDockerSocket = b
+	case "allow-privileged":
+		if value == "" {
+			value = "false"
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid allow-privileged %s, must be true or false", value)
+		}
+		e.AllowPrivileged = b
+	case "memory":
+		if value != "" {
+			if _, err := ParseSize(value); err != nil {
+				return err
+			}
+		}
+		e.Memory = value
+	case "cpus":
+		if value != "" {
+			if err := ValidateCPUs(value); err != nil {
+				return err
+			}
+		}
+		e.CPUs = value
+	case "user":
+		if value != "" {
+			if err := ValidateUser(value); err != nil {
+				return err
+			}
+		}
+		e.User = value
+	case "selinux-label":
+		if value != "" && value != SELinuxLabelShared && value != SELinuxLabelPrivate && value != SELinuxLabelOff {
+			return fmt.Errorf("invalid selinux-label %s, must be %s, %s or %s", value, SELinuxLabelShared, SELinuxLabelPrivate, SELinuxLabelOff)
+		}
+		e.SELinuxLabel = value
+	case "proxy-env":
+		if value == "" {
+			value = "false"
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid proxy-env %s, must be true or false", value)
+		}
+		e.ProxyEnv = b
+	case "http-proxy", "https-proxy":
+		if value != "" {
+			if u, err := url.Parse(value); err != nil || u.Scheme == "" || u.Host == "" {
+				return fmt.Errorf("invalid %s %s, must be a URL (e.g. http://proxy.example.com:8080)", key, value)
+			}
+		}
+		if key == "http-proxy" {
+			e.HTTPProxy = value
+		} else {
+			e.HTTPSProxy = value
+		}
+	case "no-proxy":
+		e.NoProxy = value
+	case "dns":
+		var servers []string
+		if value != "" {
+			for _, v := range strings.Split(value, ",") {
+				if v != DNSHost && net.ParseIP(v) == nil {
+					return fmt.Errorf("invalid dns %s, must be comma separated IP addresses or %s", v, DNSHost)
+				}
+				servers = append(servers, v)
+			}
+		}
+		e.DNS = servers
+	case "lang-cache":
+		if value == "" {
+			value = "false"
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid lang-cache %s, must be true or false", value)
+		}
+		e.LangCache = b
+	case "secrets-file":
+		e.SecretsFile = value
+	case "vault-addr":
+		e.VaultAddr = value
+	case "vault-path":
+		e.VaultPath = value
+	case "cache-backend":
+		if value != "" && !contains(CacheBackends, value) {
+			return fmt.Errorf("invalid cache backend %s, must be one of %v", value, CacheBackends)
+		}
+		e.CacheBackend = value
+	case "cache-bucket":
+		e.CacheBucket = value
+	case "cache-endpoint":
+		e.CacheEndpoint = value
+	case "cache-region":
+		e.CacheRegion = value
+	case "src-size-warning":
+		if value != "" {
+			if _, err := ParseSize(value); err != nil {
+				return err
+			}
+		}
+		e.SrcSizeWarning = value
+	case "timestamps":
+		if value == "" {
+			value = "false"
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid timestamps %s, must be true or false", value)
+		}
+		e.Timestamps = b
+	case "api-retries":
+		if value != "" {
+			if n, err := strconv.Atoi(value); err != nil || n < 0 {
+				return fmt.Errorf("invalid api-retries %s, must be a non-negative integer", value)
+			}
+		}
+		e.APIRetries = value
+	case "api-timeout":
+		if value != "" {
+			if d, err := time.ParseDuration(value); err != nil || d <= 0 {
+				return fmt.Errorf("invalid api-timeout %s, must be a positive duration (e.g. 30s)", value)
+			}
+		}
+		e.APITimeout = value
+	case "api-keep-alive":
+		if value != "" {
+			if d, err := time.ParseDuration(value); err != nil || d < 0 {
+				return fmt.Errorf("invalid api-keep-alive %s, must be a duration (e.g. 90s), or 0 to disable keep-alive", value)
+			}
+		}
+		e.APIKeepAlive = value
+	case "api-proxy":
+		if value != "" {
+			if u, err := url.Parse(value); err != nil || u.Scheme == "" || u.Host == "" {
+				return fmt.Errorf("invalid api-proxy %s, must be a URL (e.g. http://proxy.example.com:8080)", value)
+			}
+		}
+		e.APIProxy = value
+	case "api-ca-cert":
+		e.APICACert = value
+	case "api-client-cert":
+		e.APIClientCert = value
+	case "api-client-key":
+		e.APIClientKey = value
+	case "insecure-skip-tls-verify":
+		if value == "" {
+			value = "false"
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid insecure-skip-tls-verify %s, must be true or false", value)
+		}
+		e.InsecureSkipTLSVerify = b
+	case "plaintext-token":
+		if value == "" {
+			value = "false"
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid plaintext-token %s, must be true or false", value)
+		}
+		e.PlaintextToken = b
+	case "disable-update-check":
+		if value == "" {
+			value = "false"
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid disable-update-check %s, must be true or false", value)
+		}
+		e.DisableUpdateCheck = b
+	case "notify-desktop":
+		if value == "" {
+			value = "false"
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid notify-desktop %s, must be true or false", value)
+		}
+		e.NotifyDesktop = b
+	case "slack-webhook", "teams-webhook", "discord-webhook":
+		if value != "" {
+			if u, err := url.Parse(value); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+				return fmt.Errorf("invalid %s %s, must be the URL of the incoming webhook", key, value)
+			}
+		}
+		switch key {
+		case "slack-webhook":
+			e.SlackWebhook = value
+		case "teams-webhook":
+			e.TeamsWebhook = value
+		default:
+			e.DiscordWebhook = value
+		}
+	case "metrics-pushgateway":
+		if value != "" {
+			if u, err := url.Parse(value); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+				return fmt.Errorf("invalid metrics-pushgateway %s, must be a URL (e.g. http://pushgateway.example.com:9091)", value)
+			}
+		}
+		e.MetricsPushgateway = value
+	case "metrics-statsd":
+		if value != "" {
+			if host, port, err := net.SplitHostPort(value); err != nil || host == "" || port == "" {
+				return fmt.Errorf("invalid metrics-statsd %s, must be <host>:<port> (e.g. statsd.example.com:8125)", value)
+			}
+		}
+		e.MetricsStatsD = value
+	default:
+		return invalidKeyError(key, Keys)
+	}
+
+	return nil
+}
+
+// Unset resets the value of the key to the default.
+func (e *Entry) Unset(key string) error {
+	return e.Set(key, "")
+}
+
+// Redacted returns the copy of the entry whose token and webhooks are hidden, so that it can be shown.
+// The URLs of the webhooks are secrets which allow anyone to post to the channels.
+func (e Entry) Redacted() Entry {
+	for _, secret := range []*string{&e.Token, &e.SlackWebhook, &e.TeamsWebhook, &e.DiscordWebhook} {
+		if *secret != "" {
+			*secret = RedactedToken
+		}
+	}
+	return e
+}
+
+// ValidateVolume checks the volume mounted into the build container, which is <host path or volume name>:<container path>[:ro|rw].
+func ValidateVolume(volume string) error {
+	parts := strings.Split(volume, ":")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || !path.IsAbs(parts[1]) || (len(parts) == 3 && parts[2] != "ro" && parts[2] != "rw") {
+		return fmt.Errorf("invalid volume %s, must be <host path>:<container path>[:ro]", volume)
+	}
+	return nil
+}
+
+// ValidateTmpfs checks the tmpfs mounted into the build container, which is <container path>[:<options>].
+func ValidateTmpfs(tmpfs string) error {
+	parts := strings.SplitN(tmpfs, ":", 2)
+	if !path.IsAbs(parts[0]) || (len(parts) == 2 && parts[1] == "") {
+		return fmt.Errorf("invalid tmpfs %s, must be <container path>[:<options>] (e.g. /tmp:size=1g)", tmpfs)
+	}
+	return nil
+}
+
+// ValidateExtraHost checks the host added to /etc/hosts of the build container, which is <name>:<ip> or <name>:host-gateway.
+func ValidateExtraHost(host string) error {
+	parts := strings.SplitN(host, ":", 2)
+	if len(parts) != 2 || parts[0] == "" || (parts[1] != "host-gateway" && net.ParseIP(parts[1]) == nil) {
+		return fmt.Errorf("invalid host %s, must be <name>:<ip> (e.g. api.local:192.168.1.10)", host)
+	}
+	return nil
+}
+
+var publishPortPattern = regexp.MustCompile(`^[0-9]+(-[0-9]+)?$`)
+
+// ValidatePublish checks the port published from the build container, which is [<ip>:][<host port>:]<container port>[/tcp|udp].
+func ValidatePublish(publish string) error {
+	invalid := fmt.Errorf("invalid port %s, must be [<ip>:][<host port>:]<container port>[/<protocol>] (e.g. 8080:8080)", publish)
+
+	ports := publish
+	if i := strings.LastIndex(publish, "/"); i != -1 {
+		if protocol := publish[i+1:]; protocol != "tcp" && protocol != "udp" && protocol != "sctp" {
+			return invalid
+		}
+		ports = publish[:i]
+	}
+
+	parts := strings.Split(ports, ":")
+	switch len(parts) {
+	case 1, 2:
+	case 3:
+		if net.ParseIP(parts[0]) == nil {
+			return invalid
+		}
+		parts = parts[1:]
 	default:
-		return fmt.Errorf("invalid key %s", key)
+		return invalid
+	}
+
+	// The host port can be omitted to publish the container port to a random port of the host
+	if len(parts) == 2 && parts[0] != "" && !publishPortPattern.MatchString(parts[0]) {
+		return invalid
+	}
+	if !publishPortPattern.MatchString(parts[len(parts)-1]) {
+		return invalid
 	}
+	return nil
+}
+
+var userPattern = regexp.MustCompile(`^([0-9]+|[a-z_][a-z0-9_-]*)(:([0-9]+|[a-z_][a-z0-9_-]*))?$`)
 
+// ValidateUser checks the user of the build container, which is <name|uid>[:<group|gid>] or host.
+func ValidateUser(user string) error {
+	if user != HostUser && !userPattern.MatchString(user) {
+		return fmt.Errorf("invalid user %s, must be <name|uid>[:<group|gid>] or %s (e.g. 1000:1000)", user, HostUser)
+	}
 	return nil
 }
+
+var gpuDevicesPattern = regexp.MustCompile(`^device=[0-9]+(,[0-9]+)*$`)
+
+// ValidateGPUs checks the GPUs exposed to the build container, which are all, the number of GPUs or device=<index>[,<index>...].
+func ValidateGPUs(gpus string) error {
+	if n, err := strconv.Atoi(gpus); (err == nil && n > 0) || gpus == "all" || gpuDevicesPattern.MatchString(gpus) {
+		return nil
+	}
+	return fmt.Errorf("invalid gpus %s, must be all, the number of GPUs or device=<index>[,<index>...] (e.g. device=0,1)", gpus)
+}
+
+// ValidatePlatform checks the platform of the images, which is one of Platforms.
+func ValidatePlatform(platform string) error {
+	if !contains(Platforms, platform) {
+		return fmt.Errorf("invalid platform %s, must be one of %v", platform, Platforms)
+	}
+	return nil
+}
+
+// ValidateRegistryMirror checks the mirror of Docker Hub, which is <host>[:port][/path] without the scheme.
+func ValidateRegistryMirror(mirror string) error {
+	if strings.Contains(mirror, "://") || strings.HasPrefix(mirror, "/") || strings.HasSuffix(mirror, "/") {
+		return fmt.Errorf("invalid registry-mirror %s, must be <host>[:port][/path] (e.g. mirror.example.com)", mirror)
+	}
+	return nil
+}
+
+// ValidateImageAlias checks the alias of the image, which rewrites the image to the other image.
+// The image ending with * matches the images with the prefix, and the rest of them replaces * of the other image.
+func ValidateImageAlias(from, to string) error {
+	err := fmt.Errorf("invalid image alias %s=%s, must be <image>=<image> or <prefix>*=<prefix>*", from, to)
+
+	if from == "" || to == "" {
+		return err
+	}
+	// * is allowed only at the end, and the other image can end with it only if the image ends with it
+	if strings.Contains(strings.TrimSuffix(from, "*"), "*") || strings.Contains(strings.TrimSuffix(to, "*"), "*") {
+		return err
+	}
+	if strings.HasSuffix(to, "*") && !strings.HasSuffix(from, "*") {
+		return err
+	}
+
+	return nil
+}
+
+// ValidateCPUs checks the number of CPUs of the build container, which takes a positive number such as 0.5 or 2.
+func ValidateCPUs(cpus string) error {
+	n, err := strconv.ParseFloat(cpus, 64)
+	if err != nil || n <= 0 {
+		return fmt.Errorf("invalid cpus %s, must be a positive number (e.g. 0.5, 2)", cpus)
+	}
+	return nil
+}
+
+// ParseSize parses the size which takes a positive integer, followed by a suffix of b, k, m, g.
+func ParseSize(size string) (int64, error) {
+	units := map[byte]int64{'b': 1, 'k': 1 << 10, 'm': 1 << 20, 'g': 1 << 30}
+
+	s := strings.ToLower(size)
+	unit := int64(1)
+	if len(s) != 0 {
+		if u, ok := units[s[len(s)-1]]; ok {
+			unit = u
+			s = s[:len(s)-1]
+		}
+	}
+
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid size %s, must be a positive integer, followed by a suffix of b, k, m, g", size)
+	}
+
+	return n * unit, nil
+}
+
+func contains(list []string, name string) bool {
+	for _, v := range list {
+		if v == name {
+			return true
+		}
+	}
+	return false
+}
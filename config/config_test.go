@@ -369,6 +369,9 @@ func TestConfigSetCurrent(t *testing.T) {
 }
 
 func TestConfigSave(t *testing.T) {
+	defer func() { osKeychain = newKeychain }()
+	osKeychain = func() keychain { return nil }
+
 	t.Run("success", func(t *testing.T) {
 		rand.Seed(time.Now().UnixNano())
 		cnfPath := filepath.Join(testDir, ".sdlocal", fmt.Sprintf("%vconfig", rand.Int()))
@@ -453,6 +456,16 @@ func TestSetEntry(t *testing.T) {
 				"token":            "override-dummy-token",
 				"launcher-version": "override-1.0.0",
 				"launcher-image":   "override-alpine",
+				"secrets-file":     "override-secrets.yaml",
+				"vault-addr":       "https://vault.example.com",
+				"vault-path":       "secret/data/sd-local",
+				"cache-backend":    "s3",
+				"cache-bucket":     "sd-local-cache",
+				"cache-endpoint":   "http://minio.example.com:9000",
+				"cache-region":     "ap-northeast-1",
+				"src-size-warning": "500m",
+				"timestamps":       "true",
+				"api-retries":      "5",
 				"invalidKey":       "override-invalidValue",
 			},
 			expectEntry: Entry{
@@ -463,6 +476,16 @@ func TestSetEntry(t *testing.T) {
 					Version: "override-1.0.0",
 					Image:   "override-alpine",
 				},
+				SecretsFile:    "override-secrets.yaml",
+				VaultAddr:      "https://vault.example.com",
+				VaultPath:      "secret/data/sd-local",
+				CacheBackend:   "s3",
+				CacheBucket:    "sd-local-cache",
+				CacheEndpoint:  "http://minio.example.com:9000",
+				CacheRegion:    "ap-northeast-1",
+				SrcSizeWarning: "500m",
+				Timestamps:     true,
+				APIRetries:     "5",
 			},
 		},
 		{
@@ -524,3 +547,727 @@ func TestSetEntry(t *testing.T) {
 		})
 	}
 }
+
+func TestParseSize(t *testing.T) {
+	testCases := []struct {
+		size      string
+		expect    int64
+		expectErr bool
+	}{
+		{size: "512", expect: 512},
+		{size: "512b", expect: 512},
+		{size: "2k", expect: 2 << 10},
+		{size: "500m", expect: 500 << 20},
+		{size: "1G", expect: 1 << 30},
+		{size: "", expectErr: true},
+		{size: "g", expectErr: true},
+		{size: "-1g", expectErr: true},
+		{size: "1t", expectErr: true},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.size, func(t *testing.T) {
+			size, err := ParseSize(tt.size)
+			if tt.expectErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.expect, size)
+			}
+		})
+	}
+}
+
+func TestSetEntryTimestamps(t *testing.T) {
+	testCases := []struct {
+		name      string
+		value     string
+		expect    bool
+		expectErr bool
+	}{
+		{name: "true", value: "true", expect: true},
+		{name: "false", value: "false", expect: false},
+		{name: "reset to default", value: "", expect: false},
+		{name: "invalid value", value: "yes", expect: true, expectErr: true},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &Entry{Timestamps: true}
+
+			err := e.Set("timestamps", tt.value)
+			if tt.expectErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.Equal(t, tt.expect, e.Timestamps)
+		})
+	}
+}
+
+func TestSetEntryDisableUpdateCheck(t *testing.T) {
+	testCases := []struct {
+		name      string
+		value     string
+		expect    bool
+		expectErr bool
+	}{
+		{name: "true", value: "true", expect: true},
+		{name: "reset to default", value: "", expect: false},
+		{name: "invalid value", value: "off", expect: true, expectErr: true},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &Entry{DisableUpdateCheck: true}
+
+			err := e.Set("disable-update-check", tt.value)
+			if tt.expectErr {
+				assert.EqualError(t, err, "invalid disable-update-check off, must be true or false")
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.Equal(t, tt.expect, e.DisableUpdateCheck)
+		})
+	}
+}
+
+func TestSetEntryNotifications(t *testing.T) {
+	testCases := []struct {
+		name      string
+		key       string
+		value     string
+		expect    Entry
+		expectErr string
+	}{
+		{name: "notify-desktop", key: "notify-desktop", value: "true", expect: Entry{NotifyDesktop: true}},
+		{name: "invalid notify-desktop", key: "notify-desktop", value: "on", expectErr: "invalid notify-desktop on, must be true or false"},
+		{name: "slack-webhook", key: "slack-webhook", value: "https://hooks.slack.com/services/T0/B0/xxx", expect: Entry{SlackWebhook: "https://hooks.slack.com/services/T0/B0/xxx"}},
+		{name: "teams-webhook", key: "teams-webhook", value: "https://example.webhook.office.com/webhookb2/xxx", expect: Entry{TeamsWebhook: "https://example.webhook.office.com/webhookb2/xxx"}},
+		{name: "discord-webhook", key: "discord-webhook", value: "https://discord.com/api/webhooks/1/xxx", expect: Entry{DiscordWebhook: "https://discord.com/api/webhooks/1/xxx"}},
+		{name: "reset webhook", key: "slack-webhook", value: "", expect: Entry{}},
+		{name: "invalid webhook", key: "discord-webhook", value: "discord.com/api/webhooks/1/xxx", expectErr: "invalid discord-webhook discord.com/api/webhooks/1/xxx, must be the URL of the incoming webhook"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &Entry{}
+
+			err := e.Set(tt.key, tt.value)
+			if tt.expectErr != "" {
+				assert.EqualError(t, err, tt.expectErr)
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.Equal(t, tt.expect, *e)
+		})
+	}
+}
+
+func TestSetEntryMetrics(t *testing.T) {
+	testCases := []struct {
+		name      string
+		key       string
+		value     string
+		expect    Entry
+		expectErr string
+	}{
+		{name: "metrics-pushgateway", key: "metrics-pushgateway", value: "http://pushgateway.example.com:9091", expect: Entry{MetricsPushgateway: "http://pushgateway.example.com:9091"}},
+		{name: "invalid metrics-pushgateway", key: "metrics-pushgateway", value: "pushgateway.example.com:9091", expectErr: "invalid metrics-pushgateway pushgateway.example.com:9091, must be a URL (e.g. http://pushgateway.example.com:9091)"},
+		{name: "metrics-statsd", key: "metrics-statsd", value: "localhost:8125", expect: Entry{MetricsStatsD: "localhost:8125"}},
+		{name: "reset metrics-statsd", key: "metrics-statsd", value: "", expect: Entry{}},
+		{name: "invalid metrics-statsd", key: "metrics-statsd", value: "localhost", expectErr: "invalid metrics-statsd localhost, must be <host>:<port> (e.g. statsd.example.com:8125)"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &Entry{}
+
+			err := e.Set(tt.key, tt.value)
+			if tt.expectErr != "" {
+				assert.EqualError(t, err, tt.expectErr)
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.Equal(t, tt.expect, *e)
+		})
+	}
+}
+
+func TestSetEntryRuntime(t *testing.T) {
+	testCases := []struct {
+		name      string
+		value     string
+		expect    string
+		expectErr bool
+	}{
+		{name: "docker", value: "docker", expect: "docker"},
+		{name: "podman", value: "podman", expect: "podman"},
+		{name: "nerdctl", value: "nerdctl", expect: "nerdctl"},
+		{name: "reset to default", value: "", expect: ""},
+		{name: "unknown runtime", value: "lxc", expect: "docker", expectErr: true},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &Entry{Runtime: "docker"}
+
+			err := e.Set("runtime", tt.value)
+			if tt.expectErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.Equal(t, tt.expect, e.Runtime)
+		})
+	}
+}
+
+func TestSetEntryCacheBackend(t *testing.T) {
+	testCases := []struct {
+		name      string
+		value     string
+		expect    string
+		expectErr bool
+	}{
+		{name: "local", value: "local", expect: "local"},
+		{name: "s3", value: "s3", expect: "s3"},
+		{name: "reset to default", value: "", expect: ""},
+		{name: "unknown backend", value: "gcs", expect: "local", expectErr: true},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &Entry{CacheBackend: "local"}
+
+			err := e.Set("cache-backend", tt.value)
+			if tt.expectErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.Equal(t, tt.expect, e.CacheBackend)
+		})
+	}
+}
+
+func TestSetEntryAPIRetries(t *testing.T) {
+	testCases := []struct {
+		name      string
+		value     string
+		expect    string
+		expectErr bool
+	}{
+		{name: "number", value: "5", expect: "5"},
+		{name: "disabled", value: "0", expect: "0"},
+		{name: "reset to default", value: "", expect: ""},
+		{name: "negative number", value: "-1", expect: "3", expectErr: true},
+		{name: "invalid value", value: "many", expect: "3", expectErr: true},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &Entry{APIRetries: "3"}
+
+			err := e.Set("api-retries", tt.value)
+			if tt.expectErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.Equal(t, tt.expect, e.APIRetries)
+		})
+	}
+}
+
+func TestSetEntryAPIClient(t *testing.T) {
+	testCases := []struct {
+		name      string
+		key       string
+		value     string
+		expectErr bool
+	}{
+		{name: "timeout", key: "api-timeout", value: "30s"},
+		{name: "zero timeout", key: "api-timeout", value: "0s", expectErr: true},
+		{name: "invalid timeout", key: "api-timeout", value: "30", expectErr: true},
+		{name: "keep-alive", key: "api-keep-alive", value: "90s"},
+		{name: "disabled keep-alive", key: "api-keep-alive", value: "0"},
+		{name: "negative keep-alive", key: "api-keep-alive", value: "-1s", expectErr: true},
+		{name: "proxy", key: "api-proxy", value: "http://proxy.example.com:8080"},
+		{name: "invalid proxy", key: "api-proxy", value: "proxy.example.com", expectErr: true},
+		{name: "reset proxy", key: "api-proxy", value: ""},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &Entry{}
+
+			err := e.Set(tt.key, tt.value)
+			if tt.expectErr {
+				assert.NotNil(t, err)
+				assert.Equal(t, Entry{}, *e)
+			} else {
+				assert.Nil(t, err)
+				got := map[string]string{"api-timeout": e.APITimeout, "api-keep-alive": e.APIKeepAlive, "api-proxy": e.APIProxy}[tt.key]
+				assert.Equal(t, tt.value, got)
+			}
+		})
+	}
+}
+
+func TestSetEntryProxyAndDNS(t *testing.T) {
+	e := &Entry{}
+
+	assert.Nil(t, e.Set("proxy-env", "true"))
+	assert.Nil(t, e.Set("http-proxy", "http://proxy.example.com:8080"))
+	assert.Nil(t, e.Set("https-proxy", "http://proxy.example.com:8443"))
+	assert.Nil(t, e.Set("no-proxy", "localhost,.example.com"))
+	assert.Nil(t, e.Set("dns", "host,8.8.8.8"))
+	assert.Equal(t, Entry{
+		ProxyEnv:   true,
+		HTTPProxy:  "http://proxy.example.com:8080",
+		HTTPSProxy: "http://proxy.example.com:8443",
+		NoProxy:    "localhost,.example.com",
+		DNS:        []string{"host", "8.8.8.8"},
+	}, *e)
+
+	err := e.Set("https-proxy", "proxy.example.com")
+	assert.Equal(t, "invalid https-proxy proxy.example.com, must be a URL (e.g. http://proxy.example.com:8080)", err.Error())
+	err = e.Set("dns", "8.8.8.8,dns.example.com")
+	assert.Equal(t, "invalid dns dns.example.com, must be comma separated IP addresses or host", err.Error())
+	assert.NotNil(t, e.Set("proxy-env", "yes"))
+	assert.Equal(t, "http://proxy.example.com:8443", e.HTTPSProxy)
+	assert.Equal(t, []string{"host", "8.8.8.8"}, e.DNS)
+	assert.True(t, e.ProxyEnv)
+
+	assert.Nil(t, e.Set("proxy-env", ""))
+	assert.Nil(t, e.Set("dns", ""))
+	assert.False(t, e.ProxyEnv)
+	assert.Nil(t, e.DNS)
+}
+
+func TestSetEntryLangCache(t *testing.T) {
+	e := &Entry{}
+
+	assert.Nil(t, e.Set("lang-cache", "true"))
+	assert.True(t, e.LangCache)
+
+	err := e.Set("lang-cache", "yes")
+	assert.Equal(t, "invalid lang-cache yes, must be true or false", err.Error())
+	assert.True(t, e.LangCache)
+
+	assert.Nil(t, e.Set("lang-cache", ""))
+	assert.False(t, e.LangCache)
+}
+
+func TestSetEntryTLS(t *testing.T) {
+	e := &Entry{}
+
+	assert.Nil(t, e.Set("api-ca-cert", "/etc/ssl/ca.crt"))
+	assert.Nil(t, e.Set("api-client-cert", "/etc/ssl/client.crt"))
+	assert.Nil(t, e.Set("api-client-key", "/etc/ssl/client.key"))
+	assert.Nil(t, e.Set("insecure-skip-tls-verify", "true"))
+	assert.Equal(t, Entry{
+		APICACert:             "/etc/ssl/ca.crt",
+		APIClientCert:         "/etc/ssl/client.crt",
+		APIClientKey:          "/etc/ssl/client.key",
+		InsecureSkipTLSVerify: true,
+	}, *e)
+
+	assert.NotNil(t, e.Set("insecure-skip-tls-verify", "yes"))
+	assert.True(t, e.InsecureSkipTLSVerify)
+	assert.Nil(t, e.Set("insecure-skip-tls-verify", ""))
+	assert.False(t, e.InsecureSkipTLSVerify)
+}
+
+func TestSetEntryPlatform(t *testing.T) {
+	testCases := []struct {
+		name      string
+		value     string
+		expect    string
+		expectErr bool
+	}{
+		{name: "amd64", value: "linux/amd64", expect: "linux/amd64"},
+		{name: "arm64", value: "linux/arm64", expect: "linux/arm64"},
+		{name: "reset to default", value: "", expect: ""},
+		{name: "unknown platform", value: "windows/amd64", expect: "linux/amd64", expectErr: true},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &Entry{Platform: "linux/amd64"}
+			err := e.Set("platform", tt.value)
+			if tt.expectErr {
+				assert.EqualError(t, err, fmt.Sprintf("invalid platform %s, must be one of [linux/amd64 linux/arm64]", tt.value))
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.Equal(t, tt.expect, e.Platform)
+		})
+	}
+}
+
+func TestSetEntryImages(t *testing.T) {
+	e := &Entry{}
+
+	assert.Nil(t, e.Set("registry-mirror", "mirror.example.com"))
+	assert.Nil(t, e.Set("image-aliases", "docker.io/library/*=registry.example.com/library/*,node:18=registry.example.com/node:18"))
+	assert.Equal(t, Entry{
+		RegistryMirror: "mirror.example.com",
+		ImageAliases: map[string]string{
+			"docker.io/library/*": "registry.example.com/library/*",
+			"node:18":             "registry.example.com/node:18",
+		},
+	}, *e)
+
+	assert.EqualError(t, e.Set("registry-mirror", "https://mirror.example.com"), "invalid registry-mirror https://mirror.example.com, must be <host>[:port][/path] (e.g. mirror.example.com)")
+	assert.EqualError(t, e.Set("image-aliases", "node:18"), "invalid image alias node:18, must be <image>=<image>")
+	assert.EqualError(t, e.Set("image-aliases", "node:*18=node:18"), "invalid image alias node:*18=node:18, must be <image>=<image> or <prefix>*=<prefix>*")
+	assert.EqualError(t, e.Set("image-aliases", "node:18=node*"), "invalid image alias node:18=node*, must be <image>=<image> or <prefix>*=<prefix>*")
+	assert.Equal(t, "mirror.example.com", e.RegistryMirror)
+	assert.Len(t, e.ImageAliases, 2)
+
+	assert.Nil(t, e.Unset("registry-mirror"))
+	assert.Nil(t, e.Unset("image-aliases"))
+	assert.Equal(t, Entry{}, *e)
+}
+
+func TestSetEntryAllowDockerSocket(t *testing.T) {
+	e := &Entry{}
+
+	assert.Nil(t, e.Set("allow-docker-socket", "true"))
+	assert.True(t, e.AllowDockerSocket)
+
+	err := e.Set("allow-docker-socket", "yes")
+	assert.EqualError(t, err, "invalid allow-docker-socket yes, must be true or false")
+	assert.True(t, e.AllowDockerSocket)
+
+	assert.Nil(t, e.Set("allow-docker-socket", ""))
+	assert.False(t, e.AllowDockerSocket)
+}
+
+func TestSetEntryUser(t *testing.T) {
+	e := &Entry{}
+
+	assert.Nil(t, e.Set("user", "host"))
+	assert.Equal(t, "host", e.User)
+
+	err := e.Set("user", "root:")
+	assert.EqualError(t, err, "invalid user root:, must be <name|uid>[:<group|gid>] or host (e.g. 1000:1000)")
+	assert.Equal(t, "host", e.User)
+
+	assert.Nil(t, e.Set("user", ""))
+	assert.Equal(t, "", e.User)
+}
+
+func TestSetEntrySELinuxLabel(t *testing.T) {
+	e := &Entry{}
+
+	assert.Nil(t, e.Set("selinux-label", "Z"))
+	assert.Equal(t, "Z", e.SELinuxLabel)
+	assert.Nil(t, e.Set("selinux-label", "off"))
+	assert.Equal(t, "off", e.SELinuxLabel)
+
+	err := e.Set("selinux-label", "shared")
+	assert.EqualError(t, err, "invalid selinux-label shared, must be z, Z or off")
+	assert.Equal(t, "off", e.SELinuxLabel)
+
+	assert.Nil(t, e.Set("selinux-label", ""))
+	assert.Equal(t, "", e.SELinuxLabel)
+}
+
+func TestSetEntryAllowPrivileged(t *testing.T) {
+	e := &Entry{}
+
+	assert.Nil(t, e.Set("allow-privileged", "true"))
+	assert.True(t, e.AllowPrivileged)
+
+	err := e.Set("allow-privileged", "yes")
+	assert.EqualError(t, err, "invalid allow-privileged yes, must be true or false")
+	assert.True(t, e.AllowPrivileged)
+
+	assert.Nil(t, e.Set("allow-privileged", ""))
+	assert.False(t, e.AllowPrivileged)
+}
+
+func TestSetEntryResources(t *testing.T) {
+	e := &Entry{}
+
+	assert.Nil(t, e.Set("memory", "4g"))
+	assert.Nil(t, e.Set("cpus", "1.5"))
+	assert.Equal(t, Entry{Memory: "4g", CPUs: "1.5"}, *e)
+
+	assert.EqualError(t, e.Set("memory", "lots"), "invalid size lots, must be a positive integer, followed by a suffix of b, k, m, g")
+	assert.EqualError(t, e.Set("cpus", "0"), "invalid cpus 0, must be a positive number (e.g. 0.5, 2)")
+	assert.Equal(t, Entry{Memory: "4g", CPUs: "1.5"}, *e)
+
+	assert.Nil(t, e.Unset("memory"))
+	assert.Nil(t, e.Unset("cpus"))
+	assert.Equal(t, Entry{}, *e)
+}
+
+func TestSetEntryTokenType(t *testing.T) {
+	testCases := []struct {
+		name      string
+		value     string
+		expect    string
+		expectErr bool
+	}{
+		{name: "user", value: "user", expect: "user"},
+		{name: "pipeline", value: "pipeline", expect: "pipeline"},
+		{name: "reset to default", value: "", expect: ""},
+		{name: "unknown type", value: "build", expect: "pipeline", expectErr: true},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &Entry{TokenType: "pipeline"}
+
+			err := e.Set("token-type", tt.value)
+			if tt.expectErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.Equal(t, tt.expect, e.TokenType)
+		})
+	}
+}
+
+func TestSetEntryPlaintextToken(t *testing.T) {
+	e := &Entry{}
+
+	assert.Nil(t, e.Set("plaintext-token", "true"))
+	assert.True(t, e.PlaintextToken)
+	assert.NotNil(t, e.Set("plaintext-token", "yes"))
+	assert.True(t, e.PlaintextToken)
+	assert.Nil(t, e.Set("plaintext-token", ""))
+	assert.False(t, e.PlaintextToken)
+}
+
+func TestUnsetEntry(t *testing.T) {
+	testCases := []struct {
+		name      string
+		key       string
+		expect    Entry
+		expectErr bool
+	}{
+		{name: "api-url", key: "api-url", expect: Entry{Launcher: Launcher{Version: "1.0.0", Image: "my/launcher"}, Timestamps: true}},
+		{name: "launcher-version", key: "launcher-version", expect: Entry{APIURL: "https://api.example.com", Launcher: Launcher{Version: "stable", Image: "my/launcher"}, Timestamps: true}},
+		{name: "timestamps", key: "timestamps", expect: Entry{APIURL: "https://api.example.com", Launcher: Launcher{Version: "1.0.0", Image: "my/launcher"}}},
+		{name: "invalid key", key: "api-urls", expect: Entry{APIURL: "https://api.example.com", Launcher: Launcher{Version: "1.0.0", Image: "my/launcher"}, Timestamps: true}, expectErr: true},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &Entry{APIURL: "https://api.example.com", Launcher: Launcher{Version: "1.0.0", Image: "my/launcher"}, Timestamps: true}
+
+			err := e.Unset(tt.key)
+			if tt.expectErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.Equal(t, tt.expect, *e)
+		})
+	}
+}
+
+func TestRedactedEntry(t *testing.T) {
+	e := Entry{APIURL: "https://api.example.com", Token: "secret-token"}
+
+	assert.Equal(t, Entry{APIURL: "https://api.example.com", Token: RedactedToken}, e.Redacted())
+	assert.Equal(t, "secret-token", e.Token)
+	assert.Equal(t, Entry{}, Entry{}.Redacted())
+
+	e = Entry{Token: "secret-token", SlackWebhook: "https://hooks.slack.com/services/T0/B0/xxx", DiscordWebhook: "https://discord.com/api/webhooks/1/xxx"}
+	assert.Equal(t, Entry{Token: RedactedToken, SlackWebhook: RedactedToken, DiscordWebhook: RedactedToken}, e.Redacted())
+}
+
+func TestSetEntryVolumes(t *testing.T) {
+	testCases := []struct {
+		name      string
+		value     string
+		expect    []string
+		expectErr bool
+	}{
+		{name: "single", value: "/opt/sdk:/opt/sdk:ro", expect: []string{"/opt/sdk:/opt/sdk:ro"}},
+		{name: "multiple", value: "${HOME}/.m2:/root/.m2,gradle-cache:/root/.gradle", expect: []string{"${HOME}/.m2:/root/.m2", "gradle-cache:/root/.gradle"}},
+		{name: "reset to default", value: "", expect: nil},
+		{name: "invalid volume", value: "/opt/sdk", expect: []string{"/data:/data"}, expectErr: true},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &Entry{Volumes: []string{"/data:/data"}}
+
+			err := e.Set("volumes", tt.value)
+			if tt.expectErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.Equal(t, tt.expect, e.Volumes)
+		})
+	}
+}
+
+func TestValidateExtraHost(t *testing.T) {
+	testCases := []struct {
+		host      string
+		expectErr bool
+	}{
+		{host: "api.local:192.168.1.10"},
+		{host: "api.local:::1"},
+		{host: "host.docker.internal:host-gateway"},
+		{host: "api.local", expectErr: true},
+		{host: ":192.168.1.10", expectErr: true},
+		{host: "api.local:example.com", expectErr: true},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.host, func(t *testing.T) {
+			err := ValidateExtraHost(tt.host)
+			if tt.expectErr {
+				assert.Equal(t, fmt.Sprintf("invalid host %s, must be <name>:<ip> (e.g. api.local:192.168.1.10)", tt.host), err.Error())
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateTmpfs(t *testing.T) {
+	testCases := []struct {
+		tmpfs     string
+		expectErr bool
+	}{
+		{tmpfs: "/tmp"},
+		{tmpfs: "/tmp:size=1g,mode=1777"},
+		{tmpfs: "tmp", expectErr: true},
+		{tmpfs: "/tmp:", expectErr: true},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.tmpfs, func(t *testing.T) {
+			err := ValidateTmpfs(tt.tmpfs)
+			if tt.expectErr {
+				assert.Equal(t, fmt.Sprintf("invalid tmpfs %s, must be <container path>[:<options>] (e.g. /tmp:size=1g)", tt.tmpfs), err.Error())
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestValidatePublish(t *testing.T) {
+	testCases := []struct {
+		publish   string
+		expectErr bool
+	}{
+		{publish: "8080"},
+		{publish: "8080:8080"},
+		{publish: "127.0.0.1:8080:8080"},
+		{publish: "127.0.0.1::8080"},
+		{publish: "5353:53/udp"},
+		{publish: "8000-8010:8000-8010"},
+		{publish: "8080:", expectErr: true},
+		{publish: "http:8080", expectErr: true},
+		{publish: "localhost:8080:8080", expectErr: true},
+		{publish: "8080:8080/http", expectErr: true},
+		{publish: "1:2:3:4", expectErr: true},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.publish, func(t *testing.T) {
+			err := ValidatePublish(tt.publish)
+			if tt.expectErr {
+				assert.Equal(t, fmt.Sprintf("invalid port %s, must be [<ip>:][<host port>:]<container port>[/<protocol>] (e.g. 8080:8080)", tt.publish), err.Error())
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateUser(t *testing.T) {
+	testCases := []struct {
+		user      string
+		expectErr bool
+	}{
+		{user: "host"},
+		{user: "1000"},
+		{user: "1000:1000"},
+		{user: "node"},
+		{user: "node:staff"},
+		{user: "1000:", expectErr: true},
+		{user: "Node", expectErr: true},
+		{user: "1000:1000:1000", expectErr: true},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.user, func(t *testing.T) {
+			err := ValidateUser(tt.user)
+			if tt.expectErr {
+				assert.Equal(t, fmt.Sprintf("invalid user %s, must be <name|uid>[:<group|gid>] or host (e.g. 1000:1000)", tt.user), err.Error())
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateGPUs(t *testing.T) {
+	testCases := []struct {
+		gpus      string
+		expectErr bool
+	}{
+		{gpus: "all"},
+		{gpus: "2"},
+		{gpus: "device=0"},
+		{gpus: "device=0,1"},
+		{gpus: "0", expectErr: true},
+		{gpus: "any", expectErr: true},
+		{gpus: "device=", expectErr: true},
+		{gpus: "device=GPU-3a23c669", expectErr: true},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.gpus, func(t *testing.T) {
+			err := ValidateGPUs(tt.gpus)
+			if tt.expectErr {
+				assert.Equal(t, fmt.Sprintf("invalid gpus %s, must be all, the number of GPUs or device=<index>[,<index>...] (e.g. device=0,1)", tt.gpus), err.Error())
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateVolume(t *testing.T) {
+	testCases := []struct {
+		volume    string
+		expectErr bool
+	}{
+		{volume: "/opt/sdk:/opt/sdk"},
+		{volume: "./data:/data:ro"},
+		{volume: "gocache:/root/.cache/go-build:rw"},
+		{volume: "/opt/sdk", expectErr: true},
+		{volume: ":/opt/sdk", expectErr: true},
+		{volume: "/opt/sdk:opt/sdk", expectErr: true},
+		{volume: "/opt/sdk:/opt/sdk:z", expectErr: true},
+		{volume: "/opt/sdk:/opt/sdk:ro:z", expectErr: true},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.volume, func(t *testing.T) {
+			err := ValidateVolume(tt.volume)
+			if tt.expectErr {
+				assert.Equal(t, fmt.Sprintf("invalid volume %s, must be <host path>:<container path>[:ro]", tt.volume), err.Error())
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"os"
+	"reflect"
+)
+
+// The environment variables which override the current config at runtime, such as in CI
+const (
+	EnvAPIURL   = "SD_LOCAL_API_URL"
+	EnvStoreURL = "SD_LOCAL_STORE_URL"
+	EnvToken    = "SD_LOCAL_TOKEN"
+)
+
+var lookupEnv = os.LookupEnv
+
+// envKeys maps the environment variables to the keys of the config which they override
+var envKeys = [][2]string{
+	{EnvAPIURL, "api-url"},
+	{EnvStoreURL, "store-url"},
+	{EnvToken, "token"},
+}
+
+// Overrides returns the keys of the config which are overridden by the environment variables, mapped to the variables.
+// The variables which are empty do not override the config.
+func Overrides() map[string]string {
+	overrides := make(map[string]string)
+	for _, k := range envKeys {
+		if v, ok := lookupEnv(k[0]); ok && v != "" {
+			overrides[k[1]] = k[0]
+		}
+	}
+	return overrides
+}
+
+// Resolved returns the copy of the entry whose values are overridden by the environment variables.
+// The environment variables take precedence over the config file, and the references to them such as ${HOME} in the values are expanded.
+func (e Entry) Resolved() Entry {
+	expandStrings(reflect.ValueOf(&e).Elem())
+
+	for key, env := range Overrides() {
+		v, _ := lookupEnv(env)
+		switch key {
+		case "api-url":
+			e.APIURL = v
+		case "store-url":
+			e.StoreURL = v
+		case "token":
+			e.Token = v
+		}
+	}
+	return e
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mockLookupEnv(env map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+}
+
+func TestOverrides(t *testing.T) {
+	defer func(l func(string) (string, bool)) {
+		lookupEnv = l
+	}(lookupEnv)
+
+	testCases := []struct {
+		name     string
+		env      map[string]string
+		expected map[string]string
+	}{
+		{
+			name: "all",
+			env: map[string]string{
+				EnvAPIURL:   "https://api.env.example.com",
+				EnvStoreURL: "https://store.env.example.com",
+				EnvToken:    "env-token",
+			},
+			expected: map[string]string{
+				"api-url":   EnvAPIURL,
+				"store-url": EnvStoreURL,
+				"token":     EnvToken,
+			},
+		},
+		{
+			name: "empty variable does not override",
+			env: map[string]string{
+				EnvAPIURL: "https://api.env.example.com",
+				EnvToken:  "",
+			},
+			expected: map[string]string{
+				"api-url": EnvAPIURL,
+			},
+		},
+		{
+			name:     "none",
+			env:      map[string]string{},
+			expected: map[string]string{},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			lookupEnv = mockLookupEnv(tt.env)
+			assert.Equal(t, tt.expected, Overrides())
+		})
+	}
+}
+
+func TestResolved(t *testing.T) {
+	defer func(l func(string) (string, bool)) {
+		lookupEnv = l
+	}(lookupEnv)
+
+	entry := Entry{
+		APIURL:   "https://api.example.com",
+		StoreURL: "https://store.example.com",
+		Token:    "config-token",
+		Launcher: Launcher{Version: "stable", Image: "screwdrivercd/launcher"},
+	}
+
+	testCases := []struct {
+		name     string
+		env      map[string]string
+		expected Entry
+	}{
+		{
+			name: "override by env",
+			env: map[string]string{
+				EnvAPIURL:   "https://api.env.example.com",
+				EnvStoreURL: "https://store.env.example.com",
+				EnvToken:    "env-token",
+			},
+			expected: Entry{
+				APIURL:   "https://api.env.example.com",
+				StoreURL: "https://store.env.example.com",
+				Token:    "env-token",
+				Launcher: Launcher{Version: "stable", Image: "screwdrivercd/launcher"},
+			},
+		},
+		{
+			name: "partially override",
+			env: map[string]string{
+				EnvToken:  "env-token",
+				EnvAPIURL: "",
+			},
+			expected: Entry{
+				APIURL:   "https://api.example.com",
+				StoreURL: "https://store.example.com",
+				Token:    "env-token",
+				Launcher: Launcher{Version: "stable", Image: "screwdrivercd/launcher"},
+			},
+		},
+		{
+			name:     "no env",
+			env:      map[string]string{},
+			expected: entry,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			lookupEnv = mockLookupEnv(tt.env)
+			assert.Equal(t, tt.expected, entry.Resolved())
+		})
+	}
+	assert.Equal(t, "config-token", entry.Token)
+}
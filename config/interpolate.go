@@ -0,0 +1,79 @@
+package config
+
+import (
+	"os"
+	"os/user"
+	"reflect"
+	"regexp"
+
+	"github.com/sirupsen/logrus"
+)
+
+// reference matches ${NAME} and ${env:NAME} in the values of the config
+var reference = regexp.MustCompile(`\$\{(env:)?([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+var (
+	userHomeDir = os.UserHomeDir
+	currentUser = user.Current
+)
+
+// lookupVar returns the value of the environment variable.
+// HOME and USER are also found on the machines which do not set them, such as in containers.
+func lookupVar(name string) (string, bool) {
+	if v, ok := lookupEnv(name); ok {
+		return v, true
+	}
+
+	switch name {
+	case "HOME":
+		if home, err := userHomeDir(); err == nil {
+			return home, true
+		}
+	case "USER":
+		if u, err := currentUser(); err == nil {
+			return u.Username, true
+		}
+	}
+
+	return "", false
+}
+
+// Expand replaces ${NAME} and ${env:NAME} in the value with the environment variables,
+// so that the config can be shared across the machines which have different paths.
+// The variables which are not set are replaced with empty.
+func Expand(value string) string {
+	return reference.ReplaceAllStringFunc(value, func(ref string) string {
+		name := reference.FindStringSubmatch(ref)[2]
+		v, ok := lookupVar(name)
+		if !ok {
+			logrus.Warnf("%s in the config is replaced with empty, because $%s is not set", ref, name)
+		}
+		return v
+	})
+}
+
+// expandStrings expands the exported string and string slice fields of the struct, including the nested ones.
+func expandStrings(v reflect.Value) {
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Field(i)
+		if !f.CanSet() {
+			continue
+		}
+		switch f.Kind() {
+		case reflect.String:
+			f.SetString(Expand(f.String()))
+		case reflect.Slice:
+			if f.Type().Elem().Kind() != reflect.String || f.IsNil() {
+				continue
+			}
+			// The slice is copied not to change the entry which the copy shares it with
+			expanded := make([]string, f.Len())
+			for j := range expanded {
+				expanded[j] = Expand(f.Index(j).String())
+			}
+			f.Set(reflect.ValueOf(expanded))
+		case reflect.Struct:
+			expandStrings(f)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"errors"
+	"os/user"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpand(t *testing.T) {
+	defer func(l func(string) (string, bool), h func() (string, error), u func() (*user.User, error)) {
+		lookupEnv, userHomeDir, currentUser = l, h, u
+	}(lookupEnv, userHomeDir, currentUser)
+
+	lookupEnv = mockLookupEnv(map[string]string{"FOO": "foo", "REGISTRY_PASSWORD": "p@ss"})
+	userHomeDir = func() (string, error) { return "/home/sd", nil }
+	currentUser = func() (*user.User, error) { return &user.User{Username: "sd"}, nil }
+
+	testCases := []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{"HOME", "${HOME}/secrets.yaml", "/home/sd/secrets.yaml"},
+		{"USER", "/Users/${USER}/.sdlocal", "/Users/sd/.sdlocal"},
+		{"env", "${env:REGISTRY_PASSWORD}", "p@ss"},
+		{"multiple references", "${env:FOO}-${FOO}", "foo-foo"},
+		{"not set", "${env:BAR}/secrets.yaml", "/secrets.yaml"},
+		{"no reference", "$FOO and ${ FOO }", "$FOO and ${ FOO }"},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Expand(tt.value))
+		})
+	}
+
+	t.Run("HOME and USER are not found", func(t *testing.T) {
+		userHomeDir = func() (string, error) { return "", errors.New("$HOME is not defined") }
+		currentUser = func() (*user.User, error) { return nil, errors.New("unknown user") }
+		assert.Equal(t, "/", Expand("${HOME}/${USER}"))
+	})
+}
+
+func TestResolvedExpandsReferences(t *testing.T) {
+	defer func(l func(string) (string, bool)) {
+		lookupEnv = l
+	}(lookupEnv)
+
+	lookupEnv = mockLookupEnv(map[string]string{"HOME": "/home/sd", "SD_TOKEN": "env-token", "LAUNCHER_VERSION": "v6"})
+
+	entry := Entry{
+		SecretsFile: "${HOME}/.sdlocal/secrets.yaml",
+		Token:       "${env:SD_TOKEN}",
+		Launcher:    Launcher{Version: "${env:LAUNCHER_VERSION}", Image: "screwdrivercd/launcher"},
+		Volumes:     []string{"${HOME}/.m2:/root/.m2", "gocache:/root/.cache/go-build"},
+	}
+
+	assert.Equal(t, Entry{
+		SecretsFile: "/home/sd/.sdlocal/secrets.yaml",
+		Token:       "env-token",
+		Launcher:    Launcher{Version: "v6", Image: "screwdrivercd/launcher"},
+		Volumes:     []string{"/home/sd/.m2:/root/.m2", "gocache:/root/.cache/go-build"},
+	}, entry.Resolved())
+	assert.Equal(t, "${env:SD_TOKEN}", entry.Token)
+	assert.Equal(t, "${HOME}/.m2:/root/.m2", entry.Volumes[0])
+}
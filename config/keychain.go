@@ -0,0 +1,102 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	goruntime "runtime"
+	"strconv"
+	"strings"
+)
+
+// keychainService is the name under which the tokens are stored in the credential store of the OS
+const keychainService = "sd-local"
+
+// TokenStorageKeychain is the storage of the token in the credential store of the OS
+const TokenStorageKeychain = "keychain"
+
+// keychain stores the tokens of the configs in the credential store of the OS
+type keychain interface {
+	Get(account string) (string, error)
+	Set(account, secret string) error
+	Delete(account string) error
+}
+
+var (
+	execCommand = exec.Command
+	goos        = goruntime.GOOS
+	// osKeychain is the credential store of the OS, which is nil when it is not supported
+	osKeychain = newKeychain
+)
+
+func newKeychain() keychain {
+	switch goos {
+	case "darwin":
+		return macKeychain{}
+	case "linux":
+		return secretService{}
+	}
+	return nil
+}
+
+func runKeychain(stdin string, name string, args ...string) (string, error) {
+	cmd := execCommand(name, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	stderr := bytes.NewBuffer(nil)
+	cmd.Stderr = stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s: %v: %s", name, err, msg)
+		}
+		return "", fmt.Errorf("%s: %v", name, err)
+	}
+
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+// macKeychain is Keychain of macOS driven by security command
+type macKeychain struct{}
+
+func (macKeychain) Get(account string) (string, error) {
+	return runKeychain("", "security", "find-generic-password", "-s", keychainService, "-a", account, "-w")
+}
+
+// Set passes the secret in the interactive mode of security, so that it does not appear in the arguments of the process
+func (macKeychain) Set(account, secret string) error {
+	line := fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n", keychainService, strconv.Quote(account), strconv.Quote(secret))
+	_, err := runKeychain(line, "security", "-i")
+	return err
+}
+
+func (macKeychain) Delete(account string) error {
+	_, err := runKeychain("", "security", "delete-generic-password", "-s", keychainService, "-a", account)
+	return err
+}
+
+// secretService is the Secret Service such as GNOME Keyring driven by secret-tool of libsecret
+type secretService struct{}
+
+func (secretService) Get(account string) (string, error) {
+	secret, err := runKeychain("", "secret-tool", "lookup", "service", keychainService, "account", account)
+	if err != nil {
+		return "", err
+	}
+	if secret == "" {
+		return "", errors.New("secret-tool: token is not found")
+	}
+	return secret, nil
+}
+
+// Set passes the secret with stdin, so that it does not appear in the arguments of the process
+func (secretService) Set(account, secret string) error {
+	_, err := runKeychain(secret, "secret-tool", "store", "--label", fmt.Sprintf("sd-local token of %s", account), "service", keychainService, "account", account)
+	return err
+}
+
+func (secretService) Delete(account string) error {
+	_, err := runKeychain("", "secret-tool", "clear", "service", keychainService, "account", account)
+	return err
+}
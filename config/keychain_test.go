@@ -0,0 +1,315 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockKeychain struct {
+	secrets map[string]string
+	err     error
+	sets    int
+}
+
+func (m *mockKeychain) Get(account string) (string, error) {
+	if m.err != nil {
+		return "", m.err
+	}
+	secret, ok := m.secrets[account]
+	if !ok {
+		return "", errors.New("not found")
+	}
+	return secret, nil
+}
+
+func (m *mockKeychain) Set(account, secret string) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.sets++
+	m.secrets[account] = secret
+	return nil
+}
+
+func (m *mockKeychain) Delete(account string) error {
+	delete(m.secrets, account)
+	return nil
+}
+
+func TestSecured(t *testing.T) {
+	defer func() { osKeychain = newKeychain }()
+
+	testCases := []struct {
+		name         string
+		entry        Entry
+		keychainErr  error
+		noKeychain   bool
+		savedToken   string
+		savedStorage string
+		secrets      map[string]string
+	}{
+		{
+			name:         "success with keychain",
+			entry:        Entry{Token: "token"},
+			savedStorage: TokenStorageKeychain,
+			secrets:      map[string]string{"default": "token"},
+		},
+		{
+			name:         "success with plaintext-token",
+			entry:        Entry{Token: "token", PlaintextToken: true},
+			savedToken:   "token",
+			savedStorage: "",
+			secrets:      map[string]string{},
+		},
+		{
+			name:         "success with plaintext-token after keychain",
+			entry:        Entry{Token: "token", PlaintextToken: true, TokenStorage: TokenStorageKeychain, storedToken: "token"},
+			savedToken:   "token",
+			savedStorage: "",
+			secrets:      map[string]string{},
+		},
+		{
+			name:         "success without token",
+			entry:        Entry{},
+			savedStorage: "",
+			secrets:      map[string]string{},
+		},
+		{
+			name:         "success with failing keychain",
+			entry:        Entry{Token: "token"},
+			keychainErr:  errors.New("secret-tool: Cannot autolaunch D-Bus without X11 $DISPLAY"),
+			savedToken:   "token",
+			savedStorage: "",
+			secrets:      map[string]string{},
+		},
+		{
+			name:         "success without keychain",
+			entry:        Entry{Token: "token"},
+			noKeychain:   true,
+			savedToken:   "token",
+			savedStorage: "",
+			secrets:      map[string]string{},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			kc := &mockKeychain{secrets: map[string]string{}, err: tt.keychainErr}
+			if tt.entry.storedToken != "" {
+				kc.secrets["default"] = tt.entry.storedToken
+			}
+			osKeychain = func() keychain {
+				if tt.noKeychain {
+					return nil
+				}
+				return kc
+			}
+
+			entry := tt.entry
+			c := Config{Current: "default", Entries: map[string]*Entry{"default": &entry}}
+			saved := c.secured()
+
+			assert.Equal(t, tt.savedToken, saved.Entries["default"].Token)
+			assert.Equal(t, tt.savedStorage, saved.Entries["default"].TokenStorage)
+			assert.Equal(t, tt.entry.Token, entry.Token)
+			assert.Equal(t, tt.secrets, kc.secrets)
+		})
+	}
+}
+
+func TestSecuredUnchangedToken(t *testing.T) {
+	defer func() { osKeychain = newKeychain }()
+	kc := &mockKeychain{secrets: map[string]string{"default": "token"}}
+	osKeychain = func() keychain { return kc }
+
+	c := Config{Current: "default", Entries: map[string]*Entry{"default": {TokenStorage: TokenStorageKeychain}}}
+	c.loadTokens()
+	assert.Equal(t, "token", c.Entries["default"].Token)
+
+	c.secured()
+	assert.Equal(t, 0, kc.sets)
+
+	c.Entries["default"].Token = "new"
+	c.secured()
+	assert.Equal(t, 1, kc.sets)
+	assert.Equal(t, "new", kc.secrets["default"])
+}
+
+func TestLoadTokens(t *testing.T) {
+	defer func() { osKeychain = newKeychain }()
+	kc := &mockKeychain{secrets: map[string]string{"default": "token"}}
+	osKeychain = func() keychain { return kc }
+
+	c := Config{Entries: map[string]*Entry{
+		"default":   {TokenStorage: TokenStorageKeychain},
+		"plaintext": {Token: "plain"},
+		"missing":   {TokenStorage: TokenStorageKeychain},
+	}}
+	c.loadTokens()
+
+	assert.Equal(t, "token", c.Entries["default"].Token)
+	assert.Equal(t, "plain", c.Entries["plaintext"].Token)
+	assert.Equal(t, "", c.Entries["missing"].Token)
+}
+
+func TestDeleteEntryKeychain(t *testing.T) {
+	defer func() { osKeychain = newKeychain }()
+	kc := &mockKeychain{secrets: map[string]string{"test": "token"}}
+	osKeychain = func() keychain { return kc }
+
+	c := Config{Current: "default", Entries: map[string]*Entry{
+		"default": {},
+		"test":    {Token: "token", TokenStorage: TokenStorageKeychain},
+	}}
+	assert.Nil(t, c.DeleteEntry("test"))
+	assert.Equal(t, map[string]string{}, kc.secrets)
+}
+
+// fakeKeychainCommand runs TestKeychainHelperProcess instead of the command, which records the command and its stdin
+func fakeKeychainCommand(t *testing.T, mode string) (func(string, ...string) *exec.Cmd, string) {
+	f, err := ioutil.TempFile("", "keychain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	return func(name string, args ...string) *exec.Cmd {
+		cs := append([]string{"-test.run=TestKeychainHelperProcess", "--", name}, args...)
+		cmd := exec.Command(os.Args[0], cs...)
+		cmd.Env = []string{"GO_WANT_HELPER_PROCESS=1", "GO_TEST_MODE=" + mode, "GO_TEST_LOG=" + f.Name()}
+		return cmd
+	}, f.Name()
+}
+
+func TestKeychainHelperProcess(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+	defer os.Exit(0)
+
+	args := os.Args
+	for len(args) > 0 {
+		if args[0] == "--" {
+			args = args[1:]
+			break
+		}
+		args = args[1:]
+	}
+	stdin, _ := ioutil.ReadAll(os.Stdin)
+	ioutil.WriteFile(os.Getenv("GO_TEST_LOG"), []byte(strings.Join(args, " ")+"\n"+string(stdin)), 0600)
+
+	switch os.Getenv("GO_TEST_MODE") {
+	case "SUCCESS":
+		fmt.Println("token")
+	case "FAILURE":
+		fmt.Fprintln(os.Stderr, "The specified item could not be found in the keychain.")
+		os.Exit(44)
+	}
+}
+
+func TestKeychainCommands(t *testing.T) {
+	defer func() { execCommand = exec.Command }()
+
+	testCases := []struct {
+		name   string
+		run    func(keychain) (string, error)
+		kc     keychain
+		mode   string
+		secret string
+		log    string
+		err    string
+	}{
+		{
+			name:   "get from macOS Keychain",
+			kc:     macKeychain{},
+			run:    func(kc keychain) (string, error) { return kc.Get("default") },
+			mode:   "SUCCESS",
+			secret: "token",
+			log:    "security find-generic-password -s sd-local -a default -w\n",
+		},
+		{
+			name: "set to macOS Keychain",
+			kc:   macKeychain{},
+			run:  func(kc keychain) (string, error) { return "", kc.Set("default", "token") },
+			mode: "SUCCESS",
+			log:  "security -i\nadd-generic-password -U -s sd-local -a \"default\" -w \"token\"\n",
+		},
+		{
+			name: "failure to get from macOS Keychain",
+			kc:   macKeychain{},
+			run:  func(kc keychain) (string, error) { return kc.Get("default") },
+			mode: "FAILURE",
+			log:  "security find-generic-password -s sd-local -a default -w\n",
+			err:  "security: exit status 44: The specified item could not be found in the keychain.",
+		},
+		{
+			name:   "get from Secret Service",
+			kc:     secretService{},
+			run:    func(kc keychain) (string, error) { return kc.Get("default") },
+			mode:   "SUCCESS",
+			secret: "token",
+			log:    "secret-tool lookup service sd-local account default\n",
+		},
+		{
+			name: "set to Secret Service",
+			kc:   secretService{},
+			run:  func(kc keychain) (string, error) { return "", kc.Set("default", "token") },
+			mode: "SUCCESS",
+			log:  "secret-tool store --label sd-local token of default service sd-local account default\ntoken",
+		},
+		{
+			name: "failure to get missing token from Secret Service",
+			kc:   secretService{},
+			run:  func(kc keychain) (string, error) { return kc.Get("default") },
+			mode: "EMPTY",
+			log:  "secret-tool lookup service sd-local account default\n",
+			err:  "secret-tool: token is not found",
+		},
+		{
+			name: "delete from Secret Service",
+			kc:   secretService{},
+			run:  func(kc keychain) (string, error) { return "", kc.Delete("default") },
+			mode: "SUCCESS",
+			log:  "secret-tool clear service sd-local account default\n",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, logPath := fakeKeychainCommand(t, tt.mode)
+			defer os.Remove(logPath)
+			execCommand = cmd
+
+			secret, err := tt.run(tt.kc)
+			if tt.err != "" {
+				assert.Equal(t, tt.err, err.Error())
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.Equal(t, tt.secret, secret)
+
+			log, err := ioutil.ReadFile(logPath)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.log, string(log))
+		})
+	}
+}
+
+func TestNewKeychain(t *testing.T) {
+	defaultGOOS := goos
+	defer func() { goos = defaultGOOS }()
+
+	goos = "darwin"
+	assert.Equal(t, macKeychain{}, newKeychain())
+	goos = "linux"
+	assert.Equal(t, secretService{}, newKeychain())
+	goos = "windows"
+	assert.Nil(t, newKeychain())
+}
@@ -0,0 +1,154 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/go-yaml/yaml"
+)
+
+// ProjectFileName is the name of the file of the project settings, which is put in the root of the repository
+const ProjectFileName = ".sd-local.yaml"
+
+var serviceNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// Project is the defaults of the builds of a repository, which can be committed and shared in the team
+type Project struct {
+	Job     string            `yaml:"job"`
+	Memory  string            `yaml:"memory"`
+	CPUs    string            `yaml:"cpus"`
+	ShmSize string            `yaml:"shm-size"`
+	Env     map[string]string `yaml:"env"`
+	Volumes []string          `yaml:"volumes"`
+	// Images maps the job names to the images which are used instead of the images in screwdriver.yaml
+	Images map[string]string `yaml:"images"`
+	// Services are the sidecar containers run alongside the builds
+	Services []Service `yaml:"services"`
+	// Compose is the docker-compose file whose services are run alongside the builds
+	Compose string `yaml:"compose"`
+	// SnapshotAfter is the last setup step, after which the build container is committed to start the next builds from it
+	SnapshotAfter string `yaml:"snapshot-after"`
+}
+
+// Service is a sidecar container run alongside the build on the same network, such as a database for the integration tests.
+// The build reaches the service with its name as the host name.
+type Service struct {
+	Name  string            `yaml:"name"`
+	Image string            `yaml:"image"`
+	Env   map[string]string `yaml:"env"`
+	// HealthCheck is the command which succeeds when the service is ready, such as pg_isready.
+	// The health check of the image is used when it is empty.
+	HealthCheck string `yaml:"healthcheck"`
+}
+
+// ParseService parses the service which is [<name>=]<image>.
+// The name defaults to the repository name of the image, such as postgres for postgres:13.
+func ParseService(service string) (Service, error) {
+	s := Service{Image: service}
+	if i := strings.Index(service, "="); i != -1 {
+		s.Name, s.Image = service[:i], service[i+1:]
+	}
+
+	if err := s.validate(); err != nil {
+		return s, err
+	}
+	return s, nil
+}
+
+// validate checks the image of the service, and sets the default name if it is empty.
+func (s *Service) validate() error {
+	if s.Image == "" {
+		return fmt.Errorf("invalid service %s, must have the image", s.Name)
+	}
+
+	if s.Name == "" {
+		name := s.Image[strings.LastIndex(s.Image, "/")+1:]
+		if i := strings.IndexAny(name, ":@"); i != -1 {
+			name = name[:i]
+		}
+		s.Name = name
+	}
+
+	if !serviceNamePattern.MatchString(s.Name) {
+		return fmt.Errorf("invalid service name %s, must consist of alphanumerics, _, . and -", s.Name)
+	}
+	return nil
+}
+
+// ReadProject reads the project settings in the directory, whose references to the environment variables are expanded.
+// The empty settings are returned when the directory has no project file.
+func ReadProject(dir string) (Project, error) {
+	var p Project
+
+	path := filepath.Join(dir, ProjectFileName)
+	raw, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return p, nil
+	}
+	if err != nil {
+		return p, fmt.Errorf("failed to read %s: %v", ProjectFileName, err)
+	}
+
+	if err := yaml.Unmarshal(raw, &p); err != nil {
+		return p, fmt.Errorf("failed to parse %s: %v", ProjectFileName, err)
+	}
+
+	p.Job = Expand(p.Job)
+	p.Memory = Expand(p.Memory)
+	p.CPUs = Expand(p.CPUs)
+	if p.CPUs != "" {
+		if err := ValidateCPUs(p.CPUs); err != nil {
+			return p, fmt.Errorf("failed to parse %s: %v", ProjectFileName, err)
+		}
+	}
+	p.ShmSize = Expand(p.ShmSize)
+	if p.ShmSize != "" {
+		if _, err := ParseSize(p.ShmSize); err != nil {
+			return p, fmt.Errorf("failed to parse %s: %v", ProjectFileName, err)
+		}
+	}
+	p.SnapshotAfter = Expand(p.SnapshotAfter)
+	for k, v := range p.Env {
+		p.Env[k] = Expand(v)
+	}
+	for i, v := range p.Volumes {
+		v = Expand(v)
+		if err := ValidateVolume(v); err != nil {
+			return p, fmt.Errorf("failed to parse %s: %v", ProjectFileName, err)
+		}
+		p.Volumes[i] = AbsVolume(dir, v)
+	}
+	for k, v := range p.Images {
+		p.Images[k] = Expand(v)
+	}
+	for i := range p.Services {
+		service := &p.Services[i]
+		service.Image = Expand(service.Image)
+		for k, v := range service.Env {
+			service.Env[k] = Expand(v)
+		}
+		if err := service.validate(); err != nil {
+			return p, fmt.Errorf("failed to parse %s: %v", ProjectFileName, err)
+		}
+	}
+	p.Compose = Expand(p.Compose)
+	if p.Compose != "" && !filepath.IsAbs(p.Compose) {
+		p.Compose = filepath.Join(dir, p.Compose)
+	}
+
+	return p, nil
+}
+
+// AbsVolume resolves the relative host path of the volume, which starts with ., from the directory.
+// The named volumes such as gocache:/root/.cache are kept as they are.
+func AbsVolume(dir, volume string) string {
+	host := strings.SplitN(volume, ":", 2)[0]
+	if filepath.IsAbs(host) || !strings.HasPrefix(host, ".") {
+		return volume
+	}
+	return filepath.Join(dir, host) + volume[len(host):]
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadProject(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		dir, err := filepath.Abs(filepath.Join("testdata", "project"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		p, err := ReadProject(dir)
+		assert.Nil(t, err)
+		assert.Equal(t, Project{
+			Job:     "main",
+			Memory:  "2g",
+			CPUs:    "1.5",
+			ShmSize: "1g",
+			Env:     map[string]string{"NODE_ENV": "test"},
+			Volumes: []string{filepath.Join(dir, "data") + ":/data", "gocache:/root/.cache/go-build"},
+			Images:  map[string]string{"main": "node:18"},
+			Services: []Service{
+				{Name: "postgres", Image: "postgres:13", Env: map[string]string{"POSTGRES_PASSWORD": "password"}, HealthCheck: "pg_isready"},
+				{Name: "queue", Image: "redis:7"},
+			},
+			Compose:       filepath.Join(dir, "docker-compose.yml"),
+			SnapshotAfter: "install",
+		}, p)
+	})
+
+	t.Run("success without project file", func(t *testing.T) {
+		p, err := ReadProject("testdata")
+		assert.Nil(t, err)
+		assert.Equal(t, Project{}, p)
+	})
+}
+
+func TestParseService(t *testing.T) {
+	testCases := []struct {
+		service     string
+		expect      Service
+		expectError string
+	}{
+		{service: "postgres:13", expect: Service{Name: "postgres", Image: "postgres:13"}},
+		{service: "registry.example.com/org/redis@sha256:abc", expect: Service{Name: "redis", Image: "registry.example.com/org/redis@sha256:abc"}},
+		{service: "db=localhost:5000/postgres", expect: Service{Name: "db", Image: "localhost:5000/postgres"}},
+		{service: "db=", expectError: "invalid service db, must have the image"},
+		{service: "my db=postgres", expectError: "invalid service name my db, must consist of alphanumerics, _, . and -"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.service, func(t *testing.T) {
+			s, err := ParseService(tt.service)
+			if tt.expectError != "" {
+				assert.EqualError(t, err, tt.expectError)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expect, s)
+		})
+	}
+}
+
+func TestAbsVolume(t *testing.T) {
+	testCases := []struct {
+		name     string
+		volume   string
+		expected string
+	}{
+		{"relative path", "./data:/data", "/repo/data:/data"},
+		{"relative path with options", "../data:/data:ro", "/data:/data:ro"},
+		{"absolute path", "/var/data:/data", "/var/data:/data"},
+		{"named volume", "gocache:/root/.cache/go-build", "gocache:/root/.cache/go-build"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, AbsVolume("/repo", tt.volume))
+		})
+	}
+}
@@ -0,0 +1,245 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/go-yaml/yaml"
+)
+
+// Keys is the list of the keys which can be set
+var Keys = []string{
+	"api-url",
+	"store-url",
+	"token",
+	"token-type",
+	"plaintext-token",
+	"launcher-version",
+	"launcher-image",
+	"runtime",
+	"platform",
+	"registry-mirror",
+	"image-aliases",
+	"volumes",
+	"allow-docker-socket",
+	"allow-privileged",
+	"memory",
+	"cpus",
+	"user",
+	"selinux-label",
+	"proxy-env",
+	"http-proxy",
+	"https-proxy",
+	"no-proxy",
+	"dns",
+	"lang-cache",
+	"secrets-file",
+	"vault-addr",
+	"vault-path",
+	"cache-backend",
+	"cache-bucket",
+	"cache-endpoint",
+	"cache-region",
+	"src-size-warning",
+	"timestamps",
+	"api-retries",
+	"api-timeout",
+	"api-keep-alive",
+	"api-proxy",
+	"api-ca-cert",
+	"api-client-cert",
+	"api-client-key",
+	"insecure-skip-tls-verify",
+	"disable-update-check",
+	"notify-desktop",
+	"slack-webhook",
+	"teams-webhook",
+	"discord-webhook",
+	"metrics-pushgateway",
+	"metrics-statsd",
+}
+
+// fileKeys returns the keys of an entry in the config file, where the launcher settings are nested
+func fileKeys() []string {
+	keys := []string{"launcher", "token-storage"}
+	for _, key := range Keys {
+		if !strings.HasPrefix(key, "launcher-") {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// distance returns the Levenshtein distance between a and b.
+func distance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+
+	return prev[len(b)]
+}
+
+func minInt(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
+
+// suggestKey returns the nearest key to the unknown key, or empty if no key is near enough to be a typo.
+func suggestKey(key string, keys []string) string {
+	suggested, nearest := "", 0
+	for _, k := range keys {
+		d := distance(key, k)
+		if d > 3 || d*2 >= len(key) {
+			continue
+		}
+		if suggested == "" || d < nearest {
+			suggested, nearest = k, d
+		}
+	}
+	return suggested
+}
+
+func invalidKeyError(key string, keys []string) error {
+	if suggested := suggestKey(key, keys); suggested != "" {
+		return fmt.Errorf("invalid key %s, did you mean %s?", key, suggested)
+	}
+	return fmt.Errorf("invalid key %s", key)
+}
+
+// validateEntry returns the problems of the settings of an entry in the config file.
+func validateEntry(raw map[string]interface{}) []error {
+	var errs []error
+	scratch := newEntry()
+
+	for key, value := range raw {
+		switch key {
+		case "launcher":
+			launcher, ok := value.(map[interface{}]interface{})
+			if !ok && value != nil {
+				errs = append(errs, errors.New("invalid launcher, must be a map of version and image"))
+				continue
+			}
+			for k, v := range launcher {
+				name := fmt.Sprint(k)
+				if name != "version" && name != "image" {
+					errs = append(errs, invalidKeyError("launcher."+name, []string{"launcher.version", "launcher.image"}))
+					continue
+				}
+				if err := scratch.Set("launcher-"+name, stringValue(v)); err != nil {
+					errs = append(errs, err)
+				}
+			}
+		case "volumes":
+			volumes, ok := value.([]interface{})
+			if !ok && value != nil {
+				errs = append(errs, errors.New("invalid volumes, must be a list of <host path>:<container path>[:ro]"))
+				continue
+			}
+			for _, v := range volumes {
+				if err := ValidateVolume(stringValue(v)); err != nil {
+					errs = append(errs, err)
+				}
+			}
+		case "image-aliases":
+			aliases, ok := value.(map[interface{}]interface{})
+			if !ok && value != nil {
+				errs = append(errs, errors.New("invalid image-aliases, must be a map of <image>: <image>"))
+				continue
+			}
+			for from, to := range aliases {
+				if err := ValidateImageAlias(stringValue(from), stringValue(to)); err != nil {
+					errs = append(errs, err)
+				}
+			}
+		case "token-storage":
+			if v := stringValue(value); v != "" && v != TokenStorageKeychain {
+				errs = append(errs, fmt.Errorf("invalid token-storage %s, must be %s", v, TokenStorageKeychain))
+			}
+		default:
+			if !contains(fileKeys(), key) {
+				errs = append(errs, invalidKeyError(key, fileKeys()))
+				continue
+			}
+			if err := scratch.Set(key, stringValue(value)); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errs
+}
+
+func stringValue(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+// validate returns the problems of the config file, such as unknown keys which are ignored and invalid values.
+func validate(raw []byte) []error {
+	var file struct {
+		Entries map[string]map[string]interface{} `yaml:"configs"`
+	}
+	if err := yaml.Unmarshal(raw, &file); err != nil {
+		return []error{fmt.Errorf("failed to parse config file: %v", err)}
+	}
+
+	names := make([]string, 0, len(file.Entries))
+	for name := range file.Entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		entryErrs := validateEntry(file.Entries[name])
+		sort.Slice(entryErrs, func(i, j int) bool { return entryErrs[i].Error() < entryErrs[j].Error() })
+		for _, err := range entryErrs {
+			errs = append(errs, fmt.Errorf("config %s: %v", name, err))
+		}
+	}
+
+	return errs
+}
+
+// Validate checks the keys and the values of the config file.
+func Validate(configPath string) error {
+	raw, err := ioutil.ReadFile(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %v", err)
+	}
+
+	errs := validate(raw)
+	if len(errs) == 0 {
+		return nil
+	}
+
+	messages := make([]string, 0, len(errs))
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+	return errors.New(strings.Join(messages, "; "))
+}
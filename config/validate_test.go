@@ -0,0 +1,169 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeys(t *testing.T) {
+	for _, key := range Keys {
+		e := newEntry()
+		assert.Nil(t, e.Set(key, ""), key)
+	}
+}
+
+func TestDistance(t *testing.T) {
+	assert.Equal(t, 0, distance("api-url", "api-url"))
+	assert.Equal(t, 1, distance("api-ur", "api-url"))
+	assert.Equal(t, 2, distance("launcher-verison", "launcher-version"))
+	assert.Equal(t, 3, distance("", "abc"))
+}
+
+func TestSuggestKey(t *testing.T) {
+	testCases := []struct {
+		key      string
+		expected string
+	}{
+		{"launcher-verison", "launcher-version"},
+		{"api_url", "api-url"},
+		{"timestamp", "timestamps"},
+		{"tokn", "token"},
+		{"foo", ""},
+		{"registry", ""},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.key, func(t *testing.T) {
+			assert.Equal(t, tt.expected, suggestKey(tt.key, Keys))
+		})
+	}
+}
+
+func TestSetEntryInvalidKey(t *testing.T) {
+	e := newEntry()
+
+	err := e.Set("launcher-verison", "1.0.0")
+	assert.Equal(t, "invalid key launcher-verison, did you mean launcher-version?", err.Error())
+	assert.Equal(t, "stable", e.Launcher.Version)
+
+	err = e.Set("registry", "example.com")
+	assert.Equal(t, "invalid key registry", err.Error())
+}
+
+func TestValidateEntry(t *testing.T) {
+	testCases := []struct {
+		name     string
+		raw      map[string]interface{}
+		expected []string
+	}{
+		{
+			name: "valid",
+			raw: map[string]interface{}{
+				"api-url":       "https://api.example.com",
+				"token":         nil,
+				"timestamps":    true,
+				"runtime":       "podman",
+				"token-storage": "keychain",
+				"launcher":      map[interface{}]interface{}{"version": "stable", "image": "screwdrivercd/launcher"},
+			},
+		},
+		{
+			name:     "unknown key",
+			raw:      map[string]interface{}{"api_url": "https://api.example.com"},
+			expected: []string{"invalid key api_url, did you mean api-url?"},
+		},
+		{
+			name:     "unknown launcher key",
+			raw:      map[string]interface{}{"launcher": map[interface{}]interface{}{"versoin": "stable"}},
+			expected: []string{"invalid key launcher.versoin, did you mean launcher.version?"},
+		},
+		{
+			name:     "invalid value",
+			raw:      map[string]interface{}{"runtime": "dockr"},
+			expected: []string{"invalid runtime dockr, must be one of [docker podman nerdctl]"},
+		},
+		{
+			name:     "invalid type",
+			raw:      map[string]interface{}{"timestamps": 3},
+			expected: []string{"invalid timestamps 3, must be true or false"},
+		},
+		{
+			name:     "invalid launcher",
+			raw:      map[string]interface{}{"launcher": "stable"},
+			expected: []string{"invalid launcher, must be a map of version and image"},
+		},
+		{
+			name:     "invalid volume",
+			raw:      map[string]interface{}{"volumes": []interface{}{"/opt/sdk:/opt/sdk", "/data"}},
+			expected: []string{"invalid volume /data, must be <host path>:<container path>[:ro]"},
+		},
+		{
+			name:     "invalid volumes",
+			raw:      map[string]interface{}{"volumes": "/opt/sdk:/opt/sdk"},
+			expected: []string{"invalid volumes, must be a list of <host path>:<container path>[:ro]"},
+		},
+		{
+			name: "invalid image alias",
+			raw: map[string]interface{}{"image-aliases": map[interface{}]interface{}{
+				"docker.io/library/*": "registry.example.com/library/*",
+				"node:18":             "registry.example.com/*",
+			}},
+			expected: []string{"invalid image alias node:18=registry.example.com/*, must be <image>=<image> or <prefix>*=<prefix>*"},
+		},
+		{
+			name:     "invalid image aliases",
+			raw:      map[string]interface{}{"image-aliases": "node:18=node:18-slim"},
+			expected: []string{"invalid image-aliases, must be a map of <image>: <image>"},
+		},
+		{
+			name:     "invalid token storage",
+			raw:      map[string]interface{}{"token-storage": "file"},
+			expected: []string{"invalid token-storage file, must be keychain"},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			var actual []string
+			for _, err := range validateEntry(tt.raw) {
+				actual = append(actual, err.Error())
+			}
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	t.Run("success", func(t *testing.T) {
+		assert.Nil(t, Validate(filepath.Join(testDir, "successConfig")))
+	})
+
+	t.Run("failure by invalid config", func(t *testing.T) {
+		path := filepath.Join(dir, "invalidConfig")
+		raw := "configs:\n  default:\n    api-url: https://api.example.com\n    runtime: dockr\n  test:\n    launcher-version: stable\ncurrent: default\n"
+		if err := ioutil.WriteFile(path, []byte(raw), 0666); err != nil {
+			t.Fatal(err)
+		}
+
+		err := Validate(path)
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+		assert.Equal(t, "config default: invalid runtime dockr, must be one of [docker podman nerdctl]; config test: invalid key launcher-version", err.Error())
+	})
+
+	t.Run("failure by reading config", func(t *testing.T) {
+		err := Validate(filepath.Join(dir, "notExist"))
+		assert.Contains(t, err.Error(), "failed to read config file: ")
+	})
+}
@@ -0,0 +1,67 @@
+package launch
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Artifact is a file written into the artifacts directory by the build.
+type Artifact struct {
+	Path    string
+	Size    int64
+	ModTime time.Time
+}
+
+// ReadArtifacts returns the files in the artifacts directory which are modified after since.
+// The meta directory and the results of the steps are not included because they are written by sd-local.
+func ReadArtifacts(artifactsPath string, since time.Time) ([]Artifact, error) {
+	artifacts := make([]Artifact, 0)
+	err := filepath.Walk(artifactsPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			if info.Name() == MetaDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if info.Name() == StepsFile || info.ModTime().Before(since) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(artifactsPath, path)
+		if err != nil {
+			return err
+		}
+		artifacts = append(artifacts, Artifact{Path: filepath.ToSlash(rel), Size: info.Size(), ModTime: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read artifacts: %v", err)
+	}
+
+	return artifacts, nil
+}
+
+// FormatSize formats the size in bytes in a human readable way (e.g. 1.5 MB).
+func FormatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,59 @@
+package launch
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadArtifacts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sd-artifacts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeSourceFiles(t, dir, map[string]string{
+		LogFile:                "log",
+		StepsFile:              "test\tSUCCESS\n",
+		"meta/meta.json":       "{}",
+		"reports/junit.xml":    "<testsuites/>",
+		"main/meta/meta.json":  "{}",
+		"main/coverage.out":    "mode: set",
+		"previous/results.txt": "old",
+	})
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "previous/results.txt"), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("success", func(t *testing.T) {
+		artifacts, err := ReadArtifacts(dir, time.Now().Add(-time.Minute))
+		assert.Nil(t, err)
+
+		paths := make([]string, 0, len(artifacts))
+		for _, a := range artifacts {
+			paths = append(paths, a.Path)
+		}
+		assert.Equal(t, []string{LogFile, "main/coverage.out", "reports/junit.xml"}, paths)
+		assert.Equal(t, int64(len("log")), artifacts[0].Size)
+	})
+
+	t.Run("success without artifacts directory", func(t *testing.T) {
+		artifacts, err := ReadArtifacts(filepath.Join(dir, "none"), time.Time{})
+		assert.Nil(t, err)
+		assert.Nil(t, artifacts)
+	})
+}
+
+func TestFormatSize(t *testing.T) {
+	assert.Equal(t, "0 B", FormatSize(0))
+	assert.Equal(t, "1023 B", FormatSize(1023))
+	assert.Equal(t, "1.0 KB", FormatSize(1024))
+	assert.Equal(t, "1.5 MB", FormatSize(1536*1024))
+	assert.Equal(t, "2.0 GB", FormatSize(2*1024*1024*1024))
+}
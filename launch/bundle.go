@@ -0,0 +1,62 @@
+package launch
+
+import (
+	"fmt"
+)
+
+// BundleOption is the option to save and load the bundle of the images.
+type BundleOption struct {
+	Runtime     string
+	Platform    string
+	UseSudo     bool
+	FlagVerbose bool
+}
+
+func newBundleRunner(option BundleOption) (*docker, error) {
+	runtime := option.Runtime
+	if runtime == "" {
+		runtime = detectRuntime()
+	}
+
+	if _, err := lookPath(runtime); err != nil {
+		return nil, fmt.Errorf("`%s` command is not found in $PATH: %v", runtime, err)
+	}
+
+	return newDocker(newContainerRuntime(runtime), "", "", option.UseSudo, false, "", option.Platform, PullMissing, option.FlagVerbose).(*docker), nil
+}
+
+// SaveImages saves the images into the tarball, which can be loaded on the machines without the network.
+// The images which are not stored locally are pulled before saving them.
+func SaveImages(path string, images []string, option BundleOption) error {
+	d, err := newBundleRunner(option)
+	if err != nil {
+		return err
+	}
+
+	for _, image := range images {
+		if err := d.pullImage(image); err != nil {
+			return fmt.Errorf("failed to pull image %s: %v", image, err)
+		}
+	}
+
+	if _, err := d.execDockerCommand(d.runtime.save(path, images...)...); err != nil {
+		return fmt.Errorf("failed to save images into %s: %v", path, err)
+	}
+
+	return nil
+}
+
+// LoadImages loads the images in the tarball saved by SaveImages, and returns the output of the container runtime.
+func LoadImages(path string, option BundleOption) (string, error) {
+	d, err := newBundleRunner(option)
+	if err != nil {
+		return "", err
+	}
+
+	out, err := d.execDockerCommand(d.runtime.load(path)...)
+	if err != nil {
+		return "", fmt.Errorf("failed to load images from %s: %v", path, err)
+	}
+
+	return out, nil
+}
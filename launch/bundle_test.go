@@ -0,0 +1,76 @@
+package launch
+
+import (
+	"fmt"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveImages(t *testing.T) {
+	defer func() {
+		execCommand = exec.Command
+		lookPath = exec.LookPath
+	}()
+	lookPath = func(file string) (string, error) { return "/usr/bin/" + file, nil }
+
+	t.Run("success", func(t *testing.T) {
+		c := newFakeExecCommand("IMAGE_NOT_FOUND")
+		execCommand = c.execCmd
+
+		err := SaveImages("/tmp/images.tar", []string{"launcher:stable", "node:18"}, BundleOption{Runtime: "podman", Platform: "linux/amd64"})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{
+			"podman image inspect launcher:stable",
+			"podman pull --platform linux/amd64 launcher:stable",
+			"podman image inspect node:18",
+			"podman pull --platform linux/amd64 node:18",
+			"podman save -m -o /tmp/images.tar launcher:stable node:18",
+		}, c.commands)
+	})
+
+	t.Run("failure by pull", func(t *testing.T) {
+		c := newFakeExecCommand("FAIL_BUILD_IMAGE_PULL")
+		execCommand = c.execCmd
+
+		err := SaveImages("/tmp/images.tar", []string{"node:18"}, BundleOption{Runtime: "docker"})
+		assert.EqualError(t, err, "failed to pull image node:18: exit status 1")
+	})
+
+	t.Run("failure without runtime", func(t *testing.T) {
+		lookPath = func(file string) (string, error) {
+			return "", fmt.Errorf("exec: %q: executable file not found in $PATH", file)
+		}
+		defer func() { lookPath = func(file string) (string, error) { return "/usr/bin/" + file, nil } }()
+
+		err := SaveImages("/tmp/images.tar", []string{"node:18"}, BundleOption{Runtime: "docker"})
+		assert.EqualError(t, err, "`docker` command is not found in $PATH: exec: \"docker\": executable file not found in $PATH")
+	})
+}
+
+func TestLoadImages(t *testing.T) {
+	defer func() {
+		execCommand = exec.Command
+		lookPath = exec.LookPath
+	}()
+	lookPath = func(file string) (string, error) { return "/usr/bin/" + file, nil }
+
+	t.Run("success", func(t *testing.T) {
+		c := newFakeExecCommand("SUCCESS_SETUP_BIN")
+		execCommand = c.execCmd
+
+		out, err := LoadImages("/tmp/images.tar", BundleOption{Runtime: "docker"})
+		assert.Nil(t, err)
+		assert.Equal(t, "SUCCESS_SETUP_BIN", out)
+		assert.Equal(t, []string{"docker load -i /tmp/images.tar"}, c.commands)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		c := newFakeExecCommand("FAIL_CREATING_VOLUME")
+		execCommand = c.execCmd
+
+		_, err := LoadImages("/tmp/images.tar", BundleOption{Runtime: "docker"})
+		assert.EqualError(t, err, "failed to load images from /tmp/images.tar: exit status 1")
+	})
+}
@@ -0,0 +1,93 @@
+package launch
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/screwdriver-cd/sd-local/screwdriver"
+)
+
+const (
+	// containerCacheDir is the directory where the cache directories on the host are mounted.
+	containerCacheDir = "/sd/cache"
+	cacheStrategy     = "disk"
+	setupCacheStep    = "sd-setup-cache"
+	teardownCacheStep = "sd-teardown-cache"
+)
+
+// CacheOption is the cache settings of the build and the host side directories storing caches.
+// The job caches are stored in the sub directories of JobDir named after the jobs.
+type CacheOption struct {
+	Settings    screwdriver.Cache
+	PipelineDir string
+	EventDir    string
+	JobDir      string
+}
+
+type cacheScope struct {
+	name    string
+	hostDir string
+	paths   []string
+}
+
+func (c CacheOption) scopes(jobName string) []cacheScope {
+	jobDir := ""
+	if c.JobDir != "" {
+		jobDir = filepath.Join(c.JobDir, jobName)
+	}
+
+	scopes := []cacheScope{
+		{"pipeline", c.PipelineDir, c.Settings.Pipeline},
+		{"event", c.EventDir, c.Settings.Event},
+		{"job", jobDir, c.Settings.Job[jobName]},
+	}
+
+	enabled := make([]cacheScope, 0, len(scopes))
+	for _, s := range scopes {
+		if len(s.paths) != 0 && s.hostDir != "" {
+			enabled = append(enabled, s)
+		}
+	}
+	return enabled
+}
+
+// HostDirs returns the host side directories which the build of the job uses.
+func (c CacheOption) HostDirs(jobName string) []string {
+	dirs := make([]string, 0, 3)
+	for _, s := range c.scopes(jobName) {
+		dirs = append(dirs, s.hostDir)
+	}
+	return dirs
+}
+
+// applyCache makes the build restore caches before the steps and store them after the steps with store-cli.
+// store-cli reads and writes caches in the mounted directories with the disk strategy.
+func applyCache(b *buildEntry, c CacheOption) {
+	scopes := c.scopes(b.JobName)
+	if len(scopes) == 0 {
+		return
+	}
+
+	env := b.Environment[0]
+	env["SD_CACHE_STRATEGY"] = cacheStrategy
+
+	restore := make([]string, 0)
+	store := make([]string, 0)
+	for _, s := range scopes {
+		containerDir := fmt.Sprintf("%s/%s", containerCacheDir, s.name)
+		env[fmt.Sprintf("SD_%s_CACHE_DIR", strings.ToUpper(s.name))] = containerDir
+		b.CacheVolumes = append(b.CacheVolumes, fmt.Sprintf("%s/:%s", filepath.Clean(s.hostDir), containerDir))
+
+		for _, p := range s.paths {
+			restore = append(restore, fmt.Sprintf("store-cli get %s --type=cache --scope=%s || true", p, s.name))
+			store = append(store, fmt.Sprintf("store-cli set %s --type=cache --scope=%s", p, s.name))
+		}
+	}
+
+	steps := make([]screwdriver.Step, 0, len(b.Steps)+2)
+	steps = append(steps, screwdriver.Step{Name: setupCacheStep, Command: strings.Join(restore, " && ")})
+	steps = append(steps, b.Steps...)
+	steps = append(steps, screwdriver.Step{Name: teardownCacheStep, Command: strings.Join(store, " && ")})
+	b.Steps = steps
+}
@@ -0,0 +1,65 @@
+package launch
+
+import (
+	"testing"
+
+	"github.com/screwdriver-cd/sd-local/screwdriver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyCache(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		b := newBuildEntry()
+		applyCache(&b, CacheOption{
+			Settings: screwdriver.Cache{
+				Pipeline: []string{"~/.gradle"},
+				Job: map[string][]string{
+					"test":  {"$SD_SOURCE_DIR/node_modules"},
+					"other": {"$SD_SOURCE_DIR/other"},
+				},
+			},
+			PipelineDir: "/cache/pipeline",
+			EventDir:    "/cache/event/1",
+			JobDir:      "/cache/job",
+		})
+
+		assert.Equal(t, []string{
+			"/cache/pipeline/:/sd/cache/pipeline",
+			"/cache/job/test/:/sd/cache/job",
+		}, b.CacheVolumes)
+
+		env := b.Environment[0]
+		assert.Equal(t, "disk", env["SD_CACHE_STRATEGY"])
+		assert.Equal(t, "/sd/cache/pipeline", env["SD_PIPELINE_CACHE_DIR"])
+		assert.Equal(t, "/sd/cache/job", env["SD_JOB_CACHE_DIR"])
+		assert.NotContains(t, env, "SD_EVENT_CACHE_DIR")
+
+		assert.Equal(t, []screwdriver.Step{
+			{Name: "sd-setup-cache", Command: "store-cli get ~/.gradle --type=cache --scope=pipeline || true && store-cli get $SD_SOURCE_DIR/node_modules --type=cache --scope=job || true"},
+			{Name: "test", Command: "npm test"},
+			{Name: "sd-teardown-cache", Command: "store-cli set ~/.gradle --type=cache --scope=pipeline && store-cli set $SD_SOURCE_DIR/node_modules --type=cache --scope=job"},
+		}, b.Steps)
+	})
+
+	t.Run("success with host directories", func(t *testing.T) {
+		c := CacheOption{
+			Settings: screwdriver.Cache{
+				Event: []string{"dist"},
+				Job:   map[string][]string{"test": {"node_modules"}},
+			},
+			PipelineDir: "/cache/pipeline",
+			EventDir:    "/cache/event/1",
+			JobDir:      "/cache/job",
+		}
+
+		assert.Equal(t, []string{"/cache/event/1", "/cache/job/test"}, c.HostDirs("test"))
+		assert.Equal(t, []string{"/cache/event/1"}, c.HostDirs("other"))
+	})
+
+	t.Run("success without cache", func(t *testing.T) {
+		b := newBuildEntry()
+		applyCache(&b, CacheOption{PipelineDir: "/cache/pipeline"})
+
+		assert.Equal(t, newBuildEntry(), b)
+	})
+}
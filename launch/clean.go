@@ -0,0 +1,215 @@
+package launch
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// buildResourcePattern matches the names of the containers and volumes of the builds, which are named after pausedContainerName
+	buildResourcePattern = regexp.MustCompile(`^sd-local-`)
+	// launcherVolumePattern matches the volumes of the binaries of the launcher, which are named after launcherVolumes
+	launcherVolumePattern = regexp.MustCompile(`^SD_LAUNCH_(BIN|HAB)(_.+)?$`)
+	// tempFilePatterns match the temporary files of sd-local, which are left when it is killed,
+	// such as the caches downloaded from S3 and screwdriver.yaml with the inlined template.
+	tempFilePatterns = []*regexp.Regexp{
+		regexp.MustCompile(`^sd-local-cache[0-9]+$`),
+		regexp.MustCompile(`^screwdriver[0-9]+\.yaml$`),
+	}
+	tempDir = os.TempDir
+)
+
+// CleanOption is the option to remove the resources left by the builds.
+type CleanOption struct {
+	Runtime     string
+	UseSudo     bool
+	FlagVerbose bool
+	// All removes the images and the volumes which make the next builds faster, such as the launcher images, the snapshots and the language caches
+	All bool
+	// LauncherImage is the repository of the launcher images, which are removed with All
+	LauncherImage string
+	// Active is the pids of the running builds, whose containers, volumes and temporary files are kept
+	Active []int
+}
+
+// CleanResult is the resources removed by Clean.
+type CleanResult struct {
+	Containers []string
+	Volumes    []string
+	Images     []string
+	TempFiles  []string
+	// Reclaimed is the disk space in bytes freed by removing the containers, the images and the temporary files.
+	// The volumes are not counted, because the container runtimes don't report their sizes.
+	Reclaimed int64
+}
+
+// Clean removes the build containers kept with --reuse, the stopped containers and the dangling volumes of the builds,
+// and the temporary files of sd-local. The images and the volumes of the caches are also removed with All.
+func Clean(option CleanOption) (CleanResult, error) {
+	runtime := option.Runtime
+	if runtime == "" {
+		runtime = detectRuntime()
+	}
+	if _, err := lookPath(runtime); err != nil {
+		return CleanResult{}, fmt.Errorf("`%s` command is not found in $PATH: %v", runtime, err)
+	}
+	d := newDocker(newContainerRuntime(runtime), "", "", option.UseSudo, false, "", "", PullMissing, option.FlagVerbose).(*docker)
+
+	active := make([]*regexp.Regexp, 0, len(option.Active))
+	for _, pid := range option.Active {
+		active = append(active, orphanPattern(pid))
+	}
+	removable := func(name string) bool {
+		for _, p := range active {
+			if p.MatchString(name) {
+				return false
+			}
+		}
+		return buildResourcePattern.MatchString(name)
+	}
+
+	var result CleanResult
+
+	// The containers are removed at first, because the volumes in use can not be removed
+	kept, err := d.execDockerCommand(d.runtime.containerList("label=" + ReuseLabel)...)
+	if err != nil {
+		return result, fmt.Errorf("failed to list kept containers: %v", err)
+	}
+	stopped, err := d.execDockerCommand(d.runtime.containerList("status=exited")...)
+	if err != nil {
+		return result, fmt.Errorf("failed to list stopped containers: %v", err)
+	}
+	for _, name := range append(strings.Fields(kept), strings.Fields(stopped)...) {
+		if removable(name) && !contains(result.Containers, name) {
+			result.Containers = append(result.Containers, name)
+		}
+	}
+	if len(result.Containers) != 0 {
+		for _, name := range result.Containers {
+			result.Reclaimed += d.size(d.runtime.containerSize(name)...)
+		}
+		if _, err := d.execDockerCommand(d.runtime.containerRemove(result.Containers...)...); err != nil {
+			return CleanResult{}, fmt.Errorf("failed to remove containers: %v", err)
+		}
+	}
+
+	dangling, err := d.execDockerCommand(d.runtime.volumeList("dangling=true")...)
+	if err != nil {
+		return result, fmt.Errorf("failed to list dangling volumes: %v", err)
+	}
+	for _, name := range strings.Fields(dangling) {
+		cache := strings.HasPrefix(name, LangCacheVolumePrefix) || launcherVolumePattern.MatchString(name)
+		if cache && !option.All || !cache && !removable(name) {
+			continue
+		}
+		// The volume which has been mounted since it is listed is kept
+		if _, err := d.execDockerCommand(d.runtime.volumeRemove(name)...); err != nil {
+			logrus.Warn(fmt.Errorf("failed to remove volume %s: %v", name, err))
+			continue
+		}
+		result.Volumes = append(result.Volumes, name)
+	}
+
+	if option.All {
+		filters := []string{"label=" + SnapshotLabel, "reference=" + strings.Split(DockerfileImage{}.Tag(), ":")[0]}
+		if option.LauncherImage != "" {
+			filters = append(filters, "reference="+option.LauncherImage)
+		}
+		for _, f := range filters {
+			images, err := d.execDockerCommand(d.runtime.imageList(f)...)
+			if err != nil {
+				return result, fmt.Errorf("failed to list images: %v", err)
+			}
+			for _, image := range strings.Fields(images) {
+				if strings.Contains(image, "<none>") || contains(result.Images, image) {
+					continue
+				}
+				size := d.size(d.runtime.imageSize(image)...)
+				// The image used by the other containers is kept
+				if _, err := d.execDockerCommand(d.runtime.imageRemove(image)...); err != nil {
+					logrus.Warn(fmt.Errorf("failed to remove image %s: %v", image, err))
+					continue
+				}
+				result.Images = append(result.Images, image)
+				result.Reclaimed += size
+			}
+		}
+	}
+
+	// The temporary files are not related to the builds, so they are kept while any build is running
+	if len(option.Active) == 0 {
+		files, size := removeTempFiles(tempDir())
+		result.TempFiles = files
+		result.Reclaimed += size
+	}
+
+	return result, nil
+}
+
+// size returns the size in bytes printed by the command, or 0 when it fails such as with the runtime which doesn't support it.
+func (d *docker) size(args ...string) int64 {
+	commands := d.commandLine(args...)
+	out, err := execCommand(commands[0], commands[1:]...).Output()
+	if err != nil {
+		return 0
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// removeTempFiles removes the temporary files of sd-local in dir, and returns their paths and total size.
+func removeTempFiles(dir string) ([]string, int64) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		logrus.Warn(fmt.Errorf("failed to read temporary directory: %v", err))
+		return nil, 0
+	}
+
+	var files []string
+	var total int64
+	for _, info := range infos {
+		if !matchAny(tempFilePatterns, info.Name()) {
+			continue
+		}
+		path := filepath.Join(dir, info.Name())
+		size := dirSize(path)
+		if err := os.RemoveAll(path); err != nil {
+			logrus.Warn(fmt.Errorf("failed to remove temporary file: %v", err))
+			continue
+		}
+		files = append(files, path)
+		total += size
+	}
+	return files, total
+}
+
+// dirSize returns the total size of the files under path, which is the size of the file itself when path is a file.
+func dirSize(path string) int64 {
+	var size int64
+	filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
+
+func matchAny(patterns []*regexp.Regexp, name string) bool {
+	for _, p := range patterns {
+		if p.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
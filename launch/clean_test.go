@@ -0,0 +1,144 @@
+package launch
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// cleanHelperProcess fakes the container runtime which has the resources left by the builds.
+func cleanHelperProcess(testCase, subcmd string, args []string) {
+	command := subcmd + " " + strings.Join(args, " ")
+
+	switch {
+	case testCase == "CLEAN_LIST_FAILS" && strings.HasPrefix(command, "container ls"):
+		os.Exit(1)
+	case testCase == "CLEAN_NONE":
+	case strings.Contains(command, "label=sd-local.reuse"):
+		fmt.Print("sd-local-reuse-main-0123456789ab\n")
+	case strings.Contains(command, "status=exited"):
+		fmt.Print("sd-local-reuse-main-0123456789ab\nsd-local-main-123\nsd-local-main-456\nweb\n")
+	case strings.Contains(command, "dangling=true"):
+		fmt.Print("SD_LAUNCH_BIN\nsd-local-cache-npm\nsd-local-main-123_data\nsd-local-main-456_data\n0123456789abcdef\n")
+	case strings.Contains(command, "label=sd-local.snapshot"):
+		fmt.Print("sd-local/snapshot:0123456789abcdef\n<none>:<none>\n")
+	case strings.Contains(command, "reference=screwdrivercd/launcher"):
+		fmt.Print("screwdrivercd/launcher:stable\n")
+	case strings.HasPrefix(command, "container inspect --size"):
+		fmt.Print("100\n")
+	case strings.HasPrefix(command, "image inspect --format {{.Size}} screwdrivercd/launcher"):
+		fmt.Print("1000\n")
+	case strings.HasPrefix(command, "image rm sd-local/snapshot"):
+		// The snapshot is used by the other container
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func TestCleanResources(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tmp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	defer func() {
+		execCommand = exec.Command
+		lookPath = exec.LookPath
+		tempDir = os.TempDir
+	}()
+	lookPath = func(file string) (string, error) { return "/usr/bin/" + file, nil }
+	tempDir = func() string { return dir }
+
+	writeTempFiles := func() {
+		for _, f := range []string{"sd-local-cache123", "screwdriver456.yaml", "screwdriver.yaml"} {
+			if err := ioutil.WriteFile(filepath.Join(dir, f), []byte("12345"), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	t.Run("success", func(t *testing.T) {
+		writeTempFiles()
+		c := newFakeExecCommand("CLEAN_SUCCESS")
+		execCommand = c.execCmd
+
+		result, err := Clean(CleanOption{Runtime: "docker"})
+
+		assert.Nil(t, err)
+		assert.Equal(t, CleanResult{
+			Containers: []string{"sd-local-reuse-main-0123456789ab", "sd-local-main-123", "sd-local-main-456"},
+			Volumes:    []string{"sd-local-main-123_data", "sd-local-main-456_data"},
+			TempFiles:  []string{filepath.Join(dir, "screwdriver456.yaml"), filepath.Join(dir, "sd-local-cache123")},
+			Reclaimed:  310,
+		}, result)
+		assert.Equal(t, []string{
+			"docker container ls --all --filter label=sd-local.reuse --format {{.Names}}",
+			"docker container ls --all --filter status=exited --format {{.Names}}",
+			"docker container inspect --size --format {{.SizeRw}} sd-local-reuse-main-0123456789ab",
+			"docker container inspect --size --format {{.SizeRw}} sd-local-main-123",
+			"docker container inspect --size --format {{.SizeRw}} sd-local-main-456",
+			"docker container rm --force sd-local-reuse-main-0123456789ab sd-local-main-123 sd-local-main-456",
+			"docker volume ls --filter dangling=true --format {{.Name}}",
+			"docker volume rm --force sd-local-main-123_data",
+			"docker volume rm --force sd-local-main-456_data",
+		}, c.commands)
+
+		_, err = os.Stat(filepath.Join(dir, "screwdriver.yaml"))
+		assert.Nil(t, err)
+	})
+
+	t.Run("success with all", func(t *testing.T) {
+		c := newFakeExecCommand("CLEAN_SUCCESS")
+		execCommand = c.execCmd
+
+		result, err := Clean(CleanOption{Runtime: "docker", All: true, LauncherImage: "screwdrivercd/launcher", Active: []int{456}})
+
+		assert.Nil(t, err)
+		assert.Equal(t, CleanResult{
+			Containers: []string{"sd-local-reuse-main-0123456789ab", "sd-local-main-123"},
+			Volumes:    []string{"SD_LAUNCH_BIN", "sd-local-cache-npm", "sd-local-main-123_data"},
+			Images:     []string{"screwdrivercd/launcher:stable"},
+			Reclaimed:  1200,
+		}, result)
+		assert.Equal(t, []string{
+			"docker image ls --filter label=sd-local.snapshot --format {{.Repository}}:{{.Tag}}",
+			"docker image inspect --format {{.Size}} sd-local/snapshot:0123456789abcdef",
+			"docker image rm sd-local/snapshot:0123456789abcdef",
+			"docker image ls --filter reference=sd-local/build-image --format {{.Repository}}:{{.Tag}}",
+			"docker image ls --filter reference=screwdrivercd/launcher --format {{.Repository}}:{{.Tag}}",
+			"docker image inspect --format {{.Size}} screwdrivercd/launcher:stable",
+			"docker image rm screwdrivercd/launcher:stable",
+		}, c.commands[len(c.commands)-7:])
+	})
+
+	t.Run("success with sudo", func(t *testing.T) {
+		c := newFakeExecCommand("CLEAN_NONE")
+		execCommand = c.execCmd
+
+		result, err := Clean(CleanOption{Runtime: "podman", UseSudo: true, Active: []int{123}})
+
+		assert.Nil(t, err)
+		assert.Equal(t, CleanResult{}, result)
+		assert.Equal(t, []string{
+			"sudo podman container ls --all --filter label=sd-local.reuse --format {{.Names}}",
+			"sudo podman container ls --all --filter status=exited --format {{.Names}}",
+			"sudo podman volume ls --filter dangling=true --format {{.Name}}",
+		}, c.commands)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		c := newFakeExecCommand("CLEAN_LIST_FAILS")
+		execCommand = c.execCmd
+
+		_, err := Clean(CleanOption{Runtime: "docker"})
+
+		assert.Equal(t, "failed to list kept containers: exit status 1", err.Error())
+	})
+}
@@ -0,0 +1,37 @@
+package launch
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+
+	"github.com/sirupsen/logrus"
+)
+
+// containerCommandDir is the directory where sd-cmd stores the binaries of the shared commands.
+const containerCommandDir = "/opt/sd/commands"
+
+var sdCmdPattern = regexp.MustCompile(`(^|[^\w-])sd-cmd\s`)
+
+// usesSharedCommands reports whether any step of the build runs sd-cmd.
+func usesSharedCommands(b buildEntry) bool {
+	for _, s := range b.Steps {
+		if sdCmdPattern.MatchString(s.Command) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyCommands mounts hostDir on the directory of the shared commands,
+// so that the binaries downloaded by sd-cmd in a build are reused in the next builds.
+// sd-cmd fetches the commands with $SD_API_URL, $SD_STORE_URL and $SD_TOKEN, which are set for every build.
+func applyCommands(b *buildEntry, hostDir string) {
+	if hostDir != "" {
+		b.CommandsVolume = fmt.Sprintf("%s/:%s", filepath.Clean(hostDir), containerCommandDir)
+	}
+
+	if b.Environment[0]["SD_TOKEN"] == "" && usesSharedCommands(*b) {
+		logrus.Warn("sd-cmd can not fetch the shared commands without the token of Screwdriver.cd API, run the build without --offline")
+	}
+}
@@ -0,0 +1,46 @@
+package launch
+
+import (
+	"testing"
+
+	"github.com/screwdriver-cd/sd-local/screwdriver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyCommands(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		b := newBuildEntry()
+		applyCommands(&b, "/home/user/.sdlocal/commands/")
+
+		assert.Equal(t, "/home/user/.sdlocal/commands/:/opt/sd/commands", b.CommandsVolume)
+	})
+
+	t.Run("success without host directory", func(t *testing.T) {
+		b := newBuildEntry()
+		applyCommands(&b, "")
+
+		assert.Equal(t, "", b.CommandsVolume)
+	})
+}
+
+func TestUsesSharedCommands(t *testing.T) {
+	testCases := []struct {
+		name     string
+		command  string
+		expected bool
+	}{
+		{"exec", "sd-cmd exec foo/bar@1 arg", true},
+		{"after other command", "cd dist && sd-cmd exec foo/bar@stable", true},
+		{"without sd-cmd", "npm test", false},
+		{"other command", "my-sd-cmd exec foo/bar@1", false},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newBuildEntry(func(b *buildEntry) {
+				b.Steps = []screwdriver.Step{{Name: "test", Command: tt.command}}
+			})
+			assert.Equal(t, tt.expected, usesSharedCommands(b))
+		})
+	}
+}
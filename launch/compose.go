@@ -0,0 +1,71 @@
+package launch
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// composeProject is a docker-compose project run alongside the build.
+type composeProject struct {
+	file string
+	name string
+	env  []string
+}
+
+var invalidComposeProjectChars = regexp.MustCompile(`[^a-z0-9_-]`)
+
+// composeProjectName returns the name of the compose project, which is unique to the job and the process of sd-local.
+// The names of the compose projects consist of lower case alphanumerics, - and _.
+func composeProjectName(jobName string) string {
+	return invalidComposeProjectChars.ReplaceAllString(strings.ToLower(pausedContainerName(jobName)), "-")
+}
+
+// composeNetwork returns the default network of the compose project, where its services are reachable with their names.
+func composeNetwork(project string) string {
+	return project + "_default"
+}
+
+// composeEnv returns the environment variables of the compose command, which are interpolated into the compose file.
+func composeEnv(env EnvVar) []string {
+	vars := make([]string, 0, len(env))
+	for k, v := range env {
+		vars = append(vars, fmt.Sprintf("%s=%s", k, v))
+	}
+	sort.Strings(vars)
+	return vars
+}
+
+// composeUp starts the services of the compose file, and returns the default network of the project after they are healthy.
+// The project is removed with composeDown even if it fails to start.
+func (d *docker) composeUp(jobName, file string, env EnvVar) (string, error) {
+	project := composeProject{file: file, name: composeProjectName(jobName), env: composeEnv(env)}
+	d.composeProjects = append(d.composeProjects, project)
+
+	logrus.Infof("Starting the services of %s...", file)
+	cmd := d.envCommand(project.env, d.runtime.composeUp(project.file, project.name)...)
+	// The progress is shown because the services may take long to be healthy
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("failed to start the services of %s: %v", file, err)
+	}
+
+	return composeNetwork(project.name), nil
+}
+
+// composeDown removes the services, networks and volumes of the compose projects of the builds.
+func (d *docker) composeDown() {
+	for _, project := range d.composeProjects {
+		// The compose file is interpolated in the same way as it is started
+		cmd := d.envCommand(project.env, d.runtime.composeDown(project.file, project.name)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			logrus.Warn(fmt.Errorf("failed to remove the services of %s: %v: %s", project.file, err, out))
+		}
+	}
+	d.composeProjects = nil
+}
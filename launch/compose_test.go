@@ -0,0 +1,74 @@
+package launch
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComposeProjectName(t *testing.T) {
+	assert.Equal(t, fmt.Sprintf("sd-local-pr-1-main-%d", os.Getpid()), composeProjectName("PR-1:main"))
+	assert.Equal(t, fmt.Sprintf("sd-local-pr-1-main-%d_default", os.Getpid()), composeNetwork(composeProjectName("PR-1:main")))
+}
+
+func TestComposeEnv(t *testing.T) {
+	assert.Equal(t, []string{"A=1", "B=2"}, composeEnv(EnvVar{"B": "2", "A": "1"}))
+	assert.Equal(t, []string{}, composeEnv(nil))
+}
+
+func TestComposeUp(t *testing.T) {
+	defer func() {
+		execCommand = exec.Command
+	}()
+
+	project := composeProjectName("main")
+	env := EnvVar{"DB_PASSWORD": "password"}
+
+	testCases := []struct {
+		name            string
+		id              string
+		d               *docker
+		expectError     string
+		expectedCommand string
+		expectedDown    string
+	}{
+		{"success", "SUCCESS_COMPOSE", &docker{runtime: &dockerRuntime{}, mutex: &sync.Mutex{}}, "",
+			fmt.Sprintf("docker compose -f /repo/docker-compose.yml -p %s up -d --wait", project),
+			fmt.Sprintf("docker compose -f /repo/docker-compose.yml -p %s down --volumes --remove-orphans", project)},
+		{"success with sudo", "SUCCESS_COMPOSE", &docker{runtime: &dockerRuntime{}, mutex: &sync.Mutex{}, useSudo: true}, "",
+			fmt.Sprintf("sudo DB_PASSWORD=password docker compose -f /repo/docker-compose.yml -p %s up -d --wait", project),
+			fmt.Sprintf("sudo DB_PASSWORD=password docker compose -f /repo/docker-compose.yml -p %s down --volumes --remove-orphans", project)},
+		{"success with nerdctl", "SUCCESS_COMPOSE", &docker{runtime: &nerdctlRuntime{}, mutex: &sync.Mutex{}}, "",
+			fmt.Sprintf("nerdctl compose -f /repo/docker-compose.yml -p %s up -d", project),
+			fmt.Sprintf("nerdctl compose -f /repo/docker-compose.yml -p %s down --volumes --remove-orphans", project)},
+		{"failure", "FAIL_COMPOSE_UP", &docker{runtime: &dockerRuntime{}, mutex: &sync.Mutex{}}, "failed to start the services of /repo/docker-compose.yml: exit status 1",
+			fmt.Sprintf("docker compose -f /repo/docker-compose.yml -p %s up -d --wait", project),
+			fmt.Sprintf("docker compose -f /repo/docker-compose.yml -p %s down --volumes --remove-orphans", project)},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newFakeExecCommand(tt.id)
+			execCommand = c.execCmd
+
+			network, err := tt.d.composeUp("main", "/repo/docker-compose.yml", env)
+			if tt.expectError != "" {
+				assert.EqualError(t, err, tt.expectError)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, project+"_default", network)
+			}
+			assert.Equal(t, []string{tt.expectedCommand}, c.commands)
+
+			// The project is removed even if it fails to start
+			c.commands = nil
+			tt.d.composeDown()
+			assert.Equal(t, []string{tt.expectedDown}, c.commands)
+			assert.Nil(t, tt.d.composeProjects)
+		})
+	}
+}
@@ -7,7 +7,9 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 	"sync"
@@ -19,6 +21,7 @@ import (
 )
 
 type docker struct {
+	runtime           containerRuntime
 	volume            string
 	habVolume         string
 	setupImage        string
@@ -30,10 +33,27 @@ type docker struct {
 	flagVerbose       bool
 	interact          Interacter
 	socketPath        string
+	platform          string
+	pullPolicy        string
+	services          []string
+	networks          []string
+	composeProjects   []composeProject
+	container         string
+	interruptible     bool
+	interrupted       bool
+	reuse             bool
 }
 
 var _ runner = (*docker)(nil)
 var execCommand = exec.Command
+var invalidContainerNameChars = regexp.MustCompile(`[^a-zA-Z0-9_.-]`)
+
+var (
+	// interruptTimeout is the time to wait for the teardown steps after the running step is interrupted
+	interruptTimeout = 5 * time.Minute
+	// interruptInterval is the interval to check whether the interrupted build has exited
+	interruptInterval = 1 * time.Second
+)
 
 const (
 	// ArtifactsDir is default artifact directory name
@@ -45,10 +65,22 @@ const (
 	orgRepo = "sd-local/local-build"
 )
 
-func newDocker(setupImage, setupImageVer string, useSudo bool, interactiveMode bool, socketPath string, flagVerbose bool) runner {
+// launcherVolumes returns the volumes of the binaries of the launcher.
+// The volumes are separated by the platform, because the binaries of a platform can not run on the others.
+func launcherVolumes(platform string) (string, string) {
+	if platform == "" {
+		return "SD_LAUNCH_BIN", "SD_LAUNCH_HAB"
+	}
+	suffix := "_" + strings.ReplaceAll(platform, "/", "_")
+	return "SD_LAUNCH_BIN" + suffix, "SD_LAUNCH_HAB" + suffix
+}
+
+func newDocker(runtime containerRuntime, setupImage, setupImageVer string, useSudo bool, interactiveMode bool, socketPath string, platform string, pullPolicy string, flagVerbose bool) runner {
+	volume, habVolume := launcherVolumes(platform)
 	return &docker{
-		volume:            "SD_LAUNCH_BIN",
-		habVolume:         "SD_LAUNCH_HAB",
+		runtime:           runtime,
+		volume:            volume,
+		habVolume:         habVolume,
 		setupImage:        setupImage,
 		setupImageVersion: setupImageVer,
 		useSudo:           useSudo,
@@ -58,16 +90,74 @@ func newDocker(setupImage, setupImageVer string, useSudo bool, interactiveMode b
 		flagVerbose:       flagVerbose,
 		interact:          &Interact{},
 		socketPath:        socketPath,
+		platform:          platform,
+		pullPolicy:        pullPolicy,
+	}
+}
+
+// platformOptions returns the options to pull and run the images of the platform, or none for the platform of the host.
+func (d *docker) platformOptions() []string {
+	if d.platform == "" {
+		return nil
+	}
+	return []string{"--platform", d.platform}
+}
+
+// imageExists reports whether the image is stored in the container runtime.
+// The command is run quietly, because the error of the missing image is expected.
+func (d *docker) imageExists(image string) bool {
+	return d.runQuietly(d.runtime.imageInspect(image)...) == nil
+}
+
+// pullImage pulls the image according to the pull policy.
+// The local image is used when it fails to pull the image, such as without the network.
+func (d *docker) pullImage(image string) error {
+	switch d.pullPolicy {
+	case PullNever:
+		if !d.imageExists(image) {
+			return fmt.Errorf("%s is not found locally, and it is not pulled with --pull %s", image, PullNever)
+		}
+		return nil
+	case PullMissing:
+		if d.imageExists(image) {
+			return nil
+		}
+	}
+
+	logrus.Infof("Pulling docker image from %s...", image)
+	_, err := d.execDockerCommand(d.runtime.pull(image, d.platformOptions()...)...)
+	// The images loaded from the bundle are used on the machines without the network
+	if err != nil && d.pullPolicy != PullMissing && d.imageExists(image) {
+		logrus.Warnf("failed to pull %s, so the local image is used: %v", image, err)
+		return nil
 	}
+	return err
+}
+
+// buildImage builds the image from the Dockerfile with BuildKit, which reuses the cached layers of the previous builds,
+// and returns the tag of the built image.
+func (d *docker) buildImage(image DockerfileImage) (string, error) {
+	tag := image.Tag()
+	logrus.Infof("Building docker image %s from %s...", tag, image)
+
+	cmd := d.envCommand([]string{"DOCKER_BUILDKIT=1"}, d.runtime.build(image.Dockerfile, image.Target, tag, image.Context(), d.platformOptions()...)...)
+	// The progress is shown because the build may take long
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", err
+	}
+
+	return tag, nil
 }
 
 func (d *docker) setupBin() error {
-	_, err := d.execDockerCommand("volume", "create", "--name", d.volume)
+	_, err := d.execDockerCommand(d.runtime.volumeCreate(d.volume)...)
 	if err != nil {
 		return fmt.Errorf("failed to create docker volume: %v", err)
 	}
 
-	_, err = d.execDockerCommand("volume", "create", "--name", d.habVolume)
+	_, err = d.execDockerCommand(d.runtime.volumeCreate(d.habVolume)...)
 	if err != nil {
 		return fmt.Errorf("failed to create docker hab volume: %v", err)
 	}
@@ -75,12 +165,13 @@ func (d *docker) setupBin() error {
 	mount := fmt.Sprintf("%s:/opt/sd/", d.volume)
 	habMount := fmt.Sprintf("%s:/hab", d.habVolume)
 	image := fmt.Sprintf("%s:%s", d.setupImage, d.setupImageVersion)
-	_, err = d.execDockerCommand("pull", image)
+	err = d.pullImage(image)
 	if err != nil {
 		return fmt.Errorf("failed to pull launcher image: %v", err)
 	}
 
-	_, err = d.execDockerCommand("container", "run", "--rm", "-v", mount, "-v", habMount, "--entrypoint", "/bin/echo", image, "set up bin")
+	options := append(d.platformOptions(), "--rm", "-v", mount, "-v", habMount, "--entrypoint", "/bin/echo", image, "set up bin")
+	_, err = d.execDockerCommand(d.runtime.run(options...)...)
 	if err != nil {
 		return fmt.Errorf("failed to prepare build scripts: %v", err)
 	}
@@ -89,26 +180,36 @@ func (d *docker) setupBin() error {
 }
 
 func (d *docker) runBuild(buildEntry buildEntry) error {
-	environment := buildEntry.Environment[0]
-
-	srcDir := buildEntry.SrcPath
 	hostArtDir := buildEntry.ArtifactsPath
-	containerArtDir := environment["SD_ARTIFACTS_DIR"]
+	containerArtDir := buildEntry.Environment[0]["SD_ARTIFACTS_DIR"]
 	buildImage := buildEntry.Image
-	logfilePath := filepath.Join(containerArtDir, LogFile)
-
-	srcVol := fmt.Sprintf("%s/:/sd/workspace/src/%s/%s", srcDir, scmHost, orgRepo)
-	artVol := fmt.Sprintf("%s/:%s", hostArtDir, containerArtDir)
-	binVol := fmt.Sprintf("%s:%s", d.volume, "/opt/sd")
-	habVol := fmt.Sprintf("%s:%s", d.habVolume, "/opt/sd/hab")
 
 	// Overwrite steps for sd-local interact mode. The env will load later.
+	// The caches are restored so that the shell is in the same state as the build.
 	if d.interactiveMode {
-		buildEntry.Steps = []screwdriver.Step{
-			{
-				Name:    "sd-local-init",
-				Command: "env > /tmp/sd-local.env",
-			},
+		steps := make([]screwdriver.Step, 0, 2)
+		for _, s := range buildEntry.Steps {
+			if s.Name == setupCacheStep {
+				steps = append(steps, s)
+			}
+		}
+		buildEntry.Steps = append(steps, screwdriver.Step{
+			Name:    "sd-local-init",
+			Command: "env > /tmp/sd-local.env",
+		})
+	}
+
+	// The build is started from the snapshot taken after the setup steps if it exists, or the snapshot is taken in the build
+	fromSnapshot, snapshot := false, ""
+	if buildEntry.SnapshotImage != "" && !d.interactiveMode {
+		if d.imageExists(buildEntry.SnapshotImage) {
+			logrus.Infof("Starting the build from the snapshot %s taken after step %s", buildEntry.SnapshotImage, buildEntry.SnapshotAfter)
+			fromSnapshot, buildImage = true, buildEntry.SnapshotImage
+			buildEntry.Image = buildImage
+			buildEntry.Steps = skipSetupSteps(buildEntry.Steps, buildEntry.SnapshotAfter)
+		} else {
+			snapshot = buildEntry.SnapshotImage
+			buildEntry.Steps = insertSnapshotStep(buildEntry.Steps, buildEntry.SnapshotAfter, containerArtDir)
 		}
 	}
 
@@ -117,42 +218,65 @@ func (d *docker) runBuild(buildEntry buildEntry) error {
 		return err
 	}
 
-	logrus.Infof("Pulling docker image from %s...", buildImage)
-	_, err = d.execDockerCommand("pull", buildImage)
-	if err != nil {
-		return fmt.Errorf("failed to pull user image %v", err)
+	if IsDockerfileImage(buildImage) {
+		buildImage, err = d.buildImage(ParseDockerfileImage(buildImage))
+		if err != nil {
+			return fmt.Errorf("failed to build user image: %v", err)
+		}
+	} else if !fromSnapshot && (!buildEntry.Reuse || !d.containerExists(reuseContainerName(buildEntry.JobName, buildEntry.SrcPath))) {
+		// The snapshot is stored only locally, and the image of the kept container is not pulled again because the container is reused
+		err = d.pullImage(buildImage)
+		if err != nil {
+			return fmt.Errorf("failed to pull user image %v", err)
+		}
 	}
 
-	dockerCommandArgs := []string{"container", "run"}
-	dockerCommandOptions := []string{"--rm", "-v", srcVol, "-v", artVol, "-v", binVol, "-v", habVol, "-v", fmt.Sprintf("%s:/tmp/auth.sock", d.socketPath), "-e", "SSH_AUTH_SOCK=/tmp/auth.sock", buildImage}
-	configJSONArg := string(configJSON)
-	if d.interactiveMode {
-		configJSONArg = fmt.Sprintf("%q", configJSONArg)
+	// The services are run on the network of the build, and removed after the build.
+	// The build and the services join the network of the compose project if it is used.
+	network := buildEntry.Network
+	if buildEntry.ComposeFile != "" {
+		defer d.composeDown()
+		network, err = d.composeUp(buildEntry.JobName, buildEntry.ComposeFile, buildEntry.ComposeEnv)
+		if err != nil {
+			return err
+		}
 	}
-	launchCommands := []string{"/opt/sd/local_run.sh", configJSONArg, buildEntry.JobName, environment["SD_API_URL"], environment["SD_STORE_URL"], logfilePath}
-	if d.interactiveMode {
-		dockerCommandOptions = append([]string{"-itd"}, dockerCommandOptions...)
-		dockerCommandOptions = append(dockerCommandOptions, "/bin/sh")
-	} else {
-		dockerCommandOptions = append(dockerCommandOptions, launchCommands...)
+	if len(buildEntry.Services) != 0 {
+		defer d.stopServices()
+		network, err = d.startServices(buildEntry.JobName, buildEntry.Services, network)
+		if err != nil {
+			return err
+		}
 	}
 
-	if buildEntry.MemoryLimit != "" {
-		dockerCommandOptions = append([]string{fmt.Sprintf("-m%s", buildEntry.MemoryLimit)}, dockerCommandOptions...)
-	}
+	name := buildContainerName(buildEntry)
+	launchCommands := d.launchCommands(buildEntry, string(configJSON))
+	dockerCommandOptions := d.runOptions(buildEntry, name, buildImage, network, launchCommands)
 
-	if buildEntry.UsePrivileged {
-		dockerCommandOptions = append([]string{"--privileged"}, dockerCommandOptions...)
+	// The paused container waits for the signal instead of running the teardown steps
+	d.mutex.Lock()
+	d.container, d.interruptible, d.reuse = name, !d.interactiveMode && !buildEntry.PauseOnFailure, buildEntry.Reuse
+	d.mutex.Unlock()
+	defer func() {
+		d.mutex.Lock()
+		d.container = ""
+		d.mutex.Unlock()
+	}()
+
+	if snapshot != "" {
+		done := make(chan struct{})
+		defer close(done)
+		go d.watchSnapshot(name, snapshot, buildEntry.JobName, hostArtDir, done)
 	}
 
 	if d.interactiveMode {
 		// attach build container for sd-local interact mode
-		cid, err := d.execDockerCommand(append(dockerCommandArgs, dockerCommandOptions...)...)
+		cid, err := d.execDockerCommand(d.runtime.run(dockerCommandOptions...)...)
 		if err != nil {
 			return fmt.Errorf("failed to run build container: %v", err)
 		}
 
-		attachCommands := []string{"attach", cid}
+		attachCommands := d.runtime.attach(cid)
 		commands := [][]string{
 			launchCommands,
 			{"set", "-a"},
@@ -165,9 +289,13 @@ func (d *docker) runBuild(buildEntry buildEntry) error {
 		if err != nil {
 			return fmt.Errorf("failed to attach build container: %v", err)
 		}
+	} else if buildEntry.Reuse {
+		if err := d.runReused(name, dockerCommandOptions, launchCommands); err != nil {
+			return err
+		}
 	} else {
 		// run for sd-local build mode
-		_, err = d.execDockerCommand(append(dockerCommandArgs, dockerCommandOptions...)...)
+		_, err = d.execDockerCommand(d.runtime.run(dockerCommandOptions...)...)
 		if err != nil {
 			return fmt.Errorf("failed to run build container: %v", err)
 		}
@@ -176,8 +304,160 @@ func (d *docker) runBuild(buildEntry buildEntry) error {
 	return nil
 }
 
+// buildContainerName returns the name of the build container, which is named so that it can be interrupted and removed on the signal.
+func buildContainerName(buildEntry buildEntry) string {
+	if buildEntry.Reuse {
+		return reuseContainerName(buildEntry.JobName, buildEntry.SrcPath)
+	}
+	return pausedContainerName(buildEntry.JobName)
+}
+
+// launchCommands returns the commands of the launcher which run the steps of the build in the build container.
+func (d *docker) launchCommands(buildEntry buildEntry, configJSON string) []string {
+	environment := buildEntry.Environment[0]
+	logfilePath := filepath.Join(environment["SD_ARTIFACTS_DIR"], LogFile)
+	if d.interactiveMode {
+		configJSON = fmt.Sprintf("%q", configJSON)
+	}
+	return []string{"/opt/sd/local_run.sh", configJSON, buildEntry.JobName, environment["SD_API_URL"], environment["SD_STORE_URL"], logfilePath}
+}
+
+// runOptions returns the options to run the build container of the image on the network.
+func (d *docker) runOptions(buildEntry buildEntry, name, buildImage, network string, launchCommands []string) []string {
+	environment := buildEntry.Environment[0]
+	containerArtDir := environment["SD_ARTIFACTS_DIR"]
+	logfilePath := filepath.Join(containerArtDir, LogFile)
+
+	srcVol := fmt.Sprintf("%s/:%s", buildEntry.SrcPath, sourceDir)
+	artVol := fmt.Sprintf("%s/:%s", buildEntry.ArtifactsPath, containerArtDir)
+	binVol := fmt.Sprintf("%s:%s", d.volume, "/opt/sd")
+	habVol := fmt.Sprintf("%s:%s", d.habVolume, "/opt/sd/hab")
+
+	// The bind mounts are relabeled on SELinux, so that the build can read and write them
+	label := buildEntry.SELinuxLabel
+	// The kept container is run in the background, and the builds are run in it
+	keep := "--rm"
+	if buildEntry.Reuse {
+		keep = "-d"
+	}
+	dockerCommandOptions := []string{"--name", name, keep, "-v", labelVolume(srcVol, label), "-v", labelVolume(artVol, label)}
+	// The ignored directories are hidden by the anonymous volumes, which are removed with the container
+	for _, dir := range buildEntry.IgnoredDirs {
+		dockerCommandOptions = append(dockerCommandOptions, "-v", path.Join(sourceDir, dir))
+	}
+	if buildEntry.MetaPath != "" {
+		metaVol := fmt.Sprintf("%s/:%s", buildEntry.MetaPath, containerMetaDir)
+		dockerCommandOptions = append(dockerCommandOptions, "-v", labelVolume(metaVol, label))
+	}
+	for _, cacheVol := range buildEntry.CacheVolumes {
+		dockerCommandOptions = append(dockerCommandOptions, "-v", labelVolume(cacheVol, label))
+	}
+	for _, vol := range buildEntry.Volumes {
+		dockerCommandOptions = append(dockerCommandOptions, "-v", labelVolume(vol, label))
+	}
+	for _, tmpfs := range buildEntry.Tmpfs {
+		dockerCommandOptions = append(dockerCommandOptions, "--tmpfs", tmpfs)
+	}
+	dockerCommandOptions = append(dockerCommandOptions, "-v", binVol, "-v", habVol)
+	if buildEntry.CommandsVolume != "" {
+		dockerCommandOptions = append(dockerCommandOptions, "-v", labelVolume(buildEntry.CommandsVolume, label))
+	}
+	// The SSH agent of the host is forwarded unless it is not available or disabled
+	if d.socketPath != "" {
+		dockerCommandOptions = append(dockerCommandOptions, "-v", fmt.Sprintf("%s:/tmp/auth.sock", d.socketPath), "-e", "SSH_AUTH_SOCK=/tmp/auth.sock")
+	}
+	dockerCommandOptions = append(dockerCommandOptions, buildImage)
+	if d.interactiveMode {
+		dockerCommandOptions = append([]string{"-itd"}, dockerCommandOptions...)
+		dockerCommandOptions = append(dockerCommandOptions, "/bin/sh")
+	} else if buildEntry.Reuse {
+		dockerCommandOptions = append(dockerCommandOptions, keepAliveCommand...)
+	} else if buildEntry.PauseOnFailure {
+		dockerCommandOptions = append(dockerCommandOptions, d.pauseOnFailure(name, logfilePath, launchCommands)...)
+	} else {
+		dockerCommandOptions = append(dockerCommandOptions, launchCommands...)
+	}
+
+	if buildEntry.MemoryLimit != "" {
+		dockerCommandOptions = append([]string{fmt.Sprintf("-m%s", buildEntry.MemoryLimit)}, dockerCommandOptions...)
+	}
+
+	if buildEntry.CPULimit != "" {
+		dockerCommandOptions = append([]string{"--cpus", buildEntry.CPULimit}, dockerCommandOptions...)
+	}
+
+	if buildEntry.ShmSize != "" {
+		dockerCommandOptions = append([]string{"--shm-size", buildEntry.ShmSize}, dockerCommandOptions...)
+	}
+
+	dockerCommandOptions = append(d.platformOptions(), dockerCommandOptions...)
+
+	if buildEntry.User != "" {
+		dockerCommandOptions = append(d.runtime.user(buildEntry.User), dockerCommandOptions...)
+	}
+
+	if buildEntry.GPUs != "" {
+		dockerCommandOptions = append(d.runtime.gpus(buildEntry.GPUs), dockerCommandOptions...)
+	}
+
+	if network != "" {
+		dockerCommandOptions = append([]string{"--network", network}, dockerCommandOptions...)
+	}
+
+	// The DNS servers are kept in order, because the first one is queried first
+	dnsOptions := make([]string, 0, len(buildEntry.DNS)*2)
+	for _, server := range buildEntry.DNS {
+		dnsOptions = append(dnsOptions, "--dns", server)
+	}
+	dockerCommandOptions = append(dnsOptions, dockerCommandOptions...)
+
+	// The ports are given as [<ip>:][<host port>:]<container port>, such as 8080:8080
+	for _, port := range buildEntry.Publish {
+		dockerCommandOptions = append([]string{"-p", port}, dockerCommandOptions...)
+	}
+
+	// The hosts are given as <name>:<ip>, such as host.docker.internal:host-gateway
+	for _, host := range buildEntry.ExtraHosts {
+		dockerCommandOptions = append([]string{"--add-host", host}, dockerCommandOptions...)
+	}
+
+	dockerCommandOptions = append(securityOptions(buildEntry), dockerCommandOptions...)
+
+	if buildEntry.UsePrivileged {
+		dockerCommandOptions = append([]string{"--privileged"}, dockerCommandOptions...)
+	}
+
+	return dockerCommandOptions
+}
+
+// pausedContainerName returns the name of the build container, which is unique to the job and the process of sd-local.
+func pausedContainerName(jobName string) string {
+	return fmt.Sprintf("sd-local-%s-%d", invalidContainerNameChars.ReplaceAllString(jobName, "-"), os.Getpid())
+}
+
+// pauseOnFailure wraps launchCommands to keep the build container alive when the build fails.
+// The commands to inspect and stop the container are written into the build log,
+// and the container exits with the status of the build when it is stopped or sd-local is interrupted.
+func (d *docker) pauseOnFailure(name, logfilePath string, launchCommands []string) []string {
+	command := d.runtime.name()
+	if d.useSudo {
+		command = "sudo " + command
+	}
+
+	message, _ := json.Marshal(map[string]interface{}{
+		"t": 0,
+		"m": fmt.Sprintf("The build failed and the container is kept for debugging. Run `%s exec -it %s /bin/sh` to inspect it, and `%s stop %s` or Ctrl-C to finish.", command, name, command, name),
+		"n": 0,
+		"s": "sd-local",
+	})
+
+	script := `log=$1 message=$2; shift 2; "$@" && exit 0; code=$?; echo "$message" >> "$log"; trap 'exit $code' INT TERM; while :; do sleep 1; done`
+
+	return append([]string{"/bin/sh", "-c", script, "sd-local", logfilePath, string(message)}, launchCommands...)
+}
+
 func (d *docker) attachDockerCommand(attachCommands []string, commands [][]string) error {
-	attachCommands = append([]string{"docker"}, attachCommands...)
+	attachCommands = append([]string{d.runtime.name()}, attachCommands...)
 	if d.useSudo {
 		attachCommands = append([]string{"sudo"}, attachCommands...)
 	}
@@ -190,17 +470,48 @@ func (d *docker) attachDockerCommand(attachCommands []string, commands [][]strin
 	return d.interact.Run(c, commands)
 }
 
-func (d *docker) execDockerCommand(args ...string) (string, error) {
-	commands := append([]string{"docker"}, args...)
+// commandLine returns the command of the container runtime with the arguments, which is run with sudo if it is used.
+func (d *docker) commandLine(args ...string) []string {
+	commands := append([]string{d.runtime.name()}, args...)
 	if d.useSudo {
 		commands = append([]string{"sudo"}, commands...)
 	}
+	return commands
+}
+
+// envCommand returns the command of the container runtime with the environment variables.
+// sudo resets the environment variables, so they are passed on its command line.
+func (d *docker) envCommand(env []string, args ...string) *exec.Cmd {
+	commands := d.commandLine(args...)
+	if d.useSudo {
+		commands = append(append([]string{"sudo"}, env...), commands[1:]...)
+	}
+
+	cmd := execCommand(commands[0], commands[1:]...)
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+	cmd.Env = append(cmd.Env, env...)
+	if d.flagVerbose {
+		logrus.Infof("$ %s", strings.Join(commands, " "))
+	}
+	d.mutex.Lock()
+	d.commands = append(d.commands, cmd)
+	d.mutex.Unlock()
+
+	return cmd
+}
+
+func (d *docker) execDockerCommand(args ...string) (string, error) {
+	commands := d.commandLine(args...)
 	cmd := execCommand(commands[0], commands[1:]...)
 	if d.flagVerbose {
 		logrus.Infof("$ %s", strings.Join(commands, " "))
 	}
 	cmd.Stderr = logrus.StandardLogger().WriterLevel(logrus.ErrorLevel)
+	d.mutex.Lock()
 	d.commands = append(d.commands, cmd)
+	d.mutex.Unlock()
 	buf := bytes.NewBuffer(nil)
 	cmd.Stderr = buf
 	out, err := cmd.Output()
@@ -214,16 +525,40 @@ func (d *docker) execDockerCommand(args ...string) (string, error) {
 	return strings.TrimRight(string(out), "\n"), nil
 }
 
+// kill stops the build on the signal.
+// The running step is interrupted at first so that the teardown steps are run, and the processes are signaled
+// and the container is removed when it doesn't exit in time or the signal is received again.
 func (d *docker) kill(sig os.Signal) {
+	d.mutex.Lock()
+	container, graceful, reuse := d.container, d.interruptible && !d.interrupted, d.reuse
+	d.interrupted = true
+	d.mutex.Unlock()
+
+	if container != "" && graceful {
+		logrus.Info("Interrupting the running step, and running the teardown steps. Press Ctrl-C again to stop the build immediately.")
+		if err := d.interruptStep(container, sig); err != nil {
+			logrus.Warn(fmt.Errorf("failed to interrupt step: %v", err))
+		} else if reuse && d.waitForBuild(interruptTimeout) {
+			return
+		} else if !reuse && d.waitForContainer(container, interruptTimeout) {
+			return
+		}
+	}
+
 	killedCmds := make([]*exec.Cmd, 0, 10)
 
-	for _, v := range d.commands {
+	d.mutex.Lock()
+	commands := append([]*exec.Cmd{}, d.commands...)
+	d.mutex.Unlock()
+
+	for _, v := range commands {
 		var err error
 		d.mutex.Lock()
-		if v.ProcessState != nil {
+		exited := v.Process == nil || v.ProcessState != nil
+		d.mutex.Unlock()
+		if exited {
 			continue
 		}
-		d.mutex.Unlock()
 
 		if d.useSudo {
 			cmd := execCommand("sudo", "kill", fmt.Sprintf("-%v", signum(sig)), strconv.Itoa(v.Process.Pid))
@@ -243,16 +578,76 @@ func (d *docker) kill(sig os.Signal) {
 	if err != nil {
 		logrus.Warn(err)
 	}
+
+	// The container is removed even if it is left, such as when the runtime is run with sudo or the build is paused
+	if container != "" && d.containerExists(container) {
+		if err := d.runQuietly(d.runtime.containerRemove(container)...); err != nil {
+			logrus.Warn(fmt.Errorf("failed to remove build container: %v", err))
+		}
+	}
+}
+
+// interruptStep makes the build fail and sends the signal to the processes of the running step in the container.
+// The user steps after it are skipped and the teardown steps are run, as the steps after the failed step.
+func (d *docker) interruptStep(container string, sig os.Signal) error {
+	script := fmt.Sprintf(`touch %s; pid=$(cat %s 2>/dev/null) || exit 0; for s in /proc/[0-9]*/status; do while read -r k v; do if [ "$k" = PPid: ]; then [ "$v" = "$pid" ] && p=${s%%/status} && kill -"$1" "${p#/proc/}"; break; fi; done < "$s"; done; exit 0`, failedFile, stepPIDFile)
+	return d.runQuietly(d.runtime.exec(container, "/bin/sh", "-c", script, "sd-local", strconv.Itoa(signum(sig)))...)
+}
+
+// waitForContainer reports whether the container exits before the timeout.
+func (d *docker) waitForContainer(container string, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for d.containerExists(container) {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(interruptInterval)
+	}
+	return true
+}
+
+// waitForBuild reports whether the build finishes before the timeout, which is used for the kept container not exiting after the build.
+func (d *docker) waitForBuild(timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for {
+		d.mutex.Lock()
+		running := d.container != ""
+		d.mutex.Unlock()
+		if !running {
+			return true
+		}
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(interruptInterval)
+	}
+}
+
+// containerExists reports whether the container is created in the container runtime.
+func (d *docker) containerExists(container string) bool {
+	return d.runQuietly(d.runtime.containerState(container)...) == nil
+}
+
+// runQuietly runs the command of the container runtime without its output, because its errors are expected.
+func (d *docker) runQuietly(args ...string) error {
+	commands := d.commandLine(args...)
+	if d.flagVerbose {
+		logrus.Infof("$ %s", strings.Join(commands, " "))
+	}
+	return execCommand(commands[0], commands[1:]...).Run()
 }
 
 func (d *docker) clean() {
-	_, err := d.execDockerCommand("volume", "rm", "--force", d.volume)
+	d.stopServices()
+	d.composeDown()
+
+	_, err := d.execDockerCommand(d.runtime.volumeRemove(d.volume)...)
 
 	if err != nil {
 		logrus.Warn(fmt.Errorf("failed to remove volume: %v", err))
 	}
 
-	_, err = d.execDockerCommand("volume", "rm", "--force", d.habVolume)
+	_, err = d.execDockerCommand(d.runtime.volumeRemove(d.habVolume)...)
 
 	if err != nil {
 		logrus.Warn(fmt.Errorf("failed to remove hab volume: %v", err))
@@ -2,15 +2,19 @@ package launch
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
+	"strconv"
 	"strings"
 	"sync"
 	"syscall"
 	"testing"
 	"time"
 
+	"github.com/screwdriver-cd/sd-local/config"
+	"github.com/screwdriver-cd/sd-local/screwdriver"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 )
@@ -28,6 +32,7 @@ type fakeExecCommand struct {
 
 type mockInteract struct {
 	Interacter
+	commands [][]string
 }
 
 func newFakeExecCommand(id string) *fakeExecCommand {
@@ -46,12 +51,14 @@ func newFakeExecCommand(id string) *fakeExecCommand {
 }
 
 func (d *mockInteract) Run(c *exec.Cmd, commands [][]string) error {
+	d.commands = commands
 	return c.Run()
 }
 
 func TestNewDocker(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		expected := &docker{
+			runtime:           &dockerRuntime{},
 			volume:            "SD_LAUNCH_BIN",
 			habVolume:         "SD_LAUNCH_HAB",
 			setupImage:        "launcher",
@@ -65,10 +72,18 @@ func TestNewDocker(t *testing.T) {
 			socketPath:        "/auth.sock",
 		}
 
-		d := newDocker("launcher", "latest", false, false, "/auth.sock", false)
+		d := newDocker(&dockerRuntime{}, "launcher", "latest", false, false, "/auth.sock", "", "", false)
 
 		assert.Equal(t, expected, d)
 	})
+
+	t.Run("success with platform", func(t *testing.T) {
+		d := newDocker(&dockerRuntime{}, "launcher", "latest", false, false, "/auth.sock", "linux/arm64", "", false).(*docker)
+
+		assert.Equal(t, "SD_LAUNCH_BIN_linux_arm64", d.volume)
+		assert.Equal(t, "SD_LAUNCH_HAB_linux_arm64", d.habVolume)
+		assert.Equal(t, "linux/arm64", d.platform)
+	})
 }
 
 func TestSetupBin(t *testing.T) {
@@ -77,9 +92,12 @@ func TestSetupBin(t *testing.T) {
 	}()
 
 	d := &docker{
+		mutex:             &sync.Mutex{},
+		runtime:           &dockerRuntime{},
 		volume:            "SD_LAUNCH_BIN",
 		setupImage:        "launcher",
 		setupImageVersion: "latest",
+		socketPath:        "/auth.sock",
 	}
 
 	testCase := []struct {
@@ -110,9 +128,12 @@ func TestSetupBinWithSudo(t *testing.T) {
 	}()
 
 	d := &docker{
+		mutex:             &sync.Mutex{},
+		runtime:           &dockerRuntime{},
 		volume:            "SD_LAUNCH_BIN",
 		setupImage:        "launcher",
 		setupImageVersion: "latest",
+		socketPath:        "/auth.sock",
 		useSudo:           true,
 	}
 
@@ -138,15 +159,158 @@ func TestSetupBinWithSudo(t *testing.T) {
 	}
 }
 
+func TestSetupBinWithPodman(t *testing.T) {
+	defer func() {
+		execCommand = exec.Command
+	}()
+
+	d := &docker{
+		mutex:             &sync.Mutex{},
+		runtime:           &podmanRuntime{},
+		volume:            "SD_LAUNCH_BIN",
+		habVolume:         "SD_LAUNCH_HAB",
+		setupImage:        "launcher",
+		setupImageVersion: "latest",
+	}
+
+	c := newFakeExecCommand("SUCCESS_SETUP_BIN")
+	execCommand = c.execCmd
+	err := d.setupBin()
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"podman volume create SD_LAUNCH_BIN",
+		"podman volume create SD_LAUNCH_HAB",
+		"podman pull launcher:latest",
+		"podman container run --rm -v SD_LAUNCH_BIN:/opt/sd/ -v SD_LAUNCH_HAB:/hab --entrypoint /bin/echo launcher:latest set up bin",
+	}, c.commands)
+}
+
+func TestSetupBinWithPlatform(t *testing.T) {
+	defer func() {
+		execCommand = exec.Command
+	}()
+
+	d := &docker{
+		mutex:             &sync.Mutex{},
+		runtime:           &dockerRuntime{},
+		volume:            "SD_LAUNCH_BIN_linux_amd64",
+		habVolume:         "SD_LAUNCH_HAB_linux_amd64",
+		setupImage:        "launcher",
+		setupImageVersion: "latest",
+		platform:          "linux/amd64",
+	}
+
+	c := newFakeExecCommand("SUCCESS_SETUP_BIN")
+	execCommand = c.execCmd
+	err := d.setupBin()
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"docker volume create --name SD_LAUNCH_BIN_linux_amd64",
+		"docker volume create --name SD_LAUNCH_HAB_linux_amd64",
+		"docker pull --platform linux/amd64 launcher:latest",
+		"docker container run --platform linux/amd64 --rm -v SD_LAUNCH_BIN_linux_amd64:/opt/sd/ -v SD_LAUNCH_HAB_linux_amd64:/hab --entrypoint /bin/echo launcher:latest set up bin",
+	}, c.commands)
+}
+
+func TestPullImage(t *testing.T) {
+	defer func() {
+		execCommand = exec.Command
+	}()
+
+	testCases := []struct {
+		name             string
+		id               string
+		policy           string
+		expectError      string
+		expectedCommands []string
+	}{
+		{"always", "SUCCESS_SETUP_BIN", PullAlways, "", []string{"docker pull node:12"}},
+		{"default", "SUCCESS_SETUP_BIN", "", "", []string{"docker pull node:12"}},
+		{"missing with image", "SUCCESS_SETUP_BIN", PullMissing, "", []string{"docker image inspect node:12"}},
+		{"missing without image", "IMAGE_NOT_FOUND", PullMissing, "", []string{"docker image inspect node:12", "docker pull node:12"}},
+		{"never with image", "SUCCESS_SETUP_BIN", PullNever, "", []string{"docker image inspect node:12"}},
+		{"never without image", "IMAGE_NOT_FOUND", PullNever, "node:12 is not found locally, and it is not pulled with --pull never", []string{"docker image inspect node:12"}},
+		{"always without network", "FAIL_PULL_WITH_LOCAL_IMAGE", PullAlways, "", []string{"docker pull node:12", "docker image inspect node:12"}},
+		{"always without network and image", "IMAGE_NOT_FOUND_OFFLINE", PullAlways, "exit status 1", []string{"docker pull node:12", "docker image inspect node:12"}},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &docker{
+				mutex:      &sync.Mutex{},
+				runtime:    &dockerRuntime{},
+				pullPolicy: tt.policy,
+			}
+			c := newFakeExecCommand(tt.id)
+			execCommand = c.execCmd
+
+			err := d.pullImage("node:12")
+			if tt.expectError != "" {
+				assert.EqualError(t, err, tt.expectError)
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.Equal(t, tt.expectedCommands, c.commands)
+		})
+	}
+}
+
+func TestBuildImage(t *testing.T) {
+	defer func() {
+		execCommand = exec.Command
+	}()
+
+	image := DockerfileImage{Dockerfile: "/repo/ci/Dockerfile", Target: "ci"}
+
+	testCases := []struct {
+		name            string
+		id              string
+		d               *docker
+		expectError     string
+		expectedCommand string
+	}{
+		{"success", "SUCCESS_BUILD_IMAGE", &docker{runtime: &dockerRuntime{}, mutex: &sync.Mutex{}},
+			"", fmt.Sprintf("docker build -f /repo/ci/Dockerfile -t %s --target ci /repo/ci", image.Tag())},
+		{"success with platform", "SUCCESS_BUILD_IMAGE", &docker{runtime: &dockerRuntime{}, mutex: &sync.Mutex{}, platform: "linux/arm64"},
+			"", fmt.Sprintf("docker build -f /repo/ci/Dockerfile -t %s --target ci --platform linux/arm64 /repo/ci", image.Tag())},
+		{"success with sudo", "SUCCESS_BUILD_IMAGE", &docker{runtime: &dockerRuntime{}, mutex: &sync.Mutex{}, useSudo: true},
+			"", fmt.Sprintf("sudo DOCKER_BUILDKIT=1 docker build -f /repo/ci/Dockerfile -t %s --target ci /repo/ci", image.Tag())},
+		{"failure", "FAIL_BUILD_IMAGE", &docker{runtime: &dockerRuntime{}, mutex: &sync.Mutex{}},
+			"exit status 1", fmt.Sprintf("docker build -f /repo/ci/Dockerfile -t %s --target ci /repo/ci", image.Tag())},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newFakeExecCommand(tt.id)
+			execCommand = c.execCmd
+
+			tag, err := tt.d.buildImage(image)
+			if tt.expectError != "" {
+				assert.EqualError(t, err, tt.expectError)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, image.Tag(), tag)
+			}
+			assert.Equal(t, []string{tt.expectedCommand}, c.commands)
+		})
+	}
+}
+
 func TestRunBuild(t *testing.T) {
+	name := pausedContainerName("test")
 	defer func() {
 		execCommand = exec.Command
 	}()
 
 	d := &docker{
+		mutex:             &sync.Mutex{},
+		runtime:           &dockerRuntime{},
 		volume:            "SD_LAUNCH_BIN",
 		setupImage:        "launcher",
 		setupImageVersion: "latest",
+		socketPath:        "/auth.sock",
 	}
 
 	testCase := []struct {
@@ -159,17 +323,145 @@ func TestRunBuild(t *testing.T) {
 		{"success", "SUCCESS_RUN_BUILD", nil,
 			[]string{
 				"docker pull node:12",
-				fmt.Sprintf("docker container run --rm -v /:/sd/workspace/src/screwdriver.cd/sd-local/local-build -v sd-artifacts/:/test/artifacts -v %s:/opt/sd -v %s:/opt/sd/hab -v %s:/tmp/auth.sock -e SSH_AUTH_SOCK=/tmp/auth.sock node:12 /opt/sd/local_run.sh ", d.volume, d.habVolume, os.Getenv("SSH_AUTH_SOCK"))},
+				fmt.Sprintf("docker container run --name "+name+" --rm -v /:/sd/workspace/src/screwdriver.cd/sd-local/local-build -v sd-artifacts/:/test/artifacts -v %s:/opt/sd -v %s:/opt/sd/hab -v %s:/tmp/auth.sock -e SSH_AUTH_SOCK=/tmp/auth.sock node:12 /opt/sd/local_run.sh ", d.volume, d.habVolume, d.socketPath)},
 			newBuildEntry()},
 		{"success with memory limit", "SUCCESS_RUN_BUILD", nil,
 			[]string{
 				"docker pull node:12",
-				fmt.Sprintf("docker container run -m2GB --rm -v /:/sd/workspace/src/screwdriver.cd/sd-local/local-build -v sd-artifacts/:/test/artifacts -v %s:/opt/sd -v %s:/opt/sd/hab -v %s:/tmp/auth.sock -e SSH_AUTH_SOCK=/tmp/auth.sock node:12 /opt/sd/local_run.sh ", d.volume, d.habVolume, os.Getenv("SSH_AUTH_SOCK"))},
+				fmt.Sprintf("docker container run -m2GB --name "+name+" --rm -v /:/sd/workspace/src/screwdriver.cd/sd-local/local-build -v sd-artifacts/:/test/artifacts -v %s:/opt/sd -v %s:/opt/sd/hab -v %s:/tmp/auth.sock -e SSH_AUTH_SOCK=/tmp/auth.sock node:12 /opt/sd/local_run.sh ", d.volume, d.habVolume, d.socketPath)},
 			newBuildEntry(func(b *buildEntry) {
 				b.MemoryLimit = "2GB"
 			})},
+		{"success with memory and cpu limits", "SUCCESS_RUN_BUILD", nil,
+			[]string{
+				"docker pull node:12",
+				fmt.Sprintf("docker container run --cpus 1.5 -m2GB --name "+name+" --rm -v /:/sd/workspace/src/screwdriver.cd/sd-local/local-build -v sd-artifacts/:/test/artifacts -v %s:/opt/sd -v %s:/opt/sd/hab -v %s:/tmp/auth.sock -e SSH_AUTH_SOCK=/tmp/auth.sock node:12 /opt/sd/local_run.sh ", d.volume, d.habVolume, d.socketPath)},
+			newBuildEntry(func(b *buildEntry) {
+				b.MemoryLimit = "2GB"
+				b.CPULimit = "1.5"
+			})},
+		{"success with meta path", "SUCCESS_RUN_BUILD", nil,
+			[]string{
+				"docker pull node:12",
+				fmt.Sprintf("docker container run --name "+name+" --rm -v /:/sd/workspace/src/screwdriver.cd/sd-local/local-build -v sd-artifacts/:/test/artifacts -v sd-artifacts/meta/:/sd/meta -v %s:/opt/sd -v %s:/opt/sd/hab -v %s:/tmp/auth.sock -e SSH_AUTH_SOCK=/tmp/auth.sock node:12 /opt/sd/local_run.sh ", d.volume, d.habVolume, d.socketPath)},
+			newBuildEntry(func(b *buildEntry) {
+				b.MetaPath = "sd-artifacts/meta"
+			})},
+		{"success with cache", "SUCCESS_RUN_BUILD", nil,
+			[]string{
+				"docker pull node:12",
+				fmt.Sprintf("docker container run --name "+name+" --rm -v /:/sd/workspace/src/screwdriver.cd/sd-local/local-build -v sd-artifacts/:/test/artifacts -v /cache/pipeline/:/sd/cache/pipeline -v %s:/opt/sd -v %s:/opt/sd/hab -v %s:/tmp/auth.sock -e SSH_AUTH_SOCK=/tmp/auth.sock node:12 /opt/sd/local_run.sh ", d.volume, d.habVolume, d.socketPath)},
+			newBuildEntry(func(b *buildEntry) {
+				b.CacheVolumes = []string{"/cache/pipeline/:/sd/cache/pipeline"}
+			})},
+		{"success with volumes", "SUCCESS_RUN_BUILD", nil,
+			[]string{
+				"docker pull node:12",
+				fmt.Sprintf("docker container run --name "+name+" --rm -v /:/sd/workspace/src/screwdriver.cd/sd-local/local-build -v sd-artifacts/:/test/artifacts -v /data:/data -v gocache:/root/.cache/go-build -v %s:/opt/sd -v %s:/opt/sd/hab -v %s:/tmp/auth.sock -e SSH_AUTH_SOCK=/tmp/auth.sock node:12 /opt/sd/local_run.sh ", d.volume, d.habVolume, d.socketPath)},
+			newBuildEntry(func(b *buildEntry) {
+				b.Volumes = []string{"/data:/data", "gocache:/root/.cache/go-build"}
+			})},
+		{"success with tmpfs and shm size", "SUCCESS_RUN_BUILD", nil,
+			[]string{
+				"docker pull node:12",
+				fmt.Sprintf("docker container run --shm-size 1g --name "+name+" --rm -v /:/sd/workspace/src/screwdriver.cd/sd-local/local-build -v sd-artifacts/:/test/artifacts --tmpfs /tmp:size=512m -v %s:/opt/sd -v %s:/opt/sd/hab -v %s:/tmp/auth.sock -e SSH_AUTH_SOCK=/tmp/auth.sock node:12 /opt/sd/local_run.sh ", d.volume, d.habVolume, d.socketPath)},
+			newBuildEntry(func(b *buildEntry) {
+				b.Tmpfs = []string{"/tmp:size=512m"}
+				b.ShmSize = "1g"
+			})},
+		{"success with selinux label", "SUCCESS_RUN_BUILD", nil,
+			[]string{
+				"docker pull node:12",
+				fmt.Sprintf("docker container run --name "+name+" --rm -v /:/sd/workspace/src/screwdriver.cd/sd-local/local-build:z -v sd-artifacts/:/test/artifacts -v /data:/data:ro,z -v gocache:/root/.cache/go-build -v %s:/opt/sd -v %s:/opt/sd/hab -v %s:/tmp/auth.sock -e SSH_AUTH_SOCK=/tmp/auth.sock node:12 /opt/sd/local_run.sh ", d.volume, d.habVolume, d.socketPath)},
+			newBuildEntry(func(b *buildEntry) {
+				b.Volumes = []string{"/data:/data:ro", "gocache:/root/.cache/go-build"}
+				b.SELinuxLabel = "z"
+			})},
+		{"success with commands", "SUCCESS_RUN_BUILD", nil,
+			[]string{
+				"docker pull node:12",
+				fmt.Sprintf("docker container run --name "+name+" --rm -v /:/sd/workspace/src/screwdriver.cd/sd-local/local-build -v sd-artifacts/:/test/artifacts -v %s:/opt/sd -v %s:/opt/sd/hab -v /commands/:/opt/sd/commands -v %s:/tmp/auth.sock -e SSH_AUTH_SOCK=/tmp/auth.sock node:12 /opt/sd/local_run.sh ", d.volume, d.habVolume, d.socketPath)},
+			newBuildEntry(func(b *buildEntry) {
+				b.CommandsVolume = "/commands/:/opt/sd/commands"
+			})},
+		{"success with extra hosts", "SUCCESS_RUN_BUILD", nil,
+			[]string{
+				"docker pull node:12",
+				fmt.Sprintf("docker container run --add-host host.docker.internal:host-gateway --name "+name+" --rm -v /:/sd/workspace/src/screwdriver.cd/sd-local/local-build -v sd-artifacts/:/test/artifacts -v %s:/opt/sd -v %s:/opt/sd/hab -v %s:/tmp/auth.sock -e SSH_AUTH_SOCK=/tmp/auth.sock node:12 /opt/sd/local_run.sh ", d.volume, d.habVolume, d.socketPath)},
+			newBuildEntry(func(b *buildEntry) {
+				b.ExtraHosts = []string{"host.docker.internal:host-gateway"}
+			})},
+		{"success with network", "SUCCESS_RUN_BUILD", nil,
+			[]string{
+				"docker pull node:12",
+				fmt.Sprintf("docker container run --add-host api.local:192.168.1.10 --network host --name "+name+" --rm -v /:/sd/workspace/src/screwdriver.cd/sd-local/local-build -v sd-artifacts/:/test/artifacts -v %s:/opt/sd -v %s:/opt/sd/hab -v %s:/tmp/auth.sock -e SSH_AUTH_SOCK=/tmp/auth.sock node:12 /opt/sd/local_run.sh ", d.volume, d.habVolume, d.socketPath)},
+			newBuildEntry(func(b *buildEntry) {
+				b.Network = "host"
+				b.ExtraHosts = []string{"api.local:192.168.1.10"}
+			})},
+		{"success with capabilities", "SUCCESS_RUN_BUILD", nil,
+			[]string{
+				"docker pull node:12",
+				fmt.Sprintf("docker container run --cap-drop MKNOD --cap-add NET_ADMIN --security-opt seccomp=unconfined --name "+name+" --rm -v /:/sd/workspace/src/screwdriver.cd/sd-local/local-build -v sd-artifacts/:/test/artifacts -v %s:/opt/sd -v %s:/opt/sd/hab -v %s:/tmp/auth.sock -e SSH_AUTH_SOCK=/tmp/auth.sock node:12 /opt/sd/local_run.sh ", d.volume, d.habVolume, d.socketPath)},
+			newBuildEntry(func(b *buildEntry) {
+				b.CapAdd = []string{"NET_ADMIN"}
+				b.CapDrop = []string{"MKNOD"}
+				b.SecurityOpt = []string{"seccomp=unconfined"}
+			})},
+		{"success with user", "SUCCESS_RUN_BUILD", nil,
+			[]string{
+				"docker pull node:12",
+				fmt.Sprintf("docker container run --user 1000:1000 --name "+name+" --rm -v /:/sd/workspace/src/screwdriver.cd/sd-local/local-build -v sd-artifacts/:/test/artifacts -v %s:/opt/sd -v %s:/opt/sd/hab -v %s:/tmp/auth.sock -e SSH_AUTH_SOCK=/tmp/auth.sock node:12 /opt/sd/local_run.sh ", d.volume, d.habVolume, d.socketPath)},
+			newBuildEntry(func(b *buildEntry) {
+				b.User = "1000:1000"
+			})},
+		{"success with gpus", "SUCCESS_RUN_BUILD", nil,
+			[]string{
+				"docker pull node:12",
+				fmt.Sprintf("docker container run --gpus all --name "+name+" --rm -v /:/sd/workspace/src/screwdriver.cd/sd-local/local-build -v sd-artifacts/:/test/artifacts -v %s:/opt/sd -v %s:/opt/sd/hab -v %s:/tmp/auth.sock -e SSH_AUTH_SOCK=/tmp/auth.sock node:12 /opt/sd/local_run.sh ", d.volume, d.habVolume, d.socketPath)},
+			newBuildEntry(func(b *buildEntry) {
+				b.GPUs = "all"
+			})},
+		{"success with dns", "SUCCESS_RUN_BUILD", nil,
+			[]string{
+				"docker pull node:12",
+				fmt.Sprintf("docker container run --dns 10.0.0.2 --dns 8.8.4.4 --name "+name+" --rm -v /:/sd/workspace/src/screwdriver.cd/sd-local/local-build -v sd-artifacts/:/test/artifacts -v %s:/opt/sd -v %s:/opt/sd/hab -v %s:/tmp/auth.sock -e SSH_AUTH_SOCK=/tmp/auth.sock node:12 /opt/sd/local_run.sh ", d.volume, d.habVolume, d.socketPath)},
+			newBuildEntry(func(b *buildEntry) {
+				b.DNS = []string{"10.0.0.2", "8.8.4.4"}
+			})},
+		{"success with published ports", "SUCCESS_RUN_BUILD", nil,
+			[]string{
+				"docker pull node:12",
+				fmt.Sprintf("docker container run -p 127.0.0.1:3000:3000 -p 8080:8080 --name "+name+" --rm -v /:/sd/workspace/src/screwdriver.cd/sd-local/local-build -v sd-artifacts/:/test/artifacts -v %s:/opt/sd -v %s:/opt/sd/hab -v %s:/tmp/auth.sock -e SSH_AUTH_SOCK=/tmp/auth.sock node:12 /opt/sd/local_run.sh ", d.volume, d.habVolume, d.socketPath)},
+			newBuildEntry(func(b *buildEntry) {
+				b.Publish = []string{"8080:8080", "127.0.0.1:3000:3000"}
+			})},
+		{"success with ignored dirs", "SUCCESS_RUN_BUILD", nil,
+			[]string{
+				"docker pull node:12",
+				fmt.Sprintf("docker container run --name "+name+" --rm -v /:/sd/workspace/src/screwdriver.cd/sd-local/local-build -v sd-artifacts/:/test/artifacts -v /sd/workspace/src/screwdriver.cd/sd-local/local-build/node_modules -v %s:/opt/sd -v %s:/opt/sd/hab -v %s:/tmp/auth.sock -e SSH_AUTH_SOCK=/tmp/auth.sock node:12 /opt/sd/local_run.sh ", d.volume, d.habVolume, d.socketPath)},
+			newBuildEntry(func(b *buildEntry) {
+				b.IgnoredDirs = []string{"node_modules"}
+			})},
+		{"success with pause on failure", "SUCCESS_RUN_BUILD", nil,
+			[]string{
+				"docker pull node:12",
+				fmt.Sprintf("docker container run --name sd-local-test-%d --rm -v /:/sd/workspace/src/screwdriver.cd/sd-local/local-build -v sd-artifacts/:/test/artifacts -v %s:/opt/sd -v %s:/opt/sd/hab -v %s:/tmp/auth.sock -e SSH_AUTH_SOCK=/tmp/auth.sock node:12 /bin/sh -c ", os.Getpid(), d.volume, d.habVolume, d.socketPath)},
+			newBuildEntry(func(b *buildEntry) {
+				b.PauseOnFailure = true
+			})},
 		{"failure build run", "FAIL_BUILD_CONTAINER_RUN", fmt.Errorf("failed to run build container: exit status 1"), []string{}, newBuildEntry()},
 		{"failure build image pull", "FAIL_BUILD_IMAGE_PULL", fmt.Errorf("failed to pull user image exit status 1"), []string{}, newBuildEntry()},
+		{"success with Dockerfile", "SUCCESS_BUILD_IMAGE", nil,
+			[]string{
+				fmt.Sprintf("docker build -f /repo/Dockerfile -t %s /repo", DockerfileImage{Dockerfile: "/repo/Dockerfile"}.Tag()),
+				fmt.Sprintf("docker container run --name "+name+" --rm -v /:/sd/workspace/src/screwdriver.cd/sd-local/local-build -v sd-artifacts/:/test/artifacts -v %s:/opt/sd -v %s:/opt/sd/hab -v %s:/tmp/auth.sock -e SSH_AUTH_SOCK=/tmp/auth.sock %s /opt/sd/local_run.sh ", d.volume, d.habVolume, d.socketPath, DockerfileImage{Dockerfile: "/repo/Dockerfile"}.Tag())},
+			newBuildEntry(func(b *buildEntry) {
+				b.Image = "/repo/Dockerfile"
+			})},
+		{"failure build image build", "FAIL_BUILD_IMAGE", fmt.Errorf("failed to build user image: exit status 1"), []string{}, newBuildEntry(func(b *buildEntry) {
+			b.Image = "/repo/Dockerfile"
+		})},
 	}
 
 	for _, tt := range testCase {
@@ -187,17 +479,150 @@ func TestRunBuild(t *testing.T) {
 			}
 		})
 	}
+
+	t.Run("success without SSH agent", func(t *testing.T) {
+		d := *d
+		d.socketPath = ""
+		c := newFakeExecCommand("SUCCESS_RUN_BUILD")
+		execCommand = c.execCmd
+		err := d.runBuild(newBuildEntry())
+		assert.Nil(t, err)
+		expected := fmt.Sprintf("docker container run --name "+name+" --rm -v /:/sd/workspace/src/screwdriver.cd/sd-local/local-build -v sd-artifacts/:/test/artifacts -v %s:/opt/sd -v %s:/opt/sd/hab node:12 /opt/sd/local_run.sh ", d.volume, d.habVolume)
+		assert.True(t, strings.Contains(c.commands[1], expected), "expect %q \nbut got \n%q", expected, c.commands[1])
+	})
+
+	t.Run("success with reuse", func(t *testing.T) {
+		d := *d
+		c := newFakeExecCommand("REUSE_NOT_FOUND")
+		execCommand = c.execCmd
+		b := newBuildEntry(func(b *buildEntry) {
+			b.Reuse = true
+		})
+		err := d.runBuild(b)
+		assert.Nil(t, err)
+
+		reused := reuseContainerName(b.JobName, b.SrcPath)
+		assert.Equal(t, 5, len(c.commands))
+		assert.Equal(t, "docker pull node:12", c.commands[1])
+		assert.True(t, strings.HasPrefix(c.commands[3], "docker container run --label sd-local.reuse="), c.commands[3])
+		assert.Contains(t, c.commands[3], " --name "+reused+" -d -v /:/sd/workspace/src/screwdriver.cd/sd-local/local-build ")
+		assert.True(t, strings.HasSuffix(c.commands[3], " node:12 /bin/sh -c trap 'exit 0' INT TERM; while :; do sleep 1; done"), c.commands[3])
+		assert.True(t, strings.HasPrefix(c.commands[4], "docker exec "+reused+" /bin/sh -c rm -f /tmp/sd-local-failed /tmp/sd-local-step.pid; exec \"$@\" sd-local /opt/sd/local_run.sh "), c.commands[4])
+	})
+
+	t.Run("success with reused container", func(t *testing.T) {
+		d := *d
+		c := newFakeExecCommand("REUSE_RUNNING:0000")
+		execCommand = c.execCmd
+		err := d.runBuild(newBuildEntry(func(b *buildEntry) {
+			b.Reuse = true
+		}))
+		assert.Nil(t, err)
+		assert.NotContains(t, c.commands, "docker pull node:12")
+	})
+
+	t.Run("success with services", func(t *testing.T) {
+		d := *d
+		c := newFakeExecCommand("SERVICE_HEALTHY")
+		execCommand = c.execCmd
+		err := d.runBuild(newBuildEntry(func(b *buildEntry) {
+			b.Services = []config.Service{{Name: "postgres", Image: "postgres:13"}}
+		}))
+		assert.Nil(t, err)
+		network := pausedContainerName("test")
+		assert.Equal(t, fmt.Sprintf("docker network create %s", network), c.commands[1])
+		expected := fmt.Sprintf("docker container run --network %s --name "+name+" --rm -v /:/sd/workspace/src/screwdriver.cd/sd-local/local-build", network)
+		assert.True(t, strings.HasPrefix(c.commands[5], expected), "expect %q \nbut got \n%q", expected, c.commands[5])
+		assert.Equal(t, []string{fmt.Sprintf("docker container rm --force %s-postgres", network), fmt.Sprintf("docker network rm %s", network)}, c.commands[6:])
+	})
+
+	t.Run("success with services on network", func(t *testing.T) {
+		d := *d
+		c := newFakeExecCommand("SERVICE_HEALTHY")
+		execCommand = c.execCmd
+		err := d.runBuild(newBuildEntry(func(b *buildEntry) {
+			b.Network = "backend"
+			b.Services = []config.Service{{Name: "postgres", Image: "postgres:13"}}
+		}))
+		assert.Nil(t, err)
+		assert.Equal(t, fmt.Sprintf("docker container run -d --name %s-postgres --network backend --network-alias postgres postgres:13", pausedContainerName("test")), c.commands[2])
+		expected := "docker container run --network backend --name " + name + " --rm -v /:/sd/workspace/src/screwdriver.cd/sd-local/local-build"
+		assert.True(t, strings.HasPrefix(c.commands[4], expected), "expect %q \nbut got \n%q", expected, c.commands[4])
+		// The network is not removed, because it is not created by the build
+		assert.Equal(t, []string{fmt.Sprintf("docker container rm --force %s-postgres", pausedContainerName("test"))}, c.commands[5:])
+	})
+
+	t.Run("success with compose file and services", func(t *testing.T) {
+		d := *d
+		c := newFakeExecCommand("SERVICE_HEALTHY")
+		execCommand = c.execCmd
+		err := d.runBuild(newBuildEntry(func(b *buildEntry) {
+			b.ComposeFile = "/repo/docker-compose.yml"
+			b.Services = []config.Service{{Name: "postgres", Image: "postgres:13"}}
+		}))
+		assert.Nil(t, err)
+		project := composeProjectName("test")
+		assert.Equal(t, fmt.Sprintf("docker compose -f /repo/docker-compose.yml -p %s up -d --wait", project), c.commands[1])
+		assert.Equal(t, fmt.Sprintf("docker container run -d --name %s-postgres --network %s_default --network-alias postgres postgres:13", pausedContainerName("test"), project), c.commands[3])
+		expected := fmt.Sprintf("docker container run --network %s_default --name "+name+" --rm -v /:/sd/workspace/src/screwdriver.cd/sd-local/local-build", project)
+		assert.True(t, strings.HasPrefix(c.commands[5], expected), "expect %q \nbut got \n%q", expected, c.commands[5])
+		assert.Equal(t, []string{
+			fmt.Sprintf("docker container rm --force %s-postgres", pausedContainerName("test")),
+			fmt.Sprintf("docker compose -f /repo/docker-compose.yml -p %s down --volumes --remove-orphans", project),
+		}, c.commands[6:])
+	})
+
+	t.Run("success with platform", func(t *testing.T) {
+		d := *d
+		d.platform = "linux/amd64"
+		c := newFakeExecCommand("SUCCESS_RUN_BUILD")
+		execCommand = c.execCmd
+		err := d.runBuild(newBuildEntry(func(b *buildEntry) {
+			b.MemoryLimit = "2GB"
+		}))
+		assert.Nil(t, err)
+		assert.Equal(t, "docker pull --platform linux/amd64 node:12", c.commands[0])
+		expected := fmt.Sprintf("docker container run --platform linux/amd64 -m2GB --name "+name+" --rm -v /:/sd/workspace/src/screwdriver.cd/sd-local/local-build -v sd-artifacts/:/test/artifacts -v %s:/opt/sd -v %s:/opt/sd/hab -v %s:/tmp/auth.sock -e SSH_AUTH_SOCK=/tmp/auth.sock node:12 /opt/sd/local_run.sh ", d.volume, d.habVolume, d.socketPath)
+		assert.True(t, strings.Contains(c.commands[1], expected), "expect %q \nbut got \n%q", expected, c.commands[1])
+	})
+}
+
+func TestPauseOnFailure(t *testing.T) {
+	d := &docker{
+		mutex:   &sync.Mutex{},
+		runtime: &podmanRuntime{},
+		useSudo: true,
+	}
+
+	name := pausedContainerName("PR-1:main")
+	assert.Equal(t, fmt.Sprintf("sd-local-PR-1-main-%d", os.Getpid()), name)
+
+	commands := d.pauseOnFailure(name, "/test/artifacts/builds.log", []string{"/opt/sd/local_run.sh", "{}", "main"})
+	assert.Equal(t, []string{"/bin/sh", "-c"}, commands[:2])
+	assert.Equal(t, []string{"sd-local", "/test/artifacts/builds.log"}, commands[3:5])
+	assert.Equal(t, []string{"/opt/sd/local_run.sh", "{}", "main"}, commands[6:])
+
+	var message map[string]interface{}
+	err := json.Unmarshal([]byte(commands[5]), &message)
+	assert.Nil(t, err)
+	assert.Equal(t, "sd-local", message["s"])
+	assert.Contains(t, message["m"], fmt.Sprintf("`sudo podman exec -it %s /bin/sh`", name))
+	assert.Contains(t, message["m"], fmt.Sprintf("`sudo podman stop %s`", name))
 }
 
 func TestRunBuildWithSudo(t *testing.T) {
+	name := pausedContainerName("test")
 	defer func() {
 		execCommand = exec.Command
 	}()
 
 	d := &docker{
+		mutex:             &sync.Mutex{},
+		runtime:           &dockerRuntime{},
 		volume:            "SD_LAUNCH_BIN",
 		setupImage:        "launcher",
 		setupImageVersion: "latest",
+		socketPath:        "/auth.sock",
 		useSudo:           true,
 	}
 
@@ -211,12 +636,12 @@ func TestRunBuildWithSudo(t *testing.T) {
 		{"success", "SUCCESS_RUN_BUILD_SUDO", nil,
 			[]string{
 				"sudo docker pull node:12",
-				fmt.Sprintf("sudo docker container run --rm -v /:/sd/workspace/src/screwdriver.cd/sd-local/local-build -v sd-artifacts/:/test/artifacts -v %s:/opt/sd -v %s:/opt/sd/hab -v %s:/tmp/auth.sock -e SSH_AUTH_SOCK=/tmp/auth.sock node:12 /opt/sd/local_run.sh ", d.volume, d.habVolume, os.Getenv("SSH_AUTH_SOCK"))},
+				fmt.Sprintf("sudo docker container run --name "+name+" --rm -v /:/sd/workspace/src/screwdriver.cd/sd-local/local-build -v sd-artifacts/:/test/artifacts -v %s:/opt/sd -v %s:/opt/sd/hab -v %s:/tmp/auth.sock -e SSH_AUTH_SOCK=/tmp/auth.sock node:12 /opt/sd/local_run.sh ", d.volume, d.habVolume, d.socketPath)},
 			newBuildEntry()},
 		{"success with memory limit", "SUCCESS_RUN_BUILD_SUDO", nil,
 			[]string{
 				"sudo docker pull node:12",
-				fmt.Sprintf("sudo docker container run -m2GB --rm -v /:/sd/workspace/src/screwdriver.cd/sd-local/local-build -v sd-artifacts/:/test/artifacts -v %s:/opt/sd -v %s:/opt/sd/hab -v %s:/tmp/auth.sock -e SSH_AUTH_SOCK=/tmp/auth.sock node:12 /opt/sd/local_run.sh ", d.volume, d.habVolume, os.Getenv("SSH_AUTH_SOCK"))},
+				fmt.Sprintf("sudo docker container run -m2GB --name "+name+" --rm -v /:/sd/workspace/src/screwdriver.cd/sd-local/local-build -v sd-artifacts/:/test/artifacts -v %s:/opt/sd -v %s:/opt/sd/hab -v %s:/tmp/auth.sock -e SSH_AUTH_SOCK=/tmp/auth.sock node:12 /opt/sd/local_run.sh ", d.volume, d.habVolume, d.socketPath)},
 			newBuildEntry(func(b *buildEntry) {
 				b.MemoryLimit = "2GB"
 			})},
@@ -242,14 +667,18 @@ func TestRunBuildWithSudo(t *testing.T) {
 }
 
 func TestRunBuildWithInteractiveMode(t *testing.T) {
+	name := pausedContainerName("test")
 	defer func() {
 		execCommand = exec.Command
 	}()
 
 	d := &docker{
+		mutex:             &sync.Mutex{},
+		runtime:           &dockerRuntime{},
 		volume:            "SD_LAUNCH_BIN",
 		setupImage:        "launcher",
 		setupImageVersion: "latest",
+		socketPath:        "/auth.sock",
 		useSudo:           true,
 		interactiveMode:   true,
 		interact:          &mockInteract{},
@@ -265,13 +694,13 @@ func TestRunBuildWithInteractiveMode(t *testing.T) {
 		{"success", "SUCCESS_RUN_BUILD_INTERACT", nil,
 			[]string{
 				"sudo docker pull node:12",
-				fmt.Sprintf("sudo docker container run -itd --rm -v /:/sd/workspace/src/screwdriver.cd/sd-local/local-build -v sd-artifacts/:/test/artifacts -v %s:/opt/sd -v %s:/opt/sd/hab -v %s:/tmp/auth.sock -e SSH_AUTH_SOCK=/tmp/auth.sock node:12 /bin/sh", d.volume, d.habVolume, os.Getenv("SSH_AUTH_SOCK")),
+				fmt.Sprintf("sudo docker container run -itd --name "+name+" --rm -v /:/sd/workspace/src/screwdriver.cd/sd-local/local-build -v sd-artifacts/:/test/artifacts -v %s:/opt/sd -v %s:/opt/sd/hab -v %s:/tmp/auth.sock -e SSH_AUTH_SOCK=/tmp/auth.sock node:12 /bin/sh", d.volume, d.habVolume, d.socketPath),
 				"sudo docker attach "},
 			newBuildEntry()},
 		{"success with memory limit", "SUCCESS_RUN_BUILD_INTERACT", nil,
 			[]string{
 				"sudo docker pull node:12",
-				fmt.Sprintf("sudo docker container run -m2GB -itd --rm -v /:/sd/workspace/src/screwdriver.cd/sd-local/local-build -v sd-artifacts/:/test/artifacts -v %s:/opt/sd -v %s:/opt/sd/hab -v %s:/tmp/auth.sock -e SSH_AUTH_SOCK=/tmp/auth.sock node:12 /bin/sh", d.volume, d.habVolume, os.Getenv("SSH_AUTH_SOCK")),
+				fmt.Sprintf("sudo docker container run -m2GB -itd --name "+name+" --rm -v /:/sd/workspace/src/screwdriver.cd/sd-local/local-build -v sd-artifacts/:/test/artifacts -v %s:/opt/sd -v %s:/opt/sd/hab -v %s:/tmp/auth.sock -e SSH_AUTH_SOCK=/tmp/auth.sock node:12 /bin/sh", d.volume, d.habVolume, d.socketPath),
 				"sudo docker attach SUCCESS_RUN_BUILD_INTERACT"},
 			newBuildEntry(func(b *buildEntry) {
 				b.MemoryLimit = "2GB"
@@ -298,6 +727,45 @@ func TestRunBuildWithInteractiveMode(t *testing.T) {
 	}
 }
 
+func TestRunBuildWithInteractiveModeRestoresCache(t *testing.T) {
+	defer func() {
+		execCommand = exec.Command
+	}()
+
+	interact := &mockInteract{}
+	d := &docker{
+		mutex:             &sync.Mutex{},
+		runtime:           &dockerRuntime{},
+		volume:            "SD_LAUNCH_BIN",
+		setupImage:        "launcher",
+		setupImageVersion: "latest",
+		socketPath:        "/auth.sock",
+		interactiveMode:   true,
+		interact:          interact,
+	}
+
+	b := newBuildEntry(func(b *buildEntry) {
+		b.Steps = append([]screwdriver.Step{{Name: setupCacheStep, Command: "store-cli get node_modules --type=cache --scope=pipeline || true"}}, b.Steps...)
+		b.Steps = append(b.Steps, screwdriver.Step{Name: teardownCacheStep, Command: "store-cli set node_modules --type=cache --scope=pipeline"})
+	})
+
+	c := newFakeExecCommand("SUCCESS_RUN_BUILD_INTERACT")
+	execCommand = c.execCmd
+	err := d.runBuild(b)
+	assert.Nil(t, err)
+
+	configJSON, err := strconv.Unquote(interact.commands[0][1])
+	assert.Nil(t, err)
+
+	var actual buildEntry
+	err = json.Unmarshal([]byte(configJSON), &actual)
+	assert.Nil(t, err)
+	assert.Equal(t, []screwdriver.Step{
+		{Name: setupCacheStep, Command: "store-cli get node_modules --type=cache --scope=pipeline || true"},
+		{Name: "sd-local-init", Command: "env > /tmp/sd-local.env"},
+	}, actual.Steps)
+}
+
 func TestDockerKill(t *testing.T) {
 	t.Run("success with no commands", func(t *testing.T) {
 		defer func() {
@@ -305,6 +773,7 @@ func TestDockerKill(t *testing.T) {
 			logrus.SetOutput(os.Stderr)
 		}()
 		d := &docker{
+			runtime:           &dockerRuntime{},
 			volume:            "SD_LAUNCH_BIN",
 			setupImage:        "launcher",
 			setupImageVersion: "latest",
@@ -327,6 +796,7 @@ func TestDockerKill(t *testing.T) {
 		c := newFakeExecCommand("SUCCESS_TO_KILL")
 		execCommand = c.execCmd
 		d := &docker{
+			runtime:           &dockerRuntime{},
 			volume:            "SD_LAUNCH_BIN",
 			setupImage:        "launcher",
 			setupImageVersion: "latest",
@@ -353,6 +823,91 @@ func TestDockerKill(t *testing.T) {
 		assert.Equal(t, "", actual)
 	})
 
+	t.Run("success with interrupting the step", func(t *testing.T) {
+		defer func() {
+			execCommand = exec.Command
+		}()
+		c := newFakeExecCommand("INTERRUPT_EXITED")
+		execCommand = c.execCmd
+		d := &docker{
+			runtime:       &dockerRuntime{},
+			mutex:         &sync.Mutex{},
+			container:     "sd-local-test-1",
+			interruptible: true,
+		}
+
+		d.kill(syscall.SIGINT)
+
+		assert.Equal(t, 2, len(c.commands))
+		assert.True(t, strings.HasPrefix(c.commands[0], "docker exec sd-local-test-1 /bin/sh -c touch /tmp/sd-local-failed; pid=$(cat /tmp/sd-local-step.pid 2>/dev/null)"), c.commands[0])
+		assert.True(t, strings.HasSuffix(c.commands[0], " sd-local 2"), c.commands[0])
+		assert.Equal(t, "docker container inspect --format {{.State.Status}} {{if .State.Health}}{{.State.Health.Status}}{{end}} sd-local-test-1", c.commands[1])
+		assert.True(t, d.interrupted)
+	})
+
+	t.Run("success with removing the container on the second signal", func(t *testing.T) {
+		defer func() {
+			execCommand = exec.Command
+		}()
+		c := newFakeExecCommand("INTERRUPT_RUNNING")
+		execCommand = c.execCmd
+		d := &docker{
+			runtime:       &dockerRuntime{},
+			mutex:         &sync.Mutex{},
+			container:     "sd-local-test-1",
+			interruptible: true,
+			interrupted:   true,
+		}
+
+		d.kill(syscall.SIGINT)
+
+		assert.Equal(t, []string{
+			"docker container inspect --format {{.State.Status}} {{if .State.Health}}{{.State.Health.Status}}{{end}} sd-local-test-1",
+			"docker container rm --force sd-local-test-1",
+		}, c.commands)
+	})
+
+	t.Run("success with removing the container not exiting in time", func(t *testing.T) {
+		defer func(timeout time.Duration) {
+			execCommand = exec.Command
+			interruptTimeout = timeout
+		}(interruptTimeout)
+		interruptTimeout = 0
+		c := newFakeExecCommand("INTERRUPT_RUNNING")
+		execCommand = c.execCmd
+		d := &docker{
+			runtime:       &dockerRuntime{},
+			mutex:         &sync.Mutex{},
+			container:     "sd-local-test-1",
+			interruptible: true,
+		}
+
+		d.kill(syscall.SIGINT)
+
+		assert.Equal(t, 4, len(c.commands))
+		assert.True(t, strings.HasPrefix(c.commands[0], "docker exec sd-local-test-1 "))
+		assert.Equal(t, "docker container rm --force sd-local-test-1", c.commands[3])
+	})
+
+	t.Run("success without interrupting the paused build", func(t *testing.T) {
+		defer func() {
+			execCommand = exec.Command
+		}()
+		c := newFakeExecCommand("INTERRUPT_EXITED")
+		execCommand = c.execCmd
+		d := &docker{
+			runtime:   &dockerRuntime{},
+			mutex:     &sync.Mutex{},
+			container: "sd-local-test-1",
+		}
+
+		d.kill(syscall.SIGINT)
+
+		assert.Equal(t, []string{
+			"docker container inspect --format {{.State.Status}} {{if .State.Health}}{{.State.Health.Status}}{{end}} sd-local-test-1",
+		}, c.commands)
+	})
+
 	t.Run("failure", func(t *testing.T) {
 		defer func() {
 			execCommand = exec.Command
@@ -362,6 +917,7 @@ func TestDockerKill(t *testing.T) {
 		execCommand = c.execCmd
 		command := execCommand("sleep")
 		d := &docker{
+			runtime:           &dockerRuntime{},
 			volume:            "SD_LAUNCH_BIN",
 			setupImage:        "launcher",
 			setupImageVersion: "latest",
@@ -394,6 +950,7 @@ func TestDockerKill(t *testing.T) {
 		c := newFakeExecCommand("SUCCESS_TO_KILL")
 		execCommand = c.execCmd
 		d := &docker{
+			runtime:           &dockerRuntime{},
 			volume:            "SD_LAUNCH_BIN",
 			setupImage:        "launcher",
 			setupImageVersion: "latest",
@@ -424,6 +981,8 @@ func TestDockerClean(t *testing.T) {
 		c := newFakeExecCommand("SUCCESS_TO_CLEAN")
 		execCommand = c.execCmd
 		d := &docker{
+			mutex:             &sync.Mutex{},
+			runtime:           &dockerRuntime{},
 			volume:            "SD_LAUNCH_BIN",
 			setupImage:        "launcher",
 			setupImageVersion: "latest",
@@ -442,6 +1001,8 @@ func TestDockerClean(t *testing.T) {
 		c := newFakeExecCommand("SUCCESS_TO_CLEAN")
 		execCommand = c.execCmd
 		d := &docker{
+			mutex:             &sync.Mutex{},
+			runtime:           &dockerRuntime{},
 			volume:            "SD_LAUNCH_BIN",
 			setupImage:        "launcher",
 			setupImageVersion: "latest",
@@ -461,6 +1022,8 @@ func TestDockerClean(t *testing.T) {
 		c := newFakeExecCommand("FAIL_TO_CLEAN")
 		execCommand = c.execCmd
 		d := &docker{
+			mutex:             &sync.Mutex{},
+			runtime:           &dockerRuntime{},
 			volume:            "SD_LAUNCH_BIN",
 			setupImage:        "launcher",
 			setupImageVersion: "latest",
@@ -504,6 +1067,26 @@ func TestHelperProcess(t *testing.T) {
 		subcmd = args[0]
 	}
 
+	// The state of the services is printed alone, because it is parsed
+	if strings.HasPrefix(testCase, "SERVICE_") {
+		serviceHelperProcess(testCase, subcmd, args)
+	}
+	if strings.HasPrefix(testCase, "REAP_") {
+		reaperHelperProcess(testCase, subcmd, args)
+	}
+	if strings.HasPrefix(testCase, "REUSE_") {
+		reuseHelperProcess(testCase, subcmd, args)
+	}
+	if strings.HasPrefix(testCase, "SNAPSHOT_") {
+		snapshotHelperProcess(testCase, subcmd, args)
+	}
+	if strings.HasPrefix(testCase, "CLEAN_") {
+		cleanHelperProcess(testCase, subcmd, args)
+	}
+	if strings.HasPrefix(testCase, "DOCTOR_") {
+		doctorHelperProcess(testCase, subcmd, args)
+	}
+
 	fmt.Print(testCase)
 
 	switch testCase {
@@ -511,6 +1094,18 @@ func TestHelperProcess(t *testing.T) {
 		os.Exit(1)
 	case "SUCCESS_SETUP_BIN":
 		os.Exit(0)
+	case "IMAGE_NOT_FOUND":
+		if subcmd == "image" {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	case "IMAGE_NOT_FOUND_OFFLINE":
+		os.Exit(1)
+	case "FAIL_PULL_WITH_LOCAL_IMAGE":
+		if subcmd == "pull" {
+			os.Exit(1)
+		}
+		os.Exit(0)
 	case "SUCCESS_SETUP_BIN_SUDO":
 		os.Exit(0)
 	case "SUCCESS_SETUP_BIN_INTERACT":
@@ -562,27 +1157,49 @@ func TestHelperProcess(t *testing.T) {
 		}
 		os.Exit(0)
 	case "FAIL_LAUNCHER_PULL":
-		if subcmd == "pull" {
+		if subcmd == "pull" || subcmd == "image" {
 			os.Exit(1)
 		}
 		os.Exit(0)
 	case "FAIL_LAUNCHER_PULL_SUDO":
-		if subcmd == "pull" {
+		if subcmd == "pull" || subcmd == "image" {
 			os.Exit(1)
 		}
 		os.Exit(0)
 	case "FAIL_BUILD_IMAGE_PULL":
-		if subcmd == "pull" {
+		if subcmd == "pull" || subcmd == "image" {
 			os.Exit(1)
 		}
 		os.Exit(0)
 	case "FAIL_BUILD_IMAGE_PULL_SUDO":
-		if subcmd == "pull" {
+		if subcmd == "pull" || subcmd == "image" {
 			os.Exit(1)
 		}
 		os.Exit(0)
 	case "FAIL_BUILD_IMAGE_PULL_INTERACT":
-		if subcmd == "pull" {
+		if subcmd == "pull" || subcmd == "image" {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	case "SUCCESS_BUILD_IMAGE":
+		// The images are built with BuildKit
+		if subcmd == "build" && os.Getenv("DOCKER_BUILDKIT") != "1" {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	case "FAIL_BUILD_IMAGE":
+		if subcmd == "build" {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	case "SUCCESS_COMPOSE":
+		// The compose file is interpolated with the environment variables of the build
+		if subcmd == "compose" && os.Getenv("DB_PASSWORD") != "password" {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	case "FAIL_COMPOSE_UP":
+		if subcmd == "compose" && args[4] == "up" {
 			os.Exit(1)
 		}
 		os.Exit(0)
@@ -598,6 +1215,14 @@ func TestHelperProcess(t *testing.T) {
 			os.Exit(0)
 		}
 		os.Exit(1)
+	case "INTERRUPT_EXITED":
+		// The container is removed after the teardown steps
+		if subcmd == "container" {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	case "INTERRUPT_RUNNING":
+		os.Exit(0)
 	case "SUCCESS_TO_CLEAN":
 		os.Exit(0)
 	case "FAIL_TO_CLEAN":
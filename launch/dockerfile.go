@@ -0,0 +1,63 @@
+package launch
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// DockerfileImage is the image of the job which is built from the Dockerfile instead of being pulled.
+type DockerfileImage struct {
+	Dockerfile string
+	// Target is the build stage of the Dockerfile, or empty for the last stage
+	Target string
+}
+
+// IsDockerfileImage reports whether the image of the job is the path of a Dockerfile, such as ./Dockerfile.
+// The names of the images never start with . or /.
+func IsDockerfileImage(image string) bool {
+	return strings.HasPrefix(image, ".") || strings.HasPrefix(image, "/")
+}
+
+// ParseDockerfileImage parses the path of the Dockerfile followed by the optional build stage, such as ./Dockerfile:dev.
+func ParseDockerfileImage(image string) DockerfileImage {
+	i := strings.LastIndex(image, ":")
+	if i == -1 || strings.Contains(image[i:], "/") {
+		return DockerfileImage{Dockerfile: image}
+	}
+	return DockerfileImage{Dockerfile: image[:i], Target: image[i+1:]}
+}
+
+// AbsDockerfileImage returns the image whose Dockerfile is resolved from the directory, so that it can be built from any directory.
+func AbsDockerfileImage(dir, image string) (string, error) {
+	i := ParseDockerfileImage(image)
+	if !filepath.IsAbs(i.Dockerfile) {
+		i.Dockerfile = filepath.Join(dir, i.Dockerfile)
+	}
+
+	if _, err := osStat(i.Dockerfile); err != nil {
+		return "", fmt.Errorf("failed to find Dockerfile: %v", err)
+	}
+
+	return i.String(), nil
+}
+
+func (i DockerfileImage) String() string {
+	if i.Target == "" {
+		return i.Dockerfile
+	}
+	return i.Dockerfile + ":" + i.Target
+}
+
+// Context returns the build context, which is the directory of the Dockerfile.
+func (i DockerfileImage) Context() string {
+	return filepath.Dir(i.Dockerfile)
+}
+
+// Tag returns the tag of the built image, which is the same between the builds of the Dockerfile and the stage.
+// The image is replaced by every build, and the layers are reused from the cache of BuildKit.
+func (i DockerfileImage) Tag() string {
+	sum := sha256.Sum256([]byte(i.String()))
+	return fmt.Sprintf("sd-local/build-image:%x", sum[:6])
+}
@@ -0,0 +1,76 @@
+package launch
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsDockerfileImage(t *testing.T) {
+	assert.True(t, IsDockerfileImage("./Dockerfile"))
+	assert.True(t, IsDockerfileImage("../ci/Dockerfile:ci"))
+	assert.True(t, IsDockerfileImage("/repo/Dockerfile"))
+	assert.False(t, IsDockerfileImage("node:18"))
+	assert.False(t, IsDockerfileImage("localhost:5000/node:18"))
+}
+
+func TestParseDockerfileImage(t *testing.T) {
+	testCases := []struct {
+		image  string
+		expect DockerfileImage
+	}{
+		{"./Dockerfile", DockerfileImage{Dockerfile: "./Dockerfile"}},
+		{"./Dockerfile:ci", DockerfileImage{Dockerfile: "./Dockerfile", Target: "ci"}},
+		{"/repo/ci:v1/Dockerfile", DockerfileImage{Dockerfile: "/repo/ci:v1/Dockerfile"}},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.image, func(t *testing.T) {
+			image := ParseDockerfileImage(tt.image)
+			assert.Equal(t, tt.expect, image)
+			assert.Equal(t, tt.image, image.String())
+		})
+	}
+}
+
+func TestAbsDockerfileImage(t *testing.T) {
+	defer func() {
+		osStat = os.Stat
+	}()
+
+	t.Run("success", func(t *testing.T) {
+		var statPath string
+		osStat = func(name string) (os.FileInfo, error) {
+			statPath = name
+			return nil, nil
+		}
+
+		image, err := AbsDockerfileImage("/repo", "./ci/Dockerfile:ci")
+		assert.Nil(t, err)
+		assert.Equal(t, "/repo/ci/Dockerfile:ci", image)
+		assert.Equal(t, "/repo/ci/Dockerfile", statPath)
+
+		image, err = AbsDockerfileImage("/repo", "/other/Dockerfile")
+		assert.Nil(t, err)
+		assert.Equal(t, "/other/Dockerfile", image)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		osStat = func(name string) (os.FileInfo, error) {
+			return nil, errors.New("stat /repo/Dockerfile: no such file or directory")
+		}
+
+		_, err := AbsDockerfileImage("/repo", "Dockerfile")
+		assert.EqualError(t, err, "failed to find Dockerfile: stat /repo/Dockerfile: no such file or directory")
+	})
+}
+
+func TestDockerfileImage(t *testing.T) {
+	image := DockerfileImage{Dockerfile: "/repo/ci/Dockerfile", Target: "ci"}
+	assert.Equal(t, "/repo/ci", image.Context())
+	assert.Regexp(t, `^sd-local/build-image:[0-9a-f]{12}$`, image.Tag())
+	assert.Equal(t, image.Tag(), DockerfileImage{Dockerfile: "/repo/ci/Dockerfile", Target: "ci"}.Tag())
+	assert.NotEqual(t, image.Tag(), DockerfileImage{Dockerfile: "/repo/ci/Dockerfile"}.Tag())
+}
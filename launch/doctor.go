@@ -0,0 +1,57 @@
+package launch
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CheckOption is the option to check the container runtime with sd-local doctor.
+type CheckOption struct {
+	// Runtime is detected when it is empty
+	Runtime     string
+	UseSudo     bool
+	FlagVerbose bool
+}
+
+// RuntimeStatus is the container runtime which runs the builds and its version.
+type RuntimeStatus struct {
+	Runtime string
+	Version string
+}
+
+func newCheckRunner(option CheckOption) (*docker, string) {
+	runtime := option.Runtime
+	if runtime == "" {
+		runtime = detectRuntime()
+	}
+	return newDocker(newContainerRuntime(runtime), "", "", option.UseSudo, false, "", "", PullMissing, option.FlagVerbose).(*docker), runtime
+}
+
+// CheckRuntime returns the container runtime and its version, which fails when it is not installed or its daemon is not running.
+// The error has the message of the container runtime, such as the daemon which can not be connected to.
+func CheckRuntime(option CheckOption) (RuntimeStatus, error) {
+	d, runtime := newCheckRunner(option)
+	status := RuntimeStatus{Runtime: runtime}
+
+	if _, err := lookPath(runtime); err != nil {
+		return status, fmt.Errorf("`%s` command is not found in $PATH: %v", runtime, err)
+	}
+
+	commands := d.commandLine(d.runtime.version()...)
+	out, err := execCommand(commands[0], commands[1:]...).CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return status, fmt.Errorf("failed to get version of %s: %s", runtime, msg)
+		}
+		return status, fmt.Errorf("failed to get version of %s: %v", runtime, err)
+	}
+
+	status.Version = strings.TrimSpace(string(out))
+	return status, nil
+}
+
+// HasImage reports whether the image is stored in the container runtime, so that it is not pulled by the next build.
+func HasImage(option CheckOption, image string) bool {
+	d, _ := newCheckRunner(option)
+	return d.imageExists(image)
+}
@@ -0,0 +1,85 @@
+package launch
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func doctorHelperProcess(testCase, subcmd string, args []string) {
+	switch testCase {
+	case "DOCTOR_DAEMON_STOPPED":
+		fmt.Fprintln(os.Stderr, "Cannot connect to the Docker daemon at unix:///var/run/docker.sock. Is the docker daemon running?")
+		os.Exit(1)
+	case "DOCTOR_IMAGE_NOT_FOUND":
+		os.Exit(1)
+	}
+	if subcmd == "version" {
+		fmt.Print("24.0.7\n")
+	}
+	os.Exit(0)
+}
+
+func TestCheckRuntime(t *testing.T) {
+	defer func() {
+		execCommand = exec.Command
+		lookPath = exec.LookPath
+	}()
+	lookPath = func(file string) (string, error) { return "/usr/bin/" + file, nil }
+
+	t.Run("success", func(t *testing.T) {
+		c := newFakeExecCommand("DOCTOR_RUNNING")
+		execCommand = c.execCmd
+
+		status, err := CheckRuntime(CheckOption{Runtime: "docker"})
+		assert.Nil(t, err)
+		assert.Equal(t, RuntimeStatus{Runtime: "docker", Version: "24.0.7"}, status)
+		assert.Equal(t, []string{"docker version --format {{.Server.Version}}"}, c.commands)
+	})
+
+	t.Run("success with podman and sudo", func(t *testing.T) {
+		c := newFakeExecCommand("DOCTOR_RUNNING")
+		execCommand = c.execCmd
+
+		_, err := CheckRuntime(CheckOption{Runtime: "podman", UseSudo: true})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"sudo podman version --format {{.Client.Version}}"}, c.commands)
+	})
+
+	t.Run("failure by daemon", func(t *testing.T) {
+		c := newFakeExecCommand("DOCTOR_DAEMON_STOPPED")
+		execCommand = c.execCmd
+
+		status, err := CheckRuntime(CheckOption{Runtime: "docker"})
+		assert.Equal(t, "docker", status.Runtime)
+		assert.EqualError(t, err, "failed to get version of docker: Cannot connect to the Docker daemon at unix:///var/run/docker.sock. Is the docker daemon running?")
+	})
+
+	t.Run("failure without runtime", func(t *testing.T) {
+		lookPath = func(file string) (string, error) {
+			return "", fmt.Errorf("exec: %q: executable file not found in $PATH", file)
+		}
+		defer func() { lookPath = func(file string) (string, error) { return "/usr/bin/" + file, nil } }()
+
+		_, err := CheckRuntime(CheckOption{Runtime: "nerdctl"})
+		assert.EqualError(t, err, "`nerdctl` command is not found in $PATH: exec: \"nerdctl\": executable file not found in $PATH")
+	})
+}
+
+func TestHasImage(t *testing.T) {
+	defer func() {
+		execCommand = exec.Command
+	}()
+
+	c := newFakeExecCommand("DOCTOR_RUNNING")
+	execCommand = c.execCmd
+	assert.True(t, HasImage(CheckOption{Runtime: "docker"}, "screwdrivercd/launcher:stable"))
+	assert.Equal(t, []string{"docker image inspect screwdrivercd/launcher:stable"}, c.commands)
+
+	c = newFakeExecCommand("DOCTOR_IMAGE_NOT_FOUND")
+	execCommand = c.execCmd
+	assert.False(t, HasImage(CheckOption{Runtime: "docker"}, "screwdrivercd/launcher:stable"))
+}
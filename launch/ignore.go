@@ -0,0 +1,104 @@
+package launch
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// IgnoreFile is the file in the source directory which lists the directories excluded from the build.
+// .gitignore is read instead if it doesn't exist.
+const IgnoreFile = ".sdlocalignore"
+
+// SourceUsage is the directories excluded from the build and the size of the rest of the source directory.
+type SourceUsage struct {
+	IgnoredDirs []string
+	Size        int64
+}
+
+// readIgnorePatterns reads the patterns in the ignore file, or .gitignore.
+// Negative patterns are not supported, so they are skipped.
+func readIgnorePatterns(srcPath string) ([]string, error) {
+	f, err := os.Open(filepath.Join(srcPath, IgnoreFile))
+	if os.IsNotExist(err) {
+		f, err = os.Open(filepath.Join(srcPath, ".gitignore"))
+	}
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ignore file: %v", err)
+	}
+	defer f.Close()
+
+	patterns := make([]string, 0)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read ignore file: %v", err)
+	}
+
+	return patterns, nil
+}
+
+// isIgnored reports whether the directory matches any of the patterns.
+// The pattern which has a slash except at the end is matched with the path from the source directory,
+// and the others are matched with the name of the directory at any depth.
+func isIgnored(rel string, patterns []string) bool {
+	for _, p := range patterns {
+		p = strings.TrimSuffix(p, "/")
+		target := filepath.Base(rel)
+		if strings.Contains(p, "/") {
+			p = strings.TrimPrefix(p, "/")
+			target = filepath.ToSlash(rel)
+		}
+		if ok, _ := filepath.Match(p, target); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadSourceUsage finds the directories in the source directory which match the ignore file, and sums up the size of the other files.
+func ReadSourceUsage(srcPath string) (SourceUsage, error) {
+	patterns, err := readIgnorePatterns(srcPath)
+	if err != nil {
+		return SourceUsage{}, err
+	}
+
+	usage := SourceUsage{}
+	err = filepath.Walk(srcPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !info.IsDir() {
+			usage.Size += info.Size()
+			return nil
+		}
+
+		rel, err := filepath.Rel(srcPath, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		if isIgnored(rel, patterns) {
+			usage.IgnoredDirs = append(usage.IgnoredDirs, filepath.ToSlash(rel))
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return SourceUsage{}, fmt.Errorf("failed to read source directory: %v", err)
+	}
+
+	return usage, nil
+}
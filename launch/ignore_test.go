@@ -0,0 +1,108 @@
+package launch
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeSourceFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestIsIgnored(t *testing.T) {
+	patterns := []string{"node_modules/", "/dist", "build/*", "*.tmp"}
+
+	testCases := []struct {
+		rel    string
+		expect bool
+	}{
+		{"node_modules", true},
+		{"packages/app/node_modules", true},
+		{"dist", true},
+		{"packages/app/dist", false},
+		{"build/output", true},
+		{"build", false},
+		{"cache.tmp", true},
+		{"src", false},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.rel, func(t *testing.T) {
+			assert.Equal(t, tt.expect, isIgnored(tt.rel, patterns))
+		})
+	}
+}
+
+func TestReadSourceUsage(t *testing.T) {
+	t.Run("success with ignore file", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "sd-local-src")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+
+		writeSourceFiles(t, dir, map[string]string{
+			IgnoreFile:                  "# comment\nnode_modules/\n!keep\n",
+			".gitignore":                "dist/\n",
+			"main.go":                   "package main",
+			"node_modules/foo/index.js": "module.exports = {}",
+			"app/node_modules/bar.js":   "module.exports = {}",
+			"dist/app":                  "binary",
+		})
+
+		usage, err := ReadSourceUsage(dir)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"app/node_modules", "node_modules"}, usage.IgnoredDirs)
+		assert.Equal(t, int64(len("# comment\nnode_modules/\n!keep\n")+len("dist/\n")+len("package main")+len("binary")), usage.Size)
+	})
+
+	t.Run("success with .gitignore", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "sd-local-src")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+
+		writeSourceFiles(t, dir, map[string]string{
+			".gitignore": "dist/\n",
+			"dist/app":   "binary",
+		})
+
+		usage, err := ReadSourceUsage(dir)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"dist"}, usage.IgnoredDirs)
+		assert.Equal(t, int64(len("dist/\n")), usage.Size)
+	})
+
+	t.Run("success without ignore file", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "sd-local-src")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+
+		writeSourceFiles(t, dir, map[string]string{"dist/app": "binary"})
+
+		usage, err := ReadSourceUsage(dir)
+		assert.Nil(t, err)
+		assert.Nil(t, usage.IgnoredDirs)
+		assert.Equal(t, int64(len("binary")), usage.Size)
+	})
+
+	t.Run("failure by reading source directory", func(t *testing.T) {
+		_, err := ReadSourceUsage("/not/exist")
+		assert.Contains(t, err.Error(), "failed to read source directory")
+	})
+}
@@ -0,0 +1,87 @@
+package launch
+
+import (
+	"strings"
+
+	"github.com/screwdriver-cd/sd-local/config"
+	"github.com/sirupsen/logrus"
+)
+
+const dockerHub = "docker.io"
+
+// normalizeImage returns the full name of the image on Docker Hub, such as docker.io/library/node:18 for node:18.
+// The images on the other registries, whose first component is a host, are returned as they are.
+func normalizeImage(image string) string {
+	i := strings.Index(image, "/")
+	if i == -1 {
+		return dockerHub + "/library/" + image
+	}
+
+	host := image[:i]
+	if strings.ContainsAny(host, ".:") || host == "localhost" {
+		return image
+	}
+
+	return dockerHub + "/" + image
+}
+
+// aliasImage returns the image which the alias rewrites the image to.
+// The image is matched as it is written and as its full name.
+// The exact alias takes precedence over the prefix aliases, and the longest prefix wins when multiple prefix aliases match.
+func aliasImage(image string, aliases map[string]string) (string, bool) {
+	names := []string{image, normalizeImage(image)}
+
+	for _, name := range names {
+		if to, ok := aliases[name]; ok && !strings.HasSuffix(name, "*") {
+			return to, true
+		}
+	}
+
+	longest, aliased := -1, ""
+	for _, name := range names {
+		for from, to := range aliases {
+			prefix := strings.TrimSuffix(from, "*")
+			if prefix == from || len(prefix) <= longest || !strings.HasPrefix(name, prefix) {
+				continue
+			}
+
+			longest, aliased = len(prefix), to
+			if strings.HasSuffix(to, "*") {
+				aliased = strings.TrimSuffix(to, "*") + name[len(prefix):]
+			}
+		}
+	}
+
+	return aliased, longest != -1
+}
+
+// ResolveImage returns the image which is pulled instead of the image, with the image aliases and the registry mirror of the config.
+// The aliases take precedence over the mirror.
+func ResolveImage(image string, entry config.Entry) string {
+	// The images built from the Dockerfiles are not pulled
+	if IsDockerfileImage(image) {
+		return image
+	}
+
+	resolved := image
+	if aliased, ok := aliasImage(image, entry.ImageAliases); ok {
+		resolved = aliased
+	} else if normalized := normalizeImage(image); entry.RegistryMirror != "" && strings.HasPrefix(normalized, dockerHub+"/") {
+		resolved = entry.RegistryMirror + strings.TrimPrefix(normalized, dockerHub)
+	}
+
+	if resolved != image {
+		logrus.Infof("Using the image %s instead of %s", resolved, image)
+	}
+
+	return resolved
+}
+
+// splitTag splits the image into the repository and the tag, which is latest if the image has no tag.
+func splitTag(image string) (string, string) {
+	i := strings.LastIndex(image, ":")
+	if i == -1 || strings.Contains(image[i:], "/") {
+		return image, "latest"
+	}
+	return image[:i], image[i+1:]
+}
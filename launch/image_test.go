@@ -0,0 +1,82 @@
+package launch
+
+import (
+	"testing"
+
+	"github.com/screwdriver-cd/sd-local/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeImage(t *testing.T) {
+	testCases := []struct {
+		image  string
+		expect string
+	}{
+		{"node:18", "docker.io/library/node:18"},
+		{"screwdrivercd/launcher:stable", "docker.io/screwdrivercd/launcher:stable"},
+		{"docker.io/library/node:18", "docker.io/library/node:18"},
+		{"ghcr.io/org/image:1.0", "ghcr.io/org/image:1.0"},
+		{"localhost:5000/image", "localhost:5000/image"},
+		{"localhost/image", "localhost/image"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.image, func(t *testing.T) {
+			assert.Equal(t, tt.expect, normalizeImage(tt.image))
+		})
+	}
+}
+
+func TestResolveImage(t *testing.T) {
+	aliases := map[string]string{
+		"docker.io/library/*": "registry.example.com/library/*",
+		"node:18":             "registry.example.com/node:18-patched",
+		"screwdrivercd/*":     "registry.example.com/sd/*",
+	}
+
+	testCases := []struct {
+		name   string
+		image  string
+		entry  config.Entry
+		expect string
+	}{
+		{"no alias and mirror", "node:18", config.Entry{}, "node:18"},
+		{"alias of full name", "node:16", config.Entry{ImageAliases: aliases}, "registry.example.com/library/node:16"},
+		{"exact alias takes precedence over prefix", "node:18", config.Entry{ImageAliases: aliases}, "registry.example.com/node:18-patched"},
+		{"alias as written", "screwdrivercd/launcher:stable", config.Entry{ImageAliases: aliases}, "registry.example.com/sd/launcher:stable"},
+		{"longest prefix wins", "golang:1.20", config.Entry{ImageAliases: map[string]string{"docker.io/*": "registry.example.com/hub/*", "docker.io/library/golang*": "registry.example.com/golang*"}}, "registry.example.com/golang:1.20"},
+		{"alias without wildcard in replacement", "golang:1.20", config.Entry{ImageAliases: map[string]string{"golang*": "registry.example.com/golang:1.21"}}, "registry.example.com/golang:1.21"},
+		{"mirror of official image", "node:18", config.Entry{RegistryMirror: "mirror.example.com"}, "mirror.example.com/library/node:18"},
+		{"mirror of user image", "screwdrivercd/launcher:stable", config.Entry{RegistryMirror: "mirror.example.com:5000/hub"}, "mirror.example.com:5000/hub/screwdrivercd/launcher:stable"},
+		{"mirror ignores other registries", "ghcr.io/org/image:1.0", config.Entry{RegistryMirror: "mirror.example.com"}, "ghcr.io/org/image:1.0"},
+		{"Dockerfile is not resolved", "./Dockerfile", config.Entry{RegistryMirror: "mirror.example.com", ImageAliases: map[string]string{"*": "registry.example.com/*"}}, "./Dockerfile"},
+		{"alias takes precedence over mirror", "ubuntu:22.04", config.Entry{RegistryMirror: "mirror.example.com", ImageAliases: aliases}, "registry.example.com/library/ubuntu:22.04"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expect, ResolveImage(tt.image, tt.entry))
+		})
+	}
+}
+
+func TestSplitTag(t *testing.T) {
+	testCases := []struct {
+		image      string
+		repository string
+		tag        string
+	}{
+		{"screwdrivercd/launcher:stable", "screwdrivercd/launcher", "stable"},
+		{"localhost:5000/launcher:v6", "localhost:5000/launcher", "v6"},
+		{"localhost:5000/launcher", "localhost:5000/launcher", "latest"},
+		{"launcher", "launcher", "latest"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.image, func(t *testing.T) {
+			repository, tag := splitTag(tt.image)
+			assert.Equal(t, tt.repository, repository)
+			assert.Equal(t, tt.tag, tag)
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package launch
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultJUnitPattern is the default glob of the JUnit XML files in the artifacts directory.
+	DefaultJUnitPattern = "*.xml"
+	// JUnitReportFile is the file in the artifacts directory where the test suites of the build are consolidated.
+	JUnitReportFile = "junit-report.xml"
+)
+
+// JUnitTestCase is a test case in the JUnit XML report.
+type JUnitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr,omitempty"`
+	Time      string        `xml:"time,attr,omitempty"`
+	Failure   *JUnitMessage `xml:"failure,omitempty"`
+	Error     *JUnitMessage `xml:"error,omitempty"`
+	Skipped   *JUnitMessage `xml:"skipped,omitempty"`
+}
+
+// JUnitMessage is the failure, error or skipped message of the test case.
+type JUnitMessage struct {
+	Message string `xml:"message,attr,omitempty"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+// JUnitTestSuite is a test suite in the JUnit XML report.
+// File is the path of the report relative to the artifacts directory.
+type JUnitTestSuite struct {
+	XMLName   xml.Name         `xml:"testsuite"`
+	Name      string           `xml:"name,attr"`
+	Tests     int              `xml:"tests,attr"`
+	Failures  int              `xml:"failures,attr"`
+	Errors    int              `xml:"errors,attr"`
+	Skipped   int              `xml:"skipped,attr"`
+	Time      string           `xml:"time,attr,omitempty"`
+	TestCases []JUnitTestCase  `xml:"testcase"`
+	Suites    []JUnitTestSuite `xml:"testsuite"`
+	File      string           `xml:"-"`
+}
+
+// Passed reports whether the test suite has neither failures nor errors.
+func (s JUnitTestSuite) Passed() bool {
+	return s.Failures == 0 && s.Errors == 0
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Suites   []JUnitTestSuite `xml:"testsuite"`
+}
+
+// matchJUnitPattern reports whether the file is matched with the pattern.
+// The pattern is matched with the file name, or with the relative path if it contains a slash.
+func matchJUnitPattern(pattern, rel string) bool {
+	name := rel
+	if !strings.Contains(pattern, "/") {
+		name = filepath.Base(rel)
+	}
+	matched, err := filepath.Match(pattern, name)
+	return err == nil && matched
+}
+
+// countTestCases fills the counts of the test suite from its test cases when the report doesn't have them.
+func countTestCases(s *JUnitTestSuite) {
+	if s.Tests != 0 || len(s.TestCases) == 0 {
+		return
+	}
+	for _, c := range s.TestCases {
+		s.Tests++
+		switch {
+		case c.Failure != nil:
+			s.Failures++
+		case c.Error != nil:
+			s.Errors++
+		case c.Skipped != nil:
+			s.Skipped++
+		}
+	}
+}
+
+// flattenTestSuites returns the test suites which have test cases, including the nested ones.
+func flattenTestSuites(suites []JUnitTestSuite, file string) []JUnitTestSuite {
+	flattened := make([]JUnitTestSuite, 0, len(suites))
+	for _, s := range suites {
+		nested := s.Suites
+		s.Suites = nil
+		s.File = file
+		countTestCases(&s)
+		if len(s.TestCases) != 0 || len(nested) == 0 {
+			flattened = append(flattened, s)
+		}
+		flattened = append(flattened, flattenTestSuites(nested, file)...)
+	}
+	return flattened
+}
+
+// parseJUnit parses the JUnit XML report whose root is either testsuites or testsuite.
+// It returns false if the file is not a JUnit XML report.
+func parseJUnit(data []byte, file string) ([]JUnitTestSuite, bool) {
+	var suites junitTestSuites
+	if err := xml.Unmarshal(data, &suites); err == nil {
+		return flattenTestSuites(suites.Suites, file), true
+	}
+
+	var suite JUnitTestSuite
+	if err := xml.Unmarshal(data, &suite); err == nil {
+		return flattenTestSuites([]JUnitTestSuite{suite}, file), true
+	}
+
+	return nil, false
+}
+
+// ReadJUnitReports returns the test suites in the JUnit XML files in the artifacts directory which are modified after since.
+// The files matched with the pattern which are not JUnit XML reports are ignored.
+func ReadJUnitReports(artifactsPath, pattern string, since time.Time) ([]JUnitTestSuite, error) {
+	artifacts, err := ReadArtifacts(artifactsPath, since)
+	if err != nil {
+		return nil, err
+	}
+
+	suites := make([]JUnitTestSuite, 0)
+	for _, a := range artifacts {
+		if a.Path == JUnitReportFile || !matchJUnitPattern(pattern, a.Path) {
+			continue
+		}
+
+		data, err := ioutil.ReadFile(filepath.Join(artifactsPath, filepath.FromSlash(a.Path)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JUnit report: %v", err)
+		}
+
+		parsed, ok := parseJUnit(data, a.Path)
+		if !ok {
+			continue
+		}
+		suites = append(suites, parsed...)
+	}
+
+	return suites, nil
+}
+
+// WriteJUnitReport writes the test suites into a JUnit XML report.
+func WriteJUnitReport(filePath string, suites []JUnitTestSuite) error {
+	report := junitTestSuites{Suites: suites}
+	for _, s := range suites {
+		report.Tests += s.Tests
+		report.Failures += s.Failures
+		report.Errors += s.Errors
+		report.Skipped += s.Skipped
+	}
+
+	data, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to write JUnit report: %v", err)
+	}
+
+	err = ioutil.WriteFile(filePath, append([]byte(xml.Header), append(data, '\n')...), os.FileMode(0666))
+	if err != nil {
+		return fmt.Errorf("failed to write JUnit report: %v", err)
+	}
+
+	return nil
+}
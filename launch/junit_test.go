@@ -0,0 +1,133 @@
+package launch
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchJUnitPattern(t *testing.T) {
+	testCases := []struct {
+		pattern string
+		rel     string
+		expect  bool
+	}{
+		{"*.xml", "junit.xml", true},
+		{"*.xml", "reports/junit.xml", true},
+		{"TEST-*.xml", "reports/TEST-app.xml", true},
+		{"TEST-*.xml", "reports/junit.xml", false},
+		{"reports/*.xml", "reports/junit.xml", true},
+		{"reports/*.xml", "main/reports/junit.xml", false},
+		{"", "junit.xml", false},
+	}
+
+	for _, tt := range testCases {
+		assert.Equal(t, tt.expect, matchJUnitPattern(tt.pattern, tt.rel), tt.pattern+" "+tt.rel)
+	}
+}
+
+func TestReadJUnitReports(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sd-artifacts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeSourceFiles(t, dir, map[string]string{
+		"main/reports/junit.xml": `<?xml version="1.0"?>
+<testsuites>
+  <testsuite name="api" tests="2" failures="1" errors="0" skipped="0">
+    <testcase name="get" classname="api"/>
+    <testcase name="post" classname="api"><failure message="expected 200">body</failure></testcase>
+  </testsuite>
+  <testsuite name="nested">
+    <testsuite name="db">
+      <testcase name="connect"/>
+      <testcase name="migrate"><skipped/></testcase>
+    </testsuite>
+  </testsuite>
+</testsuites>`,
+		"test/TEST-ui.xml": `<testsuite name="ui" tests="1" failures="0" errors="1"><testcase name="render"><error/></testcase></testsuite>`,
+		"test/pom.xml":     `<project><name>app</name></project>`,
+		"test/broken.xml":  `<testsuite`,
+		"test/junit.txt":   `<testsuite name="txt"/>`,
+		JUnitReportFile:    `<testsuites><testsuite name="consolidated"/></testsuites>`,
+	})
+
+	t.Run("success", func(t *testing.T) {
+		suites, err := ReadJUnitReports(dir, DefaultJUnitPattern, time.Now().Add(-time.Minute))
+		assert.Nil(t, err)
+
+		assert.Equal(t, 3, len(suites))
+		assert.Equal(t, "api", suites[0].Name)
+		assert.Equal(t, "main/reports/junit.xml", suites[0].File)
+		assert.Equal(t, 2, suites[0].Tests)
+		assert.Equal(t, 1, suites[0].Failures)
+		assert.False(t, suites[0].Passed())
+		assert.Equal(t, "expected 200", suites[0].TestCases[1].Failure.Message)
+
+		assert.Equal(t, "db", suites[1].Name)
+		assert.Equal(t, 2, suites[1].Tests)
+		assert.Equal(t, 1, suites[1].Skipped)
+		assert.True(t, suites[1].Passed())
+
+		assert.Equal(t, "ui", suites[2].Name)
+		assert.Equal(t, "test/TEST-ui.xml", suites[2].File)
+		assert.Equal(t, 1, suites[2].Errors)
+	})
+
+	t.Run("success with pattern", func(t *testing.T) {
+		suites, err := ReadJUnitReports(dir, "TEST-*.xml", time.Now().Add(-time.Minute))
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(suites))
+		assert.Equal(t, "ui", suites[0].Name)
+	})
+
+	t.Run("success with old reports", func(t *testing.T) {
+		suites, err := ReadJUnitReports(dir, DefaultJUnitPattern, time.Now().Add(time.Minute))
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(suites))
+	})
+}
+
+func TestWriteJUnitReport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sd-artifacts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	suites := []JUnitTestSuite{
+		{Name: "api", Tests: 2, Failures: 1, File: "main/junit.xml", TestCases: []JUnitTestCase{
+			{Name: "get"},
+			{Name: "post", Failure: &JUnitMessage{Message: "expected 200"}},
+		}},
+		{Name: "ui", Tests: 1, Errors: 1, TestCases: []JUnitTestCase{
+			{Name: "render", Error: &JUnitMessage{}},
+		}},
+	}
+
+	filePath := filepath.Join(dir, JUnitReportFile)
+	err = WriteJUnitReport(filePath, suites)
+	assert.Nil(t, err)
+
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(t, string(data), `<testsuites tests="3" failures="1" errors="1" skipped="0">`)
+
+	parsed, ok := parseJUnit(data, JUnitReportFile)
+	assert.True(t, ok)
+	assert.Equal(t, 2, len(parsed))
+	assert.Equal(t, "post", parsed[0].TestCases[1].Name)
+	assert.Equal(t, "expected 200", parsed[0].TestCases[1].Failure.Message)
+	assert.Equal(t, "render", parsed[1].TestCases[0].Name)
+
+	err = WriteJUnitReport(filepath.Join(dir, "none", JUnitReportFile), suites)
+	assert.NotNil(t, err)
+}
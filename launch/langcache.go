@@ -0,0 +1,76 @@
+package launch
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// LangCacheVolumePrefix is the prefix of the named volumes of the language caches, which are shared by all the builds.
+	LangCacheVolumePrefix = "sd-local-cache-"
+	// containerLangCacheDir is the directory where the volumes of the language caches are mounted.
+	containerLangCacheDir = "/sd/lang-cache"
+)
+
+// langCache is the cache of the packages of the ecosystem, which is used when any of the files exists in the source directory.
+// The values of the environment variables are formatted with the directory where the volume is mounted.
+type langCache struct {
+	name  string
+	files []string
+	env   [][2]string
+}
+
+var langCaches = []langCache{
+	{"npm", []string{"package.json"}, [][2]string{{"npm_config_cache", "%s/npm"}, {"YARN_CACHE_FOLDER", "%s/yarn"}}},
+	{"maven", []string{"pom.xml"}, [][2]string{{"MAVEN_OPTS", "-Dmaven.repo.local=%s/repository"}}},
+	{"go", []string{"go.mod"}, [][2]string{{"GOMODCACHE", "%s/mod"}, {"GOCACHE", "%s/build"}}},
+	{"pip", []string{"requirements.txt", "Pipfile", "pyproject.toml", "setup.py"}, [][2]string{{"PIP_CACHE_DIR", "%s"}}},
+	{"gradle", []string{"build.gradle", "build.gradle.kts", "settings.gradle", "settings.gradle.kts"}, [][2]string{{"GRADLE_USER_HOME", "%s"}}},
+}
+
+// detectLangCaches returns the caches of the ecosystems used in the source directory.
+func detectLangCaches(srcPath string) []langCache {
+	var detected []langCache
+	for _, c := range langCaches {
+		for _, f := range c.files {
+			if _, err := osStat(filepath.Join(srcPath, f)); err == nil {
+				detected = append(detected, c)
+				break
+			}
+		}
+	}
+	return detected
+}
+
+// applyLangCache mounts the named volumes of the language caches detected in the source directory, when lang-cache of the config is set.
+// The environment variables pointing the tools to them are not set when the job or --env sets them.
+func applyLangCache(b *buildEntry, option Option) {
+	if !option.Entry.LangCache {
+		return
+	}
+
+	caches := detectLangCaches(option.SrcPath)
+	if len(caches) == 0 {
+		return
+	}
+
+	// The volumes are owned by root, so that the other users can not write the caches into them
+	if user := strings.SplitN(b.User, ":", 2)[0]; user != "" && user != "0" && user != "root" {
+		logrus.Warnf("The language caches are not mounted, because the build runs as user %s", b.User)
+		return
+	}
+
+	env := b.Environment[0]
+	for _, c := range caches {
+		containerDir := fmt.Sprintf("%s/%s", containerLangCacheDir, c.name)
+		b.CacheVolumes = append(b.CacheVolumes, fmt.Sprintf("%s%s:%s", LangCacheVolumePrefix, c.name, containerDir))
+		for _, e := range c.env {
+			if _, ok := env[e[0]]; !ok {
+				env[e[0]] = fmt.Sprintf(e[1], containerDir)
+			}
+		}
+	}
+}
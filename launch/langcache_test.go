@@ -0,0 +1,76 @@
+package launch
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/screwdriver-cd/sd-local/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyLangCache(t *testing.T) {
+	srcPath, err := ioutil.TempDir("", "src")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(srcPath)
+	for _, f := range []string{"package.json", "go.mod", "README.md"} {
+		if err := ioutil.WriteFile(filepath.Join(srcPath, f), []byte{}, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("success", func(t *testing.T) {
+		b := newBuildEntry()
+		b.Environment[0]["GOCACHE"] = "/tmp/go-build"
+		applyLangCache(&b, Option{Entry: config.Entry{LangCache: true}, SrcPath: srcPath})
+
+		assert.Equal(t, []string{
+			"sd-local-cache-npm:/sd/lang-cache/npm",
+			"sd-local-cache-go:/sd/lang-cache/go",
+		}, b.CacheVolumes)
+
+		env := b.Environment[0]
+		assert.Equal(t, "/sd/lang-cache/npm/npm", env["npm_config_cache"])
+		assert.Equal(t, "/sd/lang-cache/npm/yarn", env["YARN_CACHE_FOLDER"])
+		assert.Equal(t, "/sd/lang-cache/go/mod", env["GOMODCACHE"])
+		assert.Equal(t, "/tmp/go-build", env["GOCACHE"])
+		assert.NotContains(t, env, "MAVEN_OPTS")
+		assert.NotContains(t, env, "PIP_CACHE_DIR")
+		assert.NotContains(t, env, "GRADLE_USER_HOME")
+	})
+
+	t.Run("success as root", func(t *testing.T) {
+		b := newBuildEntry()
+		b.User = "0:0"
+		applyLangCache(&b, Option{Entry: config.Entry{LangCache: true}, SrcPath: srcPath})
+
+		assert.Len(t, b.CacheVolumes, 2)
+	})
+
+	t.Run("success without lang-cache", func(t *testing.T) {
+		b := newBuildEntry()
+		applyLangCache(&b, Option{SrcPath: srcPath})
+
+		assert.Nil(t, b.CacheVolumes)
+		assert.NotContains(t, b.Environment[0], "npm_config_cache")
+	})
+
+	t.Run("success with other user", func(t *testing.T) {
+		b := newBuildEntry()
+		b.User = "1000:1000"
+		applyLangCache(&b, Option{Entry: config.Entry{LangCache: true}, SrcPath: srcPath})
+
+		assert.Nil(t, b.CacheVolumes)
+		assert.NotContains(t, b.Environment[0], "npm_config_cache")
+	})
+
+	t.Run("success without ecosystems", func(t *testing.T) {
+		b := newBuildEntry()
+		applyLangCache(&b, Option{Entry: config.Entry{LangCache: true}, SrcPath: filepath.Join(srcPath, "missing")})
+
+		assert.Nil(t, b.CacheVolumes)
+	})
+}
@@ -1,12 +1,14 @@
 package launch
 
 import (
+	"errors"
 	"fmt"
 	"net/url"
 	"os"
 	"os/exec"
 	"path"
 	"runtime"
+	"sync"
 
 	"github.com/screwdriver-cd/sd-local/config"
 	"github.com/screwdriver-cd/sd-local/screwdriver"
@@ -17,6 +19,8 @@ var (
 	lookPath     = exec.LookPath
 	apiVersion   = "v4"
 	storeVersion = "v1"
+	// setupMutex serializes setting up the launcher volumes shared by builds running in parallel.
+	setupMutex = &sync.Mutex{}
 )
 
 type runner interface {
@@ -38,6 +42,8 @@ var _ (Launcher) = (*launch)(nil)
 type launch struct {
 	buildEntry buildEntry
 	runner     runner
+	runtime    string
+	envDigest  string
 }
 
 // EnvVar is a map for environment variables
@@ -58,12 +64,37 @@ type buildEntry struct {
 	Image           string             `json:"-"`
 	JobName         string             `json:"-"`
 	ArtifactsPath   string             `json:"-"`
+	MetaPath        string             `json:"-"`
+	CacheVolumes    []string           `json:"-"`
+	Volumes         []string           `json:"-"`
+	CommandsVolume  string             `json:"-"`
+	ExtraHosts      []string           `json:"-"`
+	Network         string             `json:"-"`
+	Publish         []string           `json:"-"`
+	DNS             []string           `json:"-"`
+	GPUs            string             `json:"-"`
+	User            string             `json:"-"`
+	Tmpfs           []string           `json:"-"`
+	ShmSize         string             `json:"-"`
+	SELinuxLabel    string             `json:"-"`
+	Services        []config.Service   `json:"-"`
+	ComposeFile     string             `json:"-"`
+	ComposeEnv      EnvVar             `json:"-"`
 	MemoryLimit     string             `json:"-"`
+	CPULimit        string             `json:"-"`
 	SrcPath         string             `json:"-"`
+	IgnoredDirs     []string           `json:"-"`
 	UseSudo         bool               `json:"-"`
 	InteractiveMode bool               `json:"-"`
 	SocketPath      string             `json:"-"`
 	UsePrivileged   bool               `json:"-"`
+	CapAdd          []string           `json:"-"`
+	CapDrop         []string           `json:"-"`
+	SecurityOpt     []string           `json:"-"`
+	PauseOnFailure  bool               `json:"-"`
+	Reuse           bool               `json:"-"`
+	SnapshotAfter   string             `json:"-"`
+	SnapshotImage   string             `json:"-"`
 }
 
 // Option is option for launch New
@@ -73,44 +104,122 @@ type Option struct {
 	JobName         string
 	JWT             string
 	ArtifactsPath   string
+	MetaPath        string
+	Cache           CacheOption
+	CommandsPath    string
+	ExtraHosts      []string
+	Network         string
+	Publish         []string
+	GPUs            string
+	User            string
+	Tmpfs           []string
+	ShmSize         string
+	Volumes         []string
+	Services        []config.Service
+	ComposeFile     string
+	Timeout         TimeoutOption
 	Memory          string
+	CPUs            string
 	SrcPath         string
+	IgnoredDirs     []string
 	OptionEnv       EnvVar
+	Secrets         EnvVar
 	Meta            Meta
+	Parameters      map[string]string
+	PullRequest     PullRequest
+	Source          SourceOption
 	UseSudo         bool
 	UsePrivileged   bool
+	CapAdd          []string
+	CapDrop         []string
+	SecurityOpt     []string
 	InteractiveMode bool
+	PauseOnFailure  bool
+	Reuse           bool
+	Snapshot        SnapshotOption
 	SocketPath      string
 	FlagVerbose     bool
+	Runtime         string
+	Platform        string
+	Pull            string
 }
 
 const (
 	defaultArtDir = "/sd/workspace/artifacts"
+	// PullAlways pulls the images before every build, which is the default pull policy.
+	PullAlways = "always"
+	// PullMissing pulls the images only if they are not stored locally.
+	PullMissing = "missing"
+	// PullNever uses the images stored locally without pulling them.
+	PullNever = "never"
+	// DefaultRuntime is the container runtime used when none is specified.
+	DefaultRuntime = "docker"
+	// dockerDesktopSocketPath is the SSH agent socket of the host in Docker Desktop VM on MacOS
+	dockerDesktopSocketPath = "/run/host-services/ssh-auth.sock"
 )
 
+// PullPolicies is the list of the policies to pull the build image and the launcher image
+var PullPolicies = []string{PullAlways, PullMissing, PullNever}
+
 // DefaultSocketPath is a socket path on the localhost to bring in the build container.
 func DefaultSocketPath() string {
 	socketPath := os.Getenv("SSH_AUTH_SOCK")
 
 	if runtime.GOOS == "darwin" {
 		// for Docker Desktop VM on MacOS
-		socketPath = "/run/host-services/ssh-auth.sock"
+		socketPath = dockerDesktopSocketPath
 	}
 
 	return socketPath
 }
 
-func mergeEnv(env, jobEnv, optionEnv EnvVar) []EnvVar {
-	for k, v := range jobEnv {
-		env[k] = v
+// CheckSSHAgent checks that the socket of the SSH agent exists to be forwarded into the build container.
+func CheckSSHAgent(socketPath string) error {
+	if socketPath == "" {
+		return errors.New("SSH agent is not available, start ssh-agent and set $SSH_AUTH_SOCK, or pass its socket with --socket")
+	}
+
+	// The socket of Docker Desktop is in the VM, which can not be seen from the host
+	if socketPath == dockerDesktopSocketPath {
+		return nil
+	}
+
+	info, err := osStat(socketPath)
+	if err != nil {
+		return fmt.Errorf("SSH agent is not available: %v", err)
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("SSH agent is not available: %s is not a socket", socketPath)
 	}
-	for k, v := range optionEnv {
-		env[k] = v
+
+	return nil
+}
+
+func mergeEnv(env EnvVar, envs ...EnvVar) []EnvVar {
+	for _, e := range envs {
+		for k, v := range e {
+			env[k] = v
+		}
 	}
 
 	return []EnvVar{env}
 }
 
+// jobSecrets returns the secrets which are listed in the secrets of the job.
+func jobSecrets(job screwdriver.Job, secrets EnvVar) EnvVar {
+	env := make(EnvVar, len(job.Secrets))
+	for _, name := range job.Secrets {
+		value, ok := secrets[name]
+		if !ok {
+			logrus.Warnf("secret %s is not provided, so it is not set", name)
+			continue
+		}
+		env[name] = value
+	}
+
+	return env
+}
+
 func createBuildEntry(option Option) buildEntry {
 	apiURL, storeURL := option.Entry.APIURL, option.Entry.StoreURL
 
@@ -137,50 +246,107 @@ func createBuildEntry(option Option) buildEntry {
 		"SD_STORE_URL":     storeURL,
 	}
 
-	env := mergeEnv(defaultEnv, option.Job.Environment, option.OptionEnv)
+	env := mergeEnv(defaultEnv, proxyEnv(option.Entry), option.Source.env(), parameterEnv(option.Parameters), option.PullRequest.env(), option.Job.Environment, jobSecrets(option.Job, option.Secrets), option.OptionEnv)
 
-	return buildEntry{
+	b := buildEntry{
 		ID:              0,
 		Environment:     env,
 		EventID:         0,
 		JobID:           0,
 		ParentBuildID:   []int{0},
-		Sha:             "dummy",
-		Meta:            option.Meta,
+		Sha:             option.Source.sha(),
+		Meta:            parameterMeta(option.Meta, option.Parameters),
 		Steps:           option.Job.Steps,
-		Image:           option.Job.Image,
+		Image:           ResolveImage(option.Job.Image, option.Entry),
 		JobName:         option.JobName,
 		ArtifactsPath:   option.ArtifactsPath,
-		MemoryLimit:     option.Memory,
+		MetaPath:        option.MetaPath,
+		MemoryLimit:     memoryLimit(option),
+		CPULimit:        cpuLimit(option),
 		SrcPath:         option.SrcPath,
+		IgnoredDirs:     option.IgnoredDirs,
 		UseSudo:         option.UseSudo,
 		InteractiveMode: option.InteractiveMode,
 		SocketPath:      option.SocketPath,
 		UsePrivileged:   option.UsePrivileged,
+		PauseOnFailure:  option.PauseOnFailure,
+		Reuse:           option.Reuse,
+		ExtraHosts:      option.ExtraHosts,
+		Network:         option.Network,
+		Publish:         option.Publish,
+		DNS:             dnsServers(option.Entry),
+		GPUs:            option.GPUs,
+		User:            option.User,
+		Tmpfs:           option.Tmpfs,
+		ShmSize:         option.ShmSize,
+		SELinuxLabel:    selinuxLabel(option.Entry.SELinuxLabel),
+		Services:        resolveServices(option.Services, option.Entry),
+		ComposeFile:     option.ComposeFile,
+		Volumes:         option.Volumes,
+	}
+
+	// The compose file is interpolated with the environment variables given to the build
+	if option.ComposeFile != "" {
+		b.ComposeEnv = mergeEnv(EnvVar{}, option.Job.Environment, option.OptionEnv)[0]
 	}
+
+	applyTimeout(&b, option.Job, option.Timeout)
+	applyCache(&b, option.Cache)
+	applyLangCache(&b, option)
+	applyCommands(&b, option.CommandsPath)
+	applyTeardown(&b)
+	applySecurity(&b, option)
+	applySnapshot(&b, option)
+
+	return b
+}
+
+// resolveServices returns the services whose images are resolved in the same way as the build image.
+func resolveServices(services []config.Service, entry config.Entry) []config.Service {
+	var resolved []config.Service
+	for _, s := range services {
+		s.Image = ResolveImage(s.Image, entry)
+		resolved = append(resolved, s)
+	}
+	return resolved
 }
 
 // New creates new Launcher interface.
 func New(option Option) Launcher {
 	l := new(launch)
 
-	l.runner = newDocker(option.Entry.Launcher.Image, option.Entry.Launcher.Version, option.UseSudo, option.InteractiveMode, option.SocketPath, option.FlagVerbose)
+	l.runtime = option.Runtime
+	if l.runtime == "" {
+		l.runtime = detectRuntime()
+	}
+
+	launcherImage, launcherVersion := option.Entry.Launcher.Image, option.Entry.Launcher.Version
+	launcher := fmt.Sprintf("%s:%s", launcherImage, launcherVersion)
+	if resolved := ResolveImage(launcher, option.Entry); resolved != launcher {
+		launcherImage, launcherVersion = splitTag(resolved)
+	}
+
+	l.runner = newDocker(newContainerRuntime(l.runtime), launcherImage, launcherVersion, option.UseSudo, option.InteractiveMode, option.SocketPath, option.Platform, option.Pull, option.FlagVerbose)
 	l.buildEntry = createBuildEntry(option)
+	l.envDigest = envDigest(l.buildEntry.Environment[0], option.Secrets)
 
 	return l
 }
 
 // Run runs the build specified.
 func (l *launch) Run() error {
-	if _, err := lookPath("docker"); err != nil {
-		return fmt.Errorf("`docker` command is not found in $PATH: %v", err)
+	if _, err := lookPath(l.runtime); err != nil {
+		return fmt.Errorf("`%s` command is not found in $PATH: %v", l.runtime, err)
 	}
 
-	if err := l.runner.setupBin(); err != nil {
+	setupMutex.Lock()
+	err := l.runner.setupBin()
+	setupMutex.Unlock()
+	if err != nil {
 		return fmt.Errorf("failed to setup build: %v", err)
 	}
 
-	err := l.runner.runBuild(l.buildEntry)
+	err = l.runner.runBuild(l.buildEntry)
 	if err != nil {
 		return fmt.Errorf("failed to run build: %v", err)
 	}
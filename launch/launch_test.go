@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -29,6 +30,7 @@ func newBuildEntry(options ...func(b *buildEntry)) buildEntry {
 			"SD_API_URL":       "http://api-test.screwdriver.cd/v4",
 			"SD_STORE_URL":     "http://store-test.screwdriver.cd/v1",
 			"SD_TOKEN":         "testjwt",
+			"SD_SOURCE_DIR":    "/sd/workspace/src/screwdriver.cd/sd-local/local-build",
 			"FOO":              "foo",
 		}},
 		EventID:       0,
@@ -65,6 +67,9 @@ func TestNew(t *testing.T) {
 
 		expectedBuildEntry := newBuildEntry()
 		expectedBuildEntry.SrcPath = "/test/sd-local/build/repo"
+		applyTeardown(&expectedBuildEntry)
+		applySecurity(&expectedBuildEntry, Option{})
+		expectedBuildEntry.SELinuxLabel = selinuxLabel("")
 
 		option := Option{
 			Job:           job,
@@ -96,6 +101,9 @@ func TestNew(t *testing.T) {
 
 		expectedBuildEntry := newBuildEntry()
 		expectedBuildEntry.Environment[0]["SD_ARTIFACTS_DIR"] = "/sd/workspace/artifacts"
+		applyTeardown(&expectedBuildEntry)
+		applySecurity(&expectedBuildEntry, Option{})
+		expectedBuildEntry.SELinuxLabel = selinuxLabel("")
 
 		option := Option{
 			Job:           job,
@@ -111,6 +119,108 @@ func TestNew(t *testing.T) {
 		assert.True(t, ok)
 		assert.Equal(t, expectedBuildEntry, l.buildEntry)
 	})
+
+	t.Run("success with registry mirror", func(t *testing.T) {
+		buf, _ := ioutil.ReadFile(filepath.Join(testDir, "job.json"))
+		job := screwdriver.Job{}
+		_ = json.Unmarshal(buf, &job)
+
+		option := Option{
+			Job: job,
+			Entry: config.Entry{
+				APIURL:         "http://api-test.screwdriver.cd",
+				StoreURL:       "http://store-test.screwdriver.cd",
+				Launcher:       config.Launcher{Version: "stable", Image: "screwdrivercd/launcher"},
+				RegistryMirror: "mirror.example.com",
+			},
+			JobName:       "test",
+			ArtifactsPath: "sd-artifacts",
+			Meta:          Meta{},
+		}
+
+		l := New(option).(*launch)
+		d := l.runner.(*docker)
+		assert.Equal(t, "mirror.example.com/library/node:12", l.buildEntry.Image)
+		assert.Equal(t, "mirror.example.com/screwdrivercd/launcher", d.setupImage)
+		assert.Equal(t, "stable", d.setupImageVersion)
+	})
+
+	t.Run("success with secrets", func(t *testing.T) {
+		buf, _ := ioutil.ReadFile(filepath.Join(testDir, "job.json"))
+		job := screwdriver.Job{}
+		_ = json.Unmarshal(buf, &job)
+		job.Secrets = []string{"GIT_KEY", "NPM_TOKEN"}
+
+		option := Option{
+			Job:     job,
+			JobName: "test",
+			Meta:    Meta{},
+			Secrets: EnvVar{
+				"GIT_KEY": "git-key",
+				"AWS_KEY": "aws-key",
+				"FOO":     "secret-foo",
+			},
+			OptionEnv: EnvVar{"FOO": "option-foo"},
+		}
+
+		l := New(option).(*launch)
+		env := l.buildEntry.Environment[0]
+		assert.Equal(t, "git-key", env["GIT_KEY"])
+		assert.NotContains(t, env, "NPM_TOKEN")
+		assert.NotContains(t, env, "AWS_KEY")
+		assert.Equal(t, "option-foo", env["FOO"])
+	})
+
+	t.Run("success with runtime", func(t *testing.T) {
+		buf, _ := ioutil.ReadFile(filepath.Join(testDir, "job.json"))
+		job := screwdriver.Job{}
+		_ = json.Unmarshal(buf, &job)
+
+		testCases := []struct {
+			runtime string
+			expect  string
+		}{
+			{"", "docker"},
+			{"docker", "docker"},
+			{"podman", "podman"},
+			{"nerdctl", "nerdctl"},
+		}
+
+		osStat = func(name string) (os.FileInfo, error) { return nil, nil }
+		defer func() {
+			osStat = os.Stat
+		}()
+
+		for _, tt := range testCases {
+			option := Option{
+				Job:     job,
+				JobName: "test",
+				Meta:    Meta{},
+				Runtime: tt.runtime,
+			}
+
+			l := New(option).(*launch)
+			assert.Equal(t, tt.expect, l.runtime)
+			assert.Equal(t, tt.expect, l.runner.(*docker).runtime.name())
+		}
+	})
+}
+
+func TestNewWithComposeFile(t *testing.T) {
+	option := Option{
+		Job:         screwdriver.Job{Image: "node:18", Environment: map[string]string{"DB_HOST": "db", "DB_PORT": "5432"}},
+		JobName:     "test",
+		Meta:        Meta{},
+		OptionEnv:   EnvVar{"DB_PORT": "15432"},
+		Secrets:     EnvVar{"DB_PASSWORD": "secret"},
+		ComposeFile: "/repo/docker-compose.yml",
+	}
+
+	l := New(option).(*launch)
+	assert.Equal(t, "/repo/docker-compose.yml", l.buildEntry.ComposeFile)
+	// The secrets are not passed into the compose command
+	assert.Equal(t, EnvVar{"DB_HOST": "db", "DB_PORT": "15432"}, l.buildEntry.ComposeEnv)
+	assert.Equal(t, map[string]string{"DB_HOST": "db", "DB_PORT": "5432"}, option.Job.Environment)
 }
 
 type mockRunner struct {
@@ -144,6 +254,7 @@ func TestRun(t *testing.T) {
 
 		launch := launch{
 			buildEntry: newBuildEntry(),
+			runtime:    "docker",
 			runner: &mockRunner{
 				errorRunBuild: nil,
 				errorSetupBin: nil,
@@ -170,6 +281,7 @@ func TestRun(t *testing.T) {
 
 		launch := launch{
 			buildEntry: newBuildEntry(),
+			runtime:    "docker",
 			runner: &mockRunner{
 				errorRunBuild: nil,
 				errorSetupBin: nil,
@@ -189,6 +301,26 @@ func TestRun(t *testing.T) {
 		assert.Equal(t, fmt.Errorf("`docker` command is not found in $PATH: exec: \"docker\": executable file not found in $PATH"), err)
 	})
 
+	t.Run("failure in lookPath with podman", func(t *testing.T) {
+		launch := launch{
+			buildEntry: newBuildEntry(),
+			runtime:    "podman",
+			runner:     &mockRunner{},
+		}
+
+		lookPath = func(cmd string) (string, error) {
+			return "", fmt.Errorf("exec: %q: executable file not found in $PATH", cmd)
+		}
+
+		defer func() {
+			lookPath = exec.LookPath
+		}()
+
+		err := launch.Run()
+
+		assert.Equal(t, fmt.Errorf("`podman` command is not found in $PATH: exec: \"podman\": executable file not found in $PATH"), err)
+	})
+
 	t.Run("failure in SetupBin", func(t *testing.T) {
 		buf, _ := ioutil.ReadFile(filepath.Join(testDir, "job.json"))
 		job := screwdriver.Job{}
@@ -196,6 +328,7 @@ func TestRun(t *testing.T) {
 
 		launch := launch{
 			buildEntry: newBuildEntry(),
+			runtime:    "docker",
 			runner: &mockRunner{
 				errorRunBuild: nil,
 				errorSetupBin: fmt.Errorf("docker: Error response from daemon"),
@@ -222,6 +355,7 @@ func TestRun(t *testing.T) {
 
 		launch := launch{
 			buildEntry: newBuildEntry(),
+			runtime:    "docker",
 			runner: &mockRunner{
 				errorRunBuild: fmt.Errorf("docker: Error response from daemon"),
 				errorSetupBin: nil,
@@ -279,3 +413,46 @@ func TestClean(t *testing.T) {
 		assert.Equal(t, 1, mRunner.cleanCalledCount)
 	})
 }
+
+func TestCheckSSHAgent(t *testing.T) {
+	dir, err := ioutil.TempDir("", "agent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	socketPath := filepath.Join(dir, "agent.sock")
+	l, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	filePath := filepath.Join(dir, "agent.txt")
+	if err := ioutil.WriteFile(filePath, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name      string
+		path      string
+		expectErr string
+	}{
+		{name: "socket", path: socketPath},
+		{name: "Docker Desktop", path: "/run/host-services/ssh-auth.sock"},
+		{name: "not set", path: "", expectErr: "SSH agent is not available, start ssh-agent and set $SSH_AUTH_SOCK, or pass its socket with --socket"},
+		{name: "not found", path: filepath.Join(dir, "notfound.sock"), expectErr: "SSH agent is not available: stat " + filepath.Join(dir, "notfound.sock") + ": no such file or directory"},
+		{name: "not socket", path: filePath, expectErr: "SSH agent is not available: " + filePath + " is not a socket"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckSSHAgent(tt.path)
+			if tt.expectErr != "" {
+				assert.Equal(t, tt.expectErr, err.Error())
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package launch
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// MetaDir is the directory name of the host side directory which is mounted into /sd/meta.
+	MetaDir = "meta"
+	// MetaFile is the file name of metadata written by the launcher.
+	MetaFile = "meta.json"
+	// containerMetaDir is the directory where the launcher reads and writes metadata.
+	containerMetaDir = "/sd/meta"
+)
+
+// ReadMeta reads the metadata stored in dir. It returns empty metadata if no metadata is stored yet.
+func ReadMeta(dir string) (Meta, error) {
+	meta := Meta{}
+
+	buf, err := ioutil.ReadFile(filepath.Join(dir, MetaFile))
+	if os.IsNotExist(err) {
+		return meta, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read meta: %v", err)
+	}
+
+	err = json.Unmarshal(buf, &meta)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse meta in %s: %v", dir, err)
+	}
+
+	return meta, nil
+}
+
+// WriteMeta stores meta in dir.
+func WriteMeta(dir string, meta Meta) error {
+	buf, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal meta: %v", err)
+	}
+
+	err = os.MkdirAll(dir, 0777)
+	if err != nil {
+		return fmt.Errorf("failed to create meta directory: %v", err)
+	}
+
+	err = ioutil.WriteFile(filepath.Join(dir, MetaFile), buf, 0666)
+	if err != nil {
+		return fmt.Errorf("failed to write meta: %v", err)
+	}
+
+	return nil
+}
+
+// Get returns the value of key. Nested values can be specified with dot separated keys (e.g. foo.bar).
+func (m Meta) Get(key string) (interface{}, bool) {
+	var value interface{} = map[string]interface{}(m)
+	for _, k := range strings.Split(key, ".") {
+		nested, ok := toMap(value)
+		if !ok {
+			return nil, false
+		}
+		value, ok = nested[k]
+		if !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// Set sets value to key. Intermediate objects of dot separated keys are created if not exist.
+func (m Meta) Set(key string, value interface{}) {
+	keys := strings.Split(key, ".")
+	current := map[string]interface{}(m)
+	for _, k := range keys[:len(keys)-1] {
+		nested, ok := toMap(current[k])
+		if !ok {
+			nested = map[string]interface{}{}
+			current[k] = nested
+		}
+		current = nested
+	}
+	current[keys[len(keys)-1]] = value
+}
+
+// Flatten returns the values with dot separated keys, sorted by key.
+func (m Meta) Flatten() []string {
+	lines := make([]string, 0, len(m))
+	flattenMeta("", map[string]interface{}(m), &lines)
+	sort.Strings(lines)
+	return lines
+}
+
+func flattenMeta(prefix string, m map[string]interface{}, lines *[]string) {
+	for k, v := range m {
+		key := prefix + k
+		if nested, ok := toMap(v); ok && len(nested) != 0 {
+			flattenMeta(key+".", nested, lines)
+			continue
+		}
+		*lines = append(*lines, fmt.Sprintf("%s=%s", key, FormatMetaValue(v)))
+	}
+}
+
+// FormatMetaValue returns the string representation of a value of metadata.
+// Strings are returned as is and the others are formatted in JSON.
+func FormatMetaValue(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	buf, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(buf)
+}
+
+func toMap(value interface{}) (map[string]interface{}, bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return v, true
+	case Meta:
+		return v, true
+	}
+	return nil, false
+}
+
+// Merge returns new metadata which has the keys of both. The values of other take precedence.
+func (m Meta) Merge(other Meta) Meta {
+	merged := make(Meta, len(m)+len(other))
+	for k, v := range m {
+		merged[k] = v
+	}
+	for k, v := range other {
+		merged[k] = v
+	}
+	return merged
+}
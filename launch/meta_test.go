@@ -0,0 +1,134 @@
+package launch
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadMeta(t *testing.T) {
+	dir, err := ioutil.TempDir("", "meta")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	t.Run("success without meta file", func(t *testing.T) {
+		meta, err := ReadMeta(dir)
+		assert.Nil(t, err)
+		assert.Equal(t, Meta{}, meta)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		err := ioutil.WriteFile(filepath.Join(dir, MetaFile), []byte(`{"foo":"bar","num":1}`), 0666)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		meta, err := ReadMeta(dir)
+		assert.Nil(t, err)
+		assert.Equal(t, Meta{"foo": "bar", "num": float64(1)}, meta)
+	})
+
+	t.Run("failure by invalid JSON", func(t *testing.T) {
+		err := ioutil.WriteFile(filepath.Join(dir, MetaFile), []byte(`{`), 0666)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = ReadMeta(dir)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestMetaMerge(t *testing.T) {
+	base := Meta{"foo": "base", "bar": "base"}
+	merged := base.Merge(Meta{"foo": "other", "baz": "other"})
+
+	assert.Equal(t, Meta{"foo": "other", "bar": "base", "baz": "other"}, merged)
+	assert.Equal(t, Meta{"foo": "base", "bar": "base"}, base)
+}
+
+func TestWriteMeta(t *testing.T) {
+	dir, err := ioutil.TempDir("", "meta")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	metaDir := filepath.Join(dir, "job", MetaDir)
+	err = WriteMeta(metaDir, Meta{"foo": "bar"})
+	assert.Nil(t, err)
+
+	meta, err := ReadMeta(metaDir)
+	assert.Nil(t, err)
+	assert.Equal(t, Meta{"foo": "bar"}, meta)
+}
+
+func TestMetaGet(t *testing.T) {
+	meta := Meta{
+		"foo": "bar",
+		"nested": map[string]interface{}{
+			"num": float64(1),
+		},
+	}
+
+	testCases := []struct {
+		key      string
+		expected interface{}
+		found    bool
+	}{
+		{"foo", "bar", true},
+		{"nested.num", float64(1), true},
+		{"nested", map[string]interface{}{"num": float64(1)}, true},
+		{"nested.none", nil, false},
+		{"foo.bar", nil, false},
+		{"none", nil, false},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.key, func(t *testing.T) {
+			value, found := meta.Get(tt.key)
+			assert.Equal(t, tt.found, found)
+			assert.Equal(t, tt.expected, value)
+		})
+	}
+}
+
+func TestMetaSet(t *testing.T) {
+	meta := Meta{"foo": "bar"}
+	meta.Set("nested.num", float64(1))
+	meta.Set("foo", "baz")
+	meta.Set("nested.str", "qux")
+
+	expected := Meta{
+		"foo": "baz",
+		"nested": map[string]interface{}{
+			"num": float64(1),
+			"str": "qux",
+		},
+	}
+	assert.Equal(t, expected, meta)
+}
+
+func TestMetaFlatten(t *testing.T) {
+	meta := Meta{
+		"foo":   "bar",
+		"empty": map[string]interface{}{},
+		"nested": map[string]interface{}{
+			"num":  float64(1),
+			"list": []interface{}{"a", "b"},
+		},
+	}
+
+	expected := []string{
+		"empty={}",
+		"foo=bar",
+		"nested.list=[\"a\",\"b\"]",
+		"nested.num=1",
+	}
+	assert.Equal(t, expected, meta.Flatten())
+}
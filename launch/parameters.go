@@ -0,0 +1,41 @@
+package launch
+
+import (
+	"regexp"
+	"strings"
+)
+
+const (
+	// ParamEnvPrefix is the prefix of the environment variables of the build parameters.
+	ParamEnvPrefix    = "SD_PARAM_"
+	parametersMetaKey = "parameters"
+)
+
+var invalidEnvChars = regexp.MustCompile(`[^A-Z0-9_]`)
+
+// ParamEnvName returns the name of the environment variable of the parameter (e.g. SD_PARAM_DRY_RUN for dry-run).
+func ParamEnvName(name string) string {
+	return ParamEnvPrefix + invalidEnvChars.ReplaceAllString(strings.ToUpper(name), "_")
+}
+
+// parameterEnv returns the environment variables of the build parameters.
+func parameterEnv(parameters map[string]string) EnvVar {
+	env := make(EnvVar, len(parameters))
+	for name, value := range parameters {
+		env[ParamEnvName(name)] = value
+	}
+	return env
+}
+
+// parameterMeta sets the build parameters into meta as Screwdriver.cd does (e.g. parameters.region.value).
+func parameterMeta(meta Meta, parameters map[string]string) Meta {
+	if len(parameters) == 0 {
+		return meta
+	}
+
+	values := make(map[string]interface{}, len(parameters))
+	for name, value := range parameters {
+		values[name] = map[string]interface{}{"value": value}
+	}
+	return meta.Merge(Meta{parametersMetaKey: values})
+}
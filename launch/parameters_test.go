@@ -0,0 +1,39 @@
+package launch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParamEnvName(t *testing.T) {
+	assert.Equal(t, "SD_PARAM_REGION", ParamEnvName("region"))
+	assert.Equal(t, "SD_PARAM_DRY_RUN", ParamEnvName("dry-run"))
+	assert.Equal(t, "SD_PARAM_DRYRUN", ParamEnvName("dryRun"))
+}
+
+func TestParameterEnv(t *testing.T) {
+	assert.Equal(t, EnvVar{}, parameterEnv(nil))
+	assert.Equal(t, EnvVar{
+		"SD_PARAM_REGION":  "us-west-1",
+		"SD_PARAM_DRY_RUN": "true",
+	}, parameterEnv(map[string]string{"region": "us-west-1", "dry-run": "true"}))
+}
+
+func TestParameterMeta(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		meta := Meta{"foo": "bar", "parameters": "overwritten"}
+		assert.Equal(t, Meta{
+			"foo": "bar",
+			"parameters": map[string]interface{}{
+				"region": map[string]interface{}{"value": "us-west-1"},
+			},
+		}, parameterMeta(meta, map[string]string{"region": "us-west-1"}))
+		assert.Equal(t, "overwritten", meta["parameters"])
+	})
+
+	t.Run("success without parameters", func(t *testing.T) {
+		meta := Meta{"foo": "bar"}
+		assert.Equal(t, meta, parameterMeta(meta, nil))
+	})
+}
@@ -0,0 +1,77 @@
+package launch
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/screwdriver-cd/sd-local/screwdriver"
+)
+
+// safeShellWord matches the words which are printed without quotes in the command line.
+var safeShellWord = regexp.MustCompile(`^[a-zA-Z0-9_./:=@%+,-]+$`)
+
+// Plan is the build resolved from the option, which is printed with --dry-run instead of running it.
+type Plan struct {
+	Image string
+	// Steps are the steps of the job in the order they are run, whose commands are not wrapped to record their results
+	Steps   []screwdriver.Step
+	Env     EnvVar
+	Volumes []string
+	// Command is the command line which runs the build container
+	Command string
+}
+
+// NewPlan resolves the build of the option in the same way as Run, without running any commands of the container runtime.
+// The image built from Dockerfile is shown with its tag, and the snapshot taken after the setup steps is not looked up.
+func NewPlan(option Option) (Plan, error) {
+	l := New(option).(*launch)
+	d := l.runner.(*docker)
+	b := l.buildEntry
+
+	image := b.Image
+	if IsDockerfileImage(image) {
+		image = ParseDockerfileImage(image).Tag()
+	}
+
+	// The build joins the network of the compose project or the one created for the services
+	network := b.Network
+	if b.ComposeFile != "" {
+		network = composeNetwork(composeProjectName(b.JobName))
+	} else if len(b.Services) != 0 && network == "" {
+		network = pausedContainerName(b.JobName)
+	}
+
+	configJSON, err := json.Marshal(b)
+	if err != nil {
+		return Plan{}, err
+	}
+	options := d.runOptions(b, buildContainerName(b), image, network, d.launchCommands(b, string(configJSON)))
+
+	var volumes []string
+	for i := 0; i < len(options)-1; i++ {
+		if options[i] == "-v" {
+			volumes = append(volumes, options[i+1])
+		}
+	}
+
+	return Plan{
+		Image:   image,
+		Steps:   sortTeardown(option.Job.Steps),
+		Env:     b.Environment[0],
+		Volumes: volumes,
+		Command: shellJoin(d.commandLine(d.runtime.run(options...)...)),
+	}, nil
+}
+
+// shellJoin joins the words into the command line, where the words with the special characters are quoted.
+func shellJoin(words []string) string {
+	quoted := make([]string, 0, len(words))
+	for _, w := range words {
+		if !safeShellWord.MatchString(w) {
+			w = shellQuote(w)
+		}
+		quoted = append(quoted, w)
+	}
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,75 @@
+package launch
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/screwdriver-cd/sd-local/config"
+	"github.com/screwdriver-cd/sd-local/screwdriver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPlan(t *testing.T) {
+	newOption := func() Option {
+		return Option{
+			Job: screwdriver.Job{
+				Image:       "node:12",
+				Steps:       []screwdriver.Step{{Name: "test", Command: "npm test"}},
+				Environment: map[string]string{"FOO": "foo bar"},
+			},
+			Entry: config.Entry{
+				APIURL:       "http://api-test.screwdriver.cd",
+				StoreURL:     "http://store-test.screwdriver.cd",
+				Launcher:     config.Launcher{Version: "latest", Image: "screwdrivercd/launcher"},
+				SELinuxLabel: config.SELinuxLabelOff,
+			},
+			JobName:       "test",
+			JWT:           "testjwt",
+			ArtifactsPath: "/test/sd-artifacts",
+			SrcPath:       "/test/repo",
+			Runtime:       "docker",
+			Meta:          Meta{},
+		}
+	}
+
+	t.Run("success", func(t *testing.T) {
+		plan, err := NewPlan(newOption())
+
+		assert.Nil(t, err)
+		assert.Equal(t, "node:12", plan.Image)
+		assert.Equal(t, "test", plan.Steps[0].Name)
+		assert.Equal(t, "testjwt", plan.Env["SD_TOKEN"])
+		assert.Equal(t, "foo bar", plan.Env["FOO"])
+		assert.Equal(t, []string{
+			"/test/repo/:/sd/workspace/src/screwdriver.cd/sd-local/local-build",
+			"/test/sd-artifacts/:/sd/workspace/artifacts",
+			"SD_LAUNCH_BIN:/opt/sd",
+			"SD_LAUNCH_HAB:/opt/sd/hab",
+		}, plan.Volumes)
+		assert.True(t, strings.HasPrefix(plan.Command, "docker container run "))
+		assert.Contains(t, plan.Command, fmt.Sprintf(" --name sd-local-test-%d --rm -v /test/repo/:", os.Getpid()))
+		assert.Contains(t, plan.Command, " node:12 /opt/sd/local_run.sh '{")
+		assert.True(t, strings.HasSuffix(plan.Command, " test http://api-test.screwdriver.cd/v4 http://store-test.screwdriver.cd/v1 /sd/workspace/artifacts/builds.log"))
+	})
+
+	t.Run("success with Dockerfile and services", func(t *testing.T) {
+		option := newOption()
+		option.Job.Image = "./Dockerfile:ci"
+		option.Services = []config.Service{{Name: "db", Image: "postgres:13"}}
+		option.UseSudo = true
+
+		plan, err := NewPlan(option)
+
+		assert.Nil(t, err)
+		assert.Equal(t, ParseDockerfileImage("./Dockerfile:ci").Tag(), plan.Image)
+		assert.True(t, strings.HasPrefix(plan.Command, "sudo docker container run "))
+		assert.Contains(t, plan.Command, fmt.Sprintf(" --network sd-local-test-%d ", os.Getpid()))
+		assert.Contains(t, plan.Command, " "+plan.Image+" /opt/sd/local_run.sh ")
+	})
+}
+
+func TestShellJoin(t *testing.T) {
+	assert.Equal(t, `docker run -e FOO=bar 'echo "it'"'"'s"' /src/:/sd`, shellJoin([]string{"docker", "run", "-e", "FOO=bar", `echo "it's"`, "/src/:/sd"}))
+}
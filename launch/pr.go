@@ -0,0 +1,21 @@
+package launch
+
+import "strconv"
+
+// PullRequest is the pull request which the build is run for. The build is not a PR build if Number is zero.
+type PullRequest struct {
+	Number     int
+	BaseBranch string
+}
+
+// env returns the environment variables which Screwdriver.cd sets in PR builds.
+func (pr PullRequest) env() EnvVar {
+	if pr.Number == 0 {
+		return EnvVar{}
+	}
+
+	return EnvVar{
+		"SD_PULL_REQUEST":     strconv.Itoa(pr.Number),
+		"PR_BASE_BRANCH_NAME": pr.BaseBranch,
+	}
+}
@@ -0,0 +1,15 @@
+package launch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPullRequestEnv(t *testing.T) {
+	assert.Equal(t, EnvVar{}, PullRequest{}.env())
+	assert.Equal(t, EnvVar{
+		"SD_PULL_REQUEST":     "12",
+		"PR_BASE_BRANCH_NAME": "master",
+	}, PullRequest{Number: 12, BaseBranch: "master"}.env())
+}
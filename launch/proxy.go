@@ -0,0 +1,91 @@
+package launch
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/screwdriver-cd/sd-local/config"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	lookupEnv      = os.LookupEnv
+	resolvConfPath = "/etc/resolv.conf"
+)
+
+// proxyEnv returns the proxy environment variables of the build, in both upper and lower cases because the tools read either of them.
+// The variables of the host are passed when proxy-env is set, and the proxies of the config take precedence over them.
+func proxyEnv(entry config.Entry) EnvVar {
+	proxies := [][2]string{
+		{"HTTP_PROXY", entry.HTTPProxy},
+		{"HTTPS_PROXY", entry.HTTPSProxy},
+		{"NO_PROXY", entry.NoProxy},
+	}
+
+	env := make(EnvVar)
+	for _, p := range proxies {
+		name, value := p[0], p[1]
+		if value == "" && entry.ProxyEnv {
+			value = hostEnv(name)
+		}
+		if value == "" {
+			continue
+		}
+		env[name] = value
+		env[strings.ToLower(name)] = value
+	}
+
+	return env
+}
+
+// hostEnv returns the environment variable of the host, which is looked up in upper case first and then in lower case.
+func hostEnv(name string) string {
+	if v, ok := lookupEnv(name); ok && v != "" {
+		return v
+	}
+	v, _ := lookupEnv(strings.ToLower(name))
+	return v
+}
+
+// dnsServers returns the DNS servers of the build, where host is replaced with the DNS servers of the host.
+func dnsServers(entry config.Entry) []string {
+	var servers []string
+	for _, server := range entry.DNS {
+		if server == config.DNSHost {
+			servers = append(servers, hostDNSServers()...)
+			continue
+		}
+		servers = append(servers, server)
+	}
+	return servers
+}
+
+// hostDNSServers returns the nameservers in resolv.conf of the host.
+// The loopback addresses such as the stub resolver of systemd-resolved are skipped, because they are not reachable from the containers.
+func hostDNSServers() []string {
+	f, err := os.Open(resolvConfPath)
+	if err != nil {
+		logrus.Warnf("DNS servers of the host are not used: %v", err)
+		return nil
+	}
+	defer f.Close()
+
+	var servers []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != "nameserver" {
+			continue
+		}
+		if ip := net.ParseIP(fields[1]); ip != nil && !ip.IsLoopback() {
+			servers = append(servers, fields[1])
+		}
+	}
+
+	if len(servers) == 0 {
+		logrus.Warnf("DNS servers of the host are not used, because %s has no nameserver reachable from the containers", resolvConfPath)
+	}
+	return servers
+}
@@ -0,0 +1,74 @@
+package launch
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/screwdriver-cd/sd-local/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProxyEnv(t *testing.T) {
+	defer func() {
+		lookupEnv = os.LookupEnv
+	}()
+
+	hostEnv := map[string]string{
+		"HTTP_PROXY":  "http://host.example.com:8080",
+		"https_proxy": "http://host.example.com:8443",
+		"NO_PROXY":    "localhost",
+	}
+	lookupEnv = func(key string) (string, bool) {
+		v, ok := hostEnv[key]
+		return v, ok
+	}
+
+	testCases := []struct {
+		name     string
+		entry    config.Entry
+		expected EnvVar
+	}{
+		{"disabled", config.Entry{}, EnvVar{}},
+		{"host", config.Entry{ProxyEnv: true}, EnvVar{
+			"HTTP_PROXY": "http://host.example.com:8080", "http_proxy": "http://host.example.com:8080",
+			"HTTPS_PROXY": "http://host.example.com:8443", "https_proxy": "http://host.example.com:8443",
+			"NO_PROXY": "localhost", "no_proxy": "localhost",
+		}},
+		{"override", config.Entry{ProxyEnv: true, HTTPSProxy: "http://proxy.example.com:8443", NoProxy: ".example.com"}, EnvVar{
+			"HTTP_PROXY": "http://host.example.com:8080", "http_proxy": "http://host.example.com:8080",
+			"HTTPS_PROXY": "http://proxy.example.com:8443", "https_proxy": "http://proxy.example.com:8443",
+			"NO_PROXY": ".example.com", "no_proxy": ".example.com",
+		}},
+		{"config only", config.Entry{HTTPProxy: "http://proxy.example.com:8080"}, EnvVar{
+			"HTTP_PROXY": "http://proxy.example.com:8080", "http_proxy": "http://proxy.example.com:8080",
+		}},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, proxyEnv(tt.entry))
+		})
+	}
+}
+
+func TestDNSServers(t *testing.T) {
+	defer func() {
+		resolvConfPath = "/etc/resolv.conf"
+	}()
+
+	dir, err := ioutil.TempDir("", "resolv")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	resolvConfPath = filepath.Join(dir, "resolv.conf")
+	assert.Nil(t, ioutil.WriteFile(resolvConfPath, []byte("# generated\nnameserver 127.0.0.53\nnameserver 10.0.0.2\nsearch example.com\nnameserver 10.0.0.3\n"), 0644))
+
+	assert.Nil(t, dnsServers(config.Entry{}))
+	assert.Equal(t, []string{"8.8.8.8"}, dnsServers(config.Entry{DNS: []string{"8.8.8.8"}}))
+	assert.Equal(t, []string{"10.0.0.2", "10.0.0.3", "8.8.8.8"}, dnsServers(config.Entry{DNS: []string{"host", "8.8.8.8"}}))
+
+	resolvConfPath = filepath.Join(dir, "missing")
+	assert.Equal(t, []string{"8.8.8.8"}, dnsServers(config.Entry{DNS: []string{"host", "8.8.8.8"}}))
+}
@@ -0,0 +1,73 @@
+package launch
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// orphanPattern matches the names of the containers, networks and volumes of the builds of the process pid.
+// They are named after pausedContainerName, and the ones of the compose projects after composeProjectName.
+func orphanPattern(pid int) *regexp.Regexp {
+	return regexp.MustCompile(fmt.Sprintf(`(?i)^sd-local-.+-%d([-_].*)?$`, pid))
+}
+
+// RemoveOrphans removes the containers, networks and volumes left by the builds of the process pid,
+// which exited without cleaning up them, such as when sd-local is killed with SIGKILL.
+func RemoveOrphans(runtime string, useSudo bool, pid int) error {
+	if runtime == "" {
+		runtime = detectRuntime()
+	}
+	d := &docker{runtime: newContainerRuntime(runtime), useSudo: useSudo, mutex: &sync.Mutex{}}
+	pattern := orphanPattern(pid)
+
+	// The containers are removed at first, because the networks and volumes in use can not be removed
+	containers, err := d.listNames(pattern, d.runtime.containerList())
+	if err != nil {
+		return fmt.Errorf("failed to list containers: %v", err)
+	}
+	if len(containers) != 0 {
+		if _, err := d.execDockerCommand(d.runtime.containerRemove(containers...)...); err != nil {
+			return fmt.Errorf("failed to remove containers: %v", err)
+		}
+	}
+
+	networks, err := d.listNames(pattern, d.runtime.networkList())
+	if err != nil {
+		return fmt.Errorf("failed to list networks: %v", err)
+	}
+	for _, network := range networks {
+		if _, err := d.execDockerCommand(d.runtime.networkRemove(network)...); err != nil {
+			return fmt.Errorf("failed to remove network: %v", err)
+		}
+	}
+
+	volumes, err := d.listNames(pattern, d.runtime.volumeList())
+	if err != nil {
+		return fmt.Errorf("failed to list volumes: %v", err)
+	}
+	for _, volume := range volumes {
+		if _, err := d.execDockerCommand(d.runtime.volumeRemove(volume)...); err != nil {
+			return fmt.Errorf("failed to remove volume: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// listNames returns the names printed by the command which match the pattern.
+func (d *docker) listNames(pattern *regexp.Regexp, args []string) ([]string, error) {
+	out, err := d.execDockerCommand(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, name := range strings.Fields(out) {
+		if pattern.MatchString(name) {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,92 @@
+package launch
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// reaperHelperProcess fakes the container runtime which has the resources left by the killed build.
+func reaperHelperProcess(testCase, subcmd string, args []string) {
+	// The subcommand is followed by ls, which is shifted with sudo
+	list := contains(args, "ls")
+
+	switch testCase {
+	case "REAP_SUCCESS", "REAP_SUCCESS_SUDO":
+		if list {
+			names := map[string]string{
+				"container": "sd-local-main-123\nsd-local-main-1234\nsd-local-main-123-db\nsd-local-main-123-postgres-1\nweb",
+				"network":   "bridge\nsd-local-main-123\nsd-local-main-123_default",
+				"volume":    "SD_LAUNCH_BIN\nsd-local-main-123_data",
+			}
+			fmt.Print(names[subcmd])
+		}
+	case "REAP_LIST_FAILS":
+		if list {
+			os.Exit(1)
+		}
+	}
+	os.Exit(0)
+}
+
+func TestOrphanPattern(t *testing.T) {
+	pattern := orphanPattern(123)
+
+	for _, name := range []string{"sd-local-main-123", "sd-local-main-123-db", "sd-local-main-123_default", "sd-local-pr-1-main-123"} {
+		assert.True(t, pattern.MatchString(name), name)
+	}
+	for _, name := range []string{"sd-local-main-1234", "sd-local-main-12", "sd-local-123", "my-sd-local-main-123", "SD_LAUNCH_BIN"} {
+		assert.False(t, pattern.MatchString(name), name)
+	}
+}
+
+func TestRemoveOrphans(t *testing.T) {
+	defer func() {
+		execCommand = exec.Command
+	}()
+
+	t.Run("success", func(t *testing.T) {
+		c := newFakeExecCommand("REAP_SUCCESS")
+		execCommand = c.execCmd
+
+		err := RemoveOrphans("docker", false, 123)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{
+			"docker container ls --all --format {{.Names}}",
+			"docker container rm --force sd-local-main-123 sd-local-main-123-db sd-local-main-123-postgres-1",
+			"docker network ls --format {{.Name}}",
+			"docker network rm sd-local-main-123",
+			"docker network rm sd-local-main-123_default",
+			"docker volume ls --format {{.Name}}",
+			"docker volume rm --force sd-local-main-123_data",
+		}, c.commands)
+	})
+
+	t.Run("success with sudo", func(t *testing.T) {
+		c := newFakeExecCommand("REAP_SUCCESS_SUDO")
+		execCommand = c.execCmd
+
+		err := RemoveOrphans("podman", true, 1234)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{
+			"sudo podman container ls --all --format {{.Names}}",
+			"sudo podman container rm --force sd-local-main-1234",
+			"sudo podman network ls --format {{.Name}}",
+			"sudo podman volume ls --format {{.Name}}",
+		}, c.commands)
+	})
+
+	t.Run("failure by listing containers", func(t *testing.T) {
+		c := newFakeExecCommand("REAP_LIST_FAILS")
+		execCommand = c.execCmd
+
+		err := RemoveOrphans("docker", false, 123)
+
+		assert.Equal(t, "failed to list containers: exit status 1", err.Error())
+	})
+}
@@ -0,0 +1,107 @@
+package launch
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/screwdriver-cd/sd-local/config"
+	"github.com/screwdriver-cd/sd-local/screwdriver"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// CPUAnnotation is the annotation of the CPUs of the build, which is a preset name or a number of CPUs.
+	CPUAnnotation = "screwdriver.cd/cpu"
+	// RAMAnnotation is the annotation of the memory of the build, which is a preset name or a number of GB.
+	RAMAnnotation = "screwdriver.cd/ram"
+)
+
+// cpuPresets and ramPresets are the resources of the preset names in the Kubernetes executor of Screwdriver.cd
+var (
+	cpuPresets = map[string]string{"MICRO": "0.5", "LOW": "2", "HIGH": "6", "TURBO": "12"}
+	ramPresets = map[string]string{"MICRO": "1g", "LOW": "2g", "HIGH": "12g", "TURBO": "16g"}
+)
+
+// annotationResource returns the resource of the annotation, or empty if the job does not have it or it is invalid.
+func annotationResource(job screwdriver.Job, annotation string, presets map[string]string, unit string) string {
+	value, ok := job.Annotations[annotation]
+	if !ok {
+		return ""
+	}
+
+	s := strings.TrimSpace(fmt.Sprint(value))
+	if preset, ok := presets[strings.ToUpper(s)]; ok {
+		return preset
+	}
+
+	if n, err := strconv.ParseFloat(s, 64); err == nil && n > 0 {
+		return s + unit
+	}
+
+	logrus.Warnf("%s annotation is ignored because it is neither a number nor one of MICRO, LOW, HIGH and TURBO: %v", annotation, value)
+	return ""
+}
+
+// exceeds reports whether the limit is more than the limit of the annotation.
+// The limits which can not be compared, such as 2GB which the container runtime accepts, are not reported.
+func exceeds(limit, annotation string, parse func(string) (float64, error)) bool {
+	l, err := parse(limit)
+	if err != nil {
+		return false
+	}
+	a, err := parse(annotation)
+	if err != nil {
+		return false
+	}
+	return l > a
+}
+
+func parseMemory(memory string) (float64, error) {
+	n, err := config.ParseSize(memory)
+	return float64(n), err
+}
+
+func parseCPUs(cpus string) (float64, error) {
+	return strconv.ParseFloat(cpus, 64)
+}
+
+// memoryLimit returns the memory limit of the build container.
+// The option takes precedence over the annotation of the job, and the annotation takes precedence over the config.
+// The build which is given more memory than the annotation is warned, because it may run out of memory on Screwdriver.cd.
+func memoryLimit(option Option) string {
+	ram := annotationResource(option.Job, RAMAnnotation, ramPresets, "g")
+
+	if option.Memory != "" {
+		if ram != "" && exceeds(option.Memory, ram, parseMemory) {
+			logrus.Warnf("The memory limit %s is more than %s of %s annotation, so the build may run out of memory on Screwdriver.cd", option.Memory, ram, RAMAnnotation)
+		}
+		return option.Memory
+	}
+
+	if ram != "" {
+		logrus.Infof("The memory of the build container is limited to %s by %s annotation", ram, RAMAnnotation)
+		return ram
+	}
+
+	return option.Entry.Memory
+}
+
+// cpuLimit returns the number of CPUs of the build container in the same precedence as the memory limit.
+func cpuLimit(option Option) string {
+	cpu := annotationResource(option.Job, CPUAnnotation, cpuPresets, "")
+
+	if option.CPUs != "" {
+		if cpu != "" && exceeds(option.CPUs, cpu, parseCPUs) {
+			logrus.Warnf("The number of CPUs %s is more than %s of %s annotation, so the build may be slower on Screwdriver.cd", option.CPUs, cpu, CPUAnnotation)
+		}
+		return option.CPUs
+	}
+
+	if cpu != "" {
+		logrus.Infof("The CPUs of the build container are limited to %s by %s annotation", cpu, CPUAnnotation)
+		return cpu
+	}
+
+	return option.Entry.CPUs
+}
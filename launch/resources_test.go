@@ -0,0 +1,129 @@
+package launch
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/screwdriver-cd/sd-local/config"
+	"github.com/screwdriver-cd/sd-local/screwdriver"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryLimit(t *testing.T) {
+	testCases := []struct {
+		name        string
+		memory      string
+		annotations map[string]interface{}
+		entry       string
+		expect      string
+	}{
+		{name: "no limit", expect: ""},
+		{name: "option", memory: "8g", annotations: map[string]interface{}{RAMAnnotation: "HIGH"}, entry: "4g", expect: "8g"},
+		{name: "preset annotation", annotations: map[string]interface{}{RAMAnnotation: "HIGH"}, entry: "4g", expect: "12g"},
+		{name: "lower case preset annotation", annotations: map[string]interface{}{RAMAnnotation: "micro"}, expect: "1g"},
+		{name: "number annotation", annotations: map[string]interface{}{RAMAnnotation: 6}, entry: "4g", expect: "6g"},
+		{name: "invalid annotation", annotations: map[string]interface{}{RAMAnnotation: "HUGE"}, entry: "4g", expect: "4g"},
+		{name: "config", entry: "4g", expect: "4g"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			option := Option{
+				Job:    screwdriver.Job{Annotations: tt.annotations},
+				Entry:  config.Entry{Memory: tt.entry},
+				Memory: tt.memory,
+			}
+			assert.Equal(t, tt.expect, memoryLimit(option))
+		})
+	}
+}
+
+func TestCPULimit(t *testing.T) {
+	testCases := []struct {
+		name        string
+		cpus        string
+		annotations map[string]interface{}
+		entry       string
+		expect      string
+	}{
+		{name: "no limit", expect: ""},
+		{name: "option", cpus: "4", annotations: map[string]interface{}{CPUAnnotation: "TURBO"}, entry: "2", expect: "4"},
+		{name: "preset annotation", annotations: map[string]interface{}{CPUAnnotation: "TURBO"}, entry: "2", expect: "12"},
+		{name: "number annotation", annotations: map[string]interface{}{CPUAnnotation: 1.5}, entry: "2", expect: "1.5"},
+		{name: "invalid annotation", annotations: map[string]interface{}{CPUAnnotation: -1}, entry: "2", expect: "2"},
+		{name: "config", entry: "2", expect: "2"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			option := Option{
+				Job:   screwdriver.Job{Annotations: tt.annotations},
+				Entry: config.Entry{CPUs: tt.entry},
+				CPUs:  tt.cpus,
+			}
+			assert.Equal(t, tt.expect, cpuLimit(option))
+		})
+	}
+}
+
+func TestResourceLimitsWarning(t *testing.T) {
+	defer logrus.SetOutput(os.Stderr)
+
+	testCases := []struct {
+		name   string
+		option Option
+		expect string
+	}{
+		{
+			name: "more memory than annotation",
+			option: Option{
+				Job:    screwdriver.Job{Annotations: map[string]interface{}{RAMAnnotation: "LOW"}},
+				Memory: "8g",
+			},
+			expect: "The memory limit 8g is more than 2g of screwdriver.cd/ram annotation, so the build may run out of memory on Screwdriver.cd",
+		},
+		{
+			name: "more CPUs than annotation",
+			option: Option{
+				Job:  screwdriver.Job{Annotations: map[string]interface{}{CPUAnnotation: "MICRO"}},
+				CPUs: "2",
+			},
+			expect: "The number of CPUs 2 is more than 0.5 of screwdriver.cd/cpu annotation, so the build may be slower on Screwdriver.cd",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := bytes.NewBuffer(nil)
+			logrus.SetOutput(buf)
+
+			memoryLimit(tt.option)
+			cpuLimit(tt.option)
+			assert.Contains(t, buf.String(), tt.expect)
+		})
+	}
+
+	t.Run("less than annotation", func(t *testing.T) {
+		buf := bytes.NewBuffer(nil)
+		logrus.SetOutput(buf)
+
+		option := Option{
+			Job:    screwdriver.Job{Annotations: map[string]interface{}{RAMAnnotation: "HIGH", CPUAnnotation: "HIGH"}},
+			Memory: "4g",
+			CPUs:   "2",
+		}
+		assert.Equal(t, "4g", memoryLimit(option))
+		assert.Equal(t, "2", cpuLimit(option))
+		assert.NotContains(t, buf.String(), "more than")
+	})
+}
+
+func TestExceeds(t *testing.T) {
+	assert.True(t, exceeds("8g", "2g", parseMemory))
+	assert.False(t, exceeds("1024m", "1g", parseMemory))
+	assert.False(t, exceeds("8GB", "2g", parseMemory))
+	assert.True(t, exceeds("2", "0.5", parseCPUs))
+	assert.False(t, exceeds("0.5", "2", parseCPUs))
+}
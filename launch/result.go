@@ -0,0 +1,135 @@
+package launch
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// ResultFile is the file in the artifacts directory where the result of the build is written.
+const ResultFile = "result.json"
+
+// Result is the result of a build, which is written in JSON for the tools wrapping sd-local.
+type Result struct {
+	Job       string         `json:"job"`
+	Status    string         `json:"status"`
+	Error     string         `json:"error,omitempty"`
+	StartTime time.Time      `json:"startTime"`
+	Duration  float64        `json:"duration"`
+	Steps     []StepJSON     `json:"steps"`
+	Artifacts []ArtifactJSON `json:"artifacts"`
+	EnvDigest string         `json:"envDigest,omitempty"`
+}
+
+// StepJSON is the result of a step in Result. ExitCode is omitted for the skipped steps.
+type StepJSON struct {
+	Name     string  `json:"name"`
+	Status   string  `json:"status"`
+	ExitCode *int    `json:"exitCode,omitempty"`
+	Duration float64 `json:"duration"`
+}
+
+// ArtifactJSON is a file written into the artifacts directory in Result.
+type ArtifactJSON struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+// NewResult returns the result of the build with the results of its steps and the artifacts written by it.
+func NewResult(job string, start time.Time, duration time.Duration, err error, steps []StepResult, artifacts []Artifact) Result {
+	r := Result{
+		Job:       job,
+		Status:    StepSuccess,
+		StartTime: start,
+		Duration:  duration.Seconds(),
+		Steps:     make([]StepJSON, 0, len(steps)),
+		Artifacts: make([]ArtifactJSON, 0, len(artifacts)),
+	}
+
+	if err != nil {
+		r.Status = StepFailure
+		r.Error = err.Error()
+	}
+
+	for _, s := range steps {
+		step := StepJSON{Name: s.Name, Status: s.Status, Duration: s.Duration.Seconds()}
+		if s.Status != StepSkipped {
+			code := s.ExitCode
+			step.ExitCode = &code
+		}
+		r.Steps = append(r.Steps, step)
+	}
+
+	for _, a := range artifacts {
+		if a.Path == ResultFile {
+			continue
+		}
+		r.Artifacts = append(r.Artifacts, ArtifactJSON{Path: a.Path, Size: a.Size})
+	}
+
+	return r
+}
+
+// WriteResult writes the result of the build into the result file in the artifacts directory.
+func WriteResult(artifactsPath string, r Result) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to write result: %v", err)
+	}
+
+	err = ioutil.WriteFile(filepath.Join(artifactsPath, ResultFile), append(data, '\n'), os.FileMode(0666))
+	if err != nil {
+		return fmt.Errorf("failed to write result: %v", err)
+	}
+
+	return nil
+}
+
+// envDigest returns the digest of the environment variables, which changes when any of them changes.
+// SD_TOKEN is excluded because it is issued for each build, and only the names of the secrets are digested.
+func envDigest(env EnvVar, secrets EnvVar) string {
+	names := make([]string, 0, len(env))
+	for name := range env {
+		if name != "SD_TOKEN" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	h := sha256.New()
+	for _, name := range names {
+		if _, ok := secrets[name]; ok {
+			fmt.Fprintf(h, "%s\n", name)
+			continue
+		}
+		fmt.Fprintf(h, "%s=%s\n", name, env[name])
+	}
+
+	return fmt.Sprintf("sha256:%x", h.Sum(nil))
+}
+
+// EnvDigest returns the digest of the environment variables of the build.
+func (l *launch) EnvDigest() string {
+	return l.envDigest
+}
+
+// ReadResult reads the result of the build from the result file in the artifacts directory.
+func ReadResult(artifactsPath string) (Result, error) {
+	var r Result
+
+	data, err := ioutil.ReadFile(filepath.Join(artifactsPath, ResultFile))
+	if err != nil {
+		return r, fmt.Errorf("failed to read result: %v", err)
+	}
+
+	if err := json.Unmarshal(data, &r); err != nil {
+		return r, fmt.Errorf("failed to parse result: %v", err)
+	}
+
+	return r, nil
+}
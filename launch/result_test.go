@@ -0,0 +1,102 @@
+package launch
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewResult(t *testing.T) {
+	start := time.Date(2020, 1, 10, 0, 0, 0, 0, time.UTC)
+	steps := []StepResult{
+		{Name: "install", Status: StepSuccess, Duration: 12 * time.Second},
+		{Name: "test", Status: StepFailure, ExitCode: 2, Duration: 3 * time.Second},
+		{Name: "publish", Status: StepSkipped},
+	}
+	artifacts := []Artifact{
+		{Path: LogFile, Size: 2048},
+		{Path: ResultFile, Size: 100},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		r := NewResult("main", start, time.Minute, nil, steps, artifacts)
+
+		success, failure := 0, 2
+		assert.Equal(t, Result{
+			Job:       "main",
+			Status:    StepSuccess,
+			StartTime: start,
+			Duration:  60,
+			Steps: []StepJSON{
+				{Name: "install", Status: StepSuccess, ExitCode: &success, Duration: 12},
+				{Name: "test", Status: StepFailure, ExitCode: &failure, Duration: 3},
+				{Name: "publish", Status: StepSkipped},
+			},
+			Artifacts: []ArtifactJSON{{Path: LogFile, Size: 2048}},
+		}, r)
+	})
+
+	t.Run("success with error", func(t *testing.T) {
+		r := NewResult("main", start, time.Minute, errors.New("failed to run build"), nil, nil)
+
+		assert.Equal(t, StepFailure, r.Status)
+		assert.Equal(t, "failed to run build", r.Error)
+		assert.Equal(t, []StepJSON{}, r.Steps)
+		assert.Equal(t, []ArtifactJSON{}, r.Artifacts)
+	})
+}
+
+func TestWriteResult(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sd-artifacts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	code := 0
+	r := Result{
+		Job:       "main",
+		Status:    StepSuccess,
+		StartTime: time.Date(2020, 1, 10, 0, 0, 0, 0, time.UTC),
+		Duration:  1.5,
+		Steps:     []StepJSON{{Name: "test", Status: StepSuccess, ExitCode: &code, Duration: 1}},
+		Artifacts: []ArtifactJSON{{Path: LogFile, Size: 10}},
+		EnvDigest: "sha256:abc",
+	}
+
+	err = WriteResult(dir, r)
+	assert.Nil(t, err)
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, ResultFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(t, string(data), `"startTime": "2020-01-10T00:00:00Z"`)
+	assert.Contains(t, string(data), `"exitCode": 0`)
+
+	read, err := ReadResult(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, r, read)
+
+	_, err = ReadResult(filepath.Join(dir, "none"))
+	assert.NotNil(t, err)
+
+	err = WriteResult(filepath.Join(dir, "none"), r)
+	assert.NotNil(t, err)
+}
+
+func TestEnvDigest(t *testing.T) {
+	env := EnvVar{"SD_TOKEN": "jwt", "FOO": "foo", "SECRET": "secret"}
+	secrets := EnvVar{"SECRET": "secret"}
+	digest := envDigest(env, secrets)
+
+	assert.Regexp(t, "^sha256:[0-9a-f]{64}$", digest)
+	assert.Equal(t, digest, envDigest(EnvVar{"SD_TOKEN": "other", "FOO": "foo", "SECRET": "other"}, secrets))
+	assert.NotEqual(t, digest, envDigest(EnvVar{"SD_TOKEN": "jwt", "FOO": "bar", "SECRET": "secret"}, secrets))
+	assert.NotEqual(t, digest, envDigest(EnvVar{"SD_TOKEN": "jwt", "FOO": "foo"}, secrets))
+}
@@ -0,0 +1,70 @@
+package launch
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ReuseLabel is the label of the build containers kept with --reuse, whose value is the digest of their options.
+const ReuseLabel = "sd-local.reuse"
+
+// keepAliveCommand keeps the container running between the builds, and exits on the signal of stopping the container.
+var keepAliveCommand = []string{"/bin/sh", "-c", "trap 'exit 0' INT TERM; while :; do sleep 1; done"}
+
+// reuseContainerName returns the name of the kept build container, which is unique to the job and the source directory.
+func reuseContainerName(jobName, srcPath string) string {
+	sum := sha256.Sum256([]byte(srcPath))
+	return fmt.Sprintf("sd-local-reuse-%s-%x", invalidContainerNameChars.ReplaceAllString(jobName, "-"), sum[:6])
+}
+
+// reuseDigest returns the digest of the options of the kept container, which is recreated when they are changed.
+func reuseDigest(options []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(options, "\x00")))
+	return fmt.Sprintf("%x", sum[:8])
+}
+
+// runReused runs the build in the kept container, which is created when it doesn't exist or its options are changed.
+// The files of the previous build which change the results of the steps are removed before the build.
+func (d *docker) runReused(name string, options, launchCommands []string) error {
+	digest := reuseDigest(options)
+
+	commands := d.commandLine(d.runtime.containerInspect(name, fmt.Sprintf(`{{index .Config.Labels %q}} {{.State.Status}}`, ReuseLabel))...)
+	out, err := execCommand(commands[0], commands[1:]...).Output()
+	state := strings.Fields(string(out))
+
+	create := false
+	switch {
+	case err != nil || len(state) != 2:
+		logrus.Infof("Creating the build container %s, which is kept for the next builds", name)
+		create = true
+	case state[0] != digest:
+		logrus.Infof("Recreating the build container %s, because the options of the build are changed", name)
+		if _, err := d.execDockerCommand(d.runtime.containerRemove(name)...); err != nil {
+			return fmt.Errorf("failed to remove build container: %v", err)
+		}
+		create = true
+	case state[1] != "running":
+		logrus.Infof("Starting the kept build container %s", name)
+		if _, err := d.execDockerCommand(d.runtime.containerStart(name)...); err != nil {
+			return fmt.Errorf("failed to start build container: %v", err)
+		}
+	default:
+		logrus.Infof("Reusing the build container %s", name)
+	}
+
+	if create {
+		if _, err := d.execDockerCommand(d.runtime.run(append([]string{"--label", ReuseLabel + "=" + digest}, options...)...)...); err != nil {
+			return fmt.Errorf("failed to run build container: %v", err)
+		}
+	}
+
+	script := fmt.Sprintf(`rm -f %s %s; exec "$@"`, failedFile, stepPIDFile)
+	if _, err := d.execDockerCommand(d.runtime.exec(name, append([]string{"/bin/sh", "-c", script, "sd-local"}, launchCommands...)...)...); err != nil {
+		return fmt.Errorf("failed to run build in container %s: %v", name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package launch
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// reuseHelperProcess fakes the container runtime which has the kept container in the state of the test case.
+// The test case is followed by the label of the kept container, such as REUSE_RUNNING:<digest>.
+func reuseHelperProcess(testCase, subcmd string, args []string) {
+	mode, label := testCase, ""
+	if i := strings.Index(testCase, ":"); i >= 0 {
+		mode, label = testCase[:i], testCase[i+1:]
+	}
+	inspect := subcmd == "container" && args[0] == "inspect"
+
+	switch mode {
+	case "REUSE_NOT_FOUND":
+		if inspect {
+			os.Exit(1)
+		}
+	case "REUSE_RUNNING":
+		if inspect {
+			fmt.Printf("%s running\n", label)
+		}
+	case "REUSE_EXITED":
+		if inspect {
+			fmt.Printf("%s exited\n", label)
+		}
+	case "REUSE_EXEC_FAILS":
+		if inspect {
+			fmt.Printf("%s running\n", label)
+		}
+		if subcmd == "exec" {
+			os.Exit(1)
+		}
+	}
+	os.Exit(0)
+}
+
+func TestReuseContainerName(t *testing.T) {
+	name := reuseContainerName("pr:main", "/src")
+	assert.Regexp(t, `^sd-local-reuse-pr-main-[0-9a-f]{12}$`, name)
+	assert.Equal(t, name, reuseContainerName("pr:main", "/src"))
+	assert.NotEqual(t, name, reuseContainerName("pr:main", "/other"))
+}
+
+func TestRunReused(t *testing.T) {
+	defer func() {
+		execCommand = exec.Command
+	}()
+
+	const name = "sd-local-reuse-main-0123456789ab"
+	options := []string{"--name", name, "-d", "node:12", "/bin/sh", "-c", "sleep"}
+	digest := reuseDigest(options)
+	run := fmt.Sprintf("docker container run --label sd-local.reuse=%s --name %s -d node:12 /bin/sh -c sleep", digest, name)
+	execCmd := fmt.Sprintf(`docker exec %s /bin/sh -c rm -f /tmp/sd-local-failed /tmp/sd-local-step.pid; exec "$@" sd-local /opt/sd/local_run.sh {}`, name)
+	inspect := fmt.Sprintf("docker container inspect --format {{index .Config.Labels \"sd-local.reuse\"}} {{.State.Status}} %s", name)
+
+	testCases := []struct {
+		name      string
+		mode      string
+		expectCmd []string
+		expectErr string
+	}{
+		{"create", "REUSE_NOT_FOUND", []string{inspect, run, execCmd}, ""},
+		{"reuse", "REUSE_RUNNING:" + digest, []string{inspect, execCmd}, ""},
+		{"start", "REUSE_EXITED:" + digest, []string{inspect, "docker container start " + name, execCmd}, ""},
+		{"recreate", "REUSE_RUNNING:0000", []string{inspect, "docker container rm --force " + name, run, execCmd}, ""},
+		{"failure", "REUSE_EXEC_FAILS:" + digest, []string{inspect, execCmd}, "failed to run build in container " + name + ": exit status 1"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newFakeExecCommand(tt.mode)
+			execCommand = c.execCmd
+			d := &docker{runtime: &dockerRuntime{}, mutex: &sync.Mutex{}}
+
+			err := d.runReused(name, options, []string{"/opt/sd/local_run.sh", "{}"})
+
+			assert.Equal(t, tt.expectCmd, c.commands)
+			if tt.expectErr == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.Equal(t, tt.expectErr, err.Error())
+			}
+		})
+	}
+}
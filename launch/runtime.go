@@ -0,0 +1,371 @@
+package launch
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/screwdriver-cd/sd-local/config"
+)
+
+var (
+	osStat           = os.Stat
+	osGetuid         = os.Getuid
+	osGetgid         = os.Getgid
+	dockerSocketPath = "/var/run/docker.sock"
+	podmanSocketPath = "/run/podman/podman.sock"
+)
+
+// containerRuntime absorbs the differences of the command line interfaces between container runtimes.
+type containerRuntime interface {
+	name() string
+	volumeCreate(volume string) []string
+	volumeRemove(volume string) []string
+	pull(image string, options ...string) []string
+	imageInspect(image string) []string
+	imageSize(image string) []string
+	imageList(filters ...string) []string
+	imageRemove(image string) []string
+	save(path string, images ...string) []string
+	load(path string) []string
+	build(dockerfile, target, tag, context string, options ...string) []string
+	run(options ...string) []string
+	attach(container string) []string
+	networkCreate(network string) []string
+	networkRemove(network string) []string
+	containerState(container string) []string
+	containerRemove(containers ...string) []string
+	containerList(filters ...string) []string
+	containerInspect(container, format string) []string
+	containerSize(container string) []string
+	containerStart(container string) []string
+	exec(container string, args ...string) []string
+	commit(container, image string, changes ...string) []string
+	networkList() []string
+	volumeList(filters ...string) []string
+	composeUp(file, project string) []string
+	composeDown(file, project string) []string
+	gpus(gpus string) []string
+	user(user string) []string
+	version() []string
+}
+
+type dockerRuntime struct{}
+
+var _ containerRuntime = (*dockerRuntime)(nil)
+
+func (r *dockerRuntime) name() string { return "docker" }
+
+func (r *dockerRuntime) volumeCreate(volume string) []string {
+	return []string{"volume", "create", "--name", volume}
+}
+
+func (r *dockerRuntime) volumeRemove(volume string) []string {
+	return []string{"volume", "rm", "--force", volume}
+}
+
+func (r *dockerRuntime) pull(image string, options ...string) []string {
+	return append(append([]string{"pull"}, options...), image)
+}
+
+func (r *dockerRuntime) imageInspect(image string) []string {
+	return []string{"image", "inspect", image}
+}
+
+// imageSize returns the command to print the size in bytes of the image.
+func (r *dockerRuntime) imageSize(image string) []string {
+	return []string{"image", "inspect", "--format", "{{.Size}}", image}
+}
+
+// imageList returns the command to print the references of the tagged images which match the filters.
+func (r *dockerRuntime) imageList(filters ...string) []string {
+	commands := []string{"image", "ls"}
+	for _, f := range filters {
+		commands = append(commands, "--filter", f)
+	}
+	return append(commands, "--format", "{{.Repository}}:{{.Tag}}")
+}
+
+func (r *dockerRuntime) imageRemove(image string) []string {
+	return []string{"image", "rm", image}
+}
+
+func (r *dockerRuntime) save(path string, images ...string) []string {
+	return append([]string{"save", "-o", path}, images...)
+}
+
+func (r *dockerRuntime) load(path string) []string {
+	return []string{"load", "-i", path}
+}
+
+func (r *dockerRuntime) build(dockerfile, target, tag, context string, options ...string) []string {
+	commands := []string{"build", "-f", dockerfile, "-t", tag}
+	if target != "" {
+		commands = append(commands, "--target", target)
+	}
+	return append(append(commands, options...), context)
+}
+
+func (r *dockerRuntime) run(options ...string) []string {
+	return append([]string{"container", "run"}, options...)
+}
+
+func (r *dockerRuntime) attach(container string) []string {
+	return []string{"attach", container}
+}
+
+func (r *dockerRuntime) networkCreate(network string) []string {
+	return []string{"network", "create", network}
+}
+
+func (r *dockerRuntime) networkRemove(network string) []string {
+	return []string{"network", "rm", network}
+}
+
+// containerState returns the command to print the status of the container followed by its health, which is empty without the health check.
+func (r *dockerRuntime) containerState(container string) []string {
+	return []string{"container", "inspect", "--format", "{{.State.Status}} {{if .State.Health}}{{.State.Health.Status}}{{end}}", container}
+}
+
+func (r *dockerRuntime) containerRemove(containers ...string) []string {
+	return append([]string{"container", "rm", "--force"}, containers...)
+}
+
+// containerList returns the command to print the names of all the containers including the stopped ones, which match the filters.
+func (r *dockerRuntime) containerList(filters ...string) []string {
+	commands := []string{"container", "ls", "--all"}
+	for _, f := range filters {
+		commands = append(commands, "--filter", f)
+	}
+	return append(commands, "--format", "{{.Names}}")
+}
+
+func (r *dockerRuntime) containerInspect(container, format string) []string {
+	return []string{"container", "inspect", "--format", format, container}
+}
+
+// containerSize returns the command to print the size in bytes of the files written in the container.
+func (r *dockerRuntime) containerSize(container string) []string {
+	return []string{"container", "inspect", "--size", "--format", "{{.SizeRw}}", container}
+}
+
+func (r *dockerRuntime) containerStart(container string) []string {
+	return []string{"container", "start", container}
+}
+
+func (r *dockerRuntime) exec(container string, args ...string) []string {
+	return append([]string{"exec", container}, args...)
+}
+
+func (r *dockerRuntime) networkList() []string {
+	return []string{"network", "ls", "--format", "{{.Name}}"}
+}
+
+// volumeList returns the command to print the names of the volumes which match the filters.
+func (r *dockerRuntime) volumeList(filters ...string) []string {
+	commands := []string{"volume", "ls"}
+	for _, f := range filters {
+		commands = append(commands, "--filter", f)
+	}
+	return append(commands, "--format", "{{.Name}}")
+}
+
+// commit returns the command to create the image from the container, where the changes are the Dockerfile instructions such as LABEL.
+func (r *dockerRuntime) commit(container, image string, changes ...string) []string {
+	commands := []string{"container", "commit"}
+	for _, c := range changes {
+		commands = append(commands, "--change", c)
+	}
+	return append(commands, container, image)
+}
+
+// composeUp returns the command to start the services of the compose project, which waits for them to be healthy.
+func (r *dockerRuntime) composeUp(file, project string) []string {
+	return []string{"compose", "-f", file, "-p", project, "up", "-d", "--wait"}
+}
+
+func (r *dockerRuntime) composeDown(file, project string) []string {
+	return []string{"compose", "-f", file, "-p", project, "down", "--volumes", "--remove-orphans"}
+}
+
+// gpus returns the options to expose the GPUs to the container, which are all, the number of GPUs or device=<index>[,<index>...].
+func (r *dockerRuntime) gpus(gpus string) []string {
+	return []string{"--gpus", gpus}
+}
+
+// user returns the options to run the container as the user, where host is the user of the host.
+func (r *dockerRuntime) user(user string) []string {
+	if user == config.HostUser {
+		user = fmt.Sprintf("%d:%d", osGetuid(), osGetgid())
+	}
+	return []string{"--user", user}
+}
+
+// version prints the version of the daemon, so that it fails when the daemon is not running.
+func (r *dockerRuntime) version() []string {
+	return []string{"version", "--format", "{{.Server.Version}}"}
+}
+
+// podmanRuntime drives rootless podman, which is mostly compatible with docker.
+type podmanRuntime struct {
+	dockerRuntime
+}
+
+var _ containerRuntime = (*podmanRuntime)(nil)
+
+func (r *podmanRuntime) name() string { return "podman" }
+
+// podman runs without the daemon, so the version of podman is printed.
+func (r *podmanRuntime) version() []string {
+	return []string{"version", "--format", "{{.Client.Version}}"}
+}
+
+// podman does not support the `--name` option, so the volume name is passed as an argument.
+func (r *podmanRuntime) volumeCreate(volume string) []string {
+	return []string{"volume", "create", volume}
+}
+
+// podman saves only one image into the archive unless the multi-image archive is specified.
+func (r *podmanRuntime) save(path string, images ...string) []string {
+	return append([]string{"save", "-m", "-o", path}, images...)
+}
+
+// podman exposes the GPUs as the devices of the Container Device Interface generated by NVIDIA Container Toolkit.
+func (r *podmanRuntime) gpus(gpus string) []string {
+	var devices []string
+	switch {
+	case gpus == "all":
+		devices = []string{"all"}
+	case strings.HasPrefix(gpus, "device="):
+		devices = strings.Split(strings.TrimPrefix(gpus, "device="), ",")
+	default:
+		n, _ := strconv.Atoi(gpus)
+		for i := 0; i < n; i++ {
+			devices = append(devices, strconv.Itoa(i))
+		}
+	}
+
+	options := make([]string, 0, len(devices)*2)
+	for _, device := range devices {
+		options = append(options, "--device", "nvidia.com/gpu="+device)
+	}
+	return options
+}
+
+// The root of rootless podman is mapped to the user of the host, so the user of the host is kept in the user namespace instead.
+func (r *podmanRuntime) user(user string) []string {
+	if user == config.HostUser {
+		return []string{"--userns", "keep-id"}
+	}
+	return r.dockerRuntime.user(user)
+}
+
+// nerdctlRuntime drives containerd through nerdctl.
+type nerdctlRuntime struct {
+	dockerRuntime
+}
+
+var _ containerRuntime = (*nerdctlRuntime)(nil)
+
+func (r *nerdctlRuntime) name() string { return "nerdctl" }
+
+func (r *nerdctlRuntime) volumeCreate(volume string) []string {
+	return []string{"volume", "create", volume}
+}
+
+// nerdctl commit supports only CMD and ENTRYPOINT of the changes, so the other changes are dropped.
+func (r *nerdctlRuntime) commit(container, image string, changes ...string) []string {
+	var supported []string
+	for _, c := range changes {
+		if strings.HasPrefix(c, "CMD ") || strings.HasPrefix(c, "ENTRYPOINT ") {
+			supported = append(supported, c)
+		}
+	}
+	return r.dockerRuntime.commit(container, image, supported...)
+}
+
+// nerdctl does not print the version of containerd alone, so the version of nerdctl is printed.
+func (r *nerdctlRuntime) version() []string {
+	return []string{"version", "--format", "{{.Client.Version}}"}
+}
+
+// nerdctl compose does not support waiting for the services.
+func (r *nerdctlRuntime) composeUp(file, project string) []string {
+	return []string{"compose", "-f", file, "-p", project, "up", "-d"}
+}
+
+func newContainerRuntime(name string) containerRuntime {
+	switch name {
+	case "podman":
+		return &podmanRuntime{}
+	case "nerdctl":
+		return &nerdctlRuntime{}
+	default:
+		return &dockerRuntime{}
+	}
+}
+
+// detectRuntime chooses nerdctl when the docker socket is absent and nerdctl is installed.
+func detectRuntime() string {
+	if os.Getenv("DOCKER_HOST") != "" {
+		return DefaultRuntime
+	}
+
+	if _, err := osStat(dockerSocketPath); err == nil {
+		return DefaultRuntime
+	}
+
+	if _, err := lookPath("nerdctl"); err == nil {
+		return "nerdctl"
+	}
+
+	return DefaultRuntime
+}
+
+// hostDockerSocket returns the socket of Docker API which the container runtime serves on the host.
+func hostDockerSocket(runtime string) (string, error) {
+	switch runtime {
+	case "docker":
+		host := os.Getenv("DOCKER_HOST")
+		if host == "" {
+			return dockerSocketPath, nil
+		}
+		if !strings.HasPrefix(host, "unix://") {
+			return "", fmt.Errorf("docker socket can not be mounted, because DOCKER_HOST %s is not a unix socket", host)
+		}
+		return strings.TrimPrefix(host, "unix://"), nil
+	case "podman":
+		// rootless podman serves the socket in the runtime directory of the user
+		if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+			socketPath := filepath.Join(dir, "podman", "podman.sock")
+			if _, err := osStat(socketPath); err == nil {
+				return socketPath, nil
+			}
+		}
+		return podmanSocketPath, nil
+	default:
+		return "", fmt.Errorf("docker socket can not be mounted with %s, which does not serve Docker API", runtime)
+	}
+}
+
+// DockerSocketVolume returns the volume which mounts the docker socket of the host into the build container,
+// so that the build can build and run images with the container runtime of the host.
+// The runtime is detected when it is empty.
+func DockerSocketVolume(runtime string) (string, error) {
+	if runtime == "" {
+		runtime = detectRuntime()
+	}
+
+	socketPath, err := hostDockerSocket(runtime)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := osStat(socketPath); err != nil {
+		return "", fmt.Errorf("docker socket is not available: %v", err)
+	}
+
+	return fmt.Sprintf("%s:%s", socketPath, dockerSocketPath), nil
+}
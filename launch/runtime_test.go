@@ -0,0 +1,254 @@
+package launch
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewContainerRuntime(t *testing.T) {
+	testCases := []struct {
+		name         string
+		expect       containerRuntime
+		expectCreate []string
+	}{
+		{"docker", &dockerRuntime{}, []string{"volume", "create", "--name", "vol"}},
+		{"podman", &podmanRuntime{}, []string{"volume", "create", "vol"}},
+		{"nerdctl", &nerdctlRuntime{}, []string{"volume", "create", "vol"}},
+		{"", &dockerRuntime{}, []string{"volume", "create", "--name", "vol"}},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newContainerRuntime(tt.name)
+			assert.Equal(t, tt.expect, r)
+			assert.Equal(t, tt.expectCreate, r.volumeCreate("vol"))
+			assert.Equal(t, []string{"volume", "rm", "--force", "vol"}, r.volumeRemove("vol"))
+			assert.Equal(t, []string{"container", "run", "--rm", "img"}, r.run("--rm", "img"))
+			assert.Equal(t, []string{"pull", "img"}, r.pull("img"))
+			assert.Equal(t, []string{"image", "inspect", "img"}, r.imageInspect("img"))
+			assert.Equal(t, []string{"load", "-i", "images.tar"}, r.load("images.tar"))
+			assert.Equal(t, []string{"pull", "--platform", "linux/arm64", "img"}, r.pull("img", "--platform", "linux/arm64"))
+			assert.Equal(t, []string{"container", "ls", "--all", "--format", "{{.Names}}"}, r.containerList())
+			assert.Equal(t, []string{"container", "ls", "--all", "--filter", "label=sd-local.reuse", "--format", "{{.Names}}"}, r.containerList("label=sd-local.reuse"))
+			assert.Equal(t, []string{"container", "start", "c"}, r.containerStart("c"))
+			assert.Equal(t, []string{"exec", "c", "ls"}, r.exec("c", "ls"))
+			assert.Equal(t, []string{"network", "ls", "--format", "{{.Name}}"}, r.networkList())
+			assert.Equal(t, []string{"volume", "ls", "--format", "{{.Name}}"}, r.volumeList())
+		})
+	}
+}
+
+func TestDetectRuntime(t *testing.T) {
+	defer func() {
+		osStat = os.Stat
+		lookPath = exec.LookPath
+	}()
+
+	testCases := []struct {
+		name       string
+		dockerHost string
+		socket     bool
+		nerdctl    bool
+		expect     string
+	}{
+		{"docker socket exists", "", true, true, "docker"},
+		{"DOCKER_HOST is set", "tcp://127.0.0.1:2375", false, true, "docker"},
+		{"only nerdctl exists", "", false, true, "nerdctl"},
+		{"nothing found", "", false, false, "docker"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			defer os.Setenv("DOCKER_HOST", os.Getenv("DOCKER_HOST"))
+			os.Setenv("DOCKER_HOST", tt.dockerHost)
+
+			osStat = func(name string) (os.FileInfo, error) {
+				if tt.socket {
+					return nil, nil
+				}
+				return nil, fmt.Errorf("stat %s: no such file or directory", name)
+			}
+			lookPath = func(file string) (string, error) {
+				if tt.nerdctl {
+					return "/usr/local/bin/nerdctl", nil
+				}
+				return "", fmt.Errorf("exec: %q: executable file not found in $PATH", file)
+			}
+
+			assert.Equal(t, tt.expect, detectRuntime())
+		})
+	}
+}
+
+func TestDockerSocketVolume(t *testing.T) {
+	defer func() {
+		osStat = os.Stat
+	}()
+
+	testCases := []struct {
+		name          string
+		runtime       string
+		dockerHost    string
+		xdgRuntimeDir string
+		exists        []string
+		expect        string
+		expectErr     string
+	}{
+		{
+			name:    "docker",
+			runtime: "docker",
+			exists:  []string{"/var/run/docker.sock"},
+			expect:  "/var/run/docker.sock:/var/run/docker.sock",
+		},
+		{
+			name:       "docker with DOCKER_HOST",
+			runtime:    "docker",
+			dockerHost: "unix:///home/user/.docker/run/docker.sock",
+			exists:     []string{"/home/user/.docker/run/docker.sock"},
+			expect:     "/home/user/.docker/run/docker.sock:/var/run/docker.sock",
+		},
+		{
+			name:       "docker with remote DOCKER_HOST",
+			runtime:    "docker",
+			dockerHost: "tcp://127.0.0.1:2375",
+			expectErr:  "docker socket can not be mounted, because DOCKER_HOST tcp://127.0.0.1:2375 is not a unix socket",
+		},
+		{
+			name:      "docker socket does not exist",
+			runtime:   "docker",
+			expectErr: "docker socket is not available: stat /var/run/docker.sock: no such file or directory",
+		},
+		{
+			name:          "rootless podman",
+			runtime:       "podman",
+			xdgRuntimeDir: "/run/user/1000",
+			exists:        []string{"/run/user/1000/podman/podman.sock", "/run/podman/podman.sock"},
+			expect:        "/run/user/1000/podman/podman.sock:/var/run/docker.sock",
+		},
+		{
+			name:          "rootful podman",
+			runtime:       "podman",
+			xdgRuntimeDir: "/run/user/1000",
+			exists:        []string{"/run/podman/podman.sock"},
+			expect:        "/run/podman/podman.sock:/var/run/docker.sock",
+		},
+		{
+			name:      "nerdctl",
+			runtime:   "nerdctl",
+			expectErr: "docker socket can not be mounted with nerdctl, which does not serve Docker API",
+		},
+		{
+			name:   "detected runtime",
+			exists: []string{"/var/run/docker.sock"},
+			expect: "/var/run/docker.sock:/var/run/docker.sock",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			defer os.Setenv("DOCKER_HOST", os.Getenv("DOCKER_HOST"))
+			defer os.Setenv("XDG_RUNTIME_DIR", os.Getenv("XDG_RUNTIME_DIR"))
+			os.Setenv("DOCKER_HOST", tt.dockerHost)
+			os.Setenv("XDG_RUNTIME_DIR", tt.xdgRuntimeDir)
+
+			osStat = func(name string) (os.FileInfo, error) {
+				for _, e := range tt.exists {
+					if e == name {
+						return nil, nil
+					}
+				}
+				return nil, fmt.Errorf("stat %s: no such file or directory", name)
+			}
+
+			volume, err := DockerSocketVolume(tt.runtime)
+			if tt.expectErr != "" {
+				assert.EqualError(t, err, tt.expectErr)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expect, volume)
+		})
+	}
+}
+
+func TestSave(t *testing.T) {
+	assert.Equal(t, []string{"save", "-o", "images.tar", "a", "b"}, newContainerRuntime("docker").save("images.tar", "a", "b"))
+	assert.Equal(t, []string{"save", "-m", "-o", "images.tar", "a", "b"}, newContainerRuntime("podman").save("images.tar", "a", "b"))
+	assert.Equal(t, []string{"save", "-o", "images.tar", "a", "b"}, newContainerRuntime("nerdctl").save("images.tar", "a", "b"))
+}
+
+func TestBuild(t *testing.T) {
+	for _, name := range []string{"docker", "podman", "nerdctl"} {
+		r := newContainerRuntime(name)
+		assert.Equal(t, []string{"build", "-f", "/repo/Dockerfile", "-t", "sd-local/build-image:0", "/repo"}, r.build("/repo/Dockerfile", "", "sd-local/build-image:0", "/repo"))
+		assert.Equal(t, []string{"build", "-f", "/repo/Dockerfile", "-t", "sd-local/build-image:0", "--target", "ci", "--platform", "linux/amd64", "/repo"}, r.build("/repo/Dockerfile", "ci", "sd-local/build-image:0", "/repo", "--platform", "linux/amd64"))
+	}
+}
+
+func TestServiceCommands(t *testing.T) {
+	for _, name := range []string{"docker", "podman", "nerdctl"} {
+		r := newContainerRuntime(name)
+		assert.Equal(t, []string{"network", "create", "sd-local-main-1"}, r.networkCreate("sd-local-main-1"))
+		assert.Equal(t, []string{"network", "rm", "sd-local-main-1"}, r.networkRemove("sd-local-main-1"))
+		assert.Equal(t, []string{"container", "rm", "--force", "a", "b"}, r.containerRemove("a", "b"))
+		assert.Equal(t, "sd-local-main-1-postgres", r.containerState("sd-local-main-1-postgres")[4])
+	}
+}
+
+func TestCompose(t *testing.T) {
+	assert.Equal(t, []string{"compose", "-f", "/repo/docker-compose.yml", "-p", "sd-local-main-1", "up", "-d", "--wait"}, newContainerRuntime("docker").composeUp("/repo/docker-compose.yml", "sd-local-main-1"))
+	assert.Equal(t, []string{"compose", "-f", "/repo/docker-compose.yml", "-p", "sd-local-main-1", "up", "-d", "--wait"}, newContainerRuntime("podman").composeUp("/repo/docker-compose.yml", "sd-local-main-1"))
+	assert.Equal(t, []string{"compose", "-f", "/repo/docker-compose.yml", "-p", "sd-local-main-1", "up", "-d"}, newContainerRuntime("nerdctl").composeUp("/repo/docker-compose.yml", "sd-local-main-1"))
+	assert.Equal(t, []string{"compose", "-f", "/repo/docker-compose.yml", "-p", "sd-local-main-1", "down", "--volumes", "--remove-orphans"}, newContainerRuntime("nerdctl").composeDown("/repo/docker-compose.yml", "sd-local-main-1"))
+}
+
+func TestGPUs(t *testing.T) {
+	testCases := []struct {
+		runtime  string
+		gpus     string
+		expected []string
+	}{
+		{"docker", "all", []string{"--gpus", "all"}},
+		{"nerdctl", "device=0,1", []string{"--gpus", "device=0,1"}},
+		{"podman", "all", []string{"--device", "nvidia.com/gpu=all"}},
+		{"podman", "2", []string{"--device", "nvidia.com/gpu=0", "--device", "nvidia.com/gpu=1"}},
+		{"podman", "device=1,3", []string{"--device", "nvidia.com/gpu=1", "--device", "nvidia.com/gpu=3"}},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.runtime+" "+tt.gpus, func(t *testing.T) {
+			assert.Equal(t, tt.expected, newContainerRuntime(tt.runtime).gpus(tt.gpus))
+		})
+	}
+}
+
+func TestUser(t *testing.T) {
+	defer func() {
+		osGetuid = os.Getuid
+		osGetgid = os.Getgid
+	}()
+	osGetuid = func() int { return 1000 }
+	osGetgid = func() int { return 100 }
+
+	testCases := []struct {
+		runtime  string
+		user     string
+		expected []string
+	}{
+		{"docker", "host", []string{"--user", "1000:100"}},
+		{"docker", "node", []string{"--user", "node"}},
+		{"nerdctl", "host", []string{"--user", "1000:100"}},
+		{"podman", "host", []string{"--userns", "keep-id"}},
+		{"podman", "1001:1001", []string{"--user", "1001:1001"}},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.runtime+" "+tt.user, func(t *testing.T) {
+			assert.Equal(t, tt.expected, newContainerRuntime(tt.runtime).user(tt.user))
+		})
+	}
+}
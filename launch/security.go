@@ -0,0 +1,64 @@
+package launch
+
+import "strings"
+
+// DefaultDropCapabilities are the capabilities dropped from the default set of the container runtime unless the build is privileged.
+// The builds rarely need them, and they can be added back with --cap-add.
+var DefaultDropCapabilities = []string{"AUDIT_WRITE", "MKNOD", "NET_RAW"}
+
+// NormalizeCapability returns the name of the capability in the form of the container runtime, such as NET_ADMIN for cap_net_admin.
+func NormalizeCapability(capability string) string {
+	return strings.TrimPrefix(strings.ToUpper(capability), "CAP_")
+}
+
+// IsDefaultDropCapability reports whether the capability is dropped by default, which can be added back without the privileges.
+func IsDefaultDropCapability(capability string) bool {
+	return contains(DefaultDropCapabilities, NormalizeCapability(capability))
+}
+
+// applySecurity sets the capabilities and the security options of the build.
+// The default capabilities are dropped except the ones added explicitly, so that the ordinary builds run with the reduced set.
+func applySecurity(b *buildEntry, option Option) {
+	for _, c := range option.CapAdd {
+		b.CapAdd = append(b.CapAdd, NormalizeCapability(c))
+	}
+
+	if !option.UsePrivileged {
+		for _, c := range DefaultDropCapabilities {
+			if !contains(b.CapAdd, c) && !contains(b.CapAdd, "ALL") {
+				b.CapDrop = append(b.CapDrop, c)
+			}
+		}
+	}
+	for _, c := range option.CapDrop {
+		if c := NormalizeCapability(c); !contains(b.CapDrop, c) {
+			b.CapDrop = append(b.CapDrop, c)
+		}
+	}
+
+	b.SecurityOpt = option.SecurityOpt
+}
+
+// securityOptions returns the options of the capabilities and the security options of the build.
+func securityOptions(b buildEntry) []string {
+	var options []string
+	for _, c := range b.CapDrop {
+		options = append(options, "--cap-drop", c)
+	}
+	for _, c := range b.CapAdd {
+		options = append(options, "--cap-add", c)
+	}
+	for _, o := range b.SecurityOpt {
+		options = append(options, "--security-opt", o)
+	}
+	return options
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package launch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplySecurity(t *testing.T) {
+	testCases := []struct {
+		name     string
+		option   Option
+		expected buildEntry
+	}{
+		{"default", Option{}, buildEntry{CapDrop: []string{"AUDIT_WRITE", "MKNOD", "NET_RAW"}}},
+		{"privileged", Option{UsePrivileged: true}, buildEntry{}},
+		{"add back", Option{CapAdd: []string{"net_raw", "CAP_SYS_PTRACE"}}, buildEntry{
+			CapAdd:  []string{"NET_RAW", "SYS_PTRACE"},
+			CapDrop: []string{"AUDIT_WRITE", "MKNOD"},
+		}},
+		{"add all", Option{CapAdd: []string{"ALL"}}, buildEntry{CapAdd: []string{"ALL"}}},
+		{"drop", Option{CapDrop: []string{"mknod", "NET_BIND_SERVICE"}, SecurityOpt: []string{"no-new-privileges"}}, buildEntry{
+			CapDrop:     []string{"AUDIT_WRITE", "MKNOD", "NET_RAW", "NET_BIND_SERVICE"},
+			SecurityOpt: []string{"no-new-privileges"},
+		}},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			b := buildEntry{}
+			applySecurity(&b, tt.option)
+			assert.Equal(t, tt.expected, b)
+		})
+	}
+}
+
+func TestSecurityOptions(t *testing.T) {
+	assert.Nil(t, securityOptions(buildEntry{}))
+	assert.Equal(t, []string{"--cap-drop", "MKNOD", "--cap-add", "NET_ADMIN", "--security-opt", "seccomp=unconfined"}, securityOptions(buildEntry{
+		CapAdd:      []string{"NET_ADMIN"},
+		CapDrop:     []string{"MKNOD"},
+		SecurityOpt: []string{"seccomp=unconfined"},
+	}))
+}
+
+func TestIsDefaultDropCapability(t *testing.T) {
+	assert.True(t, IsDefaultDropCapability("cap_net_raw"))
+	assert.True(t, IsDefaultDropCapability("MKNOD"))
+	assert.False(t, IsDefaultDropCapability("NET_ADMIN"))
+}
@@ -0,0 +1,56 @@
+package launch
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/screwdriver-cd/sd-local/config"
+	"github.com/sirupsen/logrus"
+)
+
+var selinuxEnforcePath = "/sys/fs/selinux/enforce"
+
+// selinuxEnforcing reports whether SELinux is enforcing on the host, such as Fedora and RHEL.
+func selinuxEnforcing() bool {
+	b, err := ioutil.ReadFile(selinuxEnforcePath)
+	return err == nil && strings.TrimSpace(string(b)) == "1"
+}
+
+// selinuxLabel returns the label of the bind mounts, which is z to share them with the other containers, Z to make them private, or empty.
+// The label is applied automatically when SELinux is enforcing, because the build can not read the bind mounts without it.
+func selinuxLabel(label string) string {
+	switch label {
+	case config.SELinuxLabelOff:
+		if selinuxEnforcing() {
+			logrus.Warn("SELinux is enforcing but the volumes are not relabeled because selinux-label is off, so the build may fail with permission denied on them")
+		}
+		return ""
+	case config.SELinuxLabelShared, config.SELinuxLabelPrivate:
+		return label
+	default:
+		if selinuxEnforcing() {
+			return config.SELinuxLabelShared
+		}
+		return ""
+	}
+}
+
+// labelVolume returns the volume whose host path is relabeled with the label of SELinux.
+// The named volumes are labeled by the container runtime, and the sockets such as the docker socket are never relabeled,
+// because relabeling them breaks the other processes on the host.
+func labelVolume(volume, label string) string {
+	if label == "" || !strings.HasPrefix(volume, "/") {
+		return volume
+	}
+
+	parts := strings.Split(volume, ":")
+	if info, err := osStat(parts[0]); err == nil && info.Mode()&os.ModeSocket != 0 {
+		return volume
+	}
+
+	if len(parts) == 3 {
+		return volume + "," + label
+	}
+	return volume + ":" + label
+}
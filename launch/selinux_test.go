@@ -0,0 +1,63 @@
+package launch
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSELinuxLabel(t *testing.T) {
+	defer func() {
+		selinuxEnforcePath = "/sys/fs/selinux/enforce"
+	}()
+
+	dir, err := ioutil.TempDir("", "selinux")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	enforcing := filepath.Join(dir, "enforcing")
+	assert.Nil(t, ioutil.WriteFile(enforcing, []byte("1\n"), 0644))
+	permissive := filepath.Join(dir, "permissive")
+	assert.Nil(t, ioutil.WriteFile(permissive, []byte("0\n"), 0644))
+
+	testCases := []struct {
+		name     string
+		enforce  string
+		label    string
+		expected string
+	}{
+		{"enforcing", enforcing, "", "z"},
+		{"permissive", permissive, "", ""},
+		{"disabled", filepath.Join(dir, "missing"), "", ""},
+		{"private", permissive, "Z", "Z"},
+		{"off", enforcing, "off", ""},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			selinuxEnforcePath = tt.enforce
+			assert.Equal(t, tt.expected, selinuxLabel(tt.label))
+		})
+	}
+}
+
+func TestLabelVolume(t *testing.T) {
+	dir, err := ioutil.TempDir("", "selinux")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	socketPath := filepath.Join(dir, "docker.sock")
+	l, err := net.Listen("unix", socketPath)
+	assert.Nil(t, err)
+	defer l.Close()
+
+	assert.Equal(t, "/repo/:/sd/workspace", labelVolume("/repo/:/sd/workspace", ""))
+	assert.Equal(t, "/repo/:/sd/workspace:z", labelVolume("/repo/:/sd/workspace", "z"))
+	assert.Equal(t, "/data:/data:ro,Z", labelVolume("/data:/data:ro", "Z"))
+	assert.Equal(t, "gocache:/root/.cache/go-build", labelVolume("gocache:/root/.cache/go-build", "z"))
+	assert.Equal(t, socketPath+":/var/run/docker.sock", labelVolume(socketPath+":/var/run/docker.sock", "z"))
+}
@@ -0,0 +1,116 @@
+package launch
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/screwdriver-cd/sd-local/config"
+	"github.com/sirupsen/logrus"
+)
+
+// serviceTimeout is the time to wait for the services to be ready, and serviceInterval is the interval to check them
+var (
+	serviceTimeout  = 2 * time.Minute
+	serviceInterval = time.Second
+)
+
+// serviceOptions returns the options to run the service on the network, where it is reachable with its name.
+func serviceOptions(container, network string, service config.Service) []string {
+	options := []string{"-d", "--name", container, "--network", network, "--network-alias", service.Name}
+
+	names := make([]string, 0, len(service.Env))
+	for name := range service.Env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		options = append(options, "-e", fmt.Sprintf("%s=%s", name, service.Env[name]))
+	}
+
+	if service.HealthCheck != "" {
+		options = append(options, "--health-cmd", service.HealthCheck, "--health-interval", "1s")
+	}
+
+	return append(options, service.Image)
+}
+
+// startServices runs the services on the network, and returns the network after they are ready.
+// The network of the build is created when the network is empty.
+// The services and the created network are removed with stopServices even if they fail to start.
+func (d *docker) startServices(jobName string, services []config.Service, network string) (string, error) {
+	if network == "" {
+		network = pausedContainerName(jobName)
+		if _, err := d.execDockerCommand(d.runtime.networkCreate(network)...); err != nil {
+			return "", fmt.Errorf("failed to create network %s: %v", network, err)
+		}
+		d.networks = append(d.networks, network)
+	}
+
+	containers := make([]string, 0, len(services))
+	for _, service := range services {
+		if err := d.pullImage(service.Image); err != nil {
+			return "", fmt.Errorf("failed to pull image of service %s: %v", service.Name, err)
+		}
+
+		logrus.Infof("Starting service %s with %s...", service.Name, service.Image)
+		container := fmt.Sprintf("%s-%s", pausedContainerName(jobName), service.Name)
+		options := append(d.platformOptions(), serviceOptions(container, network, service)...)
+		if _, err := d.execDockerCommand(d.runtime.run(options...)...); err != nil {
+			return "", fmt.Errorf("failed to start service %s: %v", service.Name, err)
+		}
+		d.services = append(d.services, container)
+		containers = append(containers, container)
+	}
+
+	for i, service := range services {
+		if err := d.waitForService(containers[i], service.Name); err != nil {
+			return "", err
+		}
+	}
+
+	return network, nil
+}
+
+// waitForService waits until the service is healthy, or is running if it has no health check.
+func (d *docker) waitForService(container, name string) error {
+	deadline := time.Now().Add(serviceTimeout)
+	for {
+		out, err := d.execDockerCommand(d.runtime.containerState(container)...)
+		if err != nil {
+			return fmt.Errorf("failed to inspect service %s: %v", name, err)
+		}
+
+		// The state is the status of the container followed by its health
+		state := strings.Fields(out)
+		if len(state) == 0 || state[0] != "running" {
+			return fmt.Errorf("service %s is not running: %s", name, strings.TrimSpace(out))
+		}
+		if len(state) == 1 || state[1] == "healthy" {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("service %s is not healthy in %v: %s", name, serviceTimeout, state[1])
+		}
+		time.Sleep(serviceInterval)
+	}
+}
+
+// stopServices removes the services and the networks of the builds.
+func (d *docker) stopServices() {
+	if len(d.services) != 0 {
+		if _, err := d.execDockerCommand(d.runtime.containerRemove(d.services...)...); err != nil {
+			logrus.Warn(fmt.Errorf("failed to remove services: %v", err))
+		}
+		d.services = nil
+	}
+
+	for _, network := range d.networks {
+		if _, err := d.execDockerCommand(d.runtime.networkRemove(network)...); err != nil {
+			logrus.Warn(fmt.Errorf("failed to remove network: %v", err))
+		}
+	}
+	d.networks = nil
+}
@@ -0,0 +1,124 @@
+package launch
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/screwdriver-cd/sd-local/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// serviceHelperProcess fakes the container runtime which runs the services in the states of the test case.
+func serviceHelperProcess(testCase, subcmd string, args []string) {
+	inspect := subcmd == "container" && args[0] == "inspect"
+	run := subcmd == "container" && args[0] == "run"
+
+	switch testCase {
+	case "SERVICE_HEALTHY":
+		if inspect {
+			fmt.Print("running healthy")
+		}
+	case "SERVICE_NO_HEALTHCHECK":
+		if inspect {
+			fmt.Print("running ")
+		}
+	case "SERVICE_STARTING":
+		if inspect {
+			fmt.Print("running starting")
+		}
+	case "SERVICE_EXITED":
+		if inspect {
+			fmt.Print("exited ")
+		}
+	case "SERVICE_RUN_FAILS":
+		if run {
+			os.Exit(1)
+		}
+	}
+	os.Exit(0)
+}
+
+func TestServiceOptions(t *testing.T) {
+	service := config.Service{
+		Name:        "postgres",
+		Image:       "postgres:13",
+		Env:         map[string]string{"POSTGRES_USER": "sd", "POSTGRES_PASSWORD": "password"},
+		HealthCheck: "pg_isready -U sd",
+	}
+
+	expected := []string{
+		"-d", "--name", "sd-local-main-1-postgres", "--network", "sd-local-main-1", "--network-alias", "postgres",
+		"-e", "POSTGRES_PASSWORD=password", "-e", "POSTGRES_USER=sd",
+		"--health-cmd", "pg_isready -U sd", "--health-interval", "1s",
+		"postgres:13",
+	}
+	assert.Equal(t, expected, serviceOptions("sd-local-main-1-postgres", "sd-local-main-1", service))
+}
+
+func TestStartServices(t *testing.T) {
+	defer func(timeout, interval time.Duration) {
+		execCommand = exec.Command
+		serviceTimeout, serviceInterval = timeout, interval
+	}(serviceTimeout, serviceInterval)
+	serviceTimeout, serviceInterval = 0, time.Millisecond
+
+	network := pausedContainerName("main")
+	services := []config.Service{
+		{Name: "postgres", Image: "postgres:13", HealthCheck: "pg_isready"},
+		{Name: "queue", Image: "redis:7"},
+	}
+	state := fmt.Sprintf("docker container inspect --format {{.State.Status}} {{if .State.Health}}{{.State.Health.Status}}{{end}} %s-postgres", network)
+	started := []string{
+		fmt.Sprintf("docker network create %s", network),
+		"docker pull postgres:13",
+		fmt.Sprintf("docker container run -d --name %s-postgres --network %s --network-alias postgres --health-cmd pg_isready --health-interval 1s postgres:13", network, network),
+		"docker pull redis:7",
+		fmt.Sprintf("docker container run -d --name %s-queue --network %s --network-alias queue redis:7", network, network),
+		state,
+	}
+
+	testCases := []struct {
+		name             string
+		id               string
+		expectError      string
+		expectedCommands []string
+		started          bool
+	}{
+		{"healthy", "SERVICE_HEALTHY", "", append(started, fmt.Sprintf("docker container inspect --format {{.State.Status}} {{if .State.Health}}{{.State.Health.Status}}{{end}} %s-queue", network)), true},
+		{"running without health check", "SERVICE_NO_HEALTHCHECK", "", append(started, fmt.Sprintf("docker container inspect --format {{.State.Status}} {{if .State.Health}}{{.State.Health.Status}}{{end}} %s-queue", network)), true},
+		{"not healthy", "SERVICE_STARTING", "service postgres is not healthy in 0s: starting", started, true},
+		{"exited", "SERVICE_EXITED", "service postgres is not running: exited", started, true},
+		{"failure to run", "SERVICE_RUN_FAILS", "failed to start service postgres: exit status 1", started[:3], false},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &docker{runtime: &dockerRuntime{}, mutex: &sync.Mutex{}}
+			c := newFakeExecCommand(tt.id)
+			execCommand = c.execCmd
+
+			n, err := d.startServices("main", services, "")
+			if tt.expectError != "" {
+				assert.EqualError(t, err, tt.expectError)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, network, n)
+			}
+			assert.Equal(t, tt.expectedCommands, c.commands)
+
+			c.commands = nil
+			d.stopServices()
+			removed := []string{fmt.Sprintf("docker network rm %s", network)}
+			if tt.started {
+				removed = append([]string{fmt.Sprintf("docker container rm --force %s-postgres %s-queue", network, network)}, removed...)
+			}
+			assert.Equal(t, removed, c.commands)
+			assert.Nil(t, d.services)
+			assert.Nil(t, d.networks)
+		})
+	}
+}
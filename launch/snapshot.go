@@ -0,0 +1,159 @@
+package launch
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/screwdriver-cd/sd-local/screwdriver"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// SnapshotLabel is the label of the snapshots of the build containers, whose value is the name of the job.
+	SnapshotLabel = "sd-local.snapshot"
+	// snapshotRepository is the repository of the snapshots, which are tagged with their keys
+	snapshotRepository = "sd-local/snapshot"
+	// snapshotStep is the step which waits for sd-local to commit the build container after the setup steps
+	snapshotStep = "sd-local-snapshot"
+	// snapshotRequestFile is written into the artifacts directory by the snapshot step, and removed after the commit
+	snapshotRequestFile = ".sd-local-snapshot"
+)
+
+var (
+	// snapshotLockfiles are the files in the source directory which invalidate the snapshots when they change,
+	// because the dependencies installed by the setup steps are changed with them.
+	snapshotLockfiles = []string{
+		"package-lock.json", "yarn.lock", "pnpm-lock.yaml",
+		"go.sum",
+		"pom.xml", "build.gradle", "build.gradle.kts", "gradle.lockfile",
+		"requirements.txt", "Pipfile.lock", "poetry.lock",
+		"Gemfile.lock", "Cargo.lock", "composer.lock",
+	}
+	// snapshotTimeout is the time for the snapshot step to wait for the commit, after which the build goes on without it
+	snapshotTimeout = 10 * time.Minute
+	// snapshotInterval is the interval to check the request of the snapshot step
+	snapshotInterval = 1 * time.Second
+)
+
+// SnapshotOption is the option to start the builds from the snapshot of the build container taken after the setup steps.
+type SnapshotOption struct {
+	// After is the name of the last setup step, after which the build container is committed
+	After string
+	// Files invalidate the snapshot when they change in addition to the lockfiles in the source directory, such as screwdriver.yaml
+	Files []string
+}
+
+// snapshotImage returns the snapshot of the build, whose tag is the digest of the image, the setup steps and the contents of the files.
+// The missing files are ignored, so that the snapshot is invalidated when they are created.
+func snapshotImage(image, jobName string, setupSteps []screwdriver.Step, files []string) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00", image, jobName)
+	for _, s := range setupSteps {
+		fmt.Fprintf(h, "%s\x00%s\x00", s.Name, s.Command)
+	}
+	for _, f := range files {
+		data, err := ioutil.ReadFile(f)
+		if err != nil {
+			continue
+		}
+		fmt.Fprintf(h, "%s\x00", filepath.Base(f))
+		h.Write(data)
+	}
+
+	return fmt.Sprintf("%s:%x", snapshotRepository, h.Sum(nil)[:8])
+}
+
+// applySnapshot sets the snapshot of the build taken after the setup steps.
+// The snapshot is not used when the job doesn't have the step, such as when it is skipped.
+func applySnapshot(b *buildEntry, option Option) {
+	after := option.Snapshot.After
+	if after == "" {
+		return
+	}
+
+	for i, s := range option.Job.Steps {
+		if s.Name != after {
+			continue
+		}
+
+		files := append([]string{}, option.Snapshot.Files...)
+		for _, f := range snapshotLockfiles {
+			files = append(files, filepath.Join(option.SrcPath, f))
+		}
+
+		b.SnapshotAfter = after
+		b.SnapshotImage = snapshotImage(b.Image, option.JobName, option.Job.Steps[:i+1], files)
+		return
+	}
+
+	logrus.Warnf("The snapshot is not used for %s, because the job doesn't have step %s", option.JobName, after)
+}
+
+// skipSetupSteps returns the steps without the user steps until the step named after, which are done in the snapshot.
+// The steps of Screwdriver.cd, such as checking out the source, are run even in the snapshot.
+func skipSetupSteps(steps []screwdriver.Step, after string) []screwdriver.Step {
+	for i, s := range steps {
+		if s.Name != after {
+			continue
+		}
+
+		kept := make([]screwdriver.Step, 0, len(steps))
+		for _, s := range steps[:i] {
+			if strings.HasPrefix(s.Name, sdStepPrefix) {
+				kept = append(kept, s)
+			}
+		}
+		return append(kept, steps[i+1:]...)
+	}
+	return steps
+}
+
+// insertSnapshotStep returns the steps with the step which waits for the snapshot after the step named after.
+// The snapshot is not taken when any of the setup steps fails.
+func insertSnapshotStep(steps []screwdriver.Step, after, artifactsDir string) []screwdriver.Step {
+	request := fmt.Sprintf("%s/%s", artifactsDir, snapshotRequestFile)
+	command := fmt.Sprintf(`[ -e %s ] || { touch %s; i=0; while [ -e %s ] && [ $i -lt %d ]; do sleep 1; i=$((i+1)); done; }`,
+		failedFile, request, request, int(snapshotTimeout.Seconds()))
+
+	for i, s := range steps {
+		if s.Name == after {
+			inserted := append([]screwdriver.Step{}, steps[:i+1]...)
+			inserted = append(inserted, screwdriver.Step{Name: snapshotStep, Command: command})
+			return append(inserted, steps[i+1:]...)
+		}
+	}
+	return steps
+}
+
+// watchSnapshot commits the build container when the snapshot step requests it, until done is closed.
+func (d *docker) watchSnapshot(container, image, jobName, artifactsPath string, done <-chan struct{}) {
+	request := filepath.Join(artifactsPath, snapshotRequestFile)
+	t := time.NewTicker(snapshotInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-t.C:
+			if _, err := os.Stat(request); err != nil {
+				continue
+			}
+
+			logrus.Infof("Taking the snapshot %s of the build container", image)
+			if _, err := d.execDockerCommand(d.runtime.commit(container, image, fmt.Sprintf("LABEL %s=%s", SnapshotLabel, jobName))...); err != nil {
+				logrus.Warn(fmt.Errorf("failed to take snapshot: %v", err))
+			}
+			// The build goes on even if it fails to take the snapshot
+			if err := os.Remove(request); err != nil {
+				logrus.Warn(fmt.Errorf("failed to remove the request of snapshot: %v", err))
+			}
+			return
+		}
+	}
+}
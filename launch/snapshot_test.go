@@ -0,0 +1,177 @@
+package launch
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/screwdriver-cd/sd-local/screwdriver"
+	"github.com/stretchr/testify/assert"
+)
+
+// snapshotHelperProcess fakes the container runtime which has the snapshot or not.
+func snapshotHelperProcess(testCase, subcmd string, args []string) {
+	if testCase == "SNAPSHOT_MISSING" && subcmd == "image" {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func TestSnapshotImage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	lockfile := filepath.Join(dir, "package-lock.json")
+	files := []string{lockfile, filepath.Join(dir, "missing")}
+	steps := []screwdriver.Step{{Name: "install", Command: "npm ci"}}
+
+	image := snapshotImage("node:18", "main", steps, files)
+	assert.Regexp(t, `^sd-local/snapshot:[0-9a-f]{16}$`, image)
+	assert.Equal(t, image, snapshotImage("node:18", "main", steps, files))
+	assert.NotEqual(t, image, snapshotImage("node:20", "main", steps, files))
+	assert.NotEqual(t, image, snapshotImage("node:18", "test", steps, files))
+	assert.NotEqual(t, image, snapshotImage("node:18", "main", []screwdriver.Step{{Name: "install", Command: "npm install"}}, files))
+
+	if err := ioutil.WriteFile(lockfile, []byte("{}"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	changed := snapshotImage("node:18", "main", steps, files)
+	assert.NotEqual(t, image, changed)
+
+	if err := ioutil.WriteFile(lockfile, []byte(`{"lockfileVersion": 3}`), 0666); err != nil {
+		t.Fatal(err)
+	}
+	assert.NotEqual(t, changed, snapshotImage("node:18", "main", steps, files))
+}
+
+func TestApplySnapshot(t *testing.T) {
+	job := screwdriver.Job{Steps: []screwdriver.Step{
+		{Name: "install", Command: "npm ci"},
+		{Name: "test", Command: "npm test"},
+	}}
+
+	t.Run("success", func(t *testing.T) {
+		b := buildEntry{Image: "node:18"}
+		applySnapshot(&b, Option{Job: job, JobName: "main", SrcPath: "/src", Snapshot: SnapshotOption{After: "install", Files: []string{"/src/screwdriver.yaml"}}})
+		assert.Equal(t, "install", b.SnapshotAfter)
+		assert.Equal(t, snapshotImage("node:18", "main", job.Steps[:1], nil), b.SnapshotImage)
+	})
+
+	t.Run("success without the step", func(t *testing.T) {
+		b := buildEntry{Image: "node:18"}
+		applySnapshot(&b, Option{Job: job, JobName: "main", Snapshot: SnapshotOption{After: "build"}})
+		assert.Equal(t, "", b.SnapshotAfter)
+		assert.Equal(t, "", b.SnapshotImage)
+	})
+
+	t.Run("success without snapshot", func(t *testing.T) {
+		b := buildEntry{Image: "node:18"}
+		applySnapshot(&b, Option{Job: job, JobName: "main"})
+		assert.Equal(t, buildEntry{Image: "node:18"}, b)
+	})
+}
+
+func TestSkipSetupSteps(t *testing.T) {
+	steps := []screwdriver.Step{
+		{Name: "sd-setup-init"},
+		{Name: "install"},
+		{Name: "sd-setup-cache"},
+		{Name: "build"},
+		{Name: "test"},
+	}
+
+	assert.Equal(t, []screwdriver.Step{{Name: "sd-setup-init"}, {Name: "sd-setup-cache"}, {Name: "test"}}, skipSetupSteps(steps, "build"))
+	assert.Equal(t, steps, skipSetupSteps(steps, "publish"))
+}
+
+func TestInsertSnapshotStep(t *testing.T) {
+	steps := []screwdriver.Step{{Name: "install"}, {Name: "test"}}
+
+	inserted := insertSnapshotStep(steps, "install", "/sd/workspace/artifacts")
+	assert.Equal(t, 3, len(inserted))
+	assert.Equal(t, []screwdriver.Step{{Name: "install"}, {Name: "test"}}, steps)
+	assert.Equal(t, "install", inserted[0].Name)
+	assert.Equal(t, snapshotStep, inserted[1].Name)
+	assert.Equal(t, "[ -e /tmp/sd-local-failed ] || { touch /sd/workspace/artifacts/.sd-local-snapshot; i=0; while [ -e /sd/workspace/artifacts/.sd-local-snapshot ] && [ $i -lt 600 ]; do sleep 1; i=$((i+1)); done; }", inserted[1].Command)
+	assert.Equal(t, "test", inserted[2].Name)
+
+	assert.Equal(t, steps, insertSnapshotStep(steps, "publish", "/sd/workspace/artifacts"))
+}
+
+func TestWatchSnapshot(t *testing.T) {
+	defer func(interval time.Duration) {
+		execCommand = exec.Command
+		snapshotInterval = interval
+	}(snapshotInterval)
+	snapshotInterval = 10 * time.Millisecond
+
+	dir, err := ioutil.TempDir("", "snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	request := filepath.Join(dir, snapshotRequestFile)
+	if err := ioutil.WriteFile(request, nil, 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	c := newFakeExecCommand("SNAPSHOT_EXISTS")
+	execCommand = c.execCmd
+	d := &docker{runtime: &dockerRuntime{}, mutex: &sync.Mutex{}}
+
+	done := make(chan struct{})
+	defer close(done)
+	d.watchSnapshot("sd-local-main-1", "sd-local/snapshot:0123456789abcdef", "main", dir, done)
+
+	assert.Equal(t, []string{`docker container commit --change LABEL sd-local.snapshot=main sd-local-main-1 sd-local/snapshot:0123456789abcdef`}, c.commands)
+	_, err = os.Stat(request)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestRunBuildWithSnapshot(t *testing.T) {
+	defer func() {
+		execCommand = exec.Command
+	}()
+
+	d := &docker{runtime: &dockerRuntime{}, mutex: &sync.Mutex{}, volume: "SD_LAUNCH_BIN", habVolume: "SD_LAUNCH_HAB"}
+	newEntry := func() buildEntry {
+		return newBuildEntry(func(b *buildEntry) {
+			b.Steps = []screwdriver.Step{{Name: "install", Command: "npm ci"}, {Name: "test", Command: "npm test"}}
+			b.SnapshotAfter = "install"
+			b.SnapshotImage = "sd-local/snapshot:0123456789abcdef"
+		})
+	}
+
+	t.Run("success with taking snapshot", func(t *testing.T) {
+		c := newFakeExecCommand("SNAPSHOT_MISSING")
+		execCommand = c.execCmd
+
+		err := d.runBuild(newEntry())
+		assert.Nil(t, err)
+		assert.Equal(t, "docker image inspect sd-local/snapshot:0123456789abcdef", c.commands[0])
+		assert.Equal(t, "docker pull node:12", c.commands[1])
+		assert.True(t, strings.Contains(c.commands[2], fmt.Sprintf(`"name":"%s"`, snapshotStep)), c.commands[2])
+	})
+
+	t.Run("success from snapshot", func(t *testing.T) {
+		c := newFakeExecCommand("SNAPSHOT_EXISTS")
+		execCommand = c.execCmd
+
+		err := d.runBuild(newEntry())
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(c.commands))
+		assert.Contains(t, c.commands[1], " sd-local/snapshot:0123456789abcdef /opt/sd/local_run.sh ")
+		assert.NotContains(t, c.commands[1], `"name":"install"`)
+		assert.Contains(t, c.commands[1], `"name":"test"`)
+	})
+}
@@ -0,0 +1,41 @@
+package launch
+
+import "strconv"
+
+// sourceDir is the directory in the build container where the source directory is mounted.
+const sourceDir = "/sd/workspace/src/" + scmHost + "/" + orgRepo
+
+// SourceOption is the information of the source which Screwdriver.cd sets as environment variables.
+// The empty values are not set.
+type SourceOption struct {
+	URL        string
+	Branch     string
+	SHA        string
+	PipelineID int
+}
+
+// env returns the environment variables of the source.
+func (s SourceOption) env() EnvVar {
+	env := EnvVar{"SD_SOURCE_DIR": sourceDir}
+	for k, v := range map[string]string{
+		"GIT_URL":      s.URL,
+		"GIT_BRANCH":   s.Branch,
+		"SD_BUILD_SHA": s.SHA,
+	} {
+		if v != "" {
+			env[k] = v
+		}
+	}
+	if s.PipelineID != 0 {
+		env["SD_PIPELINE_ID"] = strconv.Itoa(s.PipelineID)
+	}
+	return env
+}
+
+// sha returns the commit of the build. The launcher requires it even if the source is not a git checkout.
+func (s SourceOption) sha() string {
+	if s.SHA == "" {
+		return "dummy"
+	}
+	return s.SHA
+}
@@ -0,0 +1,32 @@
+package launch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSourceOptionEnv(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		s := SourceOption{
+			URL:        "git@github.com:screwdriver-cd/sd-local.git",
+			Branch:     "master",
+			SHA:        "abc123",
+			PipelineID: 123,
+		}
+		assert.Equal(t, EnvVar{
+			"SD_SOURCE_DIR":  "/sd/workspace/src/screwdriver.cd/sd-local/local-build",
+			"GIT_URL":        "git@github.com:screwdriver-cd/sd-local.git",
+			"GIT_BRANCH":     "master",
+			"SD_BUILD_SHA":   "abc123",
+			"SD_PIPELINE_ID": "123",
+		}, s.env())
+		assert.Equal(t, "abc123", s.sha())
+	})
+
+	t.Run("success without source", func(t *testing.T) {
+		s := SourceOption{}
+		assert.Equal(t, EnvVar{"SD_SOURCE_DIR": "/sd/workspace/src/screwdriver.cd/sd-local/local-build"}, s.env())
+		assert.Equal(t, "dummy", s.sha())
+	})
+}
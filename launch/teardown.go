@@ -0,0 +1,159 @@
+package launch
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/screwdriver-cd/sd-local/screwdriver"
+)
+
+const (
+	// StepsFile is the file in the artifacts directory where the results of the steps are written.
+	StepsFile = "steps.tsv"
+	// StepSuccess is the status of the step which exits with 0.
+	StepSuccess = "SUCCESS"
+	// StepFailure is the status of the step which exits with non-zero.
+	StepFailure = "FAILURE"
+	// StepSkipped is the status of the step which is not run.
+	StepSkipped = "SKIPPED"
+
+	sdStepPrefix         = "sd-"
+	sdTeardownStepPrefix = "sd-teardown-"
+	resultStep           = "sd-local-result"
+	failedFile           = "/tmp/sd-local-failed"
+	stepPIDFile          = "/tmp/sd-local-step.pid"
+)
+
+// StepResult is the result of a step in the build.
+// ExitCode and Duration are zero for the skipped steps.
+type StepResult struct {
+	Name     string
+	Status   string
+	ExitCode int
+	Duration time.Duration
+}
+
+// IsTeardown reports whether the step is a teardown step, which is run even if the previous steps fail.
+func IsTeardown(name string) bool {
+	return strings.HasPrefix(name, teardownPrefix)
+}
+
+// sortTeardown orders the steps as the launcher of Screwdriver.cd runs them.
+// The user steps are followed by the teardown steps of the user and then the teardown steps of Screwdriver.cd.
+func sortTeardown(steps []screwdriver.Step) []screwdriver.Step {
+	sorted := make([]screwdriver.Step, 0, len(steps))
+	for _, match := range []func(string) bool{
+		func(name string) bool { return !IsTeardown(name) && !strings.HasPrefix(name, sdTeardownStepPrefix) },
+		IsTeardown,
+		func(name string) bool { return strings.HasPrefix(name, sdTeardownStepPrefix) },
+	} {
+		for _, s := range steps {
+			if match(s.Name) {
+				sorted = append(sorted, s)
+			}
+		}
+	}
+	return sorted
+}
+
+// resultCommand wraps the command of the user step to write its result, exit code and duration in seconds into the steps file.
+// The failure of the step doesn't stop the build so that the teardown steps are run,
+// and the user steps after the failed step are skipped instead.
+func resultCommand(step screwdriver.Step, stepsFile string) string {
+	record := fmt.Sprintf(`printf '%%s\t%%s\n' %s`, shellQuote(step.Name))
+	recordRun := fmt.Sprintf(`printf '%%s\t%%s\t%%s\t%%s\n' %s`, shellQuote(step.Name))
+	elapsed := `"$sd_code" "$(( $(date +%s) - sd_start ))"`
+
+	lines := make([]string, 0)
+	if !IsTeardown(step.Name) {
+		lines = append(lines,
+			fmt.Sprintf(`if [ -e %s ]; then`, failedFile),
+			fmt.Sprintf(`  %s %s >> "%s"`, record, StepSkipped, stepsFile),
+			`  echo "Skipped because the previous step failed"`,
+			`else`)
+	}
+
+	// The shell running the user step is recorded so that the processes of the step are interrupted on the signal
+	if !IsTeardown(step.Name) {
+		lines = append(lines, fmt.Sprintf(`echo $$ > %s`, stepPIDFile))
+	}
+	lines = append(lines,
+		`sd_start=$(date +%s)`,
+		step.Command,
+		`sd_code=$?`)
+	if !IsTeardown(step.Name) {
+		lines = append(lines, fmt.Sprintf(`rm -f %s`, stepPIDFile))
+	}
+	lines = append(lines,
+		fmt.Sprintf(`if [ "$sd_code" -eq 0 ]; then %s %s %s >> "%s"; else %s %s %s >> "%s"; touch %s; fi`, recordRun, StepSuccess, elapsed, stepsFile, recordRun, StepFailure, elapsed, stepsFile, failedFile))
+
+	if !IsTeardown(step.Name) {
+		lines = append(lines, `fi`)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// applyTeardown makes the teardown steps run even if the previous steps fail, as the launcher of Screwdriver.cd does.
+// The results of the user steps are written into the steps file, and the build fails at the end if any of them failed.
+func applyTeardown(b *buildEntry) {
+	if len(b.Steps) == 0 {
+		return
+	}
+
+	stepsFile := fmt.Sprintf("%s/%s", b.Environment[0]["SD_ARTIFACTS_DIR"], StepsFile)
+
+	steps := make([]screwdriver.Step, 0, len(b.Steps)+1)
+	for _, s := range sortTeardown(b.Steps) {
+		if !strings.HasPrefix(s.Name, sdStepPrefix) {
+			s.Command = resultCommand(s, stepsFile)
+		}
+		steps = append(steps, s)
+	}
+
+	b.Steps = append(steps, screwdriver.Step{
+		Name:    resultStep,
+		Command: fmt.Sprintf("[ ! -e %s ]", failedFile),
+	})
+}
+
+// ReadStepResults reads the results of the steps in the build from the steps file in the artifacts directory.
+// It returns no results if the file doesn't exist.
+func ReadStepResults(artifactsPath string) ([]StepResult, error) {
+	f, err := os.Open(filepath.Join(artifactsPath, StepsFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read results of steps: %v", err)
+	}
+	defer f.Close()
+
+	results := make([]StepResult, 0)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) < 2 {
+			continue
+		}
+
+		r := StepResult{Name: fields[0], Status: fields[1]}
+		if len(fields) >= 4 {
+			r.ExitCode, _ = strconv.Atoi(fields[2])
+			seconds, _ := strconv.Atoi(fields[3])
+			r.Duration = time.Duration(seconds) * time.Second
+		}
+		results = append(results, r)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read results of steps: %v", err)
+	}
+
+	return results, nil
+}
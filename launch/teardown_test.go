@@ -0,0 +1,97 @@
+package launch
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/screwdriver-cd/sd-local/screwdriver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortTeardown(t *testing.T) {
+	steps := []screwdriver.Step{
+		{Name: "sd-setup-cache"},
+		{Name: "teardown-notify"},
+		{Name: "install"},
+		{Name: "sd-teardown-cache"},
+		{Name: "test"},
+		{Name: "teardown-clean"},
+	}
+
+	assert.Equal(t, []screwdriver.Step{
+		{Name: "sd-setup-cache"},
+		{Name: "install"},
+		{Name: "test"},
+		{Name: "teardown-notify"},
+		{Name: "teardown-clean"},
+		{Name: "sd-teardown-cache"},
+	}, sortTeardown(steps))
+}
+
+func TestApplyTeardown(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		b := newBuildEntry(func(b *buildEntry) {
+			b.Steps = []screwdriver.Step{
+				{Name: "sd-setup-cache", Command: "store-cli get"},
+				{Name: "teardown-notify", Command: "notify"},
+				{Name: "test", Command: "npm test"},
+			}
+		})
+		applyTeardown(&b)
+
+		assert.Equal(t, 4, len(b.Steps))
+		assert.Equal(t, screwdriver.Step{Name: "sd-setup-cache", Command: "store-cli get"}, b.Steps[0])
+
+		assert.Equal(t, "test", b.Steps[1].Name)
+		assert.True(t, strings.HasPrefix(b.Steps[1].Command, "if [ -e /tmp/sd-local-failed ]; then\n"))
+		assert.Contains(t, b.Steps[1].Command, "\necho $$ > /tmp/sd-local-step.pid\nsd_start=$(date +%s)\nnpm test\nsd_code=$?\nrm -f /tmp/sd-local-step.pid\n")
+		assert.Contains(t, b.Steps[1].Command, `printf '%s\t%s\n' 'test' SKIPPED >> "/test/artifacts/steps.tsv"`)
+		assert.Contains(t, b.Steps[1].Command, `printf '%s\t%s\t%s\t%s\n' 'test' SUCCESS "$sd_code" "$(( $(date +%s) - sd_start ))" >> "/test/artifacts/steps.tsv"`)
+
+		assert.Equal(t, "teardown-notify", b.Steps[2].Name)
+		assert.True(t, strings.HasPrefix(b.Steps[2].Command, "sd_start=$(date +%s)\nnotify\nsd_code=$?\n"))
+		assert.NotContains(t, b.Steps[2].Command, "/tmp/sd-local-step.pid")
+		assert.Contains(t, b.Steps[2].Command, `printf '%s\t%s\t%s\t%s\n' 'teardown-notify' FAILURE "$sd_code" "$(( $(date +%s) - sd_start ))" >> "/test/artifacts/steps.tsv"; touch /tmp/sd-local-failed`)
+
+		assert.Equal(t, screwdriver.Step{Name: "sd-local-result", Command: "[ ! -e /tmp/sd-local-failed ]"}, b.Steps[3])
+	})
+
+	t.Run("success without steps", func(t *testing.T) {
+		b := newBuildEntry(func(b *buildEntry) {
+			b.Steps = nil
+		})
+		applyTeardown(&b)
+		assert.Nil(t, b.Steps)
+	})
+}
+
+func TestReadStepResults(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sd-artifacts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	results, err := ReadStepResults(dir)
+	assert.Nil(t, err)
+	assert.Nil(t, results)
+
+	content := "install\tSUCCESS\t0\t12\ntest\tFAILURE\t2\t3\npublish\tSKIPPED\nteardown-notify\tSUCCESS\n"
+	err = ioutil.WriteFile(filepath.Join(dir, StepsFile), []byte(content), 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, err = ReadStepResults(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, []StepResult{
+		{Name: "install", Status: StepSuccess, ExitCode: 0, Duration: 12 * time.Second},
+		{Name: "test", Status: StepFailure, ExitCode: 2, Duration: 3 * time.Second},
+		{Name: "publish", Status: StepSkipped},
+		{Name: "teardown-notify", Status: StepSuccess},
+	}, results)
+}
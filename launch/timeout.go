@@ -0,0 +1,124 @@
+package launch
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/screwdriver-cd/sd-local/screwdriver"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// TimeoutAnnotation is the annotation of the build timeout in minutes.
+	TimeoutAnnotation = "screwdriver.cd/timeout"
+	setupTimeoutStep  = "sd-setup-timeout"
+	teardownPrefix    = "teardown-"
+	deadlineFile      = "/tmp/sd-local-deadline"
+	// killAfter is the seconds to wait before killing the step which doesn't exit after the timeout.
+	killAfter = 10
+)
+
+// The files in the build container with which the step and its watchdog tell each other about the timeout
+var (
+	timedOutFile    = "/tmp/sd-local-timed-out"
+	watchdogPIDFile = "/tmp/sd-local-watchdog.pid"
+)
+
+// TimeoutOption is the timeouts of the build and its steps. Zero means no timeout.
+// The build timeout takes precedence over the timeout annotation of the job.
+type TimeoutOption struct {
+	Build time.Duration
+	Steps map[string]time.Duration
+}
+
+// buildTimeout returns the timeout of the build, which is read from the annotation unless it is specified in the option.
+func buildTimeout(job screwdriver.Job, t TimeoutOption) time.Duration {
+	if t.Build != 0 {
+		return t.Build
+	}
+
+	annotation, ok := job.Annotations[TimeoutAnnotation]
+	if !ok {
+		return 0
+	}
+
+	minutes, err := strconv.Atoi(fmt.Sprint(annotation))
+	if err != nil || minutes < 0 {
+		logrus.Warnf("%s annotation is ignored because it is not a number of minutes: %v", TimeoutAnnotation, annotation)
+		return 0
+	}
+
+	return time.Duration(minutes) * time.Minute
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
+
+// signalDescendants returns the command sending the signal to the descendants of the process root except the process except and its descendants.
+// The processes are found in /proc, since the images may not have ps or pkill.
+func signalDescendants(sig, root, except string) string {
+	return fmt.Sprintf(`sd_pids=" %s "; sd_found=1; while [ -n "$sd_found" ]; do sd_found=; for s in /proc/[0-9]*/status; do p=${s%%/status}; p=${p#/proc/}; case "$sd_pids" in *" $p "*) continue ;; esac; while read -r k v; do if [ "$k" = PPid: ]; then case "$sd_pids" in *" $v "*) [ "$p" != "%s" ] && sd_pids="$sd_pids$p " && sd_found=1 ;; esac; break; fi; done < "$s" 2> /dev/null; done; done; for p in $sd_pids; do [ "$p" != "%s" ] && kill -%s "$p" 2> /dev/null; done`, root, except, root, sig)
+}
+
+// timeoutCommand makes the step killed when it runs beyond the timeout.
+// The remaining time of the build is used as the timeout when it is shorter than the timeout of the step.
+// The step is run in the shell of the launcher as the other steps so that the variables and the directory are passed to the later steps,
+// and the watchdog running in the background terminates the processes started by the step on the timeout.
+// The processes which don't exit are killed after killAfter seconds. The step fails with 124 even if its commands go on after the processes are stopped,
+// since the shell running them can't be stopped without stopping the build.
+func timeoutCommand(step screwdriver.Step, stepTimeout time.Duration, useDeadline bool) string {
+	lines := []string{fmt.Sprintf("sd_timeout=%d", int(stepTimeout.Seconds()))}
+	if useDeadline {
+		lines = append(lines,
+			fmt.Sprintf(`sd_left=$(( $(cat %s) - $(date +%%s) ))`, deadlineFile),
+			`if [ "$sd_timeout" -eq 0 ] || [ "$sd_left" -lt "$sd_timeout" ]; then sd_timeout=$sd_left; fi`,
+			`if [ "$sd_timeout" -lt 1 ]; then sd_timeout=1; fi`)
+	}
+
+	// The watchdog sleeps in the background so that it stops the sleep and exits as soon as it is terminated after the step
+	watchdog := fmt.Sprintf(`$(cat %s)`, watchdogPIDFile)
+	lines = append(lines,
+		fmt.Sprintf(`rm -f %s`, timedOutFile),
+		`sd_shell=$$`,
+		fmt.Sprintf(`( trap 'kill $! 2> /dev/null; exit' TERM; sleep "$sd_timeout" & wait $!; touch %s; sd_self=%s; %s; sleep %d & wait $!; while :; do %s; sleep 1 & wait $!; done ) > /dev/null 2>&1 &`,
+			timedOutFile, watchdog, signalDescendants("TERM", "$sd_shell", "$sd_self"), killAfter, signalDescendants("KILL", "$sd_shell", "$sd_self")),
+		fmt.Sprintf(`echo $! > %s`, watchdogPIDFile),
+		step.Command,
+		`sd_code=$?`,
+		fmt.Sprintf(`kill %s 2> /dev/null`, watchdog),
+		fmt.Sprintf(`if [ -e %s ]; then rm -f %s; sd_code=124; echo "Timeout of ${sd_timeout} seconds exceeded in step %s"; fi`, timedOutFile, timedOutFile, step.Name),
+		`(exit $sd_code)`)
+
+	return strings.Join(lines, "\n")
+}
+
+// applyTimeout makes the steps of the build killed when they run beyond the timeouts.
+// The build timeout is not applied to teardown steps so that they run after the timeout as Screwdriver.cd does.
+func applyTimeout(b *buildEntry, job screwdriver.Job, t TimeoutOption) {
+	build := buildTimeout(job, t)
+	if build == 0 && len(t.Steps) == 0 {
+		return
+	}
+
+	steps := make([]screwdriver.Step, 0, len(b.Steps)+1)
+	if build != 0 {
+		steps = append(steps, screwdriver.Step{
+			Name:    setupTimeoutStep,
+			Command: fmt.Sprintf("echo $(( $(date +%%s) + %d )) > %s", int(build.Seconds()), deadlineFile),
+		})
+	}
+
+	for _, s := range b.Steps {
+		stepTimeout := t.Steps[s.Name]
+		useDeadline := build != 0 && !IsTeardown(s.Name)
+		if stepTimeout != 0 || useDeadline {
+			s.Command = timeoutCommand(s, stepTimeout, useDeadline)
+		}
+		steps = append(steps, s)
+	}
+
+	b.Steps = steps
+}
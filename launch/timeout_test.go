@@ -0,0 +1,110 @@
+package launch
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/screwdriver-cd/sd-local/screwdriver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildTimeout(t *testing.T) {
+	testCases := []struct {
+		name        string
+		annotations map[string]interface{}
+		option      TimeoutOption
+		expect      time.Duration
+	}{
+		{"no timeout", nil, TimeoutOption{}, 0},
+		{"annotation", map[string]interface{}{TimeoutAnnotation: 30}, TimeoutOption{}, 30 * time.Minute},
+		{"annotation in string", map[string]interface{}{TimeoutAnnotation: "30"}, TimeoutOption{}, 30 * time.Minute},
+		{"option takes precedence", map[string]interface{}{TimeoutAnnotation: 30}, TimeoutOption{Build: time.Minute}, time.Minute},
+		{"invalid annotation", map[string]interface{}{TimeoutAnnotation: "forever"}, TimeoutOption{}, 0},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			job := screwdriver.Job{Annotations: tt.annotations}
+			assert.Equal(t, tt.expect, buildTimeout(job, tt.option))
+		})
+	}
+}
+
+func TestApplyTimeout(t *testing.T) {
+	steps := []screwdriver.Step{
+		{Name: "install", Command: "npm install"},
+		{Name: "test", Command: "echo 'npm test'"},
+		{Name: "teardown-notify", Command: "notify"},
+	}
+
+	t.Run("success without timeout", func(t *testing.T) {
+		b := buildEntry{Steps: steps}
+		applyTimeout(&b, screwdriver.Job{}, TimeoutOption{})
+		assert.Equal(t, steps, b.Steps)
+	})
+
+	t.Run("success with build timeout", func(t *testing.T) {
+		b := buildEntry{Steps: steps}
+		applyTimeout(&b, screwdriver.Job{}, TimeoutOption{Build: 2 * time.Minute})
+
+		assert.Equal(t, 4, len(b.Steps))
+		assert.Equal(t, screwdriver.Step{Name: setupTimeoutStep, Command: "echo $(( $(date +%s) + 120 )) > /tmp/sd-local-deadline"}, b.Steps[0])
+
+		assert.Equal(t, "install", b.Steps[1].Name)
+		assert.True(t, strings.HasPrefix(b.Steps[1].Command, "sd_timeout=0\nsd_left=$(( $(cat /tmp/sd-local-deadline) - $(date +%s) ))\n"))
+		assert.Contains(t, b.Steps[1].Command, "\necho $! > /tmp/sd-local-watchdog.pid\nnpm install\nsd_code=$?\n")
+
+		assert.Contains(t, b.Steps[2].Command, "\necho 'npm test'\nsd_code=$?\n")
+		assert.Contains(t, b.Steps[2].Command, `echo "Timeout of ${sd_timeout} seconds exceeded in step test"`)
+
+		assert.Equal(t, steps[2], b.Steps[3])
+	})
+
+	t.Run("success with step timeout", func(t *testing.T) {
+		b := buildEntry{Steps: steps}
+		applyTimeout(&b, screwdriver.Job{}, TimeoutOption{Steps: map[string]time.Duration{"teardown-notify": 30 * time.Second}})
+
+		assert.Equal(t, steps[:2], b.Steps[:2])
+		assert.Equal(t, "teardown-notify", b.Steps[2].Name)
+		assert.True(t, strings.HasPrefix(b.Steps[2].Command, "sd_timeout=30\nrm -f /tmp/sd-local-timed-out\n"))
+	})
+}
+
+func TestTimeoutCommand(t *testing.T) {
+	if _, err := os.Stat("/proc/self/status"); err != nil {
+		t.Skip("/proc is required to find the processes of the step")
+	}
+	// The files are written on the host in the test, so they are not shared with the other tests and builds
+	origTimedOutFile, origWatchdogPIDFile := timedOutFile, watchdogPIDFile
+	t.Cleanup(func() { timedOutFile, watchdogPIDFile = origTimedOutFile, origWatchdogPIDFile })
+	dir := t.TempDir()
+	timedOutFile = filepath.Join(dir, "timed-out")
+	watchdogPIDFile = filepath.Join(dir, "watchdog.pid")
+
+	run := func(command string, timeout time.Duration) (string, error) {
+		script := timeoutCommand(screwdriver.Step{Name: "test", Command: command}, timeout, false)
+		// The variables exported in the step are passed to the later commands in the shell
+		out, err := exec.Command("sh", "-c", script+"\nsd_code=$?\necho \"FOO=$FOO\"\nexit $sd_code").Output()
+		return string(out), err
+	}
+
+	t.Run("success in the shell", func(t *testing.T) {
+		out, err := run("export FOO=bar", 10*time.Second)
+		assert.Nil(t, err)
+		assert.Equal(t, "FOO=bar\n", out)
+	})
+
+	t.Run("failure by timeout", func(t *testing.T) {
+		start := time.Now()
+		out, err := run("export FOO=bar; sleep 30", time.Second)
+		assert.Less(t, int64(time.Since(start)), int64(10*time.Second))
+		assert.Equal(t, "Timeout of 1 seconds exceeded in step test\nFOO=bar\n", out)
+		if assert.IsType(t, &exec.ExitError{}, err) {
+			assert.Equal(t, 124, err.(*exec.ExitError).ExitCode())
+		}
+	})
+}
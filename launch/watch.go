@@ -0,0 +1,51 @@
+package launch
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// SourceDigest returns the digest of the paths, the sizes and the modification times of the files in the source directory,
+// which changes when any file is added, removed or written.
+// The directories which match the ignore file, .git and the excluded paths such as the artifacts directory are not read,
+// so that the files written by the build do not change it.
+func SourceDigest(srcPath string, excludes []string) (string, error) {
+	patterns, err := readIgnorePatterns(srcPath)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	err = filepath.Walk(srcPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			// The file removed while walking is found by the next digest
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+
+		rel, err := filepath.Rel(srcPath, path)
+		if err != nil || rel == "." {
+			return err
+		}
+
+		if info.IsDir() {
+			if info.Name() == ".git" || isIgnored(rel, patterns) || contains(excludes, path) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		fmt.Fprintf(h, "%s\t%d\t%d\n", filepath.ToSlash(rel), info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to read source directory: %v", err)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,50 @@
+package launch
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSourceDigest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sd-local-src")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeSourceFiles(t, dir, map[string]string{
+		IgnoreFile:                  "node_modules/\n",
+		"main.go":                   "package main",
+		"node_modules/foo/index.js": "module.exports = {}",
+		".git/HEAD":                 "ref: refs/heads/master",
+		"sd-artifacts/steps.tsv":    "main\tinstall\t0",
+	})
+	excludes := []string{filepath.Join(dir, "sd-artifacts")}
+
+	digest, err := SourceDigest(dir, excludes)
+	assert.Nil(t, err)
+
+	t.Run("unchanged by the ignored files", func(t *testing.T) {
+		writeSourceFiles(t, dir, map[string]string{
+			"node_modules/bar/index.js": "module.exports = {}",
+			".git/HEAD":                 "ref: refs/heads/feature",
+			"sd-artifacts/test.xml":     "<testsuites/>",
+		})
+
+		d, err := SourceDigest(dir, excludes)
+		assert.Nil(t, err)
+		assert.Equal(t, digest, d)
+	})
+
+	t.Run("changed by the source files", func(t *testing.T) {
+		writeSourceFiles(t, dir, map[string]string{"main_test.go": "package main"})
+
+		d, err := SourceDigest(dir, excludes)
+		assert.Nil(t, err)
+		assert.NotEqual(t, digest, d)
+	})
+}
@@ -0,0 +1,274 @@
+package localapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/screwdriver-cd/sd-local/launch"
+)
+
+const (
+	apiPrefix   = "/v4/"
+	storePrefix = "/v1/"
+	badge       = `<svg xmlns="http://www.w3.org/2000/svg" width="70" height="20"><rect width="70" height="20" fill="#9f9f9f"/><text x="35" y="14" fill="#fff" font-family="Verdana,sans-serif" font-size="11" text-anchor="middle">sd-local</text></svg>`
+)
+
+// Option is the token which the requests must have, and the host side directories which the server reads and writes.
+// CacheDirs maps the scopes of the store (pipelines, events and jobs) to the directories of the caches.
+type Option struct {
+	Token         string
+	MetaPath      string
+	ArtifactsPath string
+	CacheDirs     map[string]string
+}
+
+// Server emulates the endpoints of Screwdriver.cd API and the store which the tools in builds call,
+// that are the meta of the build, the badges, the caches and the artifacts.
+type Server struct {
+	option   Option
+	listener net.Listener
+	server   *http.Server
+	// metaMutex serializes updating the meta file
+	metaMutex *sync.Mutex
+}
+
+// New returns the server which is not started yet.
+func New(option Option) *Server {
+	return &Server{option: option, metaMutex: &sync.Mutex{}}
+}
+
+// Start starts serving on addr in background. The port is chosen by the system when it is 0.
+func (s *Server) Start(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen for local API: %v", err)
+	}
+	s.listener = l
+	s.server = &http.Server{Handler: s.Handler()}
+
+	go s.server.Serve(l)
+
+	return nil
+}
+
+// Port returns the port which the server is listening on.
+func (s *Server) Port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+// Close stops the server.
+func (s *Server) Close() error {
+	if s.server == nil {
+		return nil
+	}
+	return s.server.Close()
+}
+
+type errorResponse struct {
+	StatusCode int    `json:"statusCode"`
+	Error      string `json:"error"`
+	Message    string `json:"message"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError responds in the same form as Screwdriver.cd API
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, errorResponse{StatusCode: status, Error: http.StatusText(status), Message: message})
+}
+
+// Handler returns the handler of the endpoints.
+func (s *Server) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The badges are public as Screwdriver.cd
+		if isBadge(r.URL.Path) {
+			s.serveBadge(w, r)
+			return
+		}
+
+		if r.Header.Get("Authorization") != "Bearer "+s.option.Token {
+			writeError(w, http.StatusUnauthorized, "Missing authentication")
+			return
+		}
+
+		switch {
+		case strings.HasPrefix(r.URL.Path, apiPrefix+"builds/"):
+			s.serveBuild(w, r)
+		case strings.HasPrefix(r.URL.Path, storePrefix+"caches/"):
+			s.serveCache(w, r)
+		case strings.HasPrefix(r.URL.Path, storePrefix+"builds/"):
+			s.serveArtifact(w, r)
+		default:
+			writeError(w, http.StatusNotFound, fmt.Sprintf("%s is not supported by sd-local", r.URL.Path))
+		}
+	})
+}
+
+// isBadge reports whether the path is the badge of a pipeline or a job, which is /v4/pipelines/{id}[/{job}]/badge
+func isBadge(p string) bool {
+	parts := strings.Split(strings.TrimPrefix(p, apiPrefix), "/")
+	return (len(parts) == 3 || len(parts) == 4) && parts[0] == "pipelines" && parts[len(parts)-1] == "badge"
+}
+
+func (s *Server) serveBadge(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "image/svg+xml")
+	io.WriteString(w, badge)
+}
+
+type buildResponse struct {
+	ID   int         `json:"id"`
+	Meta launch.Meta `json:"meta"`
+}
+
+type buildRequest struct {
+	Meta launch.Meta `json:"meta"`
+}
+
+// serveBuild serves the meta of the build with GET and PUT /v4/builds/{id}, whatever the ID is
+func (s *Server) serveBuild(w http.ResponseWriter, r *http.Request) {
+	if strings.Contains(strings.TrimPrefix(r.URL.Path, apiPrefix+"builds/"), "/") {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("%s is not supported by sd-local", r.URL.Path))
+		return
+	}
+
+	s.metaMutex.Lock()
+	defer s.metaMutex.Unlock()
+
+	meta, err := launch.ReadMeta(s.option.MetaPath)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		req := buildRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid request: %v", err))
+			return
+		}
+		meta = meta.Merge(req.Meta)
+		if err := launch.WriteMeta(s.option.MetaPath, meta); err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	default:
+		writeError(w, http.StatusMethodNotAllowed, fmt.Sprintf("%s is not allowed", r.Method))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, buildResponse{ID: 0, Meta: meta})
+}
+
+// localPath returns the path under dir of the rest of the request path, which must not go out of dir.
+func localPath(dir, rest string) (string, bool) {
+	if dir == "" || rest == "" {
+		return "", false
+	}
+	cleaned := path.Clean("/" + rest)
+	if cleaned == "/" {
+		return "", false
+	}
+	return filepath.Join(dir, filepath.FromSlash(cleaned)), true
+}
+
+// serveCache serves the caches with /v1/caches/{scope}/{id}/{name}, which are shared among the builds of the scope
+func (s *Server) serveCache(w http.ResponseWriter, r *http.Request) {
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, storePrefix+"caches/"), "/", 3)
+	if len(parts) != 3 {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("%s is not supported by sd-local", r.URL.Path))
+		return
+	}
+
+	p, ok := localPath(s.option.CacheDirs[parts[0]], parts[2])
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("caches of scope %s are not enabled", parts[0]))
+		return
+	}
+
+	serveFile(w, r, p)
+}
+
+// serveArtifact serves the artifacts with /v1/builds/{id}/ARTIFACTS/{path}, whatever the ID is
+func (s *Server) serveArtifact(w http.ResponseWriter, r *http.Request) {
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, storePrefix+"builds/"), "/", 3)
+	if len(parts) != 3 || parts[1] != "ARTIFACTS" {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("%s is not supported by sd-local", r.URL.Path))
+		return
+	}
+
+	p, ok := localPath(s.option.ArtifactsPath, parts[2])
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("%s is not found", r.URL.Path))
+		return
+	}
+
+	serveFile(w, r, p)
+}
+
+// serveFile reads, writes and removes the file with GET, HEAD, PUT and DELETE as the store
+func serveFile(w http.ResponseWriter, r *http.Request, p string) {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		info, err := os.Stat(p)
+		if err != nil || info.IsDir() {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("%s is not found", r.URL.Path))
+			return
+		}
+		http.ServeFile(w, r, p)
+	case http.MethodPut:
+		if err := writeFile(p, r.Body); err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	case http.MethodDelete:
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			writeError(w, http.StatusInternalServerError, fmt.Sprintf("failed to remove %s: %v", r.URL.Path, err))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, fmt.Sprintf("%s is not allowed", r.Method))
+	}
+}
+
+func writeFile(p string, body io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(p), 0777); err != nil {
+		return fmt.Errorf("failed to create directory: %v", err)
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(p), ".upload")
+	if err != nil {
+		return fmt.Errorf("failed to write file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = io.Copy(tmp, body)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write file: %v", err)
+	}
+
+	if err := os.Rename(tmp.Name(), p); err != nil {
+		return fmt.Errorf("failed to write file: %v", err)
+	}
+
+	return nil
+}
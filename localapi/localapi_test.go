@@ -0,0 +1,221 @@
+package localapi
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/screwdriver-cd/sd-local/launch"
+	"github.com/stretchr/testify/assert"
+)
+
+const testToken = "testtoken"
+
+func newTestServer(t *testing.T) (*Server, string, func()) {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "localapi")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := New(Option{
+		Token:         testToken,
+		MetaPath:      filepath.Join(dir, "meta"),
+		ArtifactsPath: filepath.Join(dir, "artifacts"),
+		CacheDirs: map[string]string{
+			"pipelines": filepath.Join(dir, "pipeline"),
+			"events":    filepath.Join(dir, "event"),
+			"jobs":      "",
+		},
+	})
+
+	return s, dir, func() { os.RemoveAll(dir) }
+}
+
+func request(s *Server, method, target, token, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, req)
+	return w
+}
+
+func TestAuthorization(t *testing.T) {
+	s, _, cleanup := newTestServer(t)
+	defer cleanup()
+
+	testCases := []struct {
+		name   string
+		target string
+		token  string
+		status int
+		body   string
+	}{
+		{
+			name:   "success with token",
+			target: "/v4/builds/1",
+			token:  testToken,
+			status: http.StatusOK,
+			body:   "{\"id\":0,\"meta\":{}}\n",
+		},
+		{
+			name:   "failure without token",
+			target: "/v4/builds/1",
+			status: http.StatusUnauthorized,
+			body:   "{\"statusCode\":401,\"error\":\"Unauthorized\",\"message\":\"Missing authentication\"}\n",
+		},
+		{
+			name:   "failure with wrong token",
+			target: "/v4/builds/1",
+			token:  "wrong",
+			status: http.StatusUnauthorized,
+			body:   "{\"statusCode\":401,\"error\":\"Unauthorized\",\"message\":\"Missing authentication\"}\n",
+		},
+		{
+			name:   "success badge without token",
+			target: "/v4/pipelines/1/main/badge",
+			status: http.StatusOK,
+			body:   badge,
+		},
+		{
+			name:   "failure with unsupported endpoint",
+			target: "/v4/events/1",
+			token:  testToken,
+			status: http.StatusNotFound,
+			body:   "{\"statusCode\":404,\"error\":\"Not Found\",\"message\":\"/v4/events/1 is not supported by sd-local\"}\n",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			w := request(s, http.MethodGet, tt.target, tt.token, "")
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, tt.body, w.Body.String())
+		})
+	}
+}
+
+func TestIsBadge(t *testing.T) {
+	assert.True(t, isBadge("/v4/pipelines/1/badge"))
+	assert.True(t, isBadge("/v4/pipelines/1/main/badge"))
+	assert.False(t, isBadge("/v4/pipelines/1"))
+	assert.False(t, isBadge("/v4/builds/1/badge"))
+}
+
+func TestServeBuild(t *testing.T) {
+	s, dir, cleanup := newTestServer(t)
+	defer cleanup()
+
+	err := launch.WriteMeta(filepath.Join(dir, "meta"), launch.Meta{"foo": "bar"})
+	assert.Nil(t, err)
+
+	w := request(s, http.MethodPut, "/v4/builds/1", testToken, `{"meta":{"baz":"qux"}}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "{\"id\":0,\"meta\":{\"baz\":\"qux\",\"foo\":\"bar\"}}\n", w.Body.String())
+
+	meta, err := launch.ReadMeta(filepath.Join(dir, "meta"))
+	assert.Nil(t, err)
+	assert.Equal(t, launch.Meta{"foo": "bar", "baz": "qux"}, meta)
+
+	w = request(s, http.MethodPut, "/v4/builds/1", testToken, `{`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = request(s, http.MethodDelete, "/v4/builds/1", testToken, "")
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+
+	w = request(s, http.MethodGet, "/v4/builds/1/steps", testToken, "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestServeCache(t *testing.T) {
+	s, dir, cleanup := newTestServer(t)
+	defer cleanup()
+
+	w := request(s, http.MethodPut, "/v1/caches/pipelines/1/node_modules.zip", testToken, "cache")
+	assert.Equal(t, http.StatusAccepted, w.Code)
+
+	buf, err := ioutil.ReadFile(filepath.Join(dir, "pipeline", "node_modules.zip"))
+	assert.Nil(t, err)
+	assert.Equal(t, "cache", string(buf))
+
+	w = request(s, http.MethodGet, "/v1/caches/pipelines/1/node_modules.zip", testToken, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "cache", w.Body.String())
+
+	w = request(s, http.MethodDelete, "/v1/caches/pipelines/1/node_modules.zip", testToken, "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	_, err = os.Stat(filepath.Join(dir, "pipeline", "node_modules.zip"))
+	assert.True(t, os.IsNotExist(err))
+
+	w = request(s, http.MethodGet, "/v1/caches/pipelines/1/node_modules.zip", testToken, "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = request(s, http.MethodGet, "/v1/caches/jobs/1/node_modules.zip", testToken, "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "{\"statusCode\":404,\"error\":\"Not Found\",\"message\":\"caches of scope jobs are not enabled\"}\n", w.Body.String())
+}
+
+func TestServeArtifact(t *testing.T) {
+	s, dir, cleanup := newTestServer(t)
+	defer cleanup()
+
+	w := request(s, http.MethodPut, "/v1/builds/1/ARTIFACTS/reports/test.xml", testToken, "<testsuites/>")
+	assert.Equal(t, http.StatusAccepted, w.Code)
+
+	buf, err := ioutil.ReadFile(filepath.Join(dir, "artifacts", "reports", "test.xml"))
+	assert.Nil(t, err)
+	assert.Equal(t, "<testsuites/>", string(buf))
+
+	w = request(s, http.MethodGet, "/v1/builds/1/ARTIFACTS/reports/test.xml", testToken, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "<testsuites/>", w.Body.String())
+
+	w = request(s, http.MethodGet, "/v1/builds/1/logs", testToken, "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestLocalPath(t *testing.T) {
+	testCases := []struct {
+		name string
+		dir  string
+		rest string
+		path string
+		ok   bool
+	}{
+		{name: "success", dir: "/cache", rest: "a/b.zip", path: filepath.Join("/cache", "a", "b.zip"), ok: true},
+		{name: "success with parent directory", dir: "/cache", rest: "../../etc/passwd", path: filepath.Join("/cache", "etc", "passwd"), ok: true},
+		{name: "failure with empty dir", dir: "", rest: "a.zip"},
+		{name: "failure with root", dir: "/cache", rest: ".."},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			path, ok := localPath(tt.dir, tt.rest)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.path, path)
+		})
+	}
+}
+
+func TestStart(t *testing.T) {
+	s, _, cleanup := newTestServer(t)
+	defer cleanup()
+
+	err := s.Start("127.0.0.1:0")
+	assert.Nil(t, err)
+	defer s.Close()
+	assert.NotEqual(t, 0, s.Port())
+
+	res, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d/v4/pipelines/1/badge", s.Port()))
+	assert.Nil(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+}
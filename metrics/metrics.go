@@ -0,0 +1,50 @@
+// Package metrics emits the metrics of the builds run by sd-local to Prometheus Pushgateway and StatsD,
+// so that the usage and the slowness of the local builds can be measured.
+package metrics
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/screwdriver-cd/sd-local/launch"
+)
+
+// Emitter sends the metrics of the builds run by a command.
+type Emitter interface {
+	Emit(builds []launch.Result) error
+}
+
+// duration converts the duration in seconds written in the results.
+func duration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// steps returns the steps of the build which ran. The skipped steps are not measured.
+func steps(b launch.Result) []launch.StepJSON {
+	ran := make([]launch.StepJSON, 0, len(b.Steps))
+	for _, s := range b.Steps {
+		if s.Status != launch.StepSkipped {
+			ran = append(ran, s)
+		}
+	}
+	return ran
+}
+
+// setup returns the time of the build spent out of the steps, such as pulling the images and starting the containers.
+func setup(b launch.Result) time.Duration {
+	d := duration(b.Duration)
+	for _, s := range b.Steps {
+		d -= duration(s.Duration)
+	}
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+var unsafeNameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+// sanitize replaces the characters which can not be used in the names of the metrics, such as : of PR jobs.
+func sanitize(name string) string {
+	return unsafeNameChars.ReplaceAllString(name, "_")
+}
@@ -0,0 +1,36 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/screwdriver-cd/sd-local/launch"
+	"github.com/stretchr/testify/assert"
+)
+
+var testBuilds = []launch.Result{
+	{Job: "main", Status: launch.StepSuccess, Duration: 30, Steps: []launch.StepJSON{
+		{Name: "install", Status: launch.StepSuccess, Duration: 12},
+		{Name: "test", Status: launch.StepSuccess, Duration: 8},
+		{Name: "publish", Status: launch.StepSkipped},
+	}},
+	{Job: "PR-1:test", Status: launch.StepFailure, Duration: 5},
+}
+
+func TestSetup(t *testing.T) {
+	assert.Equal(t, 10*time.Second, setup(testBuilds[0]))
+	assert.Equal(t, 5*time.Second, setup(testBuilds[1]))
+	assert.Equal(t, time.Duration(0), setup(launch.Result{Duration: 1, Steps: []launch.StepJSON{{Duration: 2}}}))
+}
+
+func TestSteps(t *testing.T) {
+	assert.Equal(t, []launch.StepJSON{
+		{Name: "install", Status: launch.StepSuccess, Duration: 12},
+		{Name: "test", Status: launch.StepSuccess, Duration: 8},
+	}, steps(testBuilds[0]))
+}
+
+func TestSanitize(t *testing.T) {
+	assert.Equal(t, "PR-1_test", sanitize("PR-1:test"))
+	assert.Equal(t, "sd-setup-init", sanitize("sd-setup-init"))
+}
@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/screwdriver-cd/sd-local/launch"
+)
+
+var timeNow = time.Now
+
+// pushTimeout is the time limit of pushing the metrics, so that the command does not hang after the builds
+const pushTimeout = 10 * time.Second
+
+type pushgateway struct {
+	url      string
+	instance string
+	client   *http.Client
+}
+
+// NewPushgateway returns the emitter which pushes the metrics to Prometheus Pushgateway at u,
+// into the group of job sd-local and the instance, which is the host running the builds.
+func NewPushgateway(u, instance string) Emitter {
+	return &pushgateway{url: u, instance: instance, client: &http.Client{Timeout: pushTimeout}}
+}
+
+// labelValue escapes the value of a label in the text format of Prometheus.
+func labelValue(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
+
+func writeMetric(w io.Writer, name, help, kind string, samples []string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+	for _, s := range samples {
+		fmt.Fprintf(w, "%s%s\n", name, s)
+	}
+}
+
+// exposition returns the metrics of the builds in the text format of Prometheus.
+func exposition(builds []launch.Result) []byte {
+	var durations, setups, successes, stepDurations []string
+	for _, b := range builds {
+		job := labelValue(b.Job)
+		durations = append(durations, fmt.Sprintf(`{sd_job="%s",status="%s"} %g`, job, labelValue(b.Status), duration(b.Duration).Seconds()))
+		setups = append(setups, fmt.Sprintf(`{sd_job="%s"} %g`, job, setup(b).Seconds()))
+		success := 0
+		if b.Status == launch.StepSuccess {
+			success = 1
+		}
+		successes = append(successes, fmt.Sprintf(`{sd_job="%s"} %d`, job, success))
+		for _, s := range steps(b) {
+			stepDurations = append(stepDurations, fmt.Sprintf(`{sd_job="%s",step="%s",status="%s"} %g`, job, labelValue(s.Name), labelValue(s.Status), duration(s.Duration).Seconds()))
+		}
+	}
+
+	buf := bytes.NewBuffer(nil)
+	writeMetric(buf, "sd_local_build_duration_seconds", "Duration of the last build of the job.", "gauge", durations)
+	writeMetric(buf, "sd_local_build_setup_seconds", "Time of the last build of the job spent out of the steps, such as pulling the images.", "gauge", setups)
+	writeMetric(buf, "sd_local_build_success", "Whether the last build of the job succeeded.", "gauge", successes)
+	writeMetric(buf, "sd_local_step_duration_seconds", "Duration of the steps of the last build of the job.", "gauge", stepDurations)
+	writeMetric(buf, "sd_local_build_last_timestamp_seconds", "Time when the last builds finished.", "gauge", []string{fmt.Sprintf(" %d", timeNow().Unix())})
+	return buf.Bytes()
+}
+
+func (p *pushgateway) Emit(builds []launch.Result) error {
+	endpoint := fmt.Sprintf("%s/metrics/job/sd-local/instance/%s", strings.TrimSuffix(p.url, "/"), url.PathEscape(p.instance))
+
+	// POST replaces only the metrics of the same names in the group, not the other metrics pushed into it
+	res, err := p.client.Post(endpoint, "text/plain; version=0.0.4", bytes.NewReader(exposition(builds)))
+	if err != nil {
+		return fmt.Errorf("failed to push metrics: %v", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode/100 != 2 {
+		return fmt.Errorf("failed to push metrics: StatusCode %d", res.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPushgatewayEmit(t *testing.T) {
+	defer func() {
+		timeNow = time.Now
+	}()
+	timeNow = func() time.Time { return time.Unix(1578614400, 0) }
+
+	t.Run("success", func(t *testing.T) {
+		var path, body string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			path = r.URL.EscapedPath()
+			b, _ := ioutil.ReadAll(r.Body)
+			body = string(b)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		err := NewPushgateway(server.URL+"/", "my host").Emit(testBuilds)
+		assert.Nil(t, err)
+		assert.Equal(t, "/metrics/job/sd-local/instance/my%20host", path)
+		assert.Equal(t, `# HELP sd_local_build_duration_seconds Duration of the last build of the job.
+# TYPE sd_local_build_duration_seconds gauge
+sd_local_build_duration_seconds{sd_job="main",status="SUCCESS"} 30
+sd_local_build_duration_seconds{sd_job="PR-1:test",status="FAILURE"} 5
+# HELP sd_local_build_setup_seconds Time of the last build of the job spent out of the steps, such as pulling the images.
+# TYPE sd_local_build_setup_seconds gauge
+sd_local_build_setup_seconds{sd_job="main"} 10
+sd_local_build_setup_seconds{sd_job="PR-1:test"} 5
+# HELP sd_local_build_success Whether the last build of the job succeeded.
+# TYPE sd_local_build_success gauge
+sd_local_build_success{sd_job="main"} 1
+sd_local_build_success{sd_job="PR-1:test"} 0
+# HELP sd_local_step_duration_seconds Duration of the steps of the last build of the job.
+# TYPE sd_local_step_duration_seconds gauge
+sd_local_step_duration_seconds{sd_job="main",step="install",status="SUCCESS"} 12
+sd_local_step_duration_seconds{sd_job="main",step="test",status="SUCCESS"} 8
+# HELP sd_local_build_last_timestamp_seconds Time when the last builds finished.
+# TYPE sd_local_build_last_timestamp_seconds gauge
+sd_local_build_last_timestamp_seconds 1578614400
+`, body)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer server.Close()
+
+		err := NewPushgateway(server.URL, "host").Emit(testBuilds)
+		assert.EqualError(t, err, "failed to push metrics: StatusCode 400")
+	})
+}
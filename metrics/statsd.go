@@ -0,0 +1,53 @@
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+
+	"github.com/screwdriver-cd/sd-local/launch"
+)
+
+type statsd struct {
+	addr string
+}
+
+// NewStatsD returns the emitter which sends the metrics to StatsD at addr (<host>:<port>) over UDP.
+func NewStatsD(addr string) Emitter {
+	return &statsd{addr: addr}
+}
+
+// lines returns the metrics of the build in the line protocol of StatsD.
+// The success rate is the count of sd_local.<job>.success divided by the count of sd_local.<job>.builds.
+func lines(b launch.Result) []byte {
+	prefix := "sd_local." + sanitize(b.Job)
+	buf := bytes.NewBuffer(nil)
+	fmt.Fprintf(buf, "%s.builds:1|c\n", prefix)
+	if b.Status == launch.StepSuccess {
+		fmt.Fprintf(buf, "%s.success:1|c\n", prefix)
+	} else {
+		fmt.Fprintf(buf, "%s.failure:1|c\n", prefix)
+	}
+	fmt.Fprintf(buf, "%s.duration:%d|ms\n", prefix, duration(b.Duration).Milliseconds())
+	fmt.Fprintf(buf, "%s.setup:%d|ms\n", prefix, setup(b).Milliseconds())
+	for _, s := range steps(b) {
+		fmt.Fprintf(buf, "%s.step.%s.duration:%d|ms\n", prefix, sanitize(s.Name), duration(s.Duration).Milliseconds())
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+}
+
+func (s *statsd) Emit(builds []launch.Result) error {
+	conn, err := net.Dial("udp", s.addr)
+	if err != nil {
+		return fmt.Errorf("failed to send metrics to StatsD: %v", err)
+	}
+	defer conn.Close()
+
+	// Each build is sent in a packet, which keeps the packets small enough for the MTU
+	for _, b := range builds {
+		if _, err := conn.Write(lines(b)); err != nil {
+			return fmt.Errorf("failed to send metrics to StatsD: %v", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package metrics
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatsDEmit(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	err = NewStatsD(conn.LocalAddr().String()).Emit(testBuilds)
+	assert.Nil(t, err)
+
+	var packets []string
+	buf := make([]byte, 1024)
+	for range testBuilds {
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		packets = append(packets, string(buf[:n]))
+	}
+
+	assert.Equal(t, []string{
+		"sd_local.main.builds:1|c\nsd_local.main.success:1|c\nsd_local.main.duration:30000|ms\nsd_local.main.setup:10000|ms\n" +
+			"sd_local.main.step.install.duration:12000|ms\nsd_local.main.step.test.duration:8000|ms",
+		"sd_local.PR-1_test.builds:1|c\nsd_local.PR-1_test.failure:1|c\nsd_local.PR-1_test.duration:5000|ms\nsd_local.PR-1_test.setup:5000|ms",
+	}, packets)
+}
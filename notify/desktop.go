@@ -0,0 +1,65 @@
+package notify
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/screwdriver-cd/sd-local/launch"
+)
+
+var (
+	execCommand = exec.Command
+	goos        = runtime.GOOS
+)
+
+type desktop struct{}
+
+// NewDesktop returns the notifier which shows the results of the builds in the notification center of the OS,
+// with osascript on macOS, notify-send on Linux and PowerShell on Windows.
+func NewDesktop() Notifier {
+	return desktop{}
+}
+
+// appleScriptQuote quotes s as a string of AppleScript.
+func appleScriptQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// powerShellQuote quotes s as a single quoted string of PowerShell.
+func powerShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+func desktopCommand(title, message string) (*exec.Cmd, error) {
+	switch goos {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptQuote(message), appleScriptQuote(title))
+		return execCommand("osascript", "-e", script), nil
+	case "linux":
+		return execCommand("notify-send", "--app-name=sd-local", title, message), nil
+	case "windows":
+		script := fmt.Sprintf(`Add-Type -AssemblyName System.Windows.Forms, System.Drawing; `+
+			`$n = New-Object System.Windows.Forms.NotifyIcon; $n.Icon = [System.Drawing.SystemIcons]::Information; $n.Visible = $true; `+
+			`$n.ShowBalloonTip(10000, %s, %s, 'Info'); Start-Sleep -Seconds 10; $n.Dispose()`, powerShellQuote(title), powerShellQuote(message))
+		return execCommand("powershell", "-NoProfile", "-NonInteractive", "-Command", script), nil
+	}
+	return nil, fmt.Errorf("desktop notifications are not supported on %s", goos)
+}
+
+func (d desktop) Notify(builds []launch.Result) error {
+	c, err := desktopCommand(Title(builds), Message(builds))
+	if err != nil {
+		return err
+	}
+
+	// The balloon of Windows is shown while PowerShell is running, so it is not waited
+	if goos == "windows" {
+		return c.Start()
+	}
+	if out, err := c.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to show desktop notification: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package notify
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDesktopCommand(t *testing.T) {
+	defer func() {
+		goos = runtime.GOOS
+	}()
+
+	testCases := []struct {
+		goos   string
+		expect []string
+	}{
+		{
+			goos:   "darwin",
+			expect: []string{"osascript", "-e", `display notification "main: SUCCESS in 42s \"quoted\"" with title "sd-local build succeeded"`},
+		},
+		{
+			goos:   "linux",
+			expect: []string{"notify-send", "--app-name=sd-local", "sd-local build succeeded", `main: SUCCESS in 42s "quoted"`},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.goos, func(t *testing.T) {
+			goos = tt.goos
+			c, err := desktopCommand("sd-local build succeeded", `main: SUCCESS in 42s "quoted"`)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expect, c.Args)
+		})
+	}
+
+	t.Run("windows", func(t *testing.T) {
+		goos = "windows"
+		c, err := desktopCommand("sd-local build failed", "main: FAILURE in 1s (step it's failed)")
+		assert.Nil(t, err)
+		assert.Equal(t, "powershell", c.Args[0])
+		assert.True(t, strings.Contains(c.Args[len(c.Args)-1], `ShowBalloonTip(10000, 'sd-local build failed', 'main: FAILURE in 1s (step it''s failed)', 'Info')`))
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		goos = "plan9"
+		_, err := desktopCommand("sd-local build succeeded", "main: SUCCESS in 42s")
+		assert.EqualError(t, err, "desktop notifications are not supported on plan9")
+	})
+}
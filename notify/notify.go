@@ -0,0 +1,57 @@
+// Package notify notifies the results of the builds run by sd-local on the desktop and with the webhooks of the chat services.
+package notify
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/screwdriver-cd/sd-local/launch"
+)
+
+// Notifier sends the results of the builds run by a command.
+type Notifier interface {
+	Notify(builds []launch.Result) error
+}
+
+// Status returns the status of the builds, which fails when any of them fails.
+func Status(builds []launch.Result) string {
+	for _, b := range builds {
+		if b.Status == launch.StepFailure {
+			return launch.StepFailure
+		}
+	}
+	return launch.StepSuccess
+}
+
+// failedStep returns the name of the step which failed the build, or empty if no step failed.
+func failedStep(b launch.Result) string {
+	for _, s := range b.Steps {
+		if s.Status == launch.StepFailure {
+			return s.Name
+		}
+	}
+	return ""
+}
+
+// Title returns the summary of the builds.
+func Title(builds []launch.Result) string {
+	if Status(builds) == launch.StepFailure {
+		return "sd-local build failed"
+	}
+	return "sd-local build succeeded"
+}
+
+// Message returns the result of each build in a line, such as "main: FAILURE in 12s (step test failed)".
+func Message(builds []launch.Result) string {
+	lines := make([]string, 0, len(builds))
+	for _, b := range builds {
+		duration := time.Duration(b.Duration * float64(time.Second))
+		line := fmt.Sprintf("%s: %s in %s", b.Job, b.Status, duration.Round(time.Second))
+		if step := failedStep(b); step != "" {
+			line += fmt.Sprintf(" (step %s failed)", step)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,31 @@
+package notify
+
+import (
+	"testing"
+
+	"github.com/screwdriver-cd/sd-local/launch"
+	"github.com/stretchr/testify/assert"
+)
+
+var testBuilds = []launch.Result{
+	{Job: "main", Status: launch.StepSuccess, Duration: 42.4},
+	{Job: "test", Status: launch.StepFailure, Duration: 75, Steps: []launch.StepJSON{
+		{Name: "install", Status: launch.StepSuccess},
+		{Name: "unit", Status: launch.StepFailure},
+		{Name: "lint", Status: launch.StepSkipped},
+	}},
+}
+
+func TestStatus(t *testing.T) {
+	assert.Equal(t, launch.StepFailure, Status(testBuilds))
+	assert.Equal(t, launch.StepSuccess, Status(testBuilds[:1]))
+}
+
+func TestTitle(t *testing.T) {
+	assert.Equal(t, "sd-local build failed", Title(testBuilds))
+	assert.Equal(t, "sd-local build succeeded", Title(testBuilds[:1]))
+}
+
+func TestMessage(t *testing.T) {
+	assert.Equal(t, "main: SUCCESS in 42s\ntest: FAILURE in 1m15s (step unit failed)", Message(testBuilds))
+}
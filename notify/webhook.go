@@ -0,0 +1,75 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/screwdriver-cd/sd-local/launch"
+)
+
+// The chat services whose incoming webhooks are supported
+const (
+	Slack   = "slack"
+	Teams   = "teams"
+	Discord = "discord"
+)
+
+// webhookTimeout is the time limit of posting to a webhook, so that the command does not hang after the builds
+const webhookTimeout = 10 * time.Second
+
+type webhook struct {
+	service string
+	url     string
+	client  *http.Client
+}
+
+// NewWebhook returns the notifier which posts the results of the builds to the incoming webhook of the chat service.
+func NewWebhook(service, url string) Notifier {
+	return &webhook{service: service, url: url, client: &http.Client{Timeout: webhookTimeout}}
+}
+
+// payload returns the message in the format of the incoming webhook of the service.
+func (w *webhook) payload(builds []launch.Result) interface{} {
+	text := Title(builds) + "\n" + Message(builds)
+	switch w.service {
+	case Discord:
+		return map[string]string{"content": text}
+	case Teams:
+		color := "2EB886"
+		if Status(builds) == launch.StepFailure {
+			color = "D00000"
+		}
+		return map[string]string{
+			"@type":      "MessageCard",
+			"@context":   "https://schema.org/extensions",
+			"summary":    Title(builds),
+			"themeColor": color,
+			"title":      Title(builds),
+			// The text of the card is Markdown, in which a line break needs a blank line
+			"text": strings.ReplaceAll(Message(builds), "\n", "\n\n"),
+		}
+	}
+	return map[string]string{"text": text}
+}
+
+func (w *webhook) Notify(builds []launch.Result) error {
+	body, err := json.Marshal(w.payload(builds))
+	if err != nil {
+		return err
+	}
+
+	res, err := w.client.Post(w.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to post to %s webhook: %v", w.service, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode/100 != 2 {
+		return fmt.Errorf("failed to post to %s webhook: StatusCode %d", w.service, res.StatusCode)
+	}
+	return nil
+}
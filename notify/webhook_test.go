@@ -0,0 +1,64 @@
+package notify
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookNotify(t *testing.T) {
+	testCases := []struct {
+		service string
+		expect  map[string]string
+	}{
+		{
+			service: Slack,
+			expect:  map[string]string{"text": "sd-local build failed\nmain: SUCCESS in 42s\ntest: FAILURE in 1m15s (step unit failed)"},
+		},
+		{
+			service: Discord,
+			expect:  map[string]string{"content": "sd-local build failed\nmain: SUCCESS in 42s\ntest: FAILURE in 1m15s (step unit failed)"},
+		},
+		{
+			service: Teams,
+			expect: map[string]string{
+				"@type":      "MessageCard",
+				"@context":   "https://schema.org/extensions",
+				"summary":    "sd-local build failed",
+				"themeColor": "D00000",
+				"title":      "sd-local build failed",
+				"text":       "main: SUCCESS in 42s\n\ntest: FAILURE in 1m15s (step unit failed)",
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.service, func(t *testing.T) {
+			var got map[string]string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+				assert.Nil(t, json.NewDecoder(r.Body).Decode(&got))
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			defer server.Close()
+
+			err := NewWebhook(tt.service, server.URL).Notify(testBuilds)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expect, got)
+		})
+	}
+
+	t.Run("failure", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		err := NewWebhook(Slack, server.URL).Notify(testBuilds)
+		assert.EqualError(t, err, "failed to post to slack webhook: StatusCode 404")
+	})
+}
@@ -0,0 +1,35 @@
+package scm
+
+import (
+	"strings"
+)
+
+// Checkout is the state of the local git checkout.
+type Checkout struct {
+	URL    string
+	Branch string
+	SHA    string
+}
+
+func gitOutput(dir string, args ...string) string {
+	out, err := execCommand("git", append([]string{"-C", dir}, args...)...).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// ReadCheckout reads the remote URL, the branch and the commit of the git checkout in dir.
+// The values which can not be read (e.g. dir is not a git checkout, or HEAD is detached) are left empty.
+func ReadCheckout(dir string) Checkout {
+	branch := gitOutput(dir, "rev-parse", "--abbrev-ref", "HEAD")
+	if branch == "HEAD" {
+		branch = ""
+	}
+
+	return Checkout{
+		URL:    gitOutput(dir, "config", "--get", "remote.origin.url"),
+		Branch: branch,
+		SHA:    gitOutput(dir, "rev-parse", "HEAD"),
+	}
+}
@@ -0,0 +1,56 @@
+package scm
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func git(t *testing.T, dir string, args ...string) string {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com", "GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("failed to run git %v: %s", args, out)
+	}
+	return string(out)
+}
+
+func TestReadCheckout(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git command is not found")
+	}
+	execCommand = exec.Command
+
+	dir, err := ioutil.TempDir("", "sd-local-git")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	t.Run("success without git checkout", func(t *testing.T) {
+		assert.Equal(t, Checkout{}, ReadCheckout(dir))
+	})
+
+	git(t, dir, "init", "-q")
+	git(t, dir, "checkout", "-q", "-b", "feature")
+	git(t, dir, "commit", "-q", "--allow-empty", "-m", "init")
+	git(t, dir, "remote", "add", "origin", "git@github.com:screwdriver-cd/sd-local.git")
+	sha := git(t, dir, "rev-parse", "HEAD")
+
+	t.Run("success", func(t *testing.T) {
+		assert.Equal(t, Checkout{
+			URL:    "git@github.com:screwdriver-cd/sd-local.git",
+			Branch: "feature",
+			SHA:    sha[:len(sha)-1],
+		}, ReadCheckout(dir))
+	})
+
+	t.Run("success with detached HEAD", func(t *testing.T) {
+		git(t, dir, "checkout", "-q", "--detach")
+		assert.Equal(t, "", ReadCheckout(dir).Branch)
+	})
+}
@@ -31,6 +31,14 @@ type SCM interface {
 	LocalPath() string
 }
 
+// CloneOption is the option of cloning the remote repository.
+type CloneOption struct {
+	// Shallow makes the clone have only the latest commit.
+	Shallow bool
+	// SHA is the commit to check out instead of the head of the branch.
+	SHA string
+}
+
 type scm struct {
 	baseDir   string
 	remoteURL string
@@ -38,10 +46,11 @@ type scm struct {
 	localPath string
 	commands  []*exec.Cmd
 	sudo      bool
+	clone     CloneOption
 }
 
 // New create new SCM instance
-func New(baseDir, srcURL string, sudo bool) (SCM, error) {
+func New(baseDir, srcURL string, sudo bool, clone CloneOption) (SCM, error) {
 	results := srcURLRegex.FindStringSubmatch(srcURL)
 
 	if len(results) == 0 {
@@ -57,6 +66,7 @@ func New(baseDir, srcURL string, sudo bool) (SCM, error) {
 		localPath: filepath.Join(baseDir, "repo", strconv.Itoa(rand.Int())),
 		commands:  make([]*exec.Cmd, 0, 10),
 		sudo:      sudo,
+		clone:     clone,
 	}
 
 	err := osMkdirAll(s.LocalPath(), 0777)
@@ -67,20 +77,44 @@ func New(baseDir, srcURL string, sudo bool) (SCM, error) {
 	return s, nil
 }
 
+func (s *scm) git(args ...string) error {
+	cmd := execCommand("git", args...)
+	s.commands = append(s.commands, cmd)
+	return cmd.Run()
+}
+
 func (s *scm) Pull() error {
 	args := []string{"clone"}
 	if s.branch != "" {
 		args = append(args, "-b", s.branch)
 	}
+	if s.clone.Shallow {
+		args = append(args, "--depth", "1")
+	}
 	args = append(args, s.remoteURL, s.LocalPath())
 
-	cmd := execCommand("git", args...)
-	s.commands = append(s.commands, cmd)
-	err := cmd.Run()
+	err := s.git(args...)
 	if err != nil {
 		return fmt.Errorf("failed to clone remote repository: %w", err)
 	}
 
+	if s.clone.SHA == "" {
+		return nil
+	}
+
+	// The commit may not be in the shallow clone
+	if s.clone.Shallow {
+		err = s.git("-C", s.LocalPath(), "fetch", "--depth", "1", "origin", s.clone.SHA)
+		if err != nil {
+			return fmt.Errorf("failed to fetch commit %s: %w", s.clone.SHA, err)
+		}
+	}
+
+	err = s.git("-C", s.LocalPath(), "checkout", "-q", s.clone.SHA)
+	if err != nil {
+		return fmt.Errorf("failed to check out commit %s: %w", s.clone.SHA, err)
+	}
+
 	return nil
 }
 
@@ -17,9 +17,10 @@ import (
 )
 
 type fakeExecCommand struct {
-	id      string
-	execCmd func(command string, args ...string) *exec.Cmd
-	command string
+	id       string
+	execCmd  func(command string, args ...string) *exec.Cmd
+	command  string
+	commands []string
 }
 
 const (
@@ -31,6 +32,7 @@ func newFakeExecCommand(id string) *fakeExecCommand {
 	c.id = id
 	c.execCmd = func(name string, args ...string) *exec.Cmd {
 		c.command = fmt.Sprintf("%s %s", name, strings.Join(args, " "))
+		c.commands = append(c.commands, c.command)
 		cs := []string{"-test.run=TestHelperProcess", "--", name}
 		cs = append(cs, args...)
 		cmd := exec.Command(os.Args[0], cs...)
@@ -45,7 +47,7 @@ func TestNew(t *testing.T) {
 		baseDir := os.TempDir()
 		srcURL := "https://github.com/screwdriver-cd/sd-local.git#test"
 
-		s, err := New(baseDir, srcURL, false)
+		s, err := New(baseDir, srcURL, false, CloneOption{})
 		defer os.RemoveAll(s.LocalPath())
 
 		scm := s.(*scm)
@@ -62,7 +64,7 @@ func TestNew(t *testing.T) {
 		baseDir := os.TempDir()
 		srcURL := "git@github.com:screwdriver-cd/sd-local.git#branch#test"
 
-		s, err := New(baseDir, srcURL, false)
+		s, err := New(baseDir, srcURL, false, CloneOption{})
 		defer os.RemoveAll(s.LocalPath())
 
 		scm := s.(*scm)
@@ -81,7 +83,7 @@ func TestNew(t *testing.T) {
 		baseDir := os.TempDir()
 		srcURL := "https://github.com/screwdriver-cd/sd-local.git#test"
 
-		s, err := New(baseDir, srcURL, false)
+		s, err := New(baseDir, srcURL, false, CloneOption{})
 		msg := err.Error()
 
 		assert.Nil(t, s)
@@ -94,7 +96,7 @@ func TestNew(t *testing.T) {
 		baseDir := os.TempDir()
 		srcURL := "https://github.com/screwdriver-cd"
 
-		s, err := New(baseDir, srcURL, false)
+		s, err := New(baseDir, srcURL, false, CloneOption{})
 
 		assert.Nil(t, s)
 		assert.Equal(t, err.Error(), "failed to fetch source code with invalid URL: https://github.com/screwdriver-cd")
@@ -284,6 +286,50 @@ func TestPull(t *testing.T) {
 		assert.Equal(t, fmt.Sprintf("git clone https://github.com/screwdriver-cd/sd-local.git %s", s.LocalPath()), c.command)
 	})
 
+	t.Run("success with shallow clone and sha", func(t *testing.T) {
+		baseDir := os.TempDir()
+		defer os.RemoveAll(filepath.Join(baseDir, "repo"))
+		s := &scm{
+			baseDir:   baseDir,
+			remoteURL: "https://github.com/screwdriver-cd/sd-local.git",
+			localPath: filepath.Join(baseDir, "repo/test"),
+			clone:     CloneOption{Shallow: true, SHA: "abc123"},
+		}
+		c := newFakeExecCommand("SUCCESS_PULL")
+		execCommand = c.execCmd
+		os.MkdirAll(s.LocalPath(), 0777)
+
+		err := s.Pull()
+		assert.Nil(t, err)
+		assert.Equal(t, []string{
+			fmt.Sprintf("git clone --depth 1 https://github.com/screwdriver-cd/sd-local.git %s", s.LocalPath()),
+			fmt.Sprintf("git -C %s fetch --depth 1 origin abc123", s.LocalPath()),
+			fmt.Sprintf("git -C %s checkout -q abc123", s.LocalPath()),
+		}, c.commands)
+	})
+
+	t.Run("success with sha", func(t *testing.T) {
+		baseDir := os.TempDir()
+		defer os.RemoveAll(filepath.Join(baseDir, "repo"))
+		s := &scm{
+			baseDir:   baseDir,
+			remoteURL: "https://github.com/screwdriver-cd/sd-local.git",
+			branch:    "test",
+			localPath: filepath.Join(baseDir, "repo/test"),
+			clone:     CloneOption{SHA: "abc123"},
+		}
+		c := newFakeExecCommand("SUCCESS_PULL")
+		execCommand = c.execCmd
+		os.MkdirAll(s.LocalPath(), 0777)
+
+		err := s.Pull()
+		assert.Nil(t, err)
+		assert.Equal(t, []string{
+			fmt.Sprintf("git clone -b test https://github.com/screwdriver-cd/sd-local.git %s", s.LocalPath()),
+			fmt.Sprintf("git -C %s checkout -q abc123", s.LocalPath()),
+		}, c.commands)
+	})
+
 	t.Run("failed to pull image", func(t *testing.T) {
 		s := &scm{
 			remoteURL: "https://github.com/screwdriver-cd/sd-local.git",
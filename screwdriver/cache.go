@@ -0,0 +1,32 @@
+package screwdriver
+
+import (
+	"fmt"
+
+	"github.com/go-yaml/yaml"
+)
+
+// Cache is the cache settings in screwdriver.yaml
+type Cache struct {
+	Pipeline []string            `yaml:"pipeline"`
+	Event    []string            `yaml:"event"`
+	Job      map[string][]string `yaml:"job"`
+}
+
+// ReadCache reads the cache settings in screwdriver.yaml
+func ReadCache(filePath string) (Cache, error) {
+	raw, err := readScrewdriverYAML(filePath)
+	if err != nil {
+		return Cache{}, err
+	}
+
+	c := struct {
+		Cache Cache `yaml:"cache"`
+	}{}
+	err = yaml.Unmarshal([]byte(raw), &c)
+	if err != nil {
+		return Cache{}, fmt.Errorf("failed to parse cache in screwdriver.yaml: %v", err)
+	}
+
+	return c.Cache, nil
+}
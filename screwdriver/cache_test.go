@@ -0,0 +1,31 @@
+package screwdriver
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadCache(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		cache, err := ReadCache(filepath.Join(testDir, "screwdriverCache.yaml"))
+		assert.Nil(t, err)
+		assert.Equal(t, Cache{
+			Pipeline: []string{"~/.gradle"},
+			Event:    []string{"$SD_SOURCE_DIR/dist"},
+			Job:      map[string][]string{"main": {"$SD_SOURCE_DIR/node_modules"}},
+		}, cache)
+	})
+
+	t.Run("success without cache", func(t *testing.T) {
+		cache, err := ReadCache(filepath.Join(testDir, "screwdriver.yaml"))
+		assert.Nil(t, err)
+		assert.Equal(t, Cache{}, cache)
+	})
+
+	t.Run("failure by reading screwdriver.yaml", func(t *testing.T) {
+		_, err := ReadCache(filepath.Join(testDir, "none.yaml"))
+		assert.Contains(t, err.Error(), "failed to read screwdriver.yaml")
+	})
+}
@@ -0,0 +1,91 @@
+package screwdriver
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Option is the settings of the client of Screwdriver.cd API
+type Option struct {
+	// Retries is the number of times the requests are retried on transient failures, which are not retried when it is 0
+	Retries int
+	// RetryWait is the wait before the first retry, which is doubled on each retry
+	RetryWait time.Duration
+	// RetryMaxWait is the maximum wait between the retries, which also limits Retry-After
+	RetryMaxWait time.Duration
+	// Timeout is the time limit of each request, which is not limited when it is 0
+	Timeout time.Duration
+	// KeepAlive is how long the idle connections are kept to be reused
+	KeepAlive time.Duration
+	// DisableKeepAlives makes each request use a new connection
+	DisableKeepAlives bool
+	// Proxy is the proxy of all the requests, which overrides the proxy of the environment variables
+	Proxy *url.URL
+	// TokenType is the type of the token, which is either user or pipeline
+	TokenType string
+	// JWTCacheDir is the directory storing JWT to be reused until it expires, which is not stored when it is empty
+	JWTCacheDir string
+	// TLSConfig is the TLS settings such as the CA certificates, which are the ones of the system when it is nil
+	TLSConfig *tls.Config
+}
+
+// newHTTPClient returns the client with the timeout, the keep-alive and the proxy of option.
+// The proxy is chosen with $HTTP_PROXY, $HTTPS_PROXY and $NO_PROXY unless it is set in option.
+func newHTTPClient(option Option) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	if option.Proxy != nil {
+		transport.Proxy = http.ProxyURL(option.Proxy)
+	}
+	if option.DisableKeepAlives {
+		transport.DisableKeepAlives = true
+	} else if option.KeepAlive > 0 {
+		transport.IdleConnTimeout = option.KeepAlive
+	}
+
+	if option.TLSConfig != nil {
+		transport.TLSClientConfig = option.TLSConfig
+	}
+
+	return &http.Client{Transport: transport, Timeout: option.Timeout}
+}
+
+// NewTLSConfig returns the TLS settings which trust the CA certificates in caFile in addition to the ones of the system,
+// and send the client certificate in certFile and keyFile. The empty files are not used.
+// With insecure, the certificate of the server is not verified at all.
+func NewTLSConfig(caFile, certFile, keyFile string, insecure bool) (*tls.Config, error) {
+	config := &tls.Config{InsecureSkipVerify: insecure}
+
+	if caFile != "" {
+		pem, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificate: %v", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("failed to read CA certificate: no certificates in %s", caFile)
+		}
+		config.RootCAs = pool
+	}
+
+	if certFile != "" || keyFile != "" {
+		if certFile == "" || keyFile == "" {
+			return nil, fmt.Errorf("both of client certificate and its key must be set")
+		}
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client certificate: %v", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
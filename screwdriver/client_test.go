@@ -0,0 +1,183 @@
+package screwdriver
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewHTTPClient(t *testing.T) {
+	proxy := &url.URL{Scheme: "http", Host: "proxy.example.com:8080"}
+
+	testCases := []struct {
+		name              string
+		option            Option
+		timeout           time.Duration
+		idleConnTimeout   time.Duration
+		disableKeepAlives bool
+		proxy             *url.URL
+	}{
+		{
+			name:            "default",
+			option:          Option{},
+			idleConnTimeout: 90 * time.Second,
+		},
+		{
+			name:            "with timeout and keep-alive",
+			option:          Option{Timeout: 30 * time.Second, KeepAlive: 2 * time.Minute},
+			timeout:         30 * time.Second,
+			idleConnTimeout: 2 * time.Minute,
+		},
+		{
+			name:              "without keep-alive",
+			option:            Option{DisableKeepAlives: true},
+			idleConnTimeout:   90 * time.Second,
+			disableKeepAlives: true,
+		},
+		{
+			name:            "with proxy",
+			option:          Option{Proxy: proxy},
+			idleConnTimeout: 90 * time.Second,
+			proxy:           proxy,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newHTTPClient(tt.option)
+			assert.Equal(t, tt.timeout, client.Timeout)
+
+			transport, ok := client.Transport.(*http.Transport)
+			assert.True(t, ok)
+			assert.True(t, transport != http.DefaultTransport)
+			assert.NotNil(t, transport.Proxy)
+			assert.Equal(t, tt.idleConnTimeout, transport.IdleConnTimeout)
+			assert.Equal(t, tt.disableKeepAlives, transport.DisableKeepAlives)
+
+			if tt.proxy != nil {
+				req, err := http.NewRequest(http.MethodGet, "https://api.screwdriver.cd/v4/validator", nil)
+				assert.Nil(t, err)
+				got, err := transport.Proxy(req)
+				assert.Nil(t, err)
+				assert.Equal(t, tt.proxy, got)
+			}
+		})
+	}
+}
+
+// writePEM writes the PEM block into the file in dir, and returns the path of the file
+func writePEM(t *testing.T, dir, name, blockType string, der []byte) string {
+	t.Helper()
+
+	p := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(p, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// writeClientCert writes a self-signed client certificate and its key, and returns their paths
+func writeClientCert(t *testing.T, dir string) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sd-local"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return writePEM(t, dir, "client.crt", "CERTIFICATE", cert), writePEM(t, dir, "client.key", "EC PRIVATE KEY", keyDER)
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"token":"jwt"}`)
+	}))
+	defer server.Close()
+
+	caFile := writePEM(t, dir, "ca.crt", "CERTIFICATE", server.Certificate().Raw)
+	certFile, keyFile := writeClientCert(t, dir)
+	invalidFile := filepath.Join(dir, "invalid.crt")
+	assert.Nil(t, ioutil.WriteFile(invalidFile, []byte("invalid"), 0600))
+
+	testCases := []struct {
+		name      string
+		caFile    string
+		certFile  string
+		keyFile   string
+		insecure  bool
+		err       error
+		verifyErr bool
+	}{
+		{name: "success with CA certificate", caFile: caFile},
+		{name: "success with insecure", insecure: true},
+		{name: "success with client certificate", caFile: caFile, certFile: certFile, keyFile: keyFile},
+		{name: "failure without CA certificate", verifyErr: true},
+		{
+			name:   "failure with invalid CA certificate",
+			caFile: invalidFile,
+			err:    fmt.Errorf("failed to read CA certificate: no certificates in %s", invalidFile),
+		},
+		{
+			name:     "failure without key of client certificate",
+			certFile: certFile,
+			err:      fmt.Errorf("both of client certificate and its key must be set"),
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := NewTLSConfig(tt.caFile, tt.certFile, tt.keyFile, tt.insecure)
+			assert.Equal(t, tt.err, err)
+			if tt.err != nil {
+				return
+			}
+			if tt.certFile != "" {
+				assert.Len(t, config.Certificates, 1)
+			}
+
+			api := New(server.URL, "token", Option{TLSConfig: config})
+			err = api.InitJWT()
+			if tt.verifyErr {
+				assert.NotNil(t, err)
+				assert.False(t, IsUnreachable(err))
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, "jwt", api.JWT())
+			}
+		})
+	}
+}
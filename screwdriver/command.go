@@ -0,0 +1,156 @@
+package screwdriver
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+const (
+	commandsEndpoint         = "commands"
+	commandValidatorEndpoint = "validator/command"
+	binaryCommandFormat      = "binary"
+)
+
+// Command is a shared command run with sd-cmd
+type Command struct {
+	Name    string
+	Version string
+	Format  string
+}
+
+// commandSpec is the fields of the spec of a command such as sd-command.yaml, which are needed to publish it
+type commandSpec struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	Format    string `json:"format"`
+	Binary    struct {
+		File string `json:"file"`
+	} `json:"binary"`
+}
+
+func (s commandSpec) command() Command {
+	return Command{Name: s.Namespace + "/" + s.Name, Version: s.Version, Format: s.Format}
+}
+
+type commandValidatorResponse struct {
+	Errors  []json.RawMessage `json:"errors"`
+	Command json.RawMessage   `json:"command"`
+}
+
+// validateCommand returns the spec of the command parsed by Screwdriver.cd API
+func (sd *sdAPI) validateCommand(filePath string) (json.RawMessage, commandSpec, error) {
+	raw, err := readSpecFile(filePath)
+	if err != nil {
+		return nil, commandSpec{}, err
+	}
+
+	v := new(commandValidatorResponse)
+	if err := sd.post(commandValidatorEndpoint, "command validator", map[string]string{"yaml": string(raw)}, http.StatusOK, v); err != nil {
+		return nil, commandSpec{}, err
+	}
+	if len(v.Errors) != 0 {
+		return nil, commandSpec{}, fmt.Errorf("command is invalid: %v", validationMessages(v.Errors))
+	}
+
+	spec := commandSpec{}
+	if err := json.Unmarshal(v.Command, &spec); err != nil {
+		return nil, commandSpec{}, fmt.Errorf("failed to parse command validator response: %v", err)
+	}
+
+	return v.Command, spec, nil
+}
+
+func readSpecFile(filePath string) ([]byte, error) {
+	raw, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read command spec: %v", err)
+	}
+	return raw, nil
+}
+
+// ValidateCommand validates the spec of the command such as sd-command.yaml with Screwdriver.cd API
+func (sd *sdAPI) ValidateCommand(filePath string) (Command, error) {
+	_, spec, err := sd.validateCommand(filePath)
+	if err != nil {
+		return Command{}, err
+	}
+
+	return spec.command(), nil
+}
+
+// PublishCommand publishes the command of the spec such as sd-command.yaml as sd-cmd does, and returns the published command with its version.
+// The binary of the command in binary format is read from the path relative to the spec.
+func (sd *sdAPI) PublishCommand(filePath string) (Command, error) {
+	raw, spec, err := sd.validateCommand(filePath)
+	if err != nil {
+		return Command{}, err
+	}
+
+	body := bytes.NewBuffer(nil)
+	w := multipart.NewWriter(body)
+	if err := w.WriteField("spec", string(raw)); err != nil {
+		return Command{}, fmt.Errorf("failed to encode command request: %v", err)
+	}
+	if spec.Format == binaryCommandFormat {
+		binaryPath := spec.Binary.File
+		if !filepath.IsAbs(binaryPath) {
+			binaryPath = filepath.Join(filepath.Dir(filePath), binaryPath)
+		}
+		if err := writeFormFile(w, "file", binaryPath); err != nil {
+			return Command{}, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return Command{}, fmt.Errorf("failed to encode command request: %v", err)
+	}
+
+	fullpath, err := sd.makeURL(commandsEndpoint)
+	if err != nil {
+		return Command{}, fmt.Errorf("failed to make request url: %v", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, fullpath.String(), body)
+	if err != nil {
+		return Command{}, fmt.Errorf("failed to send request: %w", err)
+	}
+	req.Header.Add("Content-Type", w.FormDataContentType())
+	req.Header.Add("Authorization", "Bearer "+sd.SDJWT)
+
+	res, err := sd.HTTPClient.Do(req)
+	if err != nil {
+		return Command{}, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	published := commandSpec{}
+	if err := decodePostResponse(res, "command", http.StatusCreated, &published); err != nil {
+		return Command{}, err
+	}
+
+	return published.command(), nil
+}
+
+func writeFormFile(w *multipart.Writer, field, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to read binary of command: %v", err)
+	}
+	defer f.Close()
+
+	part, err := w.CreateFormFile(field, filepath.Base(path))
+	if err != nil {
+		return fmt.Errorf("failed to encode command request: %v", err)
+	}
+	if _, err := io.Copy(part, f); err != nil {
+		return fmt.Errorf("failed to read binary of command: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package screwdriver
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommand(t *testing.T) {
+	validated := map[string]string{
+		"binary":  `{"errors":[],"command":{"namespace":"sd","name":"hello","version":"1.0","format":"binary","binary":{"file":"./hello.sh"}}}`,
+		"habitat": `{"errors":[],"command":{"namespace":"sd","name":"node","version":"2.0","format":"habitat","habitat":{"mode":"remote","package":"core/node","command":"node"}}}`,
+		"invalid": `{"errors":[{"message":"\"format\" is required"}],"command":{}}`,
+		"missing": `{"errors":[],"command":{"namespace":"sd","name":"missing","version":"1.0","format":"binary","binary":{"file":"./missing.sh"}}}`,
+	}
+
+	var publishedSpec, publishedFile string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		validateHeader(t, "Authorization", "Bearer jwt", r)
+
+		switch r.URL.Path {
+		case "/v4/validator/command":
+			body := map[string]string{}
+			json.NewDecoder(r.Body).Decode(&body)
+			w.WriteHeader(200)
+			w.Write([]byte(validated[body["yaml"]]))
+		case "/v4/commands":
+			publishedSpec = r.FormValue("spec")
+			publishedFile = ""
+			if f, _, err := r.FormFile("file"); err == nil {
+				buf, _ := ioutil.ReadAll(f)
+				publishedFile = string(buf)
+			}
+			w.WriteHeader(201)
+			w.Write([]byte(`{"id":1,"namespace":"sd","name":"hello","version":"1.0.2","format":"binary"}`))
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "command")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"binary", "habitat", "invalid", "missing"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name+".yaml"), []byte(name), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "hello.sh"), []byte("echo hello"), 0777); err != nil {
+		t.Fatal(err)
+	}
+
+	testAPI := sdAPI{HTTPClient: http.DefaultClient, APIURL: server.URL, SDJWT: "jwt"}
+
+	t.Run("success with validating", func(t *testing.T) {
+		c, err := testAPI.ValidateCommand(filepath.Join(dir, "habitat.yaml"))
+		assert.Nil(t, err)
+		assert.Equal(t, Command{Name: "sd/node", Version: "2.0", Format: "habitat"}, c)
+	})
+
+	t.Run("success with publishing binary", func(t *testing.T) {
+		c, err := testAPI.PublishCommand(filepath.Join(dir, "binary.yaml"))
+		assert.Nil(t, err)
+		assert.Equal(t, Command{Name: "sd/hello", Version: "1.0.2", Format: "binary"}, c)
+		assert.Equal(t, `{"namespace":"sd","name":"hello","version":"1.0","format":"binary","binary":{"file":"./hello.sh"}}`, publishedSpec)
+		assert.Equal(t, "echo hello", publishedFile)
+	})
+
+	t.Run("success with publishing habitat", func(t *testing.T) {
+		_, err := testAPI.PublishCommand(filepath.Join(dir, "habitat.yaml"))
+		assert.Nil(t, err)
+		assert.Contains(t, publishedSpec, `"package":"core/node"`)
+		assert.Equal(t, "", publishedFile)
+	})
+
+	t.Run("failure by invalid command", func(t *testing.T) {
+		_, err := testAPI.PublishCommand(filepath.Join(dir, "invalid.yaml"))
+		assert.Equal(t, `command is invalid: ["format" is required]`, err.Error())
+	})
+
+	t.Run("failure by not found binary", func(t *testing.T) {
+		_, err := testAPI.PublishCommand(filepath.Join(dir, "missing.yaml"))
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "failed to read binary of command")
+	})
+
+	t.Run("failure by not found spec", func(t *testing.T) {
+		_, err := testAPI.ValidateCommand(filepath.Join(dir, "notFound.yaml"))
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "failed to read command spec")
+	})
+}
@@ -0,0 +1,169 @@
+package screwdriver
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Kinds of the changes
+const (
+	Added   = "+"
+	Removed = "-"
+	Changed = "~"
+)
+
+// Change is a difference of a field, a step or an environment variable of a job.
+// Old is the value in the remote pipeline and New is the value in the local screwdriver.yaml.
+type Change struct {
+	Kind string
+	Name string
+	Old  string
+	New  string
+}
+
+// JobDiff is the differences of a job between the remote pipeline and the local screwdriver.yaml
+type JobDiff struct {
+	Kind        string
+	Name        string
+	Fields      []Change
+	Steps       []Change
+	Environment []Change
+}
+
+func diffValue(name, before, after string) []Change {
+	if before == after {
+		return nil
+	}
+	return []Change{{Kind: Changed, Name: name, Old: before, New: after}}
+}
+
+func listValue(list []string) string {
+	return "[" + strings.Join(list, ", ") + "]"
+}
+
+func sortedValue(list []string) string {
+	sorted := append([]string{}, list...)
+	sort.Strings(sorted)
+	return listValue(sorted)
+}
+
+// diffSteps returns the added, removed and changed steps, and the change of the order of the steps kept in both.
+func diffSteps(before, after []Step) []Change {
+	oldCommands := make(map[string]string, len(before))
+	for _, s := range before {
+		oldCommands[s.Name] = s.Command
+	}
+	newCommands := make(map[string]string, len(after))
+	for _, s := range after {
+		newCommands[s.Name] = s.Command
+	}
+
+	changes := make([]Change, 0)
+	oldOrder := make([]string, 0, len(before))
+	for _, s := range before {
+		command, ok := newCommands[s.Name]
+		if !ok {
+			changes = append(changes, Change{Kind: Removed, Name: s.Name, Old: s.Command})
+			continue
+		}
+		oldOrder = append(oldOrder, s.Name)
+		if command != s.Command {
+			changes = append(changes, Change{Kind: Changed, Name: s.Name, Old: s.Command, New: command})
+		}
+	}
+
+	newOrder := make([]string, 0, len(after))
+	for _, s := range after {
+		if _, ok := oldCommands[s.Name]; !ok {
+			changes = append(changes, Change{Kind: Added, Name: s.Name, New: s.Command})
+			continue
+		}
+		newOrder = append(newOrder, s.Name)
+	}
+
+	if listValue(oldOrder) != listValue(newOrder) {
+		changes = append(changes, Change{Kind: Changed, Name: "(order)", Old: listValue(oldOrder), New: listValue(newOrder)})
+	}
+
+	return changes
+}
+
+func diffEnvironment(before, after map[string]string) []Change {
+	names := make([]string, 0, len(before)+len(after))
+	for name := range before {
+		names = append(names, name)
+	}
+	for name := range after {
+		if _, ok := before[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	changes := make([]Change, 0)
+	for _, name := range names {
+		o, inOld := before[name]
+		n, inNew := after[name]
+		switch {
+		case !inNew:
+			changes = append(changes, Change{Kind: Removed, Name: name, Old: o})
+		case !inOld:
+			changes = append(changes, Change{Kind: Added, Name: name, New: n})
+		case o != n:
+			changes = append(changes, Change{Kind: Changed, Name: name, Old: o, New: n})
+		}
+	}
+
+	return changes
+}
+
+// DiffJobs returns the differences of the jobs from remote to local sorted by the job name.
+// The jobs without differences are not included.
+func DiffJobs(remote, local map[string]Job) []JobDiff {
+	names := make([]string, 0, len(remote)+len(local))
+	for name := range remote {
+		names = append(names, name)
+	}
+	for name := range local {
+		if _, ok := remote[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	diffs := make([]JobDiff, 0)
+	for _, name := range names {
+		before, inRemote := remote[name]
+		after, inLocal := local[name]
+		switch {
+		case !inLocal:
+			diffs = append(diffs, JobDiff{Kind: Removed, Name: name})
+		case !inRemote:
+			diffs = append(diffs, JobDiff{Kind: Added, Name: name})
+		default:
+			d := JobDiff{Kind: Changed, Name: name}
+			d.Fields = append(d.Fields, diffValue("image", before.Image, after.Image)...)
+			d.Fields = append(d.Fields, diffValue("requires", sortedValue(before.Requires), sortedValue(after.Requires))...)
+			d.Fields = append(d.Fields, diffValue("secrets", sortedValue(before.Secrets), sortedValue(after.Secrets))...)
+			d.Steps = diffSteps(before.Steps, after.Steps)
+			d.Environment = diffEnvironment(before.Environment, after.Environment)
+			if len(d.Fields)+len(d.Steps)+len(d.Environment) != 0 {
+				diffs = append(diffs, d)
+			}
+		}
+	}
+
+	return diffs
+}
+
+// String returns the change in the form of "+ name: new", "- name: old" or "~ name: old -> new"
+func (c Change) String() string {
+	switch c.Kind {
+	case Added:
+		return fmt.Sprintf("%s %s: %s", c.Kind, c.Name, c.New)
+	case Removed:
+		return fmt.Sprintf("%s %s: %s", c.Kind, c.Name, c.Old)
+	}
+	return fmt.Sprintf("%s %s: %s -> %s", c.Kind, c.Name, c.Old, c.New)
+}
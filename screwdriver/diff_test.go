@@ -0,0 +1,60 @@
+package screwdriver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffJobs(t *testing.T) {
+	remote := map[string]Job{
+		"main": {
+			Image:       "node:10",
+			Requires:    []string{"~pr", "~commit"},
+			Steps:       []Step{{Name: "install", Command: "npm install"}, {Name: "test", Command: "npm test"}, {Name: "lint", Command: "npm run lint"}},
+			Environment: map[string]string{"NODE_ENV": "test", "DEBUG": "1", "CI": "true"},
+		},
+		"publish": {Image: "alpine", Requires: []string{"main"}, Secrets: []string{"NPM_TOKEN"}},
+		"old":     {Image: "alpine"},
+	}
+	local := map[string]Job{
+		"main": {
+			Image:       "node:12",
+			Requires:    []string{"~commit", "~pr"},
+			Steps:       []Step{{Name: "test", Command: "npm run test:ci"}, {Name: "install", Command: "npm install"}, {Name: "coverage", Command: "npm run cover"}},
+			Environment: map[string]string{"NODE_ENV": "ci", "CI": "true", "TZ": "UTC"},
+		},
+		"publish": {Image: "alpine", Requires: []string{"main"}, Secrets: []string{"NPM_TOKEN"}},
+		"deploy":  {Image: "alpine", Requires: []string{"publish"}},
+	}
+
+	want := []JobDiff{
+		{Kind: Added, Name: "deploy"},
+		{
+			Kind:   Changed,
+			Name:   "main",
+			Fields: []Change{{Kind: Changed, Name: "image", Old: "node:10", New: "node:12"}},
+			Steps: []Change{
+				{Kind: Changed, Name: "test", Old: "npm test", New: "npm run test:ci"},
+				{Kind: Removed, Name: "lint", Old: "npm run lint"},
+				{Kind: Added, Name: "coverage", New: "npm run cover"},
+				{Kind: Changed, Name: "(order)", Old: "[install, test]", New: "[test, install]"},
+			},
+			Environment: []Change{
+				{Kind: Removed, Name: "DEBUG", Old: "1"},
+				{Kind: Changed, Name: "NODE_ENV", Old: "test", New: "ci"},
+				{Kind: Added, Name: "TZ", New: "UTC"},
+			},
+		},
+		{Kind: Removed, Name: "old"},
+	}
+
+	assert.Equal(t, want, DiffJobs(remote, local))
+	assert.Equal(t, []JobDiff{}, DiffJobs(remote, remote))
+}
+
+func TestChangeString(t *testing.T) {
+	assert.Equal(t, "+ TZ: UTC", Change{Kind: Added, Name: "TZ", New: "UTC"}.String())
+	assert.Equal(t, "- DEBUG: 1", Change{Kind: Removed, Name: "DEBUG", Old: "1"}.String())
+	assert.Equal(t, "~ image: node:10 -> node:12", Change{Kind: Changed, Name: "image", Old: "node:10", New: "node:12"}.String())
+}
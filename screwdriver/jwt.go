@@ -0,0 +1,189 @@
+package screwdriver
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// jwtExpiryMargin is the time left before JWT expires when it is refreshed.
+// JWT is also used in the builds as $SD_TOKEN, so it must not expire while they are running.
+const jwtExpiryMargin = 30 * time.Minute
+
+// cachedJWT is JWT stored in the cache directory with when it expires
+type cachedJWT struct {
+	JWT       string    `json:"jwt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// Types of the tokens exchanged for JWT
+const (
+	UserTokenType     = "user"
+	PipelineTokenType = "pipeline"
+)
+
+// jwtClaims is the claims of JWT issued by Screwdriver.cd API which sd-local uses
+type jwtClaims struct {
+	Exp int64 `json:"exp"`
+	// PipelineID is the pipeline which the pipeline token belongs to
+	PipelineID int `json:"pipelineId"`
+}
+
+// parseJWTClaims returns the claims in the payload of JWT, whose signature is not verified
+func parseJWTClaims(jwt string) (jwtClaims, bool) {
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		return jwtClaims{}, false
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return jwtClaims{}, false
+	}
+
+	claims := jwtClaims{}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return jwtClaims{}, false
+	}
+
+	return claims, true
+}
+
+// jwtExpiry returns when JWT expires from its exp claim
+func jwtExpiry(jwt string) (time.Time, bool) {
+	claims, ok := parseJWTClaims(jwt)
+	if !ok || claims.Exp == 0 {
+		return time.Time{}, false
+	}
+
+	return time.Unix(claims.Exp, 0), true
+}
+
+// JWTPipelineID returns the pipeline of JWT exchanged for a pipeline token
+func JWTPipelineID(jwt string) (int, bool) {
+	claims, ok := parseJWTClaims(jwt)
+	if !ok || claims.PipelineID == 0 {
+		return 0, false
+	}
+
+	return claims.PipelineID, true
+}
+
+// jwtCachePath returns the path of the cache of JWT, which is separated by the API and the token
+func (sd *sdAPI) jwtCachePath() string {
+	if sd.Option.JWTCacheDir == "" {
+		return ""
+	}
+
+	sum := sha256.Sum256([]byte(sd.APIURL + "\n" + sd.Option.TokenType + "\n" + sd.UserToken))
+	return filepath.Join(sd.Option.JWTCacheDir, hex.EncodeToString(sum[:])+".json")
+}
+
+// loadJWT returns JWT in the cache unless it is about to expire
+func (sd *sdAPI) loadJWT() (cachedJWT, bool) {
+	p := sd.jwtCachePath()
+	if p == "" {
+		return cachedJWT{}, false
+	}
+
+	buf, err := ioutil.ReadFile(p)
+	if err != nil {
+		return cachedJWT{}, false
+	}
+
+	c := cachedJWT{}
+	if err := json.Unmarshal(buf, &c); err != nil || c.JWT == "" || now().Add(jwtExpiryMargin).After(c.ExpiresAt) {
+		return cachedJWT{}, false
+	}
+
+	return c, true
+}
+
+// saveJWT stores JWT in the cache, which is readable only by the user
+func (sd *sdAPI) saveJWT(c cachedJWT) error {
+	p := sd.jwtCachePath()
+	if p == "" {
+		return nil
+	}
+
+	buf, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("failed to save JWT: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0700); err != nil {
+		return fmt.Errorf("failed to save JWT: %v", err)
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(p), ".jwt")
+	if err != nil {
+		return fmt.Errorf("failed to save JWT: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(buf)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), p)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save JWT: %v", err)
+	}
+
+	return nil
+}
+
+// jwtExpired reports whether JWT should be refreshed before sending a request
+func (sd *sdAPI) jwtExpired() bool {
+	return sd.UserToken != "" && !sd.jwtExpiresAt.IsZero() && now().Add(jwtExpiryMargin).After(sd.jwtExpiresAt)
+}
+
+// refreshJWT gets new JWT, and stores it in the cache if it has the expiry
+func (sd *sdAPI) refreshJWT() error {
+	jwt, err := sd.jwt()
+	if err != nil {
+		return err
+	}
+
+	// The pipeline tokens are exchanged for JWT in the same way as the user tokens, and JWT tells which it is
+	if _, ok := JWTPipelineID(jwt); sd.Option.TokenType == PipelineTokenType && !ok {
+		return fmt.Errorf("token is not a pipeline token, set token-type to %s for a user token", UserTokenType)
+	}
+
+	sd.SDJWT = jwt
+	sd.jwtExpiresAt = time.Time{}
+
+	expiresAt, ok := jwtExpiry(jwt)
+	if !ok {
+		return nil
+	}
+	sd.jwtExpiresAt = expiresAt
+
+	// The build can run without the cache
+	if err := sd.saveJWT(cachedJWT{JWT: jwt, ExpiresAt: expiresAt}); err != nil {
+		logrus.Warn(err)
+	}
+
+	return nil
+}
+
+// InitJWT gets JWT of the token, which is reused from the cache until it is about to expire
+func (sd *sdAPI) InitJWT() error {
+	if c, ok := sd.loadJWT(); ok {
+		sd.SDJWT = c.JWT
+		sd.jwtExpiresAt = c.ExpiresAt
+		return nil
+	}
+
+	return sd.refreshJWT()
+}
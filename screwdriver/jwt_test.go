@@ -0,0 +1,230 @@
+package screwdriver
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testJWT returns an unsigned JWT which expires at exp
+func testJWT(exp time.Time) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"username":"sd-local","exp":%d}`, exp.Unix())))
+	return "eyJhbGciOiJub25lIn0." + payload + ".signature"
+}
+
+func TestJWTExpiry(t *testing.T) {
+	exp := time.Unix(1600000000, 0)
+
+	testCases := []struct {
+		name   string
+		jwt    string
+		expect time.Time
+		ok     bool
+	}{
+		{name: "success", jwt: testJWT(exp), expect: exp, ok: true},
+		{name: "failure without exp", jwt: "eyJhbGciOiJub25lIn0." + base64.RawURLEncoding.EncodeToString([]byte(`{}`)) + ".signature"},
+		{name: "failure with invalid payload", jwt: "a.b!.c"},
+		{name: "failure with invalid form", jwt: "jwt"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := jwtExpiry(tt.jwt)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expect, got)
+		})
+	}
+}
+
+func TestInitJWTCache(t *testing.T) {
+	defer func() { now = time.Now }()
+	current := time.Unix(1600000000, 0)
+	now = func() time.Time { return current }
+
+	dir, err := ioutil.TempDir("", "jwt")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	requests := 0
+	issued := testJWT(current.Add(2 * time.Hour))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprintf(w, `{"token":"%s"}`, issued)
+	}))
+	defer server.Close()
+
+	newAPI := func() *sdAPI {
+		return New(server.URL, "token", Option{JWTCacheDir: dir}).(*sdAPI)
+	}
+
+	t.Run("success without cache", func(t *testing.T) {
+		api := newAPI()
+		assert.Nil(t, api.InitJWT())
+		assert.Equal(t, issued, api.JWT())
+		assert.Equal(t, 1, requests)
+
+		info, err := os.Stat(api.jwtCachePath())
+		assert.Nil(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	})
+
+	t.Run("success with cache", func(t *testing.T) {
+		api := newAPI()
+		assert.Nil(t, api.InitJWT())
+		assert.Equal(t, issued, api.JWT())
+		assert.Equal(t, 1, requests)
+	})
+
+	t.Run("success with cache of another token", func(t *testing.T) {
+		api := New(server.URL, "another", Option{JWTCacheDir: dir}).(*sdAPI)
+		assert.NotEqual(t, newAPI().jwtCachePath(), api.jwtCachePath())
+		assert.Nil(t, api.InitJWT())
+		assert.Equal(t, 2, requests)
+	})
+
+	t.Run("success with cache about to expire", func(t *testing.T) {
+		current = current.Add(time.Hour + 31*time.Minute)
+		issued = testJWT(current.Add(2 * time.Hour))
+
+		api := newAPI()
+		assert.Nil(t, api.InitJWT())
+		assert.Equal(t, issued, api.JWT())
+		assert.Equal(t, 3, requests)
+	})
+
+	t.Run("success without cache directory", func(t *testing.T) {
+		api := New(server.URL, "token", Option{}).(*sdAPI)
+		assert.Equal(t, "", api.jwtCachePath())
+		assert.Nil(t, api.InitJWT())
+		assert.Equal(t, 4, requests)
+	})
+
+	t.Run("success with broken cache", func(t *testing.T) {
+		api := newAPI()
+		assert.Nil(t, ioutil.WriteFile(api.jwtCachePath(), []byte("{"), 0600))
+		assert.Nil(t, api.InitJWT())
+		assert.Equal(t, issued, api.JWT())
+		assert.Equal(t, 5, requests)
+	})
+}
+
+func TestRefreshJWT(t *testing.T) {
+	defer func() { now = time.Now }()
+	current := time.Unix(1600000000, 0)
+	now = func() time.Time { return current }
+
+	fresh := testJWT(current.Add(2 * time.Hour))
+	tokenRequests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v4/auth/token" {
+			tokenRequests++
+			fmt.Fprintf(w, `{"token":"%s"}`, fresh)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer "+fresh {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		testJSON, err := ioutil.ReadFile(filepath.Join(testDir, "validatedSuccess.json"))
+		assert.Nil(t, err)
+		fmt.Fprintln(w, string(testJSON))
+	}))
+	defer server.Close()
+
+	t.Run("success with revoked JWT", func(t *testing.T) {
+		tokenRequests = 0
+		api := New(server.URL, "token", Option{}).(*sdAPI)
+		api.SDJWT = "revoked"
+
+		_, err := api.Job("main", filepath.Join(testDir, "screwdriver.yaml"))
+		assert.Nil(t, err)
+		assert.Equal(t, fresh, api.JWT())
+		assert.Equal(t, 1, tokenRequests)
+	})
+
+	t.Run("success with expired JWT", func(t *testing.T) {
+		tokenRequests = 0
+		api := New(server.URL, "token", Option{}).(*sdAPI)
+		api.SDJWT = "expired"
+		api.jwtExpiresAt = current.Add(time.Minute)
+
+		_, err := api.Job("main", filepath.Join(testDir, "screwdriver.yaml"))
+		assert.Nil(t, err)
+		assert.Equal(t, fresh, api.JWT())
+		assert.Equal(t, 1, tokenRequests)
+	})
+
+	t.Run("failure without user token", func(t *testing.T) {
+		tokenRequests = 0
+		api := New(server.URL, "", Option{}).(*sdAPI)
+		api.SDJWT = "revoked"
+
+		_, err := api.Job("main", filepath.Join(testDir, "screwdriver.yaml"))
+		assert.Equal(t, fmt.Errorf("failed to post validator: StatusCode 401"), err)
+		assert.Equal(t, 0, tokenRequests)
+	})
+}
+
+func TestJWTPipelineID(t *testing.T) {
+	encode := func(payload string) string {
+		return "eyJhbGciOiJub25lIn0." + base64.RawURLEncoding.EncodeToString([]byte(payload)) + ".signature"
+	}
+
+	id, ok := JWTPipelineID(encode(`{"pipelineId":123,"scope":["pipeline"]}`))
+	assert.True(t, ok)
+	assert.Equal(t, 123, id)
+
+	_, ok = JWTPipelineID(encode(`{"username":"sd-local","scope":["user"]}`))
+	assert.False(t, ok)
+
+	_, ok = JWTPipelineID("jwt")
+	assert.False(t, ok)
+}
+
+func TestInitJWTPipelineToken(t *testing.T) {
+	testCases := []struct {
+		name      string
+		tokenType string
+		payload   string
+		err       error
+	}{
+		{name: "success with pipeline token", tokenType: PipelineTokenType, payload: `{"pipelineId":123}`},
+		{name: "success with user token", tokenType: UserTokenType, payload: `{"username":"sd-local"}`},
+		{name: "success with user token without type", payload: `{"username":"sd-local"}`},
+		{
+			name:      "failure with user token as pipeline token",
+			tokenType: PipelineTokenType,
+			payload:   `{"username":"sd-local"}`,
+			err:       fmt.Errorf("token is not a pipeline token, set token-type to user for a user token"),
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			jwt := "eyJhbGciOiJub25lIn0." + base64.RawURLEncoding.EncodeToString([]byte(tt.payload)) + ".signature"
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/v4/auth/token", r.URL.Path)
+				assert.Equal(t, "token", r.URL.Query().Get("api_token"))
+				fmt.Fprintf(w, `{"token":"%s"}`, jwt)
+			}))
+			defer server.Close()
+
+			api := New(server.URL, "token", Option{TokenType: tt.tokenType})
+			err := api.InitJWT()
+			assert.Equal(t, tt.err, err)
+			if tt.err == nil {
+				assert.Equal(t, jwt, api.JWT())
+			} else {
+				assert.Equal(t, "", api.JWT())
+			}
+		})
+	}
+}
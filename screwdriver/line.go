@@ -0,0 +1,107 @@
+package screwdriver
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// linePattern matches the lines and the columns in the messages of the YAML parsers
+	// (e.g. yaml: line 3: mapping values are not allowed, bad indentation of a mapping entry at line 3, column 5)
+	linePattern = regexp.MustCompile(`\bline (\d+)\b(?:, column (\d+)\b)?`)
+	// jobPattern matches the job names in the messages of the local validation (e.g. job 'main' has no image)
+	jobPattern = regexp.MustCompile(`\bjob '([^']+)'`)
+	// keyPathPattern matches the paths of the keys in the messages of the validator (e.g. "jobs.main.image" is required)
+	keyPathPattern = regexp.MustCompile(`\b((?:jobs|shared|cache|parameters|annotations|stages|subscribe)(?:\.[\w~@/-]+)*)\b`)
+)
+
+// Position is the position in screwdriver.yaml, whose line and column start from 1. The zero value means an unknown position.
+type Position struct {
+	Line   int
+	Column int
+}
+
+// MessagePosition returns the position in content of screwdriver.yaml which the validation message refers to.
+// The position is approximate, which is the deepest key of the path in the message found in content.
+func MessagePosition(content, message string) Position {
+	lines := strings.Split(content, "\n")
+
+	if m := linePattern.FindStringSubmatch(message); m != nil {
+		if n, err := strconv.Atoi(m[1]); err == nil && n >= 1 && n <= len(lines) {
+			column, err := strconv.Atoi(m[2])
+			if err != nil || column < 1 {
+				column = indentOf(lines[n-1]) + 1
+			}
+			return Position{Line: n, Column: column}
+		}
+	}
+
+	if m := jobPattern.FindStringSubmatch(message); m != nil {
+		return keyPosition(lines, []string{"jobs", m[1]})
+	}
+
+	if m := keyPathPattern.FindStringSubmatch(message); m != nil {
+		return keyPosition(lines, strings.Split(m[1], "."))
+	}
+
+	return Position{}
+}
+
+// keyPosition returns the position of the deepest key of the path found in the lines, where each key is looked up in the block of its parent.
+// The indexes of the lists in the path are skipped.
+func keyPosition(lines []string, path []string) Position {
+	found := Position{}
+	parent := -1
+	start := 0
+
+	for _, key := range path {
+		if _, err := strconv.Atoi(key); err == nil {
+			continue
+		}
+
+		p := Position{}
+		for i := start; i < len(lines); i++ {
+			trimmed := strings.TrimLeft(lines[i], " ")
+			if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+				continue
+			}
+			indent := indentOf(lines[i])
+			if indent <= parent {
+				break
+			}
+
+			column := indent + 1
+			if strings.HasPrefix(trimmed, "- ") {
+				trimmed = strings.TrimLeft(trimmed[2:], " ")
+				column = len(lines[i]) - len(trimmed) + 1
+			}
+			if keyOf(trimmed) == key {
+				p = Position{Line: i + 1, Column: column}
+				parent, start = indent, i+1
+				break
+			}
+		}
+
+		if p.Line == 0 {
+			break
+		}
+		found = p
+	}
+
+	return found
+}
+
+// indentOf returns the number of the spaces which indent the line.
+func indentOf(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// keyOf returns the key of the line of the mapping, or an empty string when the line has no key.
+func keyOf(line string) string {
+	i := strings.Index(line, ":")
+	if i <= 0 {
+		return ""
+	}
+	return strings.Trim(line[:i], `"' `)
+}
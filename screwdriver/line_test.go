@@ -0,0 +1,47 @@
+package screwdriver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessagePosition(t *testing.T) {
+	content := `shared:
+    image: node:12
+jobs:
+    # the main job
+    main:
+        image: alpine
+        steps:
+            - install: npm install
+            - test: npm test
+    publish:
+        requires: [main]
+        steps:
+            - publish: echo publish
+`
+
+	testCases := []struct {
+		name    string
+		message string
+		expect  Position
+	}{
+		{"line of YAML parser", "yaml: line 4: mapping values are not allowed in this context", Position{Line: 4, Column: 5}},
+		{"line and column of YAML parser", "YAMLException: bad indentation of a mapping entry at line 6, column 14", Position{Line: 6, Column: 14}},
+		{"line out of range", "yaml: line 40: did not find expected key", Position{}},
+		{"job of local validation", "job 'publish' has no image", Position{Line: 10, Column: 5}},
+		{"key path", `"jobs.main.image" must be a string`, Position{Line: 6, Column: 9}},
+		{"key path with index", `"jobs.publish.steps.0.publish" must be a string`, Position{Line: 13, Column: 15}},
+		{"deepest key found", `"jobs.main.environment" must be an object`, Position{Line: 5, Column: 5}},
+		{"key path of shared", `"shared.image" must be a string`, Position{Line: 2, Column: 5}},
+		{"unknown job", `"jobs.unknown.image" is required`, Position{Line: 3, Column: 1}},
+		{"no key path", "validate error", Position{}},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expect, MessagePosition(content, tt.message))
+		})
+	}
+}
@@ -0,0 +1,303 @@
+package screwdriver
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/go-yaml/yaml"
+)
+
+type localAPI struct {
+	templateDir string
+}
+
+var _ API = (*localAPI)(nil)
+
+// localJob is a job in screwdriver.yaml, which is also the config of a template
+type localJob struct {
+	Image       string                 `yaml:"image" json:"image,omitempty"`
+	Steps       []map[string]string    `yaml:"steps" json:"steps,omitempty"`
+	Environment map[string]string      `yaml:"environment" json:"environment,omitempty"`
+	Template    string                 `yaml:"template" json:"-"`
+	Requires    interface{}            `yaml:"requires" json:"-"`
+	Secrets     []string               `yaml:"secrets" json:"secrets,omitempty"`
+	Annotations map[string]interface{} `yaml:"annotations" json:"annotations,omitempty"`
+}
+
+type localConfig struct {
+	Shared localJob            `yaml:"shared"`
+	Jobs   map[string]localJob `yaml:"jobs"`
+}
+
+// NewLocal creates a API which resolves jobs from screwdriver.yaml without Screwdriver.cd API.
+// The templates are resolved with the ones stored in templateDir by SaveTemplate, and can not be used when it is empty.
+func NewLocal(templateDir string) API {
+	return &localAPI{templateDir: templateDir}
+}
+
+func parseSteps(steps []map[string]string) ([]Step, error) {
+	parsed := make([]Step, 0, len(steps))
+	for _, s := range steps {
+		if len(s) != 1 {
+			return nil, fmt.Errorf("each step must have exactly one name, got %v", s)
+		}
+		for name, command := range s {
+			parsed = append(parsed, Step{Name: name, Command: command})
+		}
+	}
+	return parsed, nil
+}
+
+// inherit returns the job whose image and steps are taken from base unless the job has them.
+// The environment variables, the secrets and the annotations are merged, and the ones of the job take precedence.
+func (j localJob) inherit(base localJob) localJob {
+	merged := j
+
+	if merged.Image == "" {
+		merged.Image = base.Image
+	}
+	if len(merged.Steps) == 0 {
+		merged.Steps = base.Steps
+	}
+
+	merged.Environment = make(map[string]string, len(base.Environment)+len(j.Environment))
+	for k, v := range base.Environment {
+		merged.Environment[k] = v
+	}
+	for k, v := range j.Environment {
+		merged.Environment[k] = v
+	}
+
+	merged.Secrets = make([]string, 0, len(base.Secrets)+len(j.Secrets))
+	seen := make(map[string]bool, cap(merged.Secrets))
+	for _, list := range [][]string{base.Secrets, j.Secrets} {
+		for _, secret := range list {
+			if !seen[secret] {
+				seen[secret] = true
+				merged.Secrets = append(merged.Secrets, secret)
+			}
+		}
+	}
+	if len(merged.Secrets) == 0 {
+		merged.Secrets = nil
+	}
+
+	merged.Annotations = nil
+	if len(base.Annotations)+len(j.Annotations) != 0 {
+		merged.Annotations = make(map[string]interface{}, len(base.Annotations)+len(j.Annotations))
+		for k, v := range base.Annotations {
+			merged.Annotations[k] = v
+		}
+		for k, v := range j.Annotations {
+			merged.Annotations[k] = v
+		}
+	}
+
+	return merged
+}
+
+// resolve returns the job with the settings of its template and the shared settings.
+// The template is read from the templates cached in templateDir.
+func (j localJob) resolve(name string, shared localJob, templateDir string) (Job, error) {
+	if j.Template != "" {
+		if templateDir == "" {
+			return Job{}, fmt.Errorf("job '%s' uses template '%s', which can not be resolved offline", name, j.Template)
+		}
+		t, err := loadTemplate(templateDir, j.Template)
+		if os.IsNotExist(err) {
+			return Job{}, fmt.Errorf("job '%s' uses template '%s', which can not be resolved offline because it has not been cached by online builds", name, j.Template)
+		}
+		if err != nil {
+			return Job{}, fmt.Errorf("job '%s' uses template '%s', which can not be resolved offline: %v", name, j.Template, err)
+		}
+		j = j.inherit(t.Config)
+	}
+	j = j.inherit(shared)
+
+	if j.Image == "" {
+		return Job{}, fmt.Errorf("job '%s' has no image", name)
+	}
+
+	if len(j.Steps) == 0 {
+		return Job{}, fmt.Errorf("job '%s' has no steps", name)
+	}
+	steps, err := parseSteps(j.Steps)
+	if err != nil {
+		return Job{}, fmt.Errorf("job '%s' has invalid steps: %v", name, err)
+	}
+
+	requires, err := parseRequires(j.Requires)
+	if err != nil {
+		return Job{}, fmt.Errorf("job '%s' has invalid requires: %v", name, err)
+	}
+
+	return Job{
+		Steps:       steps,
+		Environment: j.Environment,
+		Image:       j.Image,
+		Requires:    requires,
+		Secrets:     j.Secrets,
+		Annotations: j.Annotations,
+	}, nil
+}
+
+// parseRequires accepts both of a single job name and a list of job names.
+func parseRequires(requires interface{}) ([]string, error) {
+	switch r := requires.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{r}, nil
+	case []interface{}:
+		parsed := make([]string, 0, len(r))
+		for _, v := range r {
+			name, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("%v is not a job name", v)
+			}
+			parsed = append(parsed, name)
+		}
+		return parsed, nil
+	default:
+		return nil, fmt.Errorf("%v is neither a job name nor a list of job names", r)
+	}
+}
+
+// validate returns the jobs in screwdriver.yaml and the errors found in it.
+func (l *localAPI) validate(filePath string) (jobs, []string, error) {
+	raw, err := readScrewdriverYAML(filePath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	c := new(localConfig)
+	err = yaml.Unmarshal([]byte(raw), c)
+	if err != nil {
+		return nil, []string{err.Error()}, nil
+	}
+
+	if len(c.Jobs) == 0 {
+		return nil, []string{"no jobs are defined"}, nil
+	}
+
+	names := make([]string, 0, len(c.Jobs))
+	for name := range c.Jobs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parsed := make(jobs, len(c.Jobs))
+	errs := make([]string, 0)
+	for _, name := range names {
+		job, err := c.Jobs[name].resolve(name, c.Shared, l.templateDir)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		parsed[name] = []Job{job}
+	}
+
+	return parsed, errs, nil
+}
+
+func (l *localAPI) parseScrewdriverYAML(filePath string) (jobs, error) {
+	parsed, errs, err := l.validate(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(errs) != 0 {
+		return nil, fmt.Errorf("failed to parse screwdriver.yaml: %v", errs)
+	}
+
+	return parsed, nil
+}
+
+// Job returns job represented by "jobName"
+func (l *localAPI) Job(jobName, filePath string) (Job, error) {
+	jobs, err := l.parseScrewdriverYAML(filePath)
+	if err != nil {
+		return Job{}, err
+	}
+
+	return findJob(jobs, jobName)
+}
+
+// Jobs returns all jobs in screwdriver.yaml
+func (l *localAPI) Jobs(filePath string) (map[string]Job, error) {
+	jobs, err := l.parseScrewdriverYAML(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return jobs.flatten(), nil
+}
+
+// Validate returns the errors of screwdriver.yaml found locally, which has no warnings
+func (l *localAPI) Validate(filePath string) (Validation, error) {
+	parsed, errs, err := l.validate(filePath)
+	if err != nil {
+		return Validation{}, err
+	}
+
+	if len(errs) != 0 {
+		return Validation{Errors: errs}, nil
+	}
+	return Validation{Jobs: parsed.flatten()}, nil
+}
+
+// Secrets returns an error because the secrets of the pipeline can not be fetched in offline mode
+func (l *localAPI) Secrets(pipelineID int) (map[string]string, error) {
+	return nil, fmt.Errorf("secrets of pipeline %d can not be fetched in offline mode", pipelineID)
+}
+
+// RemoteBuild returns an error because the build can not be fetched in offline mode
+func (l *localAPI) RemoteBuild(buildID int) (RemoteBuild, error) {
+	return RemoteBuild{}, fmt.Errorf("build %d can not be fetched in offline mode", buildID)
+}
+
+// Pipeline returns an error because the pipeline can not be fetched in offline mode
+func (l *localAPI) Pipeline(pipelineID int) (Pipeline, error) {
+	return Pipeline{}, fmt.Errorf("pipeline %d can not be fetched in offline mode", pipelineID)
+}
+
+// PipelineJobs returns an error because the jobs of the pipeline can not be fetched in offline mode
+func (l *localAPI) PipelineJobs(pipelineID int) (map[string]Job, error) {
+	return nil, fmt.Errorf("jobs of pipeline %d can not be fetched in offline mode", pipelineID)
+}
+
+// Template returns an error because the template can not be fetched in offline mode
+func (l *localAPI) Template(ref string) (Template, error) {
+	return Template{}, fmt.Errorf("template %s can not be fetched in offline mode", ref)
+}
+
+// ValidateTemplate returns an error because the template is validated with Screwdriver.cd API
+func (l *localAPI) ValidateTemplate(filePath string) (Template, error) {
+	return Template{}, fmt.Errorf("template %s can not be validated in offline mode", filePath)
+}
+
+// PublishTemplate returns an error because the template can not be published in offline mode
+func (l *localAPI) PublishTemplate(filePath string) (Template, error) {
+	return Template{}, fmt.Errorf("template %s can not be published in offline mode", filePath)
+}
+
+// ValidateCommand returns an error because the command is validated with Screwdriver.cd API
+func (l *localAPI) ValidateCommand(filePath string) (Command, error) {
+	return Command{}, fmt.Errorf("command %s can not be validated in offline mode", filePath)
+}
+
+// PublishCommand returns an error because the command can not be published in offline mode
+func (l *localAPI) PublishCommand(filePath string) (Command, error) {
+	return Command{}, fmt.Errorf("command %s can not be published in offline mode", filePath)
+}
+
+// InitJWT does nothing because no API is called in offline mode
+func (l *localAPI) InitJWT() error {
+	return nil
+}
+
+// JWT returns empty token because no API is called in offline mode
+func (l *localAPI) JWT() string {
+	return ""
+}
@@ -0,0 +1,237 @@
+package screwdriver
+
+import (
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalJob(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		api := NewLocal("")
+
+		gotJob, err := api.Job("main", filepath.Join(testDir, "screwdriver.yaml"))
+		assert.Nil(t, err)
+		assert.Equal(t, Job{
+			Steps: []Step{
+				{Name: "install", Command: "echo install"},
+				{Name: "publish", Command: "echo publish"},
+			},
+			Environment: map[string]string{
+				"TEST_ENV": "hoge",
+			},
+			Image: "alpine",
+		}, gotJob)
+	})
+
+	t.Run("success with shared settings", func(t *testing.T) {
+		api := NewLocal("")
+
+		gotJob, err := api.Job("main", filepath.Join(testDir, "screwdriverShared.yaml"))
+		assert.Nil(t, err)
+		assert.Equal(t, Job{
+			Steps: []Step{
+				{Name: "install", Command: "npm install"},
+				{Name: "test", Command: "npm test"},
+			},
+			Environment: map[string]string{
+				"SHARED_ENV": "shared",
+				"TEST_ENV":   "hoge",
+			},
+			Image:       "node:12",
+			Secrets:     []string{"NPM_TOKEN", "GIT_KEY"},
+			Annotations: map[string]interface{}{"screwdriver.cd/timeout": 30},
+		}, gotJob)
+
+		gotJob, err = api.Job("publish", filepath.Join(testDir, "screwdriverShared.yaml"))
+		assert.Nil(t, err)
+		assert.Equal(t, "alpine", gotJob.Image)
+		assert.Equal(t, []Step{{Name: "publish", Command: "echo publish"}}, gotJob.Steps)
+		assert.Equal(t, map[string]interface{}{"screwdriver.cd/timeout": 10}, gotJob.Annotations)
+	})
+
+	t.Run("failure by template job", func(t *testing.T) {
+		api := NewLocal("")
+
+		_, err := api.Job("main", filepath.Join(testDir, "screwdriverTemplate.yaml"))
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "uses template 'sd/noop@latest', which can not be resolved offline")
+	})
+
+	t.Run("success with cached template", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "templates")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+
+		err = SaveTemplate(dir, "sd/noop@latest", Template{
+			Name:    "sd/noop",
+			Version: "1.0.0",
+			Config: localJob{
+				Image:       "alpine",
+				Steps:       []map[string]string{{"noop": "echo noop"}},
+				Environment: map[string]string{"FOO": "foo"},
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		api := NewLocal(dir)
+
+		gotJob, err := api.Job("main", filepath.Join(testDir, "screwdriverTemplate.yaml"))
+		assert.Nil(t, err)
+		assert.Equal(t, Job{
+			Steps:       []Step{{Name: "noop", Command: "echo noop"}},
+			Environment: map[string]string{"FOO": "foo"},
+			Image:       "alpine",
+		}, gotJob)
+	})
+
+	t.Run("failure by not cached template", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "templates")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+
+		api := NewLocal(dir)
+
+		_, err = api.Job("main", filepath.Join(testDir, "screwdriverTemplate.yaml"))
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "uses template 'sd/noop@latest', which can not be resolved offline because it has not been cached by online builds")
+	})
+
+	t.Run("failure by invalid screwdriver.yaml", func(t *testing.T) {
+		api := NewLocal("")
+
+		_, err := api.Job("main", filepath.Join(testDir, "screwdriverInvalid.yaml"))
+		assert.NotNil(t, err)
+		msg := err.Error()
+		assert.Equal(t, 0, strings.Index(msg, "failed to parse screwdriver.yaml: "), fmt.Sprintf("expected error is `failed to parse screwdriver.yaml: ...`, actual: `%v`", msg))
+	})
+
+	t.Run("failure by not found job name", func(t *testing.T) {
+		api := NewLocal("")
+
+		_, err := api.Job("nyancat", filepath.Join(testDir, "screwdriver.yaml"))
+		assert.Equal(t, "not found 'nyancat' in parsed screwdriver.yaml", err.Error())
+	})
+
+	t.Run("no JWT", func(t *testing.T) {
+		api := NewLocal("")
+
+		assert.Nil(t, api.InitJWT())
+		assert.Equal(t, "", api.JWT())
+	})
+
+	t.Run("failure by fetching secrets", func(t *testing.T) {
+		api := NewLocal("")
+
+		_, err := api.Secrets(1)
+		assert.Equal(t, "secrets of pipeline 1 can not be fetched in offline mode", err.Error())
+	})
+
+	t.Run("failure by fetching build", func(t *testing.T) {
+		api := NewLocal("")
+
+		_, err := api.RemoteBuild(1)
+		assert.Equal(t, "build 1 can not be fetched in offline mode", err.Error())
+	})
+
+	t.Run("failure by fetching pipeline", func(t *testing.T) {
+		api := NewLocal("")
+
+		_, err := api.Pipeline(1)
+		assert.Equal(t, "pipeline 1 can not be fetched in offline mode", err.Error())
+	})
+
+	t.Run("failure by fetching jobs of pipeline", func(t *testing.T) {
+		api := NewLocal("")
+
+		_, err := api.PipelineJobs(1)
+		assert.Equal(t, "jobs of pipeline 1 can not be fetched in offline mode", err.Error())
+	})
+
+	t.Run("failure by fetching template", func(t *testing.T) {
+		api := NewLocal("")
+
+		_, err := api.Template("sd/noop@latest")
+		assert.Equal(t, "template sd/noop@latest can not be fetched in offline mode", err.Error())
+	})
+
+	t.Run("failure by validating template", func(t *testing.T) {
+		api := NewLocal("")
+
+		_, err := api.ValidateTemplate("sd-template.yaml")
+		assert.Equal(t, "template sd-template.yaml can not be validated in offline mode", err.Error())
+
+		_, err = api.PublishTemplate("sd-template.yaml")
+		assert.Equal(t, "template sd-template.yaml can not be published in offline mode", err.Error())
+	})
+
+	t.Run("failure by validating command", func(t *testing.T) {
+		api := NewLocal("")
+
+		_, err := api.ValidateCommand("sd-command.yaml")
+		assert.Equal(t, "command sd-command.yaml can not be validated in offline mode", err.Error())
+
+		_, err = api.PublishCommand("sd-command.yaml")
+		assert.Equal(t, "command sd-command.yaml can not be published in offline mode", err.Error())
+	})
+}
+
+func TestIsUnreachable(t *testing.T) {
+	urlErr := &url.Error{Op: "Get", URL: "http://localhost", Err: fmt.Errorf("connection refused")}
+
+	assert.True(t, IsUnreachable(fmt.Errorf("failed to send request: %w", urlErr)))
+	assert.False(t, IsUnreachable(fmt.Errorf("failed to get JWT: StatusCode 500")))
+
+	certErr := &url.Error{Op: "Get", URL: "https://localhost", Err: x509.UnknownAuthorityError{}}
+	assert.False(t, IsUnreachable(fmt.Errorf("failed to send request: %w", certErr)))
+}
+
+func TestLocalValidate(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		api := NewLocal("")
+
+		v, err := api.Validate(filepath.Join(testDir, "screwdriverShared.yaml"))
+		assert.Nil(t, err)
+		assert.Empty(t, v.Errors)
+		assert.Empty(t, v.Warnings)
+		assert.Equal(t, 2, len(v.Jobs))
+		assert.Equal(t, "alpine", v.Jobs["publish"].Image)
+	})
+
+	t.Run("success with errors", func(t *testing.T) {
+		api := NewLocal("")
+
+		v, err := api.Validate(filepath.Join(testDir, "screwdriverTemplate.yaml"))
+		assert.Nil(t, err)
+		assert.Nil(t, v.Jobs)
+		assert.Equal(t, []string{"job 'main' uses template 'sd/noop@latest', which can not be resolved offline"}, v.Errors)
+	})
+
+	t.Run("success with no jobs", func(t *testing.T) {
+		api := NewLocal("")
+
+		v, err := api.Validate(filepath.Join(testDir, "screwdriverInvalid.yaml"))
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(v.Errors))
+	})
+
+	t.Run("failure by reading screwdriver.yaml", func(t *testing.T) {
+		api := NewLocal("")
+
+		_, err := api.Validate(filepath.Join(testDir, "notExist.yaml"))
+		assert.Equal(t, 0, strings.Index(err.Error(), "failed to read screwdriver.yaml: "))
+	})
+}
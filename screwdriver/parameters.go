@@ -0,0 +1,84 @@
+package screwdriver
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/go-yaml/yaml"
+)
+
+// Parameter is a build parameter defined in screwdriver.yaml
+type Parameter struct {
+	Value       string
+	Description string
+}
+
+// parseParameterValue accepts a value and a list of values whose first one is the default.
+func parseParameterValue(raw interface{}) (string, error) {
+	switch r := raw.(type) {
+	case nil:
+		return "", nil
+	case string, int, float64, bool:
+		return fmt.Sprint(r), nil
+	case []interface{}:
+		if len(r) == 0 {
+			return "", nil
+		}
+		return parseParameterValue(r[0])
+	default:
+		return "", fmt.Errorf("%v is not a value of parameter", r)
+	}
+}
+
+// parseParameter accepts a value, a list of values and an object which has the value and the description.
+func parseParameter(raw interface{}) (Parameter, error) {
+	r, ok := raw.(map[interface{}]interface{})
+	if !ok {
+		value, err := parseParameterValue(raw)
+		return Parameter{Value: value}, err
+	}
+
+	value, err := parseParameterValue(r["value"])
+	if err != nil {
+		return Parameter{}, err
+	}
+
+	p := Parameter{Value: value}
+	if description, ok := r["description"]; ok {
+		p.Description = fmt.Sprint(description)
+	}
+	return p, nil
+}
+
+// ReadParameters reads the parameters in screwdriver.yaml
+func ReadParameters(filePath string) (map[string]Parameter, error) {
+	raw, err := readScrewdriverYAML(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	c := struct {
+		Parameters map[string]interface{} `yaml:"parameters"`
+	}{}
+	err = yaml.Unmarshal([]byte(raw), &c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse parameters in screwdriver.yaml: %v", err)
+	}
+
+	names := make([]string, 0, len(c.Parameters))
+	for name := range c.Parameters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parameters := make(map[string]Parameter, len(c.Parameters))
+	for _, name := range names {
+		p, err := parseParameter(c.Parameters[name])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse parameter %s in screwdriver.yaml: %v", name, err)
+		}
+		parameters[name] = p
+	}
+
+	return parameters, nil
+}
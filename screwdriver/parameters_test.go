@@ -0,0 +1,58 @@
+package screwdriver
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseParameter(t *testing.T) {
+	testCases := []struct {
+		name   string
+		raw    interface{}
+		expect Parameter
+	}{
+		{"value", "us-west-1", Parameter{Value: "us-west-1"}},
+		{"number", 3, Parameter{Value: "3"}},
+		{"list", []interface{}{"zone-a", "zone-b"}, Parameter{Value: "zone-a"}},
+		{"object", map[interface{}]interface{}{"value": "us-west-1", "description": "region"}, Parameter{Value: "us-west-1", Description: "region"}},
+		{"object with list", map[interface{}]interface{}{"value": []interface{}{"zone-a", "zone-b"}}, Parameter{Value: "zone-a"}},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := parseParameter(tt.raw)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expect, p)
+		})
+	}
+
+	t.Run("failure by invalid value", func(t *testing.T) {
+		_, err := parseParameter(map[interface{}]interface{}{"value": map[interface{}]interface{}{}})
+		assert.NotNil(t, err)
+	})
+}
+
+func TestReadParameters(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		parameters, err := ReadParameters(filepath.Join(testDir, "screwdriverParameters.yaml"))
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]Parameter{
+			"region": {Value: "us-west-1"},
+			"az":     {Value: "zone-a", Description: "availability zone"},
+			"dryRun": {Value: "true"},
+		}, parameters)
+	})
+
+	t.Run("success without parameters", func(t *testing.T) {
+		parameters, err := ReadParameters(filepath.Join(testDir, "screwdriver.yaml"))
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]Parameter{}, parameters)
+	})
+
+	t.Run("failure by reading screwdriver.yaml", func(t *testing.T) {
+		_, err := ReadParameters(filepath.Join(testDir, "none.yaml"))
+		assert.Contains(t, err.Error(), "failed to read screwdriver.yaml")
+	})
+}
@@ -0,0 +1,268 @@
+package screwdriver
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+const (
+	buildEndpoint    = "builds/%d"
+	eventEndpoint    = "events/%d"
+	jobEndpoint      = "jobs/%d"
+	pipelineEndpoint = "pipelines/%d"
+	// pipelineJobsEndpoint lists the jobs except the archived ones
+	pipelineJobsEndpoint = "pipelines/%d/jobs"
+)
+
+var prJobNamePattern = regexp.MustCompile(`^PR-\d+:`)
+
+// RemoteBuild is a build run on Screwdriver.cd with what is needed to run it again locally
+type RemoteBuild struct {
+	ID          int
+	JobName     string
+	Job         Job
+	PipelineID  int
+	CheckoutURL string
+	Branch      string
+	SHA         string
+	Meta        map[string]interface{}
+	Parameters  map[string]string
+}
+
+// buildIDs is the ID of the parent builds, which is either a number or an array in the response
+type buildIDs []int
+
+func (ids *buildIDs) UnmarshalJSON(data []byte) error {
+	var id int
+	if err := json.Unmarshal(data, &id); err == nil {
+		*ids = buildIDs{id}
+		return nil
+	}
+
+	var list []int
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*ids = list
+
+	return nil
+}
+
+type buildResponse struct {
+	ID            int                    `json:"id"`
+	JobID         int                    `json:"jobId"`
+	EventID       int                    `json:"eventId"`
+	ParentBuildID buildIDs               `json:"parentBuildId"`
+	SHA           string                 `json:"sha"`
+	Meta          map[string]interface{} `json:"meta"`
+}
+
+type eventResponse struct {
+	Meta struct {
+		Parameters map[string]struct {
+			Value interface{} `json:"value"`
+		} `json:"parameters"`
+	} `json:"meta"`
+}
+
+type jobResponse struct {
+	Name         string `json:"name"`
+	PipelineID   int    `json:"pipelineId"`
+	Permutations []Job  `json:"permutations"`
+}
+
+type pipelineResponse struct {
+	ScmURI  string `json:"scmUri"`
+	ScmRepo struct {
+		Name   string `json:"name"`
+		Branch string `json:"branch"`
+	} `json:"scmRepo"`
+}
+
+func (sd *sdAPI) get(endpoint, name string, v interface{}) error {
+	fullpath, err := sd.makeURL(endpoint)
+	if err != nil {
+		return fmt.Errorf("failed to make request url: %v", err)
+	}
+
+	res, err := sd.request(http.MethodGet, fullpath.String(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to get %s: StatusCode %d", name, res.StatusCode)
+	}
+
+	err = json.NewDecoder(res.Body).Decode(v)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s response: %v", name, err)
+	}
+
+	return nil
+}
+
+type errorResponse struct {
+	Message string `json:"message"`
+}
+
+// post sends body in JSON, and decodes the response into v when its status is want.
+func (sd *sdAPI) post(endpoint, name string, body interface{}, want int, v interface{}) error {
+	fullpath, err := sd.makeURL(endpoint)
+	if err != nil {
+		return fmt.Errorf("failed to make request url: %v", err)
+	}
+
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s request: %v", name, err)
+	}
+
+	res, err := sd.request(http.MethodPost, fullpath.String(), bytes.NewReader(buf))
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+
+	return decodePostResponse(res, name, want, v)
+}
+
+// decodePostResponse decodes the response into v when its status is want.
+// The message of the error response is included in the error.
+func decodePostResponse(res *http.Response, name string, want int, v interface{}) error {
+	defer res.Body.Close()
+
+	if res.StatusCode != want {
+		e := new(errorResponse)
+		if json.NewDecoder(res.Body).Decode(e) == nil && e.Message != "" {
+			return fmt.Errorf("failed to post %s: StatusCode %d: %s", name, res.StatusCode, e.Message)
+		}
+		return fmt.Errorf("failed to post %s: StatusCode %d", name, res.StatusCode)
+	}
+
+	err := json.NewDecoder(res.Body).Decode(v)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s response: %v", name, err)
+	}
+
+	return nil
+}
+
+// Pipeline is a pipeline of Screwdriver.cd with the repository and the branch it builds
+type Pipeline struct {
+	ID          int
+	CheckoutURL string
+	Branch      string
+	// RootDir is the directory of screwdriver.yaml in the repository
+	RootDir string
+}
+
+// checkoutURL returns the URL to clone the repository, whose host is the first part of scmUri
+func (p pipelineResponse) checkoutURL() (string, error) {
+	host := strings.SplitN(p.ScmURI, ":", 2)[0]
+	if host == "" || p.ScmRepo.Name == "" {
+		return "", fmt.Errorf("failed to find repository of pipeline from scmUri '%s'", p.ScmURI)
+	}
+
+	return fmt.Sprintf("https://%s/%s.git", host, p.ScmRepo.Name), nil
+}
+
+// Pipeline returns the pipeline of pipelineID
+func (sd *sdAPI) Pipeline(pipelineID int) (Pipeline, error) {
+	p := new(pipelineResponse)
+	if err := sd.get(fmt.Sprintf(pipelineEndpoint, pipelineID), fmt.Sprintf("pipeline %d", pipelineID), p); err != nil {
+		return Pipeline{}, err
+	}
+
+	checkoutURL, err := p.checkoutURL()
+	if err != nil {
+		return Pipeline{}, err
+	}
+
+	// scmUri is <host>:<repository id>:<branch>[:<root dir>]
+	rootDir := ""
+	if parts := strings.SplitN(p.ScmURI, ":", 4); len(parts) == 4 {
+		rootDir = parts[3]
+	}
+
+	return Pipeline{ID: pipelineID, CheckoutURL: checkoutURL, Branch: p.ScmRepo.Branch, RootDir: rootDir}, nil
+}
+
+// RemoteBuild returns the build with its job, pipeline, parameters and the meta passed from its parent builds
+func (sd *sdAPI) RemoteBuild(buildID int) (RemoteBuild, error) {
+	b := new(buildResponse)
+	if err := sd.get(fmt.Sprintf(buildEndpoint, buildID), fmt.Sprintf("build %d", buildID), b); err != nil {
+		return RemoteBuild{}, err
+	}
+
+	j := new(jobResponse)
+	if err := sd.get(fmt.Sprintf(jobEndpoint, b.JobID), fmt.Sprintf("job %d", b.JobID), j); err != nil {
+		return RemoteBuild{}, err
+	}
+	if len(j.Permutations) == 0 {
+		return RemoteBuild{}, fmt.Errorf("job %d has no config", b.JobID)
+	}
+
+	p, err := sd.Pipeline(j.PipelineID)
+	if err != nil {
+		return RemoteBuild{}, err
+	}
+
+	e := new(eventResponse)
+	if err := sd.get(fmt.Sprintf(eventEndpoint, b.EventID), fmt.Sprintf("event %d", b.EventID), e); err != nil {
+		return RemoteBuild{}, err
+	}
+	parameters := make(map[string]string, len(e.Meta.Parameters))
+	for name, param := range e.Meta.Parameters {
+		parameters[name] = fmt.Sprint(param.Value)
+	}
+
+	// The build started with the meta of its parent builds, not with the meta it finished with
+	meta := make(map[string]interface{})
+	for _, id := range b.ParentBuildID {
+		if id == 0 {
+			continue
+		}
+		parent := new(buildResponse)
+		if err := sd.get(fmt.Sprintf(buildEndpoint, id), fmt.Sprintf("build %d", id), parent); err != nil {
+			return RemoteBuild{}, err
+		}
+		for k, v := range parent.Meta {
+			meta[k] = v
+		}
+	}
+
+	return RemoteBuild{
+		ID:          b.ID,
+		JobName:     j.Name,
+		Job:         j.Permutations[0],
+		PipelineID:  j.PipelineID,
+		CheckoutURL: p.CheckoutURL,
+		Branch:      p.Branch,
+		SHA:         b.SHA,
+		Meta:        meta,
+		Parameters:  parameters,
+	}, nil
+}
+
+// PipelineJobs returns the jobs of the current screwdriver.yaml of the pipeline. The jobs of pull requests are not included.
+func (sd *sdAPI) PipelineJobs(pipelineID int) (map[string]Job, error) {
+	list := make([]jobResponse, 0)
+	if err := sd.get(fmt.Sprintf(pipelineJobsEndpoint, pipelineID), fmt.Sprintf("jobs of pipeline %d", pipelineID), &list); err != nil {
+		return nil, err
+	}
+
+	jobs := make(map[string]Job, len(list))
+	for _, j := range list {
+		if prJobNamePattern.MatchString(j.Name) || len(j.Permutations) == 0 {
+			continue
+		}
+		jobs[j.Name] = j.Permutations[0]
+	}
+
+	return jobs, nil
+}
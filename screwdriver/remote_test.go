@@ -0,0 +1,153 @@
+package screwdriver
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newRemoteServer(t *testing.T, responses map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		validateHeader(t, "Accept", "application/json", r)
+		validateHeader(t, "Authorization", "Bearer jwt", r)
+
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(404)
+			return
+		}
+		w.WriteHeader(200)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintln(w, body)
+	}))
+}
+
+func TestRemoteBuild(t *testing.T) {
+	responses := map[string]string{
+		"/v4/builds/100":  `{"id":100,"jobId":10,"eventId":1000,"parentBuildId":[98,99],"sha":"abc123","meta":{"finished":"true"},"status":"FAILURE"}`,
+		"/v4/builds/98":   `{"id":98,"meta":{"foo":"bar","version":"1"}}`,
+		"/v4/builds/99":   `{"id":99,"meta":{"version":"2"}}`,
+		"/v4/jobs/10":     `{"id":10,"name":"test","pipelineId":1,"permutations":[{"image":"node:12","commands":[{"name":"test","command":"npm test"}]}]}`,
+		"/v4/pipelines/1": `{"id":1,"scmUri":"github.com:123456:main","scmRepo":{"name":"screwdriver-cd/sd-local","branch":"main"}}`,
+		"/v4/events/1000": `{"id":1000,"meta":{"parameters":{"region":{"value":"us-west-2"},"count":{"value":3}}}}`,
+		"/v4/builds/200":  `{"id":200,"jobId":10,"eventId":2000,"parentBuildId":null,"sha":"def456"}`,
+		"/v4/events/2000": `{"id":2000,"meta":{}}`,
+		"/v4/builds/300":  `{"id":300,"jobId":30,"eventId":1000,"parentBuildId":99,"sha":"abc123"}`,
+		"/v4/jobs/30":     `{"id":30,"name":"main","pipelineId":3,"permutations":[{"image":"node:12"}]}`,
+		"/v4/pipelines/3": `{"id":3,"scmUri":""}`,
+		"/v4/builds/400":  `{"id":400,"jobId":40}`,
+		"/v4/jobs/40":     `{"id":40,"name":"main","pipelineId":1,"permutations":[]}`,
+		"/v4/builds/500":  `{`,
+	}
+	server := newRemoteServer(t, responses)
+	defer server.Close()
+
+	testAPI := sdAPI{
+		HTTPClient: http.DefaultClient,
+		APIURL:     server.URL,
+		SDJWT:      "jwt",
+	}
+
+	t.Run("success", func(t *testing.T) {
+		b, err := testAPI.RemoteBuild(100)
+		assert.Nil(t, err)
+		assert.Equal(t, RemoteBuild{
+			ID:          100,
+			JobName:     "test",
+			Job:         Job{Image: "node:12", Steps: []Step{{Name: "test", Command: "npm test"}}},
+			PipelineID:  1,
+			CheckoutURL: "https://github.com/screwdriver-cd/sd-local.git",
+			Branch:      "main",
+			SHA:         "abc123",
+			Meta:        map[string]interface{}{"foo": "bar", "version": "2"},
+			Parameters:  map[string]string{"region": "us-west-2", "count": "3"},
+		}, b)
+	})
+
+	t.Run("success without parent builds", func(t *testing.T) {
+		b, err := testAPI.RemoteBuild(200)
+		assert.Nil(t, err)
+		assert.Equal(t, "def456", b.SHA)
+		assert.Equal(t, map[string]interface{}{}, b.Meta)
+		assert.Equal(t, map[string]string{}, b.Parameters)
+	})
+
+	testCases := []struct {
+		name    string
+		buildID int
+		wantErr string
+	}{
+		{"failure by not existing build", 1, "failed to get build 1: StatusCode 404"},
+		{"failure by invalid JSON", 500, "failed to parse build 500 response: unexpected EOF"},
+		{"failure by pipeline without repository", 300, "failed to find repository of pipeline from scmUri ''"},
+		{"failure by job without config", 400, "job 40 has no config"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := testAPI.RemoteBuild(tt.buildID)
+			assert.Equal(t, tt.wantErr, err.Error())
+		})
+	}
+}
+
+func TestPipelineJobs(t *testing.T) {
+	server := newRemoteServer(t, map[string]string{
+		"/v4/pipelines/1/jobs": `[{"id":10,"name":"main","pipelineId":1,"permutations":[{"image":"node:12","requires":["~commit"]}]},{"id":11,"name":"PR-3:main","pipelineId":1,"permutations":[{"image":"node:12"}]},{"id":12,"name":"publish","pipelineId":1,"permutations":[{"image":"alpine","requires":["main"]}]}]`,
+	})
+	defer server.Close()
+
+	testAPI := sdAPI{
+		HTTPClient: http.DefaultClient,
+		APIURL:     server.URL,
+		SDJWT:      "jwt",
+	}
+
+	t.Run("success", func(t *testing.T) {
+		jobs, err := testAPI.PipelineJobs(1)
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]Job{
+			"main":    {Image: "node:12", Requires: []string{"~commit"}},
+			"publish": {Image: "alpine", Requires: []string{"main"}},
+		}, jobs)
+	})
+
+	t.Run("failure by not existing pipeline", func(t *testing.T) {
+		_, err := testAPI.PipelineJobs(2)
+		assert.Equal(t, "failed to get jobs of pipeline 2: StatusCode 404", err.Error())
+	})
+}
+
+func TestPipeline(t *testing.T) {
+	server := newRemoteServer(t, map[string]string{
+		"/v4/pipelines/1": `{"id":1,"scmUri":"github.com:123456:main","scmRepo":{"name":"screwdriver-cd/sd-local","branch":"main"}}`,
+		"/v4/pipelines/2": `{"id":2,"scmUri":"github.example.com:7890:develop:services/api","scmRepo":{"name":"org/monorepo","branch":"develop"}}`,
+	})
+	defer server.Close()
+
+	testAPI := sdAPI{
+		HTTPClient: http.DefaultClient,
+		APIURL:     server.URL,
+		SDJWT:      "jwt",
+	}
+
+	t.Run("success", func(t *testing.T) {
+		p, err := testAPI.Pipeline(1)
+		assert.Nil(t, err)
+		assert.Equal(t, Pipeline{ID: 1, CheckoutURL: "https://github.com/screwdriver-cd/sd-local.git", Branch: "main"}, p)
+	})
+
+	t.Run("success with root dir", func(t *testing.T) {
+		p, err := testAPI.Pipeline(2)
+		assert.Nil(t, err)
+		assert.Equal(t, Pipeline{ID: 2, CheckoutURL: "https://github.example.com/org/monorepo.git", Branch: "develop", RootDir: "services/api"}, p)
+	})
+
+	t.Run("failure by not existing pipeline", func(t *testing.T) {
+		_, err := testAPI.Pipeline(3)
+		assert.Equal(t, "failed to get pipeline 3: StatusCode 404", err.Error())
+	})
+}
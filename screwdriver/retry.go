@@ -0,0 +1,145 @@
+package screwdriver
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultRetryWait    = 500 * time.Millisecond
+	defaultRetryMaxWait = 30 * time.Second
+)
+
+var (
+	sleep      = time.Sleep
+	randInt63n = rand.Int63n
+	now        = time.Now
+)
+
+// retryable reports whether the request may be sent again.
+// The GET requests and the validators have no side effects, unlike publishing templates and commands.
+func retryable(req *http.Request) bool {
+	return req.Method == http.MethodGet || strings.Contains(req.URL.Path, "/"+apiVersion+"/"+validatorEndpoint)
+}
+
+// isTransient reports whether the status is caused by the proxies or the overload, which may succeed later
+func isTransient(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// shouldRetry reports whether the request failed transiently.
+// The errors except the timeouts are not retried, so that the builds fall back to offline mode soon when the API is unreachable.
+func shouldRetry(res *http.Response, err error) bool {
+	if err != nil {
+		var urlErr *url.Error
+		return errors.As(err, &urlErr) && urlErr.Timeout()
+	}
+	return isTransient(res.StatusCode)
+}
+
+// backoff returns the wait before the retry of attempt, which is exponential with jitter, or Retry-After of the response
+func (o Option) backoff(attempt int, res *http.Response) time.Duration {
+	base, max := o.RetryWait, o.RetryMaxWait
+	if base <= 0 {
+		base = defaultRetryWait
+	}
+	if max <= 0 {
+		max = defaultRetryMaxWait
+	}
+
+	if res != nil {
+		if wait, ok := retryAfter(res.Header.Get("Retry-After")); ok {
+			if wait > max {
+				return max
+			}
+			return wait
+		}
+	}
+
+	wait := base << uint(attempt)
+	if wait > max || wait <= 0 {
+		wait = max
+	}
+	// Half of the wait is randomized not to retry at the same time as the other clients
+	return wait/2 + time.Duration(randInt63n(int64(wait/2)+1))
+}
+
+// retryAfter parses Retry-After, which is either the seconds or the HTTP date
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		wait := t.Sub(now())
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
+
+// do sends the request, and sends it again on the transient failures if it is retryable
+func (sd *sdAPI) do(req *http.Request) (*http.Response, error) {
+	if sd.Option.Retries <= 0 || !retryable(req) {
+		return sd.HTTPClient.Do(req)
+	}
+
+	// The body is read again on each retry
+	var body []byte
+	if req.Body != nil {
+		b, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = b
+	}
+
+	for attempt := 0; ; attempt++ {
+		if body != nil {
+			req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+
+		res, err := sd.HTTPClient.Do(req)
+		if attempt >= sd.Option.Retries || !shouldRetry(res, err) {
+			return res, err
+		}
+
+		reason := ""
+		if err != nil {
+			// The URL is not logged, because the request of JWT has the token in its query
+			reason = err.Error()
+			var urlErr *url.Error
+			if errors.As(err, &urlErr) {
+				reason = urlErr.Err.Error()
+			}
+		} else {
+			reason = fmt.Sprintf("StatusCode %d", res.StatusCode)
+			io.Copy(ioutil.Discard, res.Body)
+			res.Body.Close()
+		}
+
+		wait := sd.Option.backoff(attempt, res)
+		logrus.Warnf("Retrying request to Screwdriver.cd API in %v (%d/%d): %s", wait.Round(time.Millisecond), attempt+1, sd.Option.Retries, reason)
+		sleep(wait)
+	}
+}
@@ -0,0 +1,147 @@
+package screwdriver
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mockSleep() (*[]time.Duration, func()) {
+	waits := make([]time.Duration, 0)
+	sleep = func(d time.Duration) { waits = append(waits, d) }
+	randInt63n = func(n int64) int64 { return n - 1 }
+	return &waits, func() {
+		sleep = time.Sleep
+		randInt63n = rand.Int63n
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	defer func() {
+		randInt63n = rand.Int63n
+		now = time.Now
+	}()
+	randInt63n = func(n int64) int64 { return 0 }
+	now = func() time.Time { return time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC) }
+
+	testCases := []struct {
+		name       string
+		option     Option
+		attempt    int
+		retryAfter string
+		expect     time.Duration
+	}{
+		{name: "first retry", option: Option{}, attempt: 0, expect: 250 * time.Millisecond},
+		{name: "third retry", option: Option{}, attempt: 2, expect: time.Second},
+		{name: "limited by max wait", option: Option{RetryWait: time.Second, RetryMaxWait: 4 * time.Second}, attempt: 5, expect: 2 * time.Second},
+		{name: "retry after seconds", option: Option{}, retryAfter: "3", expect: 3 * time.Second},
+		{name: "retry after date", option: Option{}, retryAfter: "Wed, 01 Jan 2020 00:00:10 GMT", expect: 10 * time.Second},
+		{name: "retry after limited by max wait", option: Option{RetryMaxWait: 5 * time.Second}, retryAfter: "120", expect: 5 * time.Second},
+		{name: "invalid retry after", option: Option{}, retryAfter: "soon", expect: 250 * time.Millisecond},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			res := &http.Response{Header: http.Header{}}
+			if tt.retryAfter != "" {
+				res.Header.Set("Retry-After", tt.retryAfter)
+			}
+			assert.Equal(t, tt.expect, tt.option.backoff(tt.attempt, res))
+		})
+	}
+}
+
+func TestRetry(t *testing.T) {
+	testCases := []struct {
+		name     string
+		retries  int
+		statuses []int
+		requests int
+		waits    int
+		jwt      string
+		err      error
+	}{
+		{name: "success after transient failures", retries: 3, statuses: []int{502, 503, 200}, requests: 3, waits: 2, jwt: "jwt"},
+		{name: "failure after retries", retries: 2, statuses: []int{502, 502, 502, 502}, requests: 3, waits: 2, err: fmt.Errorf("failed to get JWT: StatusCode 502")},
+		{name: "failure without retries", retries: 0, statuses: []int{502, 200}, requests: 1, waits: 0, err: fmt.Errorf("failed to get JWT: StatusCode 502")},
+		{name: "failure not retried", retries: 3, statuses: []int{401, 200}, requests: 1, waits: 0, err: fmt.Errorf("failed to get JWT: StatusCode 401")},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			waits, reset := mockSleep()
+			defer reset()
+
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				status := tt.statuses[requests]
+				requests++
+				w.WriteHeader(status)
+				fmt.Fprintln(w, `{"token":"jwt"}`)
+			}))
+			defer server.Close()
+
+			api := New(server.URL, "token", Option{Retries: tt.retries}).(*sdAPI)
+			err := api.InitJWT()
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.jwt, api.JWT())
+			assert.Equal(t, tt.requests, requests)
+			assert.Len(t, *waits, tt.waits)
+		})
+	}
+}
+
+func TestRetryValidator(t *testing.T) {
+	waits, reset := mockSleep()
+	defer reset()
+
+	bodies := make([]string, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(buf))
+		if len(bodies) == 1 {
+			w.Header().Set("Retry-After", "2")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		testJSON, err := ioutil.ReadFile(filepath.Join(testDir, "validatedSuccess.json"))
+		assert.Nil(t, err)
+		fmt.Fprintln(w, string(testJSON))
+	}))
+	defer server.Close()
+
+	api := New(server.URL, "token", Option{Retries: 3})
+	_, err := api.Job("main", filepath.Join(testDir, "screwdriver.yaml"))
+	assert.Nil(t, err)
+	assert.Len(t, bodies, 2)
+	assert.Equal(t, bodies[0], bodies[1])
+	assert.Equal(t, []time.Duration{2 * time.Second}, *waits)
+}
+
+func TestRetryable(t *testing.T) {
+	testCases := []struct {
+		method string
+		url    string
+		expect bool
+	}{
+		{method: http.MethodGet, url: "https://api.screwdriver.cd/v4/auth/token", expect: true},
+		{method: http.MethodPost, url: "https://api.screwdriver.cd/v4/validator", expect: true},
+		{method: http.MethodPost, url: "https://api.screwdriver.cd/v4/validator/template", expect: true},
+		{method: http.MethodPost, url: "https://api.screwdriver.cd/v4/templates", expect: false},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.method+" "+tt.url, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, tt.url, nil)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expect, retryable(req))
+		})
+	}
+}
@@ -1,7 +1,10 @@
 package screwdriver
 
 import (
+	"bytes"
+	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -10,17 +13,32 @@ import (
 	"path"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
 )
 
 const (
 	apiVersion        = "v4"
 	validatorEndpoint = "validator"
 	tokenEndpoint     = "auth/token"
+	secretsEndpoint   = "pipelines/%d/secrets"
 )
 
 // API has method to get job
 type API interface {
 	Job(jobName, filePath string) (Job, error)
+	Jobs(filePath string) (map[string]Job, error)
+	Validate(filePath string) (Validation, error)
+	Secrets(pipelineID int) (map[string]string, error)
+	RemoteBuild(buildID int) (RemoteBuild, error)
+	Pipeline(pipelineID int) (Pipeline, error)
+	PipelineJobs(pipelineID int) (map[string]Job, error)
+	Template(ref string) (Template, error)
+	ValidateTemplate(filePath string) (Template, error)
+	PublishTemplate(filePath string) (Template, error)
+	ValidateCommand(filePath string) (Command, error)
+	PublishCommand(filePath string) (Command, error)
 	JWT() string
 	InitJWT() error
 }
@@ -30,6 +48,9 @@ type sdAPI struct {
 	UserToken  string
 	APIURL     string
 	SDJWT      string
+	Option     Option
+	// jwtExpiresAt is when SDJWT expires, which is zero when it is unknown
+	jwtExpiresAt time.Time
 }
 
 var _ API = (*sdAPI)(nil)
@@ -42,16 +63,33 @@ type Step struct {
 
 // Job is job entity struct
 type Job struct {
-	Steps       []Step            `json:"commands"`
-	Environment map[string]string `json:"environment"`
-	Image       string            `json:"image"`
+	Steps       []Step                 `json:"commands"`
+	Environment map[string]string      `json:"environment"`
+	Image       string                 `json:"image"`
+	Requires    []string               `json:"requires"`
+	Secrets     []string               `json:"secrets"`
+	Annotations map[string]interface{} `json:"annotations"`
+}
+
+// Secret is secret entity struct
+type Secret struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
 }
 
 type jobs map[string][]Job
 
 type validatorResponse struct {
-	Jobs   jobs     `json:"jobs"`
-	Errors []string `json:"errors"`
+	Jobs     jobs     `json:"jobs"`
+	Errors   []string `json:"errors"`
+	Warnings []string `json:"warnMessages"`
+}
+
+// Validation is the result of validating screwdriver.yaml, which has the jobs only when it has no errors
+type Validation struct {
+	Jobs     map[string]Job
+	Errors   []string
+	Warnings []string
 }
 
 type tokenResponse struct {
@@ -59,11 +97,12 @@ type tokenResponse struct {
 }
 
 // New creates a API
-func New(apiURL, token string) API {
+func New(apiURL, token string, option Option) API {
 	s := &sdAPI{
-		HTTPClient: http.DefaultClient,
+		HTTPClient: newHTTPClient(option),
 		APIURL:     apiURL,
 		UserToken:  token,
+		Option:     option,
 	}
 
 	return s
@@ -74,13 +113,23 @@ func (sd *sdAPI) makeURL(endpoint string) (*url.URL, error) {
 	if err != nil {
 		return nil, err
 	}
-	u.Path = path.Join(u.Path, apiVersion, endpoint)
+	// The endpoint may have escaped parts such as the name of a template with its namespace
+	unescaped, err := url.PathUnescape(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	u.RawPath = path.Join(u.EscapedPath(), apiVersion, endpoint)
+	u.Path = path.Join(u.Path, apiVersion, unescaped)
 
 	return u, nil
 }
 
-func (sd *sdAPI) request(method, path string, body io.Reader) (*http.Response, error) {
-	req, err := http.NewRequest(method, path, body)
+func (sd *sdAPI) newRequest(method, path string, body []byte) (*http.Request, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequest(method, path, reader)
 	if err != nil {
 		return nil, err
 	}
@@ -89,6 +138,9 @@ func (sd *sdAPI) request(method, path string, body io.Reader) (*http.Response, e
 	case http.MethodGet:
 		{
 			req.Header.Add("Accept", "application/json")
+			if sd.SDJWT != "" {
+				req.Header.Add("Authorization", "Bearer "+sd.SDJWT)
+			}
 		}
 	case http.MethodPost, http.MethodPut, http.MethodDelete:
 		{
@@ -97,7 +149,49 @@ func (sd *sdAPI) request(method, path string, body io.Reader) (*http.Response, e
 		}
 	}
 
-	return sd.HTTPClient.Do(req)
+	return req, nil
+}
+
+// request sends the request with JWT, which is refreshed when it expires or is rejected
+func (sd *sdAPI) request(method, path string, body io.Reader) (*http.Response, error) {
+	var buf []byte
+	if body != nil {
+		b, err := ioutil.ReadAll(body)
+		if err != nil {
+			return nil, err
+		}
+		buf = b
+	}
+
+	if sd.jwtExpired() {
+		if err := sd.refreshJWT(); err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := sd.newRequest(method, path, buf)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := sd.do(req)
+	if err != nil || res.StatusCode != http.StatusUnauthorized || sd.SDJWT == "" || sd.UserToken == "" {
+		return res, err
+	}
+
+	// The cached JWT may be revoked before it expires
+	res.Body.Close()
+	logrus.Debug("JWT is rejected by Screwdriver.cd API, so it is refreshed")
+	if err := sd.refreshJWT(); err != nil {
+		return nil, err
+	}
+
+	req, err = sd.newRequest(method, path, buf)
+	if err != nil {
+		return nil, err
+	}
+
+	return sd.do(req)
 }
 
 func (sd *sdAPI) jwt() (string, error) {
@@ -110,9 +204,16 @@ func (sd *sdAPI) jwt() (string, error) {
 	query.Set("api_token", sd.UserToken)
 	fullpath.RawQuery = query.Encode()
 
-	res, err := sd.request(http.MethodGet, fullpath.String(), nil)
+	// The token is requested without the current JWT, which may be expired
+	req, err := http.NewRequest(http.MethodGet, fullpath.String(), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to send request: %w", err)
+	}
+	req.Header.Add("Accept", "application/json")
+
+	res, err := sd.do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to send request: %v", err)
+		return "", fmt.Errorf("failed to send request: %w", err)
 	}
 	if res.StatusCode != http.StatusOK {
 		return "", fmt.Errorf("failed to get JWT: StatusCode %d", res.StatusCode)
@@ -136,7 +237,7 @@ func readScrewdriverYAML(filePath string) (string, error) {
 	return string(yaml), nil
 }
 
-func (sd *sdAPI) validate(filePath string) (jobs, error) {
+func (sd *sdAPI) postValidator(filePath string) (*validatorResponse, error) {
 	fullpath, err := sd.makeURL(validatorEndpoint)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make request url: %v", err)
@@ -152,7 +253,7 @@ func (sd *sdAPI) validate(filePath string) (jobs, error) {
 
 	res, err := sd.request(http.MethodPost, fullpath.String(), strings.NewReader(body))
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %v", err)
+		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
 	defer res.Body.Close()
 
@@ -166,6 +267,15 @@ func (sd *sdAPI) validate(filePath string) (jobs, error) {
 		return nil, fmt.Errorf("failed to parse validator response: %v", err)
 	}
 
+	return v, nil
+}
+
+func (sd *sdAPI) validate(filePath string) (jobs, error) {
+	v, err := sd.postValidator(filePath)
+	if err != nil {
+		return nil, err
+	}
+
 	if v.Errors != nil {
 		return nil, fmt.Errorf("failed to parse screwdriver.yaml: %v", v.Errors)
 	}
@@ -180,6 +290,43 @@ func (sd *sdAPI) Job(jobName, filepath string) (Job, error) {
 		return Job{}, err
 	}
 
+	return findJob(jobs, jobName)
+}
+
+// Jobs returns all jobs in screwdriver.yaml
+func (sd *sdAPI) Jobs(filepath string) (map[string]Job, error) {
+	jobs, err := sd.validate(filepath)
+	if err != nil {
+		return nil, err
+	}
+
+	return jobs.flatten(), nil
+}
+
+// Validate returns the warnings and the errors of screwdriver.yaml found by the validator of the API
+func (sd *sdAPI) Validate(filePath string) (Validation, error) {
+	v, err := sd.postValidator(filePath)
+	if err != nil {
+		return Validation{}, err
+	}
+
+	if len(v.Errors) != 0 {
+		return Validation{Errors: v.Errors, Warnings: v.Warnings}, nil
+	}
+	return Validation{Jobs: v.Jobs.flatten(), Warnings: v.Warnings}, nil
+}
+
+func (j jobs) flatten() map[string]Job {
+	flattened := make(map[string]Job, len(j))
+	for name, job := range j {
+		if len(job) != 0 {
+			flattened[name] = job[0]
+		}
+	}
+	return flattened
+}
+
+func findJob(jobs jobs, jobName string) (Job, error) {
 	job, ok := jobs[jobName]
 	if !ok {
 		return Job{}, fmt.Errorf("not found '%s' in parsed screwdriver.yaml", jobName)
@@ -188,15 +335,54 @@ func (sd *sdAPI) Job(jobName, filepath string) (Job, error) {
 	return job[0], nil
 }
 
-func (sd *sdAPI) InitJWT() error {
-	jwt, err := sd.jwt()
+// Secrets returns the secrets of the pipeline as a map of their names to values.
+// It fails if the values are not returned, which needs a pipeline token.
+func (sd *sdAPI) Secrets(pipelineID int) (map[string]string, error) {
+	fullpath, err := sd.makeURL(fmt.Sprintf(secretsEndpoint, pipelineID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request url: %v", err)
+	}
+
+	res, err := sd.request(http.MethodGet, fullpath.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get secrets of pipeline %d: StatusCode %d", pipelineID, res.StatusCode)
+	}
+
+	secrets := make([]Secret, 0)
+	err = json.NewDecoder(res.Body).Decode(&secrets)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("failed to parse secrets response: %v", err)
+	}
+
+	values := make(map[string]string, len(secrets))
+	for _, secret := range secrets {
+		// The API returns the secrets without their values unless the token is a pipeline token
+		if secret.Value == "" {
+			return nil, fmt.Errorf("failed to get the value of secret %s of pipeline %d: a pipeline token is required, set it with `sd-local config set token-type pipeline`", secret.Name, pipelineID)
+		}
+		values[secret.Name] = secret.Value
 	}
 
-	sd.SDJWT = jwt
+	return values, nil
+}
+
+// IsUnreachable reports whether err is caused by failing to reach Screwdriver.cd API.
+// The API which is reached but fails to be verified is not unreachable, because the certificates must be fixed.
+func IsUnreachable(err error) bool {
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		return false
+	}
 
-	return nil
+	var authorityErr x509.UnknownAuthorityError
+	var invalidErr x509.CertificateInvalidError
+	var hostnameErr x509.HostnameError
+	return !errors.As(err, &authorityErr) && !errors.As(err, &invalidErr) && !errors.As(err, &hostnameErr)
 }
 
 // JWT returns JWT token for screwdriver API
@@ -28,11 +28,12 @@ func TestNew(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		testToken := "token"
 
-		gotAPI := New("http://example.com:yyy", testToken)
+		gotAPI := New("http://example.com:yyy", testToken, Option{Retries: 3})
 		api, ok := gotAPI.(*sdAPI)
 		assert.True(t, ok)
 		assert.Equal(t, testToken, api.UserToken)
 		assert.Equal(t, "http://example.com:yyy", api.APIURL)
+		assert.Equal(t, Option{Retries: 3}, api.Option)
 	})
 }
 
@@ -73,7 +74,8 @@ func TestJob(t *testing.T) {
 			Environment: map[string]string{
 				"TEST_ENV": "hoge",
 			},
-			Image: "alpine",
+			Image:    "alpine",
+			Requires: []string{"~commit"},
 		}
 
 		gotJob, err := testAPI.Job("main", filepath.Join(testDir, "screwdriver.yaml"))
@@ -224,6 +226,169 @@ func TestJob(t *testing.T) {
 	})
 }
 
+func TestJobs(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(200)
+			w.Header().Set("Content-Type", "application/json")
+
+			testJSON, err := ioutil.ReadFile(filepath.Join(testDir, "validatedSuccess.json"))
+			assert.Nil(t, err)
+			fmt.Fprintln(w, string(testJSON))
+		}))
+
+		testAPI := sdAPI{
+			HTTPClient: http.DefaultClient,
+			UserToken:  "dummy",
+			APIURL:     server.URL,
+			SDJWT:      "jwt",
+		}
+
+		gotJobs, err := testAPI.Jobs(filepath.Join(testDir, "screwdriver.yaml"))
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(gotJobs))
+		assert.Equal(t, "alpine", gotJobs["main"].Image)
+		assert.Equal(t, []string{"~commit"}, gotJobs["main"].Requires)
+	})
+}
+
+func TestValidate(t *testing.T) {
+	newServer := func(file string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(200)
+			w.Header().Set("Content-Type", "application/json")
+
+			testJSON, err := ioutil.ReadFile(filepath.Join(testDir, file))
+			assert.Nil(t, err)
+			fmt.Fprintln(w, string(testJSON))
+		}))
+	}
+
+	t.Run("success with warnings", func(t *testing.T) {
+		server := newServer("validatedWarnings.json")
+		defer server.Close()
+
+		testAPI := sdAPI{
+			HTTPClient: http.DefaultClient,
+			UserToken:  "dummy",
+			APIURL:     server.URL,
+			SDJWT:      "jwt",
+		}
+
+		v, err := testAPI.Validate(filepath.Join(testDir, "screwdriver.yaml"))
+		assert.Nil(t, err)
+		assert.Equal(t, "alpine", v.Jobs["main"].Image)
+		assert.Empty(t, v.Errors)
+		assert.Equal(t, []string{"jobs.main.annotations.screwdriver.cd/unknown is not a valid annotation"}, v.Warnings)
+	})
+
+	t.Run("success with errors", func(t *testing.T) {
+		server := newServer("validatedFailed.json")
+		defer server.Close()
+
+		testAPI := sdAPI{
+			HTTPClient: http.DefaultClient,
+			UserToken:  "dummy",
+			APIURL:     server.URL,
+			SDJWT:      "jwt",
+		}
+
+		v, err := testAPI.Validate(filepath.Join(testDir, "screwdriver.yaml"))
+		assert.Nil(t, err)
+		assert.Nil(t, v.Jobs)
+		assert.Equal(t, []string{"validate error"}, v.Errors)
+	})
+
+	t.Run("failure by status code", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(500)
+		}))
+		defer server.Close()
+
+		testAPI := sdAPI{
+			HTTPClient: http.DefaultClient,
+			UserToken:  "dummy",
+			APIURL:     server.URL,
+			SDJWT:      "jwt",
+		}
+
+		_, err := testAPI.Validate(filepath.Join(testDir, "screwdriver.yaml"))
+		assert.Equal(t, "failed to post validator: StatusCode 500", err.Error())
+	})
+}
+
+func TestSecrets(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			validateHeader(t, "Accept", "application/json", r)
+			validateHeader(t, "Authorization", "Bearer jwt", r)
+			assert.Equal(t, "/v4/pipelines/123/secrets", r.URL.Path)
+
+			w.WriteHeader(200)
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintln(w, `[{"id":1,"pipelineId":123,"name":"GIT_KEY","value":"git-key","allowInPR":false},{"id":2,"pipelineId":123,"name":"NPM_TOKEN","value":"npm-token","allowInPR":true}]`)
+		}))
+
+		testAPI := sdAPI{
+			HTTPClient: http.DefaultClient,
+			UserToken:  "dummy",
+			APIURL:     server.URL,
+			SDJWT:      "jwt",
+		}
+
+		secrets, err := testAPI.Secrets(123)
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]string{"GIT_KEY": "git-key", "NPM_TOKEN": "npm-token"}, secrets)
+	})
+
+	t.Run("failure by status", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(403)
+		}))
+
+		testAPI := sdAPI{
+			HTTPClient: http.DefaultClient,
+			APIURL:     server.URL,
+			SDJWT:      "jwt",
+		}
+
+		_, err := testAPI.Secrets(123)
+		assert.Equal(t, "failed to get secrets of pipeline 123: StatusCode 403", err.Error())
+	})
+
+	t.Run("failure by user token", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(200)
+			fmt.Fprintln(w, `[{"id":1,"pipelineId":123,"name":"GIT_KEY","allowInPR":false}]`)
+		}))
+
+		testAPI := sdAPI{
+			HTTPClient: http.DefaultClient,
+			APIURL:     server.URL,
+			SDJWT:      "jwt",
+		}
+
+		_, err := testAPI.Secrets(123)
+		assert.Equal(t, "failed to get the value of secret GIT_KEY of pipeline 123: a pipeline token is required, set it with `sd-local config set token-type pipeline`", err.Error())
+	})
+
+	t.Run("failure by invalid JSON", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(200)
+			fmt.Fprintln(w, `{`)
+		}))
+
+		testAPI := sdAPI{
+			HTTPClient: http.DefaultClient,
+			APIURL:     server.URL,
+			SDJWT:      "jwt",
+		}
+
+		_, err := testAPI.Secrets(123)
+		assert.Contains(t, err.Error(), "failed to parse secrets response:")
+	})
+}
+
 func TestInitJWT(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		testJWT := "jwt"
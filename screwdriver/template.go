@@ -0,0 +1,384 @@
+package screwdriver
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-yaml/yaml"
+)
+
+const (
+	templateEndpoint          = "templates/%s/%s"
+	templatesEndpoint         = "templates"
+	templateValidatorEndpoint = "validator/template"
+	// defaultTemplateVersion is the tag used when the version of the template is omitted
+	defaultTemplateVersion = "latest"
+)
+
+// Template is a published template of jobs.
+// Config is the settings of the job, which are overridden by the job using the template.
+type Template struct {
+	Name    string   `json:"name"`
+	Version string   `json:"version"`
+	Config  localJob `json:"config"`
+}
+
+// parseTemplateRef splits the template in the form of <name>[@<version or tag>].
+func parseTemplateRef(ref string) (string, string) {
+	if i := strings.LastIndex(ref, "@"); i >= 0 {
+		return ref[:i], ref[i+1:]
+	}
+	return ref, defaultTemplateVersion
+}
+
+// Template returns the template of ref, which is the value of `template` in screwdriver.yaml
+func (sd *sdAPI) Template(ref string) (Template, error) {
+	name, version := parseTemplateRef(ref)
+
+	t := new(Template)
+	endpoint := fmt.Sprintf(templateEndpoint, url.PathEscape(name), url.PathEscape(version))
+	if err := sd.get(endpoint, fmt.Sprintf("template %s", ref), t); err != nil {
+		return Template{}, err
+	}
+
+	return *t, nil
+}
+
+// TemplateRefs returns the templates used by the jobs in screwdriver.yaml, sorted and without duplicates.
+func TemplateRefs(filePath string) ([]string, error) {
+	raw, err := readScrewdriverYAML(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	c := new(localConfig)
+	if err := yaml.Unmarshal([]byte(raw), c); err != nil {
+		return nil, fmt.Errorf("failed to parse screwdriver.yaml: %v", err)
+	}
+
+	seen := make(map[string]bool)
+	refs := make([]string, 0)
+	for _, j := range c.Jobs {
+		if j.Template != "" && !seen[j.Template] {
+			seen[j.Template] = true
+			refs = append(refs, j.Template)
+		}
+	}
+	sort.Strings(refs)
+
+	return refs, nil
+}
+
+func templateCachePath(dir, ref string) string {
+	return filepath.Join(dir, url.PathEscape(ref)+".json")
+}
+
+// SaveTemplate stores the template into dir, so that the jobs using ref can be resolved in offline mode.
+func SaveTemplate(dir, ref string, t Template) error {
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return fmt.Errorf("failed to create template cache directory: %v", err)
+	}
+
+	buf, err := json.Marshal(t)
+	if err != nil {
+		return fmt.Errorf("failed to encode template %s: %v", ref, err)
+	}
+
+	// The template is written into a temporary file first, so that a broken cache is not left
+	tmp, err := ioutil.TempFile(dir, ".template")
+	if err != nil {
+		return fmt.Errorf("failed to write template cache: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(buf)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write template cache: %v", err)
+	}
+
+	if err := os.Rename(tmp.Name(), templateCachePath(dir, ref)); err != nil {
+		return fmt.Errorf("failed to write template cache: %v", err)
+	}
+
+	return nil
+}
+
+// loadTemplate returns the template of ref stored with SaveTemplate.
+func loadTemplate(dir, ref string) (Template, error) {
+	buf, err := ioutil.ReadFile(templateCachePath(dir, ref))
+	if err != nil {
+		return Template{}, err
+	}
+
+	t := Template{}
+	if err := json.Unmarshal(buf, &t); err != nil {
+		return Template{}, fmt.Errorf("failed to parse template cache of %s: %v", ref, err)
+	}
+
+	return t, nil
+}
+
+// templateFile is the definition of a template such as sd-template.yaml
+type templateFile struct {
+	Namespace string   `yaml:"namespace" json:"namespace"`
+	Name      string   `yaml:"name" json:"name"`
+	Version   string   `yaml:"version" json:"version"`
+	Config    localJob `yaml:"config" json:"config"`
+}
+
+// template returns the template whose name includes the namespace
+func (f templateFile) template() Template {
+	name := f.Name
+	if f.Namespace != "" {
+		name = f.Namespace + "/" + f.Name
+	}
+
+	return Template{Name: name, Version: f.Version, Config: f.Config}
+}
+
+// ReadTemplate reads the template from the definition such as sd-template.yaml, which may not be published yet.
+// The name of the template includes its namespace.
+func ReadTemplate(filePath string) (Template, error) {
+	raw, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return Template{}, fmt.Errorf("failed to read template: %v", err)
+	}
+
+	f := new(templateFile)
+	if err := yaml.Unmarshal(raw, f); err != nil {
+		return Template{}, fmt.Errorf("failed to parse template: %v", err)
+	}
+	if f.Name == "" {
+		return Template{}, fmt.Errorf("failed to parse template: name is not defined")
+	}
+	if f.Config.Image == "" || len(f.Config.Steps) == 0 {
+		return Template{}, fmt.Errorf("failed to parse template: config must have image and steps")
+	}
+
+	return f.template(), nil
+}
+
+// usedBy reports whether the template is the one of ref regardless of the version.
+// The templates in the default namespace can be used without the namespace.
+func (t Template) usedBy(ref string) bool {
+	name, _ := parseTemplateRef(ref)
+	return name == t.Name || "default/"+name == t.Name
+}
+
+func mapValue(m yaml.MapSlice, key string) (interface{}, bool) {
+	for _, item := range m {
+		if item.Key == key {
+			return item.Value, true
+		}
+	}
+	return nil, false
+}
+
+// mergeMapSlice returns the mapping of base overridden by override, in which the keys only in base come first.
+func mergeMapSlice(base yaml.MapSlice, override interface{}) interface{} {
+	o, ok := override.(yaml.MapSlice)
+	if !ok {
+		return override
+	}
+
+	merged := make(yaml.MapSlice, 0, len(base)+len(o))
+	for _, item := range base {
+		if _, ok := mapValue(o, item.Key.(string)); !ok {
+			merged = append(merged, item)
+		}
+	}
+	return append(merged, o...)
+}
+
+func stringMapSlice(m map[string]string) yaml.MapSlice {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	s := make(yaml.MapSlice, 0, len(m))
+	for _, k := range keys {
+		s = append(s, yaml.MapItem{Key: k, Value: m[k]})
+	}
+	return s
+}
+
+func interfaceMapSlice(m map[string]interface{}) yaml.MapSlice {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	s := make(yaml.MapSlice, 0, len(m))
+	for _, k := range keys {
+		s = append(s, yaml.MapItem{Key: k, Value: m[k]})
+	}
+	return s
+}
+
+// inlineJob returns the job in screwdriver.yaml with the config of the template instead of `template`.
+// The settings of the job take precedence over the config, as the jobs using the published template.
+func inlineJob(job yaml.MapSlice, config localJob) yaml.MapSlice {
+	inlined := make(yaml.MapSlice, 0, len(job)+5)
+	for _, item := range job {
+		switch item.Key {
+		case "template":
+			continue
+		case "environment":
+			item.Value = mergeMapSlice(stringMapSlice(config.Environment), item.Value)
+		case "annotations":
+			item.Value = mergeMapSlice(interfaceMapSlice(config.Annotations), item.Value)
+		case "secrets":
+			if list, ok := item.Value.([]interface{}); ok {
+				secrets := make([]interface{}, 0, len(config.Secrets)+len(list))
+				for _, s := range config.Secrets {
+					secrets = append(secrets, s)
+				}
+				for _, s := range list {
+					secrets = append(secrets, s)
+				}
+				item.Value = secrets
+			}
+		}
+		inlined = append(inlined, item)
+	}
+
+	if _, ok := mapValue(job, "image"); !ok {
+		inlined = append(inlined, yaml.MapItem{Key: "image", Value: config.Image})
+	}
+	if _, ok := mapValue(job, "steps"); !ok {
+		steps := make([]interface{}, 0, len(config.Steps))
+		for _, s := range config.Steps {
+			steps = append(steps, stringMapSlice(s))
+		}
+		inlined = append(inlined, yaml.MapItem{Key: "steps", Value: steps})
+	}
+	if _, ok := mapValue(job, "environment"); !ok && len(config.Environment) != 0 {
+		inlined = append(inlined, yaml.MapItem{Key: "environment", Value: stringMapSlice(config.Environment)})
+	}
+	if _, ok := mapValue(job, "secrets"); !ok && len(config.Secrets) != 0 {
+		inlined = append(inlined, yaml.MapItem{Key: "secrets", Value: config.Secrets})
+	}
+	if _, ok := mapValue(job, "annotations"); !ok && len(config.Annotations) != 0 {
+		inlined = append(inlined, yaml.MapItem{Key: "annotations", Value: interfaceMapSlice(config.Annotations)})
+	}
+
+	return inlined
+}
+
+// InlineTemplate returns screwdriver.yaml whose jobs using the template have its config instead of `template`,
+// so that the template can be used before it is published. The other settings of screwdriver.yaml are kept.
+func InlineTemplate(filePath string, t Template) ([]byte, error) {
+	raw, err := readScrewdriverYAML(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := yaml.MapSlice{}
+	if err := yaml.Unmarshal([]byte(raw), &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse screwdriver.yaml: %v", err)
+	}
+
+	inlined := 0
+	value, _ := mapValue(doc, "jobs")
+	jobs, _ := value.(yaml.MapSlice)
+	for i, item := range jobs {
+		job, ok := item.Value.(yaml.MapSlice)
+		if !ok {
+			continue
+		}
+		ref, _ := mapValue(job, "template")
+		if r, ok := ref.(string); !ok || !t.usedBy(r) {
+			continue
+		}
+		jobs[i].Value = inlineJob(job, t.Config)
+		inlined++
+	}
+	if inlined == 0 {
+		return nil, fmt.Errorf("no jobs use template %s in screwdriver.yaml", t.Name)
+	}
+
+	out, err := yaml.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode screwdriver.yaml: %v", err)
+	}
+
+	return out, nil
+}
+
+type templateValidatorResponse struct {
+	Errors   []json.RawMessage `json:"errors"`
+	Template templateFile      `json:"template"`
+}
+
+// validationMessages returns the messages of the validation errors, which are either strings or objects with the message.
+func validationMessages(errs []json.RawMessage) []string {
+	messages := make([]string, 0, len(errs))
+	for _, raw := range errs {
+		var message string
+		if err := json.Unmarshal(raw, &message); err == nil {
+			messages = append(messages, message)
+			continue
+		}
+		e := new(errorResponse)
+		if err := json.Unmarshal(raw, e); err == nil && e.Message != "" {
+			messages = append(messages, e.Message)
+			continue
+		}
+		messages = append(messages, string(raw))
+	}
+	return messages
+}
+
+func readTemplateFile(filePath string) (map[string]string, error) {
+	raw, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template: %v", err)
+	}
+	return map[string]string{"yaml": string(raw)}, nil
+}
+
+// ValidateTemplate validates the template definition such as sd-template.yaml with Screwdriver.cd API
+func (sd *sdAPI) ValidateTemplate(filePath string) (Template, error) {
+	body, err := readTemplateFile(filePath)
+	if err != nil {
+		return Template{}, err
+	}
+
+	v := new(templateValidatorResponse)
+	if err := sd.post(templateValidatorEndpoint, "template validator", body, http.StatusOK, v); err != nil {
+		return Template{}, err
+	}
+	if len(v.Errors) != 0 {
+		return Template{}, fmt.Errorf("template is invalid: %v", validationMessages(v.Errors))
+	}
+
+	return v.Template.template(), nil
+}
+
+// PublishTemplate publishes the template definition such as sd-template.yaml, and returns the published template with its version.
+func (sd *sdAPI) PublishTemplate(filePath string) (Template, error) {
+	body, err := readTemplateFile(filePath)
+	if err != nil {
+		return Template{}, err
+	}
+
+	f := new(templateFile)
+	if err := sd.post(templatesEndpoint, "template", body, http.StatusCreated, f); err != nil {
+		return Template{}, err
+	}
+
+	return f.template(), nil
+}
@@ -0,0 +1,329 @@
+package screwdriver
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-yaml/yaml"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		validateHeader(t, "Authorization", "Bearer jwt", r)
+
+		switch r.URL.EscapedPath() {
+		case "/v4/templates/sd%2Fnoop/1.0.0", "/v4/templates/sd%2Fnoop/latest":
+			w.WriteHeader(200)
+			w.Write([]byte(`{"name":"sd/noop","version":"1.0.0","config":{"image":"alpine","steps":[{"noop":"echo noop"}],"environment":{"FOO":"foo"}}}`))
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer server.Close()
+
+	testAPI := sdAPI{
+		HTTPClient: http.DefaultClient,
+		APIURL:     server.URL,
+		SDJWT:      "jwt",
+	}
+
+	want := Template{
+		Name:    "sd/noop",
+		Version: "1.0.0",
+		Config: localJob{
+			Image:       "alpine",
+			Steps:       []map[string]string{{"noop": "echo noop"}},
+			Environment: map[string]string{"FOO": "foo"},
+		},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		got, err := testAPI.Template("sd/noop@1.0.0")
+		assert.Nil(t, err)
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("success without version", func(t *testing.T) {
+		got, err := testAPI.Template("sd/noop")
+		assert.Nil(t, err)
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("failure by not found template", func(t *testing.T) {
+		_, err := testAPI.Template("sd/other@1")
+		assert.Equal(t, "failed to get template sd/other@1: StatusCode 404", err.Error())
+	})
+}
+
+func TestTemplateRefs(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		refs, err := TemplateRefs(filepath.Join(testDir, "screwdriverTemplate.yaml"))
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"sd/noop@latest"}, refs)
+	})
+
+	t.Run("success without templates", func(t *testing.T) {
+		refs, err := TemplateRefs(filepath.Join(testDir, "screwdriver.yaml"))
+		assert.Nil(t, err)
+		assert.Equal(t, []string{}, refs)
+	})
+
+	t.Run("failure by not found file", func(t *testing.T) {
+		_, err := TemplateRefs(filepath.Join(testDir, "notFound.yaml"))
+		assert.NotNil(t, err)
+	})
+}
+
+func TestSaveTemplate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "templates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	template := Template{
+		Name:    "sd/noop",
+		Version: "1.0.0",
+		Config:  localJob{Image: "alpine", Steps: []map[string]string{{"noop": "echo noop"}}},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		err := SaveTemplate(filepath.Join(dir, "sub"), "sd/noop@latest", template)
+		assert.Nil(t, err)
+
+		got, err := loadTemplate(filepath.Join(dir, "sub"), "sd/noop@latest")
+		assert.Nil(t, err)
+		assert.Equal(t, template, got)
+	})
+
+	t.Run("failure by not cached template", func(t *testing.T) {
+		_, err := loadTemplate(dir, "sd/other@1")
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("failure by broken cache", func(t *testing.T) {
+		if err := ioutil.WriteFile(templateCachePath(dir, "sd/broken@1"), []byte("{"), 0666); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := loadTemplate(dir, "sd/broken@1")
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "failed to parse template cache of sd/broken@1")
+	})
+}
+
+func TestReadTemplate(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		template, err := ReadTemplate(filepath.Join(testDir, "sd-template.yaml"))
+		assert.Nil(t, err)
+		assert.Equal(t, Template{
+			Name:    "sd/noop",
+			Version: "1.1.0",
+			Config: localJob{
+				Image:       "alpine",
+				Steps:       []map[string]string{{"noop": "echo noop"}},
+				Environment: map[string]string{"FOO": "foo", "BAR": "bar"},
+				Secrets:     []string{"NOOP_TOKEN"},
+			},
+		}, template)
+	})
+
+	t.Run("failure by not found file", func(t *testing.T) {
+		_, err := ReadTemplate(filepath.Join(testDir, "notFound.yaml"))
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "failed to read template")
+	})
+}
+
+func TestTemplateUsedBy(t *testing.T) {
+	testCases := []struct {
+		name     string
+		template string
+		ref      string
+		expected bool
+	}{
+		{"same name", "sd/noop", "sd/noop@1.0.0", true},
+		{"without version", "sd/noop", "sd/noop", true},
+		{"other name", "sd/noop", "sd/other@1", false},
+		{"default namespace", "default/noop", "noop@1", true},
+		{"other namespace", "sd/noop", "noop@1", false},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Template{Name: tt.template}.usedBy(tt.ref))
+		})
+	}
+}
+
+func TestInlineJob(t *testing.T) {
+	config := localJob{
+		Image:       "alpine",
+		Steps:       []map[string]string{{"noop": "echo noop"}},
+		Environment: map[string]string{"FOO": "foo", "BAR": "bar"},
+		Secrets:     []string{"NOOP_TOKEN"},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		job := yaml.MapSlice{
+			{Key: "template", Value: "sd/noop@1"},
+			{Key: "requires", Value: []interface{}{"~commit"}},
+		}
+
+		assert.Equal(t, yaml.MapSlice{
+			{Key: "requires", Value: []interface{}{"~commit"}},
+			{Key: "image", Value: "alpine"},
+			{Key: "steps", Value: []interface{}{yaml.MapSlice{{Key: "noop", Value: "echo noop"}}}},
+			{Key: "environment", Value: yaml.MapSlice{{Key: "BAR", Value: "bar"}, {Key: "FOO", Value: "foo"}}},
+			{Key: "secrets", Value: []string{"NOOP_TOKEN"}},
+		}, inlineJob(job, config))
+	})
+
+	t.Run("success with settings of job", func(t *testing.T) {
+		job := yaml.MapSlice{
+			{Key: "template", Value: "sd/noop@1"},
+			{Key: "image", Value: "node:12"},
+			{Key: "environment", Value: yaml.MapSlice{{Key: "FOO", Value: "job"}}},
+			{Key: "secrets", Value: []interface{}{"GIT_KEY"}},
+		}
+
+		assert.Equal(t, yaml.MapSlice{
+			{Key: "image", Value: "node:12"},
+			{Key: "environment", Value: yaml.MapSlice{{Key: "BAR", Value: "bar"}, {Key: "FOO", Value: "job"}}},
+			{Key: "secrets", Value: []interface{}{"NOOP_TOKEN", "GIT_KEY"}},
+			{Key: "steps", Value: []interface{}{yaml.MapSlice{{Key: "noop", Value: "echo noop"}}}},
+		}, inlineJob(job, config))
+	})
+}
+
+func TestInlineTemplate(t *testing.T) {
+	template := Template{Name: "sd/noop", Config: localJob{Image: "alpine", Steps: []map[string]string{{"noop": "echo noop"}}}}
+
+	t.Run("success", func(t *testing.T) {
+		out, err := InlineTemplate(filepath.Join(testDir, "screwdriverTemplate.yaml"), template)
+		assert.Nil(t, err)
+		assert.Equal(t, "jobs:\n  main:\n    image: alpine\n    steps:\n    - noop: echo noop\n", string(out))
+	})
+
+	t.Run("failure by not used template", func(t *testing.T) {
+		_, err := InlineTemplate(filepath.Join(testDir, "screwdriverTemplate.yaml"), Template{Name: "sd/other"})
+		assert.Equal(t, "no jobs use template sd/other in screwdriver.yaml", err.Error())
+	})
+}
+
+func TestValidateTemplate(t *testing.T) {
+	responses := map[string]struct {
+		status int
+		body   string
+	}{
+		"valid":       {200, `{"errors":[],"template":{"namespace":"sd","name":"noop","version":"1.1.0","config":{"image":"alpine","steps":[{"noop":"echo noop"}]}}}`},
+		"invalid":     {200, `{"errors":[{"message":"\"name\" is required","path":["name"]},"\"config\" is required"],"template":{}}`},
+		"unavailable": {503, `{"statusCode":503,"error":"Service Unavailable","message":"down"}`},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		validateHeader(t, "Content-Type", "application/json", r)
+		assert.Equal(t, "/v4/validator/template", r.URL.Path)
+
+		body := map[string]string{}
+		json.NewDecoder(r.Body).Decode(&body)
+		res := responses[body["yaml"]]
+		w.WriteHeader(res.status)
+		w.Write([]byte(res.body))
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "template")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	templateFile := func(content string) string {
+		path := filepath.Join(dir, content+".yaml")
+		if err := ioutil.WriteFile(path, []byte(content), 0666); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	testAPI := sdAPI{HTTPClient: http.DefaultClient, APIURL: server.URL, SDJWT: "jwt"}
+
+	t.Run("success", func(t *testing.T) {
+		got, err := testAPI.ValidateTemplate(templateFile("valid"))
+		assert.Nil(t, err)
+		assert.Equal(t, Template{
+			Name:    "sd/noop",
+			Version: "1.1.0",
+			Config:  localJob{Image: "alpine", Steps: []map[string]string{{"noop": "echo noop"}}},
+		}, got)
+	})
+
+	t.Run("failure by invalid template", func(t *testing.T) {
+		_, err := testAPI.ValidateTemplate(templateFile("invalid"))
+		assert.Equal(t, `template is invalid: ["name" is required "config" is required]`, err.Error())
+	})
+
+	t.Run("failure by error response", func(t *testing.T) {
+		_, err := testAPI.ValidateTemplate(templateFile("unavailable"))
+		assert.Equal(t, "failed to post template validator: StatusCode 503: down", err.Error())
+	})
+
+	t.Run("failure by not found file", func(t *testing.T) {
+		_, err := testAPI.ValidateTemplate(filepath.Join(dir, "notFound.yaml"))
+		assert.Contains(t, err.Error(), "failed to read template")
+	})
+}
+
+func TestPublishTemplate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		validateHeader(t, "Authorization", "Bearer jwt", r)
+		assert.Equal(t, "/v4/templates", r.URL.Path)
+
+		body := map[string]string{}
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["yaml"] == "forbidden" {
+			w.WriteHeader(403)
+			w.Write([]byte(`{"statusCode":403,"error":"Forbidden","message":"Not allowed to publish this template"}`))
+			return
+		}
+		w.WriteHeader(201)
+		w.Write([]byte(`{"id":1,"namespace":"sd","name":"noop","version":"1.1.3","config":{"image":"alpine"}}`))
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "template")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	testAPI := sdAPI{HTTPClient: http.DefaultClient, APIURL: server.URL, SDJWT: "jwt"}
+
+	t.Run("success", func(t *testing.T) {
+		path := filepath.Join(dir, "sd-template.yaml")
+		if err := ioutil.WriteFile(path, []byte("name: noop"), 0666); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := testAPI.PublishTemplate(path)
+		assert.Nil(t, err)
+		assert.Equal(t, Template{Name: "sd/noop", Version: "1.1.3", Config: localJob{Image: "alpine"}}, got)
+	})
+
+	t.Run("failure by forbidden", func(t *testing.T) {
+		path := filepath.Join(dir, "forbidden.yaml")
+		if err := ioutil.WriteFile(path, []byte("forbidden"), 0666); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := testAPI.PublishTemplate(path)
+		assert.Equal(t, "failed to post template: StatusCode 403: Not allowed to publish this template", err.Error())
+	})
+}
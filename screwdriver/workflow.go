@@ -0,0 +1,118 @@
+package screwdriver
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// UpstreamJobs returns names of the jobs in the pipeline which trigger the job.
+// Triggers outside the pipeline (e.g. ~commit, ~pr, ~sd@123:main) are ignored.
+func UpstreamJobs(job Job, jobs map[string]Job) []string {
+	upstreams := make([]string, 0, len(job.Requires))
+	for _, r := range job.Requires {
+		name := strings.TrimPrefix(r, "~")
+		if _, ok := jobs[name]; ok {
+			upstreams = append(upstreams, name)
+		}
+	}
+	return upstreams
+}
+
+// WorkflowOrder sorts the jobs topologically along with their requires.
+// Jobs which can run at the same time are sorted by name to make the order stable.
+func WorkflowOrder(jobs map[string]Job) ([]string, error) {
+	indegree := make(map[string]int, len(jobs))
+	downstreams := make(map[string][]string, len(jobs))
+
+	for name, job := range jobs {
+		if _, ok := indegree[name]; !ok {
+			indegree[name] = 0
+		}
+		for _, up := range UpstreamJobs(job, jobs) {
+			indegree[name]++
+			downstreams[up] = append(downstreams[up], name)
+		}
+	}
+
+	ready := make([]string, 0, len(jobs))
+	for name, n := range indegree {
+		if n == 0 {
+			ready = append(ready, name)
+		}
+	}
+
+	order := make([]string, 0, len(jobs))
+	for len(ready) != 0 {
+		sort.Strings(ready)
+		name := ready[0]
+		ready = ready[1:]
+		order = append(order, name)
+
+		for _, down := range downstreams[name] {
+			indegree[down]--
+			if indegree[down] == 0 {
+				ready = append(ready, down)
+			}
+		}
+	}
+
+	if len(order) != len(jobs) {
+		cyclic := make([]string, 0)
+		for name, n := range indegree {
+			if n > 0 {
+				cyclic = append(cyclic, name)
+			}
+		}
+		sort.Strings(cyclic)
+		return nil, fmt.Errorf("workflow has a cycle in jobs: %v", cyclic)
+	}
+
+	return order, nil
+}
+
+const (
+	// PRTrigger is the requires of the jobs which are triggered by pull requests.
+	PRTrigger = "~pr"
+	// ChainPRAnnotation is the annotation which makes the downstream jobs of PR jobs run in PR builds.
+	ChainPRAnnotation = "screwdriver.cd/chainPR"
+)
+
+// IsPRJob reports whether the job is triggered by pull requests.
+func IsPRJob(job Job) bool {
+	for _, r := range job.Requires {
+		if r == PRTrigger {
+			return true
+		}
+	}
+	return false
+}
+
+// PRJobs returns the jobs which are run in PR builds.
+// They are the jobs triggered by pull requests, and their downstream jobs if the chainPR annotation is enabled.
+func PRJobs(jobs map[string]Job) map[string]Job {
+	selected := make(map[string]Job)
+	for name, job := range jobs {
+		if IsPRJob(job) {
+			selected[name] = job
+		}
+	}
+
+	for added := true; added; {
+		added = false
+		for name, job := range jobs {
+			if _, ok := selected[name]; ok || fmt.Sprint(job.Annotations[ChainPRAnnotation]) != "true" {
+				continue
+			}
+			for _, up := range UpstreamJobs(job, jobs) {
+				if _, ok := selected[up]; ok {
+					selected[name] = job
+					added = true
+					break
+				}
+			}
+		}
+	}
+
+	return selected
+}
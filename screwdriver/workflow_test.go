@@ -0,0 +1,96 @@
+package screwdriver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkflowOrder(t *testing.T) {
+	testCases := []struct {
+		name      string
+		jobs      map[string]Job
+		expect    []string
+		expectErr string
+	}{
+		{
+			name: "linear",
+			jobs: map[string]Job{
+				"publish": {Requires: []string{"test"}},
+				"test":    {Requires: []string{"main"}},
+				"main":    {Requires: []string{"~commit", "~pr"}},
+			},
+			expect: []string{"main", "test", "publish"},
+		},
+		{
+			name: "fan out and fan in",
+			jobs: map[string]Job{
+				"main":   {Requires: []string{"~commit"}},
+				"lint":   {Requires: []string{"main"}},
+				"test":   {Requires: []string{"~main"}},
+				"deploy": {Requires: []string{"lint", "test", "~sd@123:main"}},
+			},
+			expect: []string{"main", "lint", "test", "deploy"},
+		},
+		{
+			name: "jobs without requires",
+			jobs: map[string]Job{
+				"b": {},
+				"a": {},
+			},
+			expect: []string{"a", "b"},
+		},
+		{
+			name: "cycle",
+			jobs: map[string]Job{
+				"main": {Requires: []string{"~commit"}},
+				"a":    {Requires: []string{"b"}},
+				"b":    {Requires: []string{"a"}},
+			},
+			expectErr: "workflow has a cycle in jobs: [a b]",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			order, err := WorkflowOrder(tt.jobs)
+			if tt.expectErr != "" {
+				assert.Equal(t, tt.expectErr, err.Error())
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.expect, order)
+			}
+		})
+	}
+}
+
+func TestPRJobs(t *testing.T) {
+	chainPR := map[string]interface{}{ChainPRAnnotation: true}
+
+	t.Run("success", func(t *testing.T) {
+		jobs := map[string]Job{
+			"main":    {Requires: []string{"~commit", "~pr"}},
+			"lint":    {Requires: []string{"~pr"}},
+			"test":    {Requires: []string{"main"}},
+			"publish": {Requires: []string{"~commit"}},
+		}
+		assert.Equal(t, map[string]Job{
+			"main": jobs["main"],
+			"lint": jobs["lint"],
+		}, PRJobs(jobs))
+	})
+
+	t.Run("success with chainPR", func(t *testing.T) {
+		jobs := map[string]Job{
+			"main":    {Requires: []string{"~commit", "~pr"}},
+			"test":    {Requires: []string{"main"}, Annotations: chainPR},
+			"deploy":  {Requires: []string{"test"}, Annotations: chainPR},
+			"publish": {Requires: []string{"~commit"}, Annotations: chainPR},
+		}
+		assert.Equal(t, map[string]Job{
+			"main":   jobs["main"],
+			"test":   jobs["test"],
+			"deploy": jobs["deploy"],
+		}, PRJobs(jobs))
+	})
+}
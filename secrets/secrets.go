@@ -0,0 +1,61 @@
+package secrets
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/go-yaml/yaml"
+)
+
+// Provider resolves secrets as a map of their names to values
+type Provider interface {
+	Secrets() (map[string]string, error)
+}
+
+type fileProvider struct {
+	filePath string
+}
+
+var _ Provider = (*fileProvider)(nil)
+
+// NewFileProvider creates a Provider which reads secrets from the YAML file mapping secret names to their values
+func NewFileProvider(filePath string) Provider {
+	return &fileProvider{filePath: filePath}
+}
+
+func (p *fileProvider) Secrets() (map[string]string, error) {
+	absFilePath, err := filepath.Abs(p.filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	buf, err := ioutil.ReadFile(absFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secrets file in `%s`: %v", absFilePath, err)
+	}
+
+	secrets := make(map[string]string)
+	err = yaml.Unmarshal(buf, &secrets)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse secrets file in `%s`: %v", absFilePath, err)
+	}
+
+	return secrets, nil
+}
+
+// Resolve resolves secrets from all providers. Secrets of the latter providers take precedence.
+func Resolve(providers ...Provider) (map[string]string, error) {
+	resolved := make(map[string]string)
+	for _, p := range providers {
+		secrets, err := p.Secrets()
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range secrets {
+			resolved[k] = v
+		}
+	}
+
+	return resolved, nil
+}
@@ -0,0 +1,55 @@
+package secrets
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockProvider struct {
+	secrets map[string]string
+	err     error
+}
+
+func (m mockProvider) Secrets() (map[string]string, error) {
+	return m.secrets, m.err
+}
+
+func TestFileProvider(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		secrets, err := NewFileProvider("./testdata/secrets.yaml").Secrets()
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]string{"GIT_KEY": "git-key", "NPM_TOKEN": "npm-token"}, secrets)
+	})
+
+	t.Run("failure by nonexistent file", func(t *testing.T) {
+		_, err := NewFileProvider("./testdata/none.yaml").Secrets()
+		assert.Contains(t, err.Error(), "failed to read secrets file")
+	})
+}
+
+func TestResolve(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		secrets, err := Resolve(
+			mockProvider{secrets: map[string]string{"GIT_KEY": "vault", "NPM_TOKEN": "vault"}},
+			mockProvider{secrets: map[string]string{"GIT_KEY": "file"}},
+		)
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]string{"GIT_KEY": "file", "NPM_TOKEN": "vault"}, secrets)
+	})
+
+	t.Run("success without providers", func(t *testing.T) {
+		secrets, err := Resolve()
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]string{}, secrets)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		_, err := Resolve(
+			mockProvider{secrets: map[string]string{"GIT_KEY": "vault"}},
+			mockProvider{err: errors.New("failed to read secrets")},
+		)
+		assert.Equal(t, "failed to read secrets", err.Error())
+	})
+}
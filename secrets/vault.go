@@ -0,0 +1,132 @@
+package secrets
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/mitchellh/go-homedir"
+)
+
+const (
+	vaultAPIVersion = "v1"
+	vaultTokenEnv   = "VAULT_TOKEN"
+	vaultTokenFile  = ".vault-token"
+)
+
+var homeDir = homedir.Dir
+
+type vaultProvider struct {
+	HTTPClient *http.Client
+	Addr       string
+	Path       string
+	Token      string
+}
+
+var _ Provider = (*vaultProvider)(nil)
+
+type vaultResponse struct {
+	Data   map[string]interface{} `json:"data"`
+	Errors []string               `json:"errors"`
+}
+
+// NewVaultProvider creates a Provider which reads secrets stored in the path of the Vault KV secrets engine.
+// The token is read from $VAULT_TOKEN or ~/.vault-token as the Vault CLI does.
+func NewVaultProvider(addr, secretPath string) Provider {
+	return &vaultProvider{
+		HTTPClient: http.DefaultClient,
+		Addr:       addr,
+		Path:       secretPath,
+	}
+}
+
+func vaultToken() (string, error) {
+	if token := os.Getenv(vaultTokenEnv); token != "" {
+		return token, nil
+	}
+
+	home, err := homeDir()
+	if err != nil {
+		return "", err
+	}
+
+	buf, err := ioutil.ReadFile(filepath.Join(home, vaultTokenFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", errors.New("Vault token is not found, please set $VAULT_TOKEN or log in with Vault CLI")
+		}
+		return "", fmt.Errorf("failed to read Vault token: %v", err)
+	}
+
+	return strings.TrimSpace(string(buf)), nil
+}
+
+func (p *vaultProvider) Secrets() (map[string]string, error) {
+	token := p.Token
+	if token == "" {
+		var err error
+		token, err = vaultToken()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	u, err := url.Parse(p.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request url: %v", err)
+	}
+	u.Path = path.Join(u.Path, vaultAPIVersion, p.Path)
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %v", err)
+	}
+	req.Header.Add("X-Vault-Token", token)
+	req.Header.Add("Accept", "application/json")
+
+	res, err := p.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer res.Body.Close()
+
+	v := new(vaultResponse)
+	err = json.NewDecoder(res.Body).Decode(v)
+	if err != nil && res.StatusCode == http.StatusOK {
+		return nil, fmt.Errorf("failed to parse Vault response: %v", err)
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to read secrets from Vault: StatusCode %d %v", res.StatusCode, v.Errors)
+	}
+
+	return flattenVaultData(v.Data)
+}
+
+// flattenVaultData returns the secrets in the data of KV secrets engine version 1 and 2.
+func flattenVaultData(data map[string]interface{}) (map[string]string, error) {
+	// KV version 2 nests the secrets in data with metadata
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		if _, ok := data["metadata"]; ok {
+			data = nested
+		}
+	}
+
+	secrets := make(map[string]string, len(data))
+	for k, v := range data {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("secret %s in Vault is not a string", k)
+		}
+		secrets[k] = s
+	}
+
+	return secrets, nil
+}
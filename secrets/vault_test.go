@@ -0,0 +1,134 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVaultProvider(t *testing.T) {
+	testCases := []struct {
+		name      string
+		status    int
+		body      string
+		expect    map[string]string
+		expectErr string
+	}{
+		{
+			name:   "success with KV version 1",
+			status: 200,
+			body:   `{"data":{"GIT_KEY":"git-key","NPM_TOKEN":"npm-token"}}`,
+			expect: map[string]string{"GIT_KEY": "git-key", "NPM_TOKEN": "npm-token"},
+		},
+		{
+			name:   "success with KV version 2",
+			status: 200,
+			body:   `{"data":{"data":{"GIT_KEY":"git-key"},"metadata":{"version":1}}}`,
+			expect: map[string]string{"GIT_KEY": "git-key"},
+		},
+		{
+			name:      "failure by not string secret",
+			status:    200,
+			body:      `{"data":{"GIT_KEY":1}}`,
+			expectErr: "secret GIT_KEY in Vault is not a string",
+		},
+		{
+			name:      "failure by status",
+			status:    403,
+			body:      `{"errors":["permission denied"]}`,
+			expectErr: "failed to read secrets from Vault: StatusCode 403 [permission denied]",
+		},
+		{
+			name:      "failure by invalid JSON",
+			status:    200,
+			body:      `{`,
+			expectErr: "failed to parse Vault response: unexpected EOF",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/v1/secret/data/sd-local", r.URL.Path)
+				assert.Equal(t, "vault-token", r.Header.Get("X-Vault-Token"))
+
+				w.WriteHeader(tt.status)
+				fmt.Fprintln(w, tt.body)
+			}))
+			defer server.Close()
+
+			p := &vaultProvider{
+				HTTPClient: http.DefaultClient,
+				Addr:       server.URL,
+				Path:       "secret/data/sd-local",
+				Token:      "vault-token",
+			}
+
+			secrets, err := p.Secrets()
+			if tt.expectErr != "" {
+				assert.Equal(t, tt.expectErr, err.Error())
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.expect, secrets)
+			}
+		})
+	}
+}
+
+func TestVaultToken(t *testing.T) {
+	defer func() {
+		homeDir = homedir.Dir
+	}()
+
+	dir, err := ioutil.TempDir("", "home")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	homeDir = func() (string, error) { return dir, nil }
+
+	t.Run("success with env", func(t *testing.T) {
+		os.Setenv(vaultTokenEnv, "env-token")
+		defer os.Unsetenv(vaultTokenEnv)
+
+		token, err := vaultToken()
+		assert.Nil(t, err)
+		assert.Equal(t, "env-token", token)
+	})
+
+	t.Run("failure without token", func(t *testing.T) {
+		os.Unsetenv(vaultTokenEnv)
+
+		_, err := vaultToken()
+		assert.Equal(t, "Vault token is not found, please set $VAULT_TOKEN or log in with Vault CLI", err.Error())
+	})
+
+	t.Run("success with token file", func(t *testing.T) {
+		os.Unsetenv(vaultTokenEnv)
+		err := ioutil.WriteFile(filepath.Join(dir, vaultTokenFile), []byte("file-token\n"), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		token, err := vaultToken()
+		assert.Nil(t, err)
+		assert.Equal(t, "file-token", token)
+	})
+
+	t.Run("failure by home directory", func(t *testing.T) {
+		os.Unsetenv(vaultTokenEnv)
+		homeDir = func() (string, error) { return "", errors.New("no home") }
+
+		_, err := vaultToken()
+		assert.Equal(t, "no home", err.Error())
+	})
+}
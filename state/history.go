@@ -0,0 +1,114 @@
+package state
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// HistoryFileName is the name of the file which records the finished builds.
+const HistoryFileName = "history.jsonl"
+
+// Record is a finished build.
+// Args is the arguments of sd-local including the command, so that the build can be run again as it was.
+type Record struct {
+	ID            int       `json:"id"`
+	Job           string    `json:"job"`
+	Args          []string  `json:"args"`
+	Dir           string    `json:"dir"`
+	ArtifactsPath string    `json:"artifactsPath"`
+	Status        string    `json:"status"`
+	StartTime     time.Time `json:"startTime"`
+	EndTime       time.Time `json:"endTime"`
+}
+
+// History records the builds run by the processes of sd-local.
+type History interface {
+	Add(r Record) (Record, error)
+	List() ([]Record, error)
+}
+
+type fileHistory struct {
+	path string
+}
+
+// NewHistory returns the history recording the builds into the file of path, one JSON per line.
+func NewHistory(path string) History {
+	return &fileHistory{path: path}
+}
+
+func (h *fileHistory) read() ([]Record, error) {
+	f, err := os.Open(h.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Record{}, nil
+		}
+		return nil, fmt.Errorf("failed to read history file: %v", err)
+	}
+	defer f.Close()
+
+	records := make([]Record, 0)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var r Record
+		// A broken line, such as one left by a full disk, doesn't hide the others
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			continue
+		}
+		records = append(records, r)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history file: %v", err)
+	}
+
+	return records, nil
+}
+
+// Add appends the build and returns it with its ID.
+func (h *fileHistory) Add(r Record) (Record, error) {
+	if err := os.MkdirAll(filepath.Dir(h.path), 0777); err != nil {
+		return r, fmt.Errorf("failed to create state directory: %v", err)
+	}
+
+	unlock, err := lockFile(h.path)
+	if err != nil {
+		return r, err
+	}
+	defer unlock()
+
+	records, err := h.read()
+	if err != nil {
+		return r, err
+	}
+	r.ID = 1
+	if len(records) != 0 {
+		r.ID = records[len(records)-1].ID + 1
+	}
+
+	line, err := json.Marshal(r)
+	if err != nil {
+		return r, fmt.Errorf("failed to encode history: %v", err)
+	}
+
+	// The arguments may contain environment variables, so the history is readable only by the user
+	f, err := os.OpenFile(h.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return r, fmt.Errorf("failed to write history file: %v", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return r, fmt.Errorf("failed to write history file: %v", err)
+	}
+
+	return r, nil
+}
+
+// List returns the recorded builds from the oldest.
+func (h *fileHistory) List() ([]Record, error) {
+	return h.read()
+}
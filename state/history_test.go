@@ -0,0 +1,67 @@
+package state
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "history")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "sub", HistoryFileName)
+	h := NewHistory(path)
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("success with empty", func(t *testing.T) {
+		records, err := h.List()
+		assert.Nil(t, err)
+		assert.Equal(t, []Record{}, records)
+	})
+
+	t.Run("success with add", func(t *testing.T) {
+		r, err := h.Add(Record{Job: "main", Args: []string{"build", "main"}, Dir: "/src", Status: "SUCCESS", StartTime: start, EndTime: start.Add(time.Minute)})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, r.ID)
+
+		r, err = h.Add(Record{Job: "test", Args: []string{"build", "test"}, Dir: "/src", Status: "FAILURE", StartTime: start, EndTime: start.Add(time.Minute)})
+		assert.Nil(t, err)
+		assert.Equal(t, 2, r.ID)
+
+		records, err := h.List()
+		assert.Nil(t, err)
+		assert.Equal(t, []Record{
+			{ID: 1, Job: "main", Args: []string{"build", "main"}, Dir: "/src", Status: "SUCCESS", StartTime: start, EndTime: start.Add(time.Minute)},
+			{ID: 2, Job: "test", Args: []string{"build", "test"}, Dir: "/src", Status: "FAILURE", StartTime: start, EndTime: start.Add(time.Minute)},
+		}, records)
+
+		info, err := os.Stat(path)
+		assert.Nil(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	})
+
+	t.Run("success with broken line", func(t *testing.T) {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.WriteString("{\"id\": 3, \"job\n")
+		f.Close()
+
+		r, err := h.Add(Record{Job: "main"})
+		assert.Nil(t, err)
+		assert.Equal(t, 3, r.ID)
+
+		records, err := h.List()
+		assert.Nil(t, err)
+		assert.Len(t, records, 3)
+	})
+}
@@ -0,0 +1,204 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"syscall"
+	"time"
+)
+
+// FileName is the name of the file which records the running builds.
+const FileName = "builds.json"
+
+// processAlive reports whether the process of pid exists.
+var processAlive = func(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = p.Signal(syscall.Signal(0))
+	return err == nil || err == syscall.EPERM
+}
+
+// Build is a build running on the host.
+type Build struct {
+	ID        int       `json:"id"`
+	PID       int       `json:"pid"`
+	Job       string    `json:"job"`
+	SrcPath   string    `json:"srcPath"`
+	StartTime time.Time `json:"startTime"`
+	Runtime   string    `json:"runtime,omitempty"`
+	UseSudo   bool      `json:"useSudo,omitempty"`
+}
+
+// Store records the running builds shared by the processes of sd-local.
+type Store interface {
+	Add(b Build) (Build, error)
+	Remove(id int) error
+	List() ([]Build, error)
+	Get(id int) (Build, error)
+	Reap() ([]Build, error)
+}
+
+type state struct {
+	NextID int     `json:"nextId"`
+	Builds []Build `json:"builds"`
+}
+
+type fileStore struct {
+	path string
+}
+
+// New returns the store recording the running builds into the file of path.
+func New(path string) Store {
+	return &fileStore{path: path}
+}
+
+// lockFile takes the exclusive lock shared by the processes of sd-local for the file of path.
+// The returned function releases the lock.
+func lockFile(path string) (func(), error) {
+	lock, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0666)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %v", err)
+	}
+
+	if err := syscall.Flock(int(lock.Fd()), syscall.LOCK_EX); err != nil {
+		lock.Close()
+		return nil, fmt.Errorf("failed to lock %s: %v", path, err)
+	}
+
+	return func() {
+		syscall.Flock(int(lock.Fd()), syscall.LOCK_UN)
+		lock.Close()
+	}, nil
+}
+
+// update reads the state, applies f and writes the state back while holding the lock of the file.
+func (s *fileStore) update(f func(st *state) error) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0777); err != nil {
+		return fmt.Errorf("failed to create state directory: %v", err)
+	}
+
+	unlock, err := lockFile(s.path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	st := &state{NextID: 1}
+	data, err := ioutil.ReadFile(s.path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read state file: %v", err)
+	}
+	if len(data) != 0 {
+		if err := json.Unmarshal(data, st); err != nil {
+			return fmt.Errorf("failed to parse state file: %v", err)
+		}
+	}
+
+	if err := f(st); err != nil {
+		return err
+	}
+
+	data, err = json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %v", err)
+	}
+
+	// The state is replaced at once so that it is never read half-written
+	tmp := s.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0666); err != nil {
+		return fmt.Errorf("failed to write state file: %v", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to write state file: %v", err)
+	}
+
+	return nil
+}
+
+// Add records the build and returns it with its ID.
+func (s *fileStore) Add(b Build) (Build, error) {
+	err := s.update(func(st *state) error {
+		b.ID = st.NextID
+		st.NextID++
+		st.Builds = append(st.Builds, b)
+		return nil
+	})
+
+	return b, err
+}
+
+// Remove removes the build of id. Nothing happens if it is not recorded.
+func (s *fileStore) Remove(id int) error {
+	return s.update(func(st *state) error {
+		for i, b := range st.Builds {
+			if b.ID == id {
+				st.Builds = append(st.Builds[:i], st.Builds[i+1:]...)
+				break
+			}
+		}
+		return nil
+	})
+}
+
+// List returns the running builds sorted by ID.
+// The builds whose processes have exited without removing themselves, such as killed ones, are not listed.
+func (s *fileStore) List() ([]Build, error) {
+	builds := make([]Build, 0)
+	err := s.update(func(st *state) error {
+		for _, b := range st.Builds {
+			if processAlive(b.PID) {
+				builds = append(builds, b)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(builds, func(i, j int) bool { return builds[i].ID < builds[j].ID })
+
+	return builds, nil
+}
+
+// Get returns the running build of id.
+func (s *fileStore) Get(id int) (Build, error) {
+	builds, err := s.List()
+	if err != nil {
+		return Build{}, err
+	}
+
+	for _, b := range builds {
+		if b.ID == id {
+			return b, nil
+		}
+	}
+
+	return Build{}, fmt.Errorf("not found running build %d", id)
+}
+
+// Reap removes the builds whose processes have exited without removing themselves, such as killed ones, and returns them.
+// Their containers are left, so the caller removes them.
+func (s *fileStore) Reap() ([]Build, error) {
+	var dead []Build
+	err := s.update(func(st *state) error {
+		alive := make([]Build, 0, len(st.Builds))
+		for _, b := range st.Builds {
+			if processAlive(b.PID) {
+				alive = append(alive, b)
+			} else {
+				dead = append(dead, b)
+			}
+		}
+		st.Builds = alive
+		return nil
+	})
+
+	return dead, err
+}
@@ -0,0 +1,111 @@
+package state
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "state")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	defer func(f func(int) bool) {
+		processAlive = f
+	}(processAlive)
+	dead := map[int]bool{}
+	processAlive = func(pid int) bool { return !dead[pid] }
+
+	s := New(filepath.Join(dir, "sub", FileName))
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("success with empty", func(t *testing.T) {
+		builds, err := s.List()
+		assert.Nil(t, err)
+		assert.Equal(t, []Build{}, builds)
+	})
+
+	t.Run("success with add", func(t *testing.T) {
+		b, err := s.Add(Build{PID: 100, Job: "main", SrcPath: "/src", StartTime: start})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, b.ID)
+
+		b, err = s.Add(Build{PID: 200, Job: "test", SrcPath: "/src", StartTime: start})
+		assert.Nil(t, err)
+		assert.Equal(t, 2, b.ID)
+
+		builds, err := s.List()
+		assert.Nil(t, err)
+		assert.Equal(t, []Build{
+			{ID: 1, PID: 100, Job: "main", SrcPath: "/src", StartTime: start},
+			{ID: 2, PID: 200, Job: "test", SrcPath: "/src", StartTime: start},
+		}, builds)
+	})
+
+	t.Run("success with get", func(t *testing.T) {
+		b, err := s.Get(2)
+		assert.Nil(t, err)
+		assert.Equal(t, "test", b.Job)
+	})
+
+	t.Run("success with remove", func(t *testing.T) {
+		assert.Nil(t, s.Remove(1))
+		assert.Nil(t, s.Remove(10))
+
+		builds, err := s.List()
+		assert.Nil(t, err)
+		assert.Equal(t, []Build{{ID: 2, PID: 200, Job: "test", SrcPath: "/src", StartTime: start}}, builds)
+	})
+
+	t.Run("success with exited process", func(t *testing.T) {
+		dead[200] = true
+
+		builds, err := s.List()
+		assert.Nil(t, err)
+		assert.Equal(t, []Build{}, builds)
+
+		b, err := s.Add(Build{PID: 300, Job: "main", Runtime: "podman", UseSudo: true})
+		assert.Nil(t, err)
+		assert.Equal(t, 3, b.ID)
+	})
+
+	t.Run("success with reap", func(t *testing.T) {
+		builds, err := s.Reap()
+		assert.Nil(t, err)
+		assert.Equal(t, []Build{{ID: 2, PID: 200, Job: "test", SrcPath: "/src", StartTime: start}}, builds)
+
+		builds, err = s.Reap()
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(builds))
+
+		builds, err = s.List()
+		assert.Nil(t, err)
+		assert.Equal(t, []Build{{ID: 3, PID: 300, Job: "main", Runtime: "podman", UseSudo: true}}, builds)
+	})
+
+	t.Run("failure by not running build", func(t *testing.T) {
+		_, err := s.Get(2)
+		assert.Equal(t, "not found running build 2", err.Error())
+	})
+
+	t.Run("failure by broken file", func(t *testing.T) {
+		path := filepath.Join(dir, "broken.json")
+		if err := ioutil.WriteFile(path, []byte("{"), 0666); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := New(path).List()
+		assert.Contains(t, err.Error(), "failed to parse state file")
+	})
+}
+
+func TestProcessAlive(t *testing.T) {
+	assert.True(t, processAlive(os.Getpid()))
+}